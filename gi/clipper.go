@@ -5,6 +5,8 @@
 package gi
 
 import (
+	"image"
+
 	"github.com/goki/gi/oswin/dnd"
 	"github.com/goki/gi/oswin/mimedata"
 )
@@ -54,3 +56,11 @@ type DragNDropper interface {
 	// will be nil.
 	DropExternal(md mimedata.Mimes, mod dnd.DropMods)
 }
+
+// DragNDropUnhandler is an optional interface for the source of a
+// drag-n-drop, which is called when the drop was not processed by any
+// target in the window -- e.g., it was dropped outside of the window.  where
+// is the drop position in window coordinates, and can be outside the window.
+type DragNDropUnhandler interface {
+	DragNDropUnhandled(win *Window, where image.Point)
+}
@@ -58,14 +58,15 @@ var StdDialogVSpaceUnits = units.Value{Val: StdDialogVSpace, Un: units.Ex, Dots:
 // be rendered in a separate window or on top of an existing one.
 type Dialog struct {
 	Viewport2D
-	Title     string      `desc:"title text displayed as the window title for the dialog"`
-	Prompt    string      `desc:"a prompt string displayed below the title"`
-	Modal     bool        `desc:"open the dialog in a modal state, blocking all other input"`
-	DefSize   image.Point `desc:"default size -- if non-zero, then this is used instead of doing an initial size computation -- can save a lot of time for complex dialogs -- sizes are remembered and used after first use anyway"`
-	State     DialogState `desc:"state of the dialog"`
-	SigVal    int64       `desc:"signal value that will be sent, if >= 0 (by default, DialogAccepted or DialogCanceled will be sent for standard Ok / Cancel buttons)"`
-	DialogSig ki.Signal   `json:"-" xml:"-" view:"-" desc:"signal for dialog -- sends a signal when opened, accepted, or canceled"`
-	Data      interface{} `json:"-" xml:"-" view:"-" desc:"the main data element represented by this window -- used for Recycle* methods for windows that represent a given data element -- prevents redundant windows"`
+	Title       string      `desc:"title text displayed as the window title for the dialog"`
+	Prompt      string      `desc:"a prompt string displayed below the title"`
+	Modal       bool        `desc:"open the dialog in a modal state, blocking all other input"`
+	DefSize     image.Point `desc:"default size -- if non-zero, then this is used instead of doing an initial size computation -- can save a lot of time for complex dialogs -- sizes are remembered and used after first use anyway"`
+	State       DialogState `desc:"state of the dialog"`
+	SigVal      int64       `desc:"signal value that will be sent, if >= 0 (by default, DialogAccepted or DialogCanceled will be sent for standard Ok / Cancel buttons)"`
+	DialogSig   ki.Signal   `json:"-" xml:"-" view:"-" desc:"signal for dialog -- sends a signal when opened, accepted, or canceled"`
+	Data        interface{} `json:"-" xml:"-" view:"-" desc:"the main data element represented by this window -- used for Recycle* methods for windows that represent a given data element -- prevents redundant windows"`
+	AcceptCheck func() bool `json:"-" xml:"-" view:"-" desc:"optional function called when the dialog is about to be accepted -- if it returns false, the dialog remains open, e.g., because it has invalid values, which the function should report to the user"`
 }
 
 var KiT_Dialog = kit.Types.AddType(&Dialog{}, DialogProps)
@@ -220,6 +221,9 @@ func (dlg *Dialog) Accept() {
 	if dlg == nil {
 		return
 	}
+	if dlg.AcceptCheck != nil && !dlg.AcceptCheck() {
+		return
+	}
 	dlg.State = DialogAccepted
 	if dlg.SigVal >= 0 {
 		dlg.DialogSig.Emit(dlg.This(), dlg.SigVal, nil)
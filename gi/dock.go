@@ -0,0 +1,779 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"strings"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// DockZones are the regions of a DockTabs where a dragged panel can be
+// dropped: the center adds it as another tab, and the edges split the tabs
+// in that direction, putting the panel in a new set of tabs on that side.
+type DockZones int32
+
+const (
+	// DockCenter adds the panel as a new tab
+	DockCenter DockZones = iota
+
+	// DockLeft splits the tabs horizontally, with the panel on the left
+	DockLeft
+
+	// DockRight splits the tabs horizontally, with the panel on the right
+	DockRight
+
+	// DockTop splits the tabs vertically, with the panel on the top
+	DockTop
+
+	// DockBottom splits the tabs vertically, with the panel on the bottom
+	DockBottom
+
+	DockZonesN
+)
+
+//go:generate stringer -type=DockZones
+
+var KiT_DockZones = kit.Enums.AddEnum(DockZonesN, kit.NotBitFlag, nil)
+
+func (ev DockZones) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *DockZones) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// DockEdgeZone is the proportion of the size of a DockTabs, from each edge,
+// that counts as an edge drop zone for splitting -- the rest is the center.
+var DockEdgeZone = float32(0.25)
+
+// DockPanelMimeType is the mime type used for drag-n-drop of dock panels --
+// the data is the name of the panel.
+const DockPanelMimeType = "application/x-gogi-dock-panel"
+
+// DockFloatWinName is the name prefix of the windows holding floating
+// panels -- see DockFloatWinNameFor.
+const DockFloatWinName = "dock-float"
+
+////////////////////////////////////////////////////////////////////////////////////////
+// DockLayout
+
+// DockLayout is a docking layout that the user can rearrange: panels are
+// shown in DockTabs, which can be split in any direction within nested
+// SplitViews.  Panels are moved by dragging their tabs onto the drop zones of
+// any DockTabs (see DockZones), and are torn off into a separate floating
+// window by dropping them outside of the window -- the floating window has
+// its own DockLayout, linked back to the Home one.  Closing a floating window
+// docks its panels back into the home layout.  The arrangement of all panels,
+// including floating windows, can be saved and restored as named workspaces
+// (see SaveWorkspace, OpenWorkspace), which are stored in the GoGi prefs
+// directory, alongside WinGeomPrefs.  Panels are identified by their name,
+// which must be unique among all panels in the layout.
+type DockLayout struct {
+	Layout
+	Home   *DockLayout   `copy:"-" json:"-" xml:"-" view:"-" desc:"for a floating dock layout in its own window, the home dock layout that it was torn off from -- nil for the home layout itself"`
+	Floats []*DockLayout `copy:"-" json:"-" xml:"-" view:"-" desc:"for the home dock layout, the floating dock layouts torn off from it, each in its own window"`
+}
+
+var KiT_DockLayout = kit.Types.AddType(&DockLayout{}, DockLayoutProps)
+
+// AddNewDockLayout adds a new dock layout to given parent node, with given name.
+func AddNewDockLayout(parent ki.Ki, name string) *DockLayout {
+	return parent.AddNewChild(KiT_DockLayout, name).(*DockLayout)
+}
+
+func (dl *DockLayout) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*DockLayout)
+	dl.Layout.CopyFieldsFrom(&fr.Layout)
+}
+
+var DockLayoutProps = ki.Props{
+	"EnumType:Flag": KiT_NodeFlags,
+	"max-width":     -1,
+	"max-height":    -1,
+	"margin":        0,
+	"padding":       0,
+}
+
+// dockTabsNo is used for generating unique names for new DockTabs
+var dockTabsNo = 0
+
+// newDockName returns a new unique name with given prefix, for new DockTabs
+// and SplitViews created by the layout
+func newDockName(prefix string) string {
+	dockTabsNo++
+	return fmt.Sprintf("%v-%v", prefix, dockTabsNo)
+}
+
+// DockRoot returns the root of the docking tree, which is either a *SplitView
+// or a *DockTabs -- creates an empty DockTabs if there is nothing yet.
+func (dl *DockLayout) DockRoot() Node2D {
+	if !dl.HasChildren() {
+		dl.Lay = LayoutVert
+		dl.newTabs(dl, 0)
+	}
+	return dl.Child(0).(Node2D)
+}
+
+// newTabs inserts a new DockTabs in given parent at given index
+func (dl *DockLayout) newTabs(par ki.Ki, idx int) *DockTabs {
+	dt := par.InsertNewChild(KiT_DockTabs, idx, newDockName("tabs")).(*DockTabs)
+	dt.NoDeleteTabs = true
+	return dt
+}
+
+// AllTabs returns all the DockTabs in this layout (not including floats),
+// in depth-first order
+func (dl *DockLayout) AllTabs() []*DockTabs {
+	var tl []*DockTabs
+	dockTabsList(dl.DockRoot(), &tl)
+	return tl
+}
+
+// dockTabsList adds all DockTabs under given node of docking tree to list
+func dockTabsList(k ki.Ki, tl *[]*DockTabs) {
+	switch nd := k.(type) {
+	case *DockTabs:
+		*tl = append(*tl, nd)
+	case *SplitView:
+		for _, kid := range nd.Kids {
+			dockTabsList(kid, tl)
+		}
+	}
+}
+
+// DefaultTabs returns the DockTabs where new panels are added by default:
+// the first one in the layout
+func (dl *DockLayout) DefaultTabs() *DockTabs {
+	return dl.AllTabs()[0]
+}
+
+// AddPanel adds given widget as a new panel in the default tabs, with given
+// tab label, returning the tab index.  The widget name must be unique among
+// all panels in the layout, as it is used to identify the panel in
+// workspaces.
+func (dl *DockLayout) AddPanel(widg Node2D, label string) int {
+	return dl.DefaultTabs().AddTab(widg, label)
+}
+
+// HomeDock returns the home dock layout -- this one if not a float
+func (dl *DockLayout) HomeDock() *DockLayout {
+	if dl.Home != nil {
+		return dl.Home
+	}
+	return dl
+}
+
+// Family returns the home dock layout and all of its floats
+func (dl *DockLayout) Family() []*DockLayout {
+	home := dl.HomeDock()
+	fam := make([]*DockLayout, 0, len(home.Floats)+1)
+	fam = append(fam, home)
+	fam = append(fam, home.Floats...)
+	return fam
+}
+
+// IsFloat returns true if this is a floating dock layout in its own window
+func (dl *DockLayout) IsFloat() bool {
+	return dl.Home != nil
+}
+
+// FindPanel finds the panel with given name anywhere in the family of
+// layouts, returning the DockTabs holding it and its index -- nil if not found
+func (dl *DockLayout) FindPanel(name string) (*DockTabs, int) {
+	for _, fl := range dl.Family() {
+		for _, dt := range fl.AllTabs() {
+			if idx := dt.PanelIndex(name); idx >= 0 {
+				return dt, idx
+			}
+		}
+	}
+	return nil, -1
+}
+
+// TabsAt returns the DockTabs at given position in window coordinates, nil if none
+func (dl *DockLayout) TabsAt(pos image.Point) *DockTabs {
+	for _, dt := range dl.AllTabs() {
+		if dt.PosInWinBBox(pos) {
+			return dt
+		}
+	}
+	return nil
+}
+
+// MovePanel moves the panel at given index in src tabs to dst tabs, which
+// can be in another window of the family, splitting dst according to given
+// zone.  Returns false if nothing was moved.
+func (dl *DockLayout) MovePanel(src *DockTabs, idx int, dst *DockTabs, zone DockZones) bool {
+	widg, tab, ok := src.TabAtIndex(idx)
+	if !ok {
+		return false
+	}
+	if src == dst && (zone == DockCenter || src.NTabs() == 1) {
+		return false
+	}
+	label := tab.Text
+	sdl := src.DockLayout()
+	ddl := dst.DockLayout()
+	svp := sdl.ViewportSafe()
+	dvp := ddl.ViewportSafe()
+	cross := svp != dvp
+	// block updates while the structure is in flux -- also needed because
+	// the other window runs its own event loop
+	svp.BlockUpdates()
+	if cross {
+		dvp.BlockUpdates()
+		widg.AsNode2D().DisconnectAllEvents(AllPris)
+	}
+	if zone != DockCenter {
+		dst = ddl.SplitTabs(dst, zone)
+	}
+	didx := dst.AddTab(widg, label)
+	dst.SelectTabIndex(didx)
+	src.removeTabButton(idx)
+	sdl.prune(src)
+	svp.UnblockUpdates()
+	if cross {
+		dvp.UnblockUpdates()
+		svp.SetNeedsFullRender()
+	}
+	dvp.SetNeedsFullRender()
+	return true
+}
+
+// SplitTabs splits given tabs in the direction of given (edge) zone,
+// returning the new empty tabs on that side.  If the tabs are already within
+// a SplitView in the same direction, the new tabs are added to that,
+// splitting the space of the given tabs, and otherwise a new SplitView
+// replaces the given tabs, with both tabs in it.
+func (dl *DockLayout) SplitTabs(dt *DockTabs, zone DockZones) *DockTabs {
+	dim := mat32.X
+	if zone == DockTop || zone == DockBottom {
+		dim = mat32.Y
+	}
+	after := zone == DockRight || zone == DockBottom
+	idx, _ := dt.IndexInParent()
+	if sv, ok := dt.Parent().(*SplitView); ok && sv.Dim == dim {
+		sv.UpdateSplits()
+		splits := make([]float32, 0, len(sv.Splits)+1)
+		for i, sp := range sv.Splits {
+			if i != idx {
+				splits = append(splits, sp)
+				continue
+			}
+			splits = append(splits, 0.5*sp, 0.5*sp)
+		}
+		nidx := idx
+		if after {
+			nidx++
+		}
+		nt := dl.newTabs(sv, nidx)
+		sv.SetSplits(splits...)
+		return nt
+	}
+	par := dt.Parent()
+	sv := par.InsertNewChild(KiT_SplitView, idx, newDockName("split")).(*SplitView)
+	sv.Dim = dim
+	sv.AddChild(dt)
+	nidx := 0
+	if after {
+		nidx = 1
+	}
+	nt := dl.newTabs(sv, nidx)
+	sv.SetSplits(0.5, 0.5)
+	return nt
+}
+
+// prune removes given tabs if they are now empty, collapsing any SplitView
+// left with a single child, and closing a float window that is now empty
+func (dl *DockLayout) prune(dt *DockTabs) {
+	if dt.NTabs() > 0 {
+		return
+	}
+	sv, ok := dt.Parent().(*SplitView)
+	if !ok { // root tabs
+		if dl.IsFloat() {
+			dl.closeFloat()
+		}
+		return
+	}
+	idx, _ := dt.IndexInParent()
+	sv.UpdateSplits()
+	splits := make([]float32, 0, len(sv.Splits))
+	for i, sp := range sv.Splits {
+		if i != idx {
+			splits = append(splits, sp)
+		}
+	}
+	sv.DeleteChild(dt, ki.DestroyKids)
+	if len(sv.Kids) > 1 {
+		sv.SetSplits(splits...)
+		return
+	}
+	kid := sv.Child(0)
+	par := sv.Parent()
+	sidx, _ := sv.IndexInParent()
+	par.InsertChild(kid, sidx)
+	par.DeleteChild(sv, ki.DestroyKids)
+}
+
+// DockFloatWinNameFor returns the name for a new window holding a floating
+// dock layout of given home layout, whose first panel has given name.  The
+// part before the colon, which WinGeomPrefs uses to key the geometry, is
+// the same each time the panel is floated, so its geometry is restored and
+// the prefs do not grow with each float -- the part after the colon makes
+// the name unique among the open windows.
+func DockFloatWinNameFor(home, panel string) string {
+	cls := strings.Replace(DockFloatWinName+"-"+home+"-"+panel, ":", "-", -1)
+	for i := 0; ; i++ {
+		nm := fmt.Sprintf("%v:%v", cls, i)
+		if _, has := AllWindows.FindName(nm); !has {
+			return nm
+		}
+	}
+}
+
+// NewFloat creates a new floating dock layout in its own window, with given
+// title, for given (first) panel name, at given screen position and window
+// size (in window manager units, as in WindowGeom) -- if pos is zero the
+// window is placed by default.  The caller must start the window event loop
+// with GoStartEventLoop once the panels are in place.
+func (dl *DockLayout) NewFloat(title, panel string, pos, sz image.Point) (*DockLayout, *Window) {
+	home := dl.HomeDock()
+	nm := DockFloatWinNameFor(home.Nm, panel)
+	win := NewMainWindow(nm, title, sz.X, sz.Y)
+	if win == nil {
+		log.Printf("gi.DockLayout NewFloat: could not create window: %v\n", nm)
+		return nil, nil
+	}
+	if pos != image.ZP {
+		win.OSWin.SetGeom(pos, sz)
+	}
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	fl := AddNewDockLayout(mfr, home.Nm)
+	fl.Home = home
+	fl.DockRoot()
+	home.Floats = append(home.Floats, fl)
+	win.SetCloseReqFunc(func(w *Window) {
+		fl.DockBackAll()
+		w.Close()
+	})
+	win.SetCloseCleanFunc(func(w *Window) {
+		home.removeFloat(fl)
+	})
+	vp.UpdateEndNoSig(updt)
+	return fl, win
+}
+
+// FloatPanel tears off the panel at given index in given tabs into a new
+// floating window at given screen position (in window manager units)
+func (dl *DockLayout) FloatPanel(src *DockTabs, idx int, pos image.Point) *DockLayout {
+	widg, tab, ok := src.TabAtIndex(idx)
+	if !ok {
+		return nil
+	}
+	win := src.ParentWindow()
+	sz := image.Point{640, 480}
+	if wb := widg.AsWidget(); wb != nil && win != nil {
+		dpr := win.OSWin.Screen().DevicePixelRatio
+		psz := src.LayState.Alloc.Size.ToPoint()
+		if wb.LayState.Alloc.Size.X > 0 {
+			psz = wb.LayState.Alloc.Size.ToPoint()
+		}
+		sz = image.Point{int(float32(psz.X) / dpr), int(float32(psz.Y) / dpr)}
+	}
+	fl, fwin := dl.NewFloat(tab.Text, widg.Name(), pos, sz)
+	if fl == nil {
+		return nil
+	}
+	dl.MovePanel(src, idx, fl.DefaultTabs(), DockCenter)
+	fwin.GoStartEventLoop()
+	return fl
+}
+
+// DockBackAll docks all the panels of this floating layout back into the
+// default tabs of the home layout, which closes the float window
+func (dl *DockLayout) DockBackAll() {
+	if !dl.IsFloat() {
+		return
+	}
+	dst := dl.Home.DefaultTabs()
+	for _, dt := range dl.AllTabs() {
+		n := dt.NTabs() // dt is destroyed when its last panel moves
+		for i := 0; i < n; i++ {
+			if !dl.MovePanel(dt, 0, dst, DockCenter) {
+				break
+			}
+		}
+	}
+}
+
+// closeFloat closes the window of this floating layout
+func (dl *DockLayout) closeFloat() {
+	dl.Home.removeFloat(dl)
+	win := dl.ParentWindow()
+	if win != nil && win.OSWin != nil {
+		win.Close()
+	}
+}
+
+// removeFloat removes given float from list of Floats
+func (dl *DockLayout) removeFloat(fl *DockLayout) {
+	for i, f := range dl.Floats {
+		if f == fl {
+			dl.Floats = append(dl.Floats[:i], dl.Floats[i+1:]...)
+			return
+		}
+	}
+}
+
+// dockScreenPos converts a position in window coordinates to screen
+// coordinates, in window manager units
+func dockScreenPos(win *Window, pos image.Point) image.Point {
+	dpr := win.OSWin.Screen().DevicePixelRatio
+	wp := win.OSWin.Position()
+	return image.Point{wp.X + int(float32(pos.X)/dpr), wp.Y + int(float32(pos.Y)/dpr)}
+}
+
+// dockWinPos converts a position in screen coordinates (window manager
+// units) to window coordinates, returning false if it is outside the window
+func dockWinPos(win *Window, spos image.Point) (image.Point, bool) {
+	dpr := win.OSWin.Screen().DevicePixelRatio
+	wp := win.OSWin.Position()
+	rel := spos.Sub(wp)
+	pos := image.Point{int(float32(rel.X) * dpr), int(float32(rel.Y) * dpr)}
+	return pos, pos.In(image.Rectangle{Max: win.OSWin.Size()})
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+// DockTabs
+
+// DockTabs is a TabView within a DockLayout, whose tabs can be dragged to
+// move the panels to other DockTabs, or out of the window to float them.
+// Right-clicking on the tabs gives a menu of docking and workspace actions.
+type DockTabs struct {
+	TabView
+	ctxtIdx   int         `desc:"index of the tab where the context menu was opened"`
+	ctxtPos   image.Point `desc:"position where the context menu was opened"`
+	dragPanel string      `desc:"name of the panel being dragged from us"`
+}
+
+var KiT_DockTabs = kit.Types.AddType(&DockTabs{}, DockTabsProps)
+
+// AddNewDockTabs adds a new dock tabs to given parent node, with given name.
+func AddNewDockTabs(parent ki.Ki, name string) *DockTabs {
+	dt := parent.AddNewChild(KiT_DockTabs, name).(*DockTabs)
+	dt.NoDeleteTabs = true
+	return dt
+}
+
+func (dt *DockTabs) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*DockTabs)
+	dt.TabView.CopyFieldsFrom(&fr.TabView)
+}
+
+var DockTabsProps = ki.Props{
+	"EnumType:Flag":    KiT_NodeFlags,
+	"border-color":     &Prefs.Colors.Border,
+	"border-width":     units.NewPx(2),
+	"background-color": &Prefs.Colors.Background,
+	"color":            &Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+// DockLayout returns the DockLayout that we are in
+func (dt *DockTabs) DockLayout() *DockLayout {
+	dli, err := dt.ParentByTypeTry(KiT_DockLayout, ki.NoEmbeds)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return dli.(*DockLayout)
+}
+
+// PanelIndex returns the index of the panel with given name, -1 if not found
+func (dt *DockTabs) PanelIndex(name string) int {
+	idx, ok := dt.Frame().Kids.IndexByName(name, 0)
+	if !ok {
+		return -1
+	}
+	return idx
+}
+
+// TabIndexAt returns the index of the tab button at given position in
+// window coordinates, -1 if none
+func (dt *DockTabs) TabIndexAt(pos image.Point) int {
+	sz := dt.NTabs()
+	tbs := dt.Tabs()
+	for i := 0; i < sz; i++ {
+		tb := tbs.Child(i).Embed(KiT_TabButton).(*TabButton)
+		if tb.PosInWinBBox(pos) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ZoneAt returns the drop zone at given position in window coordinates:
+// within DockEdgeZone of the nearest edge it is that edge, and otherwise the
+// center
+func (dt *DockTabs) ZoneAt(pos image.Point) DockZones {
+	dt.BBoxMu.RLock()
+	bb := dt.WinBBox
+	dt.BBoxMu.RUnlock()
+	sz := bb.Size()
+	if sz.X <= 0 || sz.Y <= 0 {
+		return DockCenter
+	}
+	rel := pos.Sub(bb.Min)
+	fx := float32(rel.X) / float32(sz.X)
+	fy := float32(rel.Y) / float32(sz.Y)
+	zone := DockCenter
+	mind := DockEdgeZone
+	edges := []struct {
+		zone DockZones
+		dist float32
+	}{{DockLeft, fx}, {DockRight, 1 - fx}, {DockTop, fy}, {DockBottom, 1 - fy}}
+	for _, e := range edges {
+		if e.dist < mind {
+			zone = e.zone
+			mind = e.dist
+		}
+	}
+	return zone
+}
+
+// ZoneBBox returns the region, in window coordinates, that a panel will
+// occupy if dropped in given zone
+func (dt *DockTabs) ZoneBBox(zone DockZones) image.Rectangle {
+	dt.BBoxMu.RLock()
+	bb := dt.WinBBox
+	dt.BBoxMu.RUnlock()
+	mid := bb.Min.Add(bb.Max).Div(2)
+	switch zone {
+	case DockLeft:
+		bb.Max.X = mid.X
+	case DockRight:
+		bb.Min.X = mid.X
+	case DockTop:
+		bb.Max.Y = mid.Y
+	case DockBottom:
+		bb.Min.Y = mid.Y
+	}
+	return bb
+}
+
+// removeTabButton removes the tab button at given index, after the panel
+// itself has been moved elsewhere, keeping the current tab selected if
+// possible
+func (dt *DockTabs) removeTabButton(idx int) {
+	dt.Mu.Lock()
+	fr := dt.Frame()
+	tbs := dt.Tabs()
+	updt := dt.UpdateStart()
+	dt.SetFullReRender()
+	tbs.DeleteChildAtIndex(idx, ki.DestroyKids)
+	dt.RenumberTabs()
+	sz := len(fr.Kids)
+	cur := fr.StackTop
+	switch {
+	case cur > idx:
+		cur--
+	case cur == idx && idx > 0:
+		cur = idx - 1
+	case cur == idx && sz > 0:
+		cur = 0
+	case cur == idx:
+		cur = -1
+	}
+	fr.StackTop = cur
+	dt.Mu.Unlock()
+	if cur >= 0 {
+		dt.UnselectOtherTabs(cur)
+		tbs.Child(cur).Embed(KiT_TabButton).(*TabButton).SetSelectedState(true)
+	}
+	dt.UpdateEnd(updt)
+}
+
+// DragNDropStart starts dragging the panel at given tab index
+func (dt *DockTabs) DragNDropStart(idx int) {
+	_, tab, ok := dt.TabAtIndex(idx)
+	if !ok {
+		return
+	}
+	dt.dragPanel = dt.Frame().Child(idx).Name()
+	md := mimedata.NewMime(DockPanelMimeType, []byte(dt.dragPanel))
+	sp := &Sprite{}
+	sp.GrabRenderFrom(tab)
+	ImageClearer(sp.Pixels, 50.0)
+	dt.ParentWindow().StartDragNDrop(dt.This(), md, sp)
+}
+
+// DragNDropTarget handles the drop of a dock panel onto us
+func (dt *DockTabs) DragNDropTarget(de *dnd.Event) {
+	src, ok := de.Source.(*DockTabs)
+	if !ok || !de.Data.HasType(DockPanelMimeType) {
+		return
+	}
+	de.Target = dt.This()
+	de.SetProcessed()
+	name := string(de.Data.TypeData(DockPanelMimeType))
+	zone := dt.ZoneAt(de.Where)
+	dt.ParentWindow().FinalizeDragNDrop(dnd.DropMove)
+	idx := src.PanelIndex(name)
+	if idx < 0 {
+		return
+	}
+	dt.DockLayout().MovePanel(src, idx, dt, zone)
+}
+
+// DragNDropUnhandled is called when a dragged panel was not dropped onto
+// any tabs in our window: if it was dropped onto another window of the
+// dock layout it is docked there, and if dropped outside of all of them, it
+// is floated in a new window.
+func (dt *DockTabs) DragNDropUnhandled(win *Window, where image.Point) {
+	if win.OSWin == nil {
+		return
+	}
+	dl := dt.DockLayout()
+	idx := dt.PanelIndex(dt.dragPanel)
+	if idx < 0 {
+		return
+	}
+	spos := dockScreenPos(win, where)
+	for _, fl := range dl.Family() {
+		fwin := fl.ParentWindow()
+		if fwin == nil || fwin == win || fwin.OSWin == nil {
+			continue
+		}
+		if pos, in := dockWinPos(fwin, spos); in {
+			if dst := fl.TabsAt(pos); dst != nil {
+				dl.MovePanel(dt, idx, dst, dst.ZoneAt(pos))
+			}
+			return
+		}
+	}
+	if where.In(image.Rectangle{Max: win.OSWin.Size()}) {
+		return
+	}
+	dl.FloatPanel(dt, idx, spos)
+}
+
+// MakeContextMenu makes the menu of docking actions for the tab where the
+// menu was opened
+func (dt *DockTabs) MakeContextMenu(m *Menu) {
+	dl := dt.DockLayout()
+	idx := dt.ctxtIdx
+	if idx >= 0 {
+		m.AddAction(ActOpts{Label: "Float in Window"}, dt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dtt := recv.Embed(KiT_DockTabs).(*DockTabs)
+			win := dtt.ParentWindow()
+			dtt.DockLayout().FloatPanel(dtt, idx, dockScreenPos(win, dtt.ctxtPos))
+		})
+		if dl.IsFloat() {
+			m.AddAction(ActOpts{Label: "Dock Back"}, dt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				dtt := recv.Embed(KiT_DockTabs).(*DockTabs)
+				dll := dtt.DockLayout()
+				dll.MovePanel(dtt, idx, dll.Home.DefaultTabs(), DockCenter)
+			})
+		}
+		m.AddAction(ActOpts{Label: "Split Right"}, dt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dtt := recv.Embed(KiT_DockTabs).(*DockTabs)
+			dtt.DockLayout().MovePanel(dtt, idx, dtt, DockRight)
+		})
+		m.AddAction(ActOpts{Label: "Split Down"}, dt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dtt := recv.Embed(KiT_DockTabs).(*DockTabs)
+			dtt.DockLayout().MovePanel(dtt, idx, dtt, DockBottom)
+		})
+		m.AddSeparator("sep-ws")
+	}
+	m.AddAction(ActOpts{Label: "Save Workspace..."}, dt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dtt := recv.Embed(KiT_DockTabs).(*DockTabs)
+		StringPromptDialog(dtt.ViewportSafe(), "", "Workspace name", DlgOpts{Title: "Save Workspace", Prompt: "Save the current arrangement of panels as a workspace with given name"}, dtt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(DialogAccepted) {
+				return
+			}
+			nm := StringPromptDialogValue(send.(*Dialog))
+			if nm == "" {
+				return
+			}
+			recv.Embed(KiT_DockTabs).(*DockTabs).DockLayout().HomeDock().SaveWorkspace(nm)
+		})
+	})
+	wsnms := dl.HomeDock().WorkspaceNames()
+	if len(wsnms) > 0 {
+		ac := m.AddAction(ActOpts{Label: "Open Workspace"}, nil, nil)
+		for _, wsnm := range wsnms {
+			nm := wsnm
+			ac.Menu.AddAction(ActOpts{Label: nm}, dt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				recv.Embed(KiT_DockTabs).(*DockTabs).DockLayout().HomeDock().OpenWorkspace(nm)
+			})
+		}
+	}
+}
+
+func (dt *DockTabs) ContextMenuPos() image.Point {
+	return dt.ctxtPos
+}
+
+// DockTabsEvents connects the drag-n-drop and context menu events
+func (dt *DockTabs) DockTabsEvents() {
+	dt.ConnectEvent(oswin.DNDEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.Event)
+		dtt := recv.Embed(KiT_DockTabs).(*DockTabs)
+		switch de.Action {
+		case dnd.Start:
+			if idx := dtt.TabIndexAt(de.Where); idx >= 0 {
+				de.SetProcessed()
+				dtt.DragNDropStart(idx)
+			}
+		case dnd.DropOnTarget:
+			dtt.DragNDropTarget(de)
+		}
+	})
+	dt.ConnectEvent(oswin.DNDMoveEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.MoveEvent)
+		dtt := recv.Embed(KiT_DockTabs).(*DockTabs)
+		win := dtt.ParentWindow()
+		if !win.EventMgr.DNDData.HasType(DockPanelMimeType) {
+			return
+		}
+		win.DNDShowZone(dtt.ZoneBBox(dtt.ZoneAt(de.Where)))
+	})
+	dt.ConnectEvent(oswin.DNDFocusEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.FocusEvent)
+		if de.Action == dnd.Exit {
+			recv.Embed(KiT_DockTabs).(*DockTabs).ParentWindow().DNDShowZone(image.ZR)
+		}
+	})
+	dt.ConnectEvent(oswin.MouseEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		if me.Action != mouse.Release || me.Button != mouse.Right {
+			return
+		}
+		dtt := recv.Embed(KiT_DockTabs).(*DockTabs)
+		if !dtt.Tabs().PosInWinBBox(me.Where) {
+			return
+		}
+		me.SetProcessed()
+		dtt.ctxtIdx = dtt.TabIndexAt(me.Where)
+		dtt.ctxtPos = me.Where
+		dtt.ContextMenu()
+	})
+}
+
+func (dt *DockTabs) ConnectEvents2D() {
+	dt.TabView.ConnectEvents2D()
+	dt.DockTabsEvents()
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+func TestDockFloatWinNameFor(t *testing.T) {
+	nm := DockFloatWinNameFor("dock", "a:b")
+	if nm != "dock-float-dock-a-b:0" {
+		t.Errorf("DockFloatWinNameFor = %v, expected dock-float-dock-a-b:0", nm)
+	}
+	// the part used by WinGeomPrefs is the same for each float of a panel
+	if cls := nm[:strings.Index(nm, ":")]; cls != "dock-float-dock-a-b" {
+		t.Errorf("DockFloatWinNameFor class = %v", cls)
+	}
+	if nm2 := DockFloatWinNameFor("dock", "c"); nm2 == nm || !strings.HasSuffix(nm2, ":0") {
+		t.Errorf("DockFloatWinNameFor other panel = %v", nm2)
+	}
+}
+
+func TestDockTabsZones(t *testing.T) {
+	dt := &DockTabs{}
+	dt.InitName(dt, "tabs")
+	if z := dt.ZoneAt(image.Point{10, 10}); z != DockCenter {
+		t.Errorf("ZoneAt with no size = %v, expected center", z)
+	}
+	dt.WinBBox = image.Rect(100, 100, 300, 200)
+	tests := []struct {
+		pos  image.Point
+		zone DockZones
+	}{
+		{image.Point{200, 150}, DockCenter},
+		{image.Point{110, 150}, DockLeft},
+		{image.Point{290, 150}, DockRight},
+		{image.Point{200, 110}, DockTop},
+		{image.Point{200, 190}, DockBottom},
+		{image.Point{120, 105}, DockTop}, // nearest edge in proportion
+		{image.Point{140, 170}, DockLeft},
+	}
+	for _, tst := range tests {
+		if z := dt.ZoneAt(tst.pos); z != tst.zone {
+			t.Errorf("ZoneAt(%v) = %v, expected %v", tst.pos, z, tst.zone)
+		}
+	}
+	bbs := map[DockZones]image.Rectangle{
+		DockCenter: image.Rect(100, 100, 300, 200),
+		DockLeft:   image.Rect(100, 100, 200, 200),
+		DockRight:  image.Rect(200, 100, 300, 200),
+		DockTop:    image.Rect(100, 100, 300, 150),
+		DockBottom: image.Rect(100, 150, 300, 200),
+	}
+	for zone, xbb := range bbs {
+		if bb := dt.ZoneBBox(zone); bb != xbb {
+			t.Errorf("ZoneBBox(%v) = %v, expected %v", zone, bb, xbb)
+		}
+	}
+}
+
+// dockTestPanel adds a new panel of given name to given tabs -- only the
+// panel itself, as AddTab configures a tab button with icons
+func dockTestPanel(dt *DockTabs, name string) {
+	lb := &Label{}
+	lb.InitName(lb, name)
+	dt.Frame().AddChild(lb)
+}
+
+func TestDockLayoutSplit(t *testing.T) {
+	dl := &DockLayout{}
+	dl.InitName(dl, "dock")
+	root := dl.DefaultTabs()
+	dockTestPanel(root, "a")
+	dockTestPanel(root, "b")
+	if dt, idx := dl.FindPanel("b"); dt != root || idx != 1 {
+		t.Errorf("FindPanel(b) = %v, %v, expected root tabs, 1", dt, idx)
+	}
+	if dt, idx := dl.FindPanel("x"); dt != nil || idx != -1 {
+		t.Errorf("FindPanel(x) = %v, %v, expected nil, -1", dt, idx)
+	}
+
+	// splitting root tabs replaces them with a split view
+	right := dl.SplitTabs(root, DockRight)
+	dockTestPanel(right, "c")
+	// splitting in the same direction adds to the split view
+	left := dl.SplitTabs(root, DockLeft)
+	dockTestPanel(left, "d")
+	// splitting in the other direction nests a new split view
+	bot := dl.SplitTabs(right, DockBottom)
+	dockTestPanel(bot, "e")
+
+	tl := dl.AllTabs()
+	if !reflect.DeepEqual(tl, []*DockTabs{left, root, right, bot}) {
+		t.Errorf("AllTabs: got %v tabs, expected left, root, right, bottom", len(tl))
+	}
+	st := dl.State()
+	rt := st.Root
+	if rt.Dim != mat32.X || len(rt.Kids) != 3 || len(rt.Splits) != 3 {
+		t.Fatalf("State: root dim %v, %v kids, splits %v, expected X, 3, 3", rt.Dim, len(rt.Kids), rt.Splits)
+	}
+	if xs := []float32{0.25, 0.25, 0.5}; !reflect.DeepEqual(rt.Splits, xs) {
+		t.Errorf("State: root splits %v, expected %v", rt.Splits, xs)
+	}
+	if !reflect.DeepEqual(rt.Kids[0].Panels, []string{"d"}) || !reflect.DeepEqual(rt.Kids[1].Panels, []string{"a", "b"}) {
+		t.Errorf("State: root kids panels %v, %v, expected [d], [a b]", rt.Kids[0].Panels, rt.Kids[1].Panels)
+	}
+	nest := rt.Kids[2]
+	if nest.Dim != mat32.Y || len(nest.Kids) != 2 || !reflect.DeepEqual(nest.Kids[1].Panels, []string{"e"}) {
+		t.Errorf("State: nested split dim %v, kids %v, expected Y with [c], [e]", nest.Dim, len(nest.Kids))
+	}
+	if fp := rt.firstPanel(); fp != "d" {
+		t.Errorf("firstPanel = %v, expected d", fp)
+	}
+	if fp := nest.firstPanel(); fp != "c" {
+		t.Errorf("nested firstPanel = %v, expected c", fp)
+	}
+
+	// removing the last panel of a tabs prunes it and collapses the split
+	bot.Frame().DeleteChildAtIndex(0, ki.DestroyKids)
+	dl.prune(bot)
+	tl = dl.AllTabs()
+	if !reflect.DeepEqual(tl, []*DockTabs{left, root, right}) {
+		t.Errorf("prune: got %v tabs, expected left, root, right", len(tl))
+	}
+	if sv, ok := right.Parent().(*SplitView); !ok || sv.Dim != mat32.X || sv.Parent() != dl.This() {
+		t.Errorf("prune: nested split view not collapsed into the root split")
+	}
+	dl.prune(root) // not empty: nothing happens
+	if len(dl.AllTabs()) != 3 {
+		t.Errorf("prune of non-empty tabs removed them")
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// DockNode records the state of one node in the docking tree of a
+// DockLayout: either a split, which has Kids, or a set of tabs, which has
+// Panels.
+type DockNode struct {
+	Dim    mat32.Dims  `desc:"for a split, the dimension along which it is split"`
+	Splits []float32   `desc:"for a split, the proportion of space allocated to each of the kids"`
+	Kids   []*DockNode `desc:"for a split, the nodes within it"`
+	Panels []string    `desc:"for tabs, the names of the panels, in tab order"`
+	Cur    int         `desc:"for tabs, the index of the selected tab"`
+}
+
+// DockFloat records the state of a floating DockLayout in its own window
+type DockFloat struct {
+	Geom WindowGeom `desc:"geometry of the window"`
+	Root *DockNode  `desc:"the docking tree within the window"`
+}
+
+// DockState records the full arrangement of panels in a DockLayout,
+// including its floating windows -- it is what is saved as a workspace.
+type DockState struct {
+	Root   *DockNode    `desc:"the docking tree of the home layout"`
+	Floats []*DockFloat `desc:"the floating windows"`
+}
+
+// dockNodeState returns the state of given node in the docking tree
+func dockNodeState(k ki.Ki) *DockNode {
+	nd := &DockNode{}
+	switch kn := k.(type) {
+	case *DockTabs:
+		fr := kn.Frame()
+		for _, pk := range fr.Kids {
+			nd.Panels = append(nd.Panels, pk.Name())
+		}
+		nd.Cur = fr.StackTop
+	case *SplitView:
+		kn.UpdateSplits()
+		nd.Dim = kn.Dim
+		nd.Splits = append(nd.Splits, kn.Splits...)
+		for _, kid := range kn.Kids {
+			nd.Kids = append(nd.Kids, dockNodeState(kid))
+		}
+	}
+	return nd
+}
+
+// firstPanel returns the name of the first panel in given docking tree
+func (nd *DockNode) firstPanel() string {
+	if len(nd.Panels) > 0 {
+		return nd.Panels[0]
+	}
+	for _, kd := range nd.Kids {
+		if pnm := kd.firstPanel(); pnm != "" {
+			return pnm
+		}
+	}
+	return ""
+}
+
+// State returns the current arrangement of all the panels in the family of
+// layouts, including floating windows
+func (dl *DockLayout) State() *DockState {
+	home := dl.HomeDock()
+	st := &DockState{Root: dockNodeState(home.DockRoot())}
+	for _, fl := range home.Floats {
+		df := &DockFloat{Root: dockNodeState(fl.DockRoot())}
+		if win := fl.ParentWindow(); win != nil && win.OSWin != nil {
+			sc := win.OSWin.Screen()
+			df.Geom = WindowGeom{DPI: win.LogicalDPI(), DPR: sc.DevicePixelRatio}
+			df.Geom.SetPos(win.OSWin.Position())
+			df.Geom.SetSize(win.OSWin.WinSize())
+		}
+		st.Floats = append(st.Floats, df)
+	}
+	return st
+}
+
+// dockPanel is a panel collected for rearranging by SetState
+type dockPanel struct {
+	widg  Node2D
+	label string
+}
+
+// collectPanels returns all the panels in the family of layouts, by name,
+// and the names in their current order -- panels in floats are disconnected
+// from their window, and float window updates are blocked, as they are about
+// to be closed
+func (dl *DockLayout) collectPanels() (map[string]*dockPanel, []string) {
+	pm := make(map[string]*dockPanel)
+	var order []string
+	for _, fl := range dl.Family() {
+		cross := fl.IsFloat()
+		if cross {
+			fl.ViewportSafe().BlockUpdates()
+		}
+		for _, dt := range fl.AllTabs() {
+			fr := dt.Frame()
+			tbs := dt.Tabs()
+			for i, pk := range fr.Kids {
+				widg := pk.(Node2D)
+				if cross {
+					widg.AsNode2D().DisconnectAllEvents(AllPris)
+				}
+				tab := tbs.Child(i).Embed(KiT_TabButton).(*TabButton)
+				pm[pk.Name()] = &dockPanel{widg: widg, label: tab.Text}
+				order = append(order, pk.Name())
+			}
+		}
+	}
+	return pm, order
+}
+
+// buildDockNode builds given docking tree node at given index in given
+// parent, using (and removing) the panels from given map -- nodes without
+// any existing panels are skipped, and returns false if nothing was built.
+func (dl *DockLayout) buildDockNode(par ki.Ki, idx int, nd *DockNode, pm map[string]*dockPanel) bool {
+	if len(nd.Kids) > 0 {
+		sv := par.InsertNewChild(KiT_SplitView, idx, newDockName("split")).(*SplitView)
+		sv.Dim = nd.Dim
+		var splits []float32
+		for i, kd := range nd.Kids {
+			if !dl.buildDockNode(sv, len(sv.Kids), kd, pm) {
+				continue
+			}
+			sp := float32(0)
+			if i < len(nd.Splits) {
+				sp = nd.Splits[i]
+			}
+			splits = append(splits, sp)
+		}
+		switch len(sv.Kids) {
+		case 0:
+			par.DeleteChild(sv, ki.DestroyKids)
+			return false
+		case 1:
+			par.InsertChild(sv.Child(0), idx)
+			par.DeleteChild(sv, ki.DestroyKids)
+			return true
+		}
+		sv.SetSplits(splits...)
+		return true
+	}
+	var dt *DockTabs
+	for _, pnm := range nd.Panels {
+		p, ok := pm[pnm]
+		if !ok {
+			continue
+		}
+		delete(pm, pnm)
+		if dt == nil {
+			dt = dl.newTabs(par, idx)
+		}
+		dt.AddTab(p.widg, p.label)
+	}
+	if dt == nil {
+		return false
+	}
+	if nd.Cur >= 0 && nd.Cur < dt.NTabs() {
+		dt.SelectTabIndex(nd.Cur)
+	}
+	return true
+}
+
+// SetState rearranges all the panels in the family of layouts according to
+// given state, replacing all existing floating windows with those in the
+// state.  Panels that are not in the state go into the default tabs, and
+// panels in the state that do not exist are ignored.
+func (dl *DockLayout) SetState(st *DockState) {
+	home := dl.HomeDock()
+	hvp := home.ViewportSafe()
+	hvp.BlockUpdates()
+	pm, order := home.collectPanels()
+	oldFloats := home.Floats
+	home.Floats = nil
+
+	old := home.DockRoot()
+	if st.Root == nil || !home.buildDockNode(home, 0, st.Root, pm) {
+		home.newTabs(home, 0)
+	}
+
+	var wins []*Window
+	for _, df := range st.Floats {
+		if df.Root == nil {
+			continue
+		}
+		panel := df.Root.firstPanel()
+		title := panel
+		if p, ok := pm[panel]; ok {
+			title = p.label
+		}
+		fl, win := home.NewFloat(title, panel, df.Geom.Pos(), df.Geom.Size())
+		if fl == nil {
+			continue
+		}
+		froot := fl.DockRoot()
+		if !fl.buildDockNode(fl, 0, df.Root, pm) {
+			home.removeFloat(fl)
+			win.Close()
+			continue
+		}
+		fl.DeleteChild(froot, ki.DestroyKids)
+		wins = append(wins, win)
+	}
+
+	dt := home.DefaultTabs()
+	for _, pnm := range order {
+		if p, ok := pm[pnm]; ok {
+			dt.AddTab(p.widg, p.label)
+		}
+	}
+	// only now that all the panels have been moved out of it
+	home.DeleteChild(old, ki.DestroyKids)
+	hvp.UnblockUpdates()
+
+	for _, fl := range oldFloats {
+		if win := fl.ParentWindow(); win != nil && win.OSWin != nil {
+			win.Close()
+		}
+	}
+	for _, win := range wins {
+		win.GoStartEventLoop()
+	}
+	hvp.SetNeedsFullRender()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+// Workspaces
+
+// DockWorkspaces records the saved workspaces of dock layouts, by
+// DockLayout.WorkspaceKey and then by workspace name -- saved persistently
+// in the GoGi prefs directory
+type DockWorkspaces map[string]map[string]*DockState
+
+// DockWorkspacePrefs are the saved workspaces for all dock layouts
+var DockWorkspacePrefs = DockWorkspaces{}
+
+// DockWorkspacesFileName is the base name of the workspaces file in GoGi prefs directory
+var DockWorkspacesFileName = "dock_workspaces"
+
+// DockWorkspacesMu is read-write mutex that protects updating of DockWorkspacePrefs
+var DockWorkspacesMu sync.RWMutex
+
+// Open dock workspaces from GoGi standard prefs directory
+// called under mutex or at start
+func (dw *DockWorkspaces) Open() error {
+	if *dw == nil {
+		*dw = make(DockWorkspaces)
+	}
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, DockWorkspacesFileName+".json")
+	b, err := ioutil.ReadFile(pnm)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(b, dw)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// Save dock workspaces to GoGi standard prefs directory
+// assumed to be under mutex
+func (dw *DockWorkspaces) Save() error {
+	if *dw == nil {
+		return nil
+	}
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, DockWorkspacesFileName+".json")
+	b, err := json.MarshalIndent(dw, "", "\t")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(pnm, b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// WorkspaceKey returns the key for the workspaces of this layout in
+// DockWorkspacePrefs: the class of the window (part of its name prior to
+// any colon, as in WinGeomPrefs) and the name of the home layout
+func (dl *DockLayout) WorkspaceKey() string {
+	home := dl.HomeDock()
+	win := home.ParentWindow()
+	if win == nil {
+		return home.Nm
+	}
+	winName := win.Nm
+	if ci := strings.Index(winName, ":"); ci > 0 {
+		winName = winName[:ci]
+	}
+	return winName + ":" + home.Nm
+}
+
+// SaveWorkspace saves the current arrangement of panels as a workspace with
+// given name, replacing any existing one of that name
+func (dl *DockLayout) SaveWorkspace(name string) error {
+	st := dl.State()
+	key := dl.WorkspaceKey()
+	DockWorkspacesMu.Lock()
+	defer DockWorkspacesMu.Unlock()
+	DockWorkspacePrefs.Open() // get any saved by other apps
+	if DockWorkspacePrefs[key] == nil {
+		DockWorkspacePrefs[key] = make(map[string]*DockState)
+	}
+	DockWorkspacePrefs[key][name] = st
+	return DockWorkspacePrefs.Save()
+}
+
+// OpenWorkspace rearranges the panels according to saved workspace of given name
+func (dl *DockLayout) OpenWorkspace(name string) error {
+	DockWorkspacesMu.RLock()
+	st, ok := DockWorkspacePrefs[dl.WorkspaceKey()][name]
+	DockWorkspacesMu.RUnlock()
+	if !ok {
+		err := fmt.Errorf("gi.DockLayout OpenWorkspace: workspace named: %v not found", name)
+		log.Println(err)
+		return err
+	}
+	dl.SetState(st)
+	return nil
+}
+
+// DeleteWorkspace deletes the saved workspace of given name
+func (dl *DockLayout) DeleteWorkspace(name string) error {
+	key := dl.WorkspaceKey()
+	DockWorkspacesMu.Lock()
+	defer DockWorkspacesMu.Unlock()
+	DockWorkspacePrefs.Open()
+	if _, ok := DockWorkspacePrefs[key][name]; !ok {
+		err := fmt.Errorf("gi.DockLayout DeleteWorkspace: workspace named: %v not found", name)
+		log.Println(err)
+		return err
+	}
+	delete(DockWorkspacePrefs[key], name)
+	return DockWorkspacePrefs.Save()
+}
+
+// WorkspaceNames returns the sorted names of the saved workspaces for this layout
+func (dl *DockLayout) WorkspaceNames() []string {
+	DockWorkspacesMu.RLock()
+	defer DockWorkspacesMu.RUnlock()
+	wss := DockWorkspacePrefs[dl.WorkspaceKey()]
+	nms := make([]string, 0, len(wss))
+	for nm := range wss {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return nms
+}
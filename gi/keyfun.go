@@ -88,6 +88,12 @@ const (
 	KeyFunWinClose
 	KeyFunWinSnapshot
 	KeyFunGoGiEditor
+	KeyFunAddCursorNext  // add a cursor at the next occurrence of the selection or word
+	KeyFunAddCursorLines // add a cursor at the end of each line in the selection
+	KeyFunFoldToggle     // fold / unfold the region at the cursor
+	KeyFunFoldAll
+	KeyFunUnfoldAll
+	KeyFunCommandPalette // search and run any action or method in the window
 	// Below are menu specific functions -- use these as shortcuts for menu actions
 	// allows uniqueness of mapping and easy customization of all key actions
 	KeyFunMenuNew
@@ -599,6 +605,12 @@ var StdKeyMaps = KeyMaps{
 		"Shift+Control+G":         KeyFunWinSnapshot,
 		"Control+Alt+I":           KeyFunGoGiEditor,
 		"Shift+Control+I":         KeyFunGoGiEditor,
+		"Shift+Meta+D":            KeyFunAddCursorNext,
+		"Shift+Alt+I":             KeyFunAddCursorLines,
+		"Shift+Alt+F":             KeyFunFoldToggle,
+		"Shift+Alt+A":             KeyFunFoldAll,
+		"Shift+Alt+U":             KeyFunUnfoldAll,
+		"Shift+Meta+P":            KeyFunCommandPalette,
 		"Meta+N":                  KeyFunMenuNew,
 		"Shift+Meta+N":            KeyFunMenuNewAlt1,
 		"Alt+Meta+N":              KeyFunMenuNewAlt2,
@@ -733,6 +745,12 @@ var StdKeyMaps = KeyMaps{
 		"Shift+Control+G":         KeyFunWinSnapshot,
 		"Control+Alt+I":           KeyFunGoGiEditor,
 		"Shift+Control+I":         KeyFunGoGiEditor,
+		"Shift+Meta+D":            KeyFunAddCursorNext,
+		"Shift+Alt+I":             KeyFunAddCursorLines,
+		"Shift+Alt+F":             KeyFunFoldToggle,
+		"Shift+Alt+A":             KeyFunFoldAll,
+		"Shift+Alt+U":             KeyFunUnfoldAll,
+		"Shift+Meta+P":            KeyFunCommandPalette,
 		"Meta+N":                  KeyFunMenuNew,
 		"Shift+Meta+N":            KeyFunMenuNewAlt1,
 		"Alt+Meta+N":              KeyFunMenuNewAlt2,
@@ -852,6 +870,12 @@ var StdKeyMaps = KeyMaps{
 		"Shift+Control+G":         KeyFunWinSnapshot,
 		"Control+Alt+I":           KeyFunGoGiEditor,
 		"Shift+Control+I":         KeyFunGoGiEditor,
+		"Shift+Control+D":         KeyFunAddCursorNext,
+		"Shift+Alt+I":             KeyFunAddCursorLines,
+		"Shift+Alt+F":             KeyFunFoldToggle,
+		"Shift+Alt+A":             KeyFunFoldAll,
+		"Shift+Alt+U":             KeyFunUnfoldAll,
+		"Shift+Alt+P":             KeyFunCommandPalette,
 		"Alt+N":                   KeyFunMenuNew, // ctrl keys conflict..
 		"Shift+Alt+N":             KeyFunMenuNewAlt1,
 		"Control+Alt+N":           KeyFunMenuNewAlt2,
@@ -941,6 +965,12 @@ var StdKeyMaps = KeyMaps{
 		"Control+Alt+G":           KeyFunWinSnapshot,
 		"Shift+Control+G":         KeyFunWinSnapshot,
 		"Shift+Control+I":         KeyFunGoGiEditor,
+		"Shift+Control+D":         KeyFunAddCursorNext,
+		"Shift+Alt+I":             KeyFunAddCursorLines,
+		"Shift+Alt+F":             KeyFunFoldToggle,
+		"Shift+Alt+A":             KeyFunFoldAll,
+		"Shift+Alt+U":             KeyFunUnfoldAll,
+		"Shift+Alt+P":             KeyFunCommandPalette,
 		"Shift+Control+N":         KeyFunMenuNewAlt1,
 		"Control+Alt+N":           KeyFunMenuNewAlt2,
 		"Control+O":               KeyFunMenuOpen,
@@ -1028,6 +1058,12 @@ var StdKeyMaps = KeyMaps{
 		"Control+Alt+G":           KeyFunWinSnapshot,
 		"Shift+Control+G":         KeyFunWinSnapshot,
 		"Shift+Control+I":         KeyFunGoGiEditor,
+		"Shift+Control+D":         KeyFunAddCursorNext,
+		"Shift+Alt+I":             KeyFunAddCursorLines,
+		"Shift+Alt+F":             KeyFunFoldToggle,
+		"Shift+Alt+A":             KeyFunFoldAll,
+		"Shift+Alt+U":             KeyFunUnfoldAll,
+		"Shift+Alt+P":             KeyFunCommandPalette,
 		"Control+N":               KeyFunMenuNew,
 		"Shift+Control+N":         KeyFunMenuNewAlt1,
 		"Control+Alt+N":           KeyFunMenuNewAlt2,
@@ -1116,6 +1152,12 @@ var StdKeyMaps = KeyMaps{
 		"Control+Alt+G":           KeyFunWinSnapshot,
 		"Shift+Control+G":         KeyFunWinSnapshot,
 		"Shift+Control+I":         KeyFunGoGiEditor,
+		"Shift+Control+D":         KeyFunAddCursorNext,
+		"Shift+Alt+I":             KeyFunAddCursorLines,
+		"Shift+Alt+F":             KeyFunFoldToggle,
+		"Shift+Alt+A":             KeyFunFoldAll,
+		"Shift+Alt+U":             KeyFunUnfoldAll,
+		"Shift+Alt+P":             KeyFunCommandPalette,
 		"Control+N":               KeyFunMenuNew,
 		"Shift+Control+N":         KeyFunMenuNewAlt1,
 		"Control+Alt+N":           KeyFunMenuNewAlt2,
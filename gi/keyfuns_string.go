@@ -68,23 +68,29 @@ func _() {
 	_ = x[KeyFunWinClose-52]
 	_ = x[KeyFunWinSnapshot-53]
 	_ = x[KeyFunGoGiEditor-54]
-	_ = x[KeyFunMenuNew-55]
-	_ = x[KeyFunMenuNewAlt1-56]
-	_ = x[KeyFunMenuNewAlt2-57]
-	_ = x[KeyFunMenuOpen-58]
-	_ = x[KeyFunMenuOpenAlt1-59]
-	_ = x[KeyFunMenuOpenAlt2-60]
-	_ = x[KeyFunMenuSave-61]
-	_ = x[KeyFunMenuSaveAs-62]
-	_ = x[KeyFunMenuSaveAlt-63]
-	_ = x[KeyFunMenuCloseAlt1-64]
-	_ = x[KeyFunMenuCloseAlt2-65]
-	_ = x[KeyFunsN-66]
+	_ = x[KeyFunAddCursorNext-55]
+	_ = x[KeyFunAddCursorLines-56]
+	_ = x[KeyFunFoldToggle-57]
+	_ = x[KeyFunFoldAll-58]
+	_ = x[KeyFunUnfoldAll-59]
+	_ = x[KeyFunCommandPalette-60]
+	_ = x[KeyFunMenuNew-61]
+	_ = x[KeyFunMenuNewAlt1-62]
+	_ = x[KeyFunMenuNewAlt2-63]
+	_ = x[KeyFunMenuOpen-64]
+	_ = x[KeyFunMenuOpenAlt1-65]
+	_ = x[KeyFunMenuOpenAlt2-66]
+	_ = x[KeyFunMenuSave-67]
+	_ = x[KeyFunMenuSaveAs-68]
+	_ = x[KeyFunMenuSaveAlt-69]
+	_ = x[KeyFunMenuCloseAlt1-70]
+	_ = x[KeyFunMenuCloseAlt2-71]
+	_ = x[KeyFunsN-72]
 }
 
-const _KeyFuns_name = "KeyFunNilKeyFunMoveUpKeyFunMoveDownKeyFunMoveRightKeyFunMoveLeftKeyFunPageUpKeyFunPageDownKeyFunHomeKeyFunEndKeyFunDocHomeKeyFunDocEndKeyFunWordRightKeyFunWordLeftKeyFunFocusNextKeyFunFocusPrevKeyFunEnterKeyFunAcceptKeyFunCancelSelectKeyFunSelectModeKeyFunSelectAllKeyFunAbortKeyFunCopyKeyFunCutKeyFunPasteKeyFunPasteHistKeyFunBackspaceKeyFunBackspaceWordKeyFunDeleteKeyFunDeleteWordKeyFunKillKeyFunDuplicateKeyFunTransposeKeyFunTransposeWordKeyFunUndoKeyFunRedoKeyFunInsertKeyFunInsertAfterKeyFunZoomOutKeyFunZoomInKeyFunPrefsKeyFunRefreshKeyFunRecenterKeyFunCompleteKeyFunLookupKeyFunSearchKeyFunFindKeyFunReplaceKeyFunJumpKeyFunHistPrevKeyFunHistNextKeyFunMenuKeyFunWinFocusNextKeyFunWinCloseKeyFunWinSnapshotKeyFunGoGiEditorKeyFunMenuNewKeyFunMenuNewAlt1KeyFunMenuNewAlt2KeyFunMenuOpenKeyFunMenuOpenAlt1KeyFunMenuOpenAlt2KeyFunMenuSaveKeyFunMenuSaveAsKeyFunMenuSaveAltKeyFunMenuCloseAlt1KeyFunMenuCloseAlt2KeyFunsN"
+const _KeyFuns_name = "KeyFunNilKeyFunMoveUpKeyFunMoveDownKeyFunMoveRightKeyFunMoveLeftKeyFunPageUpKeyFunPageDownKeyFunHomeKeyFunEndKeyFunDocHomeKeyFunDocEndKeyFunWordRightKeyFunWordLeftKeyFunFocusNextKeyFunFocusPrevKeyFunEnterKeyFunAcceptKeyFunCancelSelectKeyFunSelectModeKeyFunSelectAllKeyFunAbortKeyFunCopyKeyFunCutKeyFunPasteKeyFunPasteHistKeyFunBackspaceKeyFunBackspaceWordKeyFunDeleteKeyFunDeleteWordKeyFunKillKeyFunDuplicateKeyFunTransposeKeyFunTransposeWordKeyFunUndoKeyFunRedoKeyFunInsertKeyFunInsertAfterKeyFunZoomOutKeyFunZoomInKeyFunPrefsKeyFunRefreshKeyFunRecenterKeyFunCompleteKeyFunLookupKeyFunSearchKeyFunFindKeyFunReplaceKeyFunJumpKeyFunHistPrevKeyFunHistNextKeyFunMenuKeyFunWinFocusNextKeyFunWinCloseKeyFunWinSnapshotKeyFunGoGiEditorKeyFunAddCursorNextKeyFunAddCursorLinesKeyFunFoldToggleKeyFunFoldAllKeyFunUnfoldAllKeyFunCommandPaletteKeyFunMenuNewKeyFunMenuNewAlt1KeyFunMenuNewAlt2KeyFunMenuOpenKeyFunMenuOpenAlt1KeyFunMenuOpenAlt2KeyFunMenuSaveKeyFunMenuSaveAsKeyFunMenuSaveAltKeyFunMenuCloseAlt1KeyFunMenuCloseAlt2KeyFunsN"
 
-var _KeyFuns_index = [...]uint16{0, 9, 21, 35, 50, 64, 76, 90, 100, 109, 122, 134, 149, 163, 178, 193, 204, 216, 234, 250, 265, 276, 286, 295, 306, 321, 336, 355, 367, 383, 393, 408, 423, 442, 452, 462, 474, 491, 504, 516, 527, 540, 554, 568, 580, 592, 602, 615, 625, 639, 653, 663, 681, 695, 712, 728, 741, 758, 775, 789, 807, 825, 839, 855, 872, 891, 910, 918}
+var _KeyFuns_index = [...]uint16{0, 9, 21, 35, 50, 64, 76, 90, 100, 109, 122, 134, 149, 163, 178, 193, 204, 216, 234, 250, 265, 276, 286, 295, 306, 321, 336, 355, 367, 383, 393, 408, 423, 442, 452, 462, 474, 491, 504, 516, 527, 540, 554, 568, 580, 592, 602, 615, 625, 639, 653, 663, 681, 695, 712, 728, 747, 767, 783, 796, 811, 831, 844, 861, 878, 892, 910, 928, 942, 958, 975, 994, 1013, 1021}
 
 func (i KeyFuns) String() string {
 	if i < 0 || i >= KeyFuns(len(_KeyFuns_index)-1) {
@@ -14,3 +14,16 @@ type Updater interface {
 	// everything assuming anything could have changed.
 	Update()
 }
+
+// Undoer defines an interface for an undo manager that can be installed in a
+// Window (see Window.Undoer), which is then called for the KeyFunUndo and
+// KeyFunRedo key functions when they are not otherwise processed, e.g., by a
+// focused TextView that has its own undo.  giv.ValueUndo implements this
+// for edits made in struct, slice, map and tree views.
+type Undoer interface {
+	// Undo undoes the last edit, returning false if there was nothing to undo
+	Undo() bool
+
+	// Redo redoes the last undone edit, returning false if nothing to redo
+	Redo() bool
+}
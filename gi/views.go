@@ -51,6 +51,10 @@ type ViewIFace interface {
 
 	// PrefsDbgView opens an interactive view of given debugging preferences object
 	PrefsDbgView(prefs *PrefsDebug)
+
+	// CommandPalette opens a searchable list of all the actions, shortcuts
+	// and methods available in given window, and runs the one chosen
+	CommandPalette(win *Window)
 }
 
 // TheViewIFace is the implementation of the interface, defined in giv package
@@ -155,6 +155,7 @@ type Window struct {
 	PopupFocus        ki.Ki             `json:"-" xml:"-" desc:"node to focus on when next popup is activated -- use SetNextPopup"`
 	DelPopup          ki.Ki             `json:"-" xml:"-" desc:"this popup will be popped at the end of the current event cycle -- use SetDelPopup"`
 	PopMu             sync.RWMutex      `json:"-" xml:"-" view:"-" desc:"read-write mutex that protects popup updating and access"`
+	Undoer            Undoer            `json:"-" xml:"-" view:"-" desc:"optional undo manager for this window, called for Undo / Redo key functions that are not otherwise processed (e.g., giv.ValueUndo)"`
 	lastWinMenuUpdate time.Time
 	// below are internal vars used during the event loop
 	delPop        bool
@@ -832,6 +833,7 @@ func Init() {
 		TheViewIFace.HiStyleInit()
 		WinGeomPrefs.NeedToReload() // gets time stamp associated with open, so it doesn't re-open
 		WinGeomPrefs.Open()
+		DockWorkspacePrefs.Open()
 	}
 }
 
@@ -2144,6 +2146,9 @@ func (w *Window) KeyChordEventLowPri(e *key.ChordEvent) bool {
 		SaveImage(fnm, w.Viewport.Pixels)
 		fmt.Printf("Saved Window Image to: %s\n", fnm)
 		e.SetProcessed()
+	case KeyFunCommandPalette:
+		TheViewIFace.CommandPalette(w)
+		e.SetProcessed()
 	case KeyFunZoomIn:
 		w.ZoomDPI(1)
 		e.SetProcessed()
@@ -2159,6 +2164,14 @@ func (w *Window) KeyChordEventLowPri(e *key.ChordEvent) bool {
 	case KeyFunWinFocusNext:
 		e.SetProcessed()
 		AllWindows.FocusNext()
+	case KeyFunUndo:
+		if w.Undoer != nil && w.Undoer.Undo() {
+			e.SetProcessed()
+		}
+	case KeyFunRedo:
+		if w.Undoer != nil && w.Undoer.Redo() {
+			e.SetProcessed()
+		}
 	}
 	switch cs { // some other random special codes, during dev..
 	case "Control+Alt+R":
@@ -2245,6 +2258,10 @@ func WindowInFocus() *Window {
 
 const DNDSpriteName = "gi.Window:DNDSprite"
 
+// DNDZoneSpriteName is the name of the sprite that highlights the drop zone
+// of a drag-n-drop, shown by DNDShowZone
+const DNDZoneSpriteName = "gi.Window:DNDZoneSprite"
+
 // StartDragNDrop is called by a node to start a drag-n-drop operation on
 // given source node, which is responsible for providing the data and Sprite
 // representation of the node.
@@ -2275,11 +2292,17 @@ func (w *Window) DNDMoveEvent(e *mouse.DragEvent) {
 	e.SetProcessed()
 }
 
-// DNDDropEvent handles drag-n-drop drop event (action = release).
+// DNDDropEvent handles drag-n-drop drop event (action = release).  If no
+// target processes the drop, a source implementing DragNDropUnhandler is
+// told about it, after the drag-n-drop is cleared.
 func (w *Window) DNDDropEvent(e *mouse.Event) {
+	src := w.EventMgr.DNDSource
 	proc := w.EventMgr.SendDNDDropEvent(e)
 	if !proc {
 		w.ClearDragNDrop()
+		if un, ok := src.(DragNDropUnhandler); ok {
+			un.DragNDropUnhandled(w, e.Where)
+		}
 	}
 }
 
@@ -2309,10 +2332,35 @@ func (w *Window) FinalizeDragNDrop(action dnd.DropMods) {
 func (w *Window) ClearDragNDrop() {
 	w.EventMgr.ClearDND()
 	w.DeleteSprite(DNDSpriteName)
+	w.DeleteSprite(DNDZoneSpriteName)
 	w.DNDClearCursor()
 	w.RenderOverlays()
 }
 
+// DNDShowZone highlights given region of the window (in window coordinates)
+// as the place where the current drag-n-drop will be dropped -- an empty
+// region hides the highlight.  It is automatically hidden when the
+// drag-n-drop is cleared.
+func (w *Window) DNDShowZone(r image.Rectangle) {
+	if r.Empty() {
+		if w.DeleteSprite(DNDZoneSpriteName) {
+			w.RenderOverlays()
+		}
+		return
+	}
+	sp, ok := w.SpriteByName(DNDZoneSpriteName)
+	if ok && sp.Geom.Pos == r.Min && sp.Geom.Size == r.Size() {
+		return
+	}
+	sp = w.AddNewSprite(DNDZoneSpriteName, r.Size(), r.Min)
+	sp.Resize(r.Size())
+	sp.Geom.Pos = r.Min
+	clr := Prefs.Colors.Select.Clearer(50)
+	draw.Draw(sp.Pixels, sp.Pixels.Bounds(), &image.Uniform{clr}, image.ZP, draw.Src)
+	w.ActivateSprite(DNDZoneSpriteName)
+	w.RenderOverlays()
+}
+
 // DNDModCursor gets the appropriate cursor based on the DND event mod.
 func DNDModCursor(dmod dnd.DropMods) cursor.Shapes {
 	switch dmod {
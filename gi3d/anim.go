@@ -0,0 +1,557 @@
+// Copyright (c) 2019, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// AnimTargets are the properties of a node that an AnimTrack can animate
+type AnimTargets int
+
+const (
+	// AnimPos animates the Pose.Pos position (3 values per key)
+	AnimPos AnimTargets = iota
+
+	// AnimQuat animates the Pose.Quat rotation (4 values per key: X, Y, Z, W)
+	AnimQuat
+
+	// AnimScale animates the Pose.Scale scaling (3 values per key)
+	AnimScale
+
+	// AnimColor animates the Material Color of a Solid (4 values per key:
+	// R, G, B, A in 0-1 range)
+	AnimColor
+
+	AnimTargetsN
+)
+
+//go:generate stringer -type=AnimTargets
+
+var KiT_AnimTargets = kit.Enums.AddEnum(AnimTargetsN, kit.NotBitFlag, nil)
+
+// NVals returns the number of values per key for given target
+func (at AnimTargets) NVals() int {
+	switch at {
+	case AnimQuat, AnimColor:
+		return 4
+	}
+	return 3
+}
+
+// AnimInterps are the ways of interpolating between keyframes
+type AnimInterps int
+
+const (
+	// AnimLinear interpolates linearly between keys -- rotations use
+	// spherical linear interpolation (slerp)
+	AnimLinear AnimInterps = iota
+
+	// AnimStep holds the value of each key constant until the next key
+	AnimStep
+
+	// AnimCubic uses cubic Hermite spline interpolation, using the InTans
+	// and OutTans tangents if present, or else Catmull-Rom tangents
+	// computed from the neighboring keys
+	AnimCubic
+
+	AnimInterpsN
+)
+
+//go:generate stringer -type=AnimInterps
+
+var KiT_AnimInterps = kit.Enums.AddEnum(AnimInterpsN, kit.NotBitFlag, nil)
+
+///////////////////////////////////////////////////////////////////////////
+//  AnimTrack
+
+// AnimTrack is a sequence of keyframes for one property of one node.
+// Values are stored flat, with Target.NVals() values per key.
+type AnimTrack struct {
+	Node    string      `desc:"path to the animated node, relative to the Scene, using unique names -- see Node3DBase.PathFromUnique"`
+	Target  AnimTargets `desc:"which property of the node is animated"`
+	Interp  AnimInterps `desc:"how values are interpolated between keys"`
+	Times   []float32   `desc:"time of each key, in seconds, in increasing order"`
+	Values  []float32   `desc:"values for each key, with Target.NVals() values per key"`
+	InTans  []float32   `desc:"for AnimCubic: incoming tangent for each key, in units per second, same layout as Values -- if empty, Catmull-Rom tangents are used"`
+	OutTans []float32   `desc:"for AnimCubic: outgoing tangent for each key, in units per second, same layout as Values -- if empty, Catmull-Rom tangents are used"`
+	NodePtr Node3D      `copy:"-" json:"-" xml:"-" view:"-" desc:"cached pointer to the animated node"`
+}
+
+var KiT_AnimTrack = kit.Types.AddType(&AnimTrack{}, nil)
+
+// NKeys returns the number of keys in the track
+func (tr *AnimTrack) NKeys() int {
+	return len(tr.Times)
+}
+
+// Duration returns the time of the last key
+func (tr *AnimTrack) Duration() float32 {
+	if len(tr.Times) == 0 {
+		return 0
+	}
+	return tr.Times[len(tr.Times)-1]
+}
+
+// AddKey adds a key at given time with given values, which must have
+// Target.NVals() elements.  Keys must be added in increasing time order.
+func (tr *AnimTrack) AddKey(t float32, vals ...float32) {
+	if nv := tr.Target.NVals(); len(vals) != nv {
+		log.Printf("gi3d.AnimTrack: %v key has %d values, should have %d\n", tr.Target, len(vals), nv)
+		return
+	}
+	tr.Times = append(tr.Times, t)
+	tr.Values = append(tr.Values, vals...)
+}
+
+// AddVec3Key adds a key at given time for a position or scale track
+func (tr *AnimTrack) AddVec3Key(t float32, v mat32.Vec3) {
+	tr.AddKey(t, v.X, v.Y, v.Z)
+}
+
+// AddQuatKey adds a key at given time for a rotation track
+func (tr *AnimTrack) AddQuatKey(t float32, q mat32.Quat) {
+	tr.AddKey(t, q.X, q.Y, q.Z, q.W)
+}
+
+// AddColorKey adds a key at given time for a color track
+func (tr *AnimTrack) AddColorKey(t float32, clr gi.Color) {
+	r, g, b, a := clr.ToFloat32()
+	tr.AddKey(t, r, g, b, a)
+}
+
+// Value computes the interpolated value of the track at given time into
+// dst, which must have Target.NVals() elements.  Times before the first
+// key or after the last key hold the first or last key value.
+// Returns false if there are no keys.
+func (tr *AnimTrack) Value(t float32, dst []float32) bool {
+	nk := len(tr.Times)
+	if nk == 0 {
+		return false
+	}
+	nv := tr.Target.NVals()
+	// index of first key after t
+	k := sort.Search(nk, func(i int) bool { return tr.Times[i] > t })
+	if k == 0 {
+		copy(dst, tr.Values[:nv])
+		return true
+	}
+	if k == nk {
+		copy(dst, tr.Values[(nk-1)*nv:nk*nv])
+		return true
+	}
+	k0 := k - 1
+	v0 := tr.Values[k0*nv : k*nv]
+	v1 := tr.Values[k*nv : (k+1)*nv]
+	dt := tr.Times[k] - tr.Times[k0]
+	if tr.Interp == AnimStep || dt <= 0 {
+		copy(dst, v0)
+		return true
+	}
+	s := (t - tr.Times[k0]) / dt
+	switch tr.Interp {
+	case AnimLinear:
+		if tr.Target == AnimQuat {
+			q := mat32.NewQuat(v0[0], v0[1], v0[2], v0[3])
+			q.Slerp(mat32.NewQuat(v1[0], v1[1], v1[2], v1[3]), s)
+			q.ToArray(dst, 0)
+			return true
+		}
+		for i := 0; i < nv; i++ {
+			dst[i] = v0[i] + s*(v1[i]-v0[i])
+		}
+	case AnimCubic:
+		s2 := s * s
+		s3 := s2 * s
+		h00 := 2*s3 - 3*s2 + 1
+		h10 := s3 - 2*s2 + s
+		h01 := -2*s3 + 3*s2
+		h11 := s3 - s2
+		for i := 0; i < nv; i++ {
+			m0 := tr.tangent(k0, i, true) * dt
+			m1 := tr.tangent(k, i, false) * dt
+			dst[i] = h00*v0[i] + h10*m0 + h01*v1[i] + h11*m1
+		}
+		if tr.Target == AnimQuat {
+			q := mat32.NewQuat(dst[0], dst[1], dst[2], dst[3])
+			q.Normalize()
+			q.ToArray(dst, 0)
+		}
+	}
+	return true
+}
+
+// tangent returns the tangent (per second) of value component i at key k,
+// using OutTans (out = true) or InTans if present, or else the
+// Catmull-Rom (finite difference) tangent.
+func (tr *AnimTrack) tangent(k, i int, out bool) float32 {
+	nv := tr.Target.NVals()
+	if out && len(tr.OutTans) == len(tr.Values) {
+		return tr.OutTans[k*nv+i]
+	}
+	if !out && len(tr.InTans) == len(tr.Values) {
+		return tr.InTans[k*nv+i]
+	}
+	nk := len(tr.Times)
+	kp := k - 1
+	if kp < 0 {
+		kp = 0
+	}
+	kn := k + 1
+	if kn >= nk {
+		kn = nk - 1
+	}
+	dt := tr.Times[kn] - tr.Times[kp]
+	if dt <= 0 {
+		return 0
+	}
+	return (tr.Values[kn*nv+i] - tr.Values[kp*nv+i]) / dt
+}
+
+// SetNode sets the node that this track animates, recording its path
+// relative to the scene
+func (tr *AnimTrack) SetNode(sc *Scene, nd Node3D) {
+	tr.NodePtr = nd
+	tr.Node = nd.PathFromUnique(sc.This())
+}
+
+// FindNode finds the animated node from the Node path, caching it in NodePtr
+func (tr *AnimTrack) FindNode(sc *Scene) (Node3D, error) {
+	if tr.NodePtr != nil && tr.NodePtr.This() != nil && !tr.NodePtr.IsDestroyed() {
+		return tr.NodePtr, nil
+	}
+	k, err := sc.FindPathUniqueTry(tr.Node)
+	if err != nil {
+		return nil, err
+	}
+	nd, _ := KiToNode3D(k)
+	if nd == nil {
+		return nil, fmt.Errorf("gi3d.AnimTrack: node at path: %v is not a Node3D", tr.Node)
+	}
+	tr.NodePtr = nd
+	return nd, nil
+}
+
+// Apply sets the animated property of the node to the value at given time.
+// Pose values are set under the node's PoseMu lock, and colors are set on
+// the main thread, where the scene is rendered -- so this must not be
+// called on the main thread.
+func (tr *AnimTrack) Apply(sc *Scene, t float32) error {
+	nd, err := tr.FindNode(sc)
+	if err != nil {
+		return err
+	}
+	var vals [4]float32
+	if !tr.Value(t, vals[:]) {
+		return nil
+	}
+	nb := nd.AsNode3D()
+	switch tr.Target {
+	case AnimPos:
+		nb.SetPosePos(mat32.NewVec3(vals[0], vals[1], vals[2]))
+	case AnimQuat:
+		nb.SetPoseQuat(mat32.NewQuat(vals[0], vals[1], vals[2], vals[3]))
+	case AnimScale:
+		nb.SetPoseScale(mat32.NewVec3(vals[0], vals[1], vals[2]))
+	case AnimColor:
+		sld := nd.AsSolid()
+		if sld == nil {
+			return fmt.Errorf("gi3d.AnimTrack: color track node: %v is not a Solid", tr.Node)
+		}
+		var clr gi.Color
+		clr.SetFloat32(mat32.Clamp(vals[0], 0, 1), mat32.Clamp(vals[1], 0, 1), mat32.Clamp(vals[2], 0, 1), mat32.Clamp(vals[3], 0, 1))
+		// the material is read by Render3D on the main thread, and has no lock
+		if oswin.TheApp == nil {
+			sld.Mat.Color = clr
+		} else {
+			oswin.TheApp.RunOnMain(func() { sld.Mat.Color = clr })
+		}
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+//  AnimClip
+
+// AnimClip is a named collection of AnimTracks that play together
+type AnimClip struct {
+	Name   string       `desc:"name of the clip -- clips are stored on the Scene by name"`
+	Tracks []*AnimTrack `desc:"the tracks in this clip"`
+}
+
+var KiT_AnimClip = kit.Types.AddType(&AnimClip{}, nil)
+
+// AddNewAnimClip adds a new animation clip of given name to the scene
+func AddNewAnimClip(sc *Scene, name string) *AnimClip {
+	ac := &AnimClip{Name: name}
+	sc.AddAnim(ac)
+	return ac
+}
+
+// AddTrack adds a new track animating given target of given node,
+// with given interpolation
+func (ac *AnimClip) AddTrack(sc *Scene, nd Node3D, target AnimTargets, interp AnimInterps) *AnimTrack {
+	tr := &AnimTrack{Target: target, Interp: interp}
+	tr.SetNode(sc, nd)
+	ac.Tracks = append(ac.Tracks, tr)
+	return tr
+}
+
+// Duration returns the duration of the clip, which is the time of the
+// last key across all tracks
+func (ac *AnimClip) Duration() float32 {
+	dur := float32(0)
+	for _, tr := range ac.Tracks {
+		dur = mat32.Max(dur, tr.Duration())
+	}
+	return dur
+}
+
+// Apply sets all the animated properties to their values at given time.
+// Tracks whose nodes cannot be found are skipped, and the first such
+// error is returned.
+func (ac *AnimClip) Apply(sc *Scene, t float32) error {
+	var rerr error
+	for _, tr := range ac.Tracks {
+		err := tr.Apply(sc, t)
+		if err != nil && rerr == nil {
+			rerr = err
+		}
+	}
+	return rerr
+}
+
+///////////////////////////////////////////////////////////////////////////
+//  AnimPlayer
+
+// AnimPlayer plays an AnimClip in the Scene, updating the animated
+// properties and world matrices at a fixed frame rate, and triggering
+// a re-render of the Scene after each frame.
+type AnimPlayer struct {
+	Clip     string          `desc:"name of the clip being played, in Scene.Anims"`
+	Time     float32         `inactive:"+" desc:"current time within the clip, in seconds"`
+	Speed    float32         `desc:"playback speed multiplier -- 1 = real time, negative plays backward"`
+	Loop     bool            `desc:"if true, playback wraps around to the start at the end of the clip -- otherwise it stops"`
+	FPS      float32         `min:"1" desc:"number of frames per second to update during playback"`
+	Playing  bool            `inactive:"+" desc:"true if currently playing"`
+	Mu       sync.Mutex      `copy:"-" json:"-" xml:"-" view:"-" desc:"mutex protecting player state"`
+	FrameFun func(sc *Scene) `copy:"-" json:"-" xml:"-" view:"-" desc:"optional function called after each frame is applied, before rendering"`
+	stop     chan struct{}
+}
+
+var KiT_AnimPlayer = kit.Types.AddType(&AnimPlayer{}, nil)
+
+// Defaults sets default parameters
+func (ap *AnimPlayer) Defaults() {
+	ap.Speed = 1
+	ap.FPS = 30
+}
+
+// Play starts playing the clip of given name from the current Time --
+// if name is empty, the current Clip is used.  Set Time with Seek first
+// to start from a different time.
+func (ap *AnimPlayer) Play(sc *Scene, name string) error {
+	ap.Mu.Lock()
+	defer ap.Mu.Unlock()
+	if name != "" && name != ap.Clip {
+		ap.Clip = name
+		ap.Time = 0
+	}
+	if _, err := sc.AnimByNameTry(ap.Clip); err != nil {
+		log.Println(err)
+		return err
+	}
+	if ap.FPS <= 0 || ap.Speed == 0 {
+		ap.Defaults()
+	}
+	if ap.Playing {
+		return nil
+	}
+	ap.Playing = true
+	ap.stop = make(chan struct{})
+	go ap.run(sc, ap.stop)
+	return nil
+}
+
+// Pause stops playback at the current Time
+func (ap *AnimPlayer) Pause() {
+	ap.Mu.Lock()
+	defer ap.Mu.Unlock()
+	if !ap.Playing {
+		return
+	}
+	ap.Playing = false
+	close(ap.stop)
+	ap.stop = nil
+}
+
+// Stop stops playback and rewinds to the start of the clip
+func (ap *AnimPlayer) Stop(sc *Scene) {
+	ap.Pause()
+	ap.Seek(sc, 0)
+}
+
+// Seek sets the current Time, applying the clip at that time and
+// updating the scene -- this works whether or not the player is playing.
+func (ap *AnimPlayer) Seek(sc *Scene, t float32) error {
+	ap.Mu.Lock()
+	ap.Time = t
+	ap.Mu.Unlock()
+	return ap.ApplyFrame(sc)
+}
+
+// ApplyFrame applies the current clip at the current Time, updates world
+// matrices, and triggers a re-render of the scene
+func (ap *AnimPlayer) ApplyFrame(sc *Scene) error {
+	ap.Mu.Lock()
+	t := ap.Time
+	ac, err := sc.AnimByNameTry(ap.Clip)
+	ap.Mu.Unlock()
+	if err != nil {
+		return err
+	}
+	err = ac.Apply(sc, t)
+	sc.UpdateWorldMatrix()
+	if ap.FrameFun != nil {
+		ap.FrameFun(sc)
+	}
+	sc.UpdateSig()
+	return err
+}
+
+// advance advances the Time by given elapsed real time, handling looping
+// and the end of the clip.  Returns false if playback has ended.
+func (ap *AnimPlayer) advance(sc *Scene, elapsed float32) bool {
+	ap.Mu.Lock()
+	defer ap.Mu.Unlock()
+	ac, err := sc.AnimByNameTry(ap.Clip)
+	if err != nil {
+		return false
+	}
+	dur := ac.Duration()
+	ap.Time += elapsed * ap.Speed
+	if ap.Loop && dur > 0 {
+		ap.Time = mat32.Mod(ap.Time, dur)
+		if ap.Time < 0 {
+			ap.Time += dur
+		}
+		return true
+	}
+	if ap.Time >= dur {
+		ap.Time = dur
+		return false
+	}
+	if ap.Time <= 0 {
+		ap.Time = 0
+		return false
+	}
+	return true
+}
+
+// run is the playback goroutine
+func (ap *AnimPlayer) run(sc *Scene, stop chan struct{}) {
+	ticker := time.NewTicker(time.Duration(float32(time.Second) / ap.FPS))
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if sc.This() == nil || sc.IsDestroyed() {
+				return
+			}
+			more := ap.advance(sc, float32(now.Sub(last).Seconds()))
+			last = now
+			ap.ApplyFrame(sc)
+			if !more {
+				ap.ended(stop)
+				return
+			}
+		}
+	}
+}
+
+// ended records the end of playback by the run with given stop channel
+// at the end of the clip -- unless it has already been paused, in which
+// case a new run may have been started, which must keep playing
+func (ap *AnimPlayer) ended(stop chan struct{}) {
+	ap.Mu.Lock()
+	defer ap.Mu.Unlock()
+	if ap.stop != stop {
+		return
+	}
+	ap.Playing = false
+	ap.stop = nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+//  Scene
+
+// AddAnim adds given animation clip to the scene's Anims, replacing any
+// existing clip of the same name
+func (sc *Scene) AddAnim(ac *AnimClip) {
+	if sc.Anims == nil {
+		sc.Anims = make(map[string]*AnimClip)
+	}
+	sc.Anims[ac.Name] = ac
+}
+
+// AnimByName returns animation clip of given name, or nil if not found
+func (sc *Scene) AnimByName(nm string) *AnimClip {
+	ac, _ := sc.AnimByNameTry(nm)
+	return ac
+}
+
+// AnimByNameTry returns animation clip of given name, or error if not found
+func (sc *Scene) AnimByNameTry(nm string) (*AnimClip, error) {
+	if ac, ok := sc.Anims[nm]; ok {
+		return ac, nil
+	}
+	return nil, fmt.Errorf("gi3d.Scene: %v animation clip named: %v not found", sc.Nm, nm)
+}
+
+// AnimList returns a sorted list of animation clip names
+func (sc *Scene) AnimList() []string {
+	sl := make([]string, 0, len(sc.Anims))
+	for nm := range sc.Anims {
+		sl = append(sl, nm)
+	}
+	sort.Strings(sl)
+	return sl
+}
+
+// DeleteAnim deletes animation clip of given name, stopping the player
+// if it is playing that clip
+func (sc *Scene) DeleteAnim(nm string) {
+	sc.Anim.Mu.Lock()
+	cur := sc.Anim.Clip == nm
+	sc.Anim.Mu.Unlock()
+	if cur {
+		sc.Anim.Pause()
+	}
+	delete(sc.Anims, nm)
+}
+
+// PlayAnim starts playing animation clip of given name from the start,
+// using the scene's Anim player
+func (sc *Scene) PlayAnim(nm string, loop bool) error {
+	sc.Anim.Pause()
+	sc.Anim.Mu.Lock()
+	sc.Anim.Loop = loop
+	sc.Anim.Clip = nm
+	sc.Anim.Time = 0
+	sc.Anim.Mu.Unlock()
+	return sc.Anim.Play(sc, "")
+}
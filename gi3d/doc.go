@@ -45,6 +45,16 @@ Textures are also stored by unique names on the Scene, and the Material can
 optionally refer to a texture -- likewise allowing efficient re-use across
 different Solids.
 
+The Material uses the Phong lighting model by default, or a metallic-roughness
+physically based rendering (PBR) model with optional normal, occlusion and
+emissive maps when PBR.On is set.  PBR materials are also lit by the Scene
+EnvMap equirectangular environment image if set (image-based lighting).
+
+Keyframe animations of node poses and material colors are stored as
+AnimClips in the Scene Anims, and played by the Scene Anim AnimPlayer.
+Meshes with joint indexes and weights are deformed by the Bones of the
+Skeleton set on their Solid.
+
 The Scene also contains a Library of uniquely-named "objects" (Groups)
 which can be loaded from 3D object files, and then added into the scenegraph as
 needed.  Thus, a typical, efficient workflow is to initialize a Library of such
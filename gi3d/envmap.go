@@ -0,0 +1,285 @@
+// Copyright (c) 2019, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/gpu"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"golang.org/x/image/draw"
+)
+
+// https://learnopengl.com/PBR/IBL/Diffuse-irradiance
+// https://learnopengl.com/PBR/IBL/Specular-IBL
+
+// EnvSpecLevels is the number of prefiltered specular maps of increasing
+// roughness in an EnvMap -- the first level is the source image itself.
+const EnvSpecLevels = 4
+
+// EnvMap is an equirectangular (latitude-longitude) environment map that
+// provides image-based lighting (IBL) for PBR materials.
+// The source image is prefiltered on the CPU into a diffuse irradiance map
+// and a set of specular maps of increasing roughness, which are then
+// uploaded as standard 2D textures.  The X axis of the image spans the
+// full 360 degrees around the vertical Y axis, and the Y axis of the
+// image goes from straight up (top) to straight down (bottom).
+type EnvMap struct {
+	File       gi.FileName                  `desc:"file name of the equirectangular environment image -- typically a 2:1 aspect ratio panorama"`
+	Intensity  float32                      `min:"0" desc:"multiplier on the light contributed by the environment map"`
+	Rotation   float32                      `desc:"rotation of the environment around the vertical Y axis, in degrees"`
+	Img        image.Image                  `view:"-" desc:"source environment image -- loaded from File or set directly with SetImage"`
+	Irradiance *image.RGBA                  `view:"-" desc:"prefiltered diffuse irradiance map"`
+	Spec       [EnvSpecLevels]*image.RGBA   `view:"-" desc:"prefiltered specular maps of increasing roughness"`
+	IrrTex     gpu.Texture2D                `view:"-" desc:"gpu texture for Irradiance"`
+	SpecTex    [EnvSpecLevels]gpu.Texture2D `view:"-" desc:"gpu textures for Spec"`
+	NeedsXfer  bool                         `view:"-" desc:"true if the prefiltered maps have changed and need to be transferred to the GPU"`
+}
+
+var KiT_EnvMap = kit.Types.AddType(&EnvMap{}, nil)
+
+// EnvMap image sizes used for prefiltering
+var (
+	// EnvSrcSize is the size that the source image is reduced to for
+	// computing the prefiltered maps
+	EnvSrcSize = image.Point{128, 64}
+
+	// EnvIrrSize is the size of the diffuse irradiance map
+	EnvIrrSize = image.Point{32, 16}
+)
+
+// Defaults sets default parameters
+func (em *EnvMap) Defaults() {
+	em.Intensity = 1
+}
+
+// IsSet returns true if an environment image has been loaded
+func (em *EnvMap) IsSet() bool {
+	return em.Irradiance != nil
+}
+
+// Open opens the environment image from given file, and prefilters it.
+// This does not require a gpu context -- Init uploads to the GPU.
+func (em *EnvMap) Open(fname string) error {
+	img, err := gi.OpenImage(fname)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	em.File = gi.FileName(fname)
+	em.SetImage(img)
+	return nil
+}
+
+// SetImage sets the source environment image and prefilters it.
+// This does not require a gpu context -- Init uploads to the GPU.
+func (em *EnvMap) SetImage(img image.Image) {
+	if em.Intensity == 0 {
+		em.Defaults()
+	}
+	em.Img = img
+	em.Prefilter()
+}
+
+// Reset removes any current environment image
+func (em *EnvMap) Reset() {
+	em.File = ""
+	em.Img = nil
+	em.Irradiance = nil
+	for i := range em.Spec {
+		em.Spec[i] = nil
+	}
+	em.NeedsXfer = false
+}
+
+// Prefilter computes the Irradiance and Spec maps from the source Img.
+// This is computationally expensive and is done on the CPU, so it
+// only happens when a new image is set.
+func (em *EnvMap) Prefilter() {
+	if em.Img == nil {
+		return
+	}
+	src := image.NewRGBA(image.Rectangle{Max: EnvSrcSize})
+	draw.ApproxBiLinear.Scale(src, src.Bounds(), em.Img, em.Img.Bounds(), draw.Src, nil)
+	smps := envSamples(src)
+
+	em.Irradiance = envConvolve(smps, EnvIrrSize, func(cosAng float32) float32 {
+		return cosAng // cosine-weighted hemisphere
+	})
+
+	full := image.NewRGBA(em.Img.Bounds())
+	draw.Draw(full, full.Bounds(), em.Img, em.Img.Bounds().Min, draw.Src)
+	em.Spec[0] = full
+	for li := 1; li < EnvSpecLevels; li++ {
+		rough := float32(li) / float32(EnvSpecLevels-1)
+		alpha := rough * rough
+		a2 := alpha * alpha
+		sz := image.Point{EnvSrcSize.X >> uint(li-1), EnvSrcSize.Y >> uint(li-1)}
+		em.Spec[li] = envConvolve(smps, sz, func(cosAng float32) float32 {
+			// GGX distribution using the half-vector between the reflection
+			// direction and light direction, assuming N = V = R
+			cosH := mat32.Sqrt((1 + cosAng) / 2)
+			d := cosH*cosH*(a2-1) + 1
+			return cosAng * a2 / (mat32.Pi * d * d)
+		})
+	}
+	em.NeedsXfer = true
+}
+
+// envSample is one texel of the source environment image
+type envSample struct {
+	dir mat32.Vec3 // world direction of texel center
+	clr mat32.Vec3 // linear color multiplied by solid angle
+	sa  float32    // solid angle
+}
+
+// envSamples returns the samples for each texel of given image,
+// converting colors to linear space
+func envSamples(img *image.RGBA) []envSample {
+	sz := img.Bounds().Size()
+	smps := make([]envSample, 0, sz.X*sz.Y)
+	dphi := 2 * mat32.Pi / float32(sz.X)
+	dth := mat32.Pi / float32(sz.Y)
+	for y := 0; y < sz.Y; y++ {
+		v := (float32(y) + 0.5) / float32(sz.Y)
+		sa := dphi * dth * mat32.Sin(v*mat32.Pi)
+		for x := 0; x < sz.X; x++ {
+			u := (float32(x) + 0.5) / float32(sz.X)
+			c := img.RGBAAt(x, y)
+			clr := mat32.Vec3{SRGBToLinear(float32(c.R) / 255), SRGBToLinear(float32(c.G) / 255), SRGBToLinear(float32(c.B) / 255)}
+			smps = append(smps, envSample{dir: EnvDir(u, v), clr: clr.MulScalar(sa), sa: sa})
+		}
+	}
+	return smps
+}
+
+// envConvolve computes a filtered equirectangular image of given size
+// from the samples, using given weighting function of the cosine of
+// the angle between each output direction and each sample direction
+// (only called for positive cosines).
+func envConvolve(smps []envSample, sz image.Point, wtFun func(cosAng float32) float32) *image.RGBA {
+	img := image.NewRGBA(image.Rectangle{Max: sz})
+	for y := 0; y < sz.Y; y++ {
+		v := (float32(y) + 0.5) / float32(sz.Y)
+		for x := 0; x < sz.X; x++ {
+			u := (float32(x) + 0.5) / float32(sz.X)
+			dir := EnvDir(u, v)
+			sum := mat32.Vec3{}
+			wsum := float32(0)
+			for i := range smps {
+				sm := &smps[i]
+				cosAng := dir.Dot(sm.dir)
+				if cosAng <= 0 {
+					continue
+				}
+				wt := wtFun(cosAng)
+				sum.SetAdd(sm.clr.MulScalar(wt))
+				wsum += wt * sm.sa
+			}
+			if wsum > 0 {
+				sum.SetDivScalar(wsum)
+			}
+			img.SetRGBA(x, y, color.RGBA{envToUInt8(sum.X), envToUInt8(sum.Y), envToUInt8(sum.Z), 255})
+		}
+	}
+	return img
+}
+
+// envToUInt8 converts linear color value to an sRGB byte
+func envToUInt8(lin float32) uint8 {
+	return uint8(mat32.Clamp(LinearToSRGB(lin), 0, 1)*255 + 0.5)
+}
+
+// EnvDir returns the world direction for given normalized equirectangular
+// image coordinates, where u goes around the vertical Y axis starting
+// at -X, and v goes from up (0) to down (1).
+func EnvDir(u, v float32) mat32.Vec3 {
+	phi := (u - 0.5) * 2 * mat32.Pi
+	th := v * mat32.Pi
+	st := mat32.Sin(th)
+	return mat32.Vec3{st * mat32.Cos(phi), mat32.Cos(th), st * mat32.Sin(phi)}
+}
+
+// SRGBToLinear converts an sRGB color component (0-1) to linear
+// space, using the standard 2.2 gamma approximation
+func SRGBToLinear(c float32) float32 {
+	return mat32.Pow(c, 2.2)
+}
+
+// LinearToSRGB converts a linear color component to sRGB space,
+// using the standard 2.2 gamma approximation
+func LinearToSRGB(c float32) float32 {
+	if c <= 0 {
+		return 0
+	}
+	return mat32.Pow(c, 1/2.2)
+}
+
+// Init uploads the prefiltered maps to the GPU, if not already done or
+// if they have changed.  If only File is set, it is opened first, and if
+// nothing is set (e.g., after Reset), any prior textures are deleted.
+// Must be called in context on main thread.
+func (em *EnvMap) Init(sc *Scene) error {
+	if !em.IsSet() {
+		if em.File == "" {
+			em.Delete(sc)
+			return nil
+		}
+		if err := em.Open(string(em.File)); err != nil {
+			return err
+		}
+	}
+	if !em.NeedsXfer && em.IrrTex != nil {
+		return nil
+	}
+	if em.IrrTex == nil {
+		em.IrrTex = gpu.TheGPU.NewTexture2D(sc.Nm + "-env-irr")
+	}
+	if err := em.IrrTex.SetImage(em.Irradiance); err != nil {
+		return err
+	}
+	for li := range em.SpecTex {
+		if em.SpecTex[li] == nil {
+			em.SpecTex[li] = gpu.TheGPU.NewTexture2D(fmt.Sprintf("%s-env-spec-%d", sc.Nm, li))
+		}
+		if err := em.SpecTex[li].SetImage(em.Spec[li]); err != nil {
+			return err
+		}
+	}
+	em.NeedsXfer = false
+	return nil
+}
+
+// Activate activates the environment textures, with the irradiance map
+// at given texture number, followed by the specular maps.
+// Must be called in context on main thread.
+func (em *EnvMap) Activate(texNo int) {
+	if em.IrrTex == nil {
+		return
+	}
+	em.IrrTex.Activate(texNo)
+	for li, tx := range em.SpecTex {
+		tx.Activate(texNo + 1 + li)
+	}
+}
+
+// Delete deletes the GPU resources -- must be called in context on main thread
+func (em *EnvMap) Delete(sc *Scene) {
+	if em.IrrTex != nil {
+		em.IrrTex.Delete()
+		em.IrrTex = nil
+	}
+	for li, tx := range em.SpecTex {
+		if tx != nil {
+			tx.Delete()
+			em.SpecTex[li] = nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/goki/mat32"
+)
+
+func TestEnvDir(t *testing.T) {
+	tests := []struct {
+		u, v float32
+		dir  mat32.Vec3
+	}{
+		{0.5, 0, mat32.Vec3{0, 1, 0}},
+		{0.5, 1, mat32.Vec3{0, -1, 0}},
+		{0.5, 0.5, mat32.Vec3{1, 0, 0}},
+		{0, 0.5, mat32.Vec3{-1, 0, 0}},
+		{0.75, 0.5, mat32.Vec3{0, 0, 1}},
+		{0.25, 0.5, mat32.Vec3{0, 0, -1}},
+	}
+	for _, tst := range tests {
+		dir := EnvDir(tst.u, tst.v)
+		if dir.Sub(tst.dir).Length() > 1e-5 {
+			t.Errorf("EnvDir(%v, %v) = %v, expected %v", tst.u, tst.v, dir, tst.dir)
+		}
+	}
+}
+
+func TestEnvSRGB(t *testing.T) {
+	for _, c := range []float32{0, 0.1, 0.5, 0.8, 1} {
+		if rt := LinearToSRGB(SRGBToLinear(c)); mat32.Abs(rt-c) > 1e-5 {
+			t.Errorf("LinearToSRGB(SRGBToLinear(%v)) = %v", c, rt)
+		}
+	}
+	if c := LinearToSRGB(-1); c != 0 {
+		t.Errorf("LinearToSRGB(-1) = %v, expected 0", c)
+	}
+	if b := envToUInt8(2); b != 255 {
+		t.Errorf("envToUInt8(2) = %v, expected 255", b)
+	}
+}
+
+func TestEnvMapPrefilter(t *testing.T) {
+	svsrc := EnvSrcSize
+	EnvSrcSize = image.Point{16, 8}
+	defer func() { EnvSrcSize = svsrc }()
+
+	// a uniform environment stays uniform at all levels of filtering
+	clr := color.RGBA{200, 100, 50, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+	draw.Draw(img, img.Bounds(), &image.Uniform{clr}, image.ZP, draw.Src)
+	em := &EnvMap{}
+	em.SetImage(img)
+	if !em.IsSet() || !em.NeedsXfer || em.Intensity != 1 {
+		t.Fatalf("SetImage: IsSet %v NeedsXfer %v Intensity %v", em.IsSet(), em.NeedsXfer, em.Intensity)
+	}
+	if sz := em.Irradiance.Bounds().Size(); sz != EnvIrrSize {
+		t.Errorf("Irradiance size %v, expected %v", sz, EnvIrrSize)
+	}
+	if sz := em.Spec[0].Bounds().Size(); sz != img.Bounds().Size() {
+		t.Errorf("Spec[0] size %v, expected source size %v", sz, img.Bounds().Size())
+	}
+	near := func(a, b uint8) bool {
+		d := int(a) - int(b)
+		return d >= -2 && d <= 2
+	}
+	maps := append([]*image.RGBA{em.Irradiance}, em.Spec[:]...)
+	for mi, m := range maps {
+		bb := m.Bounds()
+		for y := bb.Min.Y; y < bb.Max.Y; y++ {
+			for x := bb.Min.X; x < bb.Max.X; x++ {
+				c := m.RGBAAt(x, y)
+				if !near(c.R, clr.R) || !near(c.G, clr.G) || !near(c.B, clr.B) {
+					t.Fatalf("map %v at %v,%v: got %v, expected %v", mi, x, y, c, clr)
+				}
+			}
+		}
+	}
+	for li := 2; li < EnvSpecLevels; li++ {
+		if em.Spec[li].Bounds().Dx() >= em.Spec[li-1].Bounds().Dx() {
+			t.Errorf("Spec[%v] not smaller than Spec[%v]", li, li-1)
+		}
+	}
+
+	em.Reset()
+	if em.IsSet() || em.Img != nil || em.Spec[0] != nil || em.NeedsXfer {
+		t.Errorf("Reset: environment still set")
+	}
+}
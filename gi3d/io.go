@@ -49,6 +49,7 @@ type Decoder interface {
 
 // Decoders is the master list of decoders, indexed by the primary extension.
 // .obj = Wavefront object file -- only has mesh data, not scene info.
+// .gltf, .glb = glTF 2.0 -- meshes, node hierarchy, materials and animations.
 var Decoders = map[string]Decoder{}
 
 // DecodeFile decodes the given file using a decoder based on the file
@@ -56,6 +57,8 @@ var Decoders = map[string]Decoder{}
 // Supported formats include:
 // .obj = Wavefront OBJ format, including associated materials (.mtl) which
 //        must have same name as .obj, or a default material is used.
+// .gltf, .glb = glTF 2.0 format, in JSON or binary form, including
+//        PBR materials and animations, which are added to the Scene Anims.
 func DecodeFile(fname string) (Decoder, error) {
 	ext := filepath.Ext(fname)
 	dt, has := Decoders[ext]
@@ -0,0 +1,857 @@
+// Copyright (c) 2019, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gltf is used to parse the Khronos glTF 2.0 format, in both the
+// JSON (*.gltf) and binary (*.glb) forms.  Meshes, node hierarchy,
+// metallic-roughness materials with their textures, and animations are
+// supported, as are skins, which become a gi3d.Skeleton of Bones.
+// Cameras, lights, morph targets and sparse accessors are not.
+// Format spec: https://github.com/KhronosGroup/glTF/tree/master/specification/2.0
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi3d"
+	"github.com/goki/mat32"
+)
+
+// note: gimain imports "github.com/goki/gi/gi3d/io/gltf" to get this code
+func init() {
+	gi3d.Decoders[".gltf"] = &Decoder{}
+	gi3d.Decoders[".glb"] = &Decoder{}
+}
+
+// Decoder contains all decoded data from a glTF file.
+// It also implements the gi3d.Decoder interface and an instance
+// is registered to handle .gltf and .glb files.
+type Decoder struct {
+	File     string           // file name (without path)
+	Dir      string           // path to file
+	Doc      Doc              // decoded JSON document
+	Buffers  [][]byte         // loaded buffer data
+	Warnings []string         // warning messages
+	nodes    []gi3d.Node3D    // created gi3d node for each glTF node
+	solids   [][]*gi3d.Solid  // created solids for each glTF node
+	skels    []*gi3d.Skeleton // created skeleton for each glTF skin
+	jointOf  []int            // skin index for each glTF node that is a joint, else -1
+	skelRoot []bool           // true for each glTF node that is a root joint of its skin
+	textures []gi3d.Texture   // created gi3d texture for each glTF texture
+	mats     []*gi3d.Material // converted material for each glTF material
+}
+
+func (dec *Decoder) New() gi3d.Decoder {
+	di := new(Decoder)
+	di.Warnings = make([]string, 0)
+	return di
+}
+
+func (dec *Decoder) Desc() string {
+	return ".gltf, .glb = Khronos glTF 2.0 format, in JSON or binary form, including meshes, node hierarchy, PBR materials, textures, skins and animations.  Only supports Object-level data, not full Scene (camera, lights etc)."
+}
+
+func (dec *Decoder) HasScene() bool {
+	return false
+}
+
+func (dec *Decoder) SetFile(fname string) []string {
+	dec.Dir, dec.File = filepath.Split(fname)
+	return []string{fname}
+}
+
+// Decode reads the given data and decodes into the Doc and Buffers.
+// Binary .glb data is detected automatically.
+func (dec *Decoder) Decode(rs []io.Reader) error {
+	if len(rs) == 0 {
+		return errors.New("gltf.Decoder: no readers passed")
+	}
+	data, err := ioutil.ReadAll(rs[0])
+	if err != nil {
+		return err
+	}
+	var bin []byte
+	if len(data) >= 12 && string(data[:4]) == "glTF" {
+		data, bin, err = parseGLB(data)
+		if err != nil {
+			return err
+		}
+	}
+	if err = json.Unmarshal(data, &dec.Doc); err != nil {
+		return fmt.Errorf("gltf.Decoder: %v: %v", dec.File, err)
+	}
+	dec.Buffers = make([][]byte, len(dec.Doc.Buffers))
+	for i := range dec.Doc.Buffers {
+		bf := &dec.Doc.Buffers[i]
+		if bf.URI == "" {
+			if i != 0 || bin == nil {
+				return fmt.Errorf("gltf.Decoder: %v: buffer %d has no data", dec.File, i)
+			}
+			dec.Buffers[i] = bin
+			continue
+		}
+		dec.Buffers[i], err = dec.loadURI(bf.URI)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseGLB splits binary glTF data into its JSON and binary chunks
+func parseGLB(data []byte) (jsn, bin []byte, err error) {
+	if ver := binary.LittleEndian.Uint32(data[4:8]); ver != 2 {
+		return nil, nil, fmt.Errorf("gltf.Decoder: glb version %d not supported", ver)
+	}
+	pos := 12
+	for pos+8 <= len(data) {
+		clen := int(binary.LittleEndian.Uint32(data[pos : pos+4]))
+		ctyp := binary.LittleEndian.Uint32(data[pos+4 : pos+8])
+		pos += 8
+		if pos+clen > len(data) {
+			return nil, nil, errors.New("gltf.Decoder: glb chunk extends past end of data")
+		}
+		switch ctyp {
+		case 0x4E4F534A: // JSON
+			jsn = data[pos : pos+clen]
+		case 0x004E4942: // BIN
+			bin = data[pos : pos+clen]
+		}
+		pos += clen
+	}
+	if jsn == nil {
+		return nil, nil, errors.New("gltf.Decoder: glb has no JSON chunk")
+	}
+	return
+}
+
+// loadURI returns the data for given uri, which is either an embedded
+// base64 data uri, or a file relative to the glTF file
+func (dec *Decoder) loadURI(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		ci := strings.Index(uri, ",")
+		if ci < 0 || !strings.Contains(uri[:ci], ";base64") {
+			return nil, fmt.Errorf("gltf.Decoder: unsupported data uri in %v", dec.File)
+		}
+		return base64.StdEncoding.DecodeString(uri[ci+1:])
+	}
+	return ioutil.ReadFile(dec.uriPath(uri))
+}
+
+// uriPath returns the file path for given (non-data) uri
+func (dec *Decoder) uriPath(uri string) string {
+	uri = strings.Replace(uri, "%20", " ", -1)
+	if filepath.IsAbs(uri) {
+		return uri
+	}
+	return filepath.Join(dec.Dir, uri)
+}
+
+// SetScene sets group with with all the decoded objects.
+func (dec *Decoder) SetScene(sc *gi3d.Scene) {
+	gp := gi3d.AddNewGroup(sc, sc, dec.File)
+	dec.SetGroup(sc, gp)
+}
+
+// SetGroup sets group with all the nodes of the default scene in the
+// file, and adds the animations to the Scene -- animations only apply
+// if the group is in the scene itself (not the Library).
+func (dec *Decoder) SetGroup(sc *gi3d.Scene, gp *gi3d.Group) {
+	dec.nodes = make([]gi3d.Node3D, len(dec.Doc.Nodes))
+	dec.textures = make([]gi3d.Texture, len(dec.Doc.Textures))
+	dec.mats = make([]*gi3d.Material, len(dec.Doc.Materials))
+	dec.initSkins()
+	var roots []int
+	if len(dec.Doc.Scenes) > 0 {
+		si := 0
+		if dec.Doc.Scene != nil {
+			si = *dec.Doc.Scene
+		}
+		if si < len(dec.Doc.Scenes) {
+			roots = dec.Doc.Scenes[si].Nodes
+		}
+	} else { // no scenes: all nodes that are not children
+		ischild := make([]bool, len(dec.Doc.Nodes))
+		for _, nd := range dec.Doc.Nodes {
+			for _, ci := range nd.Children {
+				if ci < len(ischild) {
+					ischild[ci] = true
+				}
+			}
+		}
+		for i, c := range ischild {
+			if !c {
+				roots = append(roots, i)
+			}
+		}
+	}
+	for _, ni := range roots {
+		dec.SetNode(sc, gp, ni)
+	}
+	dec.SetSkins(sc)
+	dec.SetAnims(sc)
+}
+
+// initSkins records which nodes are joints, and which of those are the
+// root joints of each skin, where a Skeleton is inserted
+func (dec *Decoder) initSkins() {
+	nn := len(dec.Doc.Nodes)
+	dec.solids = make([][]*gi3d.Solid, nn)
+	dec.skels = make([]*gi3d.Skeleton, len(dec.Doc.Skins))
+	dec.jointOf = make([]int, nn)
+	dec.skelRoot = make([]bool, nn)
+	parent := make([]int, nn)
+	for i := range parent {
+		parent[i] = -1
+		dec.jointOf[i] = -1
+	}
+	for ni, nd := range dec.Doc.Nodes {
+		for _, ci := range nd.Children {
+			if ci >= 0 && ci < nn {
+				parent[ci] = ni
+			}
+		}
+	}
+	for si, sk := range dec.Doc.Skins {
+		if len(sk.Joints) > gi3d.MaxJoints {
+			dec.appendWarn(fmt.Sprintf("skin %d has %d joints, more than gi3d.MaxJoints: %d", si, len(sk.Joints), gi3d.MaxJoints))
+		}
+		for _, ji := range sk.Joints {
+			if ji >= 0 && ji < nn && dec.jointOf[ji] < 0 {
+				dec.jointOf[ji] = si
+			}
+		}
+	}
+	for ni := range dec.Doc.Nodes {
+		si := dec.jointOf[ni]
+		if si < 0 {
+			continue
+		}
+		if pi := parent[ni]; pi < 0 || dec.jointOf[pi] != si {
+			dec.skelRoot[ni] = true
+		}
+	}
+}
+
+// SetNode creates the gi3d node for given glTF node index under given
+// parent, along with all of its children.  A skin joint node becomes a
+// Bone, with a Skeleton inserted above the root joint.  Otherwise, a node
+// with a single mesh primitive and no children becomes a Solid, and
+// otherwise a Group.
+func (dec *Decoder) SetNode(sc *gi3d.Scene, par gi3d.Node3D, ni int) {
+	if ni < 0 || ni >= len(dec.Doc.Nodes) || dec.nodes[ni] != nil {
+		return
+	}
+	nd := &dec.Doc.Nodes[ni]
+	if dec.skelRoot[ni] {
+		si := dec.jointOf[ni]
+		if dec.skels[si] == nil {
+			snm := dec.Doc.Skins[si].Name
+			if snm == "" {
+				snm = fmt.Sprintf("skeleton_%d", si)
+			}
+			dec.skels[si] = gi3d.AddNewSkeleton(sc, par, snm)
+		}
+		par = dec.skels[si]
+	}
+	nm := nd.Name
+	if nm == "" {
+		nm = fmt.Sprintf("node_%d", ni)
+	}
+	if par.ChildByName(nm, 0) != nil {
+		nm = fmt.Sprintf("%s_%d", nm, ni)
+	}
+	var prims []int
+	var mesh *Mesh
+	if nd.Mesh != nil && *nd.Mesh < len(dec.Doc.Meshes) {
+		mesh = &dec.Doc.Meshes[*nd.Mesh]
+		prims = make([]int, len(mesh.Primitives))
+		for i := range prims {
+			prims[i] = i
+		}
+	}
+	var nb *gi3d.Node3DBase
+	if si := dec.jointOf[ni]; si >= 0 {
+		bn := gi3d.AddNewBone(sc, par, nm, dec.jointIndex(si, ni))
+		dec.nodes[ni] = bn
+		nb = bn.AsNode3D()
+		for _, pi := range prims {
+			dec.SetSolid(sc, ni, bn, fmt.Sprintf("%s_%d", nm, pi), *nd.Mesh, pi)
+		}
+	} else if len(prims) == 1 && len(nd.Children) == 0 {
+		sld := dec.SetSolid(sc, ni, par, nm, *nd.Mesh, 0)
+		if sld == nil {
+			return
+		}
+		dec.nodes[ni] = sld
+		nb = sld.AsNode3D()
+	} else {
+		ngp := gi3d.AddNewGroup(sc, par, nm)
+		dec.nodes[ni] = ngp
+		nb = ngp.AsNode3D()
+		for _, pi := range prims {
+			dec.SetSolid(sc, ni, ngp, fmt.Sprintf("%s_%d", nm, pi), *nd.Mesh, pi)
+		}
+	}
+	nd.SetPose(&nb.Pose)
+	for _, ci := range nd.Children {
+		dec.SetNode(sc, dec.nodes[ni], ci)
+	}
+}
+
+// jointIndex returns the index of given node in the joints of given skin
+func (dec *Decoder) jointIndex(si, ni int) int {
+	for i, ji := range dec.Doc.Skins[si].Joints {
+		if ji == ni {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetSkins sets the inverse bind matricies of the Bones in each
+// Skeleton, and connects the skinned Solids to their Skeleton
+func (dec *Decoder) SetSkins(sc *gi3d.Scene) {
+	for si := range dec.Doc.Skins {
+		sk := &dec.Doc.Skins[si]
+		if dec.skels[si] == nil {
+			continue
+		}
+		dec.skels[si].UpdateBones()
+		if sk.InverseBindMatrices == nil {
+			continue
+		}
+		ibm, err := dec.Floats(*sk.InverseBindMatrices, 16)
+		if err != nil {
+			dec.appendWarn(fmt.Sprintf("skin %d: %v", si, err))
+			continue
+		}
+		for j, ji := range sk.Joints {
+			if bn, ok := dec.nodes[ji].(*gi3d.Bone); ok && 16*(j+1) <= len(ibm) {
+				bn.InvBind.FromArray(ibm, 16*j)
+			}
+		}
+	}
+	for ni := range dec.Doc.Nodes {
+		nd := &dec.Doc.Nodes[ni]
+		if nd.Skin == nil || *nd.Skin >= len(dec.skels) || dec.skels[*nd.Skin] == nil {
+			continue
+		}
+		for _, sld := range dec.solids[ni] {
+			sld.SetSkeleton(sc, dec.skels[*nd.Skin])
+		}
+	}
+}
+
+// SetSolid creates a Solid of given name under given parent for given
+// mesh primitive of given node, returning nil if the primitive can't be used.
+func (dec *Decoder) SetSolid(sc *gi3d.Scene, ni int, par gi3d.Node3D, nm string, mi, pi int) *gi3d.Solid {
+	ms, err := dec.MakeMesh(sc, mi, pi)
+	if err != nil {
+		dec.appendWarn(err.Error())
+		return nil
+	}
+	sld := gi3d.AddNewSolid(sc, par, nm, ms.Name())
+	pr := &dec.Doc.Meshes[mi].Primitives[pi]
+	if pr.Material != nil {
+		if mt := dec.Material(sc, *pr.Material); mt != nil {
+			sld.Mat = *mt
+		}
+	}
+	dec.solids[ni] = append(dec.solids[ni], sld)
+	return sld
+}
+
+// MakeMesh creates the gi3d mesh for given mesh primitive, returning
+// any existing one from a previous use of the same glTF mesh
+func (dec *Decoder) MakeMesh(sc *gi3d.Scene, mi, pi int) (gi3d.Mesh, error) {
+	gm := &dec.Doc.Meshes[mi]
+	nm := gm.Name
+	if nm == "" {
+		nm = fmt.Sprintf("mesh_%d", mi)
+	}
+	nm = fmt.Sprintf("%s_%s_%d", strings.TrimSuffix(dec.File, filepath.Ext(dec.File)), nm, pi)
+	if ms, err := sc.MeshByNameTry(nm); err == nil {
+		return ms, nil
+	}
+	pr := &gm.Primitives[pi]
+	if pr.Mode != nil && *pr.Mode != 4 {
+		return nil, fmt.Errorf("mesh %v primitive %d: only triangles mode is supported, not: %d", nm, pi, *pr.Mode)
+	}
+	pai, ok := pr.Attributes["POSITION"]
+	if !ok {
+		return nil, fmt.Errorf("mesh %v primitive %d: no POSITION attribute", nm, pi)
+	}
+	ms := &gi3d.GenMesh{}
+	ms.Nm = nm
+	var err error
+	if ms.Vtx, err = dec.Floats(pai, 3); err != nil {
+		return nil, err
+	}
+	nvtx := len(ms.Vtx) / 3
+	if pr.Indices != nil {
+		if ms.Idx, err = dec.Indices(*pr.Indices); err != nil {
+			return nil, err
+		}
+	} else {
+		ms.Idx = make(mat32.ArrayU32, nvtx)
+		for i := range ms.Idx {
+			ms.Idx[i] = uint32(i)
+		}
+	}
+	if ai, ok := pr.Attributes["NORMAL"]; ok {
+		if ms.Norm, err = dec.Floats(ai, 3); err != nil {
+			return nil, err
+		}
+	} else {
+		ms.Norm = vertexNorms(ms.Vtx, ms.Idx)
+	}
+	if ai, ok := pr.Attributes["TEXCOORD_0"]; ok {
+		if ms.Tex, err = dec.Floats(ai, 2); err != nil {
+			return nil, err
+		}
+		for i := 1; i < len(ms.Tex); i += 2 { // glTF has V=0 at the top
+			ms.Tex[i] = 1 - ms.Tex[i]
+		}
+	}
+	if ai, ok := pr.Attributes["COLOR_0"]; ok {
+		if ms.Color, err = dec.Floats(ai, 4); err != nil {
+			return nil, err
+		}
+	}
+	ji, hasj := pr.Attributes["JOINTS_0"]
+	wi, hasw := pr.Attributes["WEIGHTS_0"]
+	if hasj && hasw {
+		if ms.Joints, err = dec.Floats(ji, 4); err != nil {
+			return nil, err
+		}
+		if ms.Weights, err = dec.Floats(wi, 4); err != nil {
+			return nil, err
+		}
+	}
+	sc.AddMesh(ms)
+	return ms, nil
+}
+
+// vertexNorms computes smooth vertex normals by averaging face normals
+func vertexNorms(vtx mat32.ArrayF32, idx mat32.ArrayU32) mat32.ArrayF32 {
+	norms := make([]mat32.Vec3, len(vtx)/3)
+	var a, b, c mat32.Vec3
+	for i := 0; i+2 < len(idx); i += 3 {
+		vtx.GetVec3(3*int(idx[i]), &a)
+		vtx.GetVec3(3*int(idx[i+1]), &b)
+		vtx.GetVec3(3*int(idx[i+2]), &c)
+		nrm := mat32.Normal(a, b, c)
+		for j := 0; j < 3; j++ {
+			norms[idx[i+j]].SetAdd(nrm)
+		}
+	}
+	nar := make(mat32.ArrayF32, 0, len(vtx))
+	for _, n := range norms {
+		nar.AppendVec3(n.Normal())
+	}
+	return nar
+}
+
+// Material returns the gi3d Material for given glTF material index,
+// creating it and its textures on first use
+func (dec *Decoder) Material(sc *gi3d.Scene, mi int) *gi3d.Material {
+	if mi < 0 || mi >= len(dec.Doc.Materials) {
+		return nil
+	}
+	if dec.mats[mi] != nil {
+		return dec.mats[mi]
+	}
+	gm := &dec.Doc.Materials[mi]
+	mt := &gi3d.Material{}
+	mt.Defaults()
+	mt.PBR.On = true
+	pm := &gm.PBRMetallicRoughness
+	if len(pm.BaseColorFactor) == 4 {
+		f := pm.BaseColorFactor
+		mt.Color.SetFloat32(f[0], f[1], f[2], f[3])
+	}
+	mt.PBR.Metallic = 1
+	if pm.MetallicFactor != nil {
+		mt.PBR.Metallic = *pm.MetallicFactor
+	}
+	mt.PBR.Roughness = 1
+	if pm.RoughnessFactor != nil {
+		mt.PBR.Roughness = *pm.RoughnessFactor
+	}
+	if len(gm.EmissiveFactor) == 3 {
+		f := gm.EmissiveFactor
+		mt.Emissive.SetFloat32(f[0], f[1], f[2], 1)
+	}
+	trans := gm.AlphaMode == "BLEND"
+	if tx := dec.textureInfo(sc, pm.BaseColorTexture, trans); tx != nil {
+		mt.SetTexture(sc, tx)
+	}
+	if tx := dec.textureInfo(sc, pm.MetallicRoughnessTexture, false); tx != nil {
+		mt.PBR.MetalRoughTex = gi3d.TexName(tx.Name())
+	}
+	if gm.NormalTexture != nil {
+		if tx := dec.textureInfo(sc, gm.NormalTexture, false); tx != nil {
+			mt.PBR.NormalTex = gi3d.TexName(tx.Name())
+			if gm.NormalTexture.Scale != nil {
+				mt.PBR.NormalScale = *gm.NormalTexture.Scale
+			}
+		}
+	}
+	if gm.OcclusionTexture != nil {
+		if tx := dec.textureInfo(sc, gm.OcclusionTexture, false); tx != nil {
+			mt.PBR.OcclusionTex = gi3d.TexName(tx.Name())
+			if gm.OcclusionTexture.Strength != nil {
+				mt.PBR.OcclusionStr = *gm.OcclusionTexture.Strength
+			}
+		}
+	}
+	if tx := dec.textureInfo(sc, gm.EmissiveTexture, false); tx != nil {
+		mt.PBR.EmissiveTex = gi3d.TexName(tx.Name())
+		if len(gm.EmissiveFactor) != 3 {
+			mt.Emissive.SetFloat32(1, 1, 1, 1)
+		}
+	}
+	dec.mats[mi] = mt
+	return mt
+}
+
+// textureInfo returns the texture for given texture info, if non-nil
+func (dec *Decoder) textureInfo(sc *gi3d.Scene, ti *TextureInfo, trans bool) gi3d.Texture {
+	if ti == nil {
+		return nil
+	}
+	tx := dec.Texture(sc, ti.Index)
+	if tx != nil && trans {
+		tx.SetTransparent(true)
+	}
+	return tx
+}
+
+// Texture returns the gi3d Texture for given glTF texture index,
+// creating it on first use.  Image files are loaded as TextureFile,
+// while embedded images are decoded into an ImageTexture.
+func (dec *Decoder) Texture(sc *gi3d.Scene, ti int) gi3d.Texture {
+	if ti < 0 || ti >= len(dec.Doc.Textures) {
+		return nil
+	}
+	if dec.textures[ti] != nil {
+		return dec.textures[ti]
+	}
+	gt := &dec.Doc.Textures[ti]
+	if gt.Source == nil || *gt.Source >= len(dec.Doc.Images) {
+		dec.appendWarn(fmt.Sprintf("texture %d has no image source", ti))
+		return nil
+	}
+	im := &dec.Doc.Images[*gt.Source]
+	base := strings.TrimSuffix(dec.File, filepath.Ext(dec.File))
+	nm := fmt.Sprintf("%s_tex_%d", base, ti)
+	var tx gi3d.Texture
+	switch {
+	case im.URI != "" && !strings.HasPrefix(im.URI, "data:"):
+		tx = gi3d.AddNewTextureFile(sc, nm, dec.uriPath(im.URI))
+	default:
+		var data []byte
+		var err error
+		if im.URI != "" {
+			data, err = dec.loadURI(im.URI)
+		} else if im.BufferView != nil {
+			data, err = dec.bufferView(*im.BufferView)
+		} else {
+			err = fmt.Errorf("image %d has no data", *gt.Source)
+		}
+		if err == nil {
+			var img image.Image
+			img, _, err = image.Decode(bytes.NewReader(data))
+			if err == nil {
+				itx := &ImageTexture{Img: img}
+				itx.Nm = nm
+				sc.AddTexture(itx)
+				tx = itx
+			}
+		}
+		if err != nil {
+			dec.appendWarn(fmt.Sprintf("texture %d: %v", ti, err))
+			return nil
+		}
+	}
+	dec.textures[ti] = tx
+	return tx
+}
+
+// SetAnims adds an AnimClip to the Scene for each glTF animation.
+// Morph target weights are not supported.
+func (dec *Decoder) SetAnims(sc *gi3d.Scene) {
+	base := strings.TrimSuffix(dec.File, filepath.Ext(dec.File))
+	for ai := range dec.Doc.Animations {
+		an := &dec.Doc.Animations[ai]
+		nm := an.Name
+		if nm == "" {
+			nm = fmt.Sprintf("%s_anim_%d", base, ai)
+		}
+		ac := &gi3d.AnimClip{Name: nm}
+		for ci := range an.Channels {
+			ch := &an.Channels[ci]
+			if ch.Target.Node == nil || *ch.Target.Node >= len(dec.nodes) || dec.nodes[*ch.Target.Node] == nil {
+				continue
+			}
+			if ch.Sampler < 0 || ch.Sampler >= len(an.Samplers) {
+				continue
+			}
+			tr, err := dec.animTrack(&an.Samplers[ch.Sampler], ch.Target.Path)
+			if err != nil {
+				dec.appendWarn(fmt.Sprintf("animation %v channel %d: %v", nm, ci, err))
+				continue
+			}
+			tr.SetNode(sc, dec.nodes[*ch.Target.Node])
+			ac.Tracks = append(ac.Tracks, tr)
+		}
+		if len(ac.Tracks) > 0 {
+			sc.AddAnim(ac)
+		}
+	}
+}
+
+// animTrack creates an AnimTrack (without node) from given sampler
+// for given target path
+func (dec *Decoder) animTrack(smp *AnimSampler, path string) (*gi3d.AnimTrack, error) {
+	tr := &gi3d.AnimTrack{}
+	switch path {
+	case "translation":
+		tr.Target = gi3d.AnimPos
+	case "rotation":
+		tr.Target = gi3d.AnimQuat
+	case "scale":
+		tr.Target = gi3d.AnimScale
+	default:
+		return nil, fmt.Errorf("target path: %v not supported", path)
+	}
+	switch smp.Interpolation {
+	case "STEP":
+		tr.Interp = gi3d.AnimStep
+	case "CUBICSPLINE":
+		tr.Interp = gi3d.AnimCubic
+	default:
+		tr.Interp = gi3d.AnimLinear
+	}
+	var err error
+	if tr.Times, err = dec.Floats(smp.Input, 1); err != nil {
+		return nil, err
+	}
+	nv := tr.Target.NVals()
+	vals, err := dec.Floats(smp.Output, nv)
+	if err != nil {
+		return nil, err
+	}
+	nk := len(tr.Times)
+	if tr.Interp != gi3d.AnimCubic {
+		if len(vals) != nk*nv {
+			return nil, fmt.Errorf("number of values: %d does not match keys: %d", len(vals), nk)
+		}
+		tr.Values = vals
+		return tr, nil
+	}
+	// cubic: in-tangent, value, out-tangent for each key
+	if len(vals) != 3*nk*nv {
+		return nil, fmt.Errorf("number of cubic spline values: %d does not match keys: %d", len(vals), nk)
+	}
+	tr.InTans = make([]float32, 0, nk*nv)
+	tr.Values = make([]float32, 0, nk*nv)
+	tr.OutTans = make([]float32, 0, nk*nv)
+	for k := 0; k < nk; k++ {
+		st := 3 * k * nv
+		tr.InTans = append(tr.InTans, vals[st:st+nv]...)
+		tr.Values = append(tr.Values, vals[st+nv:st+2*nv]...)
+		tr.OutTans = append(tr.OutTans, vals[st+2*nv:st+3*nv]...)
+	}
+	return tr, nil
+}
+
+/////////////////////////////////////////////////////////////////
+//  Accessors
+
+// component types
+const (
+	compByte   = 5120
+	compUByte  = 5121
+	compShort  = 5122
+	compUShort = 5123
+	compUInt   = 5125
+	compFloat  = 5126
+)
+
+// compSize returns the size in bytes of given component type
+func compSize(ct int) int {
+	switch ct {
+	case compByte, compUByte:
+		return 1
+	case compShort, compUShort:
+		return 2
+	}
+	return 4
+}
+
+// typeComps returns the number of components for given accessor type
+func typeComps(typ string) int {
+	switch typ {
+	case "SCALAR":
+		return 1
+	case "VEC2":
+		return 2
+	case "VEC3":
+		return 3
+	case "VEC4", "MAT2":
+		return 4
+	case "MAT3":
+		return 9
+	case "MAT4":
+		return 16
+	}
+	return 0
+}
+
+// bufferView returns the data for given buffer view
+func (dec *Decoder) bufferView(bvi int) ([]byte, error) {
+	if bvi < 0 || bvi >= len(dec.Doc.BufferViews) {
+		return nil, fmt.Errorf("buffer view index: %d out of range", bvi)
+	}
+	bv := &dec.Doc.BufferViews[bvi]
+	if bv.Buffer < 0 || bv.Buffer >= len(dec.Buffers) {
+		return nil, fmt.Errorf("buffer index: %d out of range", bv.Buffer)
+	}
+	buf := dec.Buffers[bv.Buffer]
+	if bv.ByteOffset+bv.ByteLength > len(buf) {
+		return nil, fmt.Errorf("buffer view: %d extends past end of buffer", bvi)
+	}
+	return buf[bv.ByteOffset : bv.ByteOffset+bv.ByteLength], nil
+}
+
+// Floats returns the data for given accessor as float32 values,
+// converting integer types (with normalization if specified).
+// If ncomp > 0, the accessor must have that many components, except
+// that 3 component colors are expanded to 4 with an alpha of 1.
+func (dec *Decoder) Floats(ai int, ncomp int) ([]float32, error) {
+	if ai < 0 || ai >= len(dec.Doc.Accessors) {
+		return nil, fmt.Errorf("gltf.Decoder: accessor index: %d out of range", ai)
+	}
+	ac := &dec.Doc.Accessors[ai]
+	nc := typeComps(ac.Type)
+	expand := ncomp == 4 && nc == 3
+	if ncomp > 0 && nc != ncomp && !expand {
+		return nil, fmt.Errorf("gltf.Decoder: accessor %d has type: %v, expected %d components", ai, ac.Type, ncomp)
+	}
+	onc := nc
+	if expand {
+		onc = 4
+	}
+	vals := make([]float32, ac.Count*onc)
+	if ac.BufferView == nil { // all zeros
+		return vals, nil
+	}
+	data, err := dec.bufferView(*ac.BufferView)
+	if err != nil {
+		return nil, err
+	}
+	cs := compSize(ac.ComponentType)
+	stride := dec.Doc.BufferViews[*ac.BufferView].ByteStride
+	if stride == 0 {
+		stride = cs * nc
+	}
+	if ac.ByteOffset+(ac.Count-1)*stride+cs*nc > len(data) && ac.Count > 0 {
+		return nil, fmt.Errorf("gltf.Decoder: accessor %d extends past end of buffer view", ai)
+	}
+	for i := 0; i < ac.Count; i++ {
+		st := ac.ByteOffset + i*stride
+		for c := 0; c < nc; c++ {
+			vals[i*onc+c] = compFloat32(data[st+c*cs:], ac.ComponentType, ac.Normalized)
+		}
+		if expand {
+			vals[i*onc+3] = 1
+		}
+	}
+	return vals, nil
+}
+
+// compFloat32 returns the float32 value of the component at start of data
+func compFloat32(data []byte, ct int, norm bool) float32 {
+	switch ct {
+	case compFloat:
+		return math.Float32frombits(binary.LittleEndian.Uint32(data))
+	case compUByte:
+		if norm {
+			return float32(data[0]) / 255
+		}
+		return float32(data[0])
+	case compByte:
+		if norm {
+			return mat32.Max(float32(int8(data[0]))/127, -1)
+		}
+		return float32(int8(data[0]))
+	case compUShort:
+		v := binary.LittleEndian.Uint16(data)
+		if norm {
+			return float32(v) / 65535
+		}
+		return float32(v)
+	case compShort:
+		v := int16(binary.LittleEndian.Uint16(data))
+		if norm {
+			return mat32.Max(float32(v)/32767, -1)
+		}
+		return float32(v)
+	case compUInt:
+		return float32(binary.LittleEndian.Uint32(data))
+	}
+	return 0
+}
+
+// Indices returns the data for given scalar integer accessor as indexes
+func (dec *Decoder) Indices(ai int) (mat32.ArrayU32, error) {
+	if ai < 0 || ai >= len(dec.Doc.Accessors) {
+		return nil, fmt.Errorf("gltf.Decoder: accessor index: %d out of range", ai)
+	}
+	ac := &dec.Doc.Accessors[ai]
+	if ac.BufferView == nil {
+		return nil, fmt.Errorf("gltf.Decoder: index accessor %d has no buffer view", ai)
+	}
+	data, err := dec.bufferView(*ac.BufferView)
+	if err != nil {
+		return nil, err
+	}
+	cs := compSize(ac.ComponentType)
+	if ac.ByteOffset+ac.Count*cs > len(data) {
+		return nil, fmt.Errorf("gltf.Decoder: accessor %d extends past end of buffer view", ai)
+	}
+	idx := make(mat32.ArrayU32, ac.Count)
+	for i := range idx {
+		d := data[ac.ByteOffset+i*cs:]
+		switch ac.ComponentType {
+		case compUByte:
+			idx[i] = uint32(d[0])
+		case compUShort:
+			idx[i] = uint32(binary.LittleEndian.Uint16(d))
+		case compUInt:
+			idx[i] = binary.LittleEndian.Uint32(d)
+		default:
+			return nil, fmt.Errorf("gltf.Decoder: index accessor %d has invalid component type: %d", ai, ac.ComponentType)
+		}
+	}
+	return idx, nil
+}
+
+func (dec *Decoder) appendWarn(msg string) {
+	dec.Warnings = append(dec.Warnings, fmt.Sprintf("%s: %s", dec.File, msg))
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2019, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"image"
+
+	"github.com/goki/gi/gi3d"
+	"github.com/goki/mat32"
+)
+
+// Doc is the top-level glTF JSON document -- only the elements used by
+// the Decoder are included.
+type Doc struct {
+	Scene       *int
+	Scenes      []SceneDef
+	Nodes       []Node
+	Meshes      []Mesh
+	Materials   []Material
+	Textures    []Texture
+	Images      []Image
+	Accessors   []Accessor
+	BufferViews []BufferView
+	Buffers     []Buffer
+	Animations  []Animation
+	Skins       []Skin
+}
+
+// SceneDef is a glTF scene, listing its root nodes
+type SceneDef struct {
+	Name  string
+	Nodes []int
+}
+
+// Node is a glTF node, with either a Matrix or separate
+// Translation, Rotation (quaternion X, Y, Z, W) and Scale
+type Node struct {
+	Name        string
+	Children    []int
+	Mesh        *int
+	Skin        *int
+	Matrix      []float32
+	Translation []float32
+	Rotation    []float32
+	Scale       []float32
+}
+
+// SetPose sets the given pose from the node's transform
+func (nd *Node) SetPose(ps *gi3d.Pose) {
+	ps.Defaults()
+	if len(nd.Matrix) == 16 {
+		var m mat32.Mat4
+		m.FromArray(nd.Matrix, 0)
+		ps.Pos, ps.Quat, ps.Scale = m.Decompose()
+		return
+	}
+	if len(nd.Translation) == 3 {
+		ps.Pos.FromArray(nd.Translation, 0)
+	}
+	if len(nd.Rotation) == 4 {
+		ps.Quat.FromArray(nd.Rotation, 0)
+	}
+	if len(nd.Scale) == 3 {
+		ps.Scale.FromArray(nd.Scale, 0)
+	}
+}
+
+// Mesh is a glTF mesh, consisting of primitives
+type Mesh struct {
+	Name       string
+	Primitives []Primitive
+}
+
+// Primitive is one drawable part of a glTF mesh, with vertex
+// attributes given as accessor indexes
+type Primitive struct {
+	Attributes map[string]int
+	Indices    *int
+	Material   *int
+	Mode       *int
+}
+
+// Material is a glTF metallic-roughness material
+type Material struct {
+	Name                 string
+	PBRMetallicRoughness PBRMetallicRoughness `json:"pbrMetallicRoughness"`
+	NormalTexture        *TextureInfo
+	OcclusionTexture     *TextureInfo
+	EmissiveTexture      *TextureInfo
+	EmissiveFactor       []float32
+	AlphaMode            string
+	DoubleSided          bool
+}
+
+// PBRMetallicRoughness are the metallic-roughness parameters of a Material
+type PBRMetallicRoughness struct {
+	BaseColorFactor          []float32
+	BaseColorTexture         *TextureInfo
+	MetallicFactor           *float32
+	RoughnessFactor          *float32
+	MetallicRoughnessTexture *TextureInfo
+}
+
+// TextureInfo refers to a texture from a Material -- Scale applies to
+// normal textures and Strength to occlusion textures
+type TextureInfo struct {
+	Index    int
+	TexCoord int
+	Scale    *float32
+	Strength *float32
+}
+
+// Texture is a glTF texture, referring to an Image
+type Texture struct {
+	Source *int
+}
+
+// Image is a glTF image, either in a file, a data uri, or a buffer view
+type Image struct {
+	Name       string
+	URI        string
+	MimeType   string
+	BufferView *int
+}
+
+// Accessor describes typed data within a BufferView
+type Accessor struct {
+	BufferView    *int
+	ByteOffset    int
+	ComponentType int
+	Normalized    bool
+	Count         int
+	Type          string
+}
+
+// BufferView is a contiguous region of a Buffer
+type BufferView struct {
+	Buffer     int
+	ByteOffset int
+	ByteLength int
+	ByteStride int
+}
+
+// Buffer is binary data, in a file, a data uri, or the glb binary chunk
+type Buffer struct {
+	URI        string
+	ByteLength int
+}
+
+// Animation is a glTF animation, with channels targeting node properties
+type Animation struct {
+	Name     string
+	Channels []AnimChannel
+	Samplers []AnimSampler
+}
+
+// AnimChannel connects an AnimSampler to a node property
+type AnimChannel struct {
+	Sampler int
+	Target  AnimTarget
+}
+
+// AnimTarget is the node and property (translation, rotation, scale,
+// weights) animated by an AnimChannel
+type AnimTarget struct {
+	Node *int
+	Path string
+}
+
+// AnimSampler gives the key times (Input) and values (Output) accessors,
+// and the interpolation (LINEAR, STEP, CUBICSPLINE)
+type AnimSampler struct {
+	Input         int
+	Output        int
+	Interpolation string
+}
+
+// Skin defines the joints (node indexes) of a skinned mesh, with an
+// accessor for the inverse bind matrix of each joint
+type Skin struct {
+	Name                string
+	InverseBindMatrices *int
+	Skeleton            *int
+	Joints              []int
+}
+
+// ImageTexture is a texture from an image embedded in the glTF data
+type ImageTexture struct {
+	gi3d.TextureBase
+	Img image.Image `view:"-" desc:"the decoded image"`
+}
+
+// Init initializes the texture and uploads the image to the GPU
+// Must be called in context on main thread
+func (tx *ImageTexture) Init(sc *gi3d.Scene) error {
+	if tx.Tex != nil {
+		return tx.TextureBase.Init(sc)
+	}
+	tex := tx.NewTex()
+	if err := tex.SetImage(tx.Img); err != nil {
+		return err
+	}
+	tex.Activate(0)
+	return nil
+}
+
+// Activate activates this texture on the GPU, in preparation for rendering
+// Must be called in context on main thread
+func (tx *ImageTexture) Activate(sc *gi3d.Scene, texNo int) {
+	if tx.Tex == nil {
+		tx.Init(sc)
+	}
+	tx.TextureBase.Activate(sc, texNo)
+}
@@ -41,6 +41,7 @@ type Material struct {
 	Tiling    Tiling   `view:"inline" viewif:"Texture!=''" desc:"texture tiling parameters -- repeat and offset"`
 	CullBack  bool     `xml:"cull-back" desc:"prop: cull-back = cull the back-facing surfaces"`
 	CullFront bool     `xml:"cull-front" desc:"prop: cull-front = cull the front-facing surfaces"`
+	PBR       PBR      `view:"inline" desc:"physically based rendering (metallic-roughness) parameters -- used instead of the Phong parameters when PBR.On is set"`
 	TexPtr    Texture  `view:"-" desc:"pointer to texture"`
 }
 
@@ -53,6 +54,7 @@ func (mt *Material) Defaults() {
 	mt.Bright = 1
 	mt.Tiling.Defaults()
 	mt.CullBack = true
+	mt.PBR.Defaults()
 }
 
 // IsTransparent returns true if texture says it is, or if color has alpha < 255
@@ -63,6 +65,11 @@ func (mt *Material) IsTransparent() bool {
 	return mt.Color.A < 255
 }
 
+// IsPBR returns true if this material uses physically based rendering
+func (mt *Material) IsPBR() bool {
+	return mt.PBR.On
+}
+
 // NoTexture resets any texture setting that might have been set
 func (mt *Material) NoTexture() {
 	mt.Texture = ""
@@ -113,5 +120,72 @@ func (mt *Material) Validate(sc *Scene) error {
 			return err
 		}
 	}
+	if mt.PBR.On {
+		return mt.PBR.Validate(sc)
+	}
 	return nil
 }
+
+//////////////////////////////////////////////////////////////////////////////////
+//  PBR
+
+// PBR contains the physically based rendering parameters for a Material,
+// using the metallic-roughness model as used in glTF 2.0.
+// The base color is the Material Color (and Texture if set), and the
+// emissive color is the Material Emissive color, multiplied by EmissiveTex.
+// Each of the maps is optional, and textures are accessed by name on Scene.
+type PBR struct {
+	On            bool    `xml:"pbr" desc:"prop: pbr = use physically based rendering instead of the Phong model, with the parameters below"`
+	Metallic      float32 `xml:"metallic" min:"0" max:"1" desc:"prop: metallic = how metallic the surface is: 0 = dielectric (plastic, wood, etc), 1 = metal -- metals have no diffuse color, and reflect with the base color"`
+	Roughness     float32 `xml:"roughness" min:"0" max:"1" desc:"prop: roughness = microfacet roughness of the surface: 0 = perfectly smooth mirror, 1 = completely rough and diffuse"`
+	OcclusionStr  float32 `min:"0" max:"1" desc:"strength of the ambient occlusion map effect, if OcclusionTex is set"`
+	NormalScale   float32 `desc:"scaling of the X and Y components of the normal map, if NormalTex is set"`
+	MetalRoughTex TexName `xml:"metal-rough-texture" desc:"prop: metal-rough-texture = texture with roughness in the green channel and metallic in the blue channel, multiplied by Roughness and Metallic"`
+	NormalTex     TexName `xml:"normal-texture" desc:"prop: normal-texture = tangent-space normal map texture"`
+	OcclusionTex  TexName `xml:"occlusion-texture" desc:"prop: occlusion-texture = ambient occlusion texture, using the red channel"`
+	EmissiveTex   TexName `xml:"emissive-texture" desc:"prop: emissive-texture = emissive color texture, multiplied by the Material Emissive color"`
+	MetalRoughPtr Texture `view:"-" desc:"pointer to metal-rough texture"`
+	NormalPtr     Texture `view:"-" desc:"pointer to normal texture"`
+	OcclusionPtr  Texture `view:"-" desc:"pointer to occlusion texture"`
+	EmissivePtr   Texture `view:"-" desc:"pointer to emissive texture"`
+}
+
+// Defaults sets default PBR parameters -- a non-metallic, moderately rough surface
+func (pb *PBR) Defaults() {
+	pb.Metallic = 0
+	pb.Roughness = 0.5
+	pb.OcclusionStr = 1
+	pb.NormalScale = 1
+}
+
+// Validate checks that all of the map textures are valid if set
+func (pb *PBR) Validate(sc *Scene) error {
+	var err error
+	if pb.MetalRoughPtr, err = pbrTexture(sc, pb.MetalRoughTex); err != nil {
+		return err
+	}
+	if pb.NormalPtr, err = pbrTexture(sc, pb.NormalTex); err != nil {
+		return err
+	}
+	if pb.OcclusionPtr, err = pbrTexture(sc, pb.OcclusionTex); err != nil {
+		return err
+	}
+	if pb.EmissivePtr, err = pbrTexture(sc, pb.EmissiveTex); err != nil {
+		return err
+	}
+	return nil
+}
+
+// pbrTexture returns the texture of given name on scene, nil if name is empty
+func pbrTexture(sc *Scene, nm TexName) (Texture, error) {
+	if nm == "" {
+		return nil, nil
+	}
+	tx, ok := sc.Textures[string(nm)]
+	if !ok {
+		err := fmt.Errorf("gi3d.Material in Scene: %s PBR texture name: %s not found in scene", sc.PathUnique(), nm)
+		log.Println(err)
+		return nil, err
+	}
+	return tx, nil
+}
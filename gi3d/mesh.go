@@ -26,7 +26,7 @@ type MeshName string
 // all are stored interleaved.  The Idx component points into
 // these elements as used in modern indexed VBO rendering.
 // Per-vertex Color is optional, and is appended to the vertex
-// buffer non-interleaved if present.
+// buffer non-interleaved if present, as are skinning Joints and Weights.
 type Mesh interface {
 	// Name returns name of the mesh
 	Name() string
@@ -104,6 +104,10 @@ type Mesh interface {
 	// HasColor returns true if this mesh has vertex-specific colors available
 	HasColor() bool
 
+	// HasSkin returns true if this mesh has joint indexes and weights for
+	// skinning by a Skeleton
+	HasSkin() bool
+
 	// IsTransparent returns true if this mesh has vertex-specific colors available
 	// and at least some are transparent.
 	IsTransparent() bool
@@ -173,6 +177,8 @@ type MeshBase struct {
 	Tex     mat32.ArrayF32 `desc:"texture U,V coordinates for mapping textures onto vertexes"`
 	Idx     mat32.ArrayU32 `desc:"indexes that sequentially in groups of 3 define the actual triangle faces"`
 	Color   mat32.ArrayF32 `desc:"if per-vertex color material type is used for this mesh, then these are the per-vertex colors -- may not be defined in which case per-vertex materials are not possible for such meshes"`
+	Joints  mat32.ArrayF32 `desc:"for skinned meshes, the indexes of up to 4 Skeleton joints (bones) influencing each vertex, stored as float values -- unused joints have 0 weight"`
+	Weights mat32.ArrayF32 `desc:"for skinned meshes, the weights of each of the 4 Joints for each vertex, which should sum to 1"`
 	BBox    BBox           `desc:"computed bounding-box and other gross solid properties"`
 	Buff    gpu.BufferMgr  `view:"-" desc:"buffer holding computed verticies, normals, indices, etc for rendering"`
 	BBoxMu  sync.RWMutex   `view:"-" copy:"-" json:"-" xml:"-" desc:"mutex on bbox access"`
@@ -196,6 +202,10 @@ func (ms *MeshBase) HasColor() bool {
 	return len(ms.Color) > 0
 }
 
+func (ms *MeshBase) HasSkin() bool {
+	return len(ms.Joints) > 0 && len(ms.Weights) > 0
+}
+
 func (ms *MeshBase) IsTransparent() bool {
 	if !ms.HasColor() {
 		return false
@@ -232,6 +242,8 @@ func (ms *MeshBase) Reset() {
 	ms.Tex = nil
 	ms.Idx = nil
 	ms.Color = nil
+	ms.Joints = nil
+	ms.Weights = nil
 	ms.BBoxMu.Lock()
 	ms.BBox.BBox.SetEmpty()
 	ms.BBoxMu.Unlock()
@@ -264,6 +276,13 @@ func (ms *MeshBase) Validate() error {
 		log.Println(err)
 		return err
 	}
+	jln := len(ms.Joints) / 4
+	wln := len(ms.Weights) / 4
+	if jln != wln || (jln != 0 && jln != vln) {
+		err := fmt.Errorf("gi3d.Mesh: %v number of Joints: %d and Weights: %d must both equal Vtx: %d", ms.Nm, jln, wln, vln)
+		log.Println(err)
+		return err
+	}
 	return nil
 }
 
@@ -297,10 +316,16 @@ func (ms *MeshBase) MakeVectors(sc *Scene) error {
 	if hasColor {
 		nvec++
 	}
+	hasSkin := ms.HasSkin()
+	if hasSkin {
+		nvec += 2
+	}
 	vtx := sc.Renders.Vectors[InVtxPos]
 	nrm := sc.Renders.Vectors[InVtxNorm]
 	tex := sc.Renders.Vectors[InVtxTex]
 	clr := sc.Renders.Vectors[InVtxColor]
+	jnt := sc.Renders.Vectors[InVtxJoints]
+	wgt := sc.Renders.Vectors[InVtxWeights]
 	if vbuf.NumVectors() != nvec {
 		vbuf.DeleteAllVectors()
 		vbuf.AddVectors(vtx, true) // interleave
@@ -311,6 +336,10 @@ func (ms *MeshBase) MakeVectors(sc *Scene) error {
 		if hasColor {
 			vbuf.AddVectors(clr, false) // NO interleave
 		}
+		if hasSkin {
+			vbuf.AddVectors(jnt, false)
+			vbuf.AddVectors(wgt, false)
+		}
 	}
 	vln := len(ms.Vtx) / 3
 	vbuf.SetLen(vln)
@@ -322,6 +351,10 @@ func (ms *MeshBase) MakeVectors(sc *Scene) error {
 	if hasColor {
 		vbuf.SetVecData(clr, ms.Color)
 	}
+	if hasSkin {
+		vbuf.SetVecData(jnt, ms.Joints)
+		vbuf.SetVecData(wgt, ms.Weights)
+	}
 	// fmt.Printf("mesh %v vecs:\n%v\n", ms.Nm, vbuf.AllData())
 
 	iln := len(ms.Idx)
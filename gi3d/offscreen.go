@@ -0,0 +1,271 @@
+// Copyright (c) 2019, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/mat32"
+)
+
+// NewOffscreenScene returns a new Scene that is not part of any window,
+// with Offscreen set so that it renders using the shared offscreen GPU
+// context, for headless batch rendering with RenderToImage and
+// FrameRecorder.  The GPU must have been initialized, e.g., by running
+// within gimain.Main -- on a headless machine, use a virtual X server
+// (e.g., xvfb-run) and / or a software GL (e.g., LIBGL_ALWAYS_SOFTWARE=1).
+// Call Init3D after configuring the scene, and DeleteResources when done.
+func NewOffscreenScene(name string) *Scene {
+	sc := &Scene{}
+	sc.InitName(sc, name)
+	sc.Defaults()
+	sc.Offscreen = true
+	return sc
+}
+
+// RenderToImage renders the scene to an offscreen framebuffer of given
+// size, with given multisampling number (4 = default for good antialiasing,
+// 0 if not hardware accelerated), and returns a copy of the resulting image.
+// Requires a visible window or the Offscreen flag -- the framebuffer is
+// retained in OffFrame for subsequent renders.
+func (sc *Scene) RenderToImage(size image.Point, msamp int) (*image.RGBA, error) {
+	if size.X <= 0 || size.Y <= 0 {
+		err := fmt.Errorf("gi3d.Scene: %s RenderToImage: invalid size: %v", sc.Nm, size)
+		log.Println(err)
+		return nil, err
+	}
+	if !sc.ActivateWin() {
+		err := fmt.Errorf("gi3d.Scene: %s RenderToImage: no visible window or Offscreen context to render in", sc.Nm)
+		log.Println(err)
+		return nil, err
+	}
+	if sc.Renders.Renders == nil {
+		sc.Init3D()
+	}
+	if sc.OffFrame != nil && sc.OffFrame.Samples() != msamp {
+		oswin.TheApp.RunOnMain(func() {
+			sc.OffFrame.SetSamples(msamp)
+		})
+	}
+	sc.ActivateOffFrame(&sc.OffFrame, sc.Nm+"-off-frame", size, msamp)
+	sc.UpdateNodes3D()
+	sc.RenderOffFrame()
+	var img *image.RGBA
+	oswin.TheApp.RunOnMain(func() {
+		sc.OffFrame.Rendered()
+		gim, ok := sc.OffFrame.Texture().GrabImage().(*image.RGBA)
+		if !ok || gim == nil {
+			return
+		}
+		img = image.NewRGBA(gim.Rect)
+		copy(img.Pix, gim.Pix)
+	})
+	if img == nil {
+		err := fmt.Errorf("gi3d.Scene: %s RenderToImage: could not grab rendered image", sc.Nm)
+		log.Println(err)
+		return nil, err
+	}
+	return img, nil
+}
+
+/////////////////////////////////////////////////////////////////
+//  CameraPath
+
+// CameraPath is a deterministic path for the camera over time, given by
+// keyframes of the camera position and the target it looks at.
+// Up is the up direction used in LookAt, default Y.
+type CameraPath struct {
+	Pos    AnimTrack  `desc:"keyframes of the camera position"`
+	Target AnimTrack  `desc:"keyframes of the target point the camera looks at"`
+	Up     mat32.Vec3 `desc:"up direction of the camera"`
+}
+
+// NewCameraPath returns a new camera path using given interpolation
+func NewCameraPath(interp AnimInterps) *CameraPath {
+	cp := &CameraPath{}
+	cp.Pos.Target = AnimPos
+	cp.Pos.Interp = interp
+	cp.Target.Target = AnimPos
+	cp.Target.Interp = interp
+	cp.Up = mat32.Vec3Y
+	return cp
+}
+
+// NewOrbitPath returns a camera path that orbits once around the Y axis
+// through given center over given duration in seconds, at given radius
+// and height above the center, starting on the +Z axis, always looking
+// at the center.  Uses nkeys keys (minimum 8) with cubic interpolation.
+func NewOrbitPath(center mat32.Vec3, radius, height, dur float32, nkeys int) *CameraPath {
+	if nkeys < 8 {
+		nkeys = 8
+	}
+	cp := NewCameraPath(AnimCubic)
+	angv := 2 * mat32.Pi / dur // angular velocity, for the tangents
+	for i := 0; i <= nkeys; i++ {
+		f := float32(i) / float32(nkeys)
+		ang := f * 2 * mat32.Pi
+		pos := center.Add(mat32.NewVec3(radius*mat32.Sin(ang), height, radius*mat32.Cos(ang)))
+		cp.AddKey(f*dur, pos, center)
+		// exact tangents of the circle -- Catmull-Rom tangents are
+		// one-sided at the first and last keys, flattening the orbit there
+		cp.Pos.OutTans = append(cp.Pos.OutTans, radius*angv*mat32.Cos(ang), 0, -radius*angv*mat32.Sin(ang))
+	}
+	cp.Pos.InTans = cp.Pos.OutTans
+	return cp
+}
+
+// AddKey adds a key at given time with given camera position and target.
+// Keys must be added in increasing time order.
+func (cp *CameraPath) AddKey(t float32, pos, target mat32.Vec3) {
+	cp.Pos.AddVec3Key(t, pos)
+	cp.Target.AddVec3Key(t, target)
+}
+
+// Duration returns the time of the last key
+func (cp *CameraPath) Duration() float32 {
+	return mat32.Max(cp.Pos.Duration(), cp.Target.Duration())
+}
+
+// Apply sets the camera of the scene to the path at given time
+func (cp *CameraPath) Apply(sc *Scene, t float32) {
+	var pos, trg [3]float32
+	if !cp.Pos.Value(t, pos[:]) {
+		return
+	}
+	if !cp.Target.Value(t, trg[:]) {
+		trg = [3]float32{}
+	}
+	up := cp.Up
+	if up.IsNil() {
+		up = mat32.Vec3Y
+	}
+	sc.Camera.Pose.Pos = mat32.NewVec3(pos[0], pos[1], pos[2])
+	sc.Camera.LookAt(mat32.NewVec3(trg[0], trg[1], trg[2]), up)
+}
+
+/////////////////////////////////////////////////////////////////
+//  FrameRecorder
+
+// FrameRecorder renders a sequence of frames from a Scene to numbered
+// PNG files, at a fixed frame rate, for making movies.  Time advances
+// deterministically by 1 / FPS per frame, independent of rendering speed,
+// and drives the optional camera path and animation clip.
+type FrameRecorder struct {
+	Dir      string                                 `desc:"directory to save the frames in -- created if it does not exist"`
+	Prefix   string                                 `desc:"file name prefix for frames, which are named Prefix_00000.png etc"`
+	Size     image.Point                            `desc:"size of the rendered frames"`
+	MSamp    int                                    `desc:"multisampling number -- 4 = default for good antialiasing, 0 if not hardware accelerated"`
+	FPS      float32                                `desc:"frames per second of time"`
+	Duration float32                                `desc:"total duration to record in seconds -- if 0, the longer of the CamPath and Anim durations is used"`
+	CamPath  *CameraPath                            `desc:"if non-nil, the camera follows this path"`
+	Anim     string                                 `desc:"if non-empty, the name of the animation clip in the scene to apply at each frame"`
+	FrameFun func(sc *Scene, frame int, t float32)  `view:"-" json:"-" xml:"-" desc:"if non-nil, called before rendering each frame, e.g., to update the scene from simulation state"`
+	SaveFun  func(frame int, img *image.RGBA) error `view:"-" json:"-" xml:"-" desc:"if non-nil, called with each rendered frame instead of saving to a file, e.g., to pipe into a video encoder"`
+}
+
+// Defaults sets default parameters
+func (fr *FrameRecorder) Defaults() {
+	fr.Dir = "."
+	fr.Prefix = "frame"
+	fr.Size = image.Point{1280, 720}
+	fr.MSamp = 4
+	fr.FPS = 30
+}
+
+// NFrames returns the number of frames that Record will render
+func (fr *FrameRecorder) NFrames(sc *Scene) int {
+	dur := fr.Duration
+	if dur == 0 {
+		if fr.CamPath != nil {
+			dur = fr.CamPath.Duration()
+		}
+		if fr.Anim != "" {
+			if ac := sc.AnimByName(fr.Anim); ac != nil {
+				dur = mat32.Max(dur, ac.Duration())
+			}
+		}
+	}
+	return int(dur*fr.FPS) + 1
+}
+
+// FileName returns the file name for given frame number
+func (fr *FrameRecorder) FileName(frame int) string {
+	return filepath.Join(fr.Dir, fmt.Sprintf("%s_%05d.png", fr.Prefix, frame))
+}
+
+// Record renders all the frames, saving each one, and returns the number
+// of frames recorded.  Stops at the first error.
+func (fr *FrameRecorder) Record(sc *Scene) (int, error) {
+	if fr.FPS <= 0 {
+		fr.FPS = 30
+	}
+	if fr.Size.X == 0 || fr.Size.Y == 0 {
+		fr.Size = image.Point{1280, 720}
+	}
+	if fr.Prefix == "" {
+		fr.Prefix = "frame"
+	}
+	var ac *AnimClip
+	if fr.Anim != "" {
+		var err error
+		ac, err = sc.AnimByNameTry(fr.Anim)
+		if err != nil {
+			log.Println(err)
+			return 0, err
+		}
+	}
+	if fr.SaveFun == nil && fr.Dir != "" {
+		if err := os.MkdirAll(fr.Dir, 0755); err != nil {
+			log.Println(err)
+			return 0, err
+		}
+	}
+	if fr.Duration == 0 && fr.CamPath == nil && ac == nil {
+		err := errors.New("gi3d.FrameRecorder: no Duration, CamPath or Anim to record")
+		log.Println(err)
+		return 0, err
+	}
+	nf := fr.NFrames(sc)
+	for i := 0; i < nf; i++ {
+		if err := fr.RecordFrame(sc, ac, i); err != nil {
+			return i, err
+		}
+	}
+	return nf, nil
+}
+
+// RecordFrame renders and saves given frame number, applying the camera
+// path and given animation clip (can be nil) at the time of the frame
+func (fr *FrameRecorder) RecordFrame(sc *Scene, ac *AnimClip, frame int) error {
+	t := float32(frame) / fr.FPS
+	if fr.CamPath != nil {
+		fr.CamPath.Apply(sc, t)
+	}
+	if ac != nil {
+		ac.Apply(sc, t)
+	}
+	if fr.FrameFun != nil {
+		fr.FrameFun(sc, frame, t)
+	}
+	img, err := sc.RenderToImage(fr.Size, fr.MSamp)
+	if err != nil {
+		return err
+	}
+	if fr.SaveFun != nil {
+		return fr.SaveFun(frame, img)
+	}
+	err = gi.SaveImage(fr.FileName(frame), img)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"image"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/mat32"
+)
+
+func TestCameraPath(t *testing.T) {
+	sc := NewOffscreenScene("offscreen")
+	cp := NewCameraPath(AnimLinear)
+	cp.AddKey(0, mat32.Vec3{0, 0, 10}, mat32.Vec3{})
+	cp.AddKey(2, mat32.Vec3{10, 0, 10}, mat32.Vec3{4, 0, 0})
+	if d := cp.Duration(); d != 2 {
+		t.Errorf("Duration = %v, expected 2", d)
+	}
+	cp.Apply(sc, 1)
+	if pos := sc.Camera.Pose.Pos; pos != (mat32.Vec3{5, 0, 10}) {
+		t.Errorf("Apply(1): camera at %v, expected {5 0 10}", pos)
+	}
+	if trg := sc.Camera.Target; trg != (mat32.Vec3{2, 0, 0}) {
+		t.Errorf("Apply(1): camera target %v, expected {2 0 0}", trg)
+	}
+	cp.Apply(sc, 5) // holds the last key
+	if pos := sc.Camera.Pose.Pos; pos != (mat32.Vec3{10, 0, 10}) {
+		t.Errorf("Apply(5): camera at %v, expected {10 0 10}", pos)
+	}
+}
+
+func TestOrbitPath(t *testing.T) {
+	sc := NewOffscreenScene("offscreen")
+	ctr := mat32.Vec3{1, 2, 3}
+	cp := NewOrbitPath(ctr, 10, 5, 4, 2)
+	if nk := cp.Pos.NKeys(); nk != 9 {
+		t.Errorf("NewOrbitPath: %v keys, expected minimum 8 + 1", nk)
+	}
+	tests := []struct {
+		t   float32
+		pos mat32.Vec3
+	}{
+		{0, mat32.Vec3{1, 7, 13}},
+		{1, mat32.Vec3{11, 7, 3}},
+		{2, mat32.Vec3{1, 7, -7}},
+		{3, mat32.Vec3{-9, 7, 3}},
+		{4, mat32.Vec3{1, 7, 13}},
+	}
+	for _, tst := range tests {
+		cp.Apply(sc, tst.t)
+		if pos := sc.Camera.Pose.Pos; pos.Sub(tst.pos).Length() > 1e-3 {
+			t.Errorf("Apply(%v): camera at %v, expected %v", tst.t, pos, tst.pos)
+		}
+		if trg := sc.Camera.Target; trg.Sub(ctr).Length() > 1e-4 {
+			t.Errorf("Apply(%v): camera target %v, expected center %v", tst.t, trg, ctr)
+		}
+	}
+	// in between keys, stays close to the circle, including at the ends
+	for tm := float32(0.25); tm < 4; tm += 0.5 {
+		cp.Apply(sc, tm)
+		off := sc.Camera.Pose.Pos.Sub(ctr)
+		off.Y = 0
+		if r := off.Length(); mat32.Abs(r-10) > 0.01 {
+			t.Errorf("Apply(%v): orbit radius %v, expected 10", tm, r)
+		}
+	}
+}
+
+func TestFrameRecorder(t *testing.T) {
+	sc := NewOffscreenScene("offscreen")
+	fr := &FrameRecorder{}
+	fr.Defaults()
+	fr.Dir = filepath.Join("out", "frames")
+	if fn, exp := fr.FileName(12), filepath.Join("out", "frames", "frame_00012.png"); fn != exp {
+		t.Errorf("FileName(12) = %v, expected %v", fn, exp)
+	}
+	if n, err := fr.Record(sc); n != 0 || err == nil {
+		t.Errorf("Record with nothing to record: got %v, %v, expected error", n, err)
+	}
+
+	fr.Duration = 2
+	fr.FPS = 10
+	if nf := fr.NFrames(sc); nf != 21 {
+		t.Errorf("NFrames with Duration: %v, expected 21", nf)
+	}
+	fr.Duration = 0
+	fr.CamPath = NewCameraPath(AnimLinear)
+	fr.CamPath.AddKey(0, mat32.Vec3{}, mat32.Vec3{})
+	fr.CamPath.AddKey(1, mat32.Vec3{}, mat32.Vec3{})
+	ac := AddNewAnimClip(sc, "clip")
+	tr := &AnimTrack{Target: AnimPos, Interp: AnimLinear}
+	tr.AddVec3Key(0, mat32.Vec3{})
+	tr.AddVec3Key(3, mat32.Vec3{})
+	ac.Tracks = append(ac.Tracks, tr)
+	if nf := fr.NFrames(sc); nf != 11 {
+		t.Errorf("NFrames with CamPath: %v, expected 11", nf)
+	}
+	fr.Anim = "clip"
+	if nf := fr.NFrames(sc); nf != 31 {
+		t.Errorf("NFrames with CamPath and Anim: %v, expected 31", nf)
+	}
+	fr.Anim = "none"
+	if n, err := fr.Record(sc); n != 0 || err == nil {
+		t.Errorf("Record with missing Anim: got %v, %v, expected error", n, err)
+	}
+
+	// without a GPU, the first frame cannot be rendered
+	fr.Anim = ""
+	nsave := 0
+	fr.SaveFun = func(frame int, img *image.RGBA) error { nsave++; return nil }
+	if n, err := fr.Record(sc); n != 0 || err == nil || nsave != 0 {
+		t.Errorf("Record without GPU: got %v, %v, %v saved, expected error", n, err, nsave)
+	}
+	if pos := sc.Camera.Pose.Pos; pos != (mat32.Vec3{}) {
+		t.Errorf("RecordFrame(0): camera path not applied, camera at %v", pos)
+	}
+}
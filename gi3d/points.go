@@ -0,0 +1,502 @@
+// Copyright (c) 2019, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"math/rand"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/gpu"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// PointShapes are the shapes used for drawing the points of a PointCloud
+type PointShapes int32
+
+const (
+	// PointSquare draws each point as a flat screen-aligned square
+	PointSquare PointShapes = iota
+
+	// PointDisc draws each point as a flat screen-aligned disc
+	PointDisc
+
+	// PointSphere draws each point as a screen-aligned disc shaded as a
+	// sphere, lit by the Scene lights
+	PointSphere
+
+	PointShapesN
+)
+
+//go:generate stringer -type=PointShapes
+
+var KiT_PointShapes = kit.Enums.AddEnum(PointShapesN, kit.NotBitFlag, nil)
+
+/////////////////////////////////////////////////////////////////
+//  PointsMesh
+
+// PointsMesh is a Mesh of points, rendered by a PointCloud.  Vtx has the
+// point positions, Color the per-point colors, and Tex holds the per-point
+// size factor in U (V is unused) -- there are no Norms.
+// Idx holds a random permutation of the points, which is maintained as
+// points are appended, so that any prefix of Idx is a uniform sample of
+// the points -- this is used for level-of-detail decimation, by drawing
+// only the first NDraw indexes.  Points can be added from any goroutine,
+// and are transferred to the GPU at the next render.
+type PointsMesh struct {
+	MeshBase
+	NDraw   int        `desc:"number of points to draw, in Idx order -- set by the PointCloud level of detail"`
+	Changed bool       `view:"-" desc:"points have changed since last transfer to the GPU"`
+	Mu      sync.Mutex `view:"-" copy:"-" json:"-" xml:"-" desc:"mutex on point data"`
+	rand    *rand.Rand
+}
+
+var KiT_PointsMesh = kit.Types.AddType(&PointsMesh{}, nil)
+
+// AddNewPointsMesh adds a new empty PointsMesh to given scene, with given
+// name, which is made unique if there is already a mesh of that name.
+func AddNewPointsMesh(sc *Scene, name string) *PointsMesh {
+	pm := &PointsMesh{}
+	pm.Nm = name
+	pm.Dynamic = true
+	pm.BBox.BBox.SetEmpty()
+	sc.AddMeshUnique(pm)
+	return pm
+}
+
+// NPoints returns the number of points
+func (pm *PointsMesh) NPoints() int {
+	pm.Mu.Lock()
+	defer pm.Mu.Unlock()
+	return len(pm.Vtx) / 3
+}
+
+// Make does nothing: points are added directly with AddPoint(s)
+func (pm *PointsMesh) Make(sc *Scene) {
+}
+
+// Reset removes all the points
+func (pm *PointsMesh) Reset() {
+	pm.Mu.Lock()
+	pm.MeshBase.Reset()
+	pm.NDraw = 0
+	pm.rand = nil
+	pm.Changed = true
+	pm.Mu.Unlock()
+}
+
+// AddPoint adds a point at given position with given color and size factor
+func (pm *PointsMesh) AddPoint(pos mat32.Vec3, clr gi.Color, size float32) {
+	pm.Mu.Lock()
+	pm.addPoint(pos, clr, size)
+	pm.updateBBox()
+	pm.Mu.Unlock()
+}
+
+// AddPoints adds points at given positions, all with given color and
+// size factor
+func (pm *PointsMesh) AddPoints(pos []mat32.Vec3, clr gi.Color, size float32) {
+	pm.Mu.Lock()
+	for _, p := range pos {
+		pm.addPoint(p, clr, size)
+	}
+	pm.updateBBox()
+	pm.Mu.Unlock()
+}
+
+// addPoint adds one point -- must be called under lock
+func (pm *PointsMesh) addPoint(pos mat32.Vec3, clr gi.Color, size float32) {
+	n := len(pm.Vtx) / 3
+	if n == 0 {
+		pm.BBoxMu.Lock()
+		pm.BBox.BBox.SetEmpty()
+		pm.BBoxMu.Unlock()
+	}
+	pm.Vtx = append(pm.Vtx, pos.X, pos.Y, pos.Z)
+	pm.Tex = append(pm.Tex, size, 0)
+	r, g, b, a := clr.ToFloat32()
+	pm.Color = append(pm.Color, r, g, b, a)
+	if a < 1 {
+		pm.Trans = true
+	}
+	// inside-out Fisher-Yates shuffle: keeps Idx a uniform random permutation
+	if pm.rand == nil {
+		pm.rand = rand.New(rand.NewSource(1))
+	}
+	j := pm.rand.Intn(n + 1)
+	if j == n {
+		pm.Idx = append(pm.Idx, uint32(n))
+	} else {
+		pm.Idx = append(pm.Idx, pm.Idx[j])
+		pm.Idx[j] = uint32(n)
+	}
+	pm.BBoxMu.Lock()
+	pm.BBox.BBox.ExpandByPoint(pos)
+	pm.BBoxMu.Unlock()
+	pm.Changed = true
+}
+
+// updateBBox updates the other bounding box factors from the BBox
+func (pm *PointsMesh) updateBBox() {
+	pm.BBoxMu.Lock()
+	pm.BBox.UpdateFmBBox()
+	pm.BBoxMu.Unlock()
+}
+
+// PointPos returns the position of given point
+func (pm *PointsMesh) PointPos(idx int) mat32.Vec3 {
+	pm.Mu.Lock()
+	defer pm.Mu.Unlock()
+	var p mat32.Vec3
+	p.FromArray(pm.Vtx, idx*3)
+	return p
+}
+
+// SetPointPos sets the position of given point -- the bounding box is
+// only expanded, not shrunk
+func (pm *PointsMesh) SetPointPos(idx int, pos mat32.Vec3) {
+	pm.Mu.Lock()
+	pos.ToArray(pm.Vtx, idx*3)
+	pm.BBoxMu.Lock()
+	pm.BBox.BBox.ExpandByPoint(pos)
+	pm.BBox.UpdateFmBBox()
+	pm.BBoxMu.Unlock()
+	pm.Changed = true
+	pm.Mu.Unlock()
+}
+
+// SetPointColor sets the color of given point
+func (pm *PointsMesh) SetPointColor(idx int, clr gi.Color) {
+	pm.Mu.Lock()
+	r, g, b, a := clr.ToFloat32()
+	pm.Color.Set(idx*4, r, g, b, a)
+	if a < 1 {
+		pm.Trans = true
+	}
+	pm.Changed = true
+	pm.Mu.Unlock()
+}
+
+// SetPointSize sets the size factor of given point
+func (pm *PointsMesh) SetPointSize(idx int, size float32) {
+	pm.Mu.Lock()
+	pm.Tex[idx*2] = size
+	pm.Changed = true
+	pm.Mu.Unlock()
+}
+
+// Validate checks if all the point data is valid
+func (pm *PointsMesh) Validate() error {
+	vln := len(pm.Vtx) / 3
+	if len(pm.Tex)/2 != vln || len(pm.Color)/4 != vln || len(pm.Idx) != vln {
+		err := fmt.Errorf("gi3d.PointsMesh: %v number of Tex: %d, Color: %d, Idx: %d != Vtx: %d", pm.Nm, len(pm.Tex)/2, len(pm.Color)/4, len(pm.Idx), vln)
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// MakeVectors compiles the existing point data into the Vectors for GPU rendering
+// Must be called with relevant context active on main thread
+func (pm *PointsMesh) MakeVectors(sc *Scene) error {
+	pm.Mu.Lock()
+	defer pm.Mu.Unlock()
+	return pm.makeVectors(sc)
+}
+
+// makeVectors does MakeVectors under lock
+func (pm *PointsMesh) makeVectors(sc *Scene) error {
+	err := pm.Validate()
+	if err != nil {
+		return err
+	}
+	vln := len(pm.Vtx) / 3
+	if vln == 0 && pm.Buff == nil {
+		return nil
+	}
+	var vbuf gpu.VectorsBuffer
+	var ibuf gpu.IndexesBuffer
+	if pm.Buff == nil {
+		pm.Buff = gpu.TheGPU.NewBufferMgr()
+		vbuf = pm.Buff.AddVectorsBuffer(gpu.DynamicDraw)
+		ibuf = pm.Buff.AddIndexesBuffer(gpu.DynamicDraw)
+	} else {
+		vbuf = pm.Buff.VectorsBuffer()
+		ibuf = pm.Buff.IndexesBuffer()
+	}
+	vtx := sc.Renders.Vectors[InVtxPos]
+	tex := sc.Renders.Vectors[InVtxTex]
+	clr := sc.Renders.Vectors[InVtxColor]
+	if vbuf.NumVectors() != 3 {
+		vbuf.DeleteAllVectors()
+		vbuf.AddVectors(vtx, true) // interleave
+		vbuf.AddVectors(tex, true) // interleave
+		vbuf.AddVectors(clr, false)
+	}
+	vbuf.SetLen(vln)
+	vbuf.SetVecData(vtx, pm.Vtx)
+	vbuf.SetVecData(tex, pm.Tex)
+	vbuf.SetVecData(clr, pm.Color)
+	ibuf.SetLen(len(pm.Idx))
+	ibuf.Set(pm.Idx)
+	pm.Changed = false
+	return nil
+}
+
+// Activate activates the mesh Vectors on the GPU
+// Must be called with relevant context active on main thread
+func (pm *PointsMesh) Activate(sc *Scene) bool {
+	pm.Mu.Lock()
+	defer pm.Mu.Unlock()
+	return pm.activate(sc)
+}
+
+// activate does Activate under lock
+func (pm *PointsMesh) activate(sc *Scene) bool {
+	if pm.Buff == nil {
+		pm.makeVectors(sc)
+	}
+	if pm.Buff == nil {
+		return false
+	}
+	pm.Buff.Activate()
+	return true
+}
+
+// TransferAll transfer all buffer data to GPU (vectors and indexes)
+// Activate must have just been called, assumed to be on main with context
+func (pm *PointsMesh) TransferAll() {
+	if pm.Buff != nil {
+		pm.Buff.TransferAll()
+	}
+}
+
+// Update transfers any changed points to the GPU.
+// Must be called with relevant context active on main thread
+func (pm *PointsMesh) Update(sc *Scene) {
+	pm.Mu.Lock()
+	defer pm.Mu.Unlock()
+	pm.update(sc)
+}
+
+// update does Update under lock
+func (pm *PointsMesh) update(sc *Scene) {
+	if !pm.Changed {
+		return
+	}
+	if pm.makeVectors(sc) != nil {
+		return
+	}
+	if pm.activate(sc) {
+		pm.Buff.TransferAll()
+	}
+}
+
+// Render3D transfers any changed points to the GPU, and draws the first
+// NDraw points in Idx order.
+// Must be called in context on main thread
+func (pm *PointsMesh) Render3D(sc *Scene) {
+	pm.Mu.Lock()
+	defer pm.Mu.Unlock()
+	pm.update(sc)
+	n := pm.NDraw
+	if n <= 0 || n > len(pm.Idx) {
+		n = len(pm.Idx)
+	}
+	if n == 0 || !pm.activate(sc) {
+		return
+	}
+	gpu.Draw.PointsIndexed(0, n)
+}
+
+/////////////////////////////////////////////////////////////////
+//  PointCloud
+
+// PointCloud is a Solid that renders a PointsMesh of points, each with
+// its own color and size, as screen-aligned squares, discs or spheres of
+// PointSize pixels.  Points can be appended incrementally (from any
+// goroutine -- call UpdateSig on the Scene to re-render).  For large
+// numbers of points, level-of-detail decimation draws a random sample of
+// the points, depending on the distance of the Camera (LODDist) and / or
+// a fixed budget (MaxPoints).  PointAt picks individual points.
+type PointCloud struct {
+	Solid
+	PointSize float32     `desc:"size of points in pixels, multiplied by the per-point size factor"`
+	SizeAtten float32     `desc:"if > 0, point sizes are attenuated by distance from the camera, as SizeAtten / distance -- i.e., points are PointSize pixels at distance SizeAtten"`
+	Shape     PointShapes `desc:"shape of each point"`
+	LODDist   float32     `desc:"level of detail: if > 0, all points are drawn when the camera is within this distance of the bounding box of the points, and beyond that the fraction of points drawn falls off as (LODDist / distance)^2"`
+	LODMin    float32     `min:"0" max:"1" desc:"minimum fraction of points to draw under level of detail"`
+	MaxPoints int         `desc:"if > 0, the maximum number of points to draw -- a random sample of points is drawn if there are more"`
+}
+
+var KiT_PointCloud = kit.Types.AddType(&PointCloud{}, PointCloudProps)
+
+// AddNewPointCloud adds a new point cloud of given name to given parent,
+// with a new empty PointsMesh of the same name (made unique) in the Scene
+func AddNewPointCloud(sc *Scene, parent ki.Ki, name string) *PointCloud {
+	pc := parent.AddNewChild(KiT_PointCloud, name).(*PointCloud)
+	pc.Defaults()
+	pm := AddNewPointsMesh(sc, name)
+	pc.SetMesh(sc, pm)
+	return pc
+}
+
+func (pc *PointCloud) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*PointCloud)
+	pc.Solid.CopyFieldsFrom(&fr.Solid)
+	pc.PointSize = fr.PointSize
+	pc.SizeAtten = fr.SizeAtten
+	pc.Shape = fr.Shape
+	pc.LODDist = fr.LODDist
+	pc.LODMin = fr.LODMin
+	pc.MaxPoints = fr.MaxPoints
+}
+
+func (pc *PointCloud) Defaults() {
+	pc.Solid.Defaults()
+	pc.PointSize = 4
+	pc.Shape = PointDisc
+	pc.LODMin = 0.05
+}
+
+// Points returns the PointsMesh for this point cloud, or nil if none
+func (pc *PointCloud) Points() *PointsMesh {
+	pm, _ := pc.MeshPtr.(*PointsMesh)
+	return pm
+}
+
+// AddPoint adds a point at given position with given color and size factor
+func (pc *PointCloud) AddPoint(pos mat32.Vec3, clr gi.Color, size float32) {
+	if pm := pc.Points(); pm != nil {
+		pm.AddPoint(pos, clr, size)
+	}
+}
+
+// AddPoints adds points at given positions, all with given color and
+// size factor
+func (pc *PointCloud) AddPoints(pos []mat32.Vec3, clr gi.Color, size float32) {
+	if pm := pc.Points(); pm != nil {
+		pm.AddPoints(pos, clr, size)
+	}
+}
+
+// LODCount returns the number of points to draw out of n total points,
+// based on the level of detail parameters and the current Camera
+func (pc *PointCloud) LODCount(sc *Scene, n int) int {
+	cnt := n
+	if pc.LODDist > 0 && n > 0 {
+		pc.BBoxMu.RLock()
+		wbb := pc.WorldBBox.BBox
+		pc.BBoxMu.RUnlock()
+		sc.Camera.CamMu.RLock()
+		cpos := sc.Camera.Pose.Pos
+		sc.Camera.CamMu.RUnlock()
+		d := wbb.DistToPoint(cpos)
+		if d > pc.LODDist {
+			f := pc.LODDist / d
+			f = mat32.Max(f*f, pc.LODMin)
+			cnt = int(f * float32(n))
+		}
+	}
+	if pc.MaxPoints > 0 && cnt > pc.MaxPoints {
+		cnt = pc.MaxPoints
+	}
+	return cnt
+}
+
+// PointAt returns the index of the point drawn at given 2D position in
+// Scene coordinates (e.g., from a mouse event, relative to the Scene BBox),
+// or -1 if none.  Points within given radius in pixels are considered
+// (if radius <= 0, the drawn size of each point is used), and the one
+// closest to the camera is returned.  Only points drawn under the current
+// level of detail are considered.
+func (pc *PointCloud) PointAt(sc *Scene, pos image.Point, radius float32) int {
+	pm := pc.Points()
+	if pm == nil {
+		return -1
+	}
+	sz := sc.Geom.Size
+	size := mat32.Vec2{float32(sz.X), float32(sz.Y)}
+	fpos := mat32.Vec2{float32(pos.X), float32(pos.Y)}
+	pc.PoseMu.RLock()
+	mvp := pc.Pose.MVPMatrix
+	mv := pc.Pose.MVMatrix
+	pc.PoseMu.RUnlock()
+	pm.Mu.Lock()
+	defer pm.Mu.Unlock()
+	n := pm.NDraw
+	if n <= 0 || n > len(pm.Idx) {
+		n = len(pm.Idx)
+	}
+	best := -1
+	var bestz float32
+	var p mat32.Vec3
+	for i := 0; i < n; i++ {
+		pi := int(pm.Idx[i])
+		p.FromArray(pm.Vtx, pi*3)
+		ndc := p.MVProjToNDC(&mvp, 1)
+		if ndc.Z < -1 || ndc.Z > 1 {
+			continue
+		}
+		w := ndc.NDCToWindow(size, mat32.Vec2{}, 0, 1, true) // true = flipY
+		rad := radius
+		if rad <= 0 {
+			rad = 0.5 * pc.PointSize * pm.Tex[pi*2]
+			if pc.SizeAtten > 0 {
+				vp := p.MulMat4(&mv)
+				rad *= pc.SizeAtten / mat32.Max(-vp.Z, 0.0001)
+			}
+			rad = mat32.Max(rad, 2)
+		}
+		dx := w.X - fpos.X
+		dy := w.Y - fpos.Y
+		if dx*dx+dy*dy > rad*rad {
+			continue
+		}
+		if best < 0 || w.Z < bestz {
+			best = pi
+			bestz = w.Z
+		}
+	}
+	return best
+}
+
+// RenderClass returns the class of rendering for this point cloud
+func (pc *PointCloud) RenderClass() RenderClasses {
+	if pc.IsTransparent() {
+		return RClassTransPoints
+	}
+	return RClassOpaquePoints
+}
+
+// Render3D renders the points, using the level of detail
+func (pc *PointCloud) Render3D(sc *Scene, rc RenderClasses, rnd Render) {
+	rndp, ok := rnd.(*RenderPoints)
+	if !ok {
+		return
+	}
+	pm := pc.Points()
+	if pm == nil {
+		return
+	}
+	pm.Mu.Lock()
+	pm.NDraw = pc.LODCount(sc, len(pm.Vtx)/3)
+	pm.Mu.Unlock()
+	rndp.SetPoints(pc, sc)
+	pc.PoseMu.RLock()
+	sc.Renders.SetMatrix(&pc.Pose)
+	pc.PoseMu.RUnlock()
+	pm.Render3D(sc)
+	gpu.TheGPU.ErrCheck("points render")
+}
+
+var PointCloudProps = ki.Props{
+	"EnumType:Flag": gi.KiT_NodeFlags,
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/mat32"
+)
+
+func TestPointsMesh(t *testing.T) {
+	sc := NewOffscreenScene("offscreen")
+	pm := AddNewPointsMesh(sc, "points")
+	var clr gi.Color
+	clr.SetUInt8(255, 0, 0, 255)
+	n := 1000
+	pos := make([]mat32.Vec3, n)
+	for i := range pos {
+		pos[i] = mat32.Vec3{float32(i), -float32(i), 1}
+	}
+	pm.AddPoints(pos, clr, 1)
+	pm.AddPoint(mat32.Vec3{-5, 0, 0}, clr, 2)
+	n++
+	if pm.NPoints() != n || pm.Validate() != nil || pm.Trans {
+		t.Fatalf("AddPoints: NPoints %v, expected %v, Validate %v, Trans %v", pm.NPoints(), n, pm.Validate(), pm.Trans)
+	}
+	// Idx is a permutation of all the points, with a random order
+	seen := make([]bool, n)
+	inorder := 0
+	for i, ix := range pm.Idx {
+		seen[ix] = true
+		if int(ix) == i {
+			inorder++
+		}
+	}
+	for i, s := range seen {
+		if !s {
+			t.Fatalf("Idx is missing point %v", i)
+		}
+	}
+	if inorder > n/10 {
+		t.Errorf("Idx is not shuffled: %v of %v in order", inorder, n)
+	}
+	bb := pm.BBox.BBox
+	if bb.Min != (mat32.Vec3{-5, -999, 0}) || bb.Max != (mat32.Vec3{999, 0, 1}) {
+		t.Errorf("BBox = %v, expected {-5 -999 0} - {999 0 1}", bb)
+	}
+
+	pm.SetPointPos(1, mat32.Vec3{0, 0, 10})
+	if p := pm.PointPos(1); p != (mat32.Vec3{0, 0, 10}) || pm.BBox.BBox.Max.Z != 10 {
+		t.Errorf("SetPointPos: PointPos %v, BBox max %v", p, pm.BBox.BBox.Max)
+	}
+	clr.A = 128
+	pm.SetPointColor(2, clr)
+	if !pm.Trans {
+		t.Errorf("SetPointColor with alpha: not transparent")
+	}
+	pm.Reset()
+	if pm.NPoints() != 0 || len(pm.Idx) != 0 || !pm.Changed {
+		t.Errorf("Reset: NPoints %v, Idx %v, Changed %v", pm.NPoints(), len(pm.Idx), pm.Changed)
+	}
+}
+
+func TestPointCloudLOD(t *testing.T) {
+	sc := NewOffscreenScene("offscreen")
+	pc := AddNewPointCloud(sc, sc, "cloud")
+	pc.WorldBBox.BBox = mat32.Box3{Min: mat32.Vec3{-1, -1, -1}, Max: mat32.Vec3{1, 1, 1}}
+	tests := []struct {
+		dist    float32
+		lodDist float32
+		maxPts  int
+		cnt     int
+	}{
+		{20, 0, 0, 1000},
+		{5, 10, 0, 1000},
+		{21, 10, 0, 250},  // (10 / 20)^2
+		{101, 10, 0, 50},  // LODMin
+		{5, 10, 100, 100}, // MaxPoints
+		{21, 10, 500, 250},
+	}
+	for _, tst := range tests {
+		pc.LODDist = tst.lodDist
+		pc.MaxPoints = tst.maxPts
+		sc.Camera.Pose.Pos.Set(0, 0, tst.dist)
+		if cnt := pc.LODCount(sc, 1000); cnt != tst.cnt {
+			t.Errorf("LODCount at %v LODDist %v MaxPoints %v = %v, expected %v", tst.dist, tst.lodDist, tst.maxPts, cnt, tst.cnt)
+		}
+	}
+}
+
+func TestPointCloudPointAt(t *testing.T) {
+	sc := NewOffscreenScene("offscreen")
+	sc.Geom.Size = image.Point{300, 200} // camera Aspect 1.5
+	pc := AddNewPointCloud(sc, sc, "cloud")
+	var clr gi.Color
+	clr.SetUInt8(0, 0, 255, 255)
+	pc.AddPoint(mat32.Vec3{0, 0, 0}, clr, 1)
+	pc.AddPoint(mat32.Vec3{1, 0, 0}, clr, 1)
+	pc.AddPoint(mat32.Vec3{0, 0, 5}, clr, 1) // in front of the first
+	var id mat32.Mat4
+	id.SetIdentity()
+	pc.UpdateWorldMatrix(&id)
+	pc.UpdateMVPMatrix(&sc.Camera.ViewMatrix, &sc.Camera.PrjnMatrix)
+
+	// camera at 0,0,10 looking at origin: 1 unit at the origin is
+	// 100 / (10 * tan(15 deg)) = 37.3 pixels
+	tests := []struct {
+		pos    image.Point
+		radius float32
+		idx    int
+	}{
+		{image.Point{150, 100}, 3, 2},
+		{image.Point{187, 100}, 3, 1},
+		{image.Point{187, 120}, 3, -1},
+		{image.Point{187, 101}, 0, 1},  // point size
+		{image.Point{187, 110}, 0, -1}, // beyond point size
+		{image.Point{250, 100}, 3, -1},
+	}
+	for _, tst := range tests {
+		if idx := pc.PointAt(sc, tst.pos, tst.radius); idx != tst.idx {
+			t.Errorf("PointAt(%v, %v) = %v, expected %v", tst.pos, tst.radius, idx, tst.idx)
+		}
+	}
+	// only the points drawn under level of detail are picked
+	pm := pc.Points()
+	pm.NDraw = 1
+	want := int(pm.Idx[0])
+	for i := 0; i < 3; i++ {
+		p := pm.PointPos(i)
+		ndc := p.MVProjToNDC(&pc.Pose.MVPMatrix, 1)
+		w := ndc.NDCToWindow(mat32.Vec2{300, 200}, mat32.Vec2{}, 0, 1, true)
+		idx := pc.PointAt(sc, image.Point{int(w.X + 0.5), int(w.Y + 0.5)}, 3)
+		if i == want && idx != want {
+			t.Errorf("NDraw 1: PointAt point %v = %v, expected %v", i, idx, want)
+		}
+		if i != want && idx != -1 && idx != want {
+			t.Errorf("NDraw 1: PointAt point %v = %v, expected not drawn", i, idx)
+		}
+	}
+}
@@ -6,6 +6,7 @@ package gi3d
 
 import (
 	"errors"
+	"fmt"
 	"log"
 
 	"github.com/goki/gi/gi"
@@ -27,6 +28,8 @@ const (
 	InVtxNorm
 	InVtxTex
 	InVtxColor
+	InVtxJoints
+	InVtxWeights
 	RenderInputsN
 )
 
@@ -38,9 +41,13 @@ const (
 	RClassOpaqueTexture               // textures tend to be in background
 	RClassOpaqueUniform
 	RClassOpaqueVertex
+	RClassOpaquePBR
+	RClassOpaquePoints
 	RClassTransTexture
 	RClassTransUniform
 	RClassTransVertex
+	RClassTransPBR
+	RClassTransPoints
 	RenderClassesN
 )
 
@@ -114,6 +121,8 @@ func (rn *Renderers) InitVectors() {
 	rn.Vectors[InVtxNorm] = gpu.TheGPU.NewInputVectors("InVtxNorm", int(InVtxNorm), gpu.Vec3fVecType, gpu.VertexNormal)
 	rn.Vectors[InVtxTex] = gpu.TheGPU.NewInputVectors("InVtxTex", int(InVtxTex), gpu.Vec2fVecType, gpu.VertexTexcoord)
 	rn.Vectors[InVtxColor] = gpu.TheGPU.NewInputVectors("InVtxColor", int(InVtxColor), gpu.Vec4fVecType, gpu.VertexColor)
+	rn.Vectors[InVtxJoints] = gpu.TheGPU.NewInputVectors("InVtxJoints", int(InVtxJoints), gpu.Vec4fVecType, gpu.SkinIndex)
+	rn.Vectors[InVtxWeights] = gpu.TheGPU.NewInputVectors("InVtxWeights", int(InVtxWeights), gpu.Vec4fVecType, gpu.SkinWeight)
 }
 
 func (rn *Renderers) InitUnis() error {
@@ -144,6 +153,8 @@ func (rn *Renderers) InitRenders() error {
 	rn.AddNewRender(&RenderUniformColor{}, &errs)
 	rn.AddNewRender(&RenderVertexColor{}, &errs)
 	rn.AddNewRender(&RenderTexture{}, &errs)
+	rn.AddNewRender(&RenderPBR{}, &errs)
+	rn.AddNewRender(&RenderPoints{}, &errs)
 
 	var erstr string
 	for _, er := range errs {
@@ -289,7 +300,7 @@ func (rb *RenderUniformColor) Init(rn *Renderers) error {
 	}
 	pl := rb.Pipe
 	pr := pl.ProgramByName("VtxFrag")
-	_, err := pr.AddShader(gpu.VertexShader, "Vtx", RenderUniCamera+
+	_, err := pr.AddShader(gpu.VertexShader, "Vtx", RenderUniCamera+RenderSkinVtx+
 		`
 layout(location = 0) in vec3 VtxPos;
 layout(location = 1) in vec3 VtxNorm;
@@ -300,9 +311,10 @@ out vec3 Norm;
 out vec3 CamDir;
 
 void main() {
-	vec4 vPos = vec4(VtxPos, 1.0);
+	mat4 skin = skinMatrix();
+	vec4 vPos = skin * vec4(VtxPos, 1.0);
 	Pos = MVMatrix * vPos;
-	Norm = normalize(NormMatrix * VtxNorm);
+	Norm = normalize(NormMatrix * mat3(skin) * VtxNorm);
 	CamDir = normalize(-Pos.xyz);
 	
 	gl_Position = MVPMatrix * vPos;
@@ -348,6 +360,7 @@ void main() {
 
 	pr.AddUniforms(rn.Unis["Camera"])
 	pr.AddUniforms(rn.Unis["Lights"])
+	AddSkinUniforms(pr)
 	pr.AddUniform("Color", gpu.Vec4fUniType, false, 0)
 	pr.AddUniform("Emissive", gpu.Vec3fUniType, false, 0)
 	pr.AddUniform("Specular", gpu.Vec3fUniType, false, 0)
@@ -396,7 +409,7 @@ func (rb *RenderVertexColor) Init(rn *Renderers) error {
 	}
 	pl := rb.Pipe
 	pr := pl.ProgramByName("VtxFrag")
-	_, err := pr.AddShader(gpu.VertexShader, "Vtx", RenderUniCamera+
+	_, err := pr.AddShader(gpu.VertexShader, "Vtx", RenderUniCamera+RenderSkinVtx+
 		`
 layout(location = 0) in vec3 VtxPos;
 layout(location = 1) in vec3 VtxNorm;
@@ -408,9 +421,10 @@ out vec3 CamDir;
 out vec4 Color;
 
 void main() {
-	vec4 vPos = vec4(VtxPos, 1.0);
+	mat4 skin = skinMatrix();
+	vec4 vPos = skin * vec4(VtxPos, 1.0);
 	Pos = MVMatrix * vPos;
-	Norm = normalize(NormMatrix * VtxNorm);
+	Norm = normalize(NormMatrix * mat3(skin) * VtxNorm);
 	CamDir = normalize(-Pos.xyz);
 	Color = VtxColor;
 	
@@ -456,6 +470,7 @@ void main() {
 
 	pr.AddUniforms(rn.Unis["Camera"])
 	pr.AddUniforms(rn.Unis["Lights"])
+	AddSkinUniforms(pr)
 	pr.AddUniform("Emissive", gpu.Vec3fUniType, false, 0)
 	pr.AddUniform("Specular", gpu.Vec3fUniType, false, 0)
 	pr.AddUniform("Shiny", gpu.FUniType, false, 0)
@@ -498,7 +513,7 @@ func (rb *RenderTexture) Init(rn *Renderers) error {
 	}
 	pl := rb.Pipe
 	pr := pl.ProgramByName("VtxFrag")
-	_, err := pr.AddShader(gpu.VertexShader, "Vtx", RenderUniCamera+
+	_, err := pr.AddShader(gpu.VertexShader, "Vtx", RenderUniCamera+RenderSkinVtx+
 		`
 layout(location = 0) in vec3 VtxPos;
 layout(location = 1) in vec3 VtxNorm;
@@ -511,9 +526,10 @@ out vec3 CamDir;
 out vec2 TexCoord;
 
 void main() {
-	vec4 vPos = vec4(VtxPos, 1.0);
+	mat4 skin = skinMatrix();
+	vec4 vPos = skin * vec4(VtxPos, 1.0);
 	Pos = MVMatrix * vPos;
-	Norm = normalize(NormMatrix * VtxNorm);
+	Norm = normalize(NormMatrix * mat3(skin) * VtxNorm);
 	CamDir = normalize(-Pos.xyz);
 	TexCoord = VtxTex;
 	if(FlipY) {
@@ -566,6 +582,7 @@ void main() {
 
 	pr.AddUniforms(rn.Unis["Camera"])
 	pr.AddUniforms(rn.Unis["Lights"])
+	AddSkinUniforms(pr)
 	pr.AddUniform("Emissive", gpu.Vec3fUniType, false, 0)
 	pr.AddUniform("Specular", gpu.Vec3fUniType, false, 0)
 	pr.AddUniform("Shiny", gpu.FUniType, false, 0)
@@ -607,6 +624,400 @@ func (rb *RenderTexture) SetMat(mat *Material, sc *Scene) error {
 	return nil
 }
 
+//////////////////////////////////////////////////////////////////////////
+//    RenderPBR
+
+// Texture numbers used by RenderPBR
+const (
+	PBRBaseTexNo = iota
+	PBRMetalRoughTexNo
+	PBRNormalTexNo
+	PBROcclusionTexNo
+	PBREmissiveTexNo
+	PBREnvTexNo // irradiance, followed by EnvSpecLevels specular maps
+)
+
+// RenderPBR renders a physically based (metallic-roughness) material,
+// with optional base color, metallic-roughness, normal, occlusion and
+// emissive maps, and image-based lighting from the Scene EnvMap.
+// Colors are converted to linear space for lighting, and the result
+// is converted back to sRGB.
+type RenderPBR struct {
+	RenderBase
+}
+
+func (rb *RenderPBR) Init(rn *Renderers) error {
+	rb.Nm = "RenderPBR"
+	if rb.Pipe == nil {
+		rb.Pipe = gpu.TheGPU.NewPipeline(rb.Nm)
+		rb.Pipe.AddProgram("VtxFrag")
+	}
+	pl := rb.Pipe
+	pr := pl.ProgramByName("VtxFrag")
+	_, err := pr.AddShader(gpu.VertexShader, "Vtx", RenderUniCamera+RenderSkinVtx+
+		`
+layout(location = 0) in vec3 VtxPos;
+layout(location = 1) in vec3 VtxNorm;
+layout(location = 2) in vec2 VtxTex;
+// layout(location = 3) in vec4 VtxColor;
+uniform bool FlipY;
+out vec4 Pos;
+out vec3 Norm;
+out vec3 CamDir;
+out vec2 TexCoord;
+
+void main() {
+	mat4 skin = skinMatrix();
+	vec4 vPos = skin * vec4(VtxPos, 1.0);
+	Pos = MVMatrix * vPos;
+	Norm = normalize(NormMatrix * mat3(skin) * VtxNorm);
+	CamDir = normalize(-Pos.xyz);
+	TexCoord = VtxTex;
+	if(FlipY) {
+		TexCoord.y = 1 - TexCoord.y;
+	}
+	
+	gl_Position = MVPMatrix * vPos;
+}
+`+"\x00")
+	if err != nil {
+		return err
+	}
+
+	_, err = pr.AddShader(gpu.FragmentShader, "Frag",
+		`
+// precision mediump float;
+`+RenderUniLights+
+			`
+uniform vec4 Color;
+uniform vec3 Emissive;
+uniform float Bright;
+uniform float Metallic;
+uniform float Roughness;
+uniform float OcclusionStr;
+uniform float NormalScale;
+uniform bool FlipY;
+uniform bool HasBaseTex;
+uniform bool HasMetalRoughTex;
+uniform bool HasNormalTex;
+uniform bool HasOcclusionTex;
+uniform bool HasEmissiveTex;
+uniform bool HasEnv;
+uniform sampler2D BaseTex;
+uniform sampler2D MetalRoughTex;
+uniform sampler2D NormalTex;
+uniform sampler2D OcclusionTex;
+uniform sampler2D EmissiveTex;
+uniform sampler2D EnvIrrTex;
+uniform sampler2D EnvSpecTex0;
+uniform sampler2D EnvSpecTex1;
+uniform sampler2D EnvSpecTex2;
+uniform sampler2D EnvSpecTex3;
+uniform vec2 TexRepeat;
+uniform vec2 TexOff;
+uniform mat3 InvViewRot;
+uniform float EnvIntensity;
+uniform float EnvRot;
+in vec4 Pos;
+in vec3 Norm;
+in vec3 CamDir;
+in vec2 TexCoord;
+out vec4 outputColor;
+`+RenderPBRFuncs+
+			`
+			
+void main() {
+	vec2 uv = TexCoord * TexRepeat + TexOff;
+	vec4 base = Color;
+	if (HasBaseTex) {
+		base = Color * texture(BaseTex, uv);
+	}
+	float opacity = base.a;
+	vec3 albedo = srgbToLinear(base.rgb);
+	float metal = Metallic;
+	float rough = Roughness;
+	if (HasMetalRoughTex) {
+		vec4 mr = texture(MetalRoughTex, uv);
+		rough *= mr.g;
+		metal *= mr.b;
+	}
+	rough = clamp(rough, 0.04, 1.0);
+	metal = clamp(metal, 0.0, 1.0);
+	float ao = 1.0;
+	if (HasOcclusionTex) {
+		ao = 1.0 + OcclusionStr * (texture(OcclusionTex, uv).r - 1.0);
+	}
+	vec3 emis = srgbToLinear(Emissive);
+	if (HasEmissiveTex) {
+		emis *= srgbToLinear(texture(EmissiveTex, uv).rgb);
+	}
+
+	vec3 norm = frontNormal(Pos, normalize(Norm));
+	if (HasNormalTex) {
+		vec3 mapN = texture(NormalTex, uv).xyz * 2.0 - 1.0;
+		mapN.xy *= NormalScale;
+		if (FlipY) {
+			mapN.y = -mapN.y;
+		}
+		norm = perturbNormal(norm, Pos.xyz, uv, mapN);
+	}
+
+	vec3 clr = pbrModel(Pos, norm, normalize(CamDir), albedo, metal, rough, ao);
+
+	// Final fragment color -- premultiplied alpha
+	clr = linearToSrgb(Bright * clr + emis);
+	outputColor = min(vec4(clr * opacity, opacity), vec4(1.0));
+}
+`+"\x00")
+	if err != nil {
+		return err
+	}
+
+	pr.AddUniforms(rn.Unis["Camera"])
+	pr.AddUniforms(rn.Unis["Lights"])
+	AddSkinUniforms(pr)
+	pr.AddUniform("Color", gpu.Vec4fUniType, false, 0)
+	pr.AddUniform("Emissive", gpu.Vec3fUniType, false, 0)
+	pr.AddUniform("Bright", gpu.FUniType, false, 0)
+	pr.AddUniform("Metallic", gpu.FUniType, false, 0)
+	pr.AddUniform("Roughness", gpu.FUniType, false, 0)
+	pr.AddUniform("OcclusionStr", gpu.FUniType, false, 0)
+	pr.AddUniform("NormalScale", gpu.FUniType, false, 0)
+	pr.AddUniform("FlipY", gpu.BUniType, false, 0)
+	pr.AddUniform("HasBaseTex", gpu.BUniType, false, 0)
+	pr.AddUniform("HasMetalRoughTex", gpu.BUniType, false, 0)
+	pr.AddUniform("HasNormalTex", gpu.BUniType, false, 0)
+	pr.AddUniform("HasOcclusionTex", gpu.BUniType, false, 0)
+	pr.AddUniform("HasEmissiveTex", gpu.BUniType, false, 0)
+	pr.AddUniform("HasEnv", gpu.BUniType, false, 0)
+	pr.AddUniform("BaseTex", gpu.IUniType, false, 0)
+	pr.AddUniform("MetalRoughTex", gpu.IUniType, false, 0)
+	pr.AddUniform("NormalTex", gpu.IUniType, false, 0)
+	pr.AddUniform("OcclusionTex", gpu.IUniType, false, 0)
+	pr.AddUniform("EmissiveTex", gpu.IUniType, false, 0)
+	pr.AddUniform("EnvIrrTex", gpu.IUniType, false, 0)
+	for li := 0; li < EnvSpecLevels; li++ {
+		pr.AddUniform(fmt.Sprintf("EnvSpecTex%d", li), gpu.IUniType, false, 0)
+	}
+	pr.AddUniform("TexRepeat", gpu.Vec2fUniType, false, 0)
+	pr.AddUniform("TexOff", gpu.Vec2fUniType, false, 0)
+	pr.AddUniform("InvViewRot", gpu.Mat3fUniType, false, 0)
+	pr.AddUniform("EnvIntensity", gpu.FUniType, false, 0)
+	pr.AddUniform("EnvRot", gpu.FUniType, false, 0)
+
+	pr.SetFragDataVar("outputColor")
+
+	return nil
+}
+
+// setPBRTex activates given texture (if non-nil) at given texture number,
+// and sets the corresponding Has and sampler uniforms
+func (rb *RenderPBR) setPBRTex(sc *Scene, tex Texture, texNo int, hasNm, texNm string) {
+	pr := rb.VtxFragProg()
+	if tex != nil {
+		tex.Activate(sc, texNo)
+	}
+	pr.UniformByName(hasNm).SetValue(tex != nil)
+	pr.UniformByName(texNm).SetValue(texNo)
+}
+
+func (rb *RenderPBR) SetMat(mat *Material, sc *Scene) error {
+	pr := rb.VtxFragProg()
+	pb := &mat.PBR
+	rb.setPBRTex(sc, mat.TexPtr, PBRBaseTexNo, "HasBaseTex", "BaseTex")
+	rb.setPBRTex(sc, pb.MetalRoughPtr, PBRMetalRoughTexNo, "HasMetalRoughTex", "MetalRoughTex")
+	rb.setPBRTex(sc, pb.NormalPtr, PBRNormalTexNo, "HasNormalTex", "NormalTex")
+	rb.setPBRTex(sc, pb.OcclusionPtr, PBROcclusionTexNo, "HasOcclusionTex", "OcclusionTex")
+	rb.setPBRTex(sc, pb.EmissivePtr, PBREmissiveTexNo, "HasEmissiveTex", "EmissiveTex")
+	flip := true
+	for _, tx := range []Texture{mat.TexPtr, pb.MetalRoughPtr, pb.NormalPtr, pb.OcclusionPtr, pb.EmissivePtr} {
+		if tx != nil {
+			flip = !tx.BotZero() // flip if not botzero..
+			break
+		}
+	}
+	pr.UniformByName("FlipY").SetValue(flip)
+
+	env := &sc.EnvMap
+	hasEnv := env.IsSet() && env.IrrTex != nil
+	pr.UniformByName("HasEnv").SetValue(hasEnv)
+	if hasEnv {
+		env.Activate(PBREnvTexNo)
+	}
+	pr.UniformByName("EnvIrrTex").SetValue(PBREnvTexNo)
+	for li := 0; li < EnvSpecLevels; li++ {
+		pr.UniformByName(fmt.Sprintf("EnvSpecTex%d", li)).SetValue(PBREnvTexNo + 1 + li)
+	}
+	pr.UniformByName("EnvIntensity").SetValue(env.Intensity)
+	pr.UniformByName("EnvRot").SetValue(mat32.DegToRad(env.Rotation))
+	var ivr mat32.Mat3
+	sc.Camera.CamMu.RLock()
+	ivr.SetFromMat4(&sc.Camera.Pose.Matrix) // camera world matrix = inverse of view
+	sc.Camera.CamMu.RUnlock()
+	pr.UniformByName("InvViewRot").SetValue(ivr)
+
+	pr.UniformByName("Color").SetValue(ColorToVec4f(mat.Color))
+	pr.UniformByName("Emissive").SetValue(ColorToVec3f(mat.Emissive))
+	pr.UniformByName("Bright").SetValue(mat.Bright)
+	pr.UniformByName("Metallic").SetValue(pb.Metallic)
+	pr.UniformByName("Roughness").SetValue(pb.Roughness)
+	pr.UniformByName("OcclusionStr").SetValue(pb.OcclusionStr)
+	pr.UniformByName("NormalScale").SetValue(pb.NormalScale)
+	pr.UniformByName("TexRepeat").SetValue(mat.Tiling.Repeat)
+	pr.UniformByName("TexOff").SetValue(mat.Tiling.Off)
+	gpu.Draw.CullFace(mat.CullFront, mat.CullBack, true) // back face culling, std CCW ordering
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////
+//    RenderPoints
+
+// RenderPoints renders a PointCloud as GL points, with per-point color
+// and size, drawn as squares, discs, or sphere-shaded sprites.
+type RenderPoints struct {
+	RenderBase
+}
+
+func (rb *RenderPoints) Init(rn *Renderers) error {
+	rb.Nm = "RenderPoints"
+	if rb.Pipe == nil {
+		rb.Pipe = gpu.TheGPU.NewPipeline(rb.Nm)
+		rb.Pipe.AddProgram("VtxFrag")
+	}
+	pl := rb.Pipe
+	pr := pl.ProgramByName("VtxFrag")
+	_, err := pr.AddShader(gpu.VertexShader, "Vtx", RenderUniCamera+
+		`
+layout(location = 0) in vec3 VtxPos;
+layout(location = 2) in vec2 VtxTex; // x = point size factor
+layout(location = 3) in vec4 VtxColor;
+uniform float PointSize;
+uniform float SizeAtten;
+out vec4 Pos;
+out vec4 Color;
+
+void main() {
+	Pos = MVMatrix * vec4(VtxPos, 1.0);
+	float sz = PointSize * VtxTex.x;
+	if (SizeAtten > 0.0) {
+		sz *= SizeAtten / max(-Pos.z, 0.0001);
+	}
+	gl_PointSize = max(sz, 1.0);
+	Color = VtxColor;
+	gl_Position = MVPMatrix * vec4(VtxPos, 1.0);
+}
+`+"\x00")
+	if err != nil {
+		return err
+	}
+
+	_, err = pr.AddShader(gpu.FragmentShader, "Frag",
+		`
+// precision mediump float;
+`+RenderUniLights+
+			`
+uniform int Shape;
+uniform vec3 Specular;
+uniform float Shiny;
+uniform float Bright;
+in vec4 Pos;
+in vec4 Color;
+out vec4 outputColor;
+`+RenderPhong+
+			`
+
+void main() {
+	float opacity = Color.a;
+	vec3 clr = Color.rgb;
+	if (Shape == 0) { // square
+		outputColor = min(vec4(Bright * clr * opacity, opacity), vec4(1.0));
+		return;
+	}
+	vec2 pc = 2.0 * gl_PointCoord - 1.0;
+	float r2 = dot(pc, pc);
+	if (r2 > 1.0) {
+		discard;
+	}
+	if (Shape == 1) { // disc
+		outputColor = min(vec4(Bright * clr * opacity, opacity), vec4(1.0));
+		return;
+	}
+	// sphere: lit using the normal of a sphere facing the camera
+	vec3 norm = vec3(pc.x, -pc.y, sqrt(1.0 - r2));
+	vec3 camDir = normalize(-Pos.xyz);
+	vec3 Ambdiff, Spec;
+	phongModel(Pos, norm, camDir, clr, clr, Specular, Shiny, Ambdiff, Spec);
+	outputColor = min(vec4((Bright * Ambdiff + Spec) * opacity, opacity), vec4(1.0));
+}
+`+"\x00")
+	if err != nil {
+		return err
+	}
+
+	pr.AddUniforms(rn.Unis["Camera"])
+	pr.AddUniforms(rn.Unis["Lights"])
+	pr.AddUniform("PointSize", gpu.FUniType, false, 0)
+	pr.AddUniform("SizeAtten", gpu.FUniType, false, 0)
+	pr.AddUniform("Shape", gpu.IUniType, false, 0)
+	pr.AddUniform("Specular", gpu.Vec3fUniType, false, 0)
+	pr.AddUniform("Shiny", gpu.FUniType, false, 0)
+	pr.AddUniform("Bright", gpu.FUniType, false, 0)
+
+	pr.SetFragDataVar("outputColor")
+
+	return nil
+}
+
+// SetPoints sets the point rendering parameters from given PointCloud
+// Must be called with appropriate context (window) activated and already on main.
+func (rb *RenderPoints) SetPoints(pc *PointCloud, sc *Scene) error {
+	pr := rb.VtxFragProg()
+	pr.UniformByName("PointSize").SetValue(pc.PointSize)
+	pr.UniformByName("SizeAtten").SetValue(pc.SizeAtten)
+	pr.UniformByName("Shape").SetValue(int32(pc.Shape))
+	pr.UniformByName("Specular").SetValue(ColorToVec3f(pc.Mat.Specular))
+	pr.UniformByName("Shiny").SetValue(pc.Mat.Shiny)
+	pr.UniformByName("Bright").SetValue(pc.Mat.Bright)
+	gpu.Draw.CullFace(false, false, true)
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////
+//  Skinning
+
+// MaxJoints is the maximum number of joints (bones) in a Skeleton that
+// can be used for skinning a mesh
+const MaxJoints = 64
+
+// AddSkinUniforms adds the skinning uniforms used in RenderSkinVtx
+// to given program
+func AddSkinUniforms(pr gpu.Program) {
+	pr.AddUniform("JointMats", gpu.Mat4fUniType, true, MaxJoints)
+	pr.AddUniform("NJoints", gpu.IUniType, false, 0)
+}
+
+// SetSkin sets the skinning uniforms in given render program for given
+// solid -- if the solid does not have a skinned mesh and a Skeleton,
+// skinning is turned off.
+// Must be called with appropriate context (window) activated and already on main.
+func (rn *Renderers) SetSkin(rnd Render, sld *Solid) {
+	pr := rnd.VtxFragProg()
+	nju := pr.UniformByName("NJoints")
+	if sld.SkelPtr == nil || sld.MeshPtr == nil || !sld.MeshPtr.HasSkin() {
+		nju.SetValue(0)
+		return
+	}
+	sld.PoseMu.RLock()
+	jms := sld.SkelPtr.JointMats(&sld.Pose.WorldMatrix)
+	sld.PoseMu.RUnlock()
+	pr.UniformByName("JointMats").SetValue(jms)
+	nj := len(sld.SkelPtr.Bones)
+	if nj > MaxJoints { // UpdateBones skips bones beyond, but Bones can be set directly
+		nj = MaxJoints
+	}
+	nju.SetValue(nj)
+}
+
 //////////////////////////////////////////////////////////////////////
 //  Shader code elements
 
@@ -619,6 +1030,27 @@ layout (std140) uniform Camera
 };
 `
 
+// RenderSkinVtx provides the skinning inputs and function for vertex
+// shaders: skinMatrix returns the weighted sum of the joint matricies
+// for up to 4 joints per vertex, or identity if there is no skinning.
+var RenderSkinVtx = `
+layout(location = 4) in vec4 VtxJoints;
+layout(location = 5) in vec4 VtxWeights;
+uniform mat4 JointMats[JOINTMATS_LEN];
+uniform int NJoints;
+
+mat4 skinMatrix() {
+	if (NJoints == 0) {
+		return mat4(1.0);
+	}
+	ivec4 jn = clamp(ivec4(VtxJoints), 0, NJoints-1);
+	return VtxWeights.x * JointMats[jn.x] +
+		VtxWeights.y * JointMats[jn.y] +
+		VtxWeights.z * JointMats[jn.z] +
+		VtxWeights.w * JointMats[jn.w];
+}
+`
+
 var RenderUniLights = `
 layout (std140) uniform Lights
 {
@@ -759,6 +1191,188 @@ void phongModel(vec4 pos, vec3 norm, vec3 camDir, vec3 matAmbient, vec3 matDiffu
 }
 `
 
+// RenderPBRFuncs are the shader functions for the metallic-roughness PBR model
+// https://learnopengl.com/PBR/Lighting
+// https://www.khronos.org/registry/glTF/specs/2.0/glTF-2.0.html#appendix-b-brdf-implementation
+var RenderPBRFuncs = `
+const float PI = 3.14159265359;
+const float EPS = 0.00001;
+
+vec3 srgbToLinear(vec3 c) {
+	return pow(c, vec3(2.2));
+}
+
+vec3 linearToSrgb(vec3 c) {
+	return pow(max(c, vec3(0.0)), vec3(1.0 / 2.2));
+}
+
+// frontNormal flips normal for back-facing surfaces
+// (workaround for gl_FrontFacing, buggy on Intel integrated GPU's)
+vec3 frontNormal(vec4 pos, vec3 norm) {
+	vec3 fdx = dFdx(pos.xyz);
+	vec3 fdy = dFdy(pos.xyz);
+	vec3 faceNorm = normalize(cross(fdx,fdy));
+	if (dot(norm, faceNorm) < 0.0) { // Back-facing
+		return -norm;
+	}
+	return norm;
+}
+
+// perturbNormal applies a tangent-space normal map value, computing the
+// tangent frame from screen-space derivatives, so meshes don't need tangents.
+// http://www.thetenthplanet.de/archives/1180
+vec3 perturbNormal(vec3 norm, vec3 pos, vec2 uv, vec3 mapN) {
+	vec3 dp1 = dFdx(pos);
+	vec3 dp2 = dFdy(pos);
+	vec2 duv1 = dFdx(uv);
+	vec2 duv2 = dFdy(uv);
+	vec3 dp2perp = cross(dp2, norm);
+	vec3 dp1perp = cross(norm, dp1);
+	vec3 tang = dp2perp * duv1.x + dp1perp * duv2.x;
+	vec3 bitang = dp2perp * duv1.y + dp1perp * duv2.y;
+	float invmax = inversesqrt(max(max(dot(tang, tang), dot(bitang, bitang)), EPS));
+	mat3 tbn = mat3(tang * invmax, bitang * invmax, norm);
+	return normalize(tbn * mapN);
+}
+
+float distGGX(float NdotH, float rough) {
+	float a = rough * rough;
+	float a2 = a * a;
+	float d = NdotH * NdotH * (a2 - 1.0) + 1.0;
+	return a2 / (PI * d * d);
+}
+
+float geomSmith(float NdotV, float NdotL, float rough) {
+	float r = rough + 1.0;
+	float k = (r * r) / 8.0;
+	float gv = NdotV / (NdotV * (1.0 - k) + k);
+	float gl = NdotL / (NdotL * (1.0 - k) + k);
+	return gv * gl;
+}
+
+vec3 fresnelSchlick(float cosTheta, vec3 F0) {
+	return F0 + (1.0 - F0) * pow(clamp(1.0 - cosTheta, 0.0, 1.0), 5.0);
+}
+
+vec3 fresnelSchlickRough(float cosTheta, vec3 F0, float rough) {
+	return F0 + (max(vec3(1.0 - rough), F0) - F0) * pow(clamp(1.0 - cosTheta, 0.0, 1.0), 5.0);
+}
+
+// brdfLight returns the reflected light from one light of given radiance
+// coming from direction lightDir.  Radiance is multiplied by PI so that
+// lights have the same overall brightness as in the Phong model.
+vec3 brdfLight(vec3 lightDir, vec3 radiance, vec3 norm, vec3 camDir, vec3 albedo, vec3 F0, float metal, float rough) {
+	float NdotL = dot(norm, lightDir);
+	if (NdotL <= EPS) {
+		return vec3(0.0);
+	}
+	vec3 H = normalize(camDir + lightDir);
+	float NdotV = max(dot(norm, camDir), EPS);
+	float NdotH = max(dot(norm, H), 0.0);
+	float D = distGGX(NdotH, rough);
+	float G = geomSmith(NdotV, NdotL, rough);
+	vec3 F = fresnelSchlick(max(dot(H, camDir), 0.0), F0);
+	vec3 spec = (D * G * F) / (4.0 * NdotV * NdotL + EPS);
+	vec3 kd = (vec3(1.0) - F) * (1.0 - metal);
+	return (kd * albedo / PI + spec) * radiance * PI * NdotL;
+}
+
+// envBRDFApprox is an analytic approximation to the split-sum
+// environment BRDF lookup table, from Karis, 2014
+vec2 envBRDFApprox(float NdotV, float rough) {
+	const vec4 c0 = vec4(-1.0, -0.0275, -0.572, 0.022);
+	const vec4 c1 = vec4(1.0, 0.0425, 1.04, -0.04);
+	vec4 r = rough * c0 + c1;
+	float a004 = min(r.x * r.x, exp2(-9.28 * NdotV)) * r.x + r.y;
+	return vec2(-1.04, 1.04) * a004 + r.zw;
+}
+
+// envUV returns the equirectangular texture coordinates for given world direction
+vec2 envUV(vec3 dir) {
+	float u = (atan(dir.z, dir.x) + EnvRot) / (2.0 * PI) + 0.5;
+	float v = acos(clamp(dir.y, -1.0, 1.0)) / PI;
+	return vec2(fract(u), 1.0 - v); // textures have Y = 0 at bottom
+}
+
+// envSpec returns the prefiltered specular environment color in given
+// world direction, interpolating between levels according to roughness
+vec3 envSpec(vec3 dir, float rough) {
+	vec2 uv = envUV(dir);
+	float lv = rough * 3.0;
+	vec3 c;
+	if (lv < 1.0) {
+		c = mix(texture(EnvSpecTex0, uv).rgb, texture(EnvSpecTex1, uv).rgb, lv);
+	} else if (lv < 2.0) {
+		c = mix(texture(EnvSpecTex1, uv).rgb, texture(EnvSpecTex2, uv).rgb, lv - 1.0);
+	} else {
+		c = mix(texture(EnvSpecTex2, uv).rgb, texture(EnvSpecTex3, uv).rgb, lv - 2.0);
+	}
+	return srgbToLinear(c);
+}
+
+vec3 pbrModel(vec4 pos, vec3 norm, vec3 camDir, vec3 albedo, float metal, float rough, float ao) {
+	vec3 F0 = mix(vec3(0.04), albedo, metal);
+	vec3 direct = vec3(0.0);
+	vec3 ambient = vec3(0.0);
+
+#if AMBLIGHTS_LEN>0
+	for (int i = 0; i < AMBLIGHTS_LEN; i++) {
+		ambient += srgbToLinear(AmbLights[i]) * albedo * (1.0 - metal);
+	}
+#endif
+
+#if DIRLIGHTS_LEN>0
+	int ndir = DIRLIGHTS_LEN / 2;
+	for (int i = 0; i < ndir; i++) {
+		vec3 lightDir = normalize(DirLightDir(i));
+		direct += brdfLight(lightDir, srgbToLinear(DirLightColor(i)), norm, camDir, albedo, F0, metal, rough);
+	}
+#endif
+
+#if POINTLIGHTS_LEN>0
+	int npoint = POINTLIGHTS_LEN / 3;
+	for (int i = 0; i < npoint; i++) {
+		vec3 lightDir = PointLightPos(i) - vec3(pos);
+		float lightDist = length(lightDir);
+		lightDir = lightDir / lightDist;
+		float attenuation = 1.0 / (1.0 + lightDist * (PointLightLinDecay(i) +
+			PointLightQuadDecay(i) * lightDist));
+		direct += brdfLight(lightDir, srgbToLinear(PointLightColor(i)) * attenuation, norm, camDir, albedo, F0, metal, rough);
+	}
+#endif
+
+#if SPOTLIGHTS_LEN>0
+	int nspot = SPOTLIGHTS_LEN / 5;
+	for (int i = 0; i < nspot; i++) {
+		vec3 lightDir = SpotLightPos(i) - vec3(pos);
+		float lightDist = length(lightDir);
+		lightDir = lightDir / lightDist;
+		float angle = acos(dot(-lightDir, SpotLightDir(i)));
+		float cutoff = radians(clamp(SpotLightCutAngle(i), 0.0, 90.0));
+		if (angle < cutoff) {
+			float attenuation = 1.0 / (1.0 + lightDist * (SpotLightLinDecay(i) +
+				SpotLightQuadDecay(i) * lightDist));
+			float spotFactor = pow(dot(-lightDir, SpotLightDir(i)), SpotLightAngDecay(i));
+			direct += brdfLight(lightDir, srgbToLinear(SpotLightColor(i)) * attenuation * spotFactor, norm, camDir, albedo, F0, metal, rough);
+		}
+	}
+#endif
+
+	vec3 ibl = vec3(0.0);
+	if (HasEnv) {
+		float NdotV = max(dot(norm, camDir), EPS);
+		vec3 F = fresnelSchlickRough(NdotV, F0, rough);
+		vec3 kd = (vec3(1.0) - F) * (1.0 - metal);
+		vec3 irr = srgbToLinear(texture(EnvIrrTex, envUV(InvViewRot * norm)).rgb);
+		vec2 ab = envBRDFApprox(NdotV, rough);
+		vec3 spec = envSpec(InvViewRot * reflect(-camDir, norm), rough) * (F0 * ab.x + ab.y);
+		ibl = (kd * irr * albedo + spec) * EnvIntensity;
+	}
+
+	return direct + (ambient + ibl) * ao;
+}
+`
+
 var debugDepth = `
 float near = 0.1; 
 float far  = 100.0; 
@@ -68,25 +68,30 @@ var Update3DTrace = false
 // "first person" effects.
 type Scene struct {
 	gi.WidgetBase
-	Geom          gi.Geom2DInt       `desc:"Viewport-level viewbox within any parent Viewport2D"`
-	Camera        Camera             `desc:"camera determines view onto scene"`
-	BgColor       gi.Color           `desc:"background color"`
-	Wireframe     bool               `desc:"if true, render as wireframe instead of filled"`
-	Lights        map[string]Light   `desc:"all lights used in the scene"`
-	Meshes        map[string]Mesh    `desc:"all meshes used in the scene"`
-	Textures      map[string]Texture `desc:"all textures used in the scene"`
-	Library       map[string]*Group  `desc:"library of objects that can be used in the scene"`
-	NoNav         bool               `desc:"don't activate the standard navigation keyboard and mouse event processing to move around the camera in the scene"`
-	SavedCams     map[string]Camera  `desc:"saved cameras -- can Save and Set these to view the scene from different angles"`
-	Win           *gi.Window         `copy:"-" json:"-" xml:"-" desc:"our parent window that we render into"`
-	Renders       Renderers          `view:"-" desc:"rendering programs"`
-	Frame         gpu.Framebuffer    `view:"-" desc:"direct render target for scene"`
-	Tex           gpu.Texture2D      `view:"-" desc:"the texture that the framebuffer returns, which should be rendered into the window"`
-	SetDragCursor bool               `view:"-" desc:"has dragging cursor been set yet?"`
-	SelMode       SelModes           `desc:"how to deal with selection / manipulation events"`
-	CurSel        Node3D             `copy:"-" json:"-" xml:"-" view:"-" desc:"currently selected node"`
-	CurManipPt    *ManipPt           `copy:"-" json:"-" xml:"-" view:"-" desc:"currently selected manipulation control point"`
-	SelParams     SelParams          `view:"inline" desc:"parameters for selection / manipulation box"`
+	Geom          gi.Geom2DInt         `desc:"Viewport-level viewbox within any parent Viewport2D"`
+	Camera        Camera               `desc:"camera determines view onto scene"`
+	BgColor       gi.Color             `desc:"background color"`
+	Wireframe     bool                 `desc:"if true, render as wireframe instead of filled"`
+	Lights        map[string]Light     `desc:"all lights used in the scene"`
+	Meshes        map[string]Mesh      `desc:"all meshes used in the scene"`
+	Textures      map[string]Texture   `desc:"all textures used in the scene"`
+	EnvMap        EnvMap               `view:"inline" desc:"environment map providing image-based lighting for PBR materials"`
+	Library       map[string]*Group    `desc:"library of objects that can be used in the scene"`
+	Anims         map[string]*AnimClip `desc:"animation clips that can be played in the scene"`
+	Anim          AnimPlayer           `view:"inline" desc:"animation player that plays clips from Anims"`
+	NoNav         bool                 `desc:"don't activate the standard navigation keyboard and mouse event processing to move around the camera in the scene"`
+	SavedCams     map[string]Camera    `desc:"saved cameras -- can Save and Set these to view the scene from different angles"`
+	Win           *gi.Window           `copy:"-" json:"-" xml:"-" desc:"our parent window that we render into"`
+	Renders       Renderers            `view:"-" desc:"rendering programs"`
+	Frame         gpu.Framebuffer      `view:"-" desc:"direct render target for scene"`
+	OffFrame      gpu.Framebuffer      `view:"-" desc:"offscreen render target for RenderToImage"`
+	Offscreen     bool                 `desc:"if there is no visible window, render using the shared offscreen GPU context instead -- for headless batch rendering with RenderToImage and FrameRecorder"`
+	Tex           gpu.Texture2D        `view:"-" desc:"the texture that the framebuffer returns, which should be rendered into the window"`
+	SetDragCursor bool                 `view:"-" desc:"has dragging cursor been set yet?"`
+	SelMode       SelModes             `desc:"how to deal with selection / manipulation events"`
+	CurSel        Node3D               `copy:"-" json:"-" xml:"-" view:"-" desc:"currently selected node"`
+	CurManipPt    *ManipPt             `copy:"-" json:"-" xml:"-" view:"-" desc:"currently selected manipulation control point"`
+	SelParams     SelParams            `view:"inline" desc:"parameters for selection / manipulation box"`
 }
 
 var KiT_Scene = kit.Types.AddType(&Scene{}, SceneProps)
@@ -103,9 +108,12 @@ func (sc *Scene) Defaults() {
 	sc.Camera.Defaults()
 	sc.BgColor.SetUInt8(255, 255, 255, 255)
 	sc.SelParams.Defaults()
+	sc.EnvMap.Defaults()
+	sc.Anim.Defaults()
 }
 
 func (sc *Scene) Disconnect() {
+	sc.Anim.Pause()
 	if sc.Win != nil && sc.Win.IsVisible() {
 		sc.DeleteResources()
 	}
@@ -285,6 +293,29 @@ func (sc *Scene) DeleteTextures() {
 	sc.Textures = make(map[string]Texture)
 }
 
+// SetEnvMap opens given equirectangular environment image file for
+// image-based lighting of PBR materials, prefiltering it for rendering,
+// and uploads it to the GPU if the scene is already active.
+// If fname is empty, any existing environment map is removed.
+func (sc *Scene) SetEnvMap(fname string) error {
+	if fname == "" {
+		sc.EnvMap.Reset()
+	} else if err := sc.EnvMap.Open(fname); err != nil {
+		return err
+	}
+	if !sc.ActivateWin() {
+		return nil // not yet active -- uploaded in Init3D
+	}
+	var err error
+	oswin.TheApp.RunOnMain(func() {
+		err = sc.EnvMap.Init(sc) // deletes the textures if reset
+	})
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
 // SaveCamera saves the current camera with given name -- can be restored later with SetCamera.
 // "default" is a special name that is automatically saved on first render, and
 // restored with the spacebar under default NavEvents.
@@ -761,10 +792,19 @@ func (sc *Scene) NavKeyEvents(kt *key.ChordEvent) {
 
 // ActivateWin activates the window context for GPU rendering context (on the
 // main thread -- all GPU rendering actions must be performed on main thread)
-// returns false if not possible (i.e., Win nil, not visible)
+// returns false if not possible (i.e., Win nil, not visible).
+// If Offscreen is set and there is no visible window, the shared
+// offscreen context is activated instead.
 func (sc *Scene) ActivateWin() bool {
 	if sc.Win == nil || !sc.Win.IsVisible() {
-		return false
+		if !sc.Offscreen || gpu.TheGPU == nil {
+			return false
+		}
+		var err error
+		oswin.TheApp.RunOnMain(func() {
+			err = gpu.TheGPU.ActivateShared()
+		})
+		return err == nil
 	}
 	oswin.TheApp.RunOnMain(func() {
 		sc.Win.OSWin.Activate()
@@ -831,6 +871,7 @@ func (sc *Scene) InitTexturesInCtxt() bool {
 	for _, tx := range sc.Textures {
 		tx.Init(sc)
 	}
+	sc.EnvMap.Init(sc)
 	return true
 }
 
@@ -905,7 +946,7 @@ func (sc *Scene) UpdateMeshes() {
 // DeleteResources deletes all GPU resources -- sets context and runs on main.
 // This is called during Disconnect and before the window is closed.
 func (sc *Scene) DeleteResources() {
-	if sc.Win == nil {
+	if sc.Win == nil && !sc.Offscreen {
 		return
 	}
 	oswin.TheApp.RunOnMain(func() {
@@ -913,6 +954,7 @@ func (sc *Scene) DeleteResources() {
 		for _, tx := range sc.Textures {
 			tx.Delete(sc)
 		}
+		sc.EnvMap.Delete(sc)
 		for _, ms := range sc.Meshes {
 			ms.Delete(sc)
 		}
@@ -922,6 +964,10 @@ func (sc *Scene) DeleteResources() {
 		if sc.Frame != nil {
 			sc.Frame.Delete()
 		}
+		if sc.OffFrame != nil {
+			sc.OffFrame.Delete()
+			sc.OffFrame = nil
+		}
 	})
 }
 
@@ -1213,6 +1259,10 @@ func (sc *Scene) Render3D() {
 				rnd = sc.Renders.Renders["RenderUniformColor"]
 			case RClassOpaqueVertex:
 				rnd = sc.Renders.Renders["RenderVertexColor"]
+			case RClassOpaquePBR:
+				rnd = sc.Renders.Renders["RenderPBR"]
+			case RClassOpaquePoints:
+				rnd = sc.Renders.Renders["RenderPoints"]
 			}
 			gpu.Draw.Op(draw.Src)     // opaque
 			rnd.Activate(&sc.Renders) // use same program for all..
@@ -1228,6 +1278,10 @@ func (sc *Scene) Render3D() {
 					rnd = sc.Renders.Renders["RenderUniformColor"]
 				case RClassTransVertex:
 					rnd = sc.Renders.Renders["RenderVertexColor"]
+				case RClassTransPBR:
+					rnd = sc.Renders.Renders["RenderPBR"]
+				case RClassTransPoints:
+					rnd = sc.Renders.Renders["RenderPoints"]
 				}
 				gpu.Draw.Op(draw.Over) // alpha
 				rnd.Activate(&sc.Renders)
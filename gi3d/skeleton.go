@@ -0,0 +1,198 @@
+// Copyright (c) 2019, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// https://github.com/KhronosGroup/glTF-Tutorials/blob/master/gltfTutorial/gltfTutorial_020_Skins.md
+
+// Bone is one joint in a Skeleton -- its Pose is the joint pose relative
+// to its parent bone, which can be driven by animation or IK code.
+// Child bones are added as children, and regular nodes can also be
+// added under a bone to move along with it.
+type Bone struct {
+	Group
+	Joint   int        `desc:"index of this bone's joint in the skinned mesh Joints data -- determines the index in the joint matricies"`
+	InvBind mat32.Mat4 `desc:"inverse bind matrix: transforms mesh vertices from mesh space into the local space of this bone in the bind pose"`
+}
+
+var KiT_Bone = kit.Types.AddType(&Bone{}, GroupProps)
+
+// AddNewBone adds a new bone of given name and joint index to given parent
+// (either a Skeleton or another Bone)
+func AddNewBone(sc *Scene, parent ki.Ki, name string, joint int) *Bone {
+	bn := parent.AddNewChild(KiT_Bone, name).(*Bone)
+	bn.Defaults()
+	bn.Joint = joint
+	return bn
+}
+
+func (bn *Bone) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*Bone)
+	bn.Group.CopyFieldsFrom(&fr.Group)
+	bn.Joint = fr.Joint
+	bn.InvBind = fr.InvBind
+}
+
+func (bn *Bone) Defaults() {
+	bn.Group.Defaults()
+	bn.InvBind.SetIdentity()
+}
+
+// SetBindPose sets the InvBind matrix from the current world matrix of
+// this bone relative to given mesh world matrix, so that the current
+// pose becomes the rest pose in which the mesh is undeformed.
+// UpdateWorldMatrix must have been called.
+func (bn *Bone) SetBindPose(meshWorld *mat32.Mat4) {
+	bn.PoseMu.RLock()
+	var inv mat32.Mat4
+	inv.SetInverse(meshWorld)
+	bn.InvBind.MulMatrices(&inv, &bn.Pose.WorldMatrix)
+	bn.PoseMu.RUnlock()
+	bn.InvBind.SetInverse(&bn.InvBind)
+}
+
+/////////////////////////////////////////////////////////////////
+//  Skeleton
+
+// Skeleton is the root of a tree of Bones that deform one or more skinned
+// Solids, whose Skeleton field refers to it.  The Bones are found
+// anywhere under the Skeleton, and are indexed by their Joint index.
+type Skeleton struct {
+	Group
+	Bones []*Bone `copy:"-" json:"-" xml:"-" view:"-" desc:"bones under this skeleton, indexed by Joint -- updated by UpdateBones"`
+}
+
+var KiT_Skeleton = kit.Types.AddType(&Skeleton{}, GroupProps)
+
+// AddNewSkeleton adds a new skeleton of given name to given parent
+func AddNewSkeleton(sc *Scene, parent ki.Ki, name string) *Skeleton {
+	sk := parent.AddNewChild(KiT_Skeleton, name).(*Skeleton)
+	sk.Defaults()
+	return sk
+}
+
+func (sk *Skeleton) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*Skeleton)
+	sk.Group.CopyFieldsFrom(&fr.Group)
+}
+
+func (sk *Skeleton) Init3D(sc *Scene) {
+	sk.UpdateBones()
+	sk.Group.Init3D(sc)
+}
+
+// UpdateBones updates the Bones list from the Bone nodes under the
+// skeleton.  Called in Init3D -- call if bones are added or removed.
+// Bones with a Joint index of MaxJoints or more cannot be used for
+// skinning, and are skipped with an error message.
+func (sk *Skeleton) UpdateBones() {
+	sk.Bones = sk.Bones[:0]
+	sk.FuncDownMeFirst(0, sk.This(), func(k ki.Ki, level int, d interface{}) bool {
+		bn, ok := k.(*Bone)
+		if !ok || bn.Joint < 0 {
+			return ki.Continue
+		}
+		if bn.Joint >= MaxJoints {
+			log.Printf("gi3d.Skeleton: %v bone: %v joint: %v exceeds MaxJoints: %v -- skipped\n", sk.Nm, bn.Nm, bn.Joint, MaxJoints)
+			return ki.Continue
+		}
+		for len(sk.Bones) <= bn.Joint {
+			sk.Bones = append(sk.Bones, nil)
+		}
+		sk.Bones[bn.Joint] = bn
+		return ki.Continue
+	})
+}
+
+// BoneByJoint returns the bone for given joint index, or nil if none
+func (sk *Skeleton) BoneByJoint(joint int) *Bone {
+	if joint < 0 || joint >= len(sk.Bones) {
+		return nil
+	}
+	return sk.Bones[joint]
+}
+
+// BoneByName returns the bone of given name under the skeleton, or nil
+// if not found
+func (sk *Skeleton) BoneByName(nm string) *Bone {
+	for _, bn := range sk.Bones {
+		if bn != nil && bn.Nm == nm {
+			return bn
+		}
+	}
+	return nil
+}
+
+// SetBindPose sets the InvBind matrix of all bones so that the current
+// pose is the rest pose for a mesh with given world matrix.
+// UpdateWorldMatrix must have been called.
+func (sk *Skeleton) SetBindPose(meshWorld *mat32.Mat4) {
+	for _, bn := range sk.Bones {
+		if bn != nil {
+			bn.SetBindPose(meshWorld)
+		}
+	}
+}
+
+// JointMats returns the joint matricies for skinning a mesh with given
+// world matrix, which transform mesh vertices in the bind pose to their
+// current posed positions in mesh space.  The result always has
+// MaxJoints elements, with identity for missing bones.
+func (sk *Skeleton) JointMats(meshWorld *mat32.Mat4) []mat32.Mat4 {
+	jms := make([]mat32.Mat4, MaxJoints)
+	var inv mat32.Mat4
+	inv.SetInverse(meshWorld)
+	for i := range jms {
+		if i >= len(sk.Bones) || sk.Bones[i] == nil {
+			jms[i].SetIdentity()
+			continue
+		}
+		bn := sk.Bones[i]
+		bn.PoseMu.RLock()
+		jms[i].MulMatrices(&inv, &bn.Pose.WorldMatrix)
+		bn.PoseMu.RUnlock()
+		jms[i].SetMul(&bn.InvBind)
+	}
+	return jms
+}
+
+/////////////////////////////////////////////////////////////////
+//  Solid
+
+// SetSkeleton sets the Skeleton that deforms the skinned mesh of this solid
+func (sld *Solid) SetSkeleton(sc *Scene, sk *Skeleton) {
+	sld.SkelPtr = sk
+	if sk != nil {
+		sld.Skeleton = sk.PathFromUnique(sc.This())
+	} else {
+		sld.Skeleton = ""
+	}
+}
+
+// SetSkeletonPath sets the Skeleton from its path relative to the Scene
+func (sld *Solid) SetSkeletonPath(sc *Scene, path string) error {
+	if path == "" {
+		sld.SetSkeleton(sc, nil)
+		return nil
+	}
+	k, err := sc.FindPathUniqueTry(path)
+	if err != nil {
+		return err
+	}
+	sk, ok := k.(*Skeleton)
+	if !ok {
+		return fmt.Errorf("gi3d.Solid: %s Skeleton path: %s is not a Skeleton", sld.PathUnique(), path)
+	}
+	sld.SetSkeleton(sc, sk)
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"testing"
+
+	"github.com/goki/mat32"
+)
+
+// skelTestPose updates the world matricies of the skeleton and its bones
+func skelTestPose(sk *Skeleton, bones ...*Bone) {
+	var id mat32.Mat4
+	id.SetIdentity()
+	sk.UpdateWorldMatrix(&id)
+	par := &sk.Pose.WorldMatrix
+	for _, bn := range bones {
+		bn.UpdateWorldMatrix(par)
+		par = &bn.Pose.WorldMatrix
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	sk := &Skeleton{}
+	sk.InitName(sk, "skel")
+	sk.Defaults()
+	b0 := AddNewBone(nil, sk, "b0", 0)
+	b0.Pose.Pos.Set(0, 1, 0)
+	b2 := AddNewBone(nil, b0, "b2", 2)
+	b2.Pose.Pos.Set(0, 1, 0)
+	AddNewBone(nil, b2, "nojoint", -1)
+	AddNewBone(nil, sk, "toobig", MaxJoints)
+	sk.UpdateBones()
+
+	if len(sk.Bones) != 3 || sk.BoneByJoint(0) != b0 || sk.BoneByJoint(1) != nil || sk.BoneByJoint(2) != b2 || sk.BoneByJoint(3) != nil {
+		t.Fatalf("UpdateBones: got %v bones, expected b0, nil, b2", len(sk.Bones))
+	}
+	if sk.BoneByName("b2") != b2 || sk.BoneByName("nojoint") != nil || sk.BoneByName("toobig") != nil {
+		t.Errorf("BoneByName: wrong bones found")
+	}
+
+	var mw mat32.Mat4
+	mw.SetTranslation(1, 0, 0)
+	skelTestPose(sk, b0, b2)
+	sk.SetBindPose(&mw)
+
+	// in the bind pose, the mesh is not deformed
+	jms := sk.JointMats(&mw)
+	if len(jms) != MaxJoints {
+		t.Fatalf("JointMats: got %v, expected MaxJoints", len(jms))
+	}
+	vtx := mat32.Vec3{-1, 3, 0} // one above b2 in mesh space
+	for i := range jms {
+		if p := vtx.MulMat4(&jms[i]); p.Sub(vtx).Length() > 1e-5 {
+			t.Errorf("bind pose: joint %v moves %v to %v", i, vtx, p)
+		}
+	}
+
+	// rotating b2 rotates its vertices around it, but not those of b0
+	b2.Pose.SetAxisRotation(0, 0, 1, 90)
+	skelTestPose(sk, b0, b2)
+	jms = sk.JointMats(&mw)
+	if p, exp := vtx.MulMat4(&jms[2]), (mat32.Vec3{-2, 2, 0}); p.Sub(exp).Length() > 1e-5 {
+		t.Errorf("rotated b2: joint 2 moves %v to %v, expected %v", vtx, p, exp)
+	}
+	if p := vtx.MulMat4(&jms[0]); p.Sub(vtx).Length() > 1e-5 {
+		t.Errorf("rotated b2: joint 0 moves %v to %v", vtx, p)
+	}
+
+	// moving b0 moves the vertices of both bones
+	b2.Pose.SetAxisRotation(0, 0, 1, 0)
+	b0.Pose.Pos.Set(2, 1, 0)
+	skelTestPose(sk, b0, b2)
+	jms = sk.JointMats(&mw)
+	exp := mat32.Vec3{1, 3, 0}
+	for _, j := range []int{0, 2} {
+		if p := vtx.MulMat4(&jms[j]); p.Sub(exp).Length() > 1e-5 {
+			t.Errorf("moved b0: joint %v moves %v to %v, expected %v", j, vtx, p, exp)
+		}
+	}
+}
+
+func TestMeshValidateSkin(t *testing.T) {
+	ms := &MeshBase{Nm: "skin"}
+	ms.Vtx = mat32.ArrayF32{0, 0, 0, 1, 0, 0}
+	ms.Norm = mat32.ArrayF32{0, 0, 1, 0, 0, 1}
+	if err := ms.Validate(); err != nil || ms.HasSkin() {
+		t.Errorf("Validate without skin: %v, HasSkin %v", err, ms.HasSkin())
+	}
+	ms.Joints = mat32.ArrayF32{0, 0, 0, 0, 1, 0, 0, 0}
+	if err := ms.Validate(); err == nil {
+		t.Errorf("Validate: expected error for Joints without Weights")
+	}
+	ms.Weights = mat32.ArrayF32{1, 0, 0, 0}
+	if err := ms.Validate(); err == nil {
+		t.Errorf("Validate: expected error for Weights not matching Vtx")
+	}
+	ms.Weights = mat32.ArrayF32{1, 0, 0, 0, 1, 0, 0, 0}
+	if err := ms.Validate(); err != nil || !ms.HasSkin() {
+		t.Errorf("Validate with skin: %v, HasSkin %v", err, ms.HasSkin())
+	}
+	ms.Reset()
+	if ms.HasSkin() {
+		t.Errorf("Reset: HasSkin still true")
+	}
+}
@@ -21,9 +21,11 @@ import (
 // and points to a mesh structure defining the shape of the solid.
 type Solid struct {
 	Node3DBase
-	Mesh    MeshName `desc:"name of the mesh shape information used for rendering this solid -- all meshes are collected on the Scene"`
-	Mat     Material `view:"add-fields" desc:"material properties of the surface (color, shininess, texture, etc)"`
-	MeshPtr Mesh     `view:"-" desc:"cached pointer to mesh"`
+	Mesh     MeshName  `desc:"name of the mesh shape information used for rendering this solid -- all meshes are collected on the Scene"`
+	Mat      Material  `view:"add-fields" desc:"material properties of the surface (color, shininess, texture, etc)"`
+	MeshPtr  Mesh      `view:"-" desc:"cached pointer to mesh"`
+	Skeleton string    `desc:"for skinned meshes, path to the Skeleton that deforms the mesh, relative to the Scene, using unique names"`
+	SkelPtr  *Skeleton `view:"-" desc:"cached pointer to skeleton"`
 }
 
 var KiT_Solid = kit.Types.AddType(&Solid{}, SolidProps)
@@ -42,6 +44,8 @@ func (sld *Solid) CopyFieldsFrom(frm interface{}) {
 	sld.Mesh = fr.Mesh
 	sld.Mat = fr.Mat
 	sld.MeshPtr = fr.MeshPtr
+	sld.Skeleton = fr.Skeleton
+	sld.SkelPtr = fr.SkelPtr
 }
 
 func (sld *Solid) IsSolid() bool {
@@ -134,6 +138,13 @@ func (sld *Solid) Validate(sc *Scene) error {
 			return err
 		}
 	}
+	if sld.Skeleton != "" && sld.SkelPtr == nil {
+		err := sld.SetSkeletonPath(sc, sld.Skeleton)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+	}
 	return sld.Mat.Validate(sc)
 }
 
@@ -174,6 +185,11 @@ func (sld *Solid) UpdateMeshBBox() {
 // used for organizing the ordering of rendering
 func (sld *Solid) RenderClass() RenderClasses {
 	switch {
+	case sld.Mat.IsPBR():
+		if sld.Mat.IsTransparent() {
+			return RClassTransPBR
+		}
+		return RClassOpaquePBR
 	case sld.Mat.TexPtr != nil:
 		return RClassOpaqueTexture
 	case sld.MeshPtr.HasColor():
@@ -201,7 +217,11 @@ func (sld *Solid) Render3D(sc *Scene, rc RenderClasses, rnd Render) {
 	case RClassOpaqueTexture, RClassTransTexture:
 		rndt := rnd.(*RenderTexture)
 		rndt.SetMat(&sld.Mat, sc)
+	case RClassOpaquePBR, RClassTransPBR:
+		rndp := rnd.(*RenderPBR)
+		rndp.SetMat(&sld.Mat, sc)
 	}
+	sc.Renders.SetSkin(rnd, sld)
 	sld.PoseMu.RLock()
 	sc.Renders.SetMatrix(&sld.Pose)
 	sld.PoseMu.RUnlock()
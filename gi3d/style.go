@@ -173,4 +173,46 @@ var StyleMatFuncs = map[string]gi.StyleFunc{
 			mt.CullFront = bv
 		}
 	},
+	"pbr": func(obj interface{}, key string, val interface{}, par interface{}, vp *gi.Viewport2D) {
+		mt := obj.(*Material)
+		if inh, init := gi.StyleInhInit(val, par); inh || init {
+			if inh {
+				mt.PBR.On = par.(*Material).PBR.On
+			} else if init {
+				mt.PBR.On = false
+			}
+			return
+		}
+		if bv, ok := kit.ToBool(val); ok {
+			mt.PBR.On = bv
+		}
+	},
+	"metallic": func(obj interface{}, key string, val interface{}, par interface{}, vp *gi.Viewport2D) {
+		mt := obj.(*Material)
+		if inh, init := gi.StyleInhInit(val, par); inh || init {
+			if inh {
+				mt.PBR.Metallic = par.(*Material).PBR.Metallic
+			} else if init {
+				mt.PBR.Metallic = 0
+			}
+			return
+		}
+		if iv, ok := kit.ToFloat32(val); ok {
+			mt.PBR.Metallic = iv
+		}
+	},
+	"roughness": func(obj interface{}, key string, val interface{}, par interface{}, vp *gi.Viewport2D) {
+		mt := obj.(*Material)
+		if inh, init := gi.StyleInhInit(val, par); inh || init {
+			if inh {
+				mt.PBR.Roughness = par.(*Material).PBR.Roughness
+			} else if init {
+				mt.PBR.Roughness = 0.5
+			}
+			return
+		}
+		if iv, ok := kit.ToFloat32(val); ok {
+			mt.PBR.Roughness = iv
+		}
+	},
 }
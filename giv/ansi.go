@@ -0,0 +1,335 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// ANSIColors are the html colors for the 16 standard ANSI colors: the 8
+// normal colors (30-37, 40-47) followed by the 8 bright ones (90-97, 100-107)
+var ANSIColors = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// ANSIColor256 returns the html color for given index in the 256 color
+// ANSI palette: the 16 standard colors, a 6x6x6 color cube, and 24 grays
+func ANSIColor256(idx int) string {
+	switch {
+	case idx < 0:
+		return ""
+	case idx < 16:
+		return ANSIColors[idx]
+	case idx < 232:
+		lev := [6]int{0, 95, 135, 175, 215, 255}
+		idx -= 16
+		return fmt.Sprintf("#%02x%02x%02x", lev[idx/36], lev[(idx/6)%6], lev[idx%6])
+	case idx < 256:
+		g := 8 + 10*(idx-232)
+		return fmt.Sprintf("#%02x%02x%02x", g, g, g)
+	}
+	return ""
+}
+
+// ANSIState is the current formatting state set by ANSI SGR (select
+// graphic rendition) escape codes, which persists across lines until
+// reset, as in a terminal
+type ANSIState struct {
+	Fg        string `desc:"foreground color as an html color -- empty for default"`
+	Bg        string `desc:"background color as an html color -- empty for default"`
+	Bold      bool   `desc:"bold text"`
+	Italic    bool   `desc:"italic text"`
+	Underline bool   `desc:"underlined text"`
+	Strike    bool   `desc:"strike-through text"`
+	Reverse   bool   `desc:"reverse video: foreground and background colors swapped"`
+}
+
+// Reset resets to default formatting
+func (as *ANSIState) Reset() {
+	*as = ANSIState{}
+}
+
+// IsDefault returns true if the state is the default formatting
+func (as *ANSIState) IsDefault() bool {
+	return *as == ANSIState{}
+}
+
+// SetSGR updates the state from the parameters of an SGR escape code
+// (ESC [ params m) -- an empty list is equivalent to a reset (0)
+func (as *ANSIState) SetSGR(params []int) {
+	if len(params) == 0 {
+		as.Reset()
+		return
+	}
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		switch {
+		case p == 0:
+			as.Reset()
+		case p == 1:
+			as.Bold = true
+		case p == 3:
+			as.Italic = true
+		case p == 4:
+			as.Underline = true
+		case p == 7:
+			as.Reverse = true
+		case p == 9:
+			as.Strike = true
+		case p == 22:
+			as.Bold = false
+		case p == 23:
+			as.Italic = false
+		case p == 24:
+			as.Underline = false
+		case p == 27:
+			as.Reverse = false
+		case p == 29:
+			as.Strike = false
+		case p >= 30 && p <= 37:
+			as.Fg = ANSIColors[p-30]
+		case p >= 40 && p <= 47:
+			as.Bg = ANSIColors[p-40]
+		case p >= 90 && p <= 97:
+			as.Fg = ANSIColors[p-90+8]
+		case p >= 100 && p <= 107:
+			as.Bg = ANSIColors[p-100+8]
+		case p == 39:
+			as.Fg = ""
+		case p == 49:
+			as.Bg = ""
+		case p == 38 || p == 48:
+			clr := ""
+			if i+2 < len(params) && params[i+1] == 5 {
+				clr = ANSIColor256(params[i+2])
+				i += 2
+			} else if i+4 < len(params) && params[i+1] == 2 {
+				clr = fmt.Sprintf("#%02x%02x%02x", params[i+2]&0xff, params[i+3]&0xff, params[i+4]&0xff)
+				i += 4
+			} else {
+				i = len(params) // malformed -- skip rest
+			}
+			if p == 38 {
+				as.Fg = clr
+			} else {
+				as.Bg = clr
+			}
+		}
+	}
+}
+
+// StartTags returns the html tags that start the current formatting
+func (as *ANSIState) StartTags() []byte {
+	if as.IsDefault() {
+		return nil
+	}
+	var b bytes.Buffer
+	fg, bg := as.Fg, as.Bg
+	if as.Reverse {
+		fg, bg = bg, fg
+	}
+	if fg != "" || bg != "" {
+		b.WriteString(`<span style="`)
+		if fg != "" {
+			b.WriteString("color:" + fg + ";")
+		}
+		if bg != "" {
+			b.WriteString("background-color:" + bg + ";")
+		}
+		b.WriteString(`">`)
+	}
+	if as.Bold {
+		b.WriteString("<b>")
+	}
+	if as.Italic {
+		b.WriteString("<i>")
+	}
+	if as.Underline {
+		b.WriteString("<u>")
+	}
+	if as.Strike {
+		b.WriteString("<s>")
+	}
+	return b.Bytes()
+}
+
+// EndTags returns the html tags that end the current formatting, in
+// reverse order of StartTags
+func (as *ANSIState) EndTags() []byte {
+	if as.IsDefault() {
+		return nil
+	}
+	var b bytes.Buffer
+	if as.Strike {
+		b.WriteString("</s>")
+	}
+	if as.Underline {
+		b.WriteString("</u>")
+	}
+	if as.Italic {
+		b.WriteString("</i>")
+	}
+	if as.Bold {
+		b.WriteString("</b>")
+	}
+	fg, bg := as.Fg, as.Bg
+	if fg != "" || bg != "" {
+		b.WriteString("</span>")
+	}
+	return b.Bytes()
+}
+
+// ANSIRun is a run of text with the same ANSI formatting, starting at
+// given byte offset in the plain text and extending to the next run
+type ANSIRun struct {
+	St  int       `desc:"starting byte offset in the plain text"`
+	Sty ANSIState `desc:"formatting of the run"`
+}
+
+// ANSIToMarkup converts a line of output containing ANSI escape sequences
+// into the plain text with all escape sequences removed, and the html
+// markup for that text (which is html escaped), with SGR color and style
+// codes converted into span and style tags.  Other escape sequences
+// (cursor movement, erasing etc) are removed.  The state carries the
+// formatting across lines, and is updated by the codes in the line.
+func ANSIToMarkup(line []byte, st *ANSIState) (text, markup []byte) {
+	text, runs := ANSIToRuns(line, st)
+	return text, ANSIMarkup(HTMLEscapeBytes(text), runs)
+}
+
+// ANSIToRuns converts a line of output containing ANSI escape sequences
+// into the plain text with all escape sequences removed, and the runs of
+// formatting set by the SGR codes in that text, for ANSIMarkup.  The
+// state carries the formatting across lines, as in ANSIToMarkup.
+func ANSIToRuns(line []byte, st *ANSIState) (text []byte, runs []ANSIRun) {
+	text = make([]byte, 0, len(line))
+	addRun := func() {
+		nr := len(runs)
+		if nr > 0 && runs[nr-1].St == len(text) { // empty run
+			runs = runs[:nr-1]
+			nr--
+		}
+		if nr > 0 && runs[nr-1].Sty == *st {
+			return
+		}
+		runs = append(runs, ANSIRun{St: len(text), Sty: *st})
+	}
+	addRun()
+	sz := len(line)
+	for i := 0; i < sz; {
+		c := line[i]
+		if c != 0x1b {
+			j := i + 1
+			for j < sz && line[j] != 0x1b {
+				j++
+			}
+			text = append(text, line[i:j]...)
+			i = j
+			continue
+		}
+		if i+1 >= sz {
+			break
+		}
+		switch line[i+1] {
+		case '[': // CSI: ESC [ params final-byte
+			j := i + 2
+			for j < sz && (line[j] < 0x40 || line[j] > 0x7e) {
+				j++
+			}
+			if j >= sz {
+				i = sz
+				break
+			}
+			if line[j] == 'm' {
+				st.SetSGR(ansiParams(line[i+2 : j]))
+				addRun()
+			}
+			i = j + 1
+		case ']': // OSC: ESC ] ... BEL or ESC \
+			j := i + 2
+			for j < sz {
+				if line[j] == 0x07 {
+					j++
+					break
+				}
+				if line[j] == 0x1b && j+1 < sz && line[j+1] == '\\' {
+					j += 2
+					break
+				}
+				j++
+			}
+			i = j
+		default: // two-char escape
+			i += 2
+		}
+	}
+	return
+}
+
+// ANSIMarkup adds the html tags for given runs of ANSI formatting to
+// given html markup, which must have the plain text of the runs as its
+// visible text (html escaped), and can contain other tags, e.g., added by
+// an OutBufMarkupFunc.  The formatting tags are closed around each of
+// those tags, so they are always properly nested within them.
+func ANSIMarkup(mu []byte, runs []ANSIRun) []byte {
+	out := make([]byte, 0, len(mu)+40)
+	var cur ANSIState
+	ri, pos := 0, 0 // pos is in the plain text
+	sz := len(mu)
+	for i := 0; i < sz; {
+		c := mu[i]
+		if c == '<' {
+			j := bytes.IndexByte(mu[i:], '>') + 1
+			if j <= 0 {
+				j = sz - i
+			}
+			out = append(out, cur.EndTags()...)
+			cur.Reset()
+			out = append(out, mu[i:i+j]...)
+			i += j
+			continue
+		}
+		for ri+1 < len(runs) && runs[ri+1].St <= pos {
+			ri++
+		}
+		var sty ANSIState
+		if ri < len(runs) && runs[ri].St <= pos {
+			sty = runs[ri].Sty
+		}
+		if sty != cur {
+			out = append(out, cur.EndTags()...)
+			cur = sty
+			out = append(out, cur.StartTags()...)
+		}
+		n := 1
+		if c == '&' { // escaped char
+			if j := bytes.IndexByte(mu[i:], ';'); j > 0 && j < 8 {
+				n = j + 1
+			}
+		}
+		out = append(out, mu[i:i+n]...)
+		i += n
+		pos++
+	}
+	out = append(out, cur.EndTags()...)
+	return out
+}
+
+// ansiParams parses the semicolon-separated numeric params of a CSI
+// sequence -- empty params are 0
+func ansiParams(b []byte) []int {
+	if len(b) == 0 {
+		return nil
+	}
+	flds := bytes.Split(b, []byte(";"))
+	params := make([]int, len(flds))
+	for i, f := range flds {
+		params[i], _ = strconv.Atoi(string(f))
+	}
+	return params
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestANSIToMarkup(t *testing.T) {
+	tests := []struct {
+		in, text, markup string
+		st               ANSIState // state at start
+	}{
+		{"plain <text>", "plain <text>", "plain &lt;text&gt;", ANSIState{}},
+		{"\x1b[31mred\x1b[0m off", "red off", `<span style="color:` + ANSIColors[1] + `;">red</span> off`, ANSIState{}},
+		{"\x1b[1mbold\x1b[22m", "bold", "<b>bold</b>", ANSIState{}},
+		{"\x1b[1m\x1b[4mbu\x1b[m", "bu", "<b><u>bu</u></b>", ANSIState{}},
+		{"carried", "carried", "<i>carried</i>", ANSIState{Italic: true}},
+		{"\x1b[2Kerased\x1b]0;title\x07", "erased", "erased", ANSIState{}},
+		{"a\x1b[31;47;7mb", "ab", `a<span style="color:` + ANSIColors[7] + `;background-color:` + ANSIColors[1] + `;">b</span>`, ANSIState{}},
+		{"\x1b[38;5;196mx\x1b[39m", "x", `<span style="color:` + ANSIColor256(196) + `;">x</span>`, ANSIState{}},
+		{"trunc\x1b[3", "trunc", "trunc", ANSIState{}},
+	}
+	for _, tst := range tests {
+		st := tst.st
+		txt, mu := ANSIToMarkup([]byte(tst.in), &st)
+		if string(txt) != tst.text {
+			t.Errorf("ANSIToMarkup(%q): text %q != %q", tst.in, txt, tst.text)
+		}
+		if string(mu) != tst.markup {
+			t.Errorf("ANSIToMarkup(%q): markup %q != %q", tst.in, mu, tst.markup)
+		}
+	}
+}
+
+func TestANSIMarkupFun(t *testing.T) {
+	var st ANSIState
+	txt, runs := ANSIToRuns([]byte("err: \x1b[31mfile.go:10\x1b[0m <x>"), &st)
+	mu := HTMLEscapeBytes(txt)
+	mu = bytes.Replace(mu, []byte("file.go:10"), []byte(`<a href="file.go">file.go:10</a>`), 1)
+	mu = ANSIMarkup(mu, runs)
+	red := `<span style="color:` + ANSIColors[1] + `;">`
+	exp := `err: <a href="file.go">` + red + `file.go:10</span></a> &lt;x&gt;`
+	if string(mu) != exp {
+		t.Errorf("ANSIMarkup: %q != %q", mu, exp)
+	}
+	if !st.IsDefault() {
+		t.Errorf("state not reset: %+v", st)
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// CmdPaletteItem is one command available in the command palette: either an
+// Action from the main menu, a toolbar or the window shortcuts, or a method
+// registered for CallMethod on one of the values shown in the window
+type CmdPaletteItem struct {
+	Command string        `width:"30" desc:"name of the command"`
+	Keys    string        `width:"12" desc:"key chord that triggers the command, from the active KeyMap"`
+	Where   string        `width:"20" desc:"where the command lives: menu path, ToolBar, Shortcut, or type name for methods"`
+	Desc    string        `tableview:"-" desc:"description of the command"`
+	Act     *gi.Action    `tableview:"-" view:"-" json:"-" xml:"-" desc:"action to trigger, if an action"`
+	Val     interface{}   `tableview:"-" view:"-" json:"-" xml:"-" desc:"value to call Method on, if a method"`
+	Method  string        `tableview:"-" view:"-" json:"-" xml:"-" desc:"method to call on Val, via CallMethod"`
+	Score   int           `tableview:"-" view:"-" json:"-" xml:"-" desc:"fuzzy match score for current search"`
+	mvd     *MethViewData `view:"-"`
+}
+
+// CmdPalette gathers all the commands available in a window, and filters
+// them according to a fuzzy search string
+type CmdPalette struct {
+	Win    *gi.Window        `desc:"window that commands are gathered from, and run in"`
+	Search string            `desc:"current search string"`
+	All    []*CmdPaletteItem `desc:"all the commands available in the window"`
+	Items  []*CmdPaletteItem `desc:"commands matching the current search, best match first"`
+}
+
+var KiT_CmdPalette = kit.Types.AddType(&CmdPalette{}, nil)
+
+// NewCmdPalette returns a new command palette with all the commands
+// available in given window
+func NewCmdPalette(win *gi.Window) *CmdPalette {
+	cp := &CmdPalette{Win: win}
+	cp.Gather()
+	cp.Filter("")
+	return cp
+}
+
+// Gather gathers all the currently active commands in the window: main menu
+// and toolbar actions, registered shortcuts, and the CallMethod methods of
+// values that those actions or any StructView operate on
+func (cp *CmdPalette) Gather() {
+	cp.All = nil
+	win := cp.Win
+	if win == nil {
+		return
+	}
+	acts := make(map[*gi.Action]struct{})
+	meths := make(map[interface{}]map[string]struct{})
+	var vals []interface{}
+	addVal := func(val interface{}) {
+		if kit.IfaceIsNil(val) {
+			return
+		}
+		if _, _, ok := MethViewTypeProps(val); !ok {
+			return
+		}
+		if _, has := meths[val]; has {
+			return
+		}
+		meths[val] = make(map[string]struct{})
+		vals = append(vals, val)
+	}
+	var addAct func(act *gi.Action, where string)
+	addAct = func(act *gi.Action, where string) {
+		if act == nil || act.This() == nil || act.IsDestroyed() {
+			return
+		}
+		if _, has := acts[act]; has {
+			return
+		}
+		acts[act] = struct{}{}
+		nm := act.Text
+		if nm == "" {
+			nm = act.Nm
+		}
+		if act.MakeMenuFunc != nil {
+			act.MakeMenuFunc(act.This(), &act.Menu)
+		}
+		if len(act.Menu) > 0 {
+			pth := nm
+			if where != "" {
+				pth = where + " > " + nm
+			}
+			cp.GatherMenu(act.Menu, pth, addAct)
+			return
+		}
+		if act.UpdateFunc != nil {
+			act.UpdateFunc(act)
+		}
+		if act.IsInactive() {
+			return
+		}
+		it := &CmdPaletteItem{Command: nm, Where: where, Desc: act.Tooltip, Act: act}
+		if md, ok := act.Data.(*MethViewData); ok {
+			it.mvd = md
+			addVal(md.Val)
+			if mm, has := meths[md.Val]; has {
+				mm[md.Method] = struct{}{}
+			}
+			if it.Desc == "" {
+				it.Desc = md.Desc
+			}
+		}
+		cp.All = append(cp.All, it)
+	}
+
+	if win.MainMenu != nil {
+		for _, kid := range *win.MainMenu.Children() {
+			if act, ok := kid.(*gi.Action); ok {
+				addAct(act, "")
+			}
+		}
+	}
+	if win.Viewport != nil {
+		win.Viewport.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+			nii, ok := k.(gi.Node2D)
+			if !ok || nii.AsNode2D() == nil || nii.AsNode2D().IsInvisible() {
+				return ki.Break
+			}
+			switch kv := k.(type) {
+			case *gi.ToolBar:
+				for _, kid := range *kv.Children() {
+					if act, ok := kid.(*gi.Action); ok {
+						addAct(act, "ToolBar")
+					}
+				}
+				return ki.Break
+			case *StructView:
+				addVal(kv.Struct)
+			}
+			return ki.Continue
+		})
+	}
+	scs := make([]key.Chord, 0, len(win.Shortcuts))
+	for sc := range win.Shortcuts {
+		scs = append(scs, sc)
+	}
+	sort.Slice(scs, func(i, j int) bool { return scs[i] < scs[j] })
+	for _, sc := range scs {
+		addAct(win.Shortcuts[sc], "Shortcut")
+	}
+
+	for _, val := range vals {
+		cp.GatherMethods(val, meths[val])
+	}
+
+	rsc := make(map[*gi.Action]key.Chord, len(win.Shortcuts))
+	for sc, act := range win.Shortcuts {
+		rsc[act] = sc
+	}
+	for _, it := range cp.All {
+		var sc key.Chord
+		switch {
+		case it.mvd != nil && bitflag.Has32(int32(it.mvd.Flags), int(MethViewKeyFun)):
+			sc = gi.ShortcutForFun(it.mvd.KeyFun)
+		case it.Act != nil:
+			if rs, has := rsc[it.Act]; has {
+				sc = rs.OSShortcut()
+			} else {
+				sc = it.Act.Shortcut
+			}
+		}
+		if sc != "" {
+			it.Keys = sc.Shortcut()
+		}
+	}
+}
+
+// GatherMenu calls addAct on each of the actions in given menu, with where
+// being the menu path
+func (cp *CmdPalette) GatherMenu(m gi.Menu, where string, addAct func(act *gi.Action, where string)) {
+	for _, kid := range m {
+		if act, ok := kid.(*gi.Action); ok {
+			addAct(act, where)
+		}
+	}
+}
+
+// GatherMethods adds all of the methods available for CallMethod on given
+// value, except those in the have map which are already available as actions
+func (cp *CmdPalette) GatherMethods(val interface{}, have map[string]struct{}) {
+	tpp, vtyp, ok := MethViewTypeProps(val)
+	if !ok {
+		return
+	}
+	vp := cp.Win.Viewport
+	cmp, ok := ki.SubTypeProps(tpp, MethodViewCallMethsProp)
+	if !ok {
+		cmp = MethViewCompileMeths(val, vp)
+	}
+	nms := make([]string, 0, len(cmp))
+	for nm := range cmp {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	where := kit.ShortTypeName(kit.NonPtrType(vtyp))
+	for _, nm := range nms {
+		if _, has := have[nm]; has {
+			continue
+		}
+		ac, ok := cmp[nm].(*gi.Action)
+		if !ok {
+			continue
+		}
+		md, ok := ac.Data.(*MethViewData)
+		if !ok {
+			continue
+		}
+		MethViewSetActionData(ac, val, vp)
+		if ac.UpdateFunc != nil {
+			ac.UpdateFunc(ac)
+		}
+		if ac.IsInactive() {
+			continue
+		}
+		it := &CmdPaletteItem{Command: ac.Text, Where: where, Desc: md.Desc, Val: val, Method: nm, mvd: md}
+		if ac.Shortcut != "" {
+			it.Act = ac // only used for shortcut display
+		}
+		cp.All = append(cp.All, it)
+	}
+}
+
+// Filter sets the Items to those matching given search string according to
+// FuzzyMatch, sorted by match score, best first.  The Where path is also
+// searched, with a lower score.  An empty search returns all the items.
+func (cp *CmdPalette) Filter(search string) {
+	cp.Search = search
+	cp.Items = make([]*CmdPaletteItem, 0, len(cp.All))
+	for _, it := range cp.All {
+		it.Score = 0
+		if search != "" {
+			sc, ok := FuzzyMatch(search, it.Command)
+			if !ok {
+				sc, ok = FuzzyMatch(search, it.Where+" "+it.Command)
+				sc -= 4 * len(it.Where)
+			}
+			if !ok {
+				continue
+			}
+			it.Score = sc
+		}
+		cp.Items = append(cp.Items, it)
+	}
+	sort.SliceStable(cp.Items, func(i, j int) bool {
+		return cp.Items[i].Score > cp.Items[j].Score
+	})
+}
+
+// Run runs given command: triggers the action, or calls the method using
+// CallMethod, which prompts for any args
+func (cp *CmdPalette) Run(it *CmdPaletteItem) {
+	if it == nil {
+		return
+	}
+	if it.Method != "" {
+		CallMethod(it.Val, it.Method, cp.Win.Viewport)
+		return
+	}
+	if it.Act != nil && !it.Act.IsDestroyed() {
+		it.Act.Trigger()
+	}
+}
+
+// FuzzyMatch returns whether all the characters in pattern occur in str in
+// the same order (ignoring case and spaces in pattern), and a score for the
+// match, which is higher for runs of consecutive characters and for matches
+// at the start of words (including camel-case words), and lower for
+// unmatched characters in str.
+func FuzzyMatch(pattern, str string) (int, bool) {
+	pr := []rune(strings.ToLower(strings.Replace(pattern, " ", "", -1)))
+	if len(pr) == 0 {
+		return 0, true
+	}
+	sr := []rune(str)
+	score := 0
+	pi := 0
+	prvMatch := -2
+	for si, r := range sr {
+		if pi == len(pr) {
+			break
+		}
+		if unicode.ToLower(r) != pr[pi] {
+			continue
+		}
+		score++
+		if si == prvMatch+1 {
+			score += 4
+		}
+		if si == 0 {
+			score += 8
+		} else {
+			pc := sr[si-1]
+			if !unicode.IsLetter(pc) && !unicode.IsDigit(pc) || unicode.IsUpper(r) && unicode.IsLower(pc) {
+				score += 6
+			}
+		}
+		prvMatch = si
+		pi++
+	}
+	if pi < len(pr) {
+		return 0, false
+	}
+	score = 4*score - (len(sr) - len(pr)) // unmatched chars only break ties
+	return score, true
+}
+
+// CommandPaletteDialog opens a command palette for given window, with a
+// search field and a table of all the actions, shortcuts and methods
+// available in the window.  Typing in the search field fuzzy-filters the
+// commands, up / down move the selection, and Enter (or double-click) runs
+// the selected command (the best match if none is selected).
+func CommandPaletteDialog(win *gi.Window) *gi.Dialog {
+	if win == nil || win.Viewport == nil {
+		return nil
+	}
+	avp := win.Viewport
+	cp := NewCmdPalette(win)
+
+	opts := DlgOpts{Title: "Command Palette", Prompt: "Search for a command to run"}
+	opts.CSS = ki.Props{
+		"textfield": ki.Props{
+			":inactive": ki.Props{
+				"background-color": &gi.Prefs.Colors.Control,
+			},
+		},
+	}
+	dlg := gi.NewStdDialog(opts.ToGiOpts(), gi.AddOk, gi.AddCancel)
+
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+
+	tf := frame.InsertNewChild(gi.KiT_TextField, prIdx+1, "search").(*gi.TextField)
+	tf.Placeholder = "command"
+	tf.SetStretchMaxWidth()
+	tf.SetMinPrefWidth(units.NewCh(60))
+
+	tv := frame.InsertNewChild(KiT_TableView, prIdx+2, "tableview").(*TableView)
+	tv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	tv.SetInactiveState(true)
+	tv.NoAdd = true
+	tv.NoDelete = true
+	tv.SelectedIdx = 0
+	tv.SetSlice(&cp.Items)
+
+	filter := func() {
+		cp.Filter(string(tf.EditTxt))
+		tv.SetSlice(&cp.Items)
+		if len(cp.Items) > 0 {
+			tv.SelectIdxAction(0, mouse.SelectOne)
+		} else {
+			tv.ResetSelectedIdxs()
+		}
+	}
+	tf.TextFieldSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		switch gi.TextFieldSignals(sig) {
+		case gi.TextFieldInsert, gi.TextFieldBackspace, gi.TextFieldDelete, gi.TextFieldCleared:
+			filter()
+		}
+	})
+
+	tv.SliceViewSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(SliceViewDoubleClicked) {
+			ddlg := recv.Embed(gi.KiT_Dialog).(*gi.Dialog)
+			ddlg.Accept()
+		}
+	})
+
+	dlg.DialogSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.DialogAccepted) || len(cp.Items) == 0 {
+			return
+		}
+		idx := tv.SelectedIdx
+		if idx < 0 || idx >= len(cp.Items) {
+			idx = 0
+		}
+		cp.Run(cp.Items[idx])
+	})
+
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, avp, func() {
+		// keys in the search field drive the table -- HiPri gets them before
+		// the field itself
+		dlg.Win.EventMgr.ConnectEvent(tf.This(), oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			kt := d.(*key.ChordEvent)
+			switch gi.KeyFun(kt.Chord()) {
+			case gi.KeyFunMoveUp:
+				kt.SetProcessed()
+				tv.MoveUpAction(mouse.SelectOne)
+			case gi.KeyFunMoveDown:
+				kt.SetProcessed()
+				tv.MoveDownAction(mouse.SelectOne)
+			case gi.KeyFunPageUp:
+				kt.SetProcessed()
+				tv.MovePageUpAction(mouse.SelectOne)
+			case gi.KeyFunPageDown:
+				kt.SetProcessed()
+				tv.MovePageDownAction(mouse.SelectOne)
+			case gi.KeyFunEnter, gi.KeyFunAccept:
+				kt.SetProcessed()
+				dlg.Accept()
+			}
+		})
+	})
+	return dlg
+}
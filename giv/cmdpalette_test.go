@@ -0,0 +1,69 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import "testing"
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, str string
+		ok           bool
+	}{
+		{"", "Save", true},
+		{"save", "Save", true},
+		{"sa as", "Save As...", true},
+		{"svas", "Save As...", true},
+		{"FOpen", "File Open", true},
+		{"avs", "Save", false},
+		{"saves", "Save", false},
+		{"xyz", "Save As", false},
+	}
+	for _, tst := range tests {
+		if _, ok := FuzzyMatch(tst.pattern, tst.str); ok != tst.ok {
+			t.Errorf("FuzzyMatch(%q, %q): %v, expected %v", tst.pattern, tst.str, ok, tst.ok)
+		}
+	}
+
+	// better matches must score higher: pattern, better, worse
+	order := [][3]string{
+		{"save", "Save", "Save As"},         // fewer unmatched chars
+		{"sa", "Show All", "Resample"},      // word starts
+		{"fo", "FileOpen", "Info"},          // camel-case word start
+		{"op", "Open", "Show Options"},      // start of string and run
+		{"cw", "Close Window", "Clear Row"}, // word start vs inner char
+	}
+	for _, tst := range order {
+		bs, bok := FuzzyMatch(tst[0], tst[1])
+		ws, wok := FuzzyMatch(tst[0], tst[2])
+		if !bok || !wok {
+			t.Errorf("FuzzyMatch(%q): no match for %q: %v or %q: %v", tst[0], tst[1], bok, tst[2], wok)
+			continue
+		}
+		if bs <= ws {
+			t.Errorf("FuzzyMatch(%q): %q score %v <= %q score %v", tst[0], tst[1], bs, tst[2], ws)
+		}
+	}
+}
+
+func TestCmdPaletteFilter(t *testing.T) {
+	cp := &CmdPalette{All: []*CmdPaletteItem{
+		{Command: "Close Window", Where: "File"},
+		{Command: "Save As...", Where: "File"},
+		{Command: "Save", Where: "File"},
+		{Command: "Copy", Where: "Edit"},
+	}}
+	cp.Filter("")
+	if len(cp.Items) != 4 {
+		t.Errorf("Filter(\"\"): %v items, expected all", len(cp.Items))
+	}
+	cp.Filter("save")
+	if len(cp.Items) != 2 || cp.Items[0].Command != "Save" {
+		t.Errorf("Filter(\"save\"): wrong items: %v", cp.Items)
+	}
+	cp.Filter("edit copy") // matches where path
+	if len(cp.Items) != 1 || cp.Items[0].Command != "Copy" {
+		t.Errorf("Filter(\"edit copy\"): wrong items: %v", cp.Items)
+	}
+}
@@ -121,6 +121,9 @@ func StructViewDialog(avp *gi.Viewport2D, stru interface{}, opts DlgOpts, recv k
 	sv.ViewPath = opts.ViewPath
 	sv.TmpSave = opts.TmpSave
 	sv.SetStruct(stru)
+	if !opts.Inactive {
+		dlg.AcceptCheck = sv.CheckValid // keep open while values are invalid
+	}
 	if recv != nil && dlgFunc != nil {
 		dlg.DialogSig.Connect(recv, dlgFunc)
 	}
@@ -0,0 +1,408 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+)
+
+// FileSearchCats are the default file categories searched by a FileSearch
+// when FileSearchParams.Cats is empty -- i.e., all the text file categories
+var FileSearchCats = []filecat.Cat{filecat.Code, filecat.Doc, filecat.Data, filecat.Text}
+
+// FileSearchIgnore are the default file and directory name patterns skipped
+// by a FileSearch when FileSearchParams.Ignore is empty
+var FileSearchIgnore = append([]string{"node_modules", "*~", "#*#", ".*.undo"}, VcsMetaDirs...)
+
+// errFileSearchCanceled stops walking the files when a search is canceled
+var errFileSearchCanceled = errors.New("giv.FileSearch: canceled")
+
+// FileSearchParams are the parameters for searching the files in a FileTree
+type FileSearchParams struct {
+	Find       string        `desc:"text to find"`
+	IgnoreCase bool          `desc:"ignore case when matching"`
+	Regexp     bool          `desc:"Find is a regular expression (Go regexp syntax)"`
+	WholeWord  bool          `desc:"only match Find where it starts and ends at word boundaries"`
+	Cats       []filecat.Cat `desc:"categories of files to search -- FileSearchCats if empty"`
+	Ignore     []string      `desc:"patterns (as in filepath.Match) for names of files and directories to skip -- patterns containing a path separator are matched against the path relative to the root -- FileSearchIgnore if empty"`
+}
+
+// CompileRegexp returns the regular expression for the search -- plain
+// text is quoted, so all searches use the same regexp-based search
+func (sp *FileSearchParams) CompileRegexp() (*regexp.Regexp, error) {
+	str := sp.Find
+	if !sp.Regexp {
+		str = regexp.QuoteMeta(str)
+	}
+	if sp.WholeWord {
+		str = `\b(?:` + str + `)\b`
+	}
+	if sp.IgnoreCase {
+		str = "(?i)" + str
+	}
+	return regexp.Compile(str)
+}
+
+// IsIgnored returns true if given file or directory should be skipped,
+// given its path relative to the root of the search
+func (sp *FileSearchParams) IsIgnored(rpath string) bool {
+	pats := sp.Ignore
+	if len(pats) == 0 {
+		pats = FileSearchIgnore
+	}
+	nm := filepath.Base(rpath)
+	for _, pat := range pats {
+		mpath := nm
+		if strings.ContainsRune(pat, filepath.Separator) {
+			mpath = rpath
+		}
+		if ok, _ := filepath.Match(pat, mpath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCatSearched returns true if files of given category are searched
+func (sp *FileSearchParams) IsCatSearched(cat filecat.Cat) bool {
+	cats := sp.Cats
+	if len(cats) == 0 {
+		cats = FileSearchCats
+	}
+	for _, c := range cats {
+		if c == cat {
+			return true
+		}
+	}
+	return false
+}
+
+// FileSearchCat returns the category of given file for searching: from
+// its mime type, or, if that is unknown (e.g., no extension), Text if the
+// start of the file looks like text, and otherwise Bin
+func FileSearchCat(path string) filecat.Cat {
+	mtyp, _, err := filecat.MimeFromFile(path)
+	if err == nil {
+		if cat := filecat.CatFromMime(mtyp); cat != filecat.Unknown {
+			return cat
+		}
+	}
+	fp, err := os.Open(path)
+	if err != nil {
+		return filecat.Unknown
+	}
+	defer fp.Close()
+	var buf [512]byte
+	n, _ := io.ReadFull(fp, buf[:])
+	if strings.HasPrefix(http.DetectContentType(buf[:n]), "text/") {
+		return filecat.Text
+	}
+	return filecat.Bin
+}
+
+// FileSearchResult is the list of matches within one file
+type FileSearchResult struct {
+	Path    string          `desc:"full path to the file"`
+	Count   int             `desc:"number of matches"`
+	Matches []textbuf.Match `desc:"the matches, with surrounding text"`
+	Search  *FileSearch     `json:"-" xml:"-" view:"-" desc:"the search that found this result"`
+}
+
+// FileSearch is a search through all the files within the root directory of
+// a FileTree, run concurrently in the background -- see FileTree.Search.
+// Files that are open in the tree are searched in their TextBuf, so unsaved
+// edits are included.
+type FileSearch struct {
+	Params   FileSearchParams            `desc:"the search parameters"`
+	Tree     *FileTree                   `desc:"the tree being searched"`
+	Results  []FileSearchResult          `desc:"results for each file with matches -- sorted by path once done -- lock Mu to access while searching"`
+	NFiles   int                         `desc:"number of files searched so far"`
+	Mu       sync.Mutex                  `json:"-" xml:"-" view:"-" desc:"mutex protecting Results and NFiles"`
+	Func     func(res *FileSearchResult) `json:"-" xml:"-" view:"-" desc:"function called with each result as it is found, from a separate goroutine, one at a time"`
+	root     string
+	re       *regexp.Regexp
+	bufs     map[string]*TextBuf
+	paths    chan string
+	results  chan *FileSearchResult
+	cancel   chan struct{}
+	cancOnce sync.Once
+	done     chan struct{}
+}
+
+// Search starts searching all the files within the root directory of the
+// tree, and any external files, with given parameters, running in the
+// background -- fun is called with each file's result as it is found.
+// Use Cancel to stop, and Wait to wait for all results.  Returns an error if
+// the Find regexp is invalid.
+func (ft *FileTree) Search(params FileSearchParams, fun func(res *FileSearchResult)) (*FileSearch, error) {
+	fs := &FileSearch{Params: params, Tree: ft, Func: fun}
+	if params.Find == "" {
+		err := fmt.Errorf("giv.FileTree Search: nothing to find")
+		log.Println(err)
+		return nil, err
+	}
+	re, err := params.CompileRegexp()
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	fs.re = re
+	fs.root = string(ft.FPath)
+	fs.bufs = make(map[string]*TextBuf)
+	ft.FuncDownMeFirst(0, ft, func(k ki.Ki, level int, d interface{}) bool {
+		sfn := k.Embed(KiT_FileNode).(*FileNode)
+		if sfn.Buf != nil && sfn.Buf.Filename == sfn.FPath {
+			fs.bufs[string(sfn.FPath)] = sfn.Buf
+		}
+		return ki.Continue
+	})
+	fs.paths = make(chan string, 100)
+	fs.results = make(chan *FileSearchResult, 100)
+	fs.cancel = make(chan struct{})
+	fs.done = make(chan struct{})
+	go fs.walk(append([]string{}, ft.ExtFiles...))
+	nw := runtime.NumCPU()
+	var wg sync.WaitGroup
+	wg.Add(nw)
+	for i := 0; i < nw; i++ {
+		go func() {
+			fs.worker()
+			wg.Done()
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(fs.results)
+	}()
+	go fs.collect()
+	return fs, nil
+}
+
+// Cancel stops the search -- results found so far remain
+func (fs *FileSearch) Cancel() {
+	fs.cancOnce.Do(func() { close(fs.cancel) })
+}
+
+// IsCanceled returns true if the search was canceled
+func (fs *FileSearch) IsCanceled() bool {
+	select {
+	case <-fs.cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDone returns true if the search has finished (or been canceled and
+// stopped)
+func (fs *FileSearch) IsDone() bool {
+	select {
+	case <-fs.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait waits for the search to finish, and returns the results
+func (fs *FileSearch) Wait() []FileSearchResult {
+	<-fs.done
+	return fs.Results
+}
+
+// NMatches returns the total number of matches found so far
+func (fs *FileSearch) NMatches() int {
+	fs.Mu.Lock()
+	defer fs.Mu.Unlock()
+	n := 0
+	for i := range fs.Results {
+		n += fs.Results[i].Count
+	}
+	return n
+}
+
+// walk sends the paths of all the files to search to the workers
+func (fs *FileSearch) walk(extFiles []string) {
+	defer close(fs.paths)
+	send := func(path string) bool {
+		select {
+		case fs.paths <- path:
+			return true
+		case <-fs.cancel:
+			return false
+		}
+	}
+	for _, ef := range extFiles {
+		if info, err := os.Stat(ef); err == nil && !info.IsDir() {
+			if !send(ef) {
+				return
+			}
+		}
+	}
+	filepath.Walk(fs.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // skip
+		}
+		if path == fs.root {
+			return nil
+		}
+		rpath, _ := filepath.Rel(fs.root, path)
+		if fs.Params.IsIgnored(rpath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if !send(path) {
+			return errFileSearchCanceled
+		}
+		return nil
+	})
+}
+
+// worker searches each file from walk
+func (fs *FileSearch) worker() {
+	for path := range fs.paths {
+		if fs.IsCanceled() {
+			continue // drain
+		}
+		res := fs.searchFile(path)
+		if res == nil {
+			continue
+		}
+		select {
+		case fs.results <- res:
+		case <-fs.cancel:
+		}
+	}
+}
+
+// searchFile searches one file, returning nil if no matches or not searched
+func (fs *FileSearch) searchFile(path string) *FileSearchResult {
+	var cnt int
+	var mats []textbuf.Match
+	if tb, has := fs.bufs[path]; has {
+		cnt, mats = tb.SearchRegexp(fs.re)
+	} else {
+		if !fs.Params.IsCatSearched(FileSearchCat(path)) {
+			return nil
+		}
+		cnt, mats = textbuf.SearchFileRegexp(path, fs.re)
+	}
+	fs.Mu.Lock()
+	fs.NFiles++
+	fs.Mu.Unlock()
+	if cnt == 0 {
+		return nil
+	}
+	return &FileSearchResult{Path: path, Count: cnt, Matches: mats}
+}
+
+// collect records the results and calls Func for each
+func (fs *FileSearch) collect() {
+	for res := range fs.results {
+		res.Search = fs
+		fs.Mu.Lock()
+		fs.Results = append(fs.Results, *res)
+		fs.Mu.Unlock()
+		if fs.Func != nil {
+			fs.Func(res)
+		}
+	}
+	fs.Mu.Lock()
+	sort.Slice(fs.Results, func(i, j int) bool {
+		return fs.Results[i].Path < fs.Results[j].Path
+	})
+	fs.Mu.Unlock()
+	close(fs.done)
+}
+
+// ReplaceAll replaces all the matches in the files in the search results
+// with repl, which can contain $1 etc references to regexp submatches.
+// The replacements are made in the TextBuf for each file, opening it in the
+// tree if it is not already open, so they can be undone, and each file's
+// replacements are undone together -- the buffers are not saved.
+// The buffers are searched again, in case they changed since the search.
+// Must be called after the search is done.  Returns the buffers changed
+// and the total number of replacements.
+func (fs *FileSearch) ReplaceAll(repl string) ([]*TextBuf, int) {
+	var bufs []*TextBuf
+	nrep := 0
+	for _, res := range fs.Wait() {
+		fn, ok := fs.Tree.FindFile(res.Path)
+		if !ok || string(fn.FPath) != res.Path {
+			log.Printf("giv.FileSearch ReplaceAll: file not found in tree: %v\n", res.Path)
+			continue
+		}
+		if _, err := fn.OpenBuf(); err != nil {
+			continue
+		}
+		n := fs.ReplaceInBuf(fn.Buf, repl)
+		if n > 0 {
+			bufs = append(bufs, fn.Buf)
+			nrep += n
+		}
+	}
+	return bufs, nrep
+}
+
+// fileSearchRepl is one replacement to make in ReplaceInBuf
+type fileSearchRepl struct {
+	st, ed lex.Pos
+	rep    string
+}
+
+// ReplaceInBuf replaces all the matches of the search in given buffer with
+// repl, as one undo group -- returns the number of replacements.  For a
+// Regexp search, $1 etc in repl are expanded from the submatches of each
+// match within its whole line, so anchors and context still apply.
+func (fs *FileSearch) ReplaceInBuf(tb *TextBuf, repl string) int {
+	var reps []fileSearchRepl
+	tb.LinesMu.RLock()
+	for ln, b := range tb.LineBytes {
+		for _, si := range fs.re.FindAllSubmatchIndex(b, -1) {
+			r := fileSearchRepl{st: lex.Pos{Ln: ln, Ch: utf8.RuneCount(b[:si[0]])}, rep: repl}
+			r.ed = lex.Pos{Ln: ln, Ch: r.st.Ch + utf8.RuneCount(b[si[0]:si[1]])}
+			if fs.Params.Regexp {
+				r.rep = string(fs.re.Expand(nil, []byte(repl), b, si))
+			}
+			reps = append(reps, r)
+		}
+	}
+	tb.LinesMu.RUnlock()
+	if len(reps) == 0 {
+		return 0
+	}
+	// as in QReplace, match case of replaced text if not using case, and
+	// replacement is all lower case
+	matchCase := fs.Params.IgnoreCase && !lex.HasUpperCase(repl)
+	tb.Undos.GroupStart()
+	for i := len(reps) - 1; i >= 0; i-- { // from end so positions remain valid
+		r := reps[i]
+		tb.ReplaceText(r.st, r.ed, r.st, r.rep, EditSignal, matchCase)
+	}
+	tb.Undos.GroupEnd()
+	return len(reps)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSearchIsIgnored(t *testing.T) {
+	sep := string(filepath.Separator)
+	tests := []struct {
+		ignore []string
+		rpath  string
+		ign    bool
+	}{
+		{nil, "node_modules", true},
+		{nil, "src" + sep + "node_modules", true},
+		{nil, "main.go~", true},
+		{nil, "#main.go#", true},
+		{nil, ".main.go.undo", true},
+		{nil, ".git", true},
+		{nil, "main.go", false},
+		{[]string{"*.txt"}, "docs" + sep + "notes.txt", true},
+		{[]string{"*.txt"}, "node_modules", false},
+		{[]string{"docs" + sep + "*"}, "docs" + sep + "notes.txt", true},
+		{[]string{"docs" + sep + "*"}, "src" + sep + "docs", false},
+	}
+	for _, tst := range tests {
+		sp := FileSearchParams{Ignore: tst.ignore}
+		if ign := sp.IsIgnored(tst.rpath); ign != tst.ign {
+			t.Errorf("IsIgnored(%q) with %v = %v, expected %v", tst.rpath, tst.ignore, ign, tst.ign)
+		}
+	}
+}
+
+func TestFileSearchCompileRegexp(t *testing.T) {
+	txt := "a.b axb Foo foobar foo(1)"
+	tests := []struct {
+		params  FileSearchParams
+		matches []string
+	}{
+		{FileSearchParams{Find: "a.b"}, []string{"a.b"}},
+		{FileSearchParams{Find: "a.b", Regexp: true}, []string{"a.b", "axb"}},
+		{FileSearchParams{Find: "foo"}, []string{"foo", "foo"}},
+		{FileSearchParams{Find: "foo", IgnoreCase: true}, []string{"Foo", "foo", "foo"}},
+		{FileSearchParams{Find: "foo", WholeWord: true}, []string{"foo"}},
+		{FileSearchParams{Find: "foo", WholeWord: true, IgnoreCase: true}, []string{"Foo", "foo"}},
+		{FileSearchParams{Find: "foo(1)", WholeWord: true}, nil}, // ends in non-word char
+		{FileSearchParams{Find: "f[a-z]+|axb", Regexp: true, WholeWord: true}, []string{"axb", "foobar", "foo"}},
+	}
+	for _, tst := range tests {
+		re, err := tst.params.CompileRegexp()
+		if err != nil {
+			t.Errorf("CompileRegexp(%+v): %v", tst.params, err)
+			continue
+		}
+		ms := re.FindAllString(txt, -1)
+		if len(ms) != len(tst.matches) {
+			t.Errorf("CompileRegexp(%+v): got matches %q, expected %q", tst.params, ms, tst.matches)
+			continue
+		}
+		for i, m := range ms {
+			if m != tst.matches[i] {
+				t.Errorf("CompileRegexp(%+v): got matches %q, expected %q", tst.params, ms, tst.matches)
+				break
+			}
+		}
+	}
+	if _, err := (&FileSearchParams{Find: "a(", Regexp: true}).CompileRegexp(); err == nil {
+		t.Errorf("CompileRegexp: expected error for invalid regexp")
+	}
+}
+
+func TestFileSearchReplaceInBuf(t *testing.T) {
+	textTestInit()
+	orig := "x := foo(1)\nfoo, bar := foo(2), Foo\n"
+	tests := []struct {
+		params FileSearchParams
+		repl   string
+		n      int
+		exp    string
+	}{
+		{FileSearchParams{Find: "foo"}, "baz", 3, "x := baz(1)\nbaz, bar := baz(2), Foo\n"},
+		{FileSearchParams{Find: "foo", IgnoreCase: true}, "baz", 4, "x := baz(1)\nbaz, bar := baz(2), Baz\n"},
+		{FileSearchParams{Find: `foo\((\d)\)`, Regexp: true}, "f$1()", 2, "x := f1()\nfoo, bar := f2(), Foo\n"},
+		{FileSearchParams{Find: "^foo", Regexp: true}, "qux", 1, "x := foo(1)\nqux, bar := foo(2), Foo\n"},
+		{FileSearchParams{Find: "nothing"}, "baz", 0, orig},
+	}
+	for _, tst := range tests {
+		fs := &FileSearch{Params: tst.params}
+		re, err := tst.params.CompileRegexp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs.re = re
+		tb := &TextBuf{}
+		tb.InitName(tb, "test")
+		tb.SetText([]byte(orig))
+		n := fs.ReplaceInBuf(tb, tst.repl)
+		if n != tst.n {
+			t.Errorf("ReplaceInBuf(%q, %q): got %v replacements, expected %v", tst.params.Find, tst.repl, n, tst.n)
+		}
+		if txt := string(tb.LinesToBytesCopy()); txt != tst.exp {
+			t.Errorf("ReplaceInBuf(%q, %q): got %q, expected %q", tst.params.Find, tst.repl, txt, tst.exp)
+		}
+		if n == 0 {
+			continue
+		}
+		tb.Undo()
+		if txt := string(tb.LinesToBytesCopy()); txt != orig {
+			t.Errorf("ReplaceInBuf(%q, %q): after one Undo got %q, expected %q", tst.params.Find, tst.repl, txt, orig)
+		}
+	}
+}
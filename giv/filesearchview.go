@@ -0,0 +1,420 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// FileSearchSignals are signals sent by a FileSearchView
+type FileSearchSignals int64
+
+const (
+	// FileSearchOpen means the user clicked on a match -- data is the
+	// *FileSearchLink -- if there are no receivers, the file is opened in a
+	// dialog
+	FileSearchOpen FileSearchSignals = iota
+
+	// FileSearchDone means the search finished or was canceled -- data is
+	// the *FileSearch
+	FileSearchDone
+
+	// FileSearchReplaced means Replace All was done -- data is the list of
+	// []*TextBuf that were changed, which have not been saved
+	FileSearchReplaced
+
+	FileSearchSignalsN
+)
+
+//go:generate stringer -type=FileSearchSignals
+
+// FileSearchLink is the location of a match, as opened by a FileSearchView
+type FileSearchLink struct {
+	Path string         `desc:"full path of the file"`
+	Reg  textbuf.Region `desc:"region of the match -- column positions are in runes"`
+}
+
+// URL returns the link url for the match, of the form
+// file:///path#L1C2-L1C5 (1-based line and column positions)
+func (fl *FileSearchLink) URL() string {
+	st := fl.Reg.Start
+	ed := fl.Reg.End
+	return fmt.Sprintf("file://%s#L%dC%d-L%dC%d", fl.Path, st.Ln+1, st.Ch+1, ed.Ln+1, ed.Ch+1)
+}
+
+// FromURL sets the link from a url as returned by URL -- returns false if
+// not a valid link
+func (fl *FileSearchLink) FromURL(url string) bool {
+	if !strings.HasPrefix(url, "file://") {
+		return false
+	}
+	url = strings.TrimPrefix(url, "file://")
+	fi := strings.LastIndex(url, "#")
+	if fi < 0 {
+		return false
+	}
+	fl.Path = url[:fi]
+	poss := strings.Split(url[fi+1:], "-")
+	if !fl.Reg.Start.FromString(poss[0]) {
+		return false
+	}
+	fl.Reg.End = fl.Reg.Start
+	if len(poss) > 1 {
+		fl.Reg.End.FromString(poss[1])
+	}
+	return true
+}
+
+// FileSearchView searches the files in a FileTree, showing the matches in
+// each file as they are found, as links that open the file at the match.
+// All the matches can be replaced, through the TextBuf of each file so the
+// changes can be undone and saved.
+type FileSearchView struct {
+	gi.Layout
+	Tree          *FileTree        `json:"-" xml:"-" copy:"-" desc:"the tree whose files are searched"`
+	Params        FileSearchParams `desc:"the search parameters"`
+	Replace       string           `desc:"replacement text for Replace All -- can contain $1 etc references to regexp submatches"`
+	Search        *FileSearch      `json:"-" xml:"-" copy:"-" desc:"the current or last search -- use CurSearch from other goroutines"`
+	Buf           *TextBuf         `json:"-" xml:"-" desc:"buffer showing the results"`
+	FileSearchSig ki.Signal        `json:"-" xml:"-" view:"-" desc:"signal for the view -- see FileSearchSignals for the types"`
+	searchMu      sync.Mutex
+}
+
+var KiT_FileSearchView = kit.Types.AddType(&FileSearchView{}, FileSearchViewProps)
+
+// AddNewFileSearchView adds a new filesearchview to given parent node, with given name.
+func AddNewFileSearchView(parent ki.Ki, name string) *FileSearchView {
+	return parent.AddNewChild(KiT_FileSearchView, name).(*FileSearchView)
+}
+
+func (sv *FileSearchView) Disconnect() {
+	sv.Layout.Disconnect()
+	sv.FileSearchSig.DisconnectAll()
+	sv.CancelSearch()
+}
+
+// Config configures the view to search the files in given tree
+func (sv *FileSearchView) Config(ft *FileTree) {
+	sv.Tree = ft
+	sv.Lay = gi.LayoutVert
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "findbar")
+	config.Add(gi.KiT_ToolBar, "replbar")
+	config.Add(KiT_TextView, "results")
+	mods, updt := sv.ConfigChildren(config, ki.UniqueNames)
+	if mods {
+		if sv.Buf == nil {
+			sv.Buf = &TextBuf{}
+			sv.Buf.InitName(sv.Buf, "file-search-buf")
+		}
+		sv.ConfigToolBars()
+		tv := sv.TextView()
+		tv.SetStretchMax()
+		tv.SetInactive()
+		tv.SetProp("font-family", gi.Prefs.MonoFont)
+		tv.SetProp("white-space", gi.WhiteSpacePreWrap)
+		tv.SetBuf(sv.Buf)
+		tv.LinkSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv := recv.Embed(KiT_FileSearchView).(*FileSearchView)
+			var lnk FileSearchLink
+			if lnk.FromURL(data.(string)) {
+				svv.OpenMatch(&lnk)
+			}
+		})
+	} else {
+		updt = sv.UpdateStart()
+	}
+	sv.UpdateEnd(updt)
+}
+
+// FindBar returns the toolbar with the find parameters
+func (sv *FileSearchView) FindBar() *gi.ToolBar {
+	return sv.ChildByName("findbar", 0).(*gi.ToolBar)
+}
+
+// ReplBar returns the toolbar with the replace parameters
+func (sv *FileSearchView) ReplBar() *gi.ToolBar {
+	return sv.ChildByName("replbar", 1).(*gi.ToolBar)
+}
+
+// TextView returns the view of the results
+func (sv *FileSearchView) TextView() *TextView {
+	return sv.ChildByName("results", 2).(*TextView)
+}
+
+// ConfigToolBars configures the find and replace toolbars
+func (sv *FileSearchView) ConfigToolBars() {
+	fb := sv.FindBar()
+	fb.SetStretchMaxWidth()
+	gi.AddNewLabel(fb, "find-lbl", "Find:")
+	ftf := gi.AddNewTextField(fb, "find-tf")
+	ftf.SetStretchMaxWidth()
+	ftf.SetMinPrefWidth(units.NewCh(40))
+	ftf.Tooltip = "text to find -- press Enter to search"
+	ftf.SetText(sv.Params.Find)
+	ftf.TextFieldSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svv := recv.Embed(KiT_FileSearchView).(*FileSearchView)
+		switch sig {
+		case int64(gi.TextFieldDone):
+			svv.Params.Find = ftf.Text()
+			svv.StartSearch()
+		case int64(gi.TextFieldDeFocused):
+			svv.Params.Find = ftf.Text()
+		}
+	})
+	icb := gi.AddNewCheckBox(fb, "ignore-case")
+	icb.SetText("Ignore Case")
+	icb.Tooltip = "ignore case when matching"
+	icb.SetChecked(sv.Params.IgnoreCase)
+	icb.ButtonSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			svv := recv.Embed(KiT_FileSearchView).(*FileSearchView)
+			svv.Params.IgnoreCase = icb.IsChecked()
+		}
+	})
+	wcb := gi.AddNewCheckBox(fb, "whole-word")
+	wcb.SetText("Whole Word")
+	wcb.Tooltip = "only match whole words"
+	wcb.SetChecked(sv.Params.WholeWord)
+	wcb.ButtonSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			svv := recv.Embed(KiT_FileSearchView).(*FileSearchView)
+			svv.Params.WholeWord = wcb.IsChecked()
+		}
+	})
+	rcb := gi.AddNewCheckBox(fb, "regexp")
+	rcb.SetText("Regexp")
+	rcb.Tooltip = "find is a regular expression, and replace can use $1 etc for submatches"
+	rcb.SetChecked(sv.Params.Regexp)
+	rcb.ButtonSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			svv := recv.Embed(KiT_FileSearchView).(*FileSearchView)
+			svv.Params.Regexp = rcb.IsChecked()
+		}
+	})
+	fb.AddAction(gi.ActOpts{Label: "Search", Icon: "search", Tooltip: "search all the files in the tree"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv := recv.Embed(KiT_FileSearchView).(*FileSearchView)
+			svv.Params.Find = ftf.Text()
+			svv.StartSearch()
+		})
+	fb.AddAction(gi.ActOpts{Label: "Cancel", Icon: "close", Tooltip: "stop the current search", UpdateFunc: sv.SearchingUpdate},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv := recv.Embed(KiT_FileSearchView).(*FileSearchView)
+			svv.CancelSearch()
+		})
+
+	rb := sv.ReplBar()
+	rb.SetStretchMaxWidth()
+	gi.AddNewLabel(rb, "repl-lbl", "Replace:")
+	rtf := gi.AddNewTextField(rb, "repl-tf")
+	rtf.SetStretchMaxWidth()
+	rtf.SetMinPrefWidth(units.NewCh(40))
+	rtf.Tooltip = "replacement text for Replace All"
+	rtf.SetText(sv.Replace)
+	rtf.TextFieldSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldDeFocused) {
+			svv := recv.Embed(KiT_FileSearchView).(*FileSearchView)
+			svv.Replace = rtf.Text()
+		}
+	})
+	rb.AddAction(gi.ActOpts{Label: "Replace All", Icon: "search", Tooltip: "replace all the matches found by the last search, in the buffer for each file, where they can be undone -- the files are not saved", UpdateFunc: sv.HasResultsUpdate},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv := recv.Embed(KiT_FileSearchView).(*FileSearchView)
+			svv.Replace = rtf.Text()
+			svv.ReplaceAll()
+		})
+	gi.AddNewLabel(rb, "status", "")
+}
+
+// SearchingUpdate activates the action while searching
+func (sv *FileSearchView) SearchingUpdate(act *gi.Action) {
+	act.SetActiveStateUpdt(sv.Search != nil && !sv.Search.IsDone())
+}
+
+// HasResultsUpdate activates the action if the last search is done and
+// has results
+func (sv *FileSearchView) HasResultsUpdate(act *gi.Action) {
+	act.SetActiveStateUpdt(sv.Search != nil && sv.Search.IsDone() && len(sv.Search.Results) > 0)
+}
+
+// SetStatus sets the status text
+func (sv *FileSearchView) SetStatus(msg string) {
+	if lb, ok := sv.ReplBar().ChildByName("status", 2).(*gi.Label); ok {
+		lb.SetText(msg)
+	}
+}
+
+// UpdateToolBars updates the active state of the actions
+func (sv *FileSearchView) UpdateToolBars() {
+	sv.FindBar().UpdateActions()
+	sv.ReplBar().UpdateActions()
+}
+
+// StartSearch starts a new search with the current Params, canceling any
+// current search -- results are shown as they are found, on the main
+// thread, and any still arriving from a prior search are dropped
+func (sv *FileSearchView) StartSearch() error {
+	sv.CancelSearch()
+	sv.searchMu.Lock()
+	defer sv.searchMu.Unlock()
+	sv.Buf.New(0)
+	fs, err := sv.Tree.Search(sv.Params, func(res *FileSearchResult) {
+		oswin.TheApp.RunOnMain(func() {
+			if sv.CurSearch() == res.Search {
+				sv.AddResult(res)
+			}
+		})
+	})
+	if err != nil {
+		sv.Search = nil
+		sv.SetStatus(err.Error())
+		return err
+	}
+	sv.Search = fs
+	sv.SetStatus("Searching...")
+	sv.UpdateToolBars()
+	go func() {
+		fs.Wait()
+		oswin.TheApp.RunOnMain(func() {
+			if sv.CurSearch() == fs {
+				sv.SearchDone()
+			}
+		})
+	}()
+	return nil
+}
+
+// CurSearch returns the current Search, safely from any goroutine
+func (sv *FileSearchView) CurSearch() *FileSearch {
+	sv.searchMu.Lock()
+	defer sv.searchMu.Unlock()
+	return sv.Search
+}
+
+// CancelSearch cancels the current search, if any
+func (sv *FileSearchView) CancelSearch() {
+	if sv.Search != nil && !sv.Search.IsDone() {
+		sv.Search.Cancel()
+	}
+}
+
+// SearchDone is called when the search is done
+func (sv *FileSearchView) SearchDone() {
+	fs := sv.CurSearch()
+	msg := fmt.Sprintf("%d matches in %d files, of %d searched", fs.NMatches(), len(fs.Results), fs.NFiles)
+	if fs.IsCanceled() {
+		msg += " (canceled)"
+	}
+	sv.SetStatus(msg)
+	sv.UpdateToolBars()
+	sv.FileSearchSig.Emit(sv.This(), int64(FileSearchDone), fs)
+}
+
+// AddResult adds the matches for one file to the results buffer
+func (sv *FileSearchView) AddResult(res *FileSearchResult) {
+	var tb, mb bytes.Buffer
+	rpath := RelFilePath(res.Path, string(sv.Tree.FPath))
+	hdr := fmt.Sprintf("%s: %d", rpath, res.Count)
+	tb.WriteString(hdr + "\n")
+	mb.WriteString("<b>" + html.EscapeString(hdr) + "</b>\n")
+	for _, m := range res.Matches {
+		lnk := FileSearchLink{Path: res.Path, Reg: m.Reg}
+		pfx := fmt.Sprintf("    %d: ", m.Reg.Start.Ln+1)
+		txt := string(m.Text)
+		tb.WriteString(pfx + strings.NewReplacer("<mark>", "", "</mark>", "").Replace(txt) + "\n")
+		esc := strings.NewReplacer("&lt;mark&gt;", "<mark>", "&lt;/mark&gt;", "</mark>").Replace(html.EscapeString(txt))
+		mb.WriteString(`<a href="` + html.EscapeString(lnk.URL()) + `">` + pfx + esc + "</a>\n")
+	}
+	sv.Buf.Undos.Off = true
+	sv.Buf.AppendTextMarkup(tb.Bytes(), mb.Bytes(), EditSignal)
+}
+
+// OpenMatch opens the file at given match -- sends the FileSearchOpen
+// signal if it has any receivers, and otherwise opens the file in a dialog
+func (sv *FileSearchView) OpenMatch(lnk *FileSearchLink) {
+	if len(sv.FileSearchSig.Cons) > 0 {
+		sv.FileSearchSig.Emit(sv.This(), int64(FileSearchOpen), lnk)
+		return
+	}
+	fn, ok := sv.Tree.FindFile(lnk.Path)
+	if !ok || string(fn.FPath) != lnk.Path {
+		return
+	}
+	if _, err := fn.OpenBuf(); err != nil {
+		return
+	}
+	dlg := gi.NewStdDialog(gi.DlgOpts{Title: DirAndFile(lnk.Path)}, gi.NoOk, gi.NoCancel)
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	tlv := frame.InsertNewChild(gi.KiT_Layout, prIdx+1, "text-lay").(*gi.Layout)
+	tlv.SetProp("width", units.NewCh(80))
+	tlv.SetProp("height", units.NewEm(40))
+	tlv.SetStretchMax()
+	tv := AddNewTextView(tlv, "text-view")
+	tv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	tv.SetProp("font-family", gi.Prefs.MonoFont)
+	tv.SetBuf(fn.Buf)
+	reg := fn.Buf.AdjustReg(lnk.Reg)
+	tv.Highlights = []textbuf.Region{reg}
+	tv.CursorPos = reg.Start
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, sv.ViewportSafe(), func() {
+		tv.SetCursorShow(reg.Start)
+		tv.ScrollCursorToVertCenter()
+	})
+}
+
+// ReplaceAll replaces all the matches of the last search with Replace --
+// see FileSearch.ReplaceAll
+func (sv *FileSearchView) ReplaceAll() {
+	fs := sv.Search
+	if fs == nil || !fs.IsDone() {
+		return
+	}
+	bufs, nrep := fs.ReplaceAll(sv.Replace)
+	sv.SetStatus(fmt.Sprintf("replaced %d matches in %d files -- files must be saved", nrep, len(bufs)))
+	sv.UpdateToolBars()
+	sv.FileSearchSig.Emit(sv.This(), int64(FileSearchReplaced), bufs)
+}
+
+// FileSearchViewProps are style properties for FileSearchView
+var FileSearchViewProps = ki.Props{
+	"EnumType:Flag": gi.KiT_NodeFlags,
+	"max-width":     -1,
+	"max-height":    -1,
+}
+
+// FileSearchViewDialog opens a dialog for searching the files in given tree
+func FileSearchViewDialog(avp *gi.Viewport2D, ft *FileTree, params FileSearchParams) *FileSearchView {
+	dlg := gi.NewStdDialog(gi.DlgOpts{Title: "Find in Files: " + ft.Nm}, gi.NoOk, gi.NoCancel)
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+
+	sv := frame.InsertNewChild(KiT_FileSearchView, prIdx+1, "file-search").(*FileSearchView)
+	sv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	sv.Params = params
+	sv.Config(ft)
+	sv.SetMinPrefWidth(units.NewCh(100))
+	sv.SetMinPrefHeight(units.NewEm(40))
+
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, avp, nil)
+	if params.Find != "" {
+		sv.StartSearch()
+	}
+	return sv
+}
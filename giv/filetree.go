@@ -64,11 +64,13 @@ const (
 // interface into it.
 type FileTree struct {
 	FileNode
-	ExtFiles  []string     `desc:"external files outside the root path of the tree -- abs paths are stored -- these are shown in the first sub-node if present -- use AddExtFile to add and update"`
-	Dirs      DirFlagMap   `desc:"records state of directories within the tree (encoded using paths relative to root), e.g., open (have been opened by the user) -- can persist this to restore prior view of a tree"`
-	DirsOnTop bool         `desc:"if true, then all directories are placed at the top of the tree view -- otherwise everything is mixed"`
-	NodeType  reflect.Type `view:"-" json:"-" xml:"-" desc:"type of node to create -- defaults to giv.FileNode but can use custom node types"`
-	InOpenAll bool         `desc:"if true, we are in midst of an OpenAll call -- nodes should open all dirs"`
+	ExtFiles   []string     `desc:"external files outside the root path of the tree -- abs paths are stored -- these are shown in the first sub-node if present -- use AddExtFile to add and update"`
+	Dirs       DirFlagMap   `desc:"records state of directories within the tree (encoded using paths relative to root), e.g., open (have been opened by the user) -- can persist this to restore prior view of a tree"`
+	DirsOnTop  bool         `desc:"if true, then all directories are placed at the top of the tree view -- otherwise everything is mixed"`
+	NodeType   reflect.Type `view:"-" json:"-" xml:"-" desc:"type of node to create -- defaults to giv.FileNode but can use custom node types"`
+	InOpenAll  bool         `desc:"if true, we are in midst of an OpenAll call -- nodes should open all dirs"`
+	AutoReload bool         `desc:"if true, and watching for changes (see StartWatch), open file buffers without unsaved edits are automatically reloaded when the file changes on disk -- otherwise the user is prompted"`
+	Watcher    *FileWatcher `view:"-" json:"-" xml:"-" desc:"watches the open directories in the tree for changes on disk -- see StartWatch"`
 }
 
 var KiT_FileTree = kit.Types.AddType(&FileTree{}, FileTreeProps)
@@ -82,6 +84,12 @@ func (ft *FileTree) CopyFieldsFrom(frm interface{}) {
 	ft.FileNode.CopyFieldsFrom(&fr.FileNode)
 	ft.DirsOnTop = fr.DirsOnTop
 	ft.NodeType = fr.NodeType
+	ft.AutoReload = fr.AutoReload
+}
+
+func (ft *FileTree) Disconnect() {
+	ft.StopWatch()
+	ft.FileNode.Disconnect()
 }
 
 // OpenPath opens a filetree at given directory path -- reads all the files at
@@ -122,6 +130,130 @@ func (ft *FileTree) UpdateNewFile(filename string) {
 	}
 }
 
+// StartWatch starts watching the open directories in the tree for changes
+// on disk, so that the tree is updated when files are created, deleted or
+// renamed by other programs, VCS status is updated, and open file buffers
+// are reloaded when their files change.  Directories opened later are
+// watched as they are opened.
+func (ft *FileTree) StartWatch() error {
+	if ft.Watcher != nil {
+		return nil
+	}
+	var fw *FileWatcher
+	fw, err := NewFileWatcher(func(evs []FileWatchEvent) {
+		// the watcher calls from its own goroutine, so update on the main thread
+		oswin.TheApp.RunOnMain(func() {
+			if ft.Watcher == fw && ft.This() != nil {
+				ft.FilesChanged(evs)
+			}
+		})
+	})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	ft.Watcher = fw
+	ft.FuncDownMeFirst(0, ft, func(k ki.Ki, level int, d interface{}) bool {
+		sfn := k.Embed(KiT_FileNode).(*FileNode)
+		if sfn.IsDir() && sfn.IsOpen() && !sfn.IsExternal() {
+			sfn.WatchDir()
+		}
+		return ki.Continue
+	})
+	return nil
+}
+
+// StopWatch stops watching for changes on disk
+func (ft *FileTree) StopWatch() {
+	if ft.Watcher == nil {
+		return
+	}
+	ft.Watcher.Close()
+	ft.Watcher = nil
+}
+
+// FilesChanged updates the tree for a batch of changes on disk, reported by
+// the Watcher: directories with new or removed files are updated, the VCS
+// status is refreshed once for each repository affected, and open file
+// buffers whose files were changed are reloaded (see AutoReload).  Must be
+// called on the main thread -- StartWatch arranges that for the Watcher.
+func (ft *FileTree) FilesChanged(evs []FileWatchEvent) {
+	dirs := make(map[*FileNode]bool)
+	files := make(map[*FileNode]bool)
+	repos := make(map[*FileNode]bool)
+	for _, ev := range evs {
+		dpath, fnm := filepath.Split(ev.Path)
+		dpath = filepath.Clean(dpath)
+		if IsVcsMetaDir(filepath.Base(dpath)) { // e.g., commit or checkout
+			if rn, ok := ft.NodeByPath(filepath.Dir(dpath)); ok && rn.DirRepo != nil {
+				repos[rn] = true
+			}
+			continue
+		}
+		if IsVcsMetaDir(fnm) {
+			continue
+		}
+		fn, ok := ft.NodeByPath(ev.Path)
+		if ok && !fn.IsDir() && !ev.HasOp(FileWatchCreate) && !ev.HasOp(FileWatchRemove) && !ev.HasOp(FileWatchRename) {
+			files[fn] = true
+		} else if dn, ok := ft.NodeByPath(dpath); ok && dn.IsDir() {
+			dirs[dn] = true
+			fn = dn
+		} else {
+			continue
+		}
+		if _, rn := fn.Repo(); rn != nil {
+			repos[rn] = true
+		}
+	}
+	updt := ft.UpdateStart()
+	for rn := range repos {
+		rn.UpdateRepoFiles()
+	}
+	for dn := range dirs {
+		if dn.IsOpen() {
+			dn.UpdateDir()
+		}
+	}
+	for rn := range repos {
+		rn.UpdateRepoStatus()
+	}
+	for fn := range files {
+		if fn.InitFileInfo() != nil {
+			continue
+		}
+		if repo, rn := fn.Repo(); repo != nil {
+			fn.Info.Vcs = rn.RepoFiles.Status(repo, string(fn.FPath))
+		}
+		fn.UpdateSig()
+		if fn.Buf != nil {
+			fn.Buf.ReloadIfModified(ft.AutoReload)
+		}
+	}
+	ft.UpdateEnd(updt)
+}
+
+// NodeByPath returns the node for given full path, if it is in the tree --
+// unlike FindFile and DirsTo, it does not open any directories
+func (ft *FileTree) NodeByPath(path string) (*FileNode, bool) {
+	rpath := ft.RelPath(gi.FileName(path))
+	if rpath == "." {
+		return &ft.FileNode, true
+	}
+	if strings.HasPrefix(rpath, "..") {
+		return nil, false
+	}
+	cfn := &ft.FileNode
+	for _, dr := range strings.Split(rpath, string(filepath.Separator)) {
+		sfni := cfn.ChildByName(dr, 0)
+		if sfni == nil {
+			return nil, false
+		}
+		cfn = sfni.Embed(KiT_FileNode).(*FileNode)
+	}
+	return cfn, true
+}
+
 // IsDirOpen returns true if given directory path is open (i.e., has been
 // opened in the view)
 func (ft *FileTree) IsDirOpen(fpath gi.FileName) bool {
@@ -437,6 +569,7 @@ func (fn *FileNode) UpdateDir() {
 			hasExtFiles = true
 		}
 	}
+	fn.WatchDir()
 	mods, updt := fn.ConfigChildren(config, ki.NonUniqueNames) // NOT unique names
 	if mods {
 		// fmt.Printf("got mods: %v\n", path)
@@ -600,6 +733,20 @@ func (fn *FileNode) OpenDir() {
 	fn.UpdateNode()
 }
 
+// HasLazyChildren returns true if this is a directory that has not yet been
+// read -- satisfies the TreeLazyLoader interface.
+func (fn *FileNode) HasLazyChildren() bool {
+	return fn.IsDir() && !fn.IsIrregular() && !fn.IsOpen() && !fn.HasChildren()
+}
+
+// LoadChildren opens the directory, reading its files -- satisfies the
+// TreeLazyLoader interface.
+func (fn *FileNode) LoadChildren() error {
+	fn.SetOpen()
+	fn.FRoot.SetDirOpen(fn.FPath)
+	return fn.UpdateNode()
+}
+
 // CloseDir closes given directory node -- updates memory state
 func (fn *FileNode) CloseDir() {
 	fn.SetClosed()
@@ -1094,6 +1241,68 @@ func (fn *FileNode) UpdateRepoFiles() {
 	fn.RepoFiles, _ = fn.DirRepo.Files()
 }
 
+// UpdateRepoStatus sets the VCS status of all the files within the
+// repository based at this node, from its RepoFiles -- call
+// UpdateRepoFiles first to get the current status
+func (fn *FileNode) UpdateRepoStatus() {
+	repo := fn.DirRepo
+	if repo == nil {
+		return
+	}
+	fn.FuncDownMeFirst(0, fn, func(k ki.Ki, level int, d interface{}) bool {
+		sfn := k.Embed(KiT_FileNode).(*FileNode)
+		if sfn != fn && sfn.DirRepo != nil { // nested repository
+			return ki.Break
+		}
+		if !sfn.IsDir() {
+			vcs := fn.RepoFiles.Status(repo, string(sfn.FPath))
+			if vcs != sfn.Info.Vcs {
+				sfn.Info.Vcs = vcs
+				sfn.UpdateSig()
+			}
+		}
+		return ki.Continue
+	})
+}
+
+// VcsMetaDirs are the names of the directories where version control
+// systems keep their data, at the root of a repository
+var VcsMetaDirs = []string{".git", ".svn", ".hg", ".bzr"}
+
+// IsVcsMetaDir returns true if given file name is one of the VcsMetaDirs
+func IsVcsMetaDir(fnm string) bool {
+	for _, vd := range VcsMetaDirs {
+		if fnm == vd {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchDir adds this directory to the FileTree Watcher, if watching, along
+// with the VCS data directory if this is the root of a repository, so that
+// commits, checkouts etc update the VCS status of the files
+func (fn *FileNode) WatchDir() {
+	fw := fn.FRoot.Watcher
+	if fw == nil || fn.IsExternal() {
+		return
+	}
+	if err := fw.Add(string(fn.FPath)); err != nil {
+		log.Println(err)
+		return
+	}
+	if fn.DirRepo == nil {
+		return
+	}
+	for _, vd := range VcsMetaDirs {
+		vp := filepath.Join(string(fn.FPath), vd)
+		if info, err := os.Stat(vp); err == nil && info.IsDir() {
+			fw.Add(vp)
+			break
+		}
+	}
+}
+
 // AddToVcs adds file to version control
 func (fn *FileNode) AddToVcs() {
 	repo, _ := fn.Repo()
@@ -1189,6 +1398,17 @@ func (fn *FileNode) DiffVcs(rev_a, rev_b string) error {
 	return err
 }
 
+// MergeVcs opens a MergeViewDialog for resolving the merge conflicts in
+// this file, which must be in the Conflicted state.  The conflict markers
+// written by the VCS are parsed into our and their versions of each conflict.
+func (fn *FileNode) MergeVcs() error {
+	if fn.Info.Vcs != vci.Conflicted {
+		return errors.New("file does not have merge conflicts: " + string(fn.FPath))
+	}
+	_, err := MergeViewDialogFromFile(nil, string(fn.FPath))
+	return err
+}
+
 // LogVcs shows the VCS log of commits for this file, optionally with a
 // since date qualifier: If since is non-empty, it should be
 // a date-like expression that the VCS will understand, such as
@@ -1589,7 +1809,7 @@ func (ftv *FileTreeView) FileTreeViewEvents() {
 			tvv.Open()
 		}
 	})
-	if ftv.HasChildren() {
+	if ftv.HasBranch() {
 		if wb, ok := ftv.BranchPart(); ok {
 			wb.ButtonSig.ConnectOnly(ftv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				if sig == int64(gi.ButtonToggled) {
@@ -1835,6 +2055,16 @@ func (ftv *FileTreeView) SortBy(modTime bool) {
 	}
 }
 
+// FindInFiles opens a dialog for searching all the files in the tree for
+// given text, starting the search if find is non-empty
+func (ftv *FileTreeView) FindInFiles(find string) {
+	fn := ftv.FileNode()
+	if fn == nil || fn.FRoot == nil {
+		return
+	}
+	FileSearchViewDialog(ftv.ViewportSafe(), fn.FRoot, FileSearchParams{Find: find})
+}
+
 // NewFile makes a new file in given selected directory node
 func (ftv *FileTreeView) NewFile(filename string, addToVcs bool) {
 	sels := ftv.SelectedViews()
@@ -1951,6 +2181,24 @@ func (ftv *FileTreeView) DiffVcs(rev_a, rev_b string) {
 	}
 }
 
+// MergeVcs opens a MergeViewDialog for resolving the merge conflicts in
+// each selected file that is in the Conflicted state.
+func (ftv *FileTreeView) MergeVcs() {
+	sels := ftv.SelectedViews()
+	sz := len(sels)
+	if sz == 0 { // shouldn't happen
+		return
+	}
+	for i := len(sels) - 1; i >= 0; i-- {
+		sn := sels[i]
+		ftvv := sn.Embed(KiT_FileTreeView).(*FileTreeView)
+		fn := ftvv.FileNode()
+		if fn != nil && fn.Info.Vcs == vci.Conflicted {
+			fn.MergeVcs()
+		}
+	}
+}
+
 // LogVcs shows the VCS log of commits for this file, optionally with a
 // since date qualifier: If since is non-empty, it should be
 // a date-like expression that the VCS will understand, such as
@@ -2358,6 +2606,21 @@ var FileTreeActiveInVcsModifiedFunc = ActionUpdateFunc(func(fni interface{}, act
 	}
 })
 
+// FileTreeActiveInVcsConflictedFunc is an ActionUpdateFunc that activates action if node is under version control
+// and the file has merge conflicts
+var FileTreeActiveInVcsConflictedFunc = ActionUpdateFunc(func(fni interface{}, act *gi.Action) {
+	ftv := fni.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
+	fn := ftv.FileNode()
+	if fn != nil {
+		repo, _ := fn.Repo()
+		if repo == nil || fn.IsDir() {
+			act.SetActiveState((false))
+			return
+		}
+		act.SetActiveState((fn.Info.Vcs == vci.Conflicted))
+	}
+})
+
 // VcsGetRemoveLabelFunc gets the appropriate label for removing from version control
 var VcsLabelFunc = LabelFunc(func(fni interface{}, act *gi.Action) string {
 	ftv := fni.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
@@ -2482,6 +2745,15 @@ var FileTreeViewProps = ki.Props{
 				{"Modification Time", ki.Props{}},
 			},
 		}},
+		{"FindInFiles", ki.Props{
+			"label": "Find In Files...",
+			"desc":  "search all the files in the tree for given text, with optional replace -- respects file categories and ignore patterns",
+			"Args": ki.PropSlice{
+				{"Find", ki.Props{
+					"width": 60,
+				}},
+			},
+		}},
 		{"sep-new", ki.BlankProp{}},
 		{"NewFile", ki.Props{
 			"label":    "New File...",
@@ -2551,6 +2823,11 @@ var FileTreeViewProps = ki.Props{
 			"updtfunc":   FileTreeActiveInVcsFunc,
 			"label-func": VcsLabelFunc,
 		}},
+		{"MergeVcs", ki.Props{
+			"desc":       "opens a merge view for resolving the merge conflicts in this file, choosing our or their version of each conflict, or editing the result directly.",
+			"updtfunc":   FileTreeActiveInVcsConflictedFunc,
+			"label-func": VcsLabelFunc,
+		}},
 		{"sep-extrn", ki.BlankProp{}},
 		{"RemoveFromExterns", ki.Props{
 			"desc":       "Remove file from external files listt",
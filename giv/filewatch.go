@@ -0,0 +1,167 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/kit"
+)
+
+// FileWatchDebounceMSec is the number of milliseconds to wait after the last
+// change on disk before reporting a batch of changes -- bursts of changes,
+// e.g., from a build or a VCS checkout, are reported together
+var FileWatchDebounceMSec = 250
+
+// FileWatchOps are the kinds of changes on disk reported by a FileWatcher
+type FileWatchOps int32
+
+//go:generate stringer -type=FileWatchOps
+
+var KiT_FileWatchOps = kit.Enums.AddEnum(FileWatchOpsN, kit.BitFlag, nil)
+
+const (
+	// FileWatchCreate means a file or directory was created or moved in
+	FileWatchCreate FileWatchOps = iota
+
+	// FileWatchWrite means a file was written or its attributes changed
+	FileWatchWrite
+
+	// FileWatchRemove means a file or directory was removed
+	FileWatchRemove
+
+	// FileWatchRename means a file or directory was renamed or moved out
+	FileWatchRename
+
+	FileWatchOpsN
+)
+
+// FileWatchEvent is a change on disk to given path, which is either a
+// watched path or a file within a watched directory
+type FileWatchEvent struct {
+	Path string `desc:"full path of the file or directory that changed"`
+	Op   int64  `desc:"bit flags of the changes that were made -- see FileWatchOps"`
+}
+
+// HasOp returns true if the event includes given change
+func (ev *FileWatchEvent) HasOp(op FileWatchOps) bool {
+	return bitflag.Has(ev.Op, int(op))
+}
+
+// FileWatcher watches files and directories for changes on disk, using
+// inotify on linux and polling elsewhere.  Changes are collected until there
+// have been none for FileWatchDebounceMSec, and then passed to Func in a
+// separate goroutine, with one event per path.  Watching a directory reports
+// changes to the files immediately within it, not recursively.
+type FileWatcher struct {
+	Func    func(evs []FileWatchEvent) `desc:"function called with each batch of changes"`
+	mu      sync.Mutex
+	pending []FileWatchEvent
+	pendIdx map[string]int
+	timer   *time.Timer
+	closed  bool
+	fileWatchSys
+}
+
+// NewFileWatcher returns a new FileWatcher that calls fun with each batch of
+// changes -- use Add to add paths to watch, and Close when done
+func NewFileWatcher(fun func(evs []FileWatchEvent)) (*FileWatcher, error) {
+	fw := &FileWatcher{Func: fun}
+	err := fw.initSys()
+	if err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+// Add starts watching given file or directory -- does nothing if already
+// watched
+func (fw *FileWatcher) Add(path string) error {
+	path = filepath.Clean(path)
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.closed {
+		return nil
+	}
+	return fw.addSys(path)
+}
+
+// Remove stops watching given file or directory
+func (fw *FileWatcher) Remove(path string) {
+	path = filepath.Clean(path)
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.closed {
+		return
+	}
+	fw.removeSys(path)
+}
+
+// IsWatched returns true if given path is being watched
+func (fw *FileWatcher) IsWatched(path string) bool {
+	path = filepath.Clean(path)
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.isWatchedSys(path)
+}
+
+// Close stops watching all paths -- any pending changes are discarded
+func (fw *FileWatcher) Close() {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.closed {
+		return
+	}
+	fw.closed = true
+	if fw.timer != nil {
+		fw.timer.Stop()
+	}
+	fw.pending = nil
+	fw.closeSys()
+}
+
+// send records a change, merging it with any pending change to the same
+// path, and restarts the debounce timer
+func (fw *FileWatcher) send(path string, op FileWatchOps) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.closed {
+		return
+	}
+	if fw.pendIdx == nil {
+		fw.pendIdx = make(map[string]int)
+	}
+	if i, has := fw.pendIdx[path]; has {
+		bitflag.Set(&fw.pending[i].Op, int(op))
+	} else {
+		fw.pendIdx[path] = len(fw.pending)
+		ev := FileWatchEvent{Path: path}
+		bitflag.Set(&ev.Op, int(op))
+		fw.pending = append(fw.pending, ev)
+	}
+	dur := time.Duration(FileWatchDebounceMSec) * time.Millisecond
+	if fw.timer == nil {
+		fw.timer = time.AfterFunc(dur, fw.flush)
+	} else {
+		fw.timer.Reset(dur)
+	}
+}
+
+// flush passes the pending changes to Func
+func (fw *FileWatcher) flush() {
+	fw.mu.Lock()
+	evs := fw.pending
+	fw.pending = nil
+	fw.pendIdx = nil
+	closed := fw.closed
+	fw.mu.Unlock()
+	if closed || len(evs) == 0 || fw.Func == nil {
+		return
+	}
+	fw.Func(evs)
+}
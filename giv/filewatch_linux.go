@@ -0,0 +1,139 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+
+package giv
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+// fileWatchMask is the set of inotify events watched for each path
+const fileWatchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_ATTRIB | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// fileWatchSys is the inotify state for a FileWatcher
+type fileWatchSys struct {
+	file  *os.File
+	wds   map[string]int
+	paths map[int]string
+}
+
+func (fw *FileWatcher) initSys() error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return os.NewSyscallError("inotify_init1", err)
+	}
+	// a non-blocking file uses the runtime poller, so Close unblocks Read
+	fw.file = os.NewFile(uintptr(fd), "inotify")
+	fw.wds = make(map[string]int)
+	fw.paths = make(map[int]string)
+	go fw.readLoop()
+	return nil
+}
+
+func (fw *FileWatcher) addSys(path string) error {
+	if _, has := fw.wds[path]; has {
+		return nil
+	}
+	wd, err := syscall.InotifyAddWatch(int(fw.file.Fd()), path, fileWatchMask)
+	if err != nil {
+		return os.NewSyscallError("inotify_add_watch", err)
+	}
+	fw.wds[path] = wd
+	fw.paths[wd] = path
+	return nil
+}
+
+func (fw *FileWatcher) removeSys(path string) {
+	wd, has := fw.wds[path]
+	if !has {
+		return
+	}
+	delete(fw.wds, path)
+	delete(fw.paths, wd)
+	syscall.InotifyRmWatch(int(fw.file.Fd()), uint32(wd))
+}
+
+func (fw *FileWatcher) isWatchedSys(path string) bool {
+	_, has := fw.wds[path]
+	return has
+}
+
+func (fw *FileWatcher) closeSys() {
+	fw.file.Close()
+}
+
+// readLoop reads inotify events until the watcher is closed
+func (fw *FileWatcher) readLoop() {
+	var buf [syscall.SizeofInotifyEvent * 1024]byte
+	for {
+		n, err := fw.file.Read(buf[:])
+		if err != nil {
+			fw.mu.Lock()
+			closed := fw.closed
+			fw.mu.Unlock()
+			if !closed {
+				log.Println(err)
+			}
+			return
+		}
+		off := 0
+		for off+syscall.SizeofInotifyEvent <= n {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nmst := off + syscall.SizeofInotifyEvent
+			off = nmst + int(ev.Len)
+			name := string(bytes.TrimRight(buf[nmst:off], "\x00"))
+			fw.event(int(ev.Wd), ev.Mask, name)
+		}
+	}
+}
+
+// event processes one inotify event
+func (fw *FileWatcher) event(wd int, mask uint32, name string) {
+	fw.mu.Lock()
+	if mask&syscall.IN_Q_OVERFLOW != 0 { // events lost: report all as changed
+		paths := make([]string, 0, len(fw.wds))
+		for path := range fw.wds {
+			paths = append(paths, path)
+		}
+		fw.mu.Unlock()
+		for _, path := range paths {
+			fw.send(path, FileWatchCreate)
+		}
+		return
+	}
+	path, has := fw.paths[wd]
+	if mask&syscall.IN_IGNORED != 0 { // watch removed, e.g., path deleted
+		if has {
+			delete(fw.wds, path)
+			delete(fw.paths, wd)
+		}
+		fw.mu.Unlock()
+		return
+	}
+	fw.mu.Unlock()
+	if !has {
+		return
+	}
+	if name != "" {
+		path = filepath.Join(path, name)
+	}
+	switch {
+	case mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+		fw.send(path, FileWatchCreate)
+	case mask&(syscall.IN_DELETE|syscall.IN_DELETE_SELF) != 0:
+		fw.send(path, FileWatchRemove)
+	case mask&(syscall.IN_MOVED_FROM|syscall.IN_MOVE_SELF) != 0:
+		fw.send(path, FileWatchRename)
+	default:
+		fw.send(path, FileWatchWrite)
+	}
+}
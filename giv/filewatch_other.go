@@ -0,0 +1,149 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package giv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileWatchPollMSec is the number of milliseconds between checks for
+// changes on disk, on platforms where FileWatcher polls
+var FileWatchPollMSec = 1000
+
+// fileWatchStat is the state of a file as of the last poll
+type fileWatchStat struct {
+	mod  time.Time
+	size int64
+	dir  bool
+}
+
+// fileWatchSys is the polling state for a FileWatcher: for each watched
+// path, the state of the path itself (under "") and of each file within it
+type fileWatchSys struct {
+	snaps map[string]map[string]fileWatchStat
+	done  chan struct{}
+}
+
+func (fw *FileWatcher) initSys() error {
+	fw.snaps = make(map[string]map[string]fileWatchStat)
+	fw.done = make(chan struct{})
+	go fw.pollLoop()
+	return nil
+}
+
+func (fw *FileWatcher) addSys(path string) error {
+	if _, has := fw.snaps[path]; has {
+		return nil
+	}
+	snap, err := fileWatchSnap(path)
+	if err != nil {
+		return err
+	}
+	fw.snaps[path] = snap
+	return nil
+}
+
+func (fw *FileWatcher) removeSys(path string) {
+	delete(fw.snaps, path)
+}
+
+func (fw *FileWatcher) isWatchedSys(path string) bool {
+	_, has := fw.snaps[path]
+	return has
+}
+
+func (fw *FileWatcher) closeSys() {
+	close(fw.done)
+}
+
+// fileWatchSnap returns the current state of given path, and the files
+// within it if it is a directory
+func fileWatchSnap(path string) (map[string]fileWatchStat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	snap := map[string]fileWatchStat{"": {info.ModTime(), info.Size(), info.IsDir()}}
+	if !info.IsDir() {
+		return snap, nil
+	}
+	fis, err := ioutil.ReadDir(path)
+	if err != nil {
+		return snap, nil
+	}
+	for _, fi := range fis {
+		snap[fi.Name()] = fileWatchStat{fi.ModTime(), fi.Size(), fi.IsDir()}
+	}
+	return snap, nil
+}
+
+// pollLoop checks each watched path for changes until the watcher is closed
+func (fw *FileWatcher) pollLoop() {
+	tick := time.NewTicker(time.Duration(FileWatchPollMSec) * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case <-fw.done:
+			return
+		case <-tick.C:
+			fw.poll()
+		}
+	}
+}
+
+// poll compares the current state of each watched path to the last one
+func (fw *FileWatcher) poll() {
+	fw.mu.Lock()
+	paths := make([]string, 0, len(fw.snaps))
+	for path := range fw.snaps {
+		paths = append(paths, path)
+	}
+	fw.mu.Unlock()
+	for _, path := range paths {
+		snap, err := fileWatchSnap(path)
+		fw.mu.Lock()
+		prev, has := fw.snaps[path]
+		if has {
+			if err != nil {
+				delete(fw.snaps, path)
+			} else {
+				fw.snaps[path] = snap
+			}
+		}
+		fw.mu.Unlock()
+		if !has {
+			continue
+		}
+		if err != nil {
+			fw.send(path, FileWatchRemove)
+			continue
+		}
+		for nm, st := range snap {
+			if nm == "" {
+				continue
+			}
+			pst, had := prev[nm]
+			switch {
+			case !had:
+				fw.send(filepath.Join(path, nm), FileWatchCreate)
+			case pst != st:
+				fw.send(filepath.Join(path, nm), FileWatchWrite)
+			}
+		}
+		for nm := range prev {
+			if _, still := snap[nm]; !still {
+				fw.send(filepath.Join(path, nm), FileWatchRemove)
+			}
+		}
+		if st := snap[""]; !st.dir && st != prev[""] { // watched file itself
+			fw.send(path, FileWatchWrite)
+		}
+	}
+}
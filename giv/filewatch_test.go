@@ -0,0 +1,134 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fileWatchTestWait returns the next batch of events from evc, or nil after
+// a timeout
+func fileWatchTestWait(evc chan []FileWatchEvent, timeout time.Duration) []FileWatchEvent {
+	select {
+	case evs := <-evc:
+		return evs
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+// fileWatchTestEvent returns the event for given path, or nil if none
+func fileWatchTestEvent(evs []FileWatchEvent, path string) *FileWatchEvent {
+	for i := range evs {
+		if evs[i].Path == path {
+			return &evs[i]
+		}
+	}
+	return nil
+}
+
+func TestFileWatcherDebounce(t *testing.T) {
+	svdb := FileWatchDebounceMSec
+	FileWatchDebounceMSec = 20
+	defer func() { FileWatchDebounceMSec = svdb }()
+	evc := make(chan []FileWatchEvent, 10)
+	fw := &FileWatcher{Func: func(evs []FileWatchEvent) { evc <- evs }}
+	fw.send("a", FileWatchCreate)
+	fw.send("b", FileWatchRemove)
+	fw.send("a", FileWatchWrite)
+	evs := fileWatchTestWait(evc, 5*time.Second)
+	if len(evs) != 2 {
+		t.Fatalf("send: got events %v, expected 2 paths", evs)
+	}
+	if ev := fileWatchTestEvent(evs, "a"); ev == nil || !ev.HasOp(FileWatchCreate) || !ev.HasOp(FileWatchWrite) || ev.HasOp(FileWatchRemove) {
+		t.Errorf("send: path a event %v, expected create and write", ev)
+	}
+	if ev := fileWatchTestEvent(evs, "b"); ev == nil || !ev.HasOp(FileWatchRemove) {
+		t.Errorf("send: path b event %v, expected remove", ev)
+	}
+
+	// pending changes are discarded on Close
+	fw.send("c", FileWatchWrite)
+	fw.Close()
+	if evs := fileWatchTestWait(evc, 100*time.Millisecond); evs != nil {
+		t.Errorf("Close: got events %v after close", evs)
+	}
+}
+
+func TestFileWatcher(t *testing.T) {
+	svdb := FileWatchDebounceMSec
+	FileWatchDebounceMSec = 50
+	defer func() { FileWatchDebounceMSec = svdb }()
+	dir, err := ioutil.TempDir("", "filewatch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "file.txt")
+	if err := ioutil.WriteFile(fn, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	evc := make(chan []FileWatchEvent, 10)
+	fw, err := NewFileWatcher(func(evs []FileWatchEvent) { evc <- evs })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fw.Close()
+	if err := fw.Add(dir + string(filepath.Separator)); err != nil {
+		t.Fatal(err)
+	}
+	if !fw.IsWatched(dir) || fw.IsWatched(fn) {
+		t.Errorf("IsWatched: dir %v, file %v, expected true, false", fw.IsWatched(dir), fw.IsWatched(fn))
+	}
+
+	// mod times must differ for polling to see the write, at 1 sec resolution
+	time.Sleep(1100 * time.Millisecond)
+	if err := ioutil.WriteFile(fn, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nfn := filepath.Join(dir, "new.txt")
+	if err := ioutil.WriteFile(nfn, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var evs []FileWatchEvent
+	for len(evs) < 2 {
+		bevs := fileWatchTestWait(evc, 5*time.Second)
+		if bevs == nil {
+			break
+		}
+		evs = append(evs, bevs...)
+	}
+	if ev := fileWatchTestEvent(evs, fn); ev == nil || !ev.HasOp(FileWatchWrite) {
+		t.Errorf("write: got events %v, expected write to %v", evs, fn)
+	}
+	if ev := fileWatchTestEvent(evs, nfn); ev == nil || !ev.HasOp(FileWatchCreate) {
+		t.Errorf("create: got events %v, expected create of %v", evs, nfn)
+	}
+
+	if err := os.Remove(nfn); err != nil {
+		t.Fatal(err)
+	}
+	evs = fileWatchTestWait(evc, 5*time.Second)
+	if ev := fileWatchTestEvent(evs, nfn); ev == nil || !ev.HasOp(FileWatchRemove) {
+		t.Errorf("remove: got events %v, expected remove of %v", evs, nfn)
+	}
+
+	// no more changes are reported once the directory is not watched
+	fw.Remove(dir)
+	if fw.IsWatched(dir) {
+		t.Errorf("Remove: dir is still watched")
+	}
+	if err := ioutil.WriteFile(nfn, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if evs := fileWatchTestWait(evc, 300*time.Millisecond); evs != nil {
+		t.Errorf("Remove: got events %v after remove", evs)
+	}
+}
@@ -23,6 +23,7 @@ type GiEditor struct {
 	KiRoot   ki.Ki       `desc:"root of tree being edited"`
 	Changed  bool        `desc:"has the root changed via gui actions?  updated from treeview and structview for changes"`
 	Filename gi.FileName `desc:"current filename for saving / loading"`
+	VirtTree bool        `desc:"view the tree with a VirtTreeView, which only renders the rows in view, for very large trees -- it is select-only: nodes can be viewed and edited in the StructView, but the tree itself cannot be edited (no insert, delete, duplicate, cut / paste, drag-and-drop or undo of tree changes).  Set before SetRoot."`
 }
 
 var KiT_GiEditor = kit.Types.AddType(&GiEditor{}, GiEditorProps)
@@ -40,6 +41,17 @@ func (ge *GiEditor) Update() {
 	ge.KiRoot.UpdateSig()
 }
 
+// Undo undoes the last edit made in the editor (or elsewhere in its window)
+// -- see ValueUndo
+func (ge *GiEditor) Undo() {
+	ValueUndoFor(ge.This()).Undo()
+}
+
+// Redo redoes the last undone edit -- see ValueUndo
+func (ge *GiEditor) Redo() {
+	ValueUndoFor(ge.This()).Redo()
+}
+
 // Save saves tree to current filename, in a standard JSON-formatted file
 func (ge *GiEditor) Save() {
 	if ge.KiRoot == nil {
@@ -138,9 +150,17 @@ func (ge *GiEditor) SplitView() *gi.SplitView {
 	return ge.ChildByName("splitview", 2).(*gi.SplitView)
 }
 
-// TreeView returns the main TreeView
+// TreeView returns the main TreeView -- nil if a VirtTreeView is used
 func (ge *GiEditor) TreeView() *TreeView {
-	return ge.SplitView().Child(0).Child(0).(*TreeView)
+	tv, _ := ge.SplitView().Child(0).Child(0).(*TreeView)
+	return tv
+}
+
+// VirtTreeView returns the main VirtTreeView, used instead of the TreeView
+// if VirtTree is set -- nil otherwise
+func (ge *GiEditor) VirtTreeView() *VirtTreeView {
+	vtv, _ := ge.SplitView().Child(0).Child(0).(*VirtTreeView)
+	return vtv
 }
 
 // StructView returns the main StructView
@@ -173,30 +193,57 @@ func (ge *GiEditor) ConfigSplitView() {
 	split.Dim = mat32.X
 
 	if len(split.Kids) == 0 {
-		tvfr := gi.AddNewFrame(split, "tvfr", gi.LayoutHoriz)
-		tv := AddNewTreeView(tvfr, "tv")
+		gi.AddNewFrame(split, "tvfr", gi.LayoutHoriz)
 		sv := AddNewStructView(split, "sv")
-		tv.TreeViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-			if data == nil {
-				return
-			}
-			gee, _ := recv.Embed(KiT_GiEditor).(*GiEditor)
-			svr := gee.StructView()
-			tvn, _ := data.(ki.Ki).Embed(KiT_TreeView).(*TreeView)
-			if sig == int64(TreeViewSelected) {
-				svr.SetStruct(tvn.SrcNode)
-			} else if sig == int64(TreeViewChanged) {
-				gee.SetChanged()
-			}
-		})
 		sv.ViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			gee, _ := recv.Embed(KiT_GiEditor).(*GiEditor)
 			gee.SetChanged()
 		})
 		split.SetSplits(.3, .7)
 	}
-	tv := ge.TreeView()
-	tv.SetRootNode(ge.KiRoot)
+	tvfr := split.Child(0).(*gi.Frame)
+	virt := ge.VirtTree
+	tconfig := kit.TypeAndNameList{}
+	if virt {
+		tconfig.Add(KiT_VirtTreeView, "vtv")
+	} else {
+		tconfig.Add(KiT_TreeView, "tv")
+	}
+	mods, updt := tvfr.ConfigChildren(tconfig, ki.UniqueNames)
+	if virt {
+		vtv := ge.VirtTreeView()
+		if mods {
+			vtv.TreeViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if data == nil || sig != int64(TreeViewSelected) {
+					return
+				}
+				gee, _ := recv.Embed(KiT_GiEditor).(*GiEditor)
+				gee.StructView().SetStruct(data.(ki.Ki))
+			})
+		}
+		vtv.SetRootNode(ge.KiRoot)
+	} else {
+		tv := ge.TreeView()
+		if mods {
+			tv.TreeViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if data == nil {
+					return
+				}
+				gee, _ := recv.Embed(KiT_GiEditor).(*GiEditor)
+				svr := gee.StructView()
+				tvn, _ := data.(ki.Ki).Embed(KiT_TreeView).(*TreeView)
+				if sig == int64(TreeViewSelected) {
+					svr.SetStruct(tvn.SrcNode)
+				} else if sig == int64(TreeViewChanged) {
+					gee.SetChanged()
+				}
+			})
+		}
+		tv.SetRootNode(ge.KiRoot)
+	}
+	if mods {
+		tvfr.UpdateEnd(updt)
+	}
 	sv := ge.StructView()
 	sv.SetStruct(ge.KiRoot)
 }
@@ -235,6 +282,23 @@ var GiEditorProps = ki.Props{
 				act.SetActiveStateUpdt(ge.Changed)
 			}),
 		}},
+		{"sep-undo", ki.BlankProp{}},
+		{"Undo", ki.Props{
+			"icon": "rotate-left",
+			"desc": "undo the last edit of values or tree structure",
+			"updtfunc": ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+				ge := gei.(*GiEditor)
+				act.SetActiveStateUpdt(ValueUndoFor(ge.This()).CanUndo())
+			}),
+		}},
+		{"Redo", ki.Props{
+			"icon": "rotate-right",
+			"desc": "redo the last undone edit",
+			"updtfunc": ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+				ge := gei.(*GiEditor)
+				act.SetActiveStateUpdt(ValueUndoFor(ge.This()).CanRedo())
+			}),
+		}},
 		{"sep-file", ki.BlankProp{}},
 		{"Open", ki.Props{
 			"label": "Open",
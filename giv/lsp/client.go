@@ -0,0 +1,296 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ShutdownTimeout is the time Shutdown waits for the server process to exit
+// before killing it
+var ShutdownTimeout = 2 * time.Second
+
+// DiagnosticsFunc is called with the current diagnostics for a document,
+// in the goroutine reading from the server
+type DiagnosticsFunc func(diags []Diagnostic)
+
+// Client is a language server protocol client, talking to a language
+// server over its stdin and stdout (see Start), or over any reader and
+// writer (see NewClient).  Documents are synchronized by sending their
+// full text on each change.
+type Client struct {
+	Cmd      *exec.Cmd                  `desc:"language server process, if started with Start"`
+	Conn     *Conn                      `desc:"json-rpc connection to server"`
+	RootURI  string                     `desc:"URI of the root directory of the workspace"`
+	Caps     ServerCapabilities         `desc:"capabilities of the server, from initialize"`
+	diagFuns map[string]DiagnosticsFunc // diagnostics functions by document URI
+	mu       sync.Mutex
+}
+
+// Start starts given language server command in rootDir (used as the
+// workspace root), and initializes it
+func Start(rootDir, name string, args ...string) (*Client, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = rootDir
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	cl := NewClient(out, in)
+	cl.Cmd = cmd
+	if err = cl.Initialize(rootDir); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	return cl, nil
+}
+
+// NewClient returns a new client reading responses from r and writing
+// requests to w -- Initialize must be called before use
+func NewClient(r io.Reader, w io.Writer) *Client {
+	cl := &Client{}
+	cl.diagFuns = make(map[string]DiagnosticsFunc)
+	cl.Conn = NewConn(r, w, cl.handle)
+	return cl
+}
+
+// Initialize sends the initialize request and initialized notification,
+// recording the server capabilities
+func (cl *Client) Initialize(rootDir string) error {
+	cl.RootURI = FileURI(rootDir)
+	caps := map[string]interface{}{
+		"textDocument": map[string]interface{}{
+			"synchronization": map[string]interface{}{"didSave": true},
+			"completion": map[string]interface{}{
+				"completionItem": map[string]interface{}{"snippetSupport": false},
+			},
+			"hover": map[string]interface{}{
+				"contentFormat": []string{"plaintext", "markdown"},
+			},
+			"definition":         map[string]interface{}{"linkSupport": true},
+			"publishDiagnostics": map[string]interface{}{},
+			"semanticTokens": map[string]interface{}{
+				"requests":       map[string]interface{}{"full": true},
+				"tokenTypes":     SemanticTokenTypes,
+				"tokenModifiers": SemanticTokenModifiers,
+				"formats":        []string{"relative"},
+			},
+		},
+	}
+	prm := &InitializeParams{ProcessID: os.Getpid(), RootURI: cl.RootURI, Capabilities: caps}
+	res := &InitializeResult{}
+	if err := cl.Conn.Call("initialize", prm, res); err != nil {
+		log.Println(err)
+		return err
+	}
+	cl.Caps = res.Capabilities
+	return cl.Conn.Notify("initialized", struct{}{})
+}
+
+// HasCompletion returns true if the server provides completion
+func (cl *Client) HasCompletion() bool {
+	return providerOn(cl.Caps.CompletionProvider)
+}
+
+// HasHover returns true if the server provides hover info
+func (cl *Client) HasHover() bool {
+	return providerOn(cl.Caps.HoverProvider)
+}
+
+// HasDefinition returns true if the server provides go-to-definition
+func (cl *Client) HasDefinition() bool {
+	return providerOn(cl.Caps.DefinitionProvider)
+}
+
+// HasSemanticTokens returns true if the server provides full-document
+// semantic tokens
+func (cl *Client) HasSemanticTokens() bool {
+	return cl.Caps.SemanticTokensProvider != nil && providerOn(cl.Caps.SemanticTokensProvider.Full)
+}
+
+// DidOpen tells the server that given document has been opened with given
+// text -- diagFun, if non-nil, is called with diagnostics for the document
+func (cl *Client) DidOpen(uri, langID string, version int, text string, diagFun DiagnosticsFunc) error {
+	cl.mu.Lock()
+	if diagFun != nil {
+		cl.diagFuns[uri] = diagFun
+	} else {
+		delete(cl.diagFuns, uri)
+	}
+	cl.mu.Unlock()
+	prm := &DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, LanguageID: langID, Version: version, Text: text}}
+	return cl.Conn.Notify("textDocument/didOpen", prm)
+}
+
+// DidChange sends the new full text of given document to the server
+func (cl *Client) DidChange(uri string, version int, text string) error {
+	prm := &DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: version},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: text}},
+	}
+	return cl.Conn.Notify("textDocument/didChange", prm)
+}
+
+// DidSave tells the server that given document has been saved
+func (cl *Client) DidSave(uri string) error {
+	prm := &DidSaveTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}
+	return cl.Conn.Notify("textDocument/didSave", prm)
+}
+
+// DidClose tells the server that given document has been closed
+func (cl *Client) DidClose(uri string) error {
+	cl.mu.Lock()
+	delete(cl.diagFuns, uri)
+	cl.mu.Unlock()
+	prm := &DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}
+	return cl.Conn.Notify("textDocument/didClose", prm)
+}
+
+// posParams returns the params for a request at a position
+func posParams(uri string, pos Position) *TextDocumentPositionParams {
+	return &TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos}
+}
+
+// Completion returns the completions at given position in document
+func (cl *Client) Completion(uri string, pos Position) ([]CompletionItem, error) {
+	var raw json.RawMessage
+	if err := cl.Conn.Call("textDocument/completion", posParams(uri, pos), &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if raw[0] == '[' {
+		var items []CompletionItem
+		err := json.Unmarshal(raw, &items)
+		return items, err
+	}
+	lst := &CompletionList{}
+	err := json.Unmarshal(raw, lst)
+	return lst.Items, err
+}
+
+// Hover returns the hover text at given position in document -- empty if none
+func (cl *Client) Hover(uri string, pos Position) (string, error) {
+	hv := &Hover{}
+	if err := cl.Conn.Call("textDocument/hover", posParams(uri, pos), hv); err != nil {
+		return "", err
+	}
+	return hv.Text(), nil
+}
+
+// Definition returns the location(s) of the definition of the symbol at
+// given position in document
+func (cl *Client) Definition(uri string, pos Position) ([]Location, error) {
+	var raw json.RawMessage
+	if err := cl.Conn.Call("textDocument/definition", posParams(uri, pos), &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if raw[0] == '{' {
+		loc := Location{}
+		err := json.Unmarshal(raw, &loc)
+		return []Location{loc}, err
+	}
+	var items []struct {
+		Location
+		LocationLink
+	}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	locs := make([]Location, len(items))
+	for i, it := range items {
+		if it.TargetURI != "" {
+			locs[i] = Location{URI: it.TargetURI, Range: it.TargetSelectionRange}
+		} else {
+			locs[i] = it.Location
+		}
+	}
+	return locs, nil
+}
+
+// SemanticTokens returns the decoded semantic tokens for the full document
+// -- nil if the server does not provide them
+func (cl *Client) SemanticTokens(uri string) ([]SemanticToken, error) {
+	if !cl.HasSemanticTokens() {
+		return nil, nil
+	}
+	prm := &SemanticTokensParams{TextDocument: TextDocumentIdentifier{URI: uri}}
+	st := &SemanticTokens{}
+	if err := cl.Conn.Call("textDocument/semanticTokens/full", prm, st); err != nil {
+		return nil, err
+	}
+	return DecodeSemanticTokens(st.Data, &cl.Caps.SemanticTokensProvider.Legend), nil
+}
+
+// Shutdown shuts down the server, and waits for its process to exit if
+// it was started with Start -- if it does not exit within ShutdownTimeout,
+// it is killed
+func (cl *Client) Shutdown() error {
+	err := cl.Conn.Call("shutdown", nil, nil)
+	cl.Conn.Notify("exit", nil)
+	if cl.Cmd != nil {
+		// the server closes its stdout when it exits, which ends reading --
+		// Wait closes the pipe, so it must only be called after that
+		select {
+		case <-cl.Conn.Done():
+		case <-time.After(ShutdownTimeout):
+			cl.Cmd.Process.Kill()
+			select {
+			case <-cl.Conn.Done():
+			case <-time.After(ShutdownTimeout): // e.g., child processes holding stdout
+			}
+		}
+		cl.Cmd.Wait()
+	}
+	cl.Conn.Close()
+	return err
+}
+
+// handle handles notifications and requests from the server
+func (cl *Client) handle(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "textDocument/publishDiagnostics":
+		prm := &PublishDiagnosticsParams{}
+		if err := json.Unmarshal(params, prm); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		cl.mu.Lock()
+		df := cl.diagFuns[prm.URI]
+		cl.mu.Unlock()
+		if df != nil {
+			df(prm.Diagnostics)
+		}
+	case "workspace/configuration":
+		var prm struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		json.Unmarshal(params, &prm)
+		return make([]interface{}, len(prm.Items)), nil
+	}
+	return nil, nil
+}
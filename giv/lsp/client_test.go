@@ -0,0 +1,138 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// stubServer is a minimal language server for testing the Client, reading
+// and writing the Content-Length framed messages itself
+type stubServer struct {
+	rd      *bufio.Reader
+	wr      io.WriteCloser
+	methods chan string
+	params  map[string]json.RawMessage
+}
+
+// read reads the next message from the client
+func (ss *stubServer) read() (*message, error) {
+	hdr, err := textproto.NewReader(ss.rd).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	sz, err := strconv.Atoi(hdr.Get("Content-Length"))
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, sz)
+	if _, err := io.ReadFull(ss.rd, b); err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	return msg, json.Unmarshal(b, msg)
+}
+
+// write writes a message to the client, split across two writes and with
+// an extra header, as allowed by the protocol
+func (ss *stubServer) write(msg interface{}) {
+	b, _ := json.Marshal(msg)
+	hdr := fmt.Sprintf("Content-Length: %d\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n", len(b))
+	ss.wr.Write([]byte(hdr + string(b[:len(b)/2])))
+	ss.wr.Write(b[len(b)/2:])
+}
+
+// serve handles messages until exit
+func (ss *stubServer) serve() {
+	defer ss.wr.Close()
+	for {
+		msg, err := ss.read()
+		if err != nil {
+			return
+		}
+		ss.params[msg.Method] = msg.Params
+		switch msg.Method {
+		case "initialize":
+			ss.write(map[string]interface{}{"jsonrpc": "2.0", "id": msg.ID, "result": map[string]interface{}{
+				"capabilities": map[string]interface{}{"hoverProvider": true},
+			}})
+		case "textDocument/didOpen":
+			var prm DidOpenTextDocumentParams
+			json.Unmarshal(msg.Params, &prm)
+			ss.write(map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/publishDiagnostics", "params": &PublishDiagnosticsParams{
+				URI:         prm.TextDocument.URI,
+				Diagnostics: []Diagnostic{{Range: Range{Start: Position{Line: 1}, End: Position{Line: 1, Character: 3}}, Severity: SeverityWarning, Message: "unused"}},
+			}})
+		case "shutdown":
+			ss.write(map[string]interface{}{"jsonrpc": "2.0", "id": msg.ID, "result": nil})
+		}
+		ss.methods <- msg.Method
+		if msg.Method == "exit" {
+			return
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	crd, swr := io.Pipe()
+	srd, cwr := io.Pipe()
+	ss := &stubServer{rd: bufio.NewReader(srd), wr: swr, methods: make(chan string, 10), params: make(map[string]json.RawMessage)}
+	go ss.serve()
+	cl := NewClient(crd, cwr)
+	if err := cl.Initialize("/tmp"); err != nil {
+		t.Fatal(err)
+	}
+	if !cl.HasHover() || cl.HasCompletion() {
+		t.Errorf("capabilities not recorded: %+v", cl.Caps)
+	}
+	for _, want := range []string{"initialize", "initialized"} {
+		if got := <-ss.methods; got != want {
+			t.Errorf("server got: %v, expected: %v", got, want)
+		}
+	}
+	var caps struct {
+		Capabilities struct {
+			TextDocument struct {
+				SemanticTokens SemanticTokensLegend `json:"semanticTokens"`
+			} `json:"textDocument"`
+		} `json:"capabilities"`
+	}
+	json.Unmarshal(ss.params["initialize"], &caps)
+	if len(caps.Capabilities.TextDocument.SemanticTokens.TokenTypes) == 0 || len(caps.Capabilities.TextDocument.SemanticTokens.TokenModifiers) == 0 {
+		t.Errorf("initialize did not advertise semantic token types and modifiers")
+	}
+
+	diags := make(chan []Diagnostic, 1)
+	err := cl.DidOpen("file:///tmp/a.go", "go", 1, "package a\nvar x int\n", func(dgs []Diagnostic) {
+		diags <- dgs
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case dgs := <-diags:
+		if len(dgs) != 1 || dgs[0].Range.Start.Line != 1 || dgs[0].Severity != SeverityWarning || dgs[0].Message != "unused" {
+			t.Errorf("wrong diagnostics: %+v", dgs)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no diagnostics")
+	}
+
+	if err := cl.Shutdown(); err != nil {
+		t.Error(err)
+	}
+	select {
+	case <-cl.Conn.Done():
+	default:
+		t.Error("connection not closed after Shutdown")
+	}
+}
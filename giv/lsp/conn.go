@@ -0,0 +1,286 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is the default time to wait for a response to a request
+var DefaultTimeout = 5 * time.Second
+
+// ErrClosed is returned for requests on a closed connection
+var ErrClosed = errors.New("lsp: connection closed")
+
+// ErrTimeout is returned when a request does not get a response in time
+var ErrTimeout = errors.New("lsp: timeout waiting for response")
+
+// RespError is a JSON-RPC error response
+type RespError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (re *RespError) Error() string {
+	return fmt.Sprintf("lsp: error %d: %s", re.Code, re.Message)
+}
+
+// JSON-RPC error codes
+const (
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInternal       = -32603
+)
+
+// message is a JSON-RPC 2.0 request, notification or response
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *RespError       `json:"error,omitempty"`
+}
+
+// response is the result of a call
+type response struct {
+	result json.RawMessage
+	err    error
+}
+
+// HandlerFunc handles a request or notification from the server -- for
+// notifications the result is ignored
+type HandlerFunc func(method string, params json.RawMessage) (result interface{}, err error)
+
+// Conn is a JSON-RPC 2.0 connection using the base protocol of the
+// language server protocol, where each message has a Content-Length
+// header.  Requests from the server and notifications are passed to the
+// Handler, in order, in the goroutine reading from the connection.
+type Conn struct {
+	Handler HandlerFunc   `desc:"handler for requests and notifications from the server"`
+	Timeout time.Duration `desc:"time to wait for a response to a request -- DefaultTimeout if 0"`
+	rd      *bufio.Reader
+	wr      io.Writer
+	wmu     sync.Mutex
+	mu      sync.Mutex
+	seq     int64
+	pending map[int64]chan response
+	closed  bool
+	done    chan struct{}
+}
+
+// NewConn returns a new connection reading from r and writing to w, and
+// starts reading in a separate goroutine
+func NewConn(r io.Reader, w io.Writer, handler HandlerFunc) *Conn {
+	cn := &Conn{Handler: handler, rd: bufio.NewReader(r), wr: w}
+	cn.pending = make(map[int64]chan response)
+	cn.done = make(chan struct{})
+	go cn.readLoop()
+	return cn
+}
+
+// Done returns a channel that is closed when the connection is closed
+func (cn *Conn) Done() <-chan struct{} {
+	return cn.done
+}
+
+// Call sends a request with given method and params, and waits for the
+// response, which is decoded into result (if non-nil)
+func (cn *Conn) Call(method string, params, result interface{}) error {
+	cn.mu.Lock()
+	if cn.closed {
+		cn.mu.Unlock()
+		return ErrClosed
+	}
+	cn.seq++
+	id := cn.seq
+	rch := make(chan response, 1)
+	cn.pending[id] = rch
+	cn.mu.Unlock()
+
+	rid := json.RawMessage(strconv.FormatInt(id, 10))
+	err := cn.send(&message{ID: &rid, Method: method, Params: marshalParams(params)})
+	if err != nil {
+		cn.forget(id)
+		return err
+	}
+	tout := cn.Timeout
+	if tout == 0 {
+		tout = DefaultTimeout
+	}
+	tmr := time.NewTimer(tout)
+	defer tmr.Stop()
+	select {
+	case rs := <-rch:
+		if rs.err != nil {
+			return rs.err
+		}
+		if result != nil && len(rs.result) > 0 {
+			return json.Unmarshal(rs.result, result)
+		}
+		return nil
+	case <-tmr.C:
+		cn.forget(id)
+		return ErrTimeout
+	}
+}
+
+// Notify sends a notification with given method and params
+func (cn *Conn) Notify(method string, params interface{}) error {
+	cn.mu.Lock()
+	closed := cn.closed
+	cn.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+	return cn.send(&message{Method: method, Params: marshalParams(params)})
+}
+
+// forget removes a pending request
+func (cn *Conn) forget(id int64) {
+	cn.mu.Lock()
+	delete(cn.pending, id)
+	cn.mu.Unlock()
+}
+
+// marshalParams marshals params to json -- nil for nil
+func marshalParams(params interface{}) json.RawMessage {
+	if params == nil {
+		return nil
+	}
+	b, err := json.Marshal(params)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return b
+}
+
+// send writes the message with its header
+func (cn *Conn) send(msg *message) error {
+	msg.JSONRPC = "2.0"
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	cn.wmu.Lock()
+	defer cn.wmu.Unlock()
+	if _, err = fmt.Fprintf(cn.wr, "Content-Length: %d\r\n\r\n", len(b)); err != nil {
+		return err
+	}
+	_, err = cn.wr.Write(b)
+	return err
+}
+
+// read reads the next message
+func (cn *Conn) read() (*message, error) {
+	hdr, err := textproto.NewReader(cn.rd).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	sz, err := strconv.Atoi(strings.TrimSpace(hdr.Get("Content-Length")))
+	if err != nil {
+		return nil, fmt.Errorf("lsp: invalid Content-Length header: %v", err)
+	}
+	b := make([]byte, sz)
+	if _, err = io.ReadFull(cn.rd, b); err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	if err = json.Unmarshal(b, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// readLoop reads and dispatches messages until the connection is closed
+func (cn *Conn) readLoop() {
+	for {
+		msg, err := cn.read()
+		if err != nil {
+			if err != io.EOF {
+				log.Println(err)
+			}
+			cn.Close()
+			return
+		}
+		switch {
+		case msg.Method != "" && msg.ID != nil: // request from server
+			cn.handleRequest(msg)
+		case msg.Method != "": // notification
+			if cn.Handler != nil {
+				cn.Handler(msg.Method, msg.Params)
+			}
+		case msg.ID != nil: // response
+			id, err := strconv.ParseInt(string(*msg.ID), 10, 64)
+			if err != nil {
+				continue
+			}
+			cn.mu.Lock()
+			rch, has := cn.pending[id]
+			delete(cn.pending, id)
+			cn.mu.Unlock()
+			if !has {
+				continue
+			}
+			if msg.Error != nil {
+				rch <- response{err: msg.Error}
+			} else {
+				rch <- response{result: msg.Result}
+			}
+		}
+	}
+}
+
+// handleRequest calls the Handler for a request from the server and
+// sends the response
+func (cn *Conn) handleRequest(msg *message) {
+	rsp := &message{ID: msg.ID}
+	if cn.Handler == nil {
+		rsp.Error = &RespError{Code: ErrCodeMethodNotFound, Message: "method not found: " + msg.Method}
+	} else {
+		res, err := cn.Handler(msg.Method, msg.Params)
+		switch {
+		case err != nil:
+			re, ok := err.(*RespError)
+			if !ok {
+				re = &RespError{Code: ErrCodeInternal, Message: err.Error()}
+			}
+			rsp.Error = re
+		case res == nil:
+			rsp.Result = json.RawMessage("null")
+		default:
+			rsp.Result = marshalParams(res)
+		}
+	}
+	// sending from another goroutine keeps reading, so both sides can
+	// never be blocked writing to each other
+	go cn.send(rsp)
+}
+
+// Close closes the connection: all pending requests return ErrClosed
+func (cn *Conn) Close() {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+	if cn.closed {
+		return
+	}
+	cn.closed = true
+	for id, rch := range cn.pending {
+		rch <- response{err: ErrClosed}
+		delete(cn.pending, id)
+	}
+	close(cn.done)
+}
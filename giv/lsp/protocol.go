@@ -0,0 +1,403 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// This file has the subset of the language server protocol types used by
+// the Client -- see https://microsoft.github.io/language-server-protocol
+// for the full specification.
+
+// Position is a zero-based line and character offset in a document --
+// the character offset is in UTF-16 code units, per the protocol --
+// see UTF16Col and RuneCol for conversion
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range in a document -- the End is exclusive
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a document identified by URI
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// LocationLink is an alternative form of Location returned by some servers
+type LocationLink struct {
+	TargetURI            string `json:"targetUri"`
+	TargetRange          Range  `json:"targetRange"`
+	TargetSelectionRange Range  `json:"targetSelectionRange"`
+}
+
+// TextDocumentIdentifier identifies a document by URI
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// VersionedTextDocumentIdentifier identifies a specific version of a document
+type VersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+// TextDocumentItem is a document sent to the server when opened
+type TextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+// TextDocumentPositionParams are the params for requests at a position
+// in a document: completion, hover, definition
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// DidOpenTextDocumentParams are the params for textDocument/didOpen
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+// TextDocumentContentChangeEvent is a change to a document -- we always
+// send the full text, which all servers must accept
+type TextDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+// DidChangeTextDocumentParams are the params for textDocument/didChange
+type DidChangeTextDocumentParams struct {
+	TextDocument   VersionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+// DidSaveTextDocumentParams are the params for textDocument/didSave
+type DidSaveTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Text         *string                `json:"text,omitempty"`
+}
+
+// DidCloseTextDocumentParams are the params for textDocument/didClose
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// TextEdit is an edit of a range in a document
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// CompletionItemKind is the kind of a completion item
+type CompletionItemKind int
+
+// CompletionItemKind values from the protocol
+const (
+	CompletionText CompletionItemKind = iota + 1
+	CompletionMethod
+	CompletionFunction
+	CompletionConstructor
+	CompletionField
+	CompletionVariable
+	CompletionClass
+	CompletionInterface
+	CompletionModule
+	CompletionProperty
+	CompletionUnit
+	CompletionValue
+	CompletionEnum
+	CompletionKeyword
+	CompletionSnippet
+	CompletionColor
+	CompletionFile
+	CompletionReference
+	CompletionFolder
+	CompletionEnumMember
+	CompletionConstant
+	CompletionStruct
+	CompletionEvent
+	CompletionOperator
+	CompletionTypeParameter
+)
+
+// CompletionItem is one completion
+type CompletionItem struct {
+	Label         string             `json:"label"`
+	Kind          CompletionItemKind `json:"kind,omitempty"`
+	Detail        string             `json:"detail,omitempty"`
+	Documentation json.RawMessage    `json:"documentation,omitempty"`
+	SortText      string             `json:"sortText,omitempty"`
+	FilterText    string             `json:"filterText,omitempty"`
+	InsertText    string             `json:"insertText,omitempty"`
+	TextEdit      *TextEdit          `json:"textEdit,omitempty"`
+}
+
+// CompletionList is a list of completions
+type CompletionList struct {
+	IsIncomplete bool             `json:"isIncomplete"`
+	Items        []CompletionItem `json:"items"`
+}
+
+// DiagnosticSeverity is the severity of a Diagnostic
+type DiagnosticSeverity int
+
+// DiagnosticSeverity values from the protocol
+const (
+	SeverityError DiagnosticSeverity = iota + 1
+	SeverityWarning
+	SeverityInformation
+	SeverityHint
+)
+
+// Diagnostic is an error, warning etc for a range of a document
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity,omitempty"`
+	Code     json.RawMessage    `json:"code,omitempty"`
+	Source   string             `json:"source,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// String returns the diagnostic as source: message
+func (dg *Diagnostic) String() string {
+	if dg.Source != "" {
+		return dg.Source + ": " + dg.Message
+	}
+	return dg.Message
+}
+
+// PublishDiagnosticsParams are the params for the
+// textDocument/publishDiagnostics notification from the server
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     *int         `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// MarkupContent is formatted text, in plaintext or markdown
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Hover is the result of a textDocument/hover request
+type Hover struct {
+	Contents json.RawMessage `json:"contents"`
+	Range    *Range          `json:"range,omitempty"`
+}
+
+// Text returns the plain text of the hover contents, which can be
+// a string, a MarkedString, a list of those, or MarkupContent
+func (hv *Hover) Text() string {
+	return MarkupText(hv.Contents)
+}
+
+// MarkupText returns the text from given raw json value, which can be
+// a string, a MarkedString ({language, value}), MarkupContent ({kind,
+// value}), or a list of any of these, which are joined with blank lines
+func MarkupText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var mc struct {
+		Value string `json:"value"`
+	}
+	if raw[0] == '{' && json.Unmarshal(raw, &mc) == nil {
+		return mc.Value
+	}
+	var lst []json.RawMessage
+	if json.Unmarshal(raw, &lst) == nil {
+		strs := make([]string, 0, len(lst))
+		for _, it := range lst {
+			if s := MarkupText(it); s != "" {
+				strs = append(strs, s)
+			}
+		}
+		return strings.Join(strs, "\n\n")
+	}
+	return ""
+}
+
+// SemanticTokensParams are the params for textDocument/semanticTokens/full
+type SemanticTokensParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// SemanticTokens are the encoded semantic tokens for a document -- see
+// DecodeSemanticTokens
+type SemanticTokens struct {
+	ResultID string   `json:"resultId,omitempty"`
+	Data     []uint32 `json:"data"`
+}
+
+// SemanticTokenTypes are the standard semantic token types of the protocol,
+// which the Client advertises as supported
+var SemanticTokenTypes = []string{"namespace", "type", "class", "enum", "interface", "struct", "typeParameter", "parameter", "variable", "property", "enumMember", "event", "function", "method", "macro", "keyword", "modifier", "comment", "string", "number", "regexp", "operator", "decorator", "label"}
+
+// SemanticTokenModifiers are the standard semantic token modifiers of the
+// protocol, which the Client advertises as supported
+var SemanticTokenModifiers = []string{"declaration", "definition", "readonly", "static", "deprecated", "abstract", "async", "modification", "documentation", "defaultLibrary"}
+
+// SemanticTokensLegend gives the names of the token types and modifiers
+// used by the server for encoding SemanticTokens
+type SemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+// SemanticToken is one decoded semantic token -- Start and Length are in
+// UTF-16 code units
+type SemanticToken struct {
+	Line   int
+	Start  int
+	Length int
+	Type   string
+	Mods   []string
+}
+
+// DecodeSemanticTokens decodes the relative-encoded semantic token data
+// (5 numbers per token) using given legend
+func DecodeSemanticTokens(data []uint32, legend *SemanticTokensLegend) []SemanticToken {
+	n := len(data) / 5
+	toks := make([]SemanticToken, 0, n)
+	ln, st := 0, 0
+	for i := 0; i < n; i++ {
+		d := data[i*5 : i*5+5]
+		if d[0] > 0 {
+			ln += int(d[0])
+			st = int(d[1])
+		} else {
+			st += int(d[1])
+		}
+		tk := SemanticToken{Line: ln, Start: st, Length: int(d[2])}
+		if int(d[3]) < len(legend.TokenTypes) {
+			tk.Type = legend.TokenTypes[d[3]]
+		}
+		for b, mod := range legend.TokenModifiers {
+			if d[4]&(1<<uint(b)) != 0 {
+				tk.Mods = append(tk.Mods, mod)
+			}
+		}
+		toks = append(toks, tk)
+	}
+	return toks
+}
+
+// HasMod returns true if the token has given modifier
+func (tk *SemanticToken) HasMod(mod string) bool {
+	for _, m := range tk.Mods {
+		if m == mod {
+			return true
+		}
+	}
+	return false
+}
+
+// InitializeParams are the params for the initialize request
+type InitializeParams struct {
+	ProcessID    int                    `json:"processId"`
+	RootURI      string                 `json:"rootUri,omitempty"`
+	Capabilities map[string]interface{} `json:"capabilities"`
+}
+
+// ServerCapabilities are the (subset of) capabilities of the server that
+// we use -- providers can be a bool or an options object
+type ServerCapabilities struct {
+	TextDocumentSync       json.RawMessage `json:"textDocumentSync,omitempty"`
+	CompletionProvider     json.RawMessage `json:"completionProvider,omitempty"`
+	HoverProvider          json.RawMessage `json:"hoverProvider,omitempty"`
+	DefinitionProvider     json.RawMessage `json:"definitionProvider,omitempty"`
+	SemanticTokensProvider *struct {
+		Legend SemanticTokensLegend `json:"legend"`
+		Full   json.RawMessage      `json:"full,omitempty"`
+	} `json:"semanticTokensProvider,omitempty"`
+}
+
+// providerOn returns true if a provider capability is present and not false
+func providerOn(raw json.RawMessage) bool {
+	s := string(raw)
+	return s != "" && s != "false" && s != "null"
+}
+
+// InitializeResult is the result of the initialize request
+type InitializeResult struct {
+	Capabilities ServerCapabilities `json:"capabilities"`
+}
+
+// FileURI returns the file:// URI for given file path, which is made
+// absolute
+func FileURI(path string) string {
+	if ap, err := filepath.Abs(path); err == nil {
+		path = ap
+	}
+	path = filepath.ToSlash(path)
+	if runtime.GOOS == "windows" {
+		path = "/" + path
+	}
+	u := url.URL{Scheme: "file", Path: path}
+	return u.String()
+}
+
+// URIPath returns the file path for given file:// URI
+func URIPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path)
+}
+
+// UTF16Col returns the UTF-16 column of given rune index in line
+func UTF16Col(line []rune, ch int) int {
+	if ch > len(line) {
+		ch = len(line)
+	}
+	col := 0
+	for _, r := range line[:ch] {
+		col += utf16Len(r)
+	}
+	return col
+}
+
+// RuneCol returns the rune index of given UTF-16 column in line
+func RuneCol(line []rune, col int) int {
+	c := 0
+	for i, r := range line {
+		if c >= col {
+			return i
+		}
+		c += utf16Len(r)
+	}
+	return len(line)
+}
+
+// utf16Len returns the number of UTF-16 code units for given rune
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
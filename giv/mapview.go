@@ -328,7 +328,9 @@ func (mv *MapView) MapAdd() {
 	updt := mv.UpdateStart()
 	defer mv.UpdateEnd(updt)
 
+	undo := ValueUndoSnapshot(mv.Map)
 	kit.MapAdd(mv.Map)
+	ValueUndoStru(mv.This(), mv.ViewPath, "Add", mv.Map, undo, mv.TmpSave)
 
 	if mv.TmpSave != nil {
 		mv.TmpSave.SaveTmp()
@@ -348,7 +350,9 @@ func (mv *MapView) MapDelete(key reflect.Value) {
 
 	kvi := kit.NonPtrValue(key).Interface()
 
+	undo := ValueUndoSnapshot(mv.Map)
 	kit.MapDeleteValue(mv.Map, kit.NonPtrValue(key))
+	ValueUndoStru(mv.This(), mv.ViewPath, "Delete", mv.Map, undo, mv.TmpSave)
 
 	if mv.TmpSave != nil {
 		mv.TmpSave.SaveTmp()
@@ -0,0 +1,546 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// MatrixViewSignals are signals that MatrixView can send on its MatrixSig
+type MatrixViewSignals int
+
+const (
+	// MatrixViewSelected emitted when a cell is selected by clicking on it --
+	// data is the image.Point{X: col, Y: row} of the cell
+	MatrixViewSelected MatrixViewSignals = iota
+
+	// MatrixViewDoubleClicked emitted when a cell is double-clicked -- data
+	// is the image.Point{X: col, Y: row} of the cell
+	MatrixViewDoubleClicked
+
+	MatrixViewSignalsN
+)
+
+//go:generate stringer -type=MatrixViewSignals
+
+// MatrixView displays a 2D matrix of numbers as a grid of colored cells,
+// using a ColorMap.  The Matrix can be a [][]float32 or [][]float64 (a
+// slice of rows), or a []float32 or []float64 in row-major order, with the
+// shape given by Rows, Cols.  Hovering over a cell shows its value, and
+// clicking selects it, emitting MatrixSig.  Call UpdateValues after the
+// values change to redraw only the cells that changed.
+type MatrixView struct {
+	gi.WidgetBase
+	Matrix     interface{}  `view:"-" desc:"the matrix data: [][]float32 or [][]float64 (slice of rows), or []float32 or []float64 in row-major order with shape Rows x Cols"`
+	Rows       int          `inactive:"+" desc:"number of rows -- set automatically for a slice of rows"`
+	Cols       int          `inactive:"+" desc:"number of columns -- set automatically for a slice of rows, as the length of the longest row"`
+	ColorMap   ColorMapName `desc:"color map for the values -- defaults to ColdHot"`
+	FixMin     bool         `desc:"if true, use Min as the value mapped to the lowest color, instead of the data minimum"`
+	Min        float32      `desc:"value mapped to the lowest color, if FixMin"`
+	FixMax     bool         `desc:"if true, use Max as the value mapped to the highest color, instead of the data maximum"`
+	Max        float32      `desc:"value mapped to the highest color, if FixMax"`
+	ZeroCtr    bool         `desc:"make the range symmetric around zero, so that zero is in the middle of the color map -- best used with a diverging color map such as ColdHot"`
+	ColorBar   bool         `desc:"show a color bar with the range of values to the right of the matrix"`
+	Square     bool         `desc:"keep cells square, instead of filling the available space"`
+	SelRow     int          `desc:"row of the selected cell, -1 if none"`
+	SelCol     int          `desc:"column of the selected cell, -1 if none"`
+	RangeMin   float32      `inactive:"+" desc:"value mapped to the lowest color, from the last render"`
+	RangeMax   float32      `inactive:"+" desc:"value mapped to the highest color, from the last render"`
+	MatrixSig  ki.Signal    `json:"-" xml:"-" view:"-" desc:"signal for cell selection and double-click -- see MatrixViewSignals for the types -- data is the image.Point{X: col, Y: row} of the cell"`
+	Vals       []float32    `json:"-" xml:"-" view:"-" desc:"copy of the values, in row-major order, as of the last render -- used by UpdateValues to only redraw changed cells"`
+	textRender gi.TextRender
+}
+
+var KiT_MatrixView = kit.Types.AddType(&MatrixView{}, MatrixViewProps)
+
+// AddNewMatrixView adds a new matrix view to given parent node, with given name.
+func AddNewMatrixView(parent ki.Ki, name string) *MatrixView {
+	return parent.AddNewChild(KiT_MatrixView, name).(*MatrixView)
+}
+
+func (mv *MatrixView) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*MatrixView)
+	mv.WidgetBase.CopyFieldsFrom(&fr.WidgetBase)
+	mv.Matrix = fr.Matrix
+	mv.Rows = fr.Rows
+	mv.Cols = fr.Cols
+	mv.ColorMap = fr.ColorMap
+	mv.FixMin = fr.FixMin
+	mv.Min = fr.Min
+	mv.FixMax = fr.FixMax
+	mv.Max = fr.Max
+	mv.ZeroCtr = fr.ZeroCtr
+	mv.ColorBar = fr.ColorBar
+	mv.Square = fr.Square
+}
+
+func (mv *MatrixView) Disconnect() {
+	mv.WidgetBase.Disconnect()
+	mv.MatrixSig.DisconnectAll()
+}
+
+var MatrixViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"width":            units.NewEm(20),
+	"height":           units.NewEm(20),
+	"min-width":        units.NewEm(4),
+	"min-height":       units.NewEm(4),
+	"max-width":        -1,
+	"max-height":       -1,
+	"padding":          units.NewPx(2),
+	"margin":           units.NewPx(2),
+	"font-size":        "small",
+	"color":            &gi.Prefs.Colors.Font,
+	"background-color": &gi.Prefs.Colors.Background,
+}
+
+// SetMatrix sets the matrix data to view, and updates the display -- rows,
+// cols give the shape for a flat []float32 or []float64 (in row-major
+// order), and are ignored for a slice of rows
+func (mv *MatrixView) SetMatrix(mat interface{}, rows, cols int) {
+	mv.Matrix = mat
+	mv.Rows, mv.Cols = rows, cols
+	mv.SetShape()
+	mv.SelRow, mv.SelCol = -1, -1
+	mv.UpdateSig()
+}
+
+// SetShape sets the Rows, Cols from a slice of rows, or, for a flat slice,
+// makes sure the shape is valid, defaulting to a single row
+func (mv *MatrixView) SetShape() {
+	switch mat := mv.Matrix.(type) {
+	case [][]float32:
+		mv.Rows, mv.Cols = len(mat), 0
+		for _, rw := range mat {
+			mv.Cols = ints.MaxInt(mv.Cols, len(rw))
+		}
+	case [][]float64:
+		mv.Rows, mv.Cols = len(mat), 0
+		for _, rw := range mat {
+			mv.Cols = ints.MaxInt(mv.Cols, len(rw))
+		}
+	case []float32:
+		mv.flatShape(len(mat))
+	case []float64:
+		mv.flatShape(len(mat))
+	default:
+		mv.Rows, mv.Cols = 0, 0
+	}
+}
+
+// flatShape ensures a valid shape for a flat slice of n values
+func (mv *MatrixView) flatShape(n int) {
+	if mv.Rows <= 0 && mv.Cols <= 0 {
+		mv.Rows, mv.Cols = 1, n
+		return
+	}
+	if mv.Cols <= 0 {
+		mv.Cols = (n + mv.Rows - 1) / mv.Rows
+	} else if mv.Rows <= 0 {
+		mv.Rows = (n + mv.Cols - 1) / mv.Cols
+	}
+}
+
+// Value returns the value at given row, col -- NaN if not present
+func (mv *MatrixView) Value(row, col int) float32 {
+	if row < 0 || col < 0 || row >= mv.Rows || col >= mv.Cols {
+		return mat32.NaN()
+	}
+	switch mat := mv.Matrix.(type) {
+	case [][]float32:
+		if row < len(mat) && col < len(mat[row]) {
+			return mat[row][col]
+		}
+	case [][]float64:
+		if row < len(mat) && col < len(mat[row]) {
+			return float32(mat[row][col])
+		}
+	case []float32:
+		if i := row*mv.Cols + col; i < len(mat) {
+			return mat[i]
+		}
+	case []float64:
+		if i := row*mv.Cols + col; i < len(mat) {
+			return float32(mat[i])
+		}
+	}
+	return mat32.NaN()
+}
+
+// CurVals returns the current values of the matrix in row-major order
+func (mv *MatrixView) CurVals(vals []float32) []float32 {
+	n := mv.Rows * mv.Cols
+	if cap(vals) < n {
+		vals = make([]float32, n)
+	}
+	vals = vals[:n]
+	switch mat := mv.Matrix.(type) {
+	case []float32:
+		copy(vals, mat)
+		for i := len(mat); i < n; i++ {
+			vals[i] = mat32.NaN()
+		}
+	default:
+		for r := 0; r < mv.Rows; r++ {
+			for c := 0; c < mv.Cols; c++ {
+				vals[r*mv.Cols+c] = mv.Value(r, c)
+			}
+		}
+	}
+	return vals
+}
+
+// ValRange returns the range of values to map onto the colormap, for given
+// values, according to the FixMin, FixMax and ZeroCtr settings
+func (mv *MatrixView) ValRange(vals []float32) (mn, mx float32) {
+	mn, mx = math32.Inf(1), math32.Inf(-1)
+	for _, v := range vals {
+		if mat32.IsNaN(v) || math32.IsInf(v, 0) {
+			continue
+		}
+		mn = mat32.Min(mn, v)
+		mx = mat32.Max(mx, v)
+	}
+	if math32.IsInf(mn, 0) {
+		mn, mx = 0, 0
+	}
+	if mv.FixMin {
+		mn = mv.Min
+	}
+	if mv.FixMax {
+		mx = mv.Max
+	}
+	if mv.ZeroCtr {
+		mx = mat32.Max(mat32.Abs(mn), mat32.Abs(mx))
+		mn = -mx
+	}
+	return
+}
+
+// ColorMapValue returns the ColorMap for the ColorMap name, defaulting to
+// ColdHot
+func (mv *MatrixView) ColorMapValue() *ColorMap {
+	if cm, ok := AvailColorMaps[string(mv.ColorMap)]; ok {
+		return cm
+	}
+	return StdColorMaps["ColdHot"]
+}
+
+// ValColor returns the color for given value, using the RangeMin, RangeMax
+// from the last render
+func (mv *MatrixView) ValColor(cm *ColorMap, v float32) gi.Color {
+	if mat32.IsNaN(v) {
+		return cm.NoColor
+	}
+	if mv.RangeMax == mv.RangeMin {
+		return cm.Map(0.5)
+	}
+	return cm.Map(float64((v - mv.RangeMin) / (mv.RangeMax - mv.RangeMin)))
+}
+
+// ColorBarWidth returns the width of the color bar including labels, 0 if
+// not shown
+func (mv *MatrixView) ColorBarWidth() float32 {
+	if !mv.ColorBar {
+		return 0
+	}
+	em := mv.Sty.UnContext.ToDots(1, units.Em)
+	lw := float32(0)
+	for _, v := range []float32{mv.RangeMin, mv.RangeMax} {
+		mv.setText(v)
+		lw = mat32.Max(lw, mv.textRender.Size.X)
+	}
+	return 0.5*em + em + 0.25*em + lw
+}
+
+// setText sets the textRender to the label for given value
+func (mv *MatrixView) setText(v float32) {
+	sty := &mv.Sty
+	mv.textRender.SetString(PlotTickLabel(float64(v)), &sty.Font, &sty.UnContext, &sty.Text, true, 0, 0)
+}
+
+// GridBox returns the box (in viewport coordinates) in which the cells are
+// drawn, and the size of each cell
+func (mv *MatrixView) GridBox() (mat32.Vec2, mat32.Vec2) {
+	spc := mv.Sty.BoxSpace()
+	pos := mv.LayState.Alloc.Pos.AddScalar(spc)
+	sz := mv.LayState.Alloc.Size.SubScalar(2 * spc)
+	sz.X -= mv.ColorBarWidth()
+	if mv.Rows == 0 || mv.Cols == 0 || sz.X <= 0 || sz.Y <= 0 {
+		return pos, mat32.Vec2{}
+	}
+	csz := mat32.Vec2{sz.X / float32(mv.Cols), sz.Y / float32(mv.Rows)}
+	if mv.Square {
+		cs := mat32.Min(csz.X, csz.Y)
+		csz = mat32.Vec2{cs, cs}
+	}
+	return pos, csz
+}
+
+// CellBox returns the box (in viewport coordinates) of given cell,
+// given the grid position and cell size from GridBox
+func (mv *MatrixView) CellBox(pos, csz mat32.Vec2, row, col int) image.Rectangle {
+	x0 := int(mat32.Floor(pos.X + float32(col)*csz.X))
+	x1 := int(mat32.Floor(pos.X + float32(col+1)*csz.X))
+	y0 := int(mat32.Floor(pos.Y + float32(row)*csz.Y))
+	y1 := int(mat32.Floor(pos.Y + float32(row+1)*csz.Y))
+	if x1 == x0 {
+		x1++
+	}
+	if y1 == y0 {
+		y1++
+	}
+	return image.Rect(x0, y0, x1, y1)
+}
+
+// CellAtPoint returns the row, col of the cell at given window position,
+// false if not over a cell
+func (mv *MatrixView) CellAtPoint(wp image.Point) (row, col int, ok bool) {
+	pos, csz := mv.GridBox()
+	if csz.X <= 0 || csz.Y <= 0 {
+		return
+	}
+	vpp := wp.Sub(mv.WinBBox.Min).Add(mv.VpBBox.Min)
+	col = int(mat32.Floor((float32(vpp.X) - pos.X) / csz.X))
+	row = int(mat32.Floor((float32(vpp.Y) - pos.Y) / csz.Y))
+	if row < 0 || col < 0 || row >= mv.Rows || col >= mv.Cols {
+		return 0, 0, false
+	}
+	return row, col, true
+}
+
+// SelectCell selects given cell (-1, -1 for none), and updates the display
+func (mv *MatrixView) SelectCell(row, col int) {
+	mv.SelRow, mv.SelCol = row, col
+	mv.UpdateSig()
+}
+
+// SelectCellAction selects given cell and emits the MatrixViewSelected signal
+func (mv *MatrixView) SelectCellAction(row, col int) {
+	mv.SelectCell(row, col)
+	mv.MatrixSig.Emit(mv.This(), int64(MatrixViewSelected), image.Point{X: col, Y: row})
+}
+
+// UpdateValues updates the display after the values in the Matrix have
+// changed, only redrawing the cells that changed -- if the shape or the
+// range of values changed, the whole view is re-rendered
+func (mv *MatrixView) UpdateValues() {
+	if !mv.This().(gi.Node2D).IsVisible() || mv.VpBBox.Empty() {
+		return
+	}
+	nr, nc := mv.Rows, mv.Cols
+	mv.SetShape()
+	vals := mv.CurVals(nil)
+	mn, mx := mv.ValRange(vals)
+	if nr != mv.Rows || nc != mv.Cols || len(vals) != len(mv.Vals) || mn != mv.RangeMin || mx != mv.RangeMax {
+		mv.UpdateSig()
+		return
+	}
+	wupdt := mv.TopUpdateStart()
+	rs := mv.Render()
+	rs.PushBounds(mv.VpBBox)
+	rs.Lock()
+	pc := &rs.Paint
+	cm := mv.ColorMapValue()
+	pos, csz := mv.GridBox()
+	var chg image.Rectangle
+	selb, hasSel := mv.SelBox(pos, csz)
+	selChg := false
+	for i, v := range vals {
+		ov := mv.Vals[i]
+		if v == ov || (mat32.IsNaN(v) && mat32.IsNaN(ov)) {
+			continue
+		}
+		mv.Vals[i] = v
+		r, c := i/mv.Cols, i%mv.Cols
+		cb := mv.CellBox(pos, csz, r, c)
+		pc.FillBoxColor(rs, mat32.NewVec2FmPoint(cb.Min), mat32.NewVec2FmPoint(cb.Size()), mv.ValColor(cm, v))
+		chg = chg.Union(cb)
+		if hasSel && cb.Overlaps(selb) { // painted over the outline
+			selChg = true
+		}
+	}
+	if selChg {
+		mv.RenderSel(rs, pos, csz)
+		chg = chg.Union(selb)
+	}
+	rs.Unlock()
+	rs.PopBounds()
+	chg = chg.Intersect(mv.VpBBox)
+	if !chg.Empty() {
+		winc := chg.Add(mv.WinBBox.Min.Sub(mv.VpBBox.Min))
+		mv.Viewport.This().(gi.Viewport).VpUploadRegion(chg, winc)
+	}
+	mv.TopUpdateEnd(wupdt)
+}
+
+// SelBox returns the box (in viewport coordinates) of the selection
+// outline, which extends one pixel beyond the selected cell into its
+// neighbors, and false if there is no selected cell
+func (mv *MatrixView) SelBox(pos, csz mat32.Vec2) (image.Rectangle, bool) {
+	if mv.SelRow < 0 || mv.SelCol < 0 || mv.SelRow >= mv.Rows || mv.SelCol >= mv.Cols {
+		return image.Rectangle{}, false
+	}
+	return mv.CellBox(pos, csz, mv.SelRow, mv.SelCol).Inset(-1), true
+}
+
+// RenderSel renders the selection outline around the selected cell
+func (mv *MatrixView) RenderSel(rs *gi.RenderState, pos, csz mat32.Vec2) {
+	cb, ok := mv.SelBox(pos, csz)
+	if !ok {
+		return
+	}
+	pc := &rs.Paint
+	clr := mv.Sty.Font.Color
+	mn := mat32.NewVec2FmPoint(cb.Min)
+	sz := mat32.NewVec2FmPoint(cb.Size())
+	pc.FillBoxColor(rs, mn, mat32.Vec2{sz.X, 2}, clr)
+	pc.FillBoxColor(rs, mat32.Vec2{mn.X, mn.Y + sz.Y - 2}, mat32.Vec2{sz.X, 2}, clr)
+	pc.FillBoxColor(rs, mn, mat32.Vec2{2, sz.Y}, clr)
+	pc.FillBoxColor(rs, mat32.Vec2{mn.X + sz.X - 2, mn.Y}, mat32.Vec2{2, sz.Y}, clr)
+}
+
+// RenderMatrix renders the entire matrix and color bar
+func (mv *MatrixView) RenderMatrix() {
+	mv.SetShape()
+	mv.Vals = mv.CurVals(mv.Vals)
+	mv.RangeMin, mv.RangeMax = mv.ValRange(mv.Vals)
+	rs := mv.Render()
+	rs.Lock()
+	pc := &rs.Paint
+	st := &mv.Sty
+	pc.FillBox(rs, mv.LayState.Alloc.Pos, mv.LayState.Alloc.Size, &st.Font.BgColor)
+	cm := mv.ColorMapValue()
+	pos, csz := mv.GridBox()
+	if csz.X > 0 && csz.Y > 0 {
+		for r := 0; r < mv.Rows; r++ {
+			for c := 0; c < mv.Cols; c++ {
+				cb := mv.CellBox(pos, csz, r, c)
+				pc.FillBoxColor(rs, mat32.NewVec2FmPoint(cb.Min), mat32.NewVec2FmPoint(cb.Size()), mv.ValColor(cm, mv.Vals[r*mv.Cols+c]))
+			}
+		}
+		mv.RenderSel(rs, pos, csz)
+	}
+	rs.Unlock()
+	if mv.ColorBar {
+		mv.RenderColorBar(cm)
+	}
+}
+
+// RenderColorBar renders the color bar to the right of the matrix, with
+// labels for the range of values
+func (mv *MatrixView) RenderColorBar(cm *ColorMap) {
+	rs := mv.Render()
+	pc := &rs.Paint
+	spc := mv.Sty.BoxSpace()
+	em := mv.Sty.UnContext.ToDots(1, units.Em)
+	pos := mv.LayState.Alloc.Pos.AddScalar(spc)
+	sz := mv.LayState.Alloc.Size.SubScalar(2 * spc)
+	bx := pos.X + sz.X - mv.ColorBarWidth() + 0.5*em
+	mv.setText(mv.RangeMax)
+	th := mv.textRender.Size.Y
+	by0 := pos.Y + 0.5*th
+	bh := sz.Y - th
+	if bh <= 0 {
+		return
+	}
+	rs.Lock()
+	inc := mat32.Max(1, mat32.Ceil(bh/100))
+	for p := float32(0); p < bh; p += inc {
+		val := 1 - p/(bh-1)
+		pc.FillBoxColor(rs, mat32.Vec2{bx, by0 + p}, mat32.Vec2{em, inc}, cm.Map(float64(val)))
+	}
+	rs.Unlock()
+	lx := bx + em + 0.25*em
+	mv.textRender.RenderTopPos(rs, mat32.Vec2{lx, pos.Y})
+	mv.setText(mv.RangeMin)
+	mv.textRender.RenderTopPos(rs, mat32.Vec2{lx, by0 + bh - 0.5*th})
+	if mv.RangeMin < 0 && mv.RangeMax > 0 {
+		zp := by0 + bh*mv.RangeMax/(mv.RangeMax-mv.RangeMin)
+		if zp-0.5*th > pos.Y+th && zp+0.5*th < by0+bh-0.5*th {
+			mv.setText(0)
+			mv.textRender.RenderTopPos(rs, mat32.Vec2{lx, zp - 0.5*th})
+		}
+	}
+}
+
+// MouseEvent handles cell selection
+func (mv *MatrixView) MouseEvent() {
+	mv.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		mvv := recv.Embed(KiT_MatrixView).(*MatrixView)
+		if me.Button != mouse.Left {
+			return
+		}
+		row, col, ok := mvv.CellAtPoint(me.Where)
+		if !ok {
+			return
+		}
+		switch me.Action {
+		case mouse.Press:
+			me.SetProcessed()
+			mvv.GrabFocus()
+			mvv.SelectCellAction(row, col)
+		case mouse.DoubleClick:
+			me.SetProcessed()
+			mvv.MatrixSig.Emit(mvv.This(), int64(MatrixViewDoubleClicked), image.Point{X: col, Y: row})
+		}
+	})
+}
+
+// HoverEvent shows the value of the cell under the mouse as a tooltip
+func (mv *MatrixView) HoverEvent() {
+	mv.ConnectEvent(oswin.MouseHoverEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.HoverEvent)
+		mvv := recv.Embed(KiT_MatrixView).(*MatrixView)
+		tt := mvv.Tooltip
+		if row, col, ok := mvv.CellAtPoint(me.Where); ok {
+			tt = fmt.Sprintf("[%d, %d]: %v", row, col, PlotTickLabel(float64(mvv.Value(row, col))))
+		}
+		if tt == "" {
+			return
+		}
+		me.SetProcessed()
+		pos := me.Where
+		pos.X += 10
+		pos.Y += 10
+		gi.PopupTooltip(tt, pos.X, pos.Y, mvv.ViewportSafe(), mvv.Nm)
+	})
+}
+
+func (mv *MatrixView) ConnectEvents2D() {
+	mv.MouseEvent()
+	mv.HoverEvent()
+}
+
+func (mv *MatrixView) Style2D() {
+	mv.WidgetBase.Style2D()
+	mv.Sty.Font.OpenFont(&mv.Sty.UnContext)
+}
+
+func (mv *MatrixView) Render2D() {
+	if mv.FullReRenderIfNeeded() {
+		return
+	}
+	if mv.PushBounds() {
+		mv.This().(gi.Node2D).ConnectEvents2D()
+		mv.RenderMatrix()
+		mv.Render2DChildren()
+		mv.PopBounds()
+	} else {
+		mv.DisconnectAllEvents(gi.RegPri)
+	}
+}
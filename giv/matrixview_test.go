@@ -0,0 +1,156 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goki/mat32"
+)
+
+func matrixTestView(mat interface{}, rows, cols int) *MatrixView {
+	mv := &MatrixView{}
+	mv.InitName(mv, "mv")
+	mv.Matrix = mat
+	mv.Rows, mv.Cols = rows, cols
+	mv.SetShape()
+	mv.SelRow, mv.SelCol = -1, -1
+	return mv
+}
+
+func TestMatrixViewShape(t *testing.T) {
+	tests := []struct {
+		mat        interface{}
+		rows, cols int
+		xrows      int
+		xcols      int
+	}{
+		{[][]float32{{1, 2}, {3}, {4, 5, 6}}, 0, 0, 3, 3},
+		{[][]float64{{1, 2}}, 5, 5, 1, 2},
+		{[]float32{1, 2, 3, 4, 5, 6}, 0, 0, 1, 6},
+		{[]float64{1, 2, 3, 4, 5, 6}, 2, 0, 2, 3},
+		{[]float32{1, 2, 3, 4, 5}, 0, 2, 3, 2},
+		{[]float32{1, 2, 3, 4, 5, 6}, 3, 2, 3, 2},
+		{[]int{1, 2}, 1, 2, 0, 0},
+	}
+	for _, tst := range tests {
+		mv := matrixTestView(tst.mat, tst.rows, tst.cols)
+		if mv.Rows != tst.xrows || mv.Cols != tst.xcols {
+			t.Errorf("SetShape(%T %v, %v, %v) = %v x %v, expected %v x %v", tst.mat, tst.mat, tst.rows, tst.cols, mv.Rows, mv.Cols, tst.xrows, tst.xcols)
+		}
+	}
+}
+
+func TestMatrixViewValues(t *testing.T) {
+	mvs := []*MatrixView{
+		matrixTestView([][]float32{{1, 2, 3}, {4}}, 0, 0),
+		matrixTestView([][]float64{{1, 2, 3}, {4}}, 0, 0),
+		matrixTestView([]float32{1, 2, 3, 4}, 0, 3),
+		matrixTestView([]float64{1, 2, 3, 4}, 0, 3),
+	}
+	exp := []float32{1, 2, 3, 4, mat32.NaN(), mat32.NaN()}
+	for _, mv := range mvs {
+		vals := mv.CurVals(nil)
+		if len(vals) != len(exp) {
+			t.Errorf("%T CurVals: got %v, expected %v", mv.Matrix, vals, exp)
+			continue
+		}
+		for i, v := range vals {
+			if v != exp[i] && !(mat32.IsNaN(v) && mat32.IsNaN(exp[i])) {
+				t.Errorf("%T CurVals: got %v, expected %v", mv.Matrix, vals, exp)
+				break
+			}
+		}
+		if v := mv.Value(1, 0); v != 4 {
+			t.Errorf("%T Value(1, 0) = %v, expected 4", mv.Matrix, v)
+		}
+		if v := mv.Value(0, 3); !mat32.IsNaN(v) {
+			t.Errorf("%T Value(0, 3) out of range = %v, expected NaN", mv.Matrix, v)
+		}
+	}
+}
+
+func TestMatrixViewValRange(t *testing.T) {
+	vals := []float32{-1, 3, mat32.NaN(), mat32.Inf(1), 2}
+	tests := []struct {
+		fixMin, fixMax, zeroCtr bool
+		min, max                float32
+		mn, mx                  float32
+	}{
+		{false, false, false, 0, 0, -1, 3},
+		{true, false, false, -5, 0, -5, 3},
+		{false, true, false, 0, 10, -1, 10},
+		{false, false, true, 0, 0, -3, 3},
+		{true, false, true, -5, 0, -5, 5},
+	}
+	for _, tst := range tests {
+		mv := &MatrixView{FixMin: tst.fixMin, FixMax: tst.fixMax, ZeroCtr: tst.zeroCtr, Min: tst.min, Max: tst.max}
+		if mn, mx := mv.ValRange(vals); mn != tst.mn || mx != tst.mx {
+			t.Errorf("ValRange %+v = %v, %v, expected %v, %v", tst, mn, mx, tst.mn, tst.mx)
+		}
+	}
+	mv := &MatrixView{}
+	if mn, mx := mv.ValRange([]float32{mat32.NaN()}); mn != 0 || mx != 0 {
+		t.Errorf("ValRange of no values = %v, %v, expected 0, 0", mn, mx)
+	}
+}
+
+func TestMatrixViewCells(t *testing.T) {
+	mv := matrixTestView([]float32{1, 2, 3, 4, 5, 6}, 2, 3)
+	mv.LayState.Alloc.Pos = mat32.Vec2{10, 20}
+	mv.LayState.Alloc.Size = mat32.Vec2{60, 30}
+	mv.VpBBox = image.Rect(10, 20, 70, 50)
+	mv.WinBBox = image.Rect(110, 120, 170, 150)
+	pos, csz := mv.GridBox()
+	if pos != (mat32.Vec2{10, 20}) || csz != (mat32.Vec2{20, 15}) {
+		t.Fatalf("GridBox = %v, %v, expected {10 20}, {20 15}", pos, csz)
+	}
+	if cb := mv.CellBox(pos, csz, 1, 2); cb != image.Rect(50, 35, 70, 50) {
+		t.Errorf("CellBox(1, 2) = %v", cb)
+	}
+	mv.Square = true
+	if _, csz := mv.GridBox(); csz != (mat32.Vec2{15, 15}) {
+		t.Errorf("GridBox Square: cell size %v, expected {15 15}", csz)
+	}
+	mv.Square = false
+
+	tests := []struct {
+		wp       image.Point
+		row, col int
+		ok       bool
+	}{
+		{image.Point{110, 120}, 0, 0, true},
+		{image.Point{135, 140}, 1, 1, true},
+		{image.Point{169, 149}, 1, 2, true},
+		{image.Point{170, 140}, 0, 0, false},
+		{image.Point{109, 140}, 0, 0, false},
+	}
+	for _, tst := range tests {
+		row, col, ok := mv.CellAtPoint(tst.wp)
+		if row != tst.row || col != tst.col || ok != tst.ok {
+			t.Errorf("CellAtPoint(%v) = %v, %v, %v, expected %v, %v, %v", tst.wp, row, col, ok, tst.row, tst.col, tst.ok)
+		}
+	}
+
+	if _, has := mv.SelBox(pos, csz); has {
+		t.Errorf("SelBox with no selection: has box")
+	}
+	mv.SelRow, mv.SelCol = 0, 1
+	selb, has := mv.SelBox(pos, csz)
+	if !has || selb != image.Rect(29, 19, 51, 36) {
+		t.Errorf("SelBox(0, 1) = %v, %v, expected cell box grown by 1", selb, has)
+	}
+	// the outline extends into the neighboring cells, which redraw it
+	for _, rc := range [][2]int{{0, 0}, {0, 2}, {1, 1}, {1, 0}} {
+		if cb := mv.CellBox(pos, csz, rc[0], rc[1]); !cb.Overlaps(selb) {
+			t.Errorf("cell %v box %v does not overlap selection outline %v", rc, cb, selb)
+		}
+	}
+	mv.SelRow = 2
+	if _, has := mv.SelBox(pos, csz); has {
+		t.Errorf("SelBox out of range: has box")
+	}
+}
@@ -0,0 +1,752 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/lex"
+)
+
+// MergeViewDialogFromFile opens a dialog for resolving the conflicts in
+// given file, which contains conflict markers (<<<<<<< etc), as written by
+// a version control system when a merge fails.
+func MergeViewDialogFromFile(avp *gi.Viewport2D, file string) (*MergeView, error) {
+	fb, err := textbuf.FileBytes(file)
+	if err != nil {
+		return nil, err
+	}
+	lns := textbuf.BytesToLineStrings(fb, false)
+	if n := len(lns); n > 0 && lns[n-1] == "" {
+		lns = lns[:n-1]
+	}
+	if !textbuf.HasConflictMarkers(lns) {
+		err = fmt.Errorf("giv.MergeViewDialogFromFile: no conflict markers found in file: %v", file)
+		log.Println(err)
+		return nil, err
+	}
+	mg, olbl, tlbl, err := textbuf.ParseConflicts(lns)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return MergeViewDialog(avp, mg, file, olbl, tlbl, DlgOpts{Title: "Merge: " + DirAndFile(file)}), nil
+}
+
+// MergeViewDialog opens a dialog for editing the result of a three-way merge,
+// e.g., from textbuf.MergeLines or textbuf.ParseConflicts.  The file is
+// the default file name for saving the result, and the labels name the
+// ours and theirs versions (e.g., HEAD and a branch name).
+func MergeViewDialog(avp *gi.Viewport2D, mg textbuf.Merge, file, lblOurs, lblTheirs string, opts DlgOpts) *MergeView {
+	dlg := gi.NewStdDialog(opts.ToGiOpts(), opts.Ok, opts.Cancel)
+
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+
+	mv := frame.InsertNewChild(KiT_MergeView, prIdx+1, "merge-view").(*MergeView)
+	mv.SetStretchMax()
+	mv.File = file
+	mv.LabelOurs = lblOurs
+	mv.LabelTheirs = lblTheirs
+	mv.SetMerge(mg)
+
+	dlg.UpdateEndNoSig(true) // going to be shown
+	dlg.Open(0, 0, avp, nil)
+	return mv
+}
+
+// MergeChoices are the choices for resolving a conflict in a MergeView
+type MergeChoices int32
+
+//go:generate stringer -type=MergeChoices
+
+var KiT_MergeChoices = kit.Enums.AddEnum(MergeChoicesN, kit.NotBitFlag, nil)
+
+const (
+	// MergeUnresolved means the conflict has not been resolved -- the result
+	// has both versions within conflict markers
+	MergeUnresolved MergeChoices = iota
+
+	// MergeOurs uses our version
+	MergeOurs
+
+	// MergeTheirs uses their version
+	MergeTheirs
+
+	// MergeBoth uses our version followed by their version
+	MergeBoth
+
+	// MergeBase uses the base version, if known
+	MergeBase
+
+	// MergeEdited means the result was edited directly
+	MergeEdited
+
+	MergeChoicesN
+)
+
+// MergeViewHunk records the state of one textbuf.MergeHunk in a MergeView
+type MergeViewHunk struct {
+	Choice MergeChoices `desc:"how the hunk has been resolved -- only used for conflicts"`
+	St     int          `desc:"starting line of the hunk in the result"`
+	Ed     int          `desc:"ending line (exclusive) of the hunk in the result"`
+	OursSt int          `desc:"starting line of the hunk in ours"`
+	ThrSt  int          `desc:"starting line of the hunk in theirs"`
+}
+
+///////////////////////////////////////////////////////////////////
+// MergeView
+
+// MergeView presents a three-way merge, with our version on the left, their
+// version on the right, and the editable result in the middle.  Conflicting
+// hunks are highlighted, and can be resolved by choosing ours, theirs or
+// both (or base if known), or by editing the result directly.
+type MergeView struct {
+	gi.Frame
+	File        string          `desc:"file name for saving the result"`
+	LabelOurs   string          `desc:"label for our version, e.g., HEAD"`
+	LabelTheirs string          `desc:"label for their version, e.g., a branch name"`
+	Merge       textbuf.Merge   `json:"-" xml:"-" desc:"the merge hunks"`
+	Hunks       []MergeViewHunk `json:"-" xml:"-" desc:"state of each merge hunk, in same order as Merge"`
+	BufOurs     *TextBuf        `json:"-" xml:"-" desc:"textbuf for our version"`
+	BufResult   *TextBuf        `json:"-" xml:"-" desc:"textbuf for the merge result"`
+	BufTheirs   *TextBuf        `json:"-" xml:"-" desc:"textbuf for their version"`
+	inChoose    bool            // true while editing result for a choice
+}
+
+var KiT_MergeView = kit.Types.AddType(&MergeView{}, MergeViewProps)
+
+// AddNewMergeView adds a new mergeview to given parent node, with given name.
+func AddNewMergeView(parent ki.Ki, name string) *MergeView {
+	return parent.AddNewChild(KiT_MergeView, name).(*MergeView)
+}
+
+// MergeStrings computes the three-way merge of ours and theirs relative to
+// base, and displays it in the MergeView
+func (mv *MergeView) MergeStrings(base, ours, theirs []string) {
+	mv.SetMerge(textbuf.MergeLines(base, ours, theirs))
+}
+
+// SetMerge sets the merge hunks to display and edit -- the result starts
+// with all non-conflicting changes merged, and conflicts unresolved.
+func (mv *MergeView) SetMerge(mg textbuf.Merge) {
+	if !mv.IsConfiged() {
+		mv.Config()
+	}
+	mv.Merge = mg
+	mv.Hunks = make([]MergeViewHunk, len(mg))
+	var ob, rb, tb [][]byte
+	for i := range mg {
+		mh := &mg[i]
+		hv := &mv.Hunks[i]
+		hv.OursSt = len(ob)
+		hv.ThrSt = len(tb)
+		hv.St = len(rb)
+		ob = append(ob, stringsToBytes(mh.Ours)...)
+		tb = append(tb, stringsToBytes(mh.Theirs)...)
+		rb = append(rb, stringsToBytes(mv.HunkLines(i))...)
+		hv.Ed = len(rb)
+	}
+	ov, rv, tv := mv.TextViews()
+	oupdt := ov.UpdateStart()
+	rupdt := rv.UpdateStart()
+	tupdt := tv.UpdateStart()
+	mv.BufOurs.SetTextLines(ob, false)
+	mv.BufResult.SetTextLines(rb, false)
+	mv.BufTheirs.SetTextLines(tb, false)
+	mv.BufResult.ClearChanged()
+	mv.SetColors()
+	ov.UpdateEnd(oupdt)
+	rv.UpdateEnd(rupdt)
+	tv.UpdateEnd(tupdt)
+	mv.UpdateToolBar()
+}
+
+// stringsToBytes converts string lines to byte lines
+func stringsToBytes(strs []string) [][]byte {
+	bs := make([][]byte, len(strs))
+	for i, s := range strs {
+		bs[i] = []byte(s)
+	}
+	return bs
+}
+
+// HunkLines returns the result lines for given hunk, according to its
+// current choice
+func (mv *MergeView) HunkLines(hi int) []string {
+	mh := &mv.Merge[hi]
+	if mh.Tag != 'c' {
+		return mh.Merged()
+	}
+	switch mv.Hunks[hi].Choice {
+	case MergeOurs:
+		return mh.Ours
+	case MergeTheirs:
+		return mh.Theirs
+	case MergeBoth:
+		lns := make([]string, 0, len(mh.Ours)+len(mh.Theirs))
+		lns = append(lns, mh.Ours...)
+		return append(lns, mh.Theirs...)
+	case MergeBase:
+		if mh.Base != nil {
+			return mh.Base
+		}
+	}
+	olbl := mv.LabelOurs
+	if olbl == "" {
+		olbl = "ours"
+	}
+	tlbl := mv.LabelTheirs
+	if tlbl == "" {
+		tlbl = "theirs"
+	}
+	return mh.ConflictLines(olbl, tlbl)
+}
+
+// IsUnresolved returns true if given hunk is a conflict that still has
+// conflict markers in the result
+func (mv *MergeView) IsUnresolved(hi int) bool {
+	if mv.Merge[hi].Tag != 'c' {
+		return false
+	}
+	hv := &mv.Hunks[hi]
+	if hv.Choice != MergeUnresolved && hv.Choice != MergeEdited {
+		return false
+	}
+	return textbuf.HasConflictMarkers(mv.BufResult.Strings(false)[hv.St:hv.Ed])
+}
+
+// NUnresolved returns the number of conflicts that remain unresolved
+func (mv *MergeView) NUnresolved() int {
+	n := 0
+	for hi := range mv.Hunks {
+		if mv.IsUnresolved(hi) {
+			n++
+		}
+	}
+	return n
+}
+
+// HunkForLine returns the index of the hunk containing given line in
+// ours (0), the result (1) or theirs (2) -- -1 if none
+func (mv *MergeView) HunkForLine(view int, ln int) int {
+	for hi := range mv.Hunks {
+		hv := &mv.Hunks[hi]
+		mh := &mv.Merge[hi]
+		var st, ed int
+		switch view {
+		case 0:
+			st, ed = hv.OursSt, hv.OursSt+len(mh.Ours)
+		case 1:
+			st, ed = hv.St, hv.Ed
+		default:
+			st, ed = hv.ThrSt, hv.ThrSt+len(mh.Theirs)
+		}
+		if ln >= st && (ln < ed || (st == ed && ln == st)) {
+			return hi
+		}
+	}
+	return -1
+}
+
+// ChooseHunk resolves given conflict hunk with given choice, replacing
+// its lines in the result
+func (mv *MergeView) ChooseHunk(hi int, choice MergeChoices) bool {
+	if hi < 0 || hi >= len(mv.Hunks) || mv.Merge[hi].Tag != 'c' {
+		return false
+	}
+	if choice == MergeBase && mv.Merge[hi].Base == nil {
+		return false
+	}
+	hv := &mv.Hunks[hi]
+	hv.Choice = choice
+	lns := mv.HunkLines(hi)
+	mv.inChoose = true
+	mv.ReplaceResultLines(hv.St, hv.Ed, lns)
+	mv.inChoose = false
+	dl := len(lns) - (hv.Ed - hv.St)
+	hv.Ed += dl
+	for j := hi + 1; j < len(mv.Hunks); j++ {
+		mv.Hunks[j].St += dl
+		mv.Hunks[j].Ed += dl
+	}
+	mv.SetColors()
+	mv.UpdateToolBar()
+	return true
+}
+
+// Choose resolves the conflict at the cursor in the result with given
+// choice, and moves to the next conflict
+func (mv *MergeView) Choose(choice MergeChoices) bool {
+	_, rv, _ := mv.TextViews()
+	hi := mv.HunkForLine(1, rv.CursorPos.Ln)
+	if !mv.ChooseHunk(hi, choice) {
+		return false
+	}
+	mv.ShowHunk(hi)
+	mv.NextConflict()
+	return true
+}
+
+// ReplaceResultLines replaces lines st to ed (exclusive) of the result
+// with given lines, as undoable edits
+func (mv *MergeView) ReplaceResultLines(st, ed int, lns []string) {
+	tb := mv.BufResult
+	uoff := tb.Undos.Off
+	tb.Undos.Off = false
+	defer func() { tb.Undos.Off = uoff }()
+	txt := []byte(strings.Join(lns, "\n"))
+	nl := tb.NumLines()
+	switch {
+	case ed < nl:
+		if ed > st {
+			tb.DeleteText(lex.Pos{Ln: st}, lex.Pos{Ln: ed}, EditSignal)
+		}
+		if len(lns) > 0 {
+			tb.InsertText(lex.Pos{Ln: st}, append(txt, '\n'), EditSignal)
+		}
+	case st > 0: // through end -- also replace the preceding newline
+		pp := lex.Pos{Ln: st - 1, Ch: tb.LineLen(st - 1)}
+		if ed > st {
+			tb.DeleteText(pp, tb.EndPos(), EditSignal)
+		}
+		if len(lns) > 0 {
+			tb.InsertText(pp, append([]byte("\n"), txt...), EditSignal)
+		}
+	default: // entire buffer
+		tb.DeleteText(lex.PosZero, tb.EndPos(), EditSignal)
+		tb.InsertText(lex.PosZero, txt, EditSignal)
+	}
+}
+
+// ResultEdited adjusts the hunk line ranges for an edit of the result, and
+// marks a conflict hunk that was edited as such
+func (mv *MergeView) ResultEdited(tbe *textbuf.Edit) {
+	if mv.inChoose || tbe == nil {
+		return
+	}
+	st := tbe.Reg.Start.Ln
+	nl := tbe.Reg.End.Ln - st
+	if tbe.Delete {
+		ed := tbe.Reg.End.Ln
+		adj := func(ln int) int {
+			switch {
+			case ln <= st:
+				return ln
+			case ln >= ed:
+				return ln - nl
+			}
+			return st
+		}
+		for hi := range mv.Hunks {
+			hv := &mv.Hunks[hi]
+			if st < hv.Ed && ed >= hv.St && mv.Merge[hi].Tag == 'c' {
+				hv.Choice = MergeEdited
+			}
+			hv.St = adj(hv.St)
+			hv.Ed = adj(hv.Ed)
+		}
+	} else {
+		owned := false
+		for hi := range mv.Hunks {
+			hv := &mv.Hunks[hi]
+			if owned {
+				hv.St += nl
+				hv.Ed += nl
+				continue
+			}
+			if st < hv.Ed || (hv.St == hv.Ed && hv.St == st) {
+				owned = true
+				hv.Ed += nl
+				if mv.Merge[hi].Tag == 'c' {
+					hv.Choice = MergeEdited
+				}
+			}
+		}
+	}
+	mv.SetColors()
+	mv.UpdateToolBar()
+}
+
+// SetColors sets the line colors for the hunks in each buffer: conflicts
+// are red when unresolved and green once resolved, and changes merged
+// from one side are blue.
+func (mv *MergeView) SetColors() {
+	mv.BufOurs.LineColors = nil
+	mv.BufResult.LineColors = nil
+	mv.BufTheirs.LineColors = nil
+	for hi := range mv.Hunks {
+		hv := &mv.Hunks[hi]
+		mh := &mv.Merge[hi]
+		var oclr, rclr, tclr string
+		switch mh.Tag {
+		case 'e':
+			continue
+		case 'o':
+			oclr, rclr = "blue", "blue"
+		case 't':
+			tclr, rclr = "blue", "blue"
+		case 's':
+			oclr, rclr, tclr = "blue", "blue", "blue"
+		case 'c':
+			oclr, tclr = "red", "red"
+			rclr = "red"
+			if !mv.IsUnresolved(hi) {
+				rclr = "green"
+			}
+		}
+		if oclr != "" {
+			for ln := hv.OursSt; ln < hv.OursSt+len(mh.Ours); ln++ {
+				mv.BufOurs.SetLineColor(ln, oclr)
+			}
+		}
+		if tclr != "" {
+			for ln := hv.ThrSt; ln < hv.ThrSt+len(mh.Theirs); ln++ {
+				mv.BufTheirs.SetLineColor(ln, tclr)
+			}
+		}
+		for ln := hv.St; ln < hv.Ed; ln++ {
+			mv.BufResult.SetLineColor(ln, rclr)
+		}
+	}
+	mv.BufOurs.RefreshViews()
+	mv.BufResult.RefreshViews()
+	mv.BufTheirs.RefreshViews()
+}
+
+// ShowHunk moves the cursor in each view to the start of given hunk
+func (mv *MergeView) ShowHunk(hi int) {
+	if hi < 0 || hi >= len(mv.Hunks) {
+		return
+	}
+	hv := &mv.Hunks[hi]
+	ov, rv, tv := mv.TextViews()
+	ov.SetCursorShow(lex.Pos{Ln: hv.OursSt})
+	ov.ScrollCursorToVertCenter()
+	rv.SetCursorShow(lex.Pos{Ln: hv.St})
+	rv.ScrollCursorToVertCenter()
+	tv.SetCursorShow(lex.Pos{Ln: hv.ThrSt})
+	tv.ScrollCursorToVertCenter()
+}
+
+// NextConflict moves to the next unresolved conflict after the cursor in
+// the result
+func (mv *MergeView) NextConflict() bool {
+	_, rv, _ := mv.TextViews()
+	curLn := rv.CursorPos.Ln
+	for hi := range mv.Hunks {
+		if mv.Hunks[hi].St > curLn && mv.IsUnresolved(hi) {
+			mv.ShowHunk(hi)
+			return true
+		}
+	}
+	return false
+}
+
+// PrevConflict moves to the previous unresolved conflict before the cursor
+// in the result
+func (mv *MergeView) PrevConflict() bool {
+	_, rv, _ := mv.TextViews()
+	curLn := rv.CursorPos.Ln
+	for hi := len(mv.Hunks) - 1; hi >= 0; hi-- {
+		if mv.Hunks[hi].Ed <= curLn && mv.IsUnresolved(hi) {
+			mv.ShowHunk(hi)
+			return true
+		}
+	}
+	return false
+}
+
+// SaveFile saves the result to given filename
+func (mv *MergeView) SaveFile(fname gi.FileName) {
+	mv.File = string(fname)
+	mv.BufResult.SaveAs(fname)
+	mv.UpdateToolBar()
+}
+
+func (mv *MergeView) Config() {
+	mv.Lay = gi.LayoutVert
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "toolbar")
+	config.Add(gi.KiT_Layout, "merge-lay")
+	mods, updt := mv.ConfigChildren(config, ki.UniqueNames)
+	if !mods {
+		updt = mv.UpdateStart()
+	} else {
+		mv.ConfigToolBar()
+		mv.ConfigTexts()
+	}
+	mv.SetFullReRender()
+	mv.UpdateEnd(updt)
+}
+
+func (mv *MergeView) ResultModifiedUpdate(act *gi.Action) {
+	act.SetActiveStateUpdt(mv.BufResult.IsChanged())
+}
+
+func (mv *MergeView) HasConflictsUpdate(act *gi.Action) {
+	act.SetActiveStateUpdt(mv.Merge.NConflicts() > 0)
+}
+
+func (mv *MergeView) ConfigToolBar() {
+	tb := mv.ToolBar()
+	tb.SetStretchMaxWidth()
+	gi.AddNewLabel(tb, "label-status", "")
+	tb.AddAction(gi.ActOpts{Label: "Next", Icon: "wedge-down", Tooltip: "move down to next unresolved conflict", UpdateFunc: mv.HasConflictsUpdate},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.NextConflict()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Prev", Icon: "wedge-up", Tooltip: "move up to previous unresolved conflict", UpdateFunc: mv.HasConflictsUpdate},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.PrevConflict()
+		})
+	tb.AddSeparator("sep-choose")
+	tb.AddAction(gi.ActOpts{Label: "Ours", Icon: "wedge-left", Tooltip: "resolve the conflict at the cursor in the result using our version (left), and move to next conflict", UpdateFunc: mv.HasConflictsUpdate},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.Choose(MergeOurs)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Theirs", Icon: "wedge-right", Tooltip: "resolve the conflict at the cursor in the result using their version (right), and move to next conflict", UpdateFunc: mv.HasConflictsUpdate},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.Choose(MergeTheirs)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Both", Icon: "copy", Tooltip: "resolve the conflict at the cursor in the result using our version followed by their version, and move to next conflict", UpdateFunc: mv.HasConflictsUpdate},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.Choose(MergeBoth)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Base", Icon: "update", Tooltip: "resolve the conflict at the cursor in the result using the common base version, if known, and move to next conflict", UpdateFunc: mv.HasConflictsUpdate},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.Choose(MergeBase)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Unresolve", Icon: "undo", Tooltip: "restore the conflict markers for the conflict at the cursor in the result", UpdateFunc: mv.HasConflictsUpdate},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv := recv.Embed(KiT_MergeView).(*MergeView)
+			_, rv, _ := mvv.TextViews()
+			mvv.ChooseHunk(mvv.HunkForLine(1, rv.CursorPos.Ln), MergeUnresolved)
+		})
+	tb.AddSeparator("sep-save")
+	tb.AddAction(gi.ActOpts{Label: "Save", Icon: "file-save", Tooltip: "save the merge result -- prompts for filename", UpdateFunc: mv.ResultModifiedUpdate},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv := recv.Embed(KiT_MergeView).(*MergeView)
+			CallMethod(mvv, "SaveFile", mvv.Viewport)
+		})
+}
+
+// StatusText returns the text for the status label
+func (mv *MergeView) StatusText() string {
+	nc := mv.Merge.NConflicts()
+	nu := mv.NUnresolved()
+	return fmt.Sprintf("%v: %d conflicts, %d unresolved", DirAndFile(mv.File), nc, nu)
+}
+
+func (mv *MergeView) UpdateToolBar() {
+	tb := mv.ToolBar()
+	if lb, ok := tb.ChildByName("label-status", 0).(*gi.Label); ok {
+		lb.SetText(mv.StatusText())
+	}
+	tb.UpdateActions()
+}
+
+func (mv *MergeView) ToolBar() *gi.ToolBar {
+	tb := mv.ChildByName("toolbar", 0).(*gi.ToolBar)
+	return tb
+}
+
+func (mv *MergeView) MergeLay() *gi.Layout {
+	lay := mv.ChildByName("merge-lay", 1).(*gi.Layout)
+	return lay
+}
+
+// TextViewLays returns the layouts for ours, result and theirs
+func (mv *MergeView) TextViewLays() (*gi.Layout, *gi.Layout, *gi.Layout) {
+	lay := mv.MergeLay()
+	o := lay.Child(0).(*gi.Layout)
+	r := lay.Child(1).(*gi.Layout)
+	t := lay.Child(2).(*gi.Layout)
+	return o, r, t
+}
+
+// TextViews returns the text views for ours, result and theirs
+func (mv *MergeView) TextViews() (*MergeTextView, *MergeTextView, *MergeTextView) {
+	o, r, t := mv.TextViewLays()
+	ov := o.Child(1).(*MergeTextView)
+	rv := r.Child(1).(*MergeTextView)
+	tv := t.Child(1).(*MergeTextView)
+	return ov, rv, tv
+}
+
+func (mv *MergeView) ConfigTexts() {
+	lay := mv.MergeLay()
+	if mv.BufResult == nil {
+		mv.BufOurs = &TextBuf{}
+		mv.BufOurs.InitName(mv.BufOurs, "merge-buf-ours")
+		mv.BufResult = &TextBuf{}
+		mv.BufResult.InitName(mv.BufResult, "merge-buf-result")
+		mv.BufTheirs = &TextBuf{}
+		mv.BufTheirs.InitName(mv.BufTheirs, "merge-buf-theirs")
+		mv.BufResult.TextBufSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv := recv.Embed(KiT_MergeView).(*MergeView)
+			switch TextBufSignals(sig) {
+			case TextBufInsert, TextBufDelete:
+				tbe, _ := data.(*textbuf.Edit)
+				mvv.ResultEdited(tbe)
+			}
+		})
+	}
+	for _, bf := range []*TextBuf{mv.BufOurs, mv.BufResult, mv.BufTheirs} {
+		bf.Filename = gi.FileName(mv.File)
+		bf.Opts.LineNos = true
+		bf.Stat() // update markup
+	}
+	lay.Lay = gi.LayoutHoriz
+	lay.SetStretchMax()
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Layout, "text-ours-lay")
+	config.Add(gi.KiT_Layout, "text-result-lay")
+	config.Add(gi.KiT_Layout, "text-theirs-lay")
+	mods, updt := lay.ConfigChildren(config, ki.UniqueNames)
+	if !mods {
+		updt = lay.UpdateStart()
+	} else {
+		lbls := []string{"Ours", "Result", "Theirs"}
+		nms := []string{"text-ours", "text-result", "text-theirs"}
+		bufs := []*TextBuf{mv.BufOurs, mv.BufResult, mv.BufTheirs}
+		for i, tl := range []*gi.Layout{lay.Child(0).(*gi.Layout), lay.Child(1).(*gi.Layout), lay.Child(2).(*gi.Layout)} {
+			tl.Lay = gi.LayoutVert
+			tl.SetStretchMax()
+			tl.SetMinPrefWidth(units.NewCh(60))
+			tl.SetMinPrefHeight(units.NewEm(40))
+			lbl := lbls[i]
+			switch i {
+			case 0:
+				if mv.LabelOurs != "" {
+					lbl += ": " + mv.LabelOurs
+				}
+			case 2:
+				if mv.LabelTheirs != "" {
+					lbl += ": " + mv.LabelTheirs
+				}
+			}
+			gi.AddNewLabel(tl, "label", lbl)
+			tv := AddNewMergeTextView(tl, nms[i])
+			tv.SetProp("font-family", gi.Prefs.MonoFont)
+			if i != 1 {
+				tv.SetInactive()
+			}
+			tv.SetBuf(bufs[i])
+		}
+	}
+	lay.UpdateEnd(updt)
+}
+
+func (mv *MergeView) IsConfiged() bool {
+	if mv.NumChildren() > 0 && mv.BufResult != nil {
+		return true
+	}
+	return false
+}
+
+// MergeViewProps are style properties for MergeView
+var MergeViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"max-width":        -1,
+	"max-height":       -1,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"CallMethods": ki.PropSlice{
+		{"SaveFile", ki.Props{
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"default-field": "File",
+				}},
+			},
+		}},
+	},
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//   MergeTextView
+
+// MergeTextView supports double-click on the line numbers of ours or theirs
+// to use that version for the conflict in the result.
+type MergeTextView struct {
+	TextView
+}
+
+var KiT_MergeTextView = kit.Types.AddType(&MergeTextView{}, TextViewProps)
+
+// AddNewMergeTextView adds a new MergeTextView to given parent node, with given name.
+func AddNewMergeTextView(parent ki.Ki, name string) *MergeTextView {
+	return parent.AddNewChild(KiT_MergeTextView, name).(*MergeTextView)
+}
+
+func (tv *MergeTextView) MergeView() *MergeView {
+	mvi := tv.ParentByType(KiT_MergeView, ki.NoEmbeds)
+	if mvi == nil {
+		return nil
+	}
+	return mvi.(*MergeView)
+}
+
+// MouseEvent handles the mouse.Event to process double-click
+func (tv *MergeTextView) MouseEvent(me *mouse.Event) {
+	if me.Button != mouse.Left || me.Action != mouse.DoubleClick || tv.Nm == "text-result" {
+		tv.TextView.MouseEvent(me)
+		return
+	}
+	pt := tv.PointToRelPos(me.Pos())
+	if pt.X >= 0 && pt.X < int(tv.LineNoOff) {
+		newPos := tv.PixelToCursor(pt)
+		mv := tv.MergeView()
+		if mv != nil && tv.Buf != nil {
+			if tv.Nm == "text-ours" {
+				mv.ChooseHunk(mv.HunkForLine(0, newPos.Ln), MergeOurs)
+			} else {
+				mv.ChooseHunk(mv.HunkForLine(2, newPos.Ln), MergeTheirs)
+			}
+		}
+		me.SetProcessed()
+		return
+	}
+	tv.TextView.MouseEvent(me)
+}
+
+// TextViewEvents sets connections between mouse and key events and actions
+func (tv *MergeTextView) TextViewEvents() {
+	tv.HoverTooltipEvent()
+	tv.MouseMoveEvent()
+	tv.MouseDragEvent()
+	tv.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		txf := recv.Embed(KiT_MergeTextView).(*MergeTextView)
+		me := d.(*mouse.Event)
+		txf.MouseEvent(me) // gets our new one
+	})
+	tv.MouseFocusEvent()
+	tv.ConnectEvent(oswin.KeyChordEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		txf := recv.Embed(KiT_TextView).(*TextView)
+		kt := d.(*key.ChordEvent)
+		txf.KeyInput(kt)
+	})
+}
+
+// ConnectEvents2D indirectly sets connections between mouse and key events and actions
+func (tv *MergeTextView) ConnectEvents2D() {
+	tv.TextViewEvents()
+}
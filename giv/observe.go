@@ -0,0 +1,585 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/cmplx"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Observed values provide automatic updating of views: a Go value (a
+// struct, a field within a struct, a slice or a map, given by pointer) is
+// marked as observed with Observe, and then whenever it changes, all the
+// views showing it, in all windows, are updated: the ValueViews of
+// StructView fields that overlap the changed memory, and any SliceView,
+// TableView, MapView, MatrixView or Plot2D showing it.
+//
+// Changes are signaled either explicitly, by calling ObservedChanged or
+// ObservedSet, or found by ObservePoll, which periodically compares each
+// observed value against a copy.  Updates are debounced, so that many
+// changes in quick succession (e.g., from a running simulation) only update
+// the views once per Debounce interval, and the views are always updated on
+// the main thread, via oswin.TheApp.RunOnMain -- ObservedChanged and
+// ObservedSet can thus be called from any goroutine.  Note that the values
+// themselves are not protected from concurrent access: a worker goroutine
+// should only change them in ways that are safe to be read concurrently
+// (e.g., not appending to a slice being viewed), or do the changes on the
+// main thread via RunOnMain.
+
+// ObserveDebounce is the default debounce interval for observed values --
+// views are updated at most once per interval
+var ObserveDebounce = 50 * time.Millisecond
+
+// ObserveTrace prints a trace of observed value updates
+var ObserveTrace = false
+
+// Observed is a Go value that is being observed for changes, with all the
+// views showing it updated when it changes.  See Observe.
+type Observed struct {
+	Ptr      interface{}   `desc:"pointer to the observed value"`
+	Debounce time.Duration `desc:"minimum interval between updates of views when the value changes -- defaults to ObserveDebounce"`
+	NoPoll   bool          `desc:"if true, the value is not checked for changes by ObservePoll -- changes must be signaled with ObservedChanged or ObservedSet"`
+	Snap     reflect.Value `view:"-" desc:"copy of the value as of the last update, for polling"`
+	Pending  bool          `view:"-" desc:"true if an update of views is pending"`
+	Last     time.Time     `view:"-" desc:"time of the last update of views"`
+	Mu       sync.Mutex    `view:"-" desc:"mutex protecting updating state"`
+}
+
+// ObservedKey is the key for observed values: the address and type of the
+// value -- a struct and its first field have the same address
+type ObservedKey struct {
+	Addr uintptr
+	Type reflect.Type
+}
+
+// ObservedMap is the registry of observed values
+type ObservedMap struct {
+	Vals map[ObservedKey]*Observed `desc:"observed values, by address and type"`
+	Mu   sync.Mutex                `desc:"mutex protecting map"`
+	poll chan bool
+}
+
+// TheObserved is the registry of all observed values
+var TheObserved = ObservedMap{Vals: make(map[ObservedKey]*Observed)}
+
+// observeKey returns the key for given pointer, with a 0 Addr if not a
+// non-nil pointer
+func observeKey(ptr interface{}) ObservedKey {
+	if kit.IfaceIsNil(ptr) {
+		return ObservedKey{}
+	}
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr {
+		return ObservedKey{}
+	}
+	return ObservedKey{v.Pointer(), v.Type()}
+}
+
+// Observe marks the value at given pointer (to a struct, a field of a
+// struct, a slice or a map, etc) as observed, so that when it changes, all
+// views showing it are updated.  Returns the Observed record, which can be
+// used to set the Debounce interval, etc, and returns the existing record
+// if already observed.  Returns nil (and logs an error) if not a pointer.
+func Observe(ptr interface{}) *Observed {
+	key := observeKey(ptr)
+	if key.Addr == 0 {
+		log.Printf("giv.Observe: value must be a non-nil pointer, is: %T\n", ptr)
+		return nil
+	}
+	om := &TheObserved
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	if ob, has := om.Vals[key]; has {
+		return ob
+	}
+	ob := &Observed{Ptr: ptr, Debounce: ObserveDebounce}
+	ob.Snap = ObserveCopy(reflect.ValueOf(ptr).Elem(), 1)
+	om.Vals[key] = ob
+	return ob
+}
+
+// Unobserve stops observing the value at given pointer
+func Unobserve(ptr interface{}) {
+	om := &TheObserved
+	om.Mu.Lock()
+	delete(om.Vals, observeKey(ptr))
+	om.Mu.Unlock()
+}
+
+// ObservedFor returns the Observed record that contains the memory at
+// given pointer -- the value itself, or a struct, slice or array that
+// contains it (e.g., for a pointer to a field of an observed struct), or
+// nil if none
+func ObservedFor(ptr interface{}) *Observed {
+	key := observeKey(ptr)
+	if key.Addr == 0 {
+		return nil
+	}
+	om := &TheObserved
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	if ob, has := om.Vals[key]; has {
+		return ob
+	}
+	rng := ObserveRangeOf(reflect.ValueOf(ptr))
+	for _, ob := range om.Vals {
+		for _, or := range ob.Ranges() {
+			if or.Contains(rng) {
+				return ob
+			}
+		}
+	}
+	return nil
+}
+
+// ObservedChanged signals that the value at given pointer has changed:
+// all the views showing it are updated, on the main thread, after the
+// Debounce interval of the Observed value that contains it.  The pointer
+// need not be within an observed value, in which case just the views
+// showing it are updated, without debouncing.  Safe to call from any
+// goroutine.
+func ObservedChanged(ptr interface{}) {
+	ob := ObservedFor(ptr)
+	if ob == nil {
+		ob = &Observed{Ptr: ptr, Debounce: ObserveDebounce, NoPoll: true}
+	}
+	ob.Changed()
+}
+
+// ObservedSet sets the value at given pointer to given value, converting
+// as needed (see kit.SetRobust), and signals that it has changed, so all
+// views showing it are updated.  Returns false if the value could not be
+// set.  Safe to call from any goroutine -- see Observed for notes on
+// concurrent access to the value.
+func ObservedSet(ptr, val interface{}) bool {
+	if !kit.SetRobust(ptr, val) {
+		log.Printf("giv.ObservedSet: could not set value of type: %T to: %v\n", ptr, val)
+		return false
+	}
+	ObservedChanged(ptr)
+	return true
+}
+
+// Changed signals that the observed value has changed: all the views
+// showing it are updated on the main thread, after the Debounce interval
+// -- further changes within that interval are included in the same update.
+// Safe to call from any goroutine.
+func (ob *Observed) Changed() {
+	ob.Mu.Lock()
+	if ob.Pending {
+		ob.Mu.Unlock()
+		return
+	}
+	ob.Pending = true
+	wait := ob.Debounce - time.Since(ob.Last)
+	if wait < 0 {
+		wait = 0
+	}
+	ob.Mu.Unlock()
+	time.AfterFunc(wait, func() { // always on another goroutine, so RunOnMain is safe
+		if oswin.TheApp == nil {
+			ob.Update()
+		} else {
+			oswin.TheApp.RunOnMain(ob.Update)
+		}
+	})
+}
+
+// Update updates all the views showing the observed value, in all windows,
+// and updates the snapshot used for polling.  Must be called on the main
+// thread -- use Changed to update from other goroutines.
+func (ob *Observed) Update() {
+	ob.Mu.Lock()
+	ob.Pending = false
+	ob.Last = time.Now()
+	ob.Mu.Unlock()
+	if !ob.NoPoll {
+		ob.Snap = ObserveCopy(reflect.ValueOf(ob.Ptr).Elem(), 1)
+	}
+	rngs := ob.Ranges()
+	if ObserveTrace {
+		fmt.Printf("giv.Observed: updating views of: %T at: %v\n", ob.Ptr, rngs)
+	}
+	for _, w := range gi.AllWindows {
+		if w.Viewport == nil || w.IsClosed() {
+			continue
+		}
+		ObserveUpdateViews(w.Viewport, rngs)
+	}
+}
+
+// Ranges returns the memory ranges occupied by the observed value
+func (ob *Observed) Ranges() []ObserveRange {
+	return ObserveRanges(reflect.ValueOf(ob.Ptr))
+}
+
+// IsChanged returns true if the value differs from the copy made at the
+// last update (or when first observed)
+func (ob *Observed) IsChanged() bool {
+	if ob.NoPoll || !ob.Snap.IsValid() {
+		return false
+	}
+	return !ObserveEqual(reflect.ValueOf(ob.Ptr).Elem(), ob.Snap)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Polling
+
+// ObservePoll starts polling all the observed values (except those with
+// NoPoll) for changes every interval, on the main thread, updating the
+// views of those that have changed.  This allows views to track values
+// that are changed without calling ObservedChanged, e.g., by a simulation
+// running in another goroutine.  Values are compared to a copy made at
+// the last update, following pointers one level (e.g., the elements of a
+// slice of pointers to structs are compared by value).  Calling again
+// changes the interval.  Stop polling with ObservePollStop.
+func ObservePoll(interval time.Duration) {
+	ObservePollStop()
+	om := &TheObserved
+	stop := make(chan bool)
+	om.Mu.Lock()
+	om.poll = stop
+	om.Mu.Unlock()
+	go func() {
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				if oswin.TheApp == nil {
+					om.PollChanges()
+				} else {
+					oswin.TheApp.RunOnMain(func() { om.PollChanges() })
+				}
+			}
+		}
+	}()
+}
+
+// ObservePollStop stops polling started by ObservePoll
+func ObservePollStop() {
+	om := &TheObserved
+	om.Mu.Lock()
+	if om.poll != nil {
+		close(om.poll)
+		om.poll = nil
+	}
+	om.Mu.Unlock()
+}
+
+// PollChanges checks all the observed values for changes, and signals
+// Changed on those that have changed
+func (om *ObservedMap) PollChanges() {
+	om.Mu.Lock()
+	obs := make([]*Observed, 0, len(om.Vals))
+	for _, ob := range om.Vals {
+		obs = append(obs, ob)
+	}
+	om.Mu.Unlock()
+	for _, ob := range obs {
+		ob.Mu.Lock()
+		pend := ob.Pending
+		ob.Mu.Unlock()
+		if !pend && ob.IsChanged() {
+			ob.Changed()
+		}
+	}
+}
+
+// ObserveCopy returns a copy of given value that is not affected by
+// subsequent changes to it: slices, maps and arrays are copied, and
+// pointers are followed up to depth levels, recursively, for comparison
+// with ObserveEqual.  Struct fields are copied for exported fields.
+func ObserveCopy(v reflect.Value, depth int) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || depth <= 0 {
+			return v
+		}
+		nv := reflect.New(v.Type().Elem())
+		nv.Elem().Set(ObserveCopy(v.Elem(), depth-1))
+		return nv
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		nv := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			nv.Index(i).Set(ObserveCopy(v.Index(i), depth))
+		}
+		return nv
+	case reflect.Array:
+		nv := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			nv.Index(i).Set(ObserveCopy(v.Index(i), depth))
+		}
+		return nv
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		nv := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			nv.SetMapIndex(k, ObserveCopy(v.MapIndex(k), depth))
+		}
+		return nv
+	case reflect.Struct:
+		nv := reflect.New(v.Type()).Elem()
+		nv.Set(v) // includes unexported fields
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" { // unexported
+				continue
+			}
+			f := v.Field(i)
+			switch f.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+				nv.Field(i).Set(ObserveCopy(f, depth))
+			}
+		}
+		return nv
+	}
+	return v
+}
+
+// ObserveEqual returns true if the values are equal, as for
+// reflect.DeepEqual, except that floating point NaN values are equal to
+// each other -- otherwise values containing a NaN would always be changed
+func ObserveEqual(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		af, bf := a.Float(), b.Float()
+		return af == bf || (math.IsNaN(af) && math.IsNaN(bf))
+	case reflect.Complex64, reflect.Complex128:
+		ac, bc := a.Complex(), b.Complex()
+		return ac == bc || (cmplx.IsNaN(ac) && cmplx.IsNaN(bc))
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Chan, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	case reflect.Func:
+		return a.IsNil() && b.IsNil() // as in DeepEqual
+	case reflect.Ptr:
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		if a.IsNil() || b.IsNil() {
+			return false
+		}
+		return ObserveEqual(a.Elem(), b.Elem())
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return ObserveEqual(a.Elem(), b.Elem())
+	case reflect.Slice:
+		if a.IsNil() != b.IsNil() || a.Len() != b.Len() {
+			return false
+		}
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if !ObserveEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() || a.Len() != b.Len() {
+			return false
+		}
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		for _, k := range a.MapKeys() {
+			bv := b.MapIndex(k)
+			if !bv.IsValid() || !ObserveEqual(a.MapIndex(k), bv) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !ObserveEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Memory ranges and updating views
+
+// ObserveRange is a range of memory addresses occupied by a value, used to
+// find the views that show a changed value
+type ObserveRange struct {
+	St uintptr `desc:"starting address"`
+	Ed uintptr `desc:"ending address, exclusive"`
+}
+
+// Overlaps returns true if the ranges overlap
+func (or ObserveRange) Overlaps(o ObserveRange) bool {
+	return or.St < o.Ed && o.St < or.Ed
+}
+
+// Contains returns true if given range is within this one
+func (or ObserveRange) Contains(o ObserveRange) bool {
+	return o.St >= or.St && o.Ed <= or.Ed
+}
+
+// ObserveRangeOf returns the memory range of the value at given pointer
+// value -- the range is at least one byte, even for empty types
+func ObserveRangeOf(pv reflect.Value) ObserveRange {
+	if pv.Kind() != reflect.Ptr || pv.IsNil() {
+		return ObserveRange{}
+	}
+	st := pv.Pointer()
+	sz := pv.Type().Elem().Size()
+	if sz == 0 {
+		sz = 1
+	}
+	return ObserveRange{st, st + sz}
+}
+
+// ObserveRanges returns the memory ranges for the value at given pointer
+// value, or slice value: the value itself, and for slices, the backing
+// array of the slice, and the values pointed to by its elements if they
+// are pointers (e.g., for a TableView of a slice of pointers to structs)
+func ObserveRanges(v reflect.Value) []ObserveRange {
+	var rngs []ObserveRange
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		rngs = append(rngs, ObserveRangeOf(v))
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice || v.Len() == 0 {
+		return rngs
+	}
+	st := v.Pointer()
+	rngs = append(rngs, ObserveRange{st, st + uintptr(v.Len())*v.Type().Elem().Size()})
+	switch v.Type().Elem().Kind() {
+	case reflect.Ptr:
+		for i := 0; i < v.Len(); i++ {
+			if r := ObserveRangeOf(v.Index(i)); r.Ed > 0 {
+				rngs = append(rngs, r)
+			}
+		}
+	case reflect.Slice: // e.g., [][]float32 rows
+		for i := 0; i < v.Len(); i++ {
+			if ev := v.Index(i); ev.Len() > 0 {
+				est := ev.Pointer()
+				rngs = append(rngs, ObserveRange{est, est + uintptr(ev.Len())*ev.Type().Elem().Size()})
+			}
+		}
+	}
+	return rngs
+}
+
+// observeOverlaps returns true if any of the ranges overlap
+func observeOverlaps(a, b []ObserveRange) bool {
+	for _, ar := range a {
+		for _, br := range b {
+			if ar.Overlaps(br) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ObserveUpdateViews updates all the views within given viewport that show
+// values within any of the given memory ranges
+func ObserveUpdateViews(vp *gi.Viewport2D, rngs []ObserveRange) {
+	vp.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		switch vw := k.(type) {
+		case *StructView:
+			observeUpdateFields(vw.Struct, vw.FieldViews, rngs)
+		case *StructViewInline:
+			observeUpdateFields(vw.Struct, vw.FieldViews, rngs)
+		case *MapView:
+			if observeOverlaps(ObserveRanges(reflect.ValueOf(vw.Map)), rngs) {
+				vw.UpdateValues()
+				return ki.Break // rebuilt
+			}
+		case *MapViewInline:
+			if observeOverlaps(ObserveRanges(reflect.ValueOf(vw.Map)), rngs) {
+				vw.UpdateValues()
+				return ki.Break
+			}
+		case *SliceViewInline:
+			if observeOverlaps(ObserveRanges(reflect.ValueOf(vw.Slice)), rngs) {
+				vw.UpdateValues()
+				return ki.Break
+			}
+		case SliceViewer:
+			svb := vw.AsSliceViewBase()
+			if observeOverlaps(ObserveRanges(reflect.ValueOf(svb.Slice)), rngs) {
+				svb.Update()
+				return ki.Break
+			}
+		case *MatrixView:
+			if observeOverlaps(ObserveRanges(reflect.ValueOf(vw.Matrix)), rngs) {
+				vw.UpdateValues()
+			}
+			return ki.Break
+		case *Plot2D:
+			for _, ps := range vw.Series {
+				if observeOverlaps(ObserveRanges(reflect.ValueOf(ps.X)), rngs) || observeOverlaps(ObserveRanges(reflect.ValueOf(ps.Y)), rngs) || observeOverlaps(ObserveRanges(reflect.ValueOf(ps.C)), rngs) {
+					vw.Update()
+					break
+				}
+			}
+			return ki.Break
+		}
+		return ki.Continue
+	})
+}
+
+// observeUpdateFields updates the field value views of given struct that
+// show values within given memory ranges
+func observeUpdateFields(stru interface{}, fvs []ValueView, rngs []ObserveRange) {
+	if kit.IfaceIsNil(stru) || !observeOverlaps(ObserveRanges(reflect.ValueOf(stru)), rngs) {
+		return
+	}
+	for _, vv := range fvs {
+		vvb := vv.AsValueViewBase()
+		if vvb.Value.Kind() != reflect.Ptr || observeOverlaps(ObserveRanges(vvb.Value), rngs) {
+			vv.UpdateWidget()
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type observeTest struct {
+	Name  string
+	Val   float64
+	Vals  []float32
+	Sub   *observeTest
+	Props map[string]int
+}
+
+func TestObserveEqual(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		a, b interface{}
+		eq   bool
+	}{
+		{1.0, 1.0, true},
+		{1.0, 2.0, false},
+		{nan, nan, true},
+		{nan, 1.0, false},
+		{[]float64{1, nan}, []float64{1, nan}, true},
+		{[]float64{1, nan}, []float64{1, 2}, false},
+		{[]float64{1}, []float64{1, 2}, false},
+		{[]float64(nil), []float64{}, false},
+		{map[string]float64{"a": nan}, map[string]float64{"a": nan}, true},
+		{map[string]float64{"a": 1}, map[string]float64{"b": 1}, false},
+		{observeTest{Name: "a", Val: nan}, observeTest{Name: "a", Val: nan}, true},
+		{observeTest{Name: "a", Val: nan}, observeTest{Name: "b", Val: nan}, false},
+		{&observeTest{Val: nan}, &observeTest{Val: nan}, true},
+		{"a", "a", true},
+		{[2]int{1, 2}, [2]int{1, 3}, false},
+	}
+	for _, tst := range tests {
+		if eq := ObserveEqual(reflect.ValueOf(tst.a), reflect.ValueOf(tst.b)); eq != tst.eq {
+			t.Errorf("ObserveEqual(%v, %v) = %v, expected %v", tst.a, tst.b, eq, tst.eq)
+		}
+	}
+}
+
+func TestObserveCopy(t *testing.T) {
+	sub := &observeTest{Name: "sub", Val: 1}
+	ot := &observeTest{Name: "top", Val: 2, Vals: []float32{1, 2}, Sub: sub, Props: map[string]int{"a": 1}}
+	cp := ObserveCopy(reflect.ValueOf(ot), 2)
+	if !ObserveEqual(reflect.ValueOf(ot), cp) {
+		t.Fatalf("copy not equal to original")
+	}
+	ot.Vals[0] = 10
+	if ObserveEqual(reflect.ValueOf(ot), cp) {
+		t.Errorf("change to slice element changed copy")
+	}
+	ot.Vals[0] = 1
+	ot.Props["a"] = 2
+	if ObserveEqual(reflect.ValueOf(ot), cp) {
+		t.Errorf("change to map changed copy")
+	}
+	ot.Props["a"] = 1
+	sub.Val = 5
+	if ObserveEqual(reflect.ValueOf(ot), cp) {
+		t.Errorf("change to value pointed to changed copy")
+	}
+	// beyond depth, pointed-to values are shared
+	cp = ObserveCopy(reflect.ValueOf(ot).Elem(), 0)
+	sub.Val = 6
+	if !ObserveEqual(reflect.ValueOf(ot).Elem(), cp) {
+		t.Errorf("pointer copied beyond depth")
+	}
+}
+
+func TestObserveRanges(t *testing.T) {
+	ot := &observeTest{Vals: []float32{1, 2, 3}}
+	top := ObserveRanges(reflect.ValueOf(ot))
+	if len(top) != 1 || top[0].Ed-top[0].St != reflect.TypeOf(*ot).Size() {
+		t.Fatalf("wrong ranges for struct: %v", top)
+	}
+	fld := ObserveRangeOf(reflect.ValueOf(&ot.Val))
+	if !top[0].Contains(fld) {
+		t.Errorf("struct range %v does not contain field %v", top[0], fld)
+	}
+	vals := ObserveRanges(reflect.ValueOf(&ot.Vals))
+	if len(vals) != 2 || vals[1].Ed-vals[1].St != 3*4 {
+		t.Fatalf("wrong ranges for slice: %v", vals)
+	}
+	if !vals[1].Contains(ObserveRangeOf(reflect.ValueOf(&ot.Vals[2]))) || vals[1].Overlaps(top[0]) {
+		t.Errorf("slice range %v wrong for elements", vals[1])
+	}
+	ps := []*observeTest{{Val: 1}, nil, {Val: 2}}
+	prs := ObserveRanges(reflect.ValueOf(ps))
+	if len(prs) != 3 || !prs[2].Contains(ObserveRangeOf(reflect.ValueOf(&ps[2].Val))) {
+		t.Errorf("wrong ranges for slice of pointers: %v", prs)
+	}
+}
+
+// waitObserved waits for any pending update of the observed value
+func waitObserved(t *testing.T, ob *Observed) {
+	for i := 0; i < 100; i++ {
+		ob.Mu.Lock()
+		pend := ob.Pending
+		ob.Mu.Unlock()
+		if !pend {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("observed update still pending")
+}
+
+func TestPollChanges(t *testing.T) {
+	ot := &observeTest{Val: math.NaN(), Vals: []float32{float32(math.NaN())}}
+	ob := Observe(ot)
+	defer Unobserve(ot)
+	ob.Debounce = 0
+	last := ob.Last
+	TheObserved.PollChanges()
+	waitObserved(t, ob)
+	if ob.Last != last {
+		t.Errorf("unchanged value with NaN was updated")
+	}
+	ot.Vals[0] = 1
+	if !ob.IsChanged() {
+		t.Fatalf("change not found")
+	}
+	TheObserved.PollChanges()
+	waitObserved(t, ob)
+	if ob.Last == last {
+		t.Errorf("changed value was not updated")
+	}
+	if ob.IsChanged() {
+		t.Errorf("value still changed after update")
+	}
+}
@@ -11,18 +11,23 @@ import (
 	"sync"
 	"time"
 
-	"github.com/goki/ki/sliceclone"
+	"github.com/goki/pi/lex"
 )
 
 // OutBufMarkupFunc is a function that returns a marked-up version of a given line of
 // output text by adding html tags.  It is essential that it ONLY adds tags,
-// and otherwise has the exact same visible bytes as the input
+// and otherwise has the exact same visible bytes as the input.  The input
+// is the html escaped text with any ANSI escape codes removed -- the
+// formatting from those codes is added after, within the added tags.
 type OutBufMarkupFunc func(line []byte) []byte
 
 // OutBuf is a TextBuf that records the output from an io.Reader using
 // bufio.Scanner -- optimized to combine fast chunks of output into
-// large blocks of updating.  Also supports arbitrary markup function
-// that operates on each line of output bytes.
+// large blocks of updating.  ANSI escape sequences are removed from the
+// text, with color and style codes converted into html markup, and lines
+// ending in a carriage return (e.g., progress output) are overwritten by
+// the next line.  Also supports arbitrary markup function that operates
+// on each line of output bytes.
 type OutBuf struct {
 	Out        io.Reader        `desc:"the output that we are reading from, as an io.Reader"`
 	Buf        *TextBuf         `desc:"the TextBuf that we output to"`
@@ -33,6 +38,8 @@ type OutBuf struct {
 	Mu         sync.Mutex       `desc:"mutex protecting updating of CurOutLns and Buf, and timer"`
 	LastOut    time.Time        `desc:"time when last output was sent to buffer"`
 	AfterTimer *time.Timer      `desc:"time.AfterFunc that is started after new input is received and not immediately output -- ensures that it will get output if no further burst happens"`
+	ANSI       ANSIState        `desc:"current ANSI formatting state, which carries across lines"`
+	LastCR     bool             `desc:"true if the last line ended in a carriage return, so the next line overwrites it"`
 }
 
 // Init sets the various params and prepares for running
@@ -50,24 +57,36 @@ func (ob *OutBuf) Init(out io.Reader, buf *TextBuf, batchMSec int, markup OutBuf
 // MonOut monitors the output and updates the TextBuf
 func (ob *OutBuf) MonOut() {
 	outscan := bufio.NewScanner(ob.Out) // line at a time
+	outscan.Split(ScanLinesCR)
 	ob.CurOutLns = make([][]byte, 0, 100)
 	ob.CurOutMus = make([][]byte, 0, 100)
+	ob.ANSI.Reset()
+	ob.LastCR = false
 	for outscan.Scan() {
 		b := outscan.Bytes()
-		bc := sliceclone.Byte(b) // outscan bytes are temp
-		bec := HTMLEscapeBytes(bc)
+		cr := false
+		switch {
+		case bytes.HasSuffix(b, []byte("\r\n")):
+			b = b[:len(b)-2]
+		case bytes.HasSuffix(b, []byte("\n")):
+			b = b[:len(b)-1]
+		case bytes.HasSuffix(b, []byte("\r")):
+			b = b[:len(b)-1]
+			cr = true
+		}
+		bc, runs := ANSIToRuns(b, &ob.ANSI) // copies -- outscan bytes are temp
+		mup := HTMLEscapeBytes(bc)
+		if ob.MarkupFun != nil {
+			mup = ob.MarkupFun(mup)
+		}
+		mup = ANSIMarkup(mup, runs)
 
 		ob.Mu.Lock()
 		if ob.AfterTimer != nil {
 			ob.AfterTimer.Stop()
 			ob.AfterTimer = nil
 		}
-		ob.CurOutLns = append(ob.CurOutLns, bc)
-		mup := bec
-		if ob.MarkupFun != nil {
-			mup = ob.MarkupFun(bec)
-		}
-		ob.CurOutMus = append(ob.CurOutMus, mup)
+		ob.AddLine(bc, mup, cr)
 		now := time.Now()
 		lag := int(now.Sub(ob.LastOut) / time.Millisecond)
 		if lag > ob.BatchMSec {
@@ -87,6 +106,65 @@ func (ob *OutBuf) MonOut() {
 	ob.OutToBuf()
 }
 
+// AddLine adds given line of output text and markup to the current
+// buffered output, overwriting the last line if it ended in a carriage
+// return.  cr indicates if this line ends in a carriage return.
+// MUST be called under mutex protection
+func (ob *OutBuf) AddLine(txt, mup []byte, cr bool) {
+	over := ob.LastCR
+	ob.LastCR = cr
+	if over && len(txt) == 0 { // nothing to overwrite with, e.g., \r\r or only escape codes
+		return
+	}
+	if !over {
+		ob.CurOutLns = append(ob.CurOutLns, txt)
+		ob.CurOutMus = append(ob.CurOutMus, mup)
+		return
+	}
+	if nl := len(ob.CurOutLns); nl > 0 {
+		ob.CurOutLns[nl-1] = txt
+		ob.CurOutMus[nl-1] = mup
+		return
+	}
+	if nln := ob.Buf.NumLines(); nln > 1 { // last line is already in Buf
+		ob.Buf.Undos.Off = true
+		ob.Buf.DeleteText(lex.Pos{Ln: nln - 2}, lex.Pos{Ln: nln - 1}, EditSignal)
+	}
+	ob.CurOutLns = append(ob.CurOutLns, txt)
+	ob.CurOutMus = append(ob.CurOutMus, mup)
+}
+
+// ScanLinesCR is a bufio.SplitFunc that splits lines ending in a newline
+// or a carriage return, returning each line with its line ending, so that
+// carriage returns used for overwriting progress output can be detected.
+// A carriage return followed by a newline is a single line ending.
+func ScanLinesCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	for i, c := range data {
+		switch c {
+		case '\n':
+			return i + 1, data[:i+1], nil
+		case '\r':
+			if i+1 < len(data) {
+				if data[i+1] == '\n' {
+					return i + 2, data[:i+2], nil
+				}
+				return i + 1, data[:i+1], nil
+			}
+			if atEOF {
+				return i + 1, data[:i+1], nil
+			}
+			return 0, nil, nil // need to see if next is \n
+		}
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
 // OutToBuf sends the current output to TextBuf
 // MUST be called under mutex protection
 func (ob *OutBuf) OutToBuf() {
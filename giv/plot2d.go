@@ -0,0 +1,1228 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// PlotTypes are the types of plots for a PlotSeries
+type PlotTypes int32
+
+const (
+	// PlotLine draws lines connecting the points, with optional point markers
+	PlotLine PlotTypes = iota
+
+	// PlotScatter draws point markers only, optionally colored by the C values
+	PlotScatter
+
+	// PlotBar draws bars from 0 to each Y value, at each X value -- multiple
+	// bar series are drawn side-by-side
+	PlotBar
+
+	// PlotHist draws a histogram of the Y values, with Bins bins
+	PlotHist
+
+	PlotTypesN
+)
+
+//go:generate stringer -type=PlotTypes
+
+var KiT_PlotTypes = kit.Enums.AddEnum(PlotTypesN, kit.NotBitFlag, nil)
+
+func (ev PlotTypes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *PlotTypes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// PlotSeries is one series of data in a Plot2D.  X, Y and C are Go slices
+// of numbers (e.g., []float64, []int), or, if the corresponding XField,
+// YField, CField is set, slices of structs (or pointers to them, or a
+// pointer to such a slice, e.g., the Slice of a TableView), from which the
+// named field is plotted.  The values are read from the slices each time the
+// plot is rendered, so they stay in sync with the data.
+type PlotSeries struct {
+	Name      string      `desc:"name of the series, shown in the legend"`
+	Type      PlotTypes   `desc:"type of plot"`
+	X         interface{} `view:"-" desc:"X values -- if nil, the index of each Y value is used"`
+	XField    string      `desc:"if X is a slice of structs, the name of the field to plot"`
+	Y         interface{} `view:"-" desc:"Y values (or, for PlotHist, the values to compute the histogram of)"`
+	YField    string      `desc:"if Y is a slice of structs, the name of the field to plot"`
+	C         interface{} `view:"-" desc:"optional values mapped onto point colors using the ColorMap of the plot, for PlotLine and PlotScatter"`
+	CField    string      `desc:"if C is a slice of structs, the name of the field to use"`
+	Color     gi.Color    `desc:"color of the series -- if nil (transparent), a color is assigned from the ColorMap of the plot"`
+	Width     float32     `min:"0" desc:"width of lines, in px"`
+	PointSize float32     `min:"0" desc:"radius of point markers, in px -- 0 = no markers for PlotLine"`
+	Bins      int         `min:"1" desc:"number of bins for PlotHist"`
+	Off       bool        `desc:"if true, the series is not plotted -- toggled by clicking on the legend"`
+}
+
+// Values returns the X, Y values for the series, using the index of Y
+// values if X is nil.  For PlotHist, returns the bin centers and counts.
+func (ps *PlotSeries) Values() (xs, ys []float64) {
+	ys = PlotFloats(ps.Y, ps.YField)
+	if ps.Type == PlotHist {
+		return ps.Hist(ys)
+	}
+	if ps.X != nil {
+		xs = PlotFloats(ps.X, ps.XField)
+	}
+	if len(xs) < len(ys) {
+		for i := len(xs); i < len(ys); i++ {
+			xs = append(xs, float64(i))
+		}
+	}
+	return xs[:len(ys)], ys
+}
+
+// Hist returns the bin centers and counts of the histogram of given values
+func (ps *PlotSeries) Hist(vals []float64) (ctrs, cnts []float64) {
+	nb := ps.Bins
+	if nb <= 0 {
+		nb = 20
+	}
+	mn, mx := PlotMinMax(vals, false)
+	if math.IsInf(mn, 0) {
+		return nil, nil
+	}
+	if mx == mn {
+		mn -= 0.5
+		mx += 0.5
+	}
+	bw := (mx - mn) / float64(nb)
+	ctrs = make([]float64, nb)
+	cnts = make([]float64, nb)
+	for i := range ctrs {
+		ctrs[i] = mn + (float64(i)+0.5)*bw
+	}
+	for _, v := range vals {
+		if math.IsNaN(v) {
+			continue
+		}
+		bi := int((v - mn) / bw)
+		if bi >= nb {
+			bi = nb - 1
+		}
+		if bi < 0 {
+			bi = 0
+		}
+		cnts[bi]++
+	}
+	return
+}
+
+// PlotFloats returns the values in given slice as float64 -- if field is
+// non-empty, the slice has structs (or pointers to them), and the values of
+// the named field are returned.  Values that cannot be converted are NaN.
+func PlotFloats(sl interface{}, field string) []float64 {
+	if kit.IfaceIsNil(sl) {
+		return nil
+	}
+	sv := kit.NonPtrValue(reflect.ValueOf(sl))
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		return nil
+	}
+	n := sv.Len()
+	fs := make([]float64, n)
+	for i := 0; i < n; i++ {
+		ev := kit.NonPtrValue(sv.Index(i))
+		if field != "" {
+			if ev.Kind() != reflect.Struct {
+				fs[i] = math.NaN()
+				continue
+			}
+			ev = ev.FieldByName(field)
+		}
+		if !ev.IsValid() {
+			fs[i] = math.NaN()
+			continue
+		}
+		if f, ok := kit.ToFloat(ev.Interface()); ok {
+			fs[i] = f
+		} else {
+			fs[i] = math.NaN()
+		}
+	}
+	return fs
+}
+
+// PlotMinMax returns the min and max of the given values, ignoring NaN and
+// Inf values, and also non-positive values if log is true.  Returns +Inf,
+// -Inf if there are no valid values.
+func PlotMinMax(vals []float64, log bool) (mn, mx float64) {
+	mn, mx = math.Inf(1), math.Inf(-1)
+	for _, v := range vals {
+		if math.IsNaN(v) || math.IsInf(v, 0) || (log && v <= 0) {
+			continue
+		}
+		mn = math.Min(mn, v)
+		mx = math.Max(mx, v)
+	}
+	return
+}
+
+// PlotRange is a range of values along an axis
+type PlotRange struct {
+	Min float64 `desc:"minimum value"`
+	Max float64 `desc:"maximum value"`
+}
+
+// PlotAxis has the parameters for one axis of a Plot2D
+type PlotAxis struct {
+	Label  string  `desc:"label shown along the axis"`
+	Log    bool    `desc:"use a log (base 10) scale -- non-positive values are not plotted"`
+	FixMin bool    `desc:"if true, use Min as the minimum instead of the data minimum"`
+	Min    float64 `desc:"minimum value, if FixMin"`
+	FixMax bool    `desc:"if true, use Max as the maximum instead of the data maximum"`
+	Max    float64 `desc:"maximum value, if FixMax"`
+	Ticks  int     `min:"2" desc:"approximate number of ticks"`
+}
+
+// Fwd transforms a data value into the (linear) plotting space of the axis
+func (ax *PlotAxis) Fwd(v float64) float64 {
+	if ax.Log {
+		if v <= 0 {
+			return math.NaN()
+		}
+		return math.Log10(v)
+	}
+	return v
+}
+
+// Inv transforms a plotting space value back into a data value
+func (ax *PlotAxis) Inv(v float64) float64 {
+	if ax.Log {
+		return math.Pow(10, v)
+	}
+	return v
+}
+
+// Norm returns the normalized (0-1) position of given data value within range
+func (ax *PlotAxis) Norm(v float64, rng PlotRange) float64 {
+	mn, mx := ax.Fwd(rng.Min), ax.Fwd(rng.Max)
+	if mx == mn {
+		return 0.5
+	}
+	return (ax.Fwd(v) - mn) / (mx - mn)
+}
+
+// PlotMaxTicks is the maximum number of ticks returned by PlotTicks
+var PlotMaxTicks = 100
+
+// PlotMinSpan is the minimum span of a plot range, relative to the
+// magnitude of its values (in plotting space, e.g., log10 for log axes) --
+// ranges are expanded to at least this, so that ticks and zooming stay
+// within the precision of the values
+var PlotMinSpan = 1e-9
+
+// PlotTicks returns nicely-spaced tick values within given range, about n
+// of them (at most PlotMaxTicks), and the tick spacing (in plotting space,
+// e.g., log10 for log axes)
+func PlotTicks(rng PlotRange, n int, log bool) ([]float64, float64) {
+	if n < 2 {
+		n = 2
+	}
+	if log && rng.Min > 0 && rng.Max > rng.Min {
+		lmn, lmx := math.Log10(rng.Min), math.Log10(rng.Max)
+		if lmx-lmn >= 1 {
+			step := math.Max(1, math.Ceil((lmx-lmn)/float64(n)))
+			var ts []float64
+			for e := math.Ceil(lmn/step) * step; e <= lmx+1e-9 && len(ts) < PlotMaxTicks; e += step {
+				ts = append(ts, math.Pow(10, e))
+			}
+			return ts, step
+		}
+	}
+	span := rng.Max - rng.Min
+	if span <= 0 || math.IsNaN(span) || math.IsInf(span, 0) {
+		return []float64{rng.Min}, 1
+	}
+	raw := span / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	res := raw / mag
+	var step float64
+	switch {
+	case res < 1.5:
+		step = mag
+	case res < 3:
+		step = 2 * mag
+	case res < 7:
+		step = 5 * mag
+	default:
+		step = 10 * mag
+	}
+	var ts []float64
+	for v := math.Ceil(rng.Min/step) * step; v <= rng.Max+step*1e-9 && len(ts) < PlotMaxTicks; v += step {
+		if math.Abs(v) < step*1e-9 {
+			v = 0
+		}
+		ts = append(ts, v)
+		if v+step == v { // step is below the precision of v
+			break
+		}
+	}
+	if len(ts) == 0 {
+		ts = append(ts, rng.Min)
+	}
+	return ts, step
+}
+
+// PlotTickLabel returns the label for given tick value
+func PlotTickLabel(v float64) string {
+	return strconv.FormatFloat(v, 'g', 5, 64)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Plot2D
+
+// Plot2D is a widget that plots series of data (PlotSeries) from Go
+// slices, as lines, scatter points, bars or histograms, rendered with
+// gi.Paint and gi.TextRender, with auto-ticked (optionally log) axes and a
+// legend.  The mouse wheel zooms in and out around the mouse (Shift = X
+// only, Alt = Y only), dragging pans, double-click resets the view, and
+// hovering shows the nearest data point.  Clicking on a legend entry turns
+// that series on or off.  Series without a Color get one from the ColorMap.
+// See SaveSVG and SavePNG for exporting, and SetTable for plotting columns
+// of a TableView.
+type Plot2D struct {
+	gi.WidgetBase
+	Title       string            `desc:"title shown at the top of the plot"`
+	Series      []*PlotSeries     `desc:"the data series to plot"`
+	XAxis       PlotAxis          `desc:"X axis parameters"`
+	YAxis       PlotAxis          `desc:"Y axis parameters"`
+	ColorMap    ColorMapName      `desc:"color map used for series without a Color, and for C values"`
+	NoLegend    bool              `desc:"do not show the legend"`
+	NoGrid      bool              `desc:"do not show grid lines at the ticks"`
+	XRange      PlotRange         `desc:"currently displayed range of X values -- set automatically from the data and axes unless the view has been zoomed or panned"`
+	YRange      PlotRange         `desc:"currently displayed range of Y values -- set automatically from the data and axes unless the view has been zoomed or panned"`
+	Zoomed      bool              `desc:"true if the view has been zoomed or panned -- ResetView resets to the automatic ranges"`
+	Table       *TableView        `json:"-" xml:"-" view:"-" desc:"table view that we plot columns of, if set by SetTable"`
+	HoverSeri   int               `json:"-" xml:"-" view:"-" desc:"index of series of the data point under the mouse, -1 if none"`
+	HoverIdx    int               `json:"-" xml:"-" view:"-" desc:"index of the data point under the mouse in HoverSeri"`
+	PlotBox     image.Rectangle   `json:"-" xml:"-" view:"-" desc:"data area of the plot, relative to the widget position, from the last render"`
+	LegendBoxes []image.Rectangle `json:"-" xml:"-" view:"-" desc:"boxes of the legend entries (one per series, empty if not shown), relative to the widget position, from the last render"`
+	textRender  gi.TextRender
+}
+
+var KiT_Plot2D = kit.Types.AddType(&Plot2D{}, Plot2DProps)
+
+// AddNewPlot2D adds a new plot to given parent node, with given name.
+func AddNewPlot2D(parent ki.Ki, name string) *Plot2D {
+	return parent.AddNewChild(KiT_Plot2D, name).(*Plot2D)
+}
+
+func (pl *Plot2D) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*Plot2D)
+	pl.WidgetBase.CopyFieldsFrom(&fr.WidgetBase)
+	pl.Title = fr.Title
+	pl.XAxis = fr.XAxis
+	pl.YAxis = fr.YAxis
+	pl.ColorMap = fr.ColorMap
+	pl.NoLegend = fr.NoLegend
+	pl.NoGrid = fr.NoGrid
+	pl.Series = make([]*PlotSeries, len(fr.Series))
+	for i, ps := range fr.Series {
+		cp := *ps
+		pl.Series[i] = &cp
+	}
+}
+
+var Plot2DProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"width":            units.NewEm(30),
+	"height":           units.NewEm(20),
+	"min-width":        units.NewEm(10),
+	"min-height":       units.NewEm(8),
+	"max-width":        -1,
+	"max-height":       -1,
+	"padding":          units.NewPx(2),
+	"margin":           units.NewPx(2),
+	"font-size":        "small",
+	"color":            &gi.Prefs.Colors.Font,
+	"background-color": &gi.Prefs.Colors.Background,
+	"CtxtMenu": ki.PropSlice{
+		{"ResetView", ki.Props{
+			"label": "Reset View",
+			"desc":  "reset the zoom and pan to show all the data",
+		}},
+		{"SaveSVG", ki.Props{
+			"label": "Save SVG...",
+			"desc":  "save the plot as an SVG vector graphics file",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".svg",
+				}},
+			},
+		}},
+		{"SavePNG", ki.Props{
+			"label": "Save PNG...",
+			"desc":  "save the plot as a PNG image file, as currently rendered",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".png",
+				}},
+			},
+		}},
+	},
+}
+
+// AddSeries adds a new series to the plot, with given name, type and X, Y
+// values (X can be nil to use the index of the Y values)
+func (pl *Plot2D) AddSeries(name string, typ PlotTypes, x, y interface{}) *PlotSeries {
+	ps := &PlotSeries{Name: name, Type: typ, X: x, Y: y}
+	pl.Series = append(pl.Series, ps)
+	return ps
+}
+
+// AddTableSeries adds a new series plotting the ycol field against the xcol
+// field of given slice of structs (e.g., the Slice of a TableView) -- xcol
+// can be empty to plot against the row index
+func (pl *Plot2D) AddTableSeries(slice interface{}, xcol, ycol string, typ PlotTypes) *PlotSeries {
+	ps := &PlotSeries{Name: ycol, Type: typ, Y: slice, YField: ycol}
+	if xcol != "" {
+		ps.X = slice
+		ps.XField = xcol
+	}
+	pl.Series = append(pl.Series, ps)
+	return ps
+}
+
+// SetTable sets the plot to show the ycols columns against the xcol column
+// (or row index if empty) of the slice of given TableView, as lines, and
+// updates the plot whenever the table is edited.  The axis labels are set
+// from the column names.
+func (pl *Plot2D) SetTable(tv *TableView, xcol string, ycols ...string) {
+	if pl.Table != nil {
+		pl.Table.ViewSig.Disconnect(pl.This())
+		pl.Table.SliceViewSig.Disconnect(pl.This())
+	}
+	pl.Table = tv
+	pl.Series = nil
+	for _, yc := range ycols {
+		pl.AddTableSeries(tv.Slice, xcol, yc, PlotLine)
+	}
+	pl.XAxis.Label = xcol
+	if len(ycols) == 1 {
+		pl.YAxis.Label = ycols[0]
+	}
+	tv.ViewSig.Connect(pl.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		plv := recv.Embed(KiT_Plot2D).(*Plot2D)
+		plv.Update()
+	})
+	tv.SliceViewSig.Connect(pl.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(SliceViewInserted) || sig == int64(SliceViewDeleted) {
+			plv := recv.Embed(KiT_Plot2D).(*Plot2D)
+			plv.Update()
+		}
+	})
+	pl.Update()
+}
+
+func (pl *Plot2D) Disconnect() {
+	pl.WidgetBase.Disconnect()
+	if pl.Table != nil && pl.Table.This() != nil {
+		pl.Table.ViewSig.Disconnect(pl.This())
+		pl.Table.SliceViewSig.Disconnect(pl.This())
+	}
+}
+
+// Update updates the plot from the current data -- the ranges are
+// recomputed unless the view has been zoomed
+func (pl *Plot2D) Update() {
+	if !pl.Zoomed {
+		pl.AutoRange()
+	}
+	pl.UpdateSig()
+}
+
+// ResetView resets any zooming or panning, showing all the data
+func (pl *Plot2D) ResetView() {
+	pl.Zoomed = false
+	pl.Update()
+}
+
+// AutoRange sets the XRange and YRange from the data and axis parameters
+func (pl *Plot2D) AutoRange() {
+	xr := PlotRange{math.Inf(1), math.Inf(-1)}
+	yr := xr
+	hasBar := false
+	for _, ps := range pl.Series {
+		if ps.Off {
+			continue
+		}
+		xs, ys := ps.Values()
+		mn, mx := PlotMinMax(xs, pl.XAxis.Log)
+		xr.Min, xr.Max = math.Min(xr.Min, mn), math.Max(xr.Max, mx)
+		mn, mx = PlotMinMax(ys, pl.YAxis.Log)
+		yr.Min, yr.Max = math.Min(yr.Min, mn), math.Max(yr.Max, mx)
+		if ps.Type == PlotBar || ps.Type == PlotHist {
+			hasBar = true
+			if hw := pl.barWidth(ps, xs) / 2; !math.IsInf(mn, 0) {
+				xr.Min -= hw
+				xr.Max += hw
+			}
+		}
+	}
+	if hasBar && !pl.YAxis.Log {
+		yr.Min = math.Min(yr.Min, 0)
+		yr.Max = math.Max(yr.Max, 0)
+	}
+	pl.XRange = pl.XAxis.fixRange(xr)
+	pl.YRange = pl.YAxis.fixRange(yr)
+}
+
+// fixRange applies the fixed min, max and ensures the range is valid
+func (ax *PlotAxis) fixRange(rng PlotRange) PlotRange {
+	if ax.FixMin {
+		rng.Min = ax.Min
+	}
+	if ax.FixMax {
+		rng.Max = ax.Max
+	}
+	if math.IsInf(rng.Min, 0) || math.IsInf(rng.Max, 0) {
+		rng = PlotRange{0, 1}
+		if ax.Log {
+			rng = PlotRange{1, 10}
+		}
+	}
+	if rng.Max <= rng.Min {
+		if ax.Log {
+			rng.Min /= 2
+			rng.Max = rng.Min * 4
+		} else {
+			rng.Min -= 0.5
+			rng.Max = rng.Min + 1
+		}
+	}
+	return ax.minSpan(rng)
+}
+
+// minSpan expands the range around its center, if needed, so that its span
+// is at least PlotMinSpan relative to the magnitude of its values
+func (ax *PlotAxis) minSpan(rng PlotRange) PlotRange {
+	mn, mx := ax.Fwd(rng.Min), ax.Fwd(rng.Max)
+	if math.IsNaN(mn) || math.IsNaN(mx) || math.IsInf(mn, 0) || math.IsInf(mx, 0) {
+		return rng
+	}
+	msp := PlotMinSpan * math.Max(1, math.Max(math.Abs(mn), math.Abs(mx)))
+	if mx-mn >= msp {
+		return rng
+	}
+	c := 0.5 * (mn + mx)
+	return PlotRange{ax.Inv(c - 0.5*msp), ax.Inv(c + 0.5*msp)}
+}
+
+// barWidth returns the width of bars (in X data units) for given series
+func (pl *Plot2D) barWidth(ps *PlotSeries, xs []float64) float64 {
+	mind := math.Inf(1)
+	for i := 1; i < len(xs); i++ {
+		if d := math.Abs(xs[i] - xs[i-1]); d > 0 {
+			mind = math.Min(mind, d)
+		}
+	}
+	if math.IsInf(mind, 0) {
+		mind = 1
+	}
+	if ps.Type == PlotHist {
+		return mind
+	}
+	return 0.8 * mind
+}
+
+// SeriesColor returns the color for given series index
+func (pl *Plot2D) SeriesColor(si int) gi.Color {
+	ps := pl.Series[si]
+	if !ps.Color.IsNil() {
+		return ps.Color
+	}
+	cm := pl.ColorMapValue()
+	n := len(pl.Series)
+	if n <= 1 {
+		return cm.Map(0)
+	}
+	return cm.Map(float64(si) / float64(n-1))
+}
+
+// ColorMapValue returns the ColorMap for the ColorMap name, defaulting to
+// JetMuted
+func (pl *Plot2D) ColorMapValue() *ColorMap {
+	if cm, ok := AvailColorMaps[string(pl.ColorMap)]; ok {
+		return cm
+	}
+	return StdColorMaps["JetMuted"]
+}
+
+// DataToPoint returns the position, relative to the widget, for the given
+// data values, within the PlotBox from the last render
+func (pl *Plot2D) DataToPoint(x, y float64) mat32.Vec2 {
+	pb := pl.PlotBox
+	nx := pl.XAxis.Norm(x, pl.XRange)
+	ny := pl.YAxis.Norm(y, pl.YRange)
+	return mat32.Vec2{float32(pb.Min.X) + float32(nx)*float32(pb.Dx()), float32(pb.Max.Y) - float32(ny)*float32(pb.Dy())}
+}
+
+// PointToData returns the data values for given position relative to the
+// widget, within the PlotBox from the last render
+func (pl *Plot2D) PointToData(pt image.Point) (x, y float64) {
+	pb := pl.PlotBox
+	if pb.Dx() <= 0 || pb.Dy() <= 0 {
+		return
+	}
+	nx := float64(pt.X-pb.Min.X) / float64(pb.Dx())
+	ny := float64(pb.Max.Y-pt.Y) / float64(pb.Dy())
+	x = pl.XAxis.Inv(pl.XAxis.Fwd(pl.XRange.Min) + nx*(pl.XAxis.Fwd(pl.XRange.Max)-pl.XAxis.Fwd(pl.XRange.Min)))
+	y = pl.YAxis.Inv(pl.YAxis.Fwd(pl.YRange.Min) + ny*(pl.YAxis.Fwd(pl.YRange.Max)-pl.YAxis.Fwd(pl.YRange.Min)))
+	return
+}
+
+// ZoomAt zooms the view by given factor (< 1 = zoom in) around given
+// position relative to the widget, in X and / or Y
+func (pl *Plot2D) ZoomAt(pt image.Point, factor float64, zx, zy bool) {
+	pb := pl.PlotBox
+	if pb.Dx() <= 0 || pb.Dy() <= 0 {
+		return
+	}
+	zoom := func(ax *PlotAxis, rng PlotRange, c float64) PlotRange {
+		mn, mx := ax.Fwd(rng.Min), ax.Fwd(rng.Max)
+		cv := mn + c*(mx-mn)
+		return ax.minSpan(PlotRange{ax.Inv(cv - (cv-mn)*factor), ax.Inv(cv + (mx-cv)*factor)})
+	}
+	if zx {
+		pl.XRange = zoom(&pl.XAxis, pl.XRange, float64(pt.X-pb.Min.X)/float64(pb.Dx()))
+	}
+	if zy {
+		pl.YRange = zoom(&pl.YAxis, pl.YRange, float64(pb.Max.Y-pt.Y)/float64(pb.Dy()))
+	}
+	pl.Zoomed = true
+	pl.UpdateSig()
+}
+
+// Pan pans the view by given number of pixels
+func (pl *Plot2D) Pan(del image.Point) {
+	pb := pl.PlotBox
+	if pb.Dx() <= 0 || pb.Dy() <= 0 {
+		return
+	}
+	pan := func(ax *PlotAxis, rng PlotRange, d float64) PlotRange {
+		mn, mx := ax.Fwd(rng.Min), ax.Fwd(rng.Max)
+		off := d * (mx - mn)
+		return PlotRange{ax.Inv(mn + off), ax.Inv(mx + off)}
+	}
+	pl.XRange = pan(&pl.XAxis, pl.XRange, -float64(del.X)/float64(pb.Dx()))
+	pl.YRange = pan(&pl.YAxis, pl.YRange, float64(del.Y)/float64(pb.Dy()))
+	pl.Zoomed = true
+	pl.UpdateSig()
+}
+
+// PointAt returns the series and index of the data point nearest to given
+// position relative to the widget, within dist pixels, or -1, -1 if none
+func (pl *Plot2D) PointAt(pt image.Point, dist float32) (si, idx int) {
+	si, idx = -1, -1
+	p := mat32.Vec2{float32(pt.X), float32(pt.Y)}
+	best := dist * dist
+	for i, ps := range pl.Series {
+		if ps.Off {
+			continue
+		}
+		xs, ys := ps.Values()
+		for j := range ys {
+			dp := pl.DataToPoint(xs[j], ys[j])
+			if ps.Type == PlotBar || ps.Type == PlotHist {
+				dp = pl.barPos(i, ps, xs, j, ys[j]) // top center of bar
+			}
+			if mat32.IsNaN(dp.X) || mat32.IsNaN(dp.Y) {
+				continue
+			}
+			d := dp.Sub(p)
+			if dd := d.X*d.X + d.Y*d.Y; dd <= best {
+				best = dd
+				si, idx = i, j
+			}
+		}
+	}
+	return
+}
+
+// HoverText returns the readout text for the current hover point
+func (pl *Plot2D) HoverText() string {
+	if pl.HoverSeri < 0 || pl.HoverSeri >= len(pl.Series) {
+		return ""
+	}
+	ps := pl.Series[pl.HoverSeri]
+	xs, ys := ps.Values()
+	if pl.HoverIdx >= len(ys) {
+		return ""
+	}
+	return fmt.Sprintf("%v: (%v, %v)", ps.Name, PlotTickLabel(xs[pl.HoverIdx]), PlotTickLabel(ys[pl.HoverIdx]))
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Rendering
+
+// plotPainter is the interface for drawing the plot, implemented for gi
+// rendering and SVG export.  Positions are relative to the widget.
+type plotPainter interface {
+	// Line draws a polyline
+	Line(pts []mat32.Vec2, clr gi.Color, width float32, dash bool)
+
+	// Rect draws a rectangle -- nil colors are not drawn
+	Rect(pos, sz mat32.Vec2, fill, stroke gi.Color)
+
+	// Circle draws a filled circle
+	Circle(ctr mat32.Vec2, r float32, fill gi.Color)
+
+	// Text draws text aligned relative to pos by ax, ay (0 = left / top,
+	// 0.5 = center, 1 = right / bottom), optionally rotated 90 degrees
+	Text(str string, pos mat32.Vec2, ax, ay float32, rot bool, clr gi.Color)
+}
+
+// TextSize returns the size of given text rendered in the plot font
+func (pl *Plot2D) TextSize(str string, rot bool) mat32.Vec2 {
+	sty := &pl.Sty
+	if rot {
+		pl.textRender.SetStringRot90(str, &sty.Font, &sty.UnContext, &sty.Text, true, 0)
+	} else {
+		pl.textRender.SetString(str, &sty.Font, &sty.UnContext, &sty.Text, true, 0, 0)
+	}
+	return pl.textRender.Size
+}
+
+// barPos returns the top center position of the bar for given series and
+// index, taking into account side-by-side bars of multiple bar series
+func (pl *Plot2D) barPos(si int, ps *PlotSeries, xs []float64, i int, y float64) mat32.Vec2 {
+	x := xs[i]
+	if ps.Type == PlotBar {
+		nb, bi := 0, 0
+		for j, os := range pl.Series {
+			if os.Type == PlotBar && !os.Off {
+				if j == si {
+					bi = nb
+				}
+				nb++
+			}
+		}
+		bw := pl.barWidth(ps, xs) / float64(nb)
+		x += (float64(bi) - float64(nb-1)/2) * bw
+	}
+	return pl.DataToPoint(x, y)
+}
+
+// PaintPlot draws the whole plot with given painter, within given size
+func (pl *Plot2D) PaintPlot(pp plotPainter, sz mat32.Vec2) {
+	fg := pl.Sty.Font.Color
+	bg := pl.Sty.Font.BgColor.Color
+	grid := bg.Blend(15, fg)
+	pp.Rect(mat32.Vec2{}, sz, bg, gi.Color{})
+
+	fh := pl.TextSize("0", false).Y
+	pad := 0.5 * fh
+	tick := 0.3 * fh
+
+	xticks, _ := PlotTicks(pl.XRange, pl.axisTicks(&pl.XAxis, 8), pl.XAxis.Log)
+	yticks, _ := PlotTicks(pl.YRange, pl.axisTicks(&pl.YAxis, 6), pl.YAxis.Log)
+	ytw := float32(0)
+	for _, yt := range yticks {
+		ytw = mat32.Max(ytw, pl.TextSize(PlotTickLabel(yt), false).X)
+	}
+
+	x0 := pad + ytw + tick + pad/2
+	if pl.YAxis.Label != "" {
+		x0 += fh + pad
+	}
+	y0 := pad
+	if pl.Title != "" {
+		y0 += fh + pad
+	}
+	x1 := sz.X - pad - pl.TextSize(PlotTickLabel(xticks[len(xticks)-1]), false).X/2
+	y1 := sz.Y - pad - fh - tick
+	if pl.XAxis.Label != "" {
+		y1 -= fh + pad
+	}
+	if x1 <= x0+10 || y1 <= y0+10 {
+		return
+	}
+	pl.PlotBox = image.Rect(int(x0), int(y0), int(x1), int(y1))
+	pb := pl.PlotBox
+	pbx0, pby0, pbx1, pby1 := float32(pb.Min.X), float32(pb.Min.Y), float32(pb.Max.X), float32(pb.Max.Y)
+
+	if pl.Title != "" {
+		pp.Text(pl.Title, mat32.Vec2{(pbx0 + pbx1) / 2, pad}, 0.5, 0, false, fg)
+	}
+	// grid and ticks
+	for _, xt := range xticks {
+		px := pl.DataToPoint(xt, pl.YRange.Min).X
+		if px < pbx0-0.5 || px > pbx1+0.5 {
+			continue
+		}
+		if !pl.NoGrid {
+			pp.Line([]mat32.Vec2{{px, pby0}, {px, pby1}}, grid, 1, false)
+		}
+		pp.Line([]mat32.Vec2{{px, pby1}, {px, pby1 + tick}}, fg, 1, false)
+		pp.Text(PlotTickLabel(xt), mat32.Vec2{px, pby1 + tick}, 0.5, 0, false, fg)
+	}
+	for _, yt := range yticks {
+		py := pl.DataToPoint(pl.XRange.Min, yt).Y
+		if py < pby0-0.5 || py > pby1+0.5 {
+			continue
+		}
+		if !pl.NoGrid {
+			pp.Line([]mat32.Vec2{{pbx0, py}, {pbx1, py}}, grid, 1, false)
+		}
+		pp.Line([]mat32.Vec2{{pbx0 - tick, py}, {pbx0, py}}, fg, 1, false)
+		pp.Text(PlotTickLabel(yt), mat32.Vec2{pbx0 - tick - pad/2, py}, 1, 0.5, false, fg)
+	}
+	if pl.XAxis.Label != "" {
+		pp.Text(pl.XAxis.Label, mat32.Vec2{(pbx0 + pbx1) / 2, sz.Y - pad}, 0.5, 1, false, fg)
+	}
+	if pl.YAxis.Label != "" {
+		pp.Text(pl.YAxis.Label, mat32.Vec2{pad, (pby0 + pby1) / 2}, 0, 0.5, true, fg)
+	}
+
+	// data, clipped to the plot box
+	for si, ps := range pl.Series {
+		if ps.Off {
+			continue
+		}
+		pl.paintSeries(pp, si, ps)
+	}
+	// axes box drawn after the data so it covers bars at the edges
+	pp.Rect(mat32.Vec2{pbx0, pby0}, mat32.Vec2{pbx1 - pbx0, pby1 - pby0}, gi.Color{}, fg)
+
+	pl.paintLegend(pp, fh, pad)
+	pl.paintHover(pp, fh, pad)
+}
+
+// axisTicks returns number of ticks for axis, with default
+func (pl *Plot2D) axisTicks(ax *PlotAxis, def int) int {
+	if ax.Ticks > 0 {
+		return ax.Ticks
+	}
+	return def
+}
+
+// paintSeries draws the data for one series
+func (pl *Plot2D) paintSeries(pp plotPainter, si int, ps *PlotSeries) {
+	clr := pl.SeriesColor(si)
+	xs, ys := ps.Values()
+	wd := ps.Width
+	if wd <= 0 {
+		wd = 1.5
+	}
+	psz := ps.PointSize
+	if psz <= 0 && ps.Type == PlotScatter {
+		psz = 3
+	}
+	var cs []float64
+	var cr PlotRange
+	if ps.C != nil && (ps.Type == PlotLine || ps.Type == PlotScatter) {
+		cs = PlotFloats(ps.C, ps.CField)
+		cr.Min, cr.Max = PlotMinMax(cs, false)
+	}
+	pb := pl.PlotBox
+	inBox := func(p mat32.Vec2) bool {
+		return plotFinite(p) && p.X >= float32(pb.Min.X)-0.5 && p.X <= float32(pb.Max.X)+0.5 && p.Y >= float32(pb.Min.Y)-0.5 && p.Y <= float32(pb.Max.Y)+0.5
+	}
+	switch ps.Type {
+	case PlotLine, PlotScatter:
+		var pts []mat32.Vec2
+		flush := func() {
+			if len(pts) > 1 {
+				pp.Line(pts, clr, wd, false)
+			}
+			pts = pts[:0]
+		}
+		var prv mat32.Vec2
+		hasPrv := false
+		for i := range ys {
+			p := pl.DataToPoint(xs[i], ys[i])
+			if ps.Type == PlotLine {
+				if !plotFinite(p) { // missing values break the line
+					flush()
+					hasPrv = false
+					continue
+				}
+				if hasPrv {
+					a, b, ok := plotClipSegment(prv, p, pb)
+					if ok {
+						if len(pts) == 0 {
+							pts = append(pts, a)
+						}
+						pts = append(pts, b)
+					}
+					if !ok || b != p { // leaves the box
+						flush()
+					}
+				}
+				prv = p
+				hasPrv = true
+			}
+			if psz > 0 && inBox(p) {
+				pc := clr
+				if cs != nil && i < len(cs) {
+					pc = pl.ColorMapValue().Map((cs[i] - cr.Min) / (cr.Max - cr.Min))
+				}
+				pp.Circle(p, psz, pc)
+			}
+		}
+		flush()
+	case PlotBar, PlotHist:
+		bw := pl.barWidth(ps, xs)
+		if ps.Type == PlotBar {
+			nb := 0
+			for _, os := range pl.Series {
+				if os.Type == PlotBar && !os.Off {
+					nb++
+				}
+			}
+			bw /= float64(nb)
+		}
+		base := 0.0
+		if pl.YAxis.Log {
+			base = pl.YRange.Min
+		}
+		for i := range ys {
+			top := pl.barPos(si, ps, xs, i, ys[i])
+			bot := pl.barPos(si, ps, xs, i, base)
+			if mat32.IsNaN(top.Y) {
+				continue
+			}
+			hw := pl.DataToPoint(xs[i]+bw/2, 1).X - pl.DataToPoint(xs[i], 1).X
+			if mat32.IsNaN(hw) || hw < 1 {
+				hw = 1
+			}
+			x0 := mat32.Max(top.X-hw, float32(pb.Min.X))
+			x1 := mat32.Min(top.X+hw, float32(pb.Max.X))
+			y0 := mat32.Max(mat32.Min(top.Y, bot.Y), float32(pb.Min.Y))
+			y1 := mat32.Min(mat32.Max(top.Y, bot.Y), float32(pb.Max.Y))
+			if x1 <= x0 || y1 <= y0 {
+				continue
+			}
+			pp.Rect(mat32.Vec2{x0, y0}, mat32.Vec2{x1 - x0, y1 - y0}, clr, pl.Sty.Font.BgColor.Color)
+		}
+	}
+}
+
+// plotFinite returns true if both coordinates of given point are finite
+func plotFinite(p mat32.Vec2) bool {
+	x, y := float64(p.X), float64(p.Y)
+	return !math.IsNaN(x) && !math.IsNaN(y) && !math.IsInf(x, 0) && !math.IsInf(y, 0)
+}
+
+// plotClipSegment clips the line segment from a to b to given box (plus half
+// a pixel), using the Liang-Barsky algorithm -- returns false if no part of
+// it is within the box.  The end points are returned unchanged if they are
+// within the box.
+func plotClipSegment(a, b mat32.Vec2, box image.Rectangle) (mat32.Vec2, mat32.Vec2, bool) {
+	x0, y0 := float32(box.Min.X)-0.5, float32(box.Min.Y)-0.5
+	x1, y1 := float32(box.Max.X)+0.5, float32(box.Max.Y)+0.5
+	d := b.Sub(a)
+	t0, t1 := float32(0), float32(1)
+	clip := func(p, q float32) bool {
+		if p == 0 {
+			return q >= 0
+		}
+		r := q / p
+		if p < 0 {
+			if r > t1 {
+				return false
+			}
+			t0 = mat32.Max(t0, r)
+		} else {
+			if r < t0 {
+				return false
+			}
+			t1 = mat32.Min(t1, r)
+		}
+		return true
+	}
+	if !clip(-d.X, a.X-x0) || !clip(d.X, x1-a.X) || !clip(-d.Y, a.Y-y0) || !clip(d.Y, y1-a.Y) {
+		return a, b, false
+	}
+	ca, cb := a, b
+	if t0 > 0 {
+		ca = a.Add(d.MulScalar(t0))
+	}
+	if t1 < 1 {
+		cb = a.Add(d.MulScalar(t1))
+	}
+	return ca, cb, true
+}
+
+// paintLegend draws the legend in the upper right of the plot box
+func (pl *Plot2D) paintLegend(pp plotPainter, fh, pad float32) {
+	pl.LegendBoxes = make([]image.Rectangle, len(pl.Series))
+	if pl.NoLegend || len(pl.Series) == 0 {
+		return
+	}
+	fg := pl.Sty.Font.Color
+	sw := 1.5 * fh // swatch width
+	mw := float32(0)
+	for _, ps := range pl.Series {
+		mw = mat32.Max(mw, pl.TextSize(ps.Name, false).X)
+	}
+	lw := pad + sw + pad/2 + mw + pad
+	lh := pad + float32(len(pl.Series))*fh + pad/2
+	pb := pl.PlotBox
+	lx := float32(pb.Max.X) - lw - pad
+	ly := float32(pb.Min.Y) + pad
+	pp.Rect(mat32.Vec2{lx, ly}, mat32.Vec2{lw, lh}, pl.Sty.Font.BgColor.Color, fg)
+	for si, ps := range pl.Series {
+		y := ly + pad/2 + float32(si)*fh
+		clr := pl.SeriesColor(si)
+		tclr := fg
+		if ps.Off {
+			clr = clr.Blend(70, pl.Sty.Font.BgColor.Color)
+			tclr = fg.Blend(60, pl.Sty.Font.BgColor.Color)
+		}
+		sx := lx + pad
+		switch ps.Type {
+		case PlotLine:
+			pp.Line([]mat32.Vec2{{sx, y + fh/2}, {sx + sw, y + fh/2}}, clr, 2, false)
+		case PlotScatter:
+			pp.Circle(mat32.Vec2{sx + sw/2, y + fh/2}, 3, clr)
+		default:
+			pp.Rect(mat32.Vec2{sx, y + fh*0.2}, mat32.Vec2{sw, fh * 0.6}, clr, gi.Color{})
+		}
+		pp.Text(ps.Name, mat32.Vec2{sx + sw + pad/2, y}, 0, 0, false, tclr)
+		pl.LegendBoxes[si] = image.Rect(int(lx), int(y), int(lx+lw), int(y+fh))
+	}
+}
+
+// paintHover draws the readout of the data point under the mouse
+func (pl *Plot2D) paintHover(pp plotPainter, fh, pad float32) {
+	txt := pl.HoverText()
+	if txt == "" {
+		return
+	}
+	ps := pl.Series[pl.HoverSeri]
+	xs, ys := ps.Values()
+	p := pl.DataToPoint(xs[pl.HoverIdx], ys[pl.HoverIdx])
+	if ps.Type == PlotBar || ps.Type == PlotHist {
+		p = pl.barPos(pl.HoverSeri, ps, xs, pl.HoverIdx, ys[pl.HoverIdx])
+	}
+	fg := pl.Sty.Font.Color
+	pp.Circle(p, 4, pl.SeriesColor(pl.HoverSeri))
+	tsz := pl.TextSize(txt, false)
+	bx := p.X + pad
+	if bx+tsz.X+pad > float32(pl.PlotBox.Max.X) {
+		bx = p.X - pad - tsz.X - pad
+	}
+	by := p.Y - pad - tsz.Y
+	if by < float32(pl.PlotBox.Min.Y) {
+		by = p.Y + pad
+	}
+	pp.Rect(mat32.Vec2{bx, by}, mat32.Vec2{tsz.X + pad, tsz.Y}, gi.Prefs.Colors.Highlight, fg)
+	pp.Text(txt, mat32.Vec2{bx + pad/2, by}, 0, 0, false, fg)
+}
+
+// giPlotPainter draws the plot using gi.Paint into the render state
+type giPlotPainter struct {
+	pl  *Plot2D
+	rs  *gi.RenderState
+	off mat32.Vec2
+}
+
+func (gp *giPlotPainter) Line(pts []mat32.Vec2, clr gi.Color, width float32, dash bool) {
+	pc := &gp.rs.Paint
+	pc.StrokeStyle.SetColor(clr)
+	pc.StrokeStyle.Width.Dots = width
+	pc.FillStyle.SetColor(nil)
+	if dash {
+		pc.StrokeStyle.Dashes = []float64{4, 4}
+	}
+	for i, p := range pts {
+		if i == 0 {
+			pc.MoveTo(gp.rs, gp.off.X+p.X, gp.off.Y+p.Y)
+		} else {
+			pc.LineTo(gp.rs, gp.off.X+p.X, gp.off.Y+p.Y)
+		}
+	}
+	pc.FillStrokeClear(gp.rs)
+	pc.StrokeStyle.Dashes = nil
+}
+
+func (gp *giPlotPainter) Rect(pos, sz mat32.Vec2, fill, stroke gi.Color) {
+	pc := &gp.rs.Paint
+	if fill.IsNil() {
+		pc.FillStyle.SetColor(nil)
+	} else {
+		pc.FillStyle.SetColor(fill)
+	}
+	if stroke.IsNil() {
+		pc.StrokeStyle.SetColor(nil)
+	} else {
+		pc.StrokeStyle.SetColor(stroke)
+		pc.StrokeStyle.Width.Dots = 1
+		pos = pos.AddScalar(0.5) // crisp lines
+		sz = sz.SubScalar(1)
+	}
+	pc.DrawRectangle(gp.rs, gp.off.X+pos.X, gp.off.Y+pos.Y, sz.X, sz.Y)
+	pc.FillStrokeClear(gp.rs)
+}
+
+func (gp *giPlotPainter) Circle(ctr mat32.Vec2, r float32, fill gi.Color) {
+	pc := &gp.rs.Paint
+	pc.FillStyle.SetColor(fill)
+	pc.StrokeStyle.SetColor(nil)
+	pc.DrawCircle(gp.rs, gp.off.X+ctr.X, gp.off.Y+ctr.Y, r)
+	pc.FillStrokeClear(gp.rs)
+}
+
+func (gp *giPlotPainter) Text(str string, pos mat32.Vec2, ax, ay float32, rot bool, clr gi.Color) {
+	pl := gp.pl
+	fst := pl.Sty.Font
+	fst.Color = clr
+	fst.BgColor.SetColor(nil)
+	tr := &pl.textRender
+	if rot {
+		tr.SetStringRot90(str, &fst, &pl.Sty.UnContext, &pl.Sty.Text, true, 0)
+	} else {
+		tr.SetString(str, &fst, &pl.Sty.UnContext, &pl.Sty.Text, true, 0, 0)
+	}
+	tp := gp.off.Add(pos).Sub(mat32.Vec2{ax * tr.Size.X, ay * tr.Size.Y})
+	tr.RenderTopPos(gp.rs, tp)
+}
+
+// RenderPlot renders the plot into the viewport
+func (pl *Plot2D) RenderPlot() {
+	rs := pl.Render()
+	rs.Lock()
+	pc := &rs.Paint
+	sst, fst := pc.StrokeStyle, pc.FillStyle
+	pos := pl.LayState.Alloc.Pos.AddScalar(pl.Sty.Layout.Margin.Dots)
+	sz := pl.LayState.Alloc.Size.SubScalar(2 * pl.Sty.Layout.Margin.Dots)
+	gp := &giPlotPainter{pl: pl, rs: rs, off: pos}
+	rs.Unlock()
+	if !pl.Zoomed {
+		pl.AutoRange()
+	}
+	pl.PaintPlot(gp, sz)
+	rs.Lock()
+	pc.StrokeStyle, pc.FillStyle = sst, fst
+	rs.Unlock()
+}
+
+// localPoint returns the position of given window point relative to the
+// widget, as used in PlotBox etc
+func (pl *Plot2D) localPoint(wp image.Point) image.Point {
+	return wp.Sub(pl.WinBBox.Min).Add(pl.VpBBox.Min).Sub(pl.LayState.Alloc.Pos.AddScalar(pl.Sty.Layout.Margin.Dots).ToPoint())
+}
+
+// MouseEvent handles legend clicks, double-click reset, and context menu
+func (pl *Plot2D) MouseEvent() {
+	pl.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		plv := recv.Embed(KiT_Plot2D).(*Plot2D)
+		switch {
+		case me.Button == mouse.Right && me.Action == mouse.Release:
+			me.SetProcessed()
+			plv.EmitContextMenuSignal()
+			plv.This().(gi.Node2D).ContextMenu()
+		case me.Button == mouse.Left && me.Action == mouse.DoubleClick:
+			me.SetProcessed()
+			plv.ResetView()
+		case me.Button == mouse.Left && me.Action == mouse.Press:
+			lp := plv.localPoint(me.Where)
+			for si, lb := range plv.LegendBoxes {
+				if lp.In(lb) {
+					me.SetProcessed()
+					plv.Series[si].Off = !plv.Series[si].Off
+					plv.Update()
+					return
+				}
+			}
+		}
+	})
+}
+
+// MouseDragEvent handles panning
+func (pl *Plot2D) MouseDragEvent() {
+	pl.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		plv := recv.Embed(KiT_Plot2D).(*Plot2D)
+		me.SetProcessed()
+		plv.HoverSeri = -1
+		plv.Pan(me.Delta())
+	})
+}
+
+// MouseScrollEvent handles zooming
+func (pl *Plot2D) MouseScrollEvent() {
+	pl.ConnectEvent(oswin.MouseScrollEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.ScrollEvent)
+		plv := recv.Embed(KiT_Plot2D).(*Plot2D)
+		me.SetProcessed()
+		del := me.NonZeroDelta(false)
+		if del == 0 {
+			return
+		}
+		factor := 1.1
+		if del < 0 {
+			factor = 1 / factor
+		}
+		zx, zy := true, true
+		if key.HasAnyModifierBits(me.Modifiers, key.Shift) {
+			zy = false
+		} else if key.HasAnyModifierBits(me.Modifiers, key.Alt) {
+			zx = false
+		}
+		plv.ZoomAt(plv.localPoint(me.Where), factor, zx, zy)
+	})
+}
+
+// MouseMoveEvent handles the hover readout
+func (pl *Plot2D) MouseMoveEvent() {
+	pl.ConnectEvent(oswin.MouseMoveEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.MoveEvent)
+		plv := recv.Embed(KiT_Plot2D).(*Plot2D)
+		si, idx := plv.PointAt(plv.localPoint(me.Where), 8)
+		if si != plv.HoverSeri || idx != plv.HoverIdx {
+			plv.HoverSeri, plv.HoverIdx = si, idx
+			plv.UpdateSig()
+		}
+	})
+}
+
+func (pl *Plot2D) ConnectEvents2D() {
+	pl.MouseEvent()
+	pl.MouseDragEvent()
+	pl.MouseScrollEvent()
+	pl.MouseMoveEvent()
+}
+
+func (pl *Plot2D) Style2D() {
+	pl.WidgetBase.Style2D()
+	pl.Sty.Font.OpenFont(&pl.Sty.UnContext)
+}
+
+func (pl *Plot2D) Init2D() {
+	pl.WidgetBase.Init2D()
+	pl.HoverSeri = -1
+}
+
+func (pl *Plot2D) Render2D() {
+	if pl.FullReRenderIfNeeded() {
+		return
+	}
+	if pl.PushBounds() {
+		pl.This().(gi.Node2D).ConnectEvents2D()
+		pl.RenderPlot()
+		pl.Render2DChildren()
+		pl.PopBounds()
+	} else {
+		pl.DisconnectAllEvents(gi.RegPri)
+	}
+}
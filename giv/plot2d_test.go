@@ -0,0 +1,98 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/goki/mat32"
+)
+
+func TestPlotTicks(t *testing.T) {
+	tests := []struct {
+		rng   PlotRange
+		n     int
+		log   bool
+		ticks []float64
+		step  float64
+	}{
+		{PlotRange{0, 10}, 5, false, []float64{0, 2, 4, 6, 8, 10}, 2},
+		{PlotRange{-1, 1}, 4, false, []float64{-1, -0.5, 0, 0.5, 1}, 0.5},
+		{PlotRange{0.3, 0.8}, 5, false, []float64{0.3, 0.4, 0.5, 0.6, 0.7, 0.8}, 0.1},
+		{PlotRange{1, 1000}, 3, true, []float64{1, 10, 100, 1000}, 1},
+		{PlotRange{5, 5}, 5, false, []float64{5}, 1},
+	}
+	for _, tst := range tests {
+		ts, step := PlotTicks(tst.rng, tst.n, tst.log)
+		if math.Abs(step-tst.step) > 1e-12 {
+			t.Errorf("PlotTicks(%v, %v, %v): step %v != %v", tst.rng, tst.n, tst.log, step, tst.step)
+		}
+		if len(ts) != len(tst.ticks) {
+			t.Errorf("PlotTicks(%v, %v, %v): ticks %v != %v", tst.rng, tst.n, tst.log, ts, tst.ticks)
+			continue
+		}
+		for i, v := range ts {
+			if math.Abs(v-tst.ticks[i]) > 1e-9*math.Max(1, math.Abs(v)) {
+				t.Errorf("PlotTicks(%v, %v, %v): ticks %v != %v", tst.rng, tst.n, tst.log, ts, tst.ticks)
+				break
+			}
+		}
+	}
+
+	// ranges below the precision of their values must terminate
+	tiny := []PlotRange{
+		{370.123, 370.1230000000001},
+		{1e20, math.Nextafter(math.Nextafter(1e20, math.Inf(1)), math.Inf(1))},
+		{-1e300, 1e300},
+	}
+	for _, rng := range tiny {
+		ts, _ := PlotTicks(rng, 8, false)
+		if len(ts) == 0 || len(ts) > PlotMaxTicks {
+			t.Errorf("PlotTicks(%v): %v ticks", rng, len(ts))
+		}
+	}
+}
+
+func TestPlotMinSpan(t *testing.T) {
+	var ax PlotAxis
+	rng := ax.fixRange(PlotRange{370.123, 370.1230000000001})
+	if sp := rng.Max - rng.Min; sp < 0.99*PlotMinSpan*370.123 {
+		t.Errorf("fixRange: span %v below minimum", sp)
+	}
+	if c := 0.5 * (rng.Min + rng.Max); math.Abs(c-370.123) > 1e-6 {
+		t.Errorf("fixRange: center moved to %v", c)
+	}
+	rng = ax.fixRange(PlotRange{0, 10})
+	if rng != (PlotRange{0, 10}) {
+		t.Errorf("fixRange: changed valid range to %v", rng)
+	}
+}
+
+func TestPlotClipSegment(t *testing.T) {
+	box := image.Rect(0, 0, 100, 100)
+	tests := []struct {
+		a, b   mat32.Vec2
+		ok     bool
+		ca, cb mat32.Vec2
+	}{
+		{mat32.Vec2{10, 10}, mat32.Vec2{90, 90}, true, mat32.Vec2{10, 10}, mat32.Vec2{90, 90}},
+		{mat32.Vec2{50, 50}, mat32.Vec2{250, 50}, true, mat32.Vec2{50, 50}, mat32.Vec2{100.5, 50}},
+		{mat32.Vec2{-99.5, 50}, mat32.Vec2{200.5, 50}, true, mat32.Vec2{-0.5, 50}, mat32.Vec2{100.5, 50}},
+		{mat32.Vec2{-10, -10}, mat32.Vec2{-10, 200}, false, mat32.Vec2{}, mat32.Vec2{}},
+		{mat32.Vec2{250, 0}, mat32.Vec2{0, 250}, false, mat32.Vec2{}, mat32.Vec2{}},
+	}
+	for _, tst := range tests {
+		ca, cb, ok := plotClipSegment(tst.a, tst.b, box)
+		if ok != tst.ok {
+			t.Errorf("plotClipSegment(%v, %v): ok %v != %v", tst.a, tst.b, ok, tst.ok)
+			continue
+		}
+		if ok && (ca.DistTo(tst.ca) > 1e-3 || cb.DistTo(tst.cb) > 1e-3) {
+			t.Errorf("plotClipSegment(%v, %v): %v, %v != %v, %v", tst.a, tst.b, ca, cb, tst.ca, tst.cb)
+		}
+	}
+}
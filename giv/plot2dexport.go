@@ -0,0 +1,139 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/mat32"
+)
+
+// SavePNG saves the plot as a PNG image file, as currently rendered
+func (pl *Plot2D) SavePNG(filename gi.FileName) error {
+	img := gi.GrabRenderFrom(pl.This().(gi.Node2D))
+	if img == nil {
+		err := errors.New("giv.Plot2D SavePNG: plot is not rendered")
+		log.Println(err)
+		return err
+	}
+	err := gi.SaveImage(string(filename), img)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// SaveSVG saves the plot as an SVG vector graphics file, at the current
+// size of the plot, or 640 x 480 if it has not been rendered
+func (pl *Plot2D) SaveSVG(filename gi.FileName) error {
+	b := pl.SVG()
+	err := ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// SVG returns the plot as SVG vector graphics, at the current size of the
+// plot, or 640 x 480 if it has not been rendered
+func (pl *Plot2D) SVG() []byte {
+	sz := pl.LayState.Alloc.Size.SubScalar(2 * pl.Sty.Layout.Margin.Dots)
+	if sz.X <= 0 || sz.Y <= 0 {
+		sz = mat32.Vec2{640, 480}
+	}
+	if pl.Sty.Font.Face == nil {
+		pl.Sty.Font.OpenFont(&pl.Sty.UnContext)
+	}
+	if !pl.Zoomed {
+		pl.AutoRange()
+	}
+	fam := pl.Sty.Font.Family
+	if fam == "" {
+		fam = "sans-serif"
+	}
+	sp := &svgPlotPainter{pl: pl}
+	fmt.Fprintf(&sp.buf, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(&sp.buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"0 0 %g %g\" font-family=\"%s\" font-size=\"%g\">\n", sz.X, sz.Y, sz.X, sz.Y, svgEscape(fam), pl.Sty.Font.Size.Dots)
+	hs, hi := pl.HoverSeri, pl.HoverIdx
+	pl.HoverSeri = -1 // no hover readout in export
+	pl.PaintPlot(sp, sz)
+	pl.HoverSeri, pl.HoverIdx = hs, hi
+	fmt.Fprintf(&sp.buf, "</svg>\n")
+	return sp.buf.Bytes()
+}
+
+// svgPlotPainter writes the plot as SVG elements
+type svgPlotPainter struct {
+	pl  *Plot2D
+	buf bytes.Buffer
+}
+
+// svgColor returns the SVG color and opacity attribute for given color,
+// with "none" for nil colors
+func svgColor(attr string, clr gi.Color) string {
+	if clr.IsNil() {
+		return fmt.Sprintf("%s=\"none\"", attr)
+	}
+	s := fmt.Sprintf("%s=\"#%02x%02x%02x\"", attr, clr.R, clr.G, clr.B)
+	if clr.A < 255 {
+		s += fmt.Sprintf(" %s-opacity=\"%.3g\"", attr, float32(clr.A)/255)
+	}
+	return s
+}
+
+// svgEscape escapes text for SVG
+func svgEscape(str string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(str))
+	return b.String()
+}
+
+func (sp *svgPlotPainter) Line(pts []mat32.Vec2, clr gi.Color, width float32, dash bool) {
+	fmt.Fprintf(&sp.buf, "<polyline fill=\"none\" %s stroke-width=\"%g\"", svgColor("stroke", clr), width)
+	if dash {
+		fmt.Fprintf(&sp.buf, " stroke-dasharray=\"4,4\"")
+	}
+	fmt.Fprintf(&sp.buf, " points=\"")
+	for i, p := range pts {
+		if i > 0 {
+			sp.buf.WriteByte(' ')
+		}
+		fmt.Fprintf(&sp.buf, "%.2f,%.2f", p.X, p.Y)
+	}
+	fmt.Fprintf(&sp.buf, "\"/>\n")
+}
+
+func (sp *svgPlotPainter) Rect(pos, sz mat32.Vec2, fill, stroke gi.Color) {
+	if !stroke.IsNil() {
+		pos = pos.AddScalar(0.5)
+		sz = sz.SubScalar(1)
+	}
+	fmt.Fprintf(&sp.buf, "<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" %s %s/>\n", pos.X, pos.Y, sz.X, sz.Y, svgColor("fill", fill), svgColor("stroke", stroke))
+}
+
+func (sp *svgPlotPainter) Circle(ctr mat32.Vec2, r float32, fill gi.Color) {
+	fmt.Fprintf(&sp.buf, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"%g\" %s/>\n", ctr.X, ctr.Y, r, svgColor("fill", fill))
+}
+
+func (sp *svgPlotPainter) Text(str string, pos mat32.Vec2, ax, ay float32, rot bool, clr gi.Color) {
+	pl := sp.pl
+	tsz := pl.TextSize(str, rot)
+	tp := pos.Sub(mat32.Vec2{ax * tsz.X, ay * tsz.Y})
+	asc := float32(pl.Sty.Font.Size.Dots) * 0.8
+	if ff := pl.Sty.Font.Face; ff != nil && ff.Face != nil {
+		asc = float32(ff.Face.Metrics().Ascent.Ceil())
+	}
+	if rot { // reads top to bottom, with the tops of the glyphs on the right
+		fmt.Fprintf(&sp.buf, "<text transform=\"translate(%.2f,%.2f) rotate(90)\" y=\"%.2f\" %s>%s</text>\n", tp.X+tsz.X, tp.Y, asc, svgColor("fill", clr), svgEscape(str))
+		return
+	}
+	fmt.Fprintf(&sp.buf, "<text x=\"%.2f\" y=\"%.2f\" %s>%s</text>\n", tp.X, tp.Y+asc, svgColor("fill", clr), svgEscape(str))
+}
@@ -101,6 +101,14 @@ type SliceViewer interface {
 
 	// ItemCtxtMenu pulls up the context menu for given slice index
 	ItemCtxtMenu(idx int)
+
+	// ExportFields returns the fields of the struct elements that are
+	// exported as columns, in order, or nil if the elements are not structs
+	ExportFields() []reflect.StructField
+
+	// UpdateIdxs updates the view index Idxs after the slice has been
+	// replaced or changed wholesale, e.g., by an import
+	UpdateIdxs()
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////
@@ -136,6 +144,7 @@ type SliceViewBase struct {
 	ViewPath         string           `desc:"a record of parent View names that have led up to this view -- displayed as extra contextual information in view dialog windows"`
 	TmpSave          ValueView        `copy:"-" json:"-" xml:"-" desc:"value view that needs to have SaveTmp called on it whenever a change is made to one of the underlying values -- pass this down to any sub-views created from a parent"`
 	ToolbarSlice     interface{}      `copy:"-" view:"-" json:"-" xml:"-" desc:"the slice that we successfully set a toolbar for"`
+	Idxs             []int            `copy:"-" view:"-" json:"-" xml:"-" desc:"if non-nil, the slice indexes of the elements that are shown, in display order, e.g., as filtered by TableView -- all other indexes of the view (StartIdx, SelectedIdx, SelectedIdxs etc) are then positions in this list -- see SliceIdx"`
 
 	SliceSize     int     `view:"inactive" copy:"-" json:"-" xml:"-" desc:"size of slice"`
 	DispRows      int     `view:"inactive" copy:"-" json:"-" xml:"-" desc:"actual number of rows displayed = min(VisRows, SliceSize)"`
@@ -222,7 +231,8 @@ type SliceViewSignals int
 
 const (
 	// SliceViewDoubleClicked emitted during inactive mode when item
-	// double-clicked -- can be used for accepting dialog.
+	// double-clicked -- can be used for accepting dialog -- data is the
+	// slice index of the item (see SliceIdx)
 	SliceViewDoubleClicked SliceViewSignals = iota
 
 	// SliceViewInserted emitted when a new item is inserted -- data is index of new item
@@ -323,12 +333,44 @@ func (sv *SliceViewBase) RowWidgetNs() (nWidgPerRow, idxOff int) {
 }
 
 // UpdtSliceSize updates and returns the size of the slice and sets SliceSize
+// -- this is the number of elements in the view index (Idxs) if set
 func (sv *SliceViewBase) UpdtSliceSize() int {
 	sz := sv.SliceNPVal.Len()
+	if sv.Idxs != nil {
+		sz = len(sv.Idxs)
+	}
 	sv.SliceSize = sz
 	return sz
 }
 
+// SliceIdx returns the index in the slice of the element at given index in
+// the view -- these are the same unless a view index (Idxs) is set.  An index
+// at or beyond the end of the view returns the length of the slice, and -1
+// is returned as is.
+func (sv *SliceViewBase) SliceIdx(idx int) int {
+	if sv.Idxs == nil || idx < 0 {
+		return idx
+	}
+	if idx >= len(sv.Idxs) {
+		return sv.SliceNPVal.Len()
+	}
+	return sv.Idxs[idx]
+}
+
+// ViewIdx returns the index in the view of the element at given index in
+// the slice, or -1 if it is not shown -- see SliceIdx
+func (sv *SliceViewBase) ViewIdx(si int) int {
+	if sv.Idxs == nil {
+		return si
+	}
+	for i, ix := range sv.Idxs {
+		if ix == si {
+			return i
+		}
+	}
+	return -1
+}
+
 // ViewMuLock locks the ViewMu if non-nil
 func (sv *SliceViewBase) ViewMuLock() {
 	if sv.ViewMu == nil {
@@ -584,7 +626,7 @@ func (sv *SliceViewBase) UpdateSliceGrid() {
 		ridx := i * nWidgPerRow
 		si := sv.StartIdx + i // slice idx
 		issel := sv.IdxIsSelected(si)
-		val := kit.OnePtrUnderlyingValue(sv.SliceNPVal.Index(si)) // deal with pointer lists
+		val := kit.OnePtrUnderlyingValue(sv.SliceNPVal.Index(sv.SliceIdx(si))) // deal with pointer lists
 		var vv ValueView
 		if sv.Values[i] == nil {
 			vv = ToValueView(val.Interface(), "")
@@ -756,18 +798,23 @@ func (sv *SliceViewBase) SliceNewAt(idx int) {
 							// svv, _ := recv.Embed(KiT_SliceViewBase).(*SliceViewBase)
 							dlg, _ := send.(*gi.Dialog)
 							n, typ := gi.NewKiDialogValues(dlg)
+							un := ValueUndoFor(sv.This())
+							un.GroupStart()
 							updt := ownki.UpdateStart()
 							for i := 0; i < n; i++ {
 								nm := fmt.Sprintf("New%v%v", typ.Name(), idx+1+i)
-								ownki.InsertNewChild(typ, idx+1+i, nm)
+								nki := ownki.InsertNewChild(typ, idx+1+i, nm)
+								ValueUndoTreeInsert(sv.This(), nki)
 							}
 							sv.SetChanged()
 							ownki.UpdateEnd(updt)
+							un.GroupEnd()
 						}
 					})
 			}
 		}
 	} else {
+		undo := ValueUndoSnapshot(sv.Slice)
 		nval := reflect.New(kit.NonPtrType(sltyp)) // make the concrete el
 		if !slptr {
 			nval = nval.Elem() // use concrete value
@@ -778,6 +825,7 @@ func (sv *SliceViewBase) SliceNewAt(idx int) {
 			svnp.Index(idx).Set(nval)
 		}
 		svl.Elem().Set(svnp)
+		ValueUndoStru(sv.This(), sv.ViewPath, "New", sv.Slice, undo, sv.TmpSave)
 	}
 	if idx < 0 {
 		idx = sz
@@ -817,7 +865,9 @@ func (sv *SliceViewBase) SliceDeleteAt(idx int, doupdt bool) {
 	updt := sv.UpdateStart()
 	defer sv.UpdateEnd(updt)
 
+	undo := ValueUndoSnapshot(sv.Slice)
 	kit.SliceDeleteAt(sv.Slice, idx)
+	ValueUndoStru(sv.This(), sv.ViewPath, "Delete", sv.Slice, undo, sv.TmpSave)
 
 	if sv.TmpSave != nil {
 		sv.TmpSave.SaveTmp()
@@ -848,7 +898,8 @@ func (sv *SliceViewBase) ConfigToolbar() {
 	if sv.isArray || sv.IsInactive() || sv.NoAdd {
 		ndef = 1
 	}
-	if len(*tb.Children()) < ndef {
+	nexp := ndef // Export, and Import if can add
+	if len(*tb.Children()) < ndef+nexp {
 		tb.SetStretchMaxWidth()
 		tb.AddAction(gi.ActOpts{Label: "UpdtView", Icon: "update", Tooltip: "update this SliceView to reflect current state of slice"},
 			sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
@@ -863,7 +914,9 @@ func (sv *SliceViewBase) ConfigToolbar() {
 					svv.This().(SliceViewer).SliceNewAt(-1)
 				})
 		}
+		sv.ConfigExportToolbar(tb, nexp > 1)
 	}
+	ndef += nexp
 	sz := len(*tb.Children())
 	if sz > ndef {
 		for i := sz - 1; i >= ndef; i-- {
@@ -960,7 +1013,7 @@ func (sv *SliceViewBase) SliceVal(idx int) interface{} {
 		fmt.Printf("giv.SliceViewBase: slice index out of range: %v\n", idx)
 		return nil
 	}
-	val := kit.OnePtrUnderlyingValue(sv.SliceNPVal.Index(idx)) // deal with pointer lists
+	val := kit.OnePtrUnderlyingValue(sv.SliceNPVal.Index(sv.SliceIdx(idx))) // deal with pointer lists
 	vali := val.Interface()
 	return vali
 }
@@ -1487,17 +1540,19 @@ func (sv *SliceViewBase) MimeDataType() string {
 	return filecat.DataJson
 }
 
-// CopySelToMime copies selected rows to mime data
+// CopySelToMime copies selected rows to mime data, along with the rows as
+// tab-separated values in text/plain for pasting into spreadsheets
 func (sv *SliceViewBase) CopySelToMime() mimedata.Mimes {
 	nitms := len(sv.SelectedIdxs)
 	if nitms == 0 {
 		return nil
 	}
 	ixs := sv.SelectedIdxsList(false) // ascending
-	md := make(mimedata.Mimes, 0, nitms)
+	md := make(mimedata.Mimes, 0, nitms+1)
 	for _, i := range ixs {
 		sv.MimeDataIdx(&md, i)
 	}
+	md = append(md, sv.SelTSVMime())
 	return md
 }
 
@@ -1635,7 +1690,7 @@ func (sv *SliceViewBase) PasteAssign(md mimedata.Mimes, idx int) {
 	}
 	updt := sv.UpdateStart()
 	ns := sl[0]
-	sv.SliceNPVal.Index(idx).Set(reflect.ValueOf(ns).Elem())
+	sv.SliceNPVal.Index(sv.SliceIdx(idx)).Set(reflect.ValueOf(ns).Elem())
 	if sv.TmpSave != nil {
 		sv.TmpSave.SaveTmp()
 	}
@@ -1655,15 +1710,17 @@ func (sv *SliceViewBase) PasteAtIdx(md mimedata.Mimes, idx int) {
 	wupdt := sv.TopUpdateStart()
 	defer sv.TopUpdateEnd(wupdt)
 	updt := sv.UpdateStart()
+	si := sv.SliceIdx(idx)
 	for _, ns := range sl {
 		sz := svnp.Len()
 		svnp = reflect.Append(svnp, reflect.ValueOf(ns).Elem())
 		svl.Elem().Set(svnp)
-		if idx >= 0 && idx < sz {
-			reflect.Copy(svnp.Slice(idx+1, sz+1), svnp.Slice(idx, sz))
-			svnp.Index(idx).Set(reflect.ValueOf(ns).Elem())
+		if si >= 0 && si < sz {
+			reflect.Copy(svnp.Slice(si+1, sz+1), svnp.Slice(si, sz))
+			svnp.Index(si).Set(reflect.ValueOf(ns).Elem())
 			svl.Elem().Set(svnp)
 		}
+		si++
 		idx++
 	}
 
@@ -2006,7 +2063,7 @@ func (sv *SliceViewBase) KeyInputInactive(kt *key.ChordEvent) {
 		sv.UpdateSelectIdx(ni, true)
 		kt.SetProcessed()
 	case kf == gi.KeyFunEnter || kf == gi.KeyFunAccept || kt.Rune == ' ':
-		sv.SliceViewSig.Emit(sv.This(), int64(SliceViewDoubleClicked), sv.SelectedIdx)
+		sv.SliceViewSig.Emit(sv.This(), int64(SliceViewDoubleClicked), sv.SliceIdx(sv.SelectedIdx))
 		kt.SetProcessed()
 	}
 }
@@ -2031,7 +2088,7 @@ func (sv *SliceViewBase) SliceViewBaseEvents() {
 			si := svv.SelectedIdx
 			svv.UnselectAllIdxs()
 			svv.SelectIdx(si)
-			svv.SliceViewSig.Emit(svv.This(), int64(SliceViewDoubleClicked), si)
+			svv.SliceViewSig.Emit(svv.This(), int64(SliceViewDoubleClicked), svv.SliceIdx(si))
 			me.SetProcessed()
 		}
 		if me.Button == mouse.Right && me.Action == mouse.Release {
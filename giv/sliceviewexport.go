@@ -0,0 +1,664 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
+)
+
+// SliceExportFormats are the file formats that the rows of a SliceView or
+// TableView can be exported to and imported from
+type SliceExportFormats int32
+
+//go:generate stringer -type=SliceExportFormats
+
+var KiT_SliceExportFormats = kit.Enums.AddEnum(SliceExportFormatsN, kit.NotBitFlag, nil)
+
+const (
+	// SliceExportCSV is comma-separated values -- for a slice of structs,
+	// the first row has the field names
+	SliceExportCSV SliceExportFormats = iota
+
+	// SliceExportTSV is tab-separated values -- for a slice of structs,
+	// the first row has the field names
+	SliceExportTSV
+
+	// SliceExportJSON is a JSON array, with an object per struct element
+	// holding the exported fields in column order
+	SliceExportJSON
+
+	SliceExportFormatsN
+)
+
+// SliceExportExts are the file extensions for each of the SliceExportFormats,
+// as used in the Export and Import dialogs
+var SliceExportExts = []string{".csv", ".tsv", ".json"}
+
+// SliceExportFormatFor returns the export format for given file name, based
+// on its extension -- .tab and .txt files are read as TSV
+func SliceExportFormatFor(filename string) (SliceExportFormats, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return SliceExportCSV, nil
+	case ".tsv", ".tab", ".txt":
+		return SliceExportTSV, nil
+	case ".json":
+		return SliceExportJSON, nil
+	}
+	return SliceExportCSV, fmt.Errorf("giv.SliceExportFormatFor: file: %v does not have a .csv, .tsv or .json extension", filename)
+}
+
+// Delim returns the field delimiter for the CSV and TSV formats
+func (ef SliceExportFormats) Delim() rune {
+	if ef == SliceExportTSV {
+		return '\t'
+	}
+	return ','
+}
+
+// SliceImportErrors is returned by the import methods when some rows of the
+// file could not be imported -- each error names the row (starting at 1 for
+// the first data row) and the problem.  The other rows are still imported.
+type SliceImportErrors []error
+
+func (se SliceImportErrors) Error() string {
+	strs := make([]string, len(se))
+	for i, err := range se {
+		strs[i] = err.Error()
+	}
+	return strings.Join(strs, "\n")
+}
+
+// SliceExportString returns the string used to export given field or element
+// value -- enum names, encoding.TextMarshaler (e.g., time.Time), or
+// kit.ToString, such that SliceSetFromString can read it back
+func SliceExportString(fv reflect.Value) string {
+	if !fv.IsValid() {
+		return ""
+	}
+	if fv.Kind() == reflect.Ptr && fv.IsNil() {
+		return ""
+	}
+	it := fv.Interface()
+	if kit.Enums.TypeRegistered(fv.Type()) {
+		if kit.Enums.IsBitFlag(fv.Type()) {
+			return kit.BitFlagsToString(kit.EnumIfaceToInt64(it), it)
+		}
+		return kit.ToString(it)
+	}
+	if tm, ok := it.(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		if err == nil {
+			return string(b)
+		}
+	}
+	return kit.ToString(it)
+}
+
+// enumFromStringer is the FromString method generated by stringer for enums
+type enumFromStringer interface {
+	FromString(s string) error
+}
+
+// SliceSetFromString sets given settable field or element value from string,
+// using enum names, encoding.TextUnmarshaler, or kit.SetRobust conversion --
+// an empty string sets the zero value
+func SliceSetFromString(fv reflect.Value, str string) error {
+	if str == "" {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+	pv := fv.Addr()
+	if kit.Enums.TypeRegistered(fv.Type()) {
+		if fs, ok := pv.Interface().(enumFromStringer); ok && !kit.Enums.IsBitFlag(fv.Type()) {
+			return fs.FromString(str) // reports unknown names, unlike kit
+		}
+		return kit.Enums.SetAnyEnumValueFromString(pv, str)
+	}
+	if tu, ok := pv.Interface().(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(str))
+	}
+	if !kit.SetRobust(pv.Interface(), str) {
+		return fmt.Errorf("cannot convert: %q to type: %v", str, fv.Type())
+	}
+	return nil
+}
+
+// ExportFields returns the fields of the struct elements of the slice that
+// are exported as columns, in order -- all the viewable fields for a
+// SliceView, and nil if the elements are not structs, in which case each
+// row is a single value
+func (sv *SliceViewBase) ExportFields() []reflect.StructField {
+	if kit.IfaceIsNil(sv.Slice) {
+		return nil
+	}
+	styp := kit.NonPtrType(kit.SliceElType(sv.Slice))
+	if styp.Kind() != reflect.Struct {
+		return nil
+	}
+	var flds []reflect.StructField
+	kit.FlatFieldsTypeFunc(styp, func(typ reflect.Type, fld reflect.StructField) bool {
+		if fld.PkgPath != "" || fld.Tag.Get("view") == "-" {
+			return true
+		}
+		if typ != styp {
+			if rfld, has := styp.FieldByName(fld.Name); has {
+				flds = append(flds, rfld)
+			}
+			return true
+		}
+		flds = append(flds, fld)
+		return true
+	})
+	return flds
+}
+
+// UpdateIdxs updates the view index Idxs after the slice has been replaced
+// or changed wholesale, e.g., by an import -- there is no view index in the
+// base, so this does nothing
+func (sv *SliceViewBase) UpdateIdxs() {
+}
+
+// exportElem returns the value of the element at given slice index, with
+// pointers removed -- invalid for a nil pointer element
+func (sv *SliceViewBase) exportElem(si int) reflect.Value {
+	ev := sv.SliceNPVal.Index(si)
+	for ev.Kind() == reflect.Ptr || ev.Kind() == reflect.Interface {
+		if ev.IsNil() {
+			return reflect.Value{}
+		}
+		ev = ev.Elem()
+	}
+	return ev
+}
+
+// ExportRow returns the strings for the row at given view index, for given
+// export fields (see ExportFields)
+func (sv *SliceViewBase) ExportRow(idx int, flds []reflect.StructField) []string {
+	ev := sv.exportElem(sv.SliceIdx(idx))
+	if flds == nil {
+		return []string{SliceExportString(ev)}
+	}
+	rec := make([]string, len(flds))
+	if !ev.IsValid() {
+		return rec
+	}
+	for i, fld := range flds {
+		rec[i] = SliceExportString(ev.FieldByIndex(fld.Index))
+	}
+	return rec
+}
+
+// ExportCSV writes the rows of the view, in the current (sorted, filtered)
+// order, as values separated by delim (e.g., ',' or '\t') -- struct elements
+// have a header row of field names
+func (sv *SliceViewBase) ExportCSV(w io.Writer, delim rune) error {
+	if kit.IfaceIsNil(sv.Slice) {
+		return nil
+	}
+	flds := sv.This().(SliceViewer).ExportFields()
+	cw := csv.NewWriter(w)
+	cw.Comma = delim
+	if flds != nil {
+		hdr := make([]string, len(flds))
+		for i, fld := range flds {
+			hdr[i] = fld.Name
+		}
+		cw.Write(hdr)
+	}
+	sv.ViewMuLock()
+	sz := sv.This().(SliceViewer).UpdtSliceSize()
+	for i := 0; i < sz; i++ {
+		cw.Write(sv.ExportRow(i, flds))
+	}
+	sv.ViewMuUnlock()
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportJSON writes the rows of the view, in the current (sorted, filtered)
+// order, as a JSON array -- struct elements are written as objects with the
+// export fields in column order
+func (sv *SliceViewBase) ExportJSON(w io.Writer) error {
+	if kit.IfaceIsNil(sv.Slice) {
+		return nil
+	}
+	flds := sv.This().(SliceViewer).ExportFields()
+	var b bytes.Buffer
+	b.WriteString("[")
+	sv.ViewMuLock()
+	sz := sv.This().(SliceViewer).UpdtSliceSize()
+	var err error
+	for i := 0; i < sz && err == nil; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		ev := sv.exportElem(sv.SliceIdx(i))
+		if flds == nil || !ev.IsValid() {
+			err = writeJSONVal(&b, ev)
+			continue
+		}
+		b.WriteString("{")
+		for fi, fld := range flds {
+			if fi > 0 {
+				b.WriteString(",")
+			}
+			nb, _ := json.Marshal(fld.Name)
+			b.Write(nb)
+			b.WriteString(":")
+			if err = writeJSONVal(&b, ev.FieldByIndex(fld.Index)); err != nil {
+				break
+			}
+		}
+		b.WriteString("}")
+	}
+	sv.ViewMuUnlock()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	b.WriteString("]")
+	var ib bytes.Buffer
+	if err := json.Indent(&ib, b.Bytes(), "", "  "); err != nil {
+		log.Println(err)
+		return err
+	}
+	ib.WriteString("\n")
+	_, err = ib.WriteTo(w)
+	return err
+}
+
+func writeJSONVal(b *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		b.WriteString("null")
+		return nil
+	}
+	vb, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	b.Write(vb)
+	return nil
+}
+
+// Export writes the rows of the view, in the current (sorted, filtered)
+// order, to given file, in the format given by its extension (see
+// SliceExportFormatFor), using the export fields (see ExportFields)
+func (sv *SliceViewBase) Export(filename gi.FileName) error {
+	ef, err := SliceExportFormatFor(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	fp, err := os.Create(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	if ef == SliceExportJSON {
+		err = sv.ExportJSON(fp)
+	} else {
+		err = sv.ExportCSV(fp, ef.Delim())
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// importField returns the field of given struct type to import the column of
+// given name into -- any exported field can be imported, not just the
+// export fields, and names are matched ignoring case if there is no exact match
+func importField(styp reflect.Type, name string) (reflect.StructField, bool) {
+	name = strings.TrimSpace(name)
+	if fld, has := styp.FieldByName(name); has && fld.PkgPath == "" {
+		return fld, true
+	}
+	fld, has := styp.FieldByNameFunc(func(fn string) bool {
+		return strings.EqualFold(fn, name)
+	})
+	if has && fld.PkgPath == "" {
+		return fld, true
+	}
+	return fld, false
+}
+
+// importNewElem returns a new element of given slice element type, along
+// with the (struct) value to set from the imported row
+func importNewElem(etyp reflect.Type) (nel, val reflect.Value) {
+	if etyp.Kind() == reflect.Ptr {
+		nel = reflect.New(etyp.Elem())
+		return nel, nel.Elem()
+	}
+	nel = reflect.New(etyp).Elem()
+	return nel, nel
+}
+
+// importCheck returns an error if elements cannot be imported into the slice
+func (sv *SliceViewBase) importCheck() error {
+	if kit.IfaceIsNil(sv.Slice) {
+		return errors.New("giv.SliceView Import: no slice to import into")
+	}
+	if sv.isArray {
+		return errors.New("giv.SliceView Import: cannot import into an array")
+	}
+	etyp := kit.SliceElType(sv.Slice)
+	if etyp.Kind() == reflect.Interface || kit.NonPtrType(etyp).Kind() == reflect.Interface || ki.IsKi(etyp) {
+		return fmt.Errorf("giv.SliceView Import: cannot import into slice of: %v elements", etyp)
+	}
+	return nil
+}
+
+// ImportCSV imports the rows of values separated by delim (e.g., ',' or '\t')
+// from given reader into the slice, replacing its contents unless appnd is
+// true -- see ReadSliceCSV for details.  Rows with errors are skipped, with
+// the errors returned as SliceImportErrors.
+func (sv *SliceViewBase) ImportCSV(r io.Reader, delim rune, appnd bool) error {
+	if err := sv.importCheck(); err != nil {
+		log.Println(err)
+		return err
+	}
+	nsl, err := ReadSliceCSV(r, delim, sv.SliceNPVal.Type())
+	if !nsl.IsValid() {
+		log.Println(err)
+		return err
+	}
+	sv.importSet(nsl, appnd)
+	return err
+}
+
+// ReadSliceCSV reads the rows of values separated by delim (e.g., ',' or
+// '\t') from given reader into a new slice of given type.  For struct
+// elements the first row names the fields for each column.  Values are
+// converted to the field type (see SliceSetFromString), and rows with
+// conversion errors are skipped, with the errors returned as
+// SliceImportErrors along with the slice.  Other errors return an invalid
+// slice value.
+func ReadSliceCSV(r io.Reader, delim rune, sltyp reflect.Type) (reflect.Value, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = delim == '\t'
+	cr.ReuseRecord = true
+
+	etyp := sltyp.Elem()
+	styp := kit.NonPtrType(etyp)
+	isStru := styp.Kind() == reflect.Struct
+	var errs SliceImportErrors
+	var cols []*reflect.StructField
+	if isStru {
+		hdr, err := cr.Read()
+		if err != nil {
+			if err == io.EOF {
+				err = errors.New("giv.SliceView ImportCSV: no header row of field names")
+			}
+			return reflect.Value{}, err
+		}
+		cols = make([]*reflect.StructField, len(hdr))
+		for i, nm := range hdr {
+			if fld, has := importField(styp, nm); has {
+				cols[i] = &fld
+			} else {
+				errs = append(errs, fmt.Errorf("column %d: %q is not a field of %v -- ignored", i+1, nm, styp.Name()))
+			}
+		}
+	}
+	nsl := reflect.MakeSlice(sltyp, 0, 0)
+	for row := 1; ; row++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return reflect.Value{}, err
+			}
+			errs = append(errs, fmt.Errorf("row %d: %v", row, err))
+			continue
+		}
+		nel, val := importNewElem(etyp)
+		var rerr error
+		if !isStru {
+			if len(rec) > 0 {
+				rerr = SliceSetFromString(val, rec[0])
+			}
+		} else {
+			for i, str := range rec {
+				if i >= len(cols) || cols[i] == nil {
+					continue
+				}
+				if err := SliceSetFromString(val.FieldByIndex(cols[i].Index), str); err != nil {
+					rerr = fmt.Errorf("field %v: %v", cols[i].Name, err)
+					break
+				}
+			}
+		}
+		if rerr != nil {
+			errs = append(errs, fmt.Errorf("row %d: %v", row, rerr))
+			continue
+		}
+		nsl = reflect.Append(nsl, nel)
+	}
+	if len(errs) > 0 {
+		return nsl, errs
+	}
+	return nsl, nil
+}
+
+// ImportJSON imports a JSON array from given reader into the slice, replacing
+// its contents unless appnd is true.  For struct elements, each row is an
+// object whose keys name the fields.  Rows with errors are skipped, with the
+// errors returned as SliceImportErrors.
+func (sv *SliceViewBase) ImportJSON(r io.Reader, appnd bool) error {
+	if err := sv.importCheck(); err != nil {
+		log.Println(err)
+		return err
+	}
+	var rows []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		log.Println(err)
+		return err
+	}
+	etyp := kit.SliceElType(sv.Slice)
+	styp := kit.NonPtrType(etyp)
+	isStru := styp.Kind() == reflect.Struct
+	var errs SliceImportErrors
+	bad := map[string]bool{}
+	nsl := reflect.MakeSlice(sv.SliceNPVal.Type(), 0, len(rows))
+	for ri, rb := range rows {
+		nel, val := importNewElem(etyp)
+		var rerr error
+		if !isStru {
+			rerr = json.Unmarshal(rb, val.Addr().Interface())
+		} else {
+			var obj map[string]json.RawMessage
+			rerr = json.Unmarshal(rb, &obj)
+			for nm, fb := range obj {
+				fld, has := importField(styp, nm)
+				if !has {
+					if !bad[nm] {
+						bad[nm] = true
+						errs = append(errs, fmt.Errorf("row %d: %q is not a field of %v -- ignored", ri+1, nm, styp.Name()))
+					}
+					continue
+				}
+				if err := json.Unmarshal(fb, val.FieldByIndex(fld.Index).Addr().Interface()); err != nil {
+					rerr = fmt.Errorf("field %v: %v", fld.Name, err)
+					break
+				}
+			}
+		}
+		if rerr != nil {
+			errs = append(errs, fmt.Errorf("row %d: %v", ri+1, rerr))
+			continue
+		}
+		nsl = reflect.Append(nsl, nel)
+	}
+	sv.importSet(nsl, appnd)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// importSet sets the slice to the imported elements, or appends them, and
+// updates the view
+func (sv *SliceViewBase) importSet(nsl reflect.Value, appnd bool) {
+	wupdt := sv.TopUpdateStart()
+	defer sv.TopUpdateEnd(wupdt)
+
+	updt := sv.UpdateStart()
+	sv.ViewMuLock()
+	svl := reflect.ValueOf(sv.Slice)
+	if appnd {
+		nsl = reflect.AppendSlice(sv.SliceNPVal, nsl)
+	}
+	svl.Elem().Set(nsl)
+	sv.SliceNPVal = kit.NonPtrValue(svl)
+	sv.This().(SliceViewer).UpdateIdxs()
+	sv.ViewMuUnlock()
+
+	sv.ResetSelectedIdxs()
+	sv.SelectedIdx = -1
+	if sv.TmpSave != nil {
+		sv.TmpSave.SaveTmp()
+	}
+	sv.SetChanged()
+	if sv.This().(SliceViewer).IsConfiged() {
+		sv.This().(SliceViewer).ScrollBar().SetFullReRender()
+		sv.Update()
+	} else {
+		sv.This().(SliceViewer).Config()
+	}
+	sv.SetFullReRender()
+	sv.UpdateEnd(updt)
+}
+
+// Import reads the rows in given file into the slice, replacing its contents
+// unless appnd is true, in the format given by its extension (see
+// SliceExportFormatFor) -- see ImportCSV and ImportJSON for details.  If
+// some of the rows could not be imported, the error is SliceImportErrors.
+func (sv *SliceViewBase) Import(filename gi.FileName, appnd bool) error {
+	ef, err := SliceExportFormatFor(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	fp, err := os.Open(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	if ef == SliceExportJSON {
+		return sv.ImportJSON(fp, appnd)
+	}
+	return sv.ImportCSV(fp, ef.Delim(), appnd)
+}
+
+// SelTSVMime returns the selected rows, in view order, as tab-separated
+// values in a text/plain mime data element, so that a copy can be pasted
+// into a spreadsheet -- struct elements have a header row of field names
+func (sv *SliceViewBase) SelTSVMime() *mimedata.Data {
+	flds := sv.This().(SliceViewer).ExportFields()
+	var b bytes.Buffer
+	cw := csv.NewWriter(&b)
+	cw.Comma = '\t'
+	if flds != nil {
+		hdr := make([]string, len(flds))
+		for i, fld := range flds {
+			hdr[i] = fld.Name
+		}
+		cw.Write(hdr)
+	}
+	sv.ViewMuLock()
+	for _, i := range sv.SelectedIdxsList(false) {
+		cw.Write(sv.ExportRow(i, flds))
+	}
+	sv.ViewMuUnlock()
+	cw.Flush()
+	return &mimedata.Data{Type: filecat.TextPlain, Data: b.Bytes()}
+}
+
+// ExportDialog opens a file dialog to choose a .csv, .tsv or .json file to
+// export the rows of the view to (see Export)
+func (sv *SliceViewBase) ExportDialog() {
+	FileViewDialog(sv.ViewportSafe(), "", strings.Join(SliceExportExts, ","), DlgOpts{Title: "Export", Prompt: "Export the rows of the view, in the current order, to a .csv, .tsv or .json file"}, nil,
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				svv := recv.Embed(KiT_SliceViewBase).(*SliceViewBase)
+				dlg, _ := send.Embed(gi.KiT_Dialog).(*gi.Dialog)
+				fn := FileViewDialogValue(dlg)
+				if err := svv.Export(gi.FileName(fn)); err != nil {
+					gi.PromptDialog(svv.ViewportSafe(), gi.DlgOpts{Title: "Export Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+				}
+			}
+		})
+}
+
+// ImportDialog opens a file dialog to choose a .csv, .tsv or .json file to
+// import rows from, and then asks whether to replace the slice contents or
+// append to them (see Import) -- any rows that could not be imported are
+// reported in a dialog
+func (sv *SliceViewBase) ImportDialog() {
+	FileViewDialog(sv.ViewportSafe(), "", strings.Join(SliceExportExts, ","), DlgOpts{Title: "Import", Prompt: "Import rows from a .csv, .tsv or .json file"}, nil,
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			svv := recv.Embed(KiT_SliceViewBase).(*SliceViewBase)
+			dlg, _ := send.Embed(gi.KiT_Dialog).(*gi.Dialog)
+			fn := gi.FileName(FileViewDialogValue(dlg))
+			gi.ChoiceDialog(svv.ViewportSafe(), gi.DlgOpts{Title: "Replace or Append?",
+				Prompt: fmt.Sprintf("Replace the current rows with those imported from: %v, or append the imported rows?", fn)},
+				[]string{"Replace", "Append", "Cancel"},
+				svv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					if sig != 0 && sig != 1 {
+						return
+					}
+					svv := recv.Embed(KiT_SliceViewBase).(*SliceViewBase)
+					if err := svv.Import(fn, sig == 1); err != nil {
+						gi.PromptDialog(svv.ViewportSafe(), gi.DlgOpts{Title: "Import Errors", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+					}
+				})
+		})
+}
+
+// ConfigExportToolbar adds Export and optionally Import actions to given
+// toolbar, returning the number of actions added
+func (sv *SliceViewBase) ConfigExportToolbar(tb *gi.ToolBar, imp bool) int {
+	tb.AddAction(gi.ActOpts{Label: "Export", Icon: "file-save", Tooltip: "export the rows of the view, in the current order, to a .csv, .tsv or .json file"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv := recv.Embed(KiT_SliceViewBase).(*SliceViewBase)
+			svv.ExportDialog()
+		})
+	if !imp {
+		return 1
+	}
+	tb.AddAction(gi.ActOpts{Label: "Import", Icon: "file-open", Tooltip: "import rows from a .csv, .tsv or .json file, replacing or appending to the current rows"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv := recv.Embed(KiT_SliceViewBase).(*SliceViewBase)
+			svv.ImportDialog()
+		})
+	return 2
+}
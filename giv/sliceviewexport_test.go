@@ -0,0 +1,113 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goki/ki/kit"
+)
+
+type exportTest struct {
+	Name  string
+	N     int
+	Val   float64
+	On    bool
+	Time  time.Time
+	Merge MergeChoices
+	priv  int
+}
+
+// exportTestView returns an unconfigured SliceView on given slice pointer,
+// for exporting
+func exportTestView(sl interface{}) *SliceView {
+	sv := &SliceView{}
+	sv.InitName(sv, "sv")
+	sv.Slice = sl
+	sv.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(sl))
+	return sv
+}
+
+func TestSliceExportString(t *testing.T) {
+	tm := time.Date(2020, 5, 4, 3, 2, 1, 0, time.UTC)
+	et := exportTest{Name: "a", N: -3, Val: 1.5, On: true, Time: tm, Merge: MergeChoices(1)}
+	ev := reflect.ValueOf(&et).Elem()
+	for i := 0; i < ev.NumField()-1; i++ {
+		fv := ev.Field(i)
+		str := SliceExportString(fv)
+		nv := reflect.New(fv.Type()).Elem()
+		if err := SliceSetFromString(nv, str); err != nil {
+			t.Errorf("SliceSetFromString %v = %q: %v", ev.Type().Field(i).Name, str, err)
+			continue
+		}
+		if !reflect.DeepEqual(nv.Interface(), fv.Interface()) {
+			t.Errorf("round trip %v: %v != %v via %q", ev.Type().Field(i).Name, nv.Interface(), fv.Interface(), str)
+		}
+	}
+	if str := SliceExportString(ev.FieldByName("Merge")); str != MergeChoices(1).String() {
+		t.Errorf("enum exported as %q, expected name %q", str, MergeChoices(1).String())
+	}
+	var n int
+	if err := SliceSetFromString(reflect.ValueOf(&n).Elem(), "x1"); err == nil {
+		t.Errorf("SliceSetFromString: no error for bad int")
+	}
+	mc := MergeChoices(1)
+	if err := SliceSetFromString(reflect.ValueOf(&mc).Elem(), "NotAChoice"); err == nil {
+		t.Errorf("SliceSetFromString: no error for bad enum name")
+	}
+}
+
+func TestSliceCSV(t *testing.T) {
+	tm := time.Date(2020, 5, 4, 3, 2, 1, 0, time.UTC)
+	data := []exportTest{
+		{Name: "a", N: 1, Val: 0.5, On: true, Time: tm},
+		{Name: "b, \"c\"\nd", N: 2, Merge: MergeChoices(1)},
+	}
+	for _, delim := range []rune{',', '\t'} {
+		var b bytes.Buffer
+		if err := exportTestView(&data).ExportCSV(&b, delim); err != nil {
+			t.Fatal(err)
+		}
+		hdr := strings.SplitN(b.String(), "\n", 2)[0]
+		if exp := strings.Join([]string{"Name", "N", "Val", "On", "Time", "Merge"}, string(delim)); hdr != exp {
+			t.Errorf("ExportCSV header %q != %q", hdr, exp)
+		}
+		nsl, err := ReadSliceCSV(&b, delim, reflect.TypeOf(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nd := nsl.Interface().([]exportTest); !reflect.DeepEqual(nd, data) {
+			t.Errorf("CSV round trip with delim %q:\n%+v !=\n%+v", delim, nd, data)
+		}
+	}
+
+	// columns by name in any order and case, bad rows and columns skipped
+	csv := "n,Bogus,NAME\n5,x,e\nbad,x,f\n6,x,g\n"
+	nsl, err := ReadSliceCSV(strings.NewReader(csv), ',', reflect.TypeOf([]*exportTest{}))
+	errs, ok := err.(SliceImportErrors)
+	if !ok || len(errs) != 2 {
+		t.Errorf("ReadSliceCSV: expected 2 errors (column, row), got: %v", err)
+	}
+	exp := []*exportTest{{Name: "e", N: 5}, {Name: "g", N: 6}}
+	if !reflect.DeepEqual(nsl.Interface(), exp) {
+		t.Errorf("ReadSliceCSV pointers: %+v != %+v", nsl.Interface(), exp)
+	}
+	if _, err := ReadSliceCSV(strings.NewReader(""), ',', reflect.TypeOf(data)); err == nil {
+		t.Errorf("ReadSliceCSV: no error for missing header")
+	}
+
+	// non-struct elements are one value per row
+	ints := []int{3, 1, 2}
+	var b bytes.Buffer
+	exportTestView(&ints).ExportCSV(&b, ',')
+	nsl, err = ReadSliceCSV(&b, ',', reflect.TypeOf(ints))
+	if err != nil || !reflect.DeepEqual(nsl.Interface(), ints) {
+		t.Errorf("ReadSliceCSV ints: %v, %v", nsl, err)
+	}
+}
@@ -31,7 +31,9 @@ type StructView struct {
 	ViewPath      string            `desc:"a record of parent View names that have led up to this view -- displayed as extra contextual information in view dialog windows"`
 	ToolbarStru   interface{}       `desc:"the struct that we successfully set a toolbar for"`
 	HasDefs       bool              `json:"-" xml:"-" view:"inactive" desc:"if true, some fields have default values -- update labels when values change"`
+	HasValid      bool              `json:"-" xml:"-" view:"inactive" desc:"if true, some fields have validation tags -- update labels when values change"`
 	TypeFieldTags map[string]string `json:"-" xml:"-" view:"inactive" desc:"extra tags by field name -- from type properties"`
+	Errors        []error           `json:"-" xml:"-" view:"-" desc:"validation errors from the last Validate -- nil if all fields are valid"`
 }
 
 var KiT_StructView = kit.Types.AddType(&StructView{}, StructViewProps)
@@ -280,6 +282,7 @@ func (sv *StructView) ConfigStructGrid() {
 		updt = sg.UpdateStart()
 	}
 	sv.HasDefs = false
+	sv.HasValid = false
 	for i, vv := range sv.FieldViews {
 		lbl := sg.Child(i * 2).(*gi.Label)
 		vvb := vv.AsValueViewBase()
@@ -291,11 +294,14 @@ func (sv *StructView) ConfigStructGrid() {
 		if hasDef {
 			sv.HasDefs = true
 		}
+		if ValueViewHasValidTags(vv) {
+			sv.HasValid = true
+		}
 		vv.ConfigWidget(widg)
 		if !sv.IsInactive() && !inactTag {
 			vvb.ViewSig.ConnectOnly(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				svv := recv.Embed(KiT_StructView).(*StructView)
-				svv.UpdateDefaults()
+				svv.Validate() // also updates defaults
 				// note: updating vv here is redundant -- relevant field will have already updated
 				svv.Changed = true
 				if svv.ChangeFlag != nil {
@@ -358,7 +364,8 @@ func (sv *StructView) Render2D() {
 //  Tag parsing
 
 // StructViewFieldTags processes the tags for a field in a struct view, setting
-// the properties on the label or widget appropriately
+// the properties on the label or widget appropriately, including validation
+// of the current value (see StructViewFieldValidTag)
 // returns true if there were any "def" default tags -- if so, needs updating
 func StructViewFieldTags(vv ValueView, lbl *gi.Label, widg gi.Node2D, isInact bool) (hasDef, inactTag bool) {
 	vvb := vv.AsValueViewBase()
@@ -376,11 +383,8 @@ func StructViewFieldTags(vv ValueView, lbl *gi.Label, widg gi.Node2D, isInact bo
 			vv.SetTag("inactive", "true")
 		}
 	}
-	defStr := ""
-	hasDef, _, defStr = StructViewFieldDefTag(vv, lbl)
-	if ttip, has := vv.Tag("desc"); has {
-		lbl.Tooltip = defStr + ttip
-	}
+	hasDef, _, _ = StructViewFieldDefTag(vv, lbl)
+	StructViewFieldValidTag(vv, lbl) // sets tooltip, including default values
 	return
 }
 
@@ -27,6 +27,7 @@ type StructViewInline struct {
 	ViewSig       ki.Signal   `json:"-" xml:"-" view:"-" desc:"signal for valueview -- only one signal sent when a value has been set -- all related value views interconnect with each other to update when others update"`
 	ViewPath      string      `desc:"a record of parent View names that have led up to this view -- displayed as extra contextual information in view dialog windows"`
 	HasDefs       bool        `json:"-" xml:"-" view:"inactive" desc:"if true, some fields have default values -- update labels when values change"`
+	HasValid      bool        `json:"-" xml:"-" view:"inactive" desc:"if true, some fields have validation tags -- update labels when values change"`
 }
 
 var KiT_StructViewInline = kit.Types.AddType(&StructViewInline{}, StructViewInlineProps)
@@ -98,6 +99,7 @@ func (sv *StructViewInline) ConfigParts() {
 		updt = sv.Parts.UpdateStart()
 	}
 	sv.HasDefs = false
+	sv.HasValid = false
 	for i, vv := range sv.FieldViews {
 		lbl := sv.Parts.Child(i * 2).(*gi.Label)
 		vvb := vv.AsValueViewBase()
@@ -109,11 +111,14 @@ func (sv *StructViewInline) ConfigParts() {
 		if hasDef {
 			sv.HasDefs = true
 		}
+		if ValueViewHasValidTags(vv) {
+			sv.HasValid = true
+		}
 		vv.ConfigWidget(widg)
 		if !sv.IsInactive() && !inactTag {
 			vvb.ViewSig.ConnectOnly(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				svv, _ := recv.Embed(KiT_StructViewInline).(*StructViewInline)
-				svv.UpdateDefaults()
+				svv.Validate() // also updates defaults
 				// note: updating here is redundant
 				svv.ViewSig.Emit(svv.This(), 0, nil)
 			})
@@ -0,0 +1,284 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+)
+
+// Validator is an optional interface for structs, which is called to
+// validate the struct as a whole, e.g., for constraints among fields, in
+// addition to the validation tags on individual fields (see ValidateValue).
+type Validator interface {
+	// Validate returns an error describing what is wrong, or nil if valid.
+	Validate() error
+}
+
+// ValidTags are the struct field tags used for validation -- see ValidateValue
+var ValidTags = []string{"required", "min", "max", "oneof", "regexp"}
+
+// StructViewErrColor is the background color for the labels of fields with
+// invalid values in StructView and StructViewInline.
+var StructViewErrColor = gi.Color{255, 200, 200, 255}
+
+// validRegexps caches compiled regexp tags
+var validRegexps = map[string]*regexp.Regexp{}
+
+// validRegexpsMu protects validRegexps
+var validRegexpsMu sync.Mutex
+
+// validRegexp returns the compiled regexp for given tag
+func validRegexp(expr string) (*regexp.Regexp, error) {
+	validRegexpsMu.Lock()
+	defer validRegexpsMu.Unlock()
+	if re, has := validRegexps[expr]; has {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	validRegexps[expr] = re
+	return re, nil
+}
+
+// HasValidTags returns true if given field tags include any of the ValidTags
+func HasValidTags(tags reflect.StructTag) bool {
+	for _, vt := range ValidTags {
+		if _, has := tags.Lookup(vt); has {
+			return true
+		}
+	}
+	return false
+}
+
+// ValueViewHasValidTags returns true if given value view has any of the
+// ValidTags
+func ValueViewHasValidTags(vv ValueView) bool {
+	for _, vt := range ValidTags {
+		if _, has := vv.Tag(vt); has {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateValue checks the value pointed to by valPtr against the validation
+// tags returned by tagFun (e.g., ValueView.Tag or reflect.StructTag.Lookup):
+//   - required -- value must not be the zero value or a nil pointer (unless
+//     tag is "false")
+//   - min, max -- numbers must be within range, and strings, slices and maps
+//     must have a length within range
+//   - oneof -- value must be one of comma-separated list of values
+//   - regexp -- string representation of value must match regular expression
+//
+// Values that are structs are validated with StructValidate.  Returns the
+// first error found, or nil if valid.
+func ValidateValue(valPtr interface{}, tagFun func(tag string) (string, bool)) error {
+	rq, required := tagFun("required")
+	required = required && rq != "false" && rq != "-"
+	v := reflect.ValueOf(valPtr)
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || ((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()) {
+		if required { // nil is missing
+			return errors.New("a value is required")
+		}
+		return nil
+	}
+	if required && v.IsZero() {
+		return errors.New("a value is required")
+	}
+	var num float64
+	hasNum := false
+	what := "value"
+	switch {
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Float64:
+		num, hasNum = kit.ToFloat(v.Interface())
+	case v.Kind() == reflect.String || v.Kind() == reflect.Slice || v.Kind() == reflect.Map || v.Kind() == reflect.Array:
+		num = float64(v.Len())
+		hasNum = true
+		what = "length"
+	}
+	if mn, has := tagFun("min"); has && hasNum {
+		if lo, ok := kit.ToFloat(mn); ok && num < lo {
+			return fmt.Errorf("%s must be >= %v", what, mn)
+		}
+	}
+	if mx, has := tagFun("max"); has && hasNum {
+		if hi, ok := kit.ToFloat(mx); ok && num > hi {
+			return fmt.Errorf("%s must be <= %v", what, mx)
+		}
+	}
+	if oneof, has := tagFun("oneof"); has {
+		str := strings.TrimSpace(kit.ToStringPrec(valPtr, 6))
+		match := false
+		for _, ov := range strings.Split(oneof, ",") {
+			if strings.TrimSpace(ov) == str {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return fmt.Errorf("must be one of: %v", oneof)
+		}
+	}
+	if expr, has := tagFun("regexp"); has {
+		re, err := validRegexp(expr)
+		if err != nil {
+			return fmt.Errorf("invalid regexp tag: %q: %v", expr, err)
+		}
+		if !re.MatchString(kit.ToString(v.Interface())) {
+			return fmt.Errorf("must match: %v", expr)
+		}
+	}
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		if errs := StructValidate(v.Addr().Interface(), ""); len(errs) > 0 {
+			return errs[0]
+		}
+	}
+	return nil
+}
+
+// StructValidate checks the values of all fields in given struct against
+// their validation tags (see ValidateValue), and the struct itself if it
+// implements Validator.  Fields that are themselves structs are checked
+// recursively, with field names in errors represented by dots path
+// separators, starting with given path.  Returns all errors, nil if valid.
+func StructValidate(structPtr interface{}, path string) []error {
+	var errs []error
+	kit.FlatFieldsValueFunc(structPtr, func(fval interface{}, typ reflect.Type, field reflect.StructField, fieldVal reflect.Value) bool {
+		if field.PkgPath != "" { // unexported
+			return true
+		}
+		fnm := field.Name
+		if path != "" {
+			fnm = path + "." + fnm
+		}
+		if field.Type.Kind() == reflect.Struct {
+			errs = append(errs, StructValidate(fieldVal.Addr().Interface(), fnm)...)
+			return true
+		}
+		if !HasValidTags(field.Tag) {
+			return true
+		}
+		if err := ValidateValue(fieldVal.Addr().Interface(), field.Tag.Lookup); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %v", fnm, err))
+		}
+		return true
+	})
+	if vl, ok := structPtr.(Validator); ok {
+		if err := vl.Validate(); err != nil {
+			if path != "" {
+				err = fmt.Errorf("%v: %v", path, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// StructViewFieldValidTag validates the value of a field in a struct view
+// against its validation tags (see ValidateValue) -- if invalid, the label
+// is highlighted with StructViewErrColor and the error is added to its
+// tooltip.  Call after StructViewFieldDefTag, which sets the color of valid
+// labels.  Returns the error, or nil if valid.
+func StructViewFieldValidTag(vv ValueView, lbl *gi.Label) error {
+	_, hasDef := vv.Tag("def")
+	err := ValidateValue(vv.Val().Interface(), vv.Tag)
+	ttip := ""
+	if desc, has := vv.Tag("desc"); has {
+		ttip = desc
+		if hasDef {
+			dtag, _ := vv.Tag("def")
+			ttip = "[Def: " + dtag + "] " + desc
+		}
+	}
+	if err != nil {
+		lbl.CurBgColor = StructViewErrColor
+		ttip = strings.TrimSpace("[Invalid: " + err.Error() + "] " + ttip)
+	} else if !hasDef {
+		lbl.CurBgColor.SetToNil()
+	}
+	lbl.Tooltip = ttip
+	return err
+}
+
+// Validate checks the values of all fields against their validation tags
+// (see ValidateValue), and the struct itself if it implements Validator,
+// highlighting the labels of invalid fields.  Returns all errors, and sets
+// Errors to them -- nil if valid.
+func (sv *StructView) Validate() []error {
+	if kit.IfaceIsNil(sv.Struct) || !sv.IsConfiged() {
+		return nil
+	}
+	var errs []error
+	if sv.HasDefs || sv.HasValid { // otherwise no labels to update
+		sg := sv.StructGrid()
+		updt := sg.UpdateStart()
+		for i, vv := range sv.FieldViews {
+			lbl := sg.Child(i * 2).(*gi.Label)
+			StructViewFieldDefTag(vv, lbl)
+			if err := StructViewFieldValidTag(vv, lbl); err != nil {
+				errs = append(errs, fmt.Errorf("%v: %v", lbl.Text, err))
+			}
+		}
+		sg.UpdateEnd(updt)
+	}
+	if vl, ok := sv.Struct.(Validator); ok {
+		if err := vl.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	sv.Errors = errs
+	return errs
+}
+
+// CheckValid validates the struct, and if there are any errors, reports them
+// in a dialog and returns false.  This is used to prevent a StructViewDialog
+// from being accepted with invalid values.
+func (sv *StructView) CheckValid() bool {
+	errs := sv.Validate()
+	if len(errs) == 0 {
+		return true
+	}
+	msg := "Please fix the following before continuing:<br>\n"
+	for _, err := range errs {
+		msg += html.EscapeString(err.Error()) + "<br>\n"
+	}
+	gi.PromptDialog(sv.ViewportSafe(), gi.DlgOpts{Title: "Invalid Values", Prompt: msg}, gi.AddOk, gi.NoCancel, nil, nil)
+	return false
+}
+
+// Validate checks the values of all fields against their validation tags
+// (see ValidateValue), highlighting the labels of invalid fields.  Returns
+// all errors, nil if valid.
+func (sv *StructViewInline) Validate() []error {
+	if kit.IfaceIsNil(sv.Struct) || !sv.Parts.HasChildren() || !(sv.HasDefs || sv.HasValid) {
+		return nil
+	}
+	var errs []error
+	updt := sv.UpdateStart()
+	sv.SetFullReRender() // key to regen
+	for i, vv := range sv.FieldViews {
+		lbl := sv.Parts.Child(i * 2).(*gi.Label)
+		StructViewFieldDefTag(vv, lbl)
+		if err := StructViewFieldValidTag(vv, lbl); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %v", lbl.Text, err))
+		}
+	}
+	sv.UpdateEnd(updt)
+	return errs
+}
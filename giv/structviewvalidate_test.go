@@ -0,0 +1,82 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type validTest struct {
+	Name  string   `required:"+"`
+	Age   int      `min:"0" max:"150"`
+	Tags  []string `max:"2"`
+	Color string   `oneof:"red, green, blue"`
+	Code  string   `regexp:"^[A-Z]{3}$"`
+	Ptr   *int     `required:"+"`
+	Opt   *int     `min:"1"`
+}
+
+type validTestStruct struct {
+	A, B int
+}
+
+func (vt *validTestStruct) Validate() error {
+	if vt.A > vt.B {
+		return errors.New("A must be <= B")
+	}
+	return nil
+}
+
+func TestValidateValue(t *testing.T) {
+	one, zero := 1, 0
+	valid := validTest{Name: "x", Age: 10, Tags: []string{"a"}, Color: "green", Code: "ABC", Ptr: &one}
+	tests := []struct {
+		field string
+		val   interface{}
+		err   bool
+	}{
+		{"Name", "x", false},
+		{"Name", "", true},
+		{"Age", 150, false},
+		{"Age", -1, true},
+		{"Age", 151, true},
+		{"Tags", []string{"a", "b"}, false},
+		{"Tags", []string{"a", "b", "c"}, true},
+		{"Color", "blue", false},
+		{"Color", "pink", true},
+		{"Code", "XYZ", false},
+		{"Code", "XYZW", true},
+		{"Ptr", &one, false},
+		{"Ptr", &zero, true}, // zero value pointed to, as for non-pointers
+		{"Ptr", (*int)(nil), true},
+		{"Opt", (*int)(nil), false},
+		{"Opt", &zero, true},
+	}
+	for _, tst := range tests {
+		vt := valid
+		fv := reflect.ValueOf(&vt).Elem().FieldByName(tst.field)
+		fv.Set(reflect.ValueOf(tst.val))
+		sf, _ := reflect.TypeOf(vt).FieldByName(tst.field)
+		err := ValidateValue(fv.Addr().Interface(), sf.Tag.Lookup)
+		if (err != nil) != tst.err {
+			t.Errorf("ValidateValue %v = %v: got error: %v, expected error: %v", tst.field, tst.val, err, tst.err)
+		}
+	}
+}
+
+func TestStructValidate(t *testing.T) {
+	one := 1
+	if errs := StructValidate(&validTest{Name: "x", Code: "ABC", Color: "red", Ptr: &one}, ""); len(errs) != 0 {
+		t.Errorf("StructValidate: unexpected errors: %v", errs)
+	}
+	if errs := StructValidate(&validTest{}, ""); len(errs) != 4 { // Name, Color, Code, Ptr
+		t.Errorf("StructValidate: expected 4 errors, got: %v", errs)
+	}
+	if errs := StructValidate(&validTestStruct{A: 2, B: 1}, ""); len(errs) != 1 {
+		t.Errorf("StructValidate: expected Validator error, got: %v", errs)
+	}
+}
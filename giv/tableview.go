@@ -22,7 +22,7 @@ import (
 )
 
 // todo:
-// * search option, both as a search field and as simple type-to-search
+// * simple type-to-search
 // * popup menu option -- when user does right-mouse on item, a provided func is called
 //   -- use in fileview
 // * could have a native context menu for add / delete etc.
@@ -34,7 +34,10 @@ import (
 // WidgetSelected signal, and TableViewDoubleClick for double clicks (can be
 // used for closing dialogs).  If !Inactive, it is a full-featured editor with
 // multiple-selection, cut-and-paste, and drag-and-drop, reporting each action
-// taken using the TableViewSig signals
+// taken using the TableViewSig signals.  Rows can be filtered by the values
+// of the columns (see Filters), without copying the slice, and the columns
+// can be moved, hidden and resized, with their layout saved per struct type
+// (see Cols).
 type TableView struct {
 	SliceViewBase
 	StyleFunc  TableViewStyleFunc    `copy:"-" view:"-" json:"-" xml:"-" desc:"optional styling function"`
@@ -44,6 +47,14 @@ type TableView struct {
 	StruType   reflect.Type          `copy:"-" view:"-" json:"-" xml:"-" desc:"struct type for each row"`
 	VisFields  []reflect.StructField `copy:"-" view:"-" json:"-" xml:"-" desc:"the visible fields"`
 	NVisFields int                   `copy:"-" view:"-" json:"-" xml:"-" desc:"number of visible fields"`
+
+	ColFields    []reflect.StructField   `copy:"-" view:"-" json:"-" xml:"-" desc:"all the fields that can be shown as columns, in struct order -- VisFields are those that are not hidden, in the order of the column layout (see Cols)"`
+	Filters      map[string]*TableFilter `copy:"-" view:"-" json:"-" xml:"-" desc:"filters on the values of the columns, by field name -- only the rows matching all the active filters are shown, via the view index (Idxs) -- see ApplyFilters"`
+	ShowFilter   bool                    `xml:"filter" desc:"whether to show the filter bar under the header, and the find and columns controls in the toolbar -- updated from 'filter' property (bool) -- defaults to true unless Inactive"`
+	FindText     string                  `copy:"-" desc:"current text to find -- see FindAction"`
+	filtSliceLen int                     // length of the slice when Idxs was built
+	colDrag      int                     // index in VisFields of the column being dragged, -1 if none
+	colResize    bool                    // true if the column drag is resizing it
 }
 
 var KiT_TableView = kit.Types.AddType(&TableView{}, TableViewProps)
@@ -88,6 +99,7 @@ func (tv *TableView) SetSlice(sl interface{}) {
 		log.Printf("TableView requires that you pass a pointer to a slice of struct elements -- ptr doesn't point to a slice: %v\n", slpTyp.Elem().String())
 		return
 	}
+	prvTyp := tv.StruType
 	tv.Slice = sl
 	tv.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(tv.Slice))
 	struTyp := tv.StructType()
@@ -95,6 +107,11 @@ func (tv *TableView) SetSlice(sl interface{}) {
 		log.Printf("TableView requires that you pass a slice of struct elements -- type is not a Struct: %v\n", struTyp.String())
 		return
 	}
+	if struTyp != prvTyp {
+		tv.Filters = nil
+	}
+	tv.FilterIdxs()
+	tv.colDrag = -1
 	updt := tv.UpdateStart()
 	tv.ResetSelectedIdxs()
 	tv.SelectMode = false
@@ -108,6 +125,10 @@ func (tv *TableView) SetSlice(sl interface{}) {
 	if siknp, err := tv.PropTry("inact-key-nav"); err == nil {
 		tv.InactKeyNav, _ = kit.ToBool(siknp)
 	}
+	tv.ShowFilter = !tv.IsInactive()
+	if sfltp, err := tv.PropTry("filter"); err == nil {
+		tv.ShowFilter, _ = kit.ToBool(sfltp)
+	}
 	tv.Config()
 	tv.UpdateEnd(updt)
 }
@@ -127,8 +148,18 @@ func (tv *TableView) StructType() reflect.Type {
 	return tv.StruType
 }
 
-// CacheVisFields computes the number of visible fields in nVisFields and
-// caches those to skip in fieldSkip
+// ExportFields returns the visible fields, in column order, for exporting
+// the rows of the table (see CacheVisFields)
+func (tv *TableView) ExportFields() []reflect.StructField {
+	if tv.VisFields == nil && !kit.IfaceIsNil(tv.Slice) {
+		tv.CacheVisFields()
+	}
+	return tv.VisFields
+}
+
+// CacheVisFields computes the fields that can be shown as columns in
+// ColFields, and those that are visible, in the order of the column layout
+// (see Cols), in VisFields and NVisFields
 func (tv *TableView) CacheVisFields() {
 	styp := tv.StructType()
 	tv.VisFields = make([]reflect.StructField, 0, 20)
@@ -162,6 +193,8 @@ func (tv *TableView) CacheVisFields() {
 		}
 		return true
 	})
+	tv.ColFields = tv.VisFields
+	tv.VisFields = tv.Cols().Visible(tv.ColFields)
 	tv.NVisFields = len(tv.VisFields)
 }
 
@@ -259,8 +292,8 @@ func (tv *TableView) ConfigSliceGrid() {
 
 	tv.CacheVisFields()
 
-	sz := tv.This().(SliceViewer).UpdtSliceSize()
-	if sz == 0 {
+	tv.This().(SliceViewer).UpdtSliceSize()
+	if tv.SliceNPVal.Len() == 0 {
 		return
 	}
 
@@ -277,6 +310,9 @@ func (tv *TableView) ConfigSliceGrid() {
 
 	sgcfg := kit.TypeAndNameList{}
 	sgcfg.Add(gi.KiT_ToolBar, "header")
+	if tv.ShowFilter {
+		sgcfg.Add(gi.KiT_ToolBar, "filter")
+	}
 	sgcfg.Add(gi.KiT_Layout, "grid-lay")
 	sg.ConfigChildren(sgcfg, ki.UniqueNames)
 
@@ -319,6 +355,7 @@ func (tv *TableView) ConfigSliceGrid() {
 		hcfg.Add(gi.KiT_Label, "head-del")
 	}
 	sgh.ConfigChildren(hcfg, ki.NonUniqueNames) // headers SHOULD be unique, but with labels..
+	tv.ConfigFilterBar()
 
 	// at this point, we make one dummy row to get size of widgets
 
@@ -349,7 +386,7 @@ func (tv *TableView) ConfigSliceGrid() {
 			}
 		}
 		hdr.Data = fli
-		hdr.Tooltip = field.Name + " (click to sort by)"
+		hdr.Tooltip = field.Name + " (click to sort by, drag to move, drag right edge to resize)"
 		dsc := field.Tag.Get("desc")
 		if dsc != "" {
 			hdr.Tooltip += ": " + dsc
@@ -375,6 +412,7 @@ func (tv *TableView) ConfigSliceGrid() {
 		widg := ki.NewOfType(vtyp).(gi.Node2D)
 		sgf.SetChild(widg, cidx, valnm)
 		vv.ConfigWidget(widg)
+		tv.SetColWidthProps(widg, field.Name)
 	}
 
 	if !tv.IsInactive() {
@@ -423,6 +461,7 @@ func (tv *TableView) LayoutSliceGrid() bool {
 	sz := tv.This().(SliceViewer).UpdtSliceSize()
 	if sz == 0 {
 		sg.DeleteChildren(ki.DestroyKids)
+		tv.DispRows = 0
 		return false
 	}
 
@@ -464,15 +503,24 @@ func (tv *TableView) LayoutSliceGrid() bool {
 	return true
 }
 
-// LayoutHeader updates the header layout based on field widths
+// LayoutHeader updates the header layout based on field widths, along with
+// the filter bar if shown
 func (tv *TableView) LayoutHeader() {
+	tv.LayoutBar(tv.SliceHeader())
+	if fb := tv.FilterBar(); fb != nil {
+		tv.LayoutBar(fb)
+	}
+}
+
+// LayoutBar sets the widths of the items in given bar above the grid, e.g.,
+// the header, to those of the grid columns
+func (tv *TableView) LayoutBar(sgh *gi.ToolBar) {
 	_, idxOff := tv.RowWidgetNs()
 	nfld := tv.NVisFields + idxOff
-	sgh := tv.SliceHeader()
 	sgf := tv.SliceGrid()
 	spc := sgh.Spacing.Dots
 	gd := sgf.GridData[gi.Col]
-	if gd == nil {
+	if gd == nil || len(gd) < nfld || sgh.NumChildren() < nfld {
 		return
 	}
 	sumwd := float32(0)
@@ -487,7 +535,7 @@ func (tv *TableView) LayoutHeader() {
 		sumwd += wd
 	}
 	if !tv.IsInactive() {
-		mx := len(sgf.GridData[gi.Col])
+		mx := ints.MinInt(len(gd), sgh.NumChildren())
 		for fli := nfld; fli < mx; fli++ {
 			lbl := sgh.Child(fli).(gi.Node2D).AsWidget()
 			wd := gd[fli].AllocSize - spc
@@ -540,8 +588,9 @@ func (tv *TableView) UpdateSliceGrid() {
 
 	for i := 0; i < tv.DispRows; i++ {
 		ridx := i * nWidgPerRow
-		si := tv.StartIdx + i // slice idx
-		issel := tv.IdxIsSelected(si)
+		vi := tv.StartIdx + i // view idx
+		si := tv.SliceIdx(vi) // slice idx
+		issel := tv.IdxIsSelected(vi)
 		val := kit.OnePtrUnderlyingValue(tv.SliceNPVal.Index(si)) // deal with pointer lists
 		stru := val.Interface()
 
@@ -616,6 +665,7 @@ func (tv *TableView) UpdateSliceGrid() {
 				widg = ki.NewOfType(vtyp).(gi.Node2D)
 				sg.SetChild(widg, cidx, valnm)
 				vv.ConfigWidget(widg)
+				tv.SetColWidthProps(widg, field.Name)
 				wb := widg.AsWidget()
 				if wb != nil {
 					// totally not worth it now:
@@ -687,7 +737,8 @@ func (tv *TableView) UpdateSliceGrid() {
 	}
 
 	if tv.SelField != "" && tv.SelVal != nil {
-		tv.SelectedIdx, _ = StructSliceIdxByValue(tv.Slice, tv.SelField, tv.SelVal)
+		si, _ := StructSliceIdxByValue(tv.Slice, tv.SelField, tv.SelVal)
+		tv.SelectedIdx = tv.ViewIdx(si)
 	}
 	if tv.IsInactive() && tv.SelectedIdx >= 0 {
 		tv.SelectIdx(tv.SelectedIdx)
@@ -701,8 +752,9 @@ func (tv *TableView) StyleRow(svnp reflect.Value, widg gi.Node2D, idx, fidx int,
 	}
 }
 
-// SliceNewAt inserts a new blank element at given index in the slice -- -1
-// means the end
+// SliceNewAt inserts a new blank element at given index in the view -- -1
+// means the end.  If the view is filtered, the new element is shown at that
+// index until the filters are next applied.
 func (tv *TableView) SliceNewAt(idx int) {
 	wupdt := tv.TopUpdateStart()
 	defer tv.TopUpdateEnd(wupdt)
@@ -710,10 +762,20 @@ func (tv *TableView) SliceNewAt(idx int) {
 	updt := tv.UpdateStart()
 	defer tv.UpdateEnd(updt)
 
-	kit.SliceNewAt(tv.Slice, idx)
-	if idx < 0 {
-		idx = tv.SliceSize
+	si := tv.SliceIdx(idx)
+	if si >= tv.SliceNPVal.Len() {
+		si = -1
+	}
+	undo := ValueUndoSnapshot(tv.Slice)
+	kit.SliceNewAt(tv.Slice, si)
+	ValueUndoStru(tv.This(), tv.ViewPath, "New", tv.Slice, undo, tv.TmpSave)
+	if si < 0 {
+		si = tv.SliceNPVal.Len() - 1
 	}
+	if tv.Idxs != nil {
+		tv.filterInserted(idx, si)
+	}
+	idx = si
 
 	if tv.TmpSave != nil {
 		tv.TmpSave.SaveTmp()
@@ -726,10 +788,11 @@ func (tv *TableView) SliceNewAt(idx int) {
 	tv.SliceViewSig.Emit(tv.This(), int64(SliceViewInserted), idx)
 }
 
-// SliceDeleteAt deletes element at given index from slice -- doupdt means
-// call UpdateSliceGrid to update display
+// SliceDeleteAt deletes element at given index in the view from slice --
+// doupdt means call UpdateSliceGrid to update display
 func (tv *TableView) SliceDeleteAt(idx int, doupdt bool) {
-	if idx < 0 {
+	si := tv.SliceIdx(idx)
+	if si < 0 || si >= tv.SliceNPVal.Len() {
 		return
 	}
 	wupdt := tv.TopUpdateStart()
@@ -738,7 +801,13 @@ func (tv *TableView) SliceDeleteAt(idx int, doupdt bool) {
 	updt := tv.UpdateStart()
 	defer tv.UpdateEnd(updt)
 
-	kit.SliceDeleteAt(tv.Slice, idx)
+	undo := ValueUndoSnapshot(tv.Slice)
+	kit.SliceDeleteAt(tv.Slice, si)
+	ValueUndoStru(tv.This(), tv.ViewPath, "Delete", tv.Slice, undo, tv.TmpSave)
+	if tv.Idxs != nil {
+		tv.filterDeleted(idx)
+	}
+	idx = si
 
 	if tv.TmpSave != nil {
 		tv.TmpSave.SaveTmp()
@@ -760,6 +829,19 @@ func (tv *TableView) SortSlice() {
 	}
 	rawIdx := tv.VisFields[tv.SortIdx].Index
 	kit.StructSliceSort(tv.Slice, rawIdx, !tv.SortDesc)
+	if tv.Idxs != nil {
+		tv.FilterIdxs()
+	}
+}
+
+// UpdtSliceSize updates and returns the size of the slice and sets
+// SliceSize -- if the view is filtered and the length of the slice has
+// changed, the filters are first applied again
+func (tv *TableView) UpdtSliceSize() int {
+	if tv.Idxs != nil && tv.SliceNPVal.Len() != tv.filtSliceLen {
+		tv.FilterIdxs()
+	}
+	return tv.SliceViewBase.UpdtSliceSize()
 }
 
 // SortSliceAction sorts the slice for given field index -- toggles ascending
@@ -805,7 +887,7 @@ func (tv *TableView) SortSliceAction(fldIdx int) {
 
 // ConfigToolbar configures the toolbar actions
 func (tv *TableView) ConfigToolbar() {
-	if kit.IfaceIsNil(tv.Slice) || tv.IsInactive() {
+	if kit.IfaceIsNil(tv.Slice) || (tv.IsInactive() && !tv.ShowFilter) {
 		return
 	}
 	if tv.ToolbarSlice == tv.Slice {
@@ -816,13 +898,26 @@ func (tv *TableView) ConfigToolbar() {
 	if tv.isArray || tv.IsInactive() || tv.NoAdd {
 		ndef = 1
 	}
-	if len(*tb.Children()) < ndef {
+	if tv.IsInactive() {
+		ndef = 0
+	}
+	nexp := 2 // Export and Import -- see ConfigExportToolbar
+	if ndef < 2 {
+		nexp = 1
+	}
+	nfind := 0
+	if tv.ShowFilter {
+		nfind = 5 // see ConfigFindToolbar
+	}
+	if len(*tb.Children()) < ndef+nexp+nfind {
 		tb.SetStretchMaxWidth()
-		tb.AddAction(gi.ActOpts{Label: "UpdtView", Icon: "update", Tooltip: "update this TableView to reflect current state of table"},
-			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-				tvv := recv.Embed(KiT_TableView).(*TableView)
-				tvv.UpdateSliceGrid()
-			})
+		if ndef > 0 {
+			tb.AddAction(gi.ActOpts{Label: "UpdtView", Icon: "update", Tooltip: "update this TableView to reflect current state of table"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					tvv := recv.Embed(KiT_TableView).(*TableView)
+					tvv.UpdateSliceGrid()
+				})
+		}
 		if ndef > 1 {
 			tb.AddAction(gi.ActOpts{Label: "Add", Icon: "plus", Tooltip: "add a new element to the table"},
 				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
@@ -830,7 +925,12 @@ func (tv *TableView) ConfigToolbar() {
 					tvv.SliceNewAt(-1)
 				})
 		}
+		tv.ConfigExportToolbar(tb, nexp > 1)
+		if tv.ShowFilter {
+			tv.ConfigFindToolbar(tb)
+		}
 	}
+	ndef += nexp + nfind
 	sz := len(*tb.Children())
 	if sz > ndef {
 		for i := sz - 1; i >= ndef; i-- {
@@ -888,9 +988,19 @@ func (tv *TableView) Layout2D(parBBox image.Rectangle, iter int) bool {
 	}
 	tv.LayoutHeader()
 	tv.SliceHeader().Layout2D(parBBox, iter)
+	if fb := tv.FilterBar(); fb != nil {
+		fb.Layout2D(parBBox, iter)
+	}
 	return redo
 }
 
+func (tv *TableView) ConnectEvents2D() {
+	tv.SliceViewBaseEvents()
+	if tv.IsConfiged() {
+		tv.HeaderEvents()
+	}
+}
+
 // RowFirstVisWidget returns the first visible widget for given row (could be
 // index or not) -- false if out of range
 func (tv *TableView) RowFirstVisWidget(row int) (*gi.WidgetBase, bool) {
@@ -977,7 +1087,8 @@ func (tv *TableView) SelectFieldVal(fld, val string) bool {
 	tv.SelField = fld
 	tv.SelVal = val
 	if tv.SelField != "" && tv.SelVal != nil {
-		idx, _ := StructSliceIdxByValue(tv.Slice, tv.SelField, tv.SelVal)
+		si, _ := StructSliceIdxByValue(tv.Slice, tv.SelField, tv.SelVal)
+		idx := tv.ViewIdx(si)
 		if idx >= 0 {
 			tv.ScrollToIdx(idx)
 			tv.UpdateSelectIdx(idx, true)
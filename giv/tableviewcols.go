@@ -0,0 +1,404 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// TableViewCols is the layout of the columns of the TableViews of a given
+// struct type, as arranged by the user: their order, which are hidden, and
+// their widths
+type TableViewCols struct {
+	Order  []string           `desc:"names of the fields in display order -- any fields not listed are shown after these, in struct order"`
+	Hidden []string           `desc:"names of the hidden fields"`
+	Widths map[string]float32 `desc:"widths of the columns that have been resized, in Ch units, by field name"`
+}
+
+// IsEmpty returns true if the layout is the default one
+func (tc *TableViewCols) IsEmpty() bool {
+	return len(tc.Order) == 0 && len(tc.Hidden) == 0 && len(tc.Widths) == 0
+}
+
+// IsHidden returns true if the field of given name is hidden
+func (tc *TableViewCols) IsHidden(field string) bool {
+	for _, nm := range tc.Hidden {
+		if nm == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Ordered returns given fields in the display Order, including hidden ones
+func (tc *TableViewCols) Ordered(flds []reflect.StructField) []reflect.StructField {
+	ord := make([]reflect.StructField, 0, len(flds))
+	used := make(map[string]bool, len(flds))
+	for _, nm := range tc.Order {
+		for _, fld := range flds {
+			if fld.Name == nm && !used[nm] {
+				ord = append(ord, fld)
+				used[nm] = true
+				break
+			}
+		}
+	}
+	for _, fld := range flds {
+		if !used[fld.Name] {
+			ord = append(ord, fld)
+		}
+	}
+	return ord
+}
+
+// Visible returns the fields that are shown, of given fields, in display
+// order
+func (tc *TableViewCols) Visible(flds []reflect.StructField) []reflect.StructField {
+	ord := tc.Ordered(flds)
+	vis := ord[:0]
+	for _, fld := range ord {
+		if !tc.IsHidden(fld.Name) {
+			vis = append(vis, fld)
+		}
+	}
+	return vis
+}
+
+// TableViewColsPrefs are the column layouts of TableViews, by the long
+// name of their struct type (see kit.LongTypeName)
+type TableViewColsPrefs map[string]*TableViewCols
+
+// TableViewColPrefs are the column layouts of TableViews, which are opened
+// from the GoGi prefs directory when first needed, and saved whenever the
+// layout changes
+var TableViewColPrefs = TableViewColsPrefs{}
+
+// TableViewColPrefsFileName is the name of the file in the GoGi prefs
+// directory where TableViewColPrefs are saved
+var TableViewColPrefsFileName = "tableview_cols.json"
+
+var tableViewColPrefsOnce sync.Once
+
+// Open opens the column layouts from the GoGi prefs directory
+func (tp *TableViewColsPrefs) Open() error {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, TableViewColPrefsFileName)
+	b, err := ioutil.ReadFile(pnm)
+	if err != nil {
+		// log.Println(err) // ok to be non-existent
+		return err
+	}
+	return json.Unmarshal(b, tp)
+}
+
+// Save saves the column layouts to the GoGi prefs directory, omitting
+// default ones
+func (tp *TableViewColsPrefs) Save() error {
+	sv := make(TableViewColsPrefs, len(*tp))
+	for nm, tc := range *tp {
+		if !tc.IsEmpty() {
+			sv[nm] = tc
+		}
+	}
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, TableViewColPrefsFileName)
+	b, err := json.MarshalIndent(sv, "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(pnm, b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  TableView columns
+
+// Cols returns the column layout for the struct type of the slice, from
+// TableViewColPrefs, which are opened on first use
+func (tv *TableView) Cols() *TableViewCols {
+	tableViewColPrefsOnce.Do(func() {
+		TableViewColPrefs.Open()
+	})
+	nm := kit.LongTypeName(tv.StruType)
+	tc, ok := TableViewColPrefs[nm]
+	if !ok {
+		tc = &TableViewCols{}
+		TableViewColPrefs[nm] = tc
+	}
+	return tc
+}
+
+// VisFieldIdx returns the index in VisFields of the field of given name, or
+// -1 if it is not visible
+func (tv *TableView) VisFieldIdx(field string) int {
+	for fli, fld := range tv.VisFields {
+		if fld.Name == field {
+			return fli
+		}
+	}
+	return -1
+}
+
+// ColsChanged updates the view after a change in the column layout (Cols),
+// keeping the current sort field if still visible, and saves the layout
+func (tv *TableView) ColsChanged() {
+	sfn := tv.SortFieldName()
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+
+	updt := tv.UpdateStart()
+	tv.CacheVisFields()
+	tv.SortIdx = -1
+	tv.SetSortFieldName(sfn)
+	tv.Values = nil
+	tv.ConfigSliceGrid()
+	tv.LayoutSliceGrid()
+	tv.UpdateSliceGrid()
+	tv.SetFullReRender()
+	tv.UpdateEnd(updt)
+	TableViewColPrefs.Save()
+}
+
+// HideCol hides or shows the column for the field of given name -- the
+// last visible column cannot be hidden
+func (tv *TableView) HideCol(field string, hide bool) {
+	tc := tv.Cols()
+	if tc.IsHidden(field) == hide {
+		return
+	}
+	if hide {
+		if tv.NVisFields <= 1 {
+			return
+		}
+		tc.Hidden = append(tc.Hidden, field)
+	} else {
+		for i, nm := range tc.Hidden {
+			if nm == field {
+				tc.Hidden = append(tc.Hidden[:i], tc.Hidden[i+1:]...)
+				break
+			}
+		}
+	}
+	tv.ColsChanged()
+}
+
+// MoveCol moves the column for the field of given name to given position
+// among the visible columns
+func (tv *TableView) MoveCol(field string, to int) {
+	fli := tv.VisFieldIdx(field)
+	if fli < 0 || to < 0 || to >= tv.NVisFields || to == fli {
+		return
+	}
+	tc := tv.Cols()
+	ord := tc.Ordered(tv.ColFields)
+	names := make([]string, 0, len(ord))
+	for _, fld := range ord {
+		if fld.Name != field {
+			names = append(names, fld.Name)
+		}
+	}
+	tnm := tv.VisFields[to].Name
+	ti := 0
+	for i, nm := range names {
+		if nm == tnm {
+			ti = i
+			if to > fli {
+				ti++ // after target when moving right
+			}
+			break
+		}
+	}
+	names = append(names, "")
+	copy(names[ti+1:], names[ti:])
+	names[ti] = field
+	tc.Order = names
+	tv.ColsChanged()
+}
+
+// SetColWidth sets the width of the column for the field of given name, in
+// Ch units, updating the display -- call SaveCols to save the layout when
+// done, e.g., after dragging.  A width of 0 restores the automatic width.
+func (tv *TableView) SetColWidth(field string, wd float32) {
+	tc := tv.Cols()
+	if wd <= 0 {
+		if _, has := tc.Widths[field]; has {
+			delete(tc.Widths, field)
+			tv.ColsChanged()
+		}
+		return
+	}
+	if tc.Widths == nil {
+		tc.Widths = make(map[string]float32)
+	}
+	tc.Widths[field] = wd
+	fli := tv.VisFieldIdx(field)
+	sg := tv.SliceGrid()
+	if fli < 0 || sg == nil {
+		return
+	}
+	updt := tv.UpdateStart()
+	nWidgPerRow, idxOff := tv.RowWidgetNs()
+	for cidx := idxOff + fli; cidx < len(sg.Kids); cidx += nWidgPerRow {
+		if widg, ok := sg.Kids[cidx].(gi.Node2D); ok {
+			tv.SetColWidthProps(widg, field)
+		}
+	}
+	tv.SetFullReRender()
+	tv.UpdateEnd(updt)
+}
+
+// SetColWidthProps sets the width properties of given widget for the width
+// of the column for the field of given name, if it has been set
+func (tv *TableView) SetColWidthProps(widg gi.Node2D, field string) {
+	wd, has := tv.Cols().Widths[field]
+	if !has {
+		return
+	}
+	wdu := units.NewCh(wd)
+	widg.SetProp("width", wdu)
+	widg.SetProp("min-width", wdu)
+	widg.SetProp("max-width", wdu)
+}
+
+// SaveCols saves the column layouts in prefs
+func (tv *TableView) SaveCols() {
+	TableViewColPrefs.Save()
+}
+
+// ResetCols restores the default column layout, showing all columns in
+// struct order with automatic widths
+func (tv *TableView) ResetCols() {
+	tc := tv.Cols()
+	if tc.IsEmpty() {
+		return
+	}
+	*tc = TableViewCols{}
+	tv.ColsChanged()
+}
+
+// ColsMenu makes the menu of all the columns, for showing or hiding them
+func (tv *TableView) ColsMenu(m *gi.Menu) {
+	*m = make(gi.Menu, 0, len(tv.ColFields)+2)
+	tc := tv.Cols()
+	for _, fld := range tc.Ordered(tv.ColFields) {
+		ic := "checked-box"
+		if tc.IsHidden(fld.Name) {
+			ic = "unchecked-box"
+		}
+		m.AddAction(gi.ActOpts{Label: fld.Name, Icon: ic, Data: fld.Name}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			fnm := send.(*gi.Action).Data.(string)
+			tvv.HideCol(fnm, !tvv.Cols().IsHidden(fnm))
+		})
+	}
+	m.AddSeparator("reset-sep")
+	m.AddAction(gi.ActOpts{Label: "Reset Columns", Tooltip: "show all the columns, in their original order and widths"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.ResetCols()
+	})
+}
+
+// TableViewColEdgeDots is the distance in dots from the right edge of a
+// column header within which dragging resizes the column, instead of
+// moving it
+var TableViewColEdgeDots = 5
+
+// HeaderColAt returns the index in VisFields of the column whose header is
+// at given window x position, and whether the position is at the right edge
+// of the header, for resizing -- -1 if not over a column
+func (tv *TableView) HeaderColAt(x int) (int, bool) {
+	sgh := tv.SliceHeader()
+	_, idxOff := tv.RowWidgetNs()
+	for fli := 0; fli < tv.NVisFields; fli++ {
+		hdr, ok := sgh.Child(idxOff + fli).(gi.Node2D)
+		if !ok {
+			continue
+		}
+		bb := hdr.AsNode2D().WinBBox
+		if x < bb.Min.X || x >= bb.Max.X+TableViewColEdgeDots {
+			continue
+		}
+		return fli, x >= bb.Max.X-TableViewColEdgeDots
+	}
+	return -1, false
+}
+
+// HeaderEvents connects to mouse events on the header, for resizing columns
+// by dragging the right edge of their header, and moving them by dragging
+// the rest of it
+func (tv *TableView) HeaderEvents() {
+	sgh := tv.SliceHeader()
+	sgh.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		tvv := recv.ParentByType(KiT_TableView, ki.Embeds).Embed(KiT_TableView).(*TableView)
+		if tvv.colDrag < 0 {
+			fli, edge := tvv.HeaderColAt(me.From.X)
+			if fli < 0 {
+				return
+			}
+			tvv.colDrag = fli
+			tvv.colResize = edge
+		}
+		me.SetProcessed()
+		if !tvv.colResize || tvv.colDrag >= tvv.NVisFields {
+			return
+		}
+		_, idxOff := tvv.RowWidgetNs()
+		hdr := tvv.SliceHeader().Child(idxOff + tvv.colDrag).(gi.Node2D).AsNode2D()
+		wd := float32(me.Where.X-hdr.WinBBox.Min.X) / tvv.Sty.UnContext.ToDotsFactor(units.Ch)
+		tvv.SetColWidth(tvv.VisFields[tvv.colDrag].Name, math32.Max(wd, 2))
+	})
+	sgh.ConnectEvent(oswin.MouseEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		tvv := recv.ParentByType(KiT_TableView, ki.Embeds).Embed(KiT_TableView).(*TableView)
+		if me.Action == mouse.Press {
+			tvv.colDrag = -1
+			return
+		}
+		if me.Action != mouse.Release || tvv.colDrag < 0 {
+			return
+		}
+		me.SetProcessed()
+		fli := tvv.colDrag
+		tvv.colDrag = -1
+		if fli >= tvv.NVisFields {
+			return
+		}
+		_, idxOff := tvv.RowWidgetNs()
+		if hdr, ok := tvv.SliceHeader().Child(idxOff + fli).(*gi.Action); ok {
+			hdr.SetButtonState(gi.ButtonActive)
+		}
+		if tvv.colResize {
+			tvv.SaveCols()
+			return
+		}
+		to, _ := tvv.HeaderColAt(me.Where.X)
+		if to < 0 {
+			if me.Where.X < tvv.SliceHeader().WinBBox.Min.X {
+				to = 0
+			} else {
+				to = tvv.NVisFields - 1
+			}
+		}
+		tvv.MoveCol(tvv.VisFields[fli].Name, to)
+	})
+}
@@ -0,0 +1,610 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// TableFilterTypes are the types of filters on the values of a TableView
+// column, determined by the type of the field -- see TableFilterTypeFor
+type TableFilterTypes int32
+
+//go:generate stringer -type=TableFilterTypes
+
+var KiT_TableFilterTypes = kit.Enums.AddEnum(TableFilterTypesN, kit.NotBitFlag, nil)
+
+const (
+	// TableFilterContains matches values whose string representation
+	// contains the filter Text, ignoring case
+	TableFilterContains TableFilterTypes = iota
+
+	// TableFilterRange matches numeric values within the Min and / or Max
+	// of the filter, inclusive
+	TableFilterRange
+
+	// TableFilterSet matches enum or bool values that are one of those in
+	// the filter Set
+	TableFilterSet
+
+	TableFilterTypesN
+)
+
+// TableFilter is a filter on the values of one column of a TableView
+type TableFilter struct {
+	Field  string           `desc:"name of the field that is filtered"`
+	Type   TableFilterTypes `desc:"type of filter, determined by the type of the field"`
+	Text   string           `desc:"for Contains, the text that values must contain, ignoring case"`
+	Min    float64          `desc:"for Range, the minimum value, if HasMin"`
+	Max    float64          `desc:"for Range, the maximum value, if HasMax"`
+	HasMin bool             `desc:"for Range, whether there is a minimum"`
+	HasMax bool             `desc:"for Range, whether there is a maximum"`
+	Set    []string         `desc:"for Set, the values that are shown"`
+}
+
+// TableFilterTypeFor returns the type of filter for fields of given type
+func TableFilterTypeFor(typ reflect.Type) TableFilterTypes {
+	if typ.Kind() == reflect.Bool {
+		return TableFilterSet
+	}
+	if kit.Enums.TypeRegistered(typ) && !kit.Enums.IsBitFlag(typ) {
+		return TableFilterSet
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return TableFilterRange
+	}
+	return TableFilterContains
+}
+
+// TableFilterValues returns the possible values of fields of given type, for
+// a Set filter
+func TableFilterValues(typ reflect.Type) []string {
+	if typ.Kind() == reflect.Bool {
+		return []string{"false", "true"}
+	}
+	evs := kit.Enums.TypeValues(typ, false)
+	vals := make([]string, len(evs))
+	for i, ev := range evs {
+		vals[i] = ev.Name
+	}
+	return vals
+}
+
+// IsActive returns true if the filter excludes any values
+func (tf *TableFilter) IsActive() bool {
+	switch tf.Type {
+	case TableFilterRange:
+		return tf.HasMin || tf.HasMax
+	case TableFilterSet:
+		return len(tf.Set) > 0
+	default:
+		return tf.Text != ""
+	}
+}
+
+// Match returns true if given field value passes the filter
+func (tf *TableFilter) Match(fv reflect.Value) bool {
+	switch tf.Type {
+	case TableFilterRange:
+		f, ok := kit.ToFloat(fv.Interface())
+		if !ok {
+			return false
+		}
+		if tf.HasMin && f < tf.Min {
+			return false
+		}
+		if tf.HasMax && f > tf.Max {
+			return false
+		}
+		return true
+	case TableFilterSet:
+		return tf.InSet(kit.ToString(fv.Interface()))
+	default:
+		return strings.Contains(strings.ToLower(kit.ToString(fv.Interface())), strings.ToLower(tf.Text))
+	}
+}
+
+// SetText sets the filter from given text: the text to contain for
+// Contains, and min:max for Range, where either can be omitted, and a single
+// number matches just that value
+func (tf *TableFilter) SetText(txt string) error {
+	txt = strings.TrimSpace(txt)
+	if tf.Type != TableFilterRange {
+		tf.Text = txt
+		return nil
+	}
+	tf.HasMin, tf.HasMax = false, false
+	if txt == "" {
+		return nil
+	}
+	mins, maxs := txt, txt
+	if ci := strings.Index(txt, ":"); ci >= 0 {
+		mins, maxs = strings.TrimSpace(txt[:ci]), strings.TrimSpace(txt[ci+1:])
+	}
+	if mins != "" {
+		f, err := strconv.ParseFloat(mins, 64)
+		if err != nil {
+			return fmt.Errorf("giv.TableFilter: %v: minimum is not a number: %v", tf.Field, mins)
+		}
+		tf.Min, tf.HasMin = f, true
+	}
+	if maxs != "" {
+		f, err := strconv.ParseFloat(maxs, 64)
+		if err != nil {
+			tf.HasMin = false
+			return fmt.Errorf("giv.TableFilter: %v: maximum is not a number: %v", tf.Field, maxs)
+		}
+		tf.Max, tf.HasMax = f, true
+	}
+	return nil
+}
+
+// String returns the filter in the form used by SetText for Contains and
+// Range, and the list of values for Set
+func (tf *TableFilter) String() string {
+	switch tf.Type {
+	case TableFilterRange:
+		if tf.HasMin && tf.HasMax && tf.Min == tf.Max {
+			return kit.ToString(tf.Min)
+		}
+		str := ""
+		if tf.HasMin {
+			str = kit.ToString(tf.Min)
+		}
+		if tf.HasMax {
+			str += ":" + kit.ToString(tf.Max)
+		} else if tf.HasMin {
+			str += ":"
+		}
+		return str
+	case TableFilterSet:
+		return strings.Join(tf.Set, ",")
+	default:
+		return tf.Text
+	}
+}
+
+// InSet returns true if given value is in the Set
+func (tf *TableFilter) InSet(val string) bool {
+	for _, v := range tf.Set {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleSet adds given value to the Set, or removes it if already there
+func (tf *TableFilter) ToggleSet(val string) {
+	for i, v := range tf.Set {
+		if v == val {
+			tf.Set = append(tf.Set[:i], tf.Set[i+1:]...)
+			return
+		}
+	}
+	tf.Set = append(tf.Set, val)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  TableView filtering
+
+// Filter returns the filter for the field of given name, creating it if
+// needed -- returns nil if there is no such field
+func (tv *TableView) Filter(field string) *TableFilter {
+	if tf, ok := tv.Filters[field]; ok {
+		return tf
+	}
+	fld, ok := tv.StruType.FieldByName(field)
+	if !ok {
+		return nil
+	}
+	if tv.Filters == nil {
+		tv.Filters = make(map[string]*TableFilter)
+	}
+	tf := &TableFilter{Field: field, Type: TableFilterTypeFor(fld.Type)}
+	tv.Filters[field] = tf
+	return tf
+}
+
+// IsFiltered returns true if any filters are active, so only the rows in
+// the view index (Idxs) are shown
+func (tv *TableView) IsFiltered() bool {
+	for _, tf := range tv.Filters {
+		if tf.IsActive() {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateIdxs rebuilds the view index from the current Filters after the
+// slice has been replaced or changed wholesale, e.g., by an import -- must be
+// protected by ViewMu
+func (tv *TableView) UpdateIdxs() {
+	tv.FilterIdxs()
+	tv.StartIdx = 0
+}
+
+// FilterIdxs builds the view index (Idxs) of the rows of the slice that
+// match all of the active Filters, in slice order -- the slice itself is not
+// changed, and Idxs is nil if no filters are active.  Must be protected by
+// ViewMu, and does not update the display -- see ApplyFilters.
+func (tv *TableView) FilterIdxs() {
+	tv.Idxs = nil
+	if kit.IfaceIsNil(tv.Slice) {
+		return
+	}
+	sz := tv.SliceNPVal.Len()
+	tv.filtSliceLen = sz
+	var flts []*TableFilter
+	var fidxs [][]int
+	for _, tf := range tv.Filters {
+		if !tf.IsActive() {
+			continue
+		}
+		fld, ok := tv.StruType.FieldByName(tf.Field)
+		if !ok {
+			continue
+		}
+		flts = append(flts, tf)
+		fidxs = append(fidxs, fld.Index)
+	}
+	if len(flts) == 0 {
+		return
+	}
+	idxs := make([]int, 0, sz)
+	for si := 0; si < sz; si++ {
+		val := kit.OnePtrUnderlyingValue(tv.SliceNPVal.Index(si)).Elem()
+		match := true
+		for i, tf := range flts {
+			if !tf.Match(val.FieldByIndex(fidxs[i])) {
+				match = false
+				break
+			}
+		}
+		if match {
+			idxs = append(idxs, si)
+		}
+	}
+	tv.Idxs = idxs
+}
+
+// ApplyFilters rebuilds the view index for the current Filters and updates
+// the display, clearing the selection
+func (tv *TableView) ApplyFilters() {
+	if kit.IfaceIsNil(tv.Slice) {
+		return
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+
+	updt := tv.UpdateStart()
+	tv.ViewMuLock()
+	tv.FilterIdxs()
+	tv.ViewMuUnlock()
+	tv.ResetSelectedIdxs()
+	tv.SelectedIdx = -1
+	tv.StartIdx = 0
+	sb := tv.ScrollBar()
+	sb.SetValue(0)
+	sb.SetFullReRender()
+	tv.LayoutSliceGrid()
+	tv.UpdateSliceGrid()
+	tv.UpdateFilterBar()
+	tv.UpdateEnd(updt)
+}
+
+// SetFilterText sets the filter for the field of given name from given text
+// (see TableFilter.SetText), and applies the filters if it changed
+func (tv *TableView) SetFilterText(field, txt string) error {
+	tf := tv.Filter(field)
+	if tf == nil {
+		return nil
+	}
+	prv := tf.String()
+	err := tf.SetText(txt)
+	if err != nil {
+		gi.PromptDialog(tv.ViewportSafe(), gi.DlgOpts{Title: "Invalid Filter", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+	if tf.String() != prv {
+		tv.ApplyFilters()
+	}
+	return err
+}
+
+// ToggleFilterSet toggles whether rows with given value of the field of
+// given name are shown by its Set filter, and applies the filters
+func (tv *TableView) ToggleFilterSet(field, val string) {
+	tf := tv.Filter(field)
+	if tf == nil {
+		return
+	}
+	tf.ToggleSet(val)
+	tv.ApplyFilters()
+}
+
+// ClearFilter clears the filter for the field of given name, and applies
+// the filters
+func (tv *TableView) ClearFilter(field string) {
+	if _, ok := tv.Filters[field]; !ok {
+		return
+	}
+	delete(tv.Filters, field)
+	tv.ApplyFilters()
+}
+
+// ClearFilters clears all the filters, showing all rows
+func (tv *TableView) ClearFilters() {
+	if len(tv.Filters) == 0 {
+		return
+	}
+	tv.Filters = nil
+	tv.ApplyFilters()
+}
+
+// filterInserted updates the view index for a new element inserted at given
+// slice index, shown at given view index
+func (tv *TableView) filterInserted(idx, si int) {
+	for i, ix := range tv.Idxs {
+		if ix >= si {
+			tv.Idxs[i]++
+		}
+	}
+	if idx < 0 || idx > len(tv.Idxs) {
+		idx = len(tv.Idxs)
+	}
+	tv.Idxs = append(tv.Idxs, 0)
+	copy(tv.Idxs[idx+1:], tv.Idxs[idx:])
+	tv.Idxs[idx] = si
+	tv.filtSliceLen++
+}
+
+// filterDeleted updates the view index for the element at given view index
+// having been deleted from the slice
+func (tv *TableView) filterDeleted(idx int) {
+	if idx < 0 || idx >= len(tv.Idxs) {
+		return
+	}
+	si := tv.Idxs[idx]
+	tv.Idxs = append(tv.Idxs[:idx], tv.Idxs[idx+1:]...)
+	for i, ix := range tv.Idxs {
+		if ix > si {
+			tv.Idxs[i]--
+		}
+	}
+	tv.filtSliceLen--
+}
+
+// FilterBar returns the filter bar under the header, or nil if not shown
+func (tv *TableView) FilterBar() *gi.ToolBar {
+	fb, _ := tv.SliceFrame().ChildByName("filter", 1).(*gi.ToolBar)
+	return fb
+}
+
+// ConfigFilterBar configures the filter bar, with a filter for each visible
+// field, which LayoutHeader aligns with the columns
+func (tv *TableView) ConfigFilterBar() {
+	fb := tv.FilterBar()
+	if fb == nil {
+		return
+	}
+	fb.Lay = gi.LayoutHoriz
+	fb.SetProp("overflow", gi.OverflowHidden) // no scrollbars!
+	fb.SetProp("spacing", 0)
+
+	fcfg := kit.TypeAndNameList{}
+	if tv.ShowIndex {
+		fcfg.Add(gi.KiT_Label, "filt-idx")
+	}
+	for _, fld := range tv.VisFields {
+		if TableFilterTypeFor(fld.Type) == TableFilterSet {
+			fcfg.Add(gi.KiT_MenuButton, "filt-"+fld.Name)
+		} else {
+			fcfg.Add(gi.KiT_TextField, "filt-"+fld.Name)
+		}
+	}
+	if !tv.IsInactive() {
+		fcfg.Add(gi.KiT_Label, "filt-add")
+		fcfg.Add(gi.KiT_Label, "filt-del")
+	}
+	fb.ConfigChildren(fcfg, ki.UniqueNames)
+
+	_, idxOff := tv.RowWidgetNs()
+	if tv.ShowIndex {
+		lbl := fb.Child(0).(*gi.Label)
+		lbl.Text = "Filter"
+		lbl.Tooltip = "only rows matching all the filters in this row are shown"
+	}
+	for fli, fld := range tv.VisFields {
+		fnm := fld.Name
+		switch fw := fb.Child(idxOff + fli).(type) {
+		case *gi.TextField:
+			if TableFilterTypeFor(fld.Type) == TableFilterRange {
+				fw.Tooltip = "show rows where " + fnm + " is within min:max -- either can be omitted, and a single number matches just that value"
+				fw.Placeholder = "min:max"
+			} else {
+				fw.Tooltip = "show rows where " + fnm + " contains this text, ignoring case"
+				fw.Placeholder = "contains"
+			}
+			fw.TextFieldSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				switch sig {
+				case int64(gi.TextFieldDone), int64(gi.TextFieldDeFocused), int64(gi.TextFieldCleared):
+					tvv := recv.Embed(KiT_TableView).(*TableView)
+					tff := send.(*gi.TextField)
+					tvv.SetFilterText(fnm, tff.Text())
+				}
+			})
+		case *gi.MenuButton:
+			fw.Tooltip = "show rows where " + fnm + " is one of the selected values"
+			fw.MakeMenuFunc = func(obj ki.Ki, m *gi.Menu) {
+				tv.FilterSetMenu(fnm, m)
+			}
+		}
+	}
+	tv.UpdateFilterBar()
+}
+
+// UpdateFilterBar updates the filter bar to show the current Filters
+func (tv *TableView) UpdateFilterBar() {
+	fb := tv.FilterBar()
+	if fb == nil {
+		return
+	}
+	_, idxOff := tv.RowWidgetNs()
+	for fli, fld := range tv.VisFields {
+		str := ""
+		if tf, ok := tv.Filters[fld.Name]; ok {
+			str = tf.String()
+		}
+		switch fw := fb.Child(idxOff + fli).(type) {
+		case *gi.TextField:
+			fw.SetText(str)
+		case *gi.MenuButton:
+			if str == "" {
+				str = "All"
+			}
+			fw.SetText(str)
+		}
+	}
+}
+
+// FilterSetMenu makes the menu of values of the field of given name, for
+// selecting those shown by its Set filter
+func (tv *TableView) FilterSetMenu(field string, m *gi.Menu) {
+	fld, ok := tv.StruType.FieldByName(field)
+	if !ok {
+		return
+	}
+	vals := TableFilterValues(fld.Type)
+	*m = make(gi.Menu, 0, len(vals)+2)
+	tf := tv.Filters[field]
+	m.AddAction(gi.ActOpts{Label: "All", Tooltip: "show all values"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.ClearFilter(field)
+	})
+	m.AddSeparator("all-sep")
+	for _, v := range vals {
+		ic := "unchecked-box"
+		if tf != nil && tf.InSet(v) {
+			ic = "checked-box"
+		}
+		m.AddAction(gi.ActOpts{Label: v, Icon: ic, Data: v}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			tvv.ToggleFilterSet(field, send.(*gi.Action).Data.(string))
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  TableView find
+
+// RowContains returns true if the value of any of the visible fields in the
+// row at given view index contains given text, ignoring case -- find must
+// already be lowercase
+func (tv *TableView) RowContains(idx int, find string) bool {
+	val := kit.OnePtrUnderlyingValue(tv.SliceNPVal.Index(tv.SliceIdx(idx))).Elem()
+	for _, fld := range tv.VisFields {
+		fv := val.FieldByIndex(fld.Index)
+		if strings.Contains(strings.ToLower(kit.ToString(fv.Interface())), find) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindNext finds the next row in the view, starting at given view index and
+// wrapping around, with a visible field containing given text, ignoring case
+// -- returns the view index of the row, or -1 if not found
+func (tv *TableView) FindNext(find string, idx int) int {
+	if find == "" || kit.IfaceIsNil(tv.Slice) {
+		return -1
+	}
+	find = strings.ToLower(find)
+	tv.ViewMuLock()
+	defer tv.ViewMuUnlock()
+	sz := tv.UpdtSliceSize()
+	if idx < 0 || idx >= sz {
+		idx = 0
+	}
+	for i := 0; i < sz; i++ {
+		ri := (idx + i) % sz
+		if tv.RowContains(ri, find) {
+			return ri
+		}
+	}
+	return -1
+}
+
+// FindAction sets FindText and selects the next row containing it (see
+// FindNext), starting at the current selection if incremental, which is
+// used while typing, or after it otherwise -- returns true if found
+func (tv *TableView) FindAction(find string, incremental bool) bool {
+	tv.FindText = find
+	st := tv.SelectedIdx
+	if st < 0 {
+		st = 0
+	} else if !incremental {
+		st++
+	}
+	idx := tv.FindNext(find, st)
+	if idx < 0 {
+		return false
+	}
+	tv.ScrollToIdx(idx)
+	tv.SelectIdxAction(idx, mouse.SelectOne)
+	return true
+}
+
+// ConfigFindToolbar adds the find and column controls to the toolbar --
+// returns the number of items added
+func (tv *TableView) ConfigFindToolbar(tb *gi.ToolBar) int {
+	gi.AddNewLabel(tb, "find-lbl", "Find:")
+	ftf := gi.AddNewTextField(tb, "find")
+	ftf.Tooltip = "select the next row containing this text in any column, ignoring case -- found as you type, and Enter or Next finds the next one"
+	ftf.SetText(tv.FindText)
+	ftf.TextFieldSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tff := send.(*gi.TextField)
+		switch sig {
+		case int64(gi.TextFieldInsert), int64(gi.TextFieldBackspace), int64(gi.TextFieldDelete):
+			tvv.FindAction(string(tff.EditTxt), true)
+		case int64(gi.TextFieldDone):
+			tvv.FindAction(tff.Text(), false)
+		}
+	})
+	tb.AddAction(gi.ActOpts{Label: "Next", Icon: "wedge-down", Tooltip: "select the next row containing the find text"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			tvv.FindAction(tvv.FindText, false)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Clear Filters", Icon: "close", Tooltip: "clear all the filters, showing all rows", UpdateFunc: tv.FilteredUpdate},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			tvv.ClearFilters()
+		})
+	cmb := gi.AddNewMenuButton(tb, "columns")
+	cmb.SetText("Columns")
+	cmb.Tooltip = "show or hide columns -- drag the column headers to move them, and their right edges to resize them"
+	cmb.MakeMenuFunc = func(obj ki.Ki, m *gi.Menu) {
+		tv.ColsMenu(m)
+	}
+	return 5
+}
+
+// FilteredUpdate activates the action if any filters are active
+func (tv *TableView) FilteredUpdate(act *gi.Action) {
+	act.SetActiveStateUpdt(tv.IsFiltered())
+}
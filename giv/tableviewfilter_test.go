@@ -0,0 +1,153 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goki/ki/kit"
+)
+
+type filterTest struct {
+	Name  string
+	N     int
+	On    bool
+	Merge MergeChoices
+}
+
+// filterTestView returns an unconfigured TableView on given slice pointer,
+// showing all the fields -- CacheVisFields uses the column prefs of the app
+func filterTestView(sl interface{}) *TableView {
+	tv := &TableView{}
+	tv.InitName(tv, "tv")
+	tv.Slice = sl
+	tv.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(sl))
+	styp := tv.StructType()
+	for i := 0; i < styp.NumField(); i++ {
+		tv.VisFields = append(tv.VisFields, styp.Field(i))
+	}
+	tv.SortIdx = -1
+	return tv
+}
+
+func TestTableFilterMatch(t *testing.T) {
+	tests := []struct {
+		typ   TableFilterTypes
+		txt   string
+		set   []string
+		val   interface{}
+		match bool
+		str   string
+	}{
+		{TableFilterContains, "ob", nil, "Bob", true, "ob"},
+		{TableFilterContains, "OB", nil, "bob", true, "OB"},
+		{TableFilterContains, "x", nil, "bob", false, "x"},
+		{TableFilterContains, "", nil, "bob", true, ""},
+		{TableFilterRange, "2:5", nil, 2, true, "2:5"},
+		{TableFilterRange, "2:5", nil, 5.5, false, "2:5"},
+		{TableFilterRange, "2:", nil, 100, true, "2:"},
+		{TableFilterRange, ":2", nil, -1, true, ":2"},
+		{TableFilterRange, ":2", nil, 3, false, ":2"},
+		{TableFilterRange, " 3 ", nil, 3, true, "3"},
+		{TableFilterRange, "3", nil, 4, false, "3"},
+		{TableFilterSet, "", []string{"true"}, true, true, "true"},
+		{TableFilterSet, "", []string{"true"}, false, false, "true"},
+	}
+	for _, tst := range tests {
+		tf := &TableFilter{Field: "F", Type: tst.typ, Set: tst.set}
+		if err := tf.SetText(tst.txt); err != nil {
+			t.Errorf("SetText(%q): %v", tst.txt, err)
+			continue
+		}
+		if m := tf.Match(reflect.ValueOf(tst.val)); m != tst.match {
+			t.Errorf("%v filter %q Match(%v) = %v, expected %v", tst.typ, tf.String(), tst.val, m, tst.match)
+		}
+		if str := tf.String(); str != tst.str {
+			t.Errorf("%v filter SetText(%q) String() = %q, expected %q", tst.typ, tst.txt, str, tst.str)
+		}
+	}
+	tf := &TableFilter{Field: "F", Type: TableFilterRange}
+	if err := tf.SetText("a:3"); err == nil || tf.IsActive() {
+		t.Errorf("SetText(a:3): expected error and inactive filter")
+	}
+	if err := tf.SetText("1:b"); err == nil || tf.IsActive() {
+		t.Errorf("SetText(1:b): expected error and inactive filter")
+	}
+}
+
+func TestTableFilterTypeFor(t *testing.T) {
+	tests := []struct {
+		val interface{}
+		typ TableFilterTypes
+	}{
+		{"", TableFilterContains},
+		{0, TableFilterRange},
+		{float32(0), TableFilterRange},
+		{false, TableFilterSet},
+		{MergeChoices(0), TableFilterSet},
+	}
+	for _, tst := range tests {
+		if typ := TableFilterTypeFor(reflect.TypeOf(tst.val)); typ != tst.typ {
+			t.Errorf("TableFilterTypeFor(%T) = %v, expected %v", tst.val, typ, tst.typ)
+		}
+	}
+}
+
+func TestTableViewFilterDelete(t *testing.T) {
+	sl := []filterTest{
+		{Name: "apple", N: 3},
+		{Name: "banana", N: 1},
+		{Name: "avocado", N: 2, On: true},
+		{Name: "cherry", N: 5},
+		{Name: "apricot", N: 4, On: true},
+	}
+	tv := filterTestView(&sl)
+	tv.Filter("Name").SetText("a")
+	tv.Filter("N").SetText("2:")
+	tv.FilterIdxs()
+	if !tv.IsFiltered() {
+		t.Fatalf("IsFiltered false with active filters")
+	}
+	// apple, avocado, apricot -- banana has N < 2
+	exp := []int{0, 2, 4}
+	if !reflect.DeepEqual(tv.Idxs, exp) {
+		t.Fatalf("FilterIdxs: got %v, expected %v", tv.Idxs, exp)
+	}
+	for vi, si := range exp {
+		if tv.SliceIdx(vi) != si || tv.ViewIdx(si) != vi {
+			t.Errorf("SliceIdx(%v) = %v, ViewIdx(%v) = %v, expected %v, %v", vi, tv.SliceIdx(vi), si, tv.ViewIdx(si), si, vi)
+		}
+	}
+	if vi := tv.ViewIdx(1); vi != -1 {
+		t.Errorf("ViewIdx of filtered out row = %v, expected -1", vi)
+	}
+
+	tv.SliceDeleteAt(1, false) // avocado
+	names := make([]string, len(sl))
+	for i, ft := range sl {
+		names[i] = ft.Name
+	}
+	if xnames := []string{"apple", "banana", "cherry", "apricot"}; !reflect.DeepEqual(names, xnames) {
+		t.Errorf("SliceDeleteAt(1): slice is %v, expected %v", names, xnames)
+	}
+	if exp := []int{0, 3}; !reflect.DeepEqual(tv.Idxs, exp) {
+		t.Errorf("SliceDeleteAt(1): view index is %v, expected %v", tv.Idxs, exp)
+	}
+
+	// sorting by N descending keeps showing the same rows, in sorted order
+	tv.SortIdx = 1
+	tv.SortDesc = true
+	tv.SortSlice()
+	if sl[tv.SliceIdx(0)].Name != "apricot" || sl[tv.SliceIdx(1)].Name != "apple" {
+		t.Errorf("SortSlice: view shows %v, %v, expected apricot, apple", sl[tv.SliceIdx(0)].Name, sl[tv.SliceIdx(1)].Name)
+	}
+
+	tv.Filters = nil
+	tv.FilterIdxs()
+	if tv.Idxs != nil || tv.SliceIdx(2) != 2 {
+		t.Errorf("no filters: got view index %v, expected nil", tv.Idxs)
+	}
+}
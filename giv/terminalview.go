@@ -0,0 +1,532 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+)
+
+// TerminalView is a TextView that runs a command in a pseudo-terminal,
+// e.g., a shell, a REPL, or interactive tools such as git add -p, which
+// require a full terminal and not just append-only output as in OutBuf.
+// The output of the command is processed by a VT100 / xterm compatible
+// TermScreen model, which is rendered through the TextBuf, and key
+// events are sent to the command.  Selected text can be copied with the
+// usual Copy key function (when there is a selection) or Shift+Control+C,
+// and the clipboard pasted with Shift+Control+V or Meta+V.
+type TerminalView struct {
+	TextView
+	Screen    TermScreen  `json:"-" xml:"-" desc:"terminal screen model that the output of the command is written to"`
+	Cmd       *exec.Cmd   `json:"-" xml:"-" desc:"the command running in the terminal"`
+	Pty       *os.File    `json:"-" xml:"-" view:"-" desc:"master side of the pseudo-terminal that the command is running in"`
+	BatchMSec int         `desc:"default 20: how many milliseconds to wait while batching output before updating the display"`
+	Exited    bool        `json:"-" xml:"-" inactive:"+" desc:"true if the command has exited -- set by the goroutine monitoring the command, use IsExited to access"`
+	ExitErr   error       `json:"-" xml:"-" view:"-" desc:"error returned by the command when it exited, if any"`
+	exitMu    sync.Mutex  `view:"-"`
+	updtMu    sync.Mutex  `view:"-"`
+	updtTimer *time.Timer `view:"-"`
+	lastMus   [][]byte    `view:"-"`
+}
+
+var KiT_TerminalView = kit.Types.AddType(&TerminalView{}, TextViewProps)
+
+// AddNewTerminalView adds a new TerminalView to given parent node, with given name.
+func AddNewTerminalView(parent ki.Ki, name string) *TerminalView {
+	return parent.AddNewChild(KiT_TerminalView, name).(*TerminalView)
+}
+
+// Start starts given command running in a new pseudo-terminal, creating
+// the TextBuf for display if not already set.  The TERM environment
+// variable is set to xterm-256color.
+func (tv *TerminalView) Start(cmd *exec.Cmd) error {
+	if tv.Pty != nil && !tv.IsExited() {
+		err := fmt.Errorf("giv.TerminalView: command already running in terminal: %v", tv.Nm)
+		log.Println(err)
+		return err
+	}
+	tv.SetProp("white-space", gi.WhiteSpacePre) // terminal does its own wrapping
+	tv.SetProp("line-nos", false)
+	if _, has := tv.Props["font-family"]; !has {
+		tv.SetProp("font-family", gi.Prefs.MonoFont)
+	}
+	if tv.Buf == nil {
+		tb := &TextBuf{}
+		tb.InitName(tb, "terminal-buf")
+		tv.SetBuf(tb)
+	}
+	rows, cols := tv.TermSize()
+	tv.Screen.Init(rows, cols)
+	ptm, pts, err := OpenPty()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	SetPtySize(ptm, rows, cols)
+	cmd.Stdin = pts
+	cmd.Stdout = pts
+	cmd.Stderr = pts
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, "TERM=xterm-256color")
+	ptySetCmdTerm(cmd)
+	err = cmd.Start()
+	pts.Close() // only used by the command now
+	if err != nil {
+		ptm.Close()
+		log.Println(err)
+		return err
+	}
+	tv.Cmd = cmd
+	tv.Pty = ptm
+	tv.exitMu.Lock()
+	tv.Exited = false
+	tv.ExitErr = nil
+	tv.exitMu.Unlock()
+	go tv.MonPty()
+	return nil
+}
+
+// StartCommand starts running given command with args in a new
+// pseudo-terminal -- see Start for details
+func (tv *TerminalView) StartCommand(name string, args ...string) error {
+	return tv.Start(exec.Command(name, args...))
+}
+
+// IsExited returns true if the command has exited
+func (tv *TerminalView) IsExited() bool {
+	tv.exitMu.Lock()
+	defer tv.exitMu.Unlock()
+	return tv.Exited
+}
+
+// Stop kills the command if it is still running
+func (tv *TerminalView) Stop() {
+	if tv.Cmd == nil || tv.IsExited() || tv.Cmd.Process == nil {
+		return
+	}
+	tv.Cmd.Process.Kill()
+}
+
+// MonPty monitors the output of the command in the pseudo-terminal,
+// sending it to the Screen, and updating the display -- runs in a
+// separate goroutine until the command exits
+func (tv *TerminalView) MonPty() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := tv.Pty.Read(buf)
+		if n > 0 {
+			tv.Screen.Write(buf[:n])
+			if rp := tv.Screen.TakeReply(); rp != nil {
+				tv.Pty.Write(rp)
+			}
+			tv.ScheduleUpdate()
+		}
+		if err != nil { // EIO on linux when command exits
+			break
+		}
+	}
+	err := tv.Cmd.Wait()
+	tv.exitMu.Lock()
+	tv.ExitErr = err
+	tv.Exited = true
+	tv.exitMu.Unlock()
+	tv.Pty.Close()
+	if err != nil {
+		fmt.Fprintf(&tv.Screen, "\r\n[Process exited: %v]", err)
+	} else {
+		fmt.Fprintf(&tv.Screen, "\r\n[Process exited]")
+	}
+	tv.ScheduleUpdate()
+}
+
+// SendBytes sends given bytes to the command as input, as if typed
+func (tv *TerminalView) SendBytes(b []byte) error {
+	if tv.Pty == nil || tv.IsExited() {
+		return nil
+	}
+	_, err := tv.Pty.Write(b)
+	return err
+}
+
+// SendText sends given text to the command as pasted input -- newlines
+// are sent as returns, and it is bracketed if the command has turned on
+// bracketed paste mode
+func (tv *TerminalView) SendText(txt []byte) error {
+	b := make([]byte, 0, len(txt)+12)
+	tv.Screen.Mu.Lock()
+	bp := tv.Screen.BracketPaste
+	tv.Screen.Mu.Unlock()
+	if bp {
+		b = append(b, "\x1b[200~"...)
+	}
+	for _, c := range txt {
+		if c == '\n' {
+			c = '\r'
+		}
+		b = append(b, c)
+	}
+	if bp {
+		b = append(b, "\x1b[201~"...)
+	}
+	return tv.SendBytes(b)
+}
+
+// TermSize returns the number of rows and columns of the terminal that
+// fit in the visible area of the view -- defaults to 24 x 80 before the
+// view has been laid out
+func (tv *TerminalView) TermSize() (rows, cols int) {
+	rows, cols = 24, 80
+	ply := tv.ParentLayout()
+	if ply == nil || tv.LineHeight == 0 || tv.Sty.Font.Face == nil {
+		return
+	}
+	sz := ply.VpBBox.Size()
+	if sz == image.ZP {
+		return
+	}
+	spc := 2 * tv.Sty.BoxSpace()
+	chw := tv.Sty.Font.Face.Metrics.Ch
+	rows = int((float32(sz.Y) - spc) / tv.LineHeight)
+	cols = int((float32(sz.X)-spc)/chw) - 2 // room for scrollbar
+	if rows < 2 {
+		rows = 2
+	}
+	if cols < 10 {
+		cols = 10
+	}
+	return
+}
+
+// ResizeTerm resizes the terminal to fit the view, if it has changed
+func (tv *TerminalView) ResizeTerm() {
+	if tv.Pty == nil || tv.IsExited() {
+		return
+	}
+	rows, cols := tv.TermSize()
+	tv.Screen.Mu.Lock()
+	same := rows == tv.Screen.Rows && cols == tv.Screen.Cols
+	tv.Screen.Mu.Unlock()
+	if same {
+		return
+	}
+	tv.Screen.Resize(rows, cols)
+	SetPtySize(tv.Pty, rows, cols) // command gets SIGWINCH
+	tv.ScheduleUpdate()
+}
+
+// ScheduleUpdate schedules an update of the display on the main thread
+// after BatchMSec, combining all the output received in the meantime
+func (tv *TerminalView) ScheduleUpdate() {
+	tv.updtMu.Lock()
+	defer tv.updtMu.Unlock()
+	if tv.updtTimer != nil {
+		return
+	}
+	msec := tv.BatchMSec
+	if msec == 0 {
+		msec = 20
+	}
+	tv.updtTimer = time.AfterFunc(time.Duration(msec)*time.Millisecond, func() {
+		tv.updtMu.Lock()
+		tv.updtTimer = nil
+		tv.updtMu.Unlock()
+		oswin.TheApp.GoRunOnMain(tv.UpdateView)
+	})
+}
+
+// UpdateView updates the TextBuf and display from the current Screen,
+// with the scrollback followed by the screen lines, and the cursor
+// positioned at the terminal cursor.  Only the lines that have changed
+// since the last update are laid out and rendered again.  Must be called
+// on the main thread.
+func (tv *TerminalView) UpdateView() {
+	if tv.Buf == nil || tv.This() == nil {
+		return
+	}
+	lns, mus, cln, cch := tv.Screen.TextMarkup()
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	prv := tv.lastMus
+	tv.lastMus = mus
+	onln := len(prv)
+	if !tv.This().(gi.Node2D).IsVisible() || tv.Renders == nil || tv.NLines != onln || tv.Buf.NumLines() != onln {
+		tv.Buf.SetTextLinesMarkup(lns, mus)
+		tv.CursorPos = lex.Pos{Ln: cln, Ch: cch}
+		if !tv.This().(gi.Node2D).IsVisible() {
+			tv.lastMus = nil // full update when visible again
+			return
+		}
+		tv.Refresh()
+		tv.ScrollCursorInView()
+		return
+	}
+	st, ed := TermChangedLines(prv, mus)
+	nln := len(mus)
+	if st <= ed || nln != onln {
+		tv.Buf.SetLinesMarkupFrom(st, lns, mus)
+		if nln != onln {
+			if nln < onln {
+				tv.Renders = tv.Renders[:nln]
+				tv.Offs = tv.Offs[:nln]
+			} else {
+				tv.Renders = append(tv.Renders, make([]gi.TextRender, nln-onln)...)
+				tv.Offs = append(tv.Offs, make([]float32, nln-onln)...)
+			}
+			tv.NLines = nln
+			tv.updateHidden()
+		}
+		rerend := tv.LayoutLines(st, ed, nln != onln)
+		if rerend || nln != onln {
+			tv.RenderAllLines()
+		} else {
+			tv.RenderLines(st, ed)
+		}
+	}
+	tv.CursorPos = lex.Pos{Ln: cln, Ch: cch}
+	tv.ScrollCursorInView()
+}
+
+// TermChangedLines returns the range of lines (inclusive) in given new
+// markup lines that differ from the previous ones -- if the number of
+// lines differs, the range extends to the last line.  ed < st if no lines
+// have changed or been added.
+func TermChangedLines(prv, mus [][]byte) (st, ed int) {
+	n := ints.MinInt(len(prv), len(mus))
+	for st < n && bytes.Equal(prv[st], mus[st]) {
+		st++
+	}
+	ed = len(mus) - 1
+	if len(prv) == len(mus) {
+		for ed >= st && bytes.Equal(prv[ed], mus[ed]) {
+			ed--
+		}
+	}
+	return
+}
+
+// Paste pastes the clipboard text into the terminal
+func (tv *TerminalView) Paste() {
+	win := tv.ParentWindow()
+	if win == nil {
+		return
+	}
+	data := oswin.TheApp.ClipBoard(win.OSWin).Read([]string{filecat.TextPlain})
+	if data != nil {
+		tv.SendText(data.TypeData(filecat.TextPlain))
+	}
+}
+
+// Copy copies the selected text to the clipboard, and resets the selection
+func (tv *TerminalView) Copy() {
+	sel := tv.Selection()
+	if sel == nil {
+		return
+	}
+	win := tv.ParentWindow()
+	if win == nil {
+		return
+	}
+	oswin.TheApp.ClipBoard(win.OSWin).Write(mimedata.NewTextBytes(sel.ToBytes()))
+	tv.SelectReset()
+	tv.RenderAllLines()
+}
+
+// TermFuncKeys are the escape sequences for the function keys F1..F12
+var TermFuncKeys = [12]string{
+	"\x1bOP", "\x1bOQ", "\x1bOR", "\x1bOS", "\x1b[15~", "\x1b[17~",
+	"\x1b[18~", "\x1b[19~", "\x1b[20~", "\x1b[21~", "\x1b[23~", "\x1b[24~",
+}
+
+// KeyBytes returns the bytes to send to the command for given key event,
+// or nil if the key is not sent (e.g., Meta key combinations, which are
+// used for shortcuts)
+func (tv *TerminalView) KeyBytes(kt *key.ChordEvent) []byte {
+	if kt.HasAnyModifier(key.Meta) {
+		return nil
+	}
+	shift := kt.HasAnyModifier(key.Shift)
+	ctrl := kt.HasAnyModifier(key.Control)
+	alt := kt.HasAnyModifier(key.Alt)
+	mod := 1 // xterm modifier parameter
+	if shift {
+		mod++
+	}
+	if alt {
+		mod += 2
+	}
+	if ctrl {
+		mod += 4
+	}
+	tv.Screen.Mu.Lock()
+	appCur := tv.Screen.AppCursor
+	tv.Screen.Mu.Unlock()
+	cursor := func(c byte) []byte {
+		switch {
+		case mod > 1:
+			return []byte(fmt.Sprintf("\x1b[1;%d%c", mod, c))
+		case appCur:
+			return []byte{0x1b, 'O', c}
+		}
+		return []byte{0x1b, '[', c}
+	}
+	tilde := func(n int) []byte {
+		if mod > 1 {
+			return []byte(fmt.Sprintf("\x1b[%d;%d~", n, mod))
+		}
+		return []byte(fmt.Sprintf("\x1b[%d~", n))
+	}
+	switch kt.Code {
+	case key.CodeReturnEnter, key.CodeKeypadEnter:
+		return []byte{'\r'}
+	case key.CodeEscape:
+		return []byte{0x1b}
+	case key.CodeDeleteBackspace:
+		if alt {
+			return []byte{0x1b, 0x7f}
+		}
+		return []byte{0x7f}
+	case key.CodeTab:
+		if shift {
+			return []byte("\x1b[Z")
+		}
+		return []byte{'\t'}
+	case key.CodeUpArrow:
+		return cursor('A')
+	case key.CodeDownArrow:
+		return cursor('B')
+	case key.CodeRightArrow:
+		return cursor('C')
+	case key.CodeLeftArrow:
+		return cursor('D')
+	case key.CodeHome:
+		return cursor('H')
+	case key.CodeEnd:
+		return cursor('F')
+	case key.CodeInsert:
+		return tilde(2)
+	case key.CodeDeleteForward:
+		return tilde(3)
+	case key.CodePageUp:
+		return tilde(5)
+	case key.CodePageDown:
+		return tilde(6)
+	}
+	if kt.Code >= key.CodeF1 && kt.Code <= key.CodeF12 {
+		return []byte(TermFuncKeys[kt.Code-key.CodeF1])
+	}
+	r := kt.Rune
+	if kt.Code == key.CodeSpacebar {
+		r = ' '
+	}
+	if r < 0 || !unicode.IsPrint(r) {
+		return nil
+	}
+	var b []byte
+	if alt {
+		b = append(b, 0x1b)
+	}
+	if ctrl {
+		ur := unicode.ToUpper(r)
+		switch {
+		case ur >= '@' && ur <= '_':
+			return append(b, byte(ur-'@'))
+		case r == ' ' || r == '2':
+			return append(b, 0)
+		case r == '/':
+			return append(b, 0x1f)
+		case r == '?':
+			return append(b, 0x7f)
+		}
+		return nil
+	}
+	var rb [utf8.UTFMax]byte
+	sz := utf8.EncodeRune(rb[:], r)
+	return append(b, rb[:sz]...)
+}
+
+// KeyInput handles keyboard input, sending keys to the command
+func (tv *TerminalView) KeyInput(kt *key.ChordEvent) {
+	kf := gi.KeyFun(kt.Chord())
+	chord := kt.Chord()
+	switch {
+	case chord == "Shift+Control+C" || (kf == gi.KeyFunCopy && tv.HasSelection()):
+		kt.SetProcessed()
+		tv.Copy()
+		return
+	case chord == "Shift+Control+V" || (kf == gi.KeyFunPaste && kt.HasAnyModifier(key.Meta)):
+		kt.SetProcessed()
+		tv.Paste()
+		return
+	}
+	if tv.Pty == nil || tv.IsExited() {
+		return
+	}
+	b := tv.KeyBytes(kt)
+	if b == nil {
+		return
+	}
+	kt.SetProcessed()
+	if tv.HasSelection() {
+		tv.SelectReset()
+	}
+	tv.SendBytes(b)
+}
+
+// TextViewEvents sets connections between mouse and key events and actions
+func (tv *TerminalView) TextViewEvents() {
+	tv.HoverTooltipEvent()
+	tv.MouseMoveEvent()
+	tv.MouseDragEvent()
+	tv.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		txf := recv.Embed(KiT_TextView).(*TextView)
+		me := d.(*mouse.Event)
+		txf.MouseEvent(me)
+	})
+	tv.MouseFocusEvent()
+	tv.ConnectEvent(oswin.KeyChordEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		txf := recv.Embed(KiT_TerminalView).(*TerminalView)
+		kt := d.(*key.ChordEvent)
+		txf.KeyInput(kt) // gets our new one
+	})
+}
+
+// ConnectEvents2D indirectly sets connections between mouse and key events and actions
+func (tv *TerminalView) ConnectEvents2D() {
+	tv.TextViewEvents()
+}
+
+// Render2D renders the view, and resizes the terminal if the view size
+// has changed
+func (tv *TerminalView) Render2D() {
+	tv.TextView.Render2D()
+	tv.ResizeTerm()
+}
+
+// Destroy kills the command and closes the terminal
+func (tv *TerminalView) Destroy() {
+	tv.Stop()
+	tv.TextView.Destroy()
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"strings"
+	"testing"
+)
+
+func termTestLines(s string) [][]byte {
+	var lns [][]byte
+	for _, l := range strings.Split(s, "|") {
+		lns = append(lns, []byte(l))
+	}
+	return lns
+}
+
+func TestTermChangedLines(t *testing.T) {
+	tests := []struct {
+		prv, mus string
+		st, ed   int
+	}{
+		{"a|b|c", "a|b|c", 3, 2},
+		{"a|b|c", "a|x|c", 1, 1},
+		{"a|b|c|d", "x|b|c|y", 0, 3},
+		{"a|b|c", "a|b|c|d", 3, 3},
+		{"a|b|c", "a|c|d|e", 1, 3},
+		{"a|b|c", "a|b", 2, 1},
+	}
+	for _, tst := range tests {
+		st, ed := TermChangedLines(termTestLines(tst.prv), termTestLines(tst.mus))
+		if st != tst.st || ed != tst.ed {
+			t.Errorf("TermChangedLines(%q, %q) = %v, %v, expected %v, %v", tst.prv, tst.mus, st, ed, tst.st, tst.ed)
+		}
+	}
+}
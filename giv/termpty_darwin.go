@@ -0,0 +1,53 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build darwin
+
+package giv
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ioctl requests for pseudo-terminals, from sys/ttycom.h
+const (
+	ptyTIOCPTYGRANT = 0x20007454
+	ptyTIOCPTYUNLK  = 0x20007452
+	ptyTIOCPTYGNAME = 0x40807453
+)
+
+// OpenPty opens a new pseudo-terminal, returning the master (controlling)
+// side and the slave (terminal) side, which is used for the stdin, stdout
+// and stderr of the program running in the terminal
+func OpenPty() (ptm, pts *os.File, err error) {
+	ptm, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = ptyIoctl(ptm, ptyTIOCPTYGRANT, 0); err != nil {
+		ptm.Close()
+		return nil, nil, err
+	}
+	if err = ptyIoctl(ptm, ptyTIOCPTYUNLK, 0); err != nil {
+		ptm.Close()
+		return nil, nil, err
+	}
+	nm := make([]byte, 128)
+	if err = ptyIoctl(ptm, ptyTIOCPTYGNAME, uintptr(unsafe.Pointer(&nm[0]))); err != nil {
+		ptm.Close()
+		return nil, nil, err
+	}
+	if i := bytes.IndexByte(nm, 0); i >= 0 {
+		nm = nm[:i]
+	}
+	pts, err = os.OpenFile(string(nm), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		ptm.Close()
+		return nil, nil, err
+	}
+	return ptm, pts, nil
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux,!darwin
+
+package giv
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// ErrPtyNotSupported is returned by OpenPty on platforms without
+// pseudo-terminal support
+var ErrPtyNotSupported = errors.New("pseudo-terminals are not supported on this platform")
+
+// OpenPty opens a new pseudo-terminal -- not supported on this platform
+func OpenPty() (ptm, pts *os.File, err error) {
+	return nil, nil, ErrPtyNotSupported
+}
+
+// SetPtySize sets the size of the terminal -- not supported on this platform
+func SetPtySize(f *os.File, rows, cols int) error {
+	return ErrPtyNotSupported
+}
+
+// ptySetCmdTerm does nothing on this platform
+func ptySetCmdTerm(cmd *exec.Cmd) {
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux darwin
+
+package giv
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+// ptyIoctl calls ioctl on given file
+func ptyIoctl(f *os.File, req, arg uintptr) error {
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, arg)
+	if e != 0 {
+		return e
+	}
+	return nil
+}
+
+// SetPtySize sets the size of the terminal for given pseudo-terminal file
+func SetPtySize(f *os.File, rows, cols int) error {
+	ws := struct {
+		Row, Col, X, Y uint16
+	}{uint16(rows), uint16(cols), 0, 0}
+	return ptyIoctl(f, syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws)))
+}
+
+// ptySetCmdTerm sets the command to run as a session leader with the
+// pseudo-terminal (its stdin) as its controlling terminal
+func ptySetCmdTerm(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+}
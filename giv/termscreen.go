@@ -0,0 +1,781 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/goki/ki/ints"
+)
+
+// TermCell is one character cell on a TermScreen
+type TermCell struct {
+	Ch rune      `desc:"character in the cell -- 0 if never written (renders as a space)"`
+	St ANSIState `desc:"formatting of the cell"`
+}
+
+// TermScreenMaxScrollback is the default maximum number of lines that
+// scroll off the top of the main TermScreen that are retained
+var TermScreenMaxScrollback = 1000
+
+// TermScreen is a VT100 / xterm compatible terminal screen model: the output
+// of a program running in a terminal is written to it (it is an io.Writer),
+// and it maintains the grid of character cells, with cursor addressing,
+// scroll regions, the alternate screen, and colors and styles via SGR codes
+// (including 256 and 24 bit colors).  Lines scrolled off the top of the main
+// screen are kept in the Scrollback.  Responses to queries from the program
+// (e.g., cursor position reports) are accumulated in Reply, and must be
+// sent back to the program by the owner.
+type TermScreen struct {
+	Rows          int          `desc:"number of rows on the screen"`
+	Cols          int          `desc:"number of columns on the screen"`
+	Lines         [][]TermCell `desc:"current screen lines -- main or alternate screen"`
+	Scrollback    [][]TermCell `desc:"lines that have scrolled off the top of the main screen"`
+	MaxScrollback int          `desc:"maximum number of Scrollback lines -- 0 = TermScreenMaxScrollback"`
+	CurRow        int          `desc:"cursor row"`
+	CurCol        int          `desc:"cursor column"`
+	St            ANSIState    `desc:"current formatting for new text"`
+	ScrollTop     int          `desc:"top row of the scrolling region"`
+	ScrollBot     int          `desc:"bottom row of the scrolling region (inclusive)"`
+	AltScreen     bool         `desc:"alternate screen is active (full-screen programs)"`
+	AutoWrap      bool         `desc:"wrap to next line when writing past the last column"`
+	WrapPending   bool         `desc:"cursor is past the last column -- next char wraps"`
+	CursorVisible bool         `desc:"cursor is visible"`
+	AppCursor     bool         `desc:"application cursor keys mode -- arrow keys send ESC O sequences"`
+	InsertMode    bool         `desc:"insert mode: new chars shift existing ones to the right"`
+	OriginMode    bool         `desc:"origin mode: cursor addressing is relative to the scrolling region"`
+	BracketPaste  bool         `desc:"bracketed paste mode: pasted text is enclosed in ESC [200~ and ESC [201~"`
+	Title         string       `desc:"window title set by the program"`
+	Reply         []byte       `desc:"responses to queries, to be sent back to the program"`
+	Mu            sync.Mutex   `desc:"mutex protecting updates"`
+	mainLines     [][]TermCell
+	savedRow      int
+	savedCol      int
+	savedSt       ANSIState
+	pstate        termParseState
+	pbuf          []byte
+	ubuf          []byte
+}
+
+// termParseState is the state of the escape sequence parser
+type termParseState int
+
+const (
+	termGround termParseState = iota
+	termEsc
+	termCSI
+	termOSC
+	termOSCEsc
+	termCharset
+)
+
+// termMaxSeqLen is the maximum length of a CSI or OSC sequence -- longer
+// ones are truncated
+const termMaxSeqLen = 4096
+
+// NewTermScreen returns a new TermScreen with given size
+func NewTermScreen(rows, cols int) *TermScreen {
+	ts := &TermScreen{}
+	ts.Init(rows, cols)
+	return ts
+}
+
+// Init initializes the screen to given size and resets everything
+func (ts *TermScreen) Init(rows, cols int) {
+	if rows < 1 {
+		rows = 1
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	ts.Rows = rows
+	ts.Cols = cols
+	ts.Lines = ts.newLines(rows)
+	ts.Scrollback = nil
+	ts.mainLines = nil
+	ts.AltScreen = false
+	ts.Reset()
+}
+
+// Reset resets modes, formatting and cursor, and clears the screen
+// (but not the scrollback)
+func (ts *TermScreen) Reset() {
+	if ts.AltScreen {
+		ts.Lines = ts.mainLines
+		ts.mainLines = nil
+		ts.AltScreen = false
+	}
+	ts.St.Reset()
+	ts.CurRow, ts.CurCol = 0, 0
+	ts.ScrollTop, ts.ScrollBot = 0, ts.Rows-1
+	ts.AutoWrap = true
+	ts.WrapPending = false
+	ts.CursorVisible = true
+	ts.AppCursor = false
+	ts.InsertMode = false
+	ts.OriginMode = false
+	ts.BracketPaste = false
+	ts.savedRow, ts.savedCol = 0, 0
+	ts.savedSt.Reset()
+	ts.pstate = termGround
+	ts.ubuf = ts.ubuf[:0]
+	ts.eraseLines(0, ts.Rows)
+}
+
+// newLines returns n new blank lines
+func (ts *TermScreen) newLines(n int) [][]TermCell {
+	lns := make([][]TermCell, n)
+	for i := range lns {
+		lns[i] = make([]TermCell, ts.Cols)
+	}
+	return lns
+}
+
+// blank returns a blank cell using the current background color
+func (ts *TermScreen) blank() TermCell {
+	return TermCell{St: ANSIState{Bg: ts.St.Bg}}
+}
+
+// eraseCells erases cells in given row from st to ed (exclusive)
+func (ts *TermScreen) eraseCells(row, st, ed int) {
+	if row < 0 || row >= ts.Rows {
+		return
+	}
+	st = termClamp(st, 0, ts.Cols)
+	ed = termClamp(ed, 0, ts.Cols)
+	bl := ts.blank()
+	ln := ts.Lines[row]
+	for i := st; i < ed; i++ {
+		ln[i] = bl
+	}
+}
+
+// eraseLines erases lines from st to ed (exclusive)
+func (ts *TermScreen) eraseLines(st, ed int) {
+	for r := st; r < ed; r++ {
+		ts.eraseCells(r, 0, ts.Cols)
+	}
+}
+
+// Resize resizes the screen to given number of rows and columns --
+// lines are removed from the top (into the scrollback) when shrinking,
+// as needed to keep the cursor on the screen
+func (ts *TermScreen) Resize(rows, cols int) {
+	ts.Mu.Lock()
+	defer ts.Mu.Unlock()
+	if rows < 1 {
+		rows = 1
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	if rows == ts.Rows && cols == ts.Cols {
+		return
+	}
+	if nrm := ts.CurRow + 1 - rows; nrm > 0 {
+		if !ts.AltScreen {
+			ts.addScrollback(ts.Lines[:nrm])
+		}
+		ts.Lines = ts.Lines[nrm:]
+		ts.CurRow -= nrm
+		ts.savedRow -= nrm
+	}
+	ts.Lines = termResizeLines(ts.Lines, rows, cols)
+	if ts.AltScreen {
+		ts.mainLines = termResizeLines(ts.mainLines, rows, cols)
+	}
+	ts.Rows = rows
+	ts.Cols = cols
+	ts.ScrollTop, ts.ScrollBot = 0, rows-1
+	ts.CurRow = termClamp(ts.CurRow, 0, rows-1)
+	ts.CurCol = termClamp(ts.CurCol, 0, cols-1)
+	ts.savedRow = termClamp(ts.savedRow, 0, rows-1)
+	ts.savedCol = termClamp(ts.savedCol, 0, cols-1)
+	ts.WrapPending = false
+}
+
+// termResizeLines returns new lines of given size with the contents of
+// given lines, truncated or extended with blank cells
+func termResizeLines(lns [][]TermCell, rows, cols int) [][]TermCell {
+	nl := make([][]TermCell, rows)
+	for i := range nl {
+		ln := make([]TermCell, cols)
+		if i < len(lns) {
+			copy(ln, lns[i])
+		}
+		nl[i] = ln
+	}
+	return nl
+}
+
+// addScrollback adds given lines to the scrollback, removing old lines
+// beyond MaxScrollback
+func (ts *TermScreen) addScrollback(lns [][]TermCell) {
+	mx := ts.MaxScrollback
+	if mx == 0 {
+		mx = TermScreenMaxScrollback
+	}
+	for _, ln := range lns {
+		ts.Scrollback = append(ts.Scrollback, ln)
+	}
+	if ex := len(ts.Scrollback) - mx; ex > 0 {
+		ts.Scrollback = append(ts.Scrollback[:0:0], ts.Scrollback[ex:]...)
+	}
+}
+
+// SetAltScreen switches to or from the alternate screen, which is used by
+// full-screen programs, and does not have any scrollback
+func (ts *TermScreen) SetAltScreen(alt bool) {
+	if alt == ts.AltScreen {
+		return
+	}
+	if alt {
+		ts.mainLines = ts.Lines
+		ts.Lines = ts.newLines(ts.Rows)
+	} else {
+		ts.Lines = ts.mainLines
+		ts.mainLines = nil
+	}
+	ts.AltScreen = alt
+	ts.WrapPending = false
+}
+
+// Write writes output from the program to the screen, processing all
+// control codes and escape sequences -- implements io.Writer
+func (ts *TermScreen) Write(b []byte) (int, error) {
+	ts.Mu.Lock()
+	defer ts.Mu.Unlock()
+	for _, c := range b {
+		ts.processByte(c)
+	}
+	return len(b), nil
+}
+
+// TakeReply returns and clears any pending responses to be sent back to
+// the program
+func (ts *TermScreen) TakeReply() []byte {
+	ts.Mu.Lock()
+	defer ts.Mu.Unlock()
+	if len(ts.Reply) == 0 {
+		return nil
+	}
+	rp := ts.Reply
+	ts.Reply = nil
+	return rp
+}
+
+// processByte processes the next byte of output
+func (ts *TermScreen) processByte(c byte) {
+	switch ts.pstate {
+	case termGround:
+		if len(ts.ubuf) > 0 || c >= 0x80 {
+			ts.ubuf = append(ts.ubuf, c)
+			if utf8.FullRune(ts.ubuf) || len(ts.ubuf) >= utf8.UTFMax {
+				r, _ := utf8.DecodeRune(ts.ubuf)
+				ts.ubuf = ts.ubuf[:0]
+				ts.put(r)
+			}
+			return
+		}
+		if c < 0x20 || c == 0x7f {
+			ts.control(c)
+			return
+		}
+		ts.put(rune(c))
+	case termEsc:
+		ts.pstate = termGround
+		ts.escape(c)
+	case termCSI:
+		switch {
+		case c == 0x1b:
+			ts.pstate = termEsc
+		case c < 0x20:
+			ts.control(c)
+		case c >= 0x40 && c <= 0x7e:
+			ts.pstate = termGround
+			ts.csi(c)
+		default:
+			if len(ts.pbuf) < termMaxSeqLen {
+				ts.pbuf = append(ts.pbuf, c)
+			}
+		}
+	case termOSC:
+		switch c {
+		case 0x07:
+			ts.pstate = termGround
+			ts.osc()
+		case 0x1b:
+			ts.pstate = termOSCEsc
+		default:
+			if len(ts.pbuf) < termMaxSeqLen {
+				ts.pbuf = append(ts.pbuf, c)
+			}
+		}
+	case termOSCEsc: // ESC \ is the string terminator
+		ts.pstate = termGround
+		ts.osc()
+	case termCharset: // character set designation -- ignored
+		ts.pstate = termGround
+	}
+}
+
+// control processes a C0 control code
+func (ts *TermScreen) control(c byte) {
+	switch c {
+	case 0x1b:
+		ts.pstate = termEsc
+	case '\b':
+		if ts.CurCol > 0 {
+			ts.CurCol--
+		}
+		ts.WrapPending = false
+	case '\t':
+		ts.CurCol = ints.MinInt((ts.CurCol/8+1)*8, ts.Cols-1)
+		ts.WrapPending = false
+	case '\n', '\v', '\f':
+		ts.index()
+	case '\r':
+		ts.CurCol = 0
+		ts.WrapPending = false
+	}
+}
+
+// escape processes the char after an ESC
+func (ts *TermScreen) escape(c byte) {
+	switch c {
+	case '[':
+		ts.pstate = termCSI
+		ts.pbuf = ts.pbuf[:0]
+	case ']':
+		ts.pstate = termOSC
+		ts.pbuf = ts.pbuf[:0]
+	case '(', ')', '*', '+':
+		ts.pstate = termCharset
+	case '7':
+		ts.saveCursor()
+	case '8':
+		ts.restoreCursor()
+	case 'D':
+		ts.index()
+	case 'E':
+		ts.CurCol = 0
+		ts.index()
+	case 'M':
+		ts.reverseIndex()
+	case 'c':
+		ts.Reset()
+	}
+}
+
+// put puts the rune at the cursor and advances the cursor
+func (ts *TermScreen) put(r rune) {
+	if ts.WrapPending {
+		ts.WrapPending = false
+		if ts.AutoWrap {
+			ts.CurCol = 0
+			ts.index()
+		}
+	}
+	ln := ts.Lines[ts.CurRow]
+	if ts.InsertMode {
+		copy(ln[ts.CurCol+1:], ln[ts.CurCol:])
+	}
+	ln[ts.CurCol] = TermCell{Ch: r, St: ts.St}
+	if ts.CurCol >= ts.Cols-1 {
+		ts.WrapPending = true
+	} else {
+		ts.CurCol++
+	}
+}
+
+// index moves the cursor down one line, scrolling if at the bottom of the
+// scrolling region
+func (ts *TermScreen) index() {
+	ts.WrapPending = false
+	switch {
+	case ts.CurRow == ts.ScrollBot:
+		ts.scrollUp(1)
+	case ts.CurRow < ts.Rows-1:
+		ts.CurRow++
+	}
+}
+
+// reverseIndex moves the cursor up one line, scrolling if at the top of
+// the scrolling region
+func (ts *TermScreen) reverseIndex() {
+	ts.WrapPending = false
+	switch {
+	case ts.CurRow == ts.ScrollTop:
+		ts.scrollDown(1)
+	case ts.CurRow > 0:
+		ts.CurRow--
+	}
+}
+
+// scrollUp scrolls the lines in the scrolling region up by n lines --
+// lines scrolled off the top of the full main screen go to the scrollback
+func (ts *TermScreen) scrollUp(n int) {
+	ts.scrollRegionUp(ts.ScrollTop, ts.ScrollBot, n, ts.ScrollTop == 0 && !ts.AltScreen)
+}
+
+// scrollRegionUp scrolls lines from top to bot (inclusive) up by n lines,
+// adding the lines scrolled off to the scrollback if sb is true
+func (ts *TermScreen) scrollRegionUp(top, bot, n int, sb bool) {
+	sz := bot - top + 1
+	n = termClamp(n, 0, sz)
+	if n == 0 {
+		return
+	}
+	if sb {
+		ts.addScrollback(ts.Lines[top : top+n])
+	}
+	copy(ts.Lines[top:], ts.Lines[top+n:bot+1])
+	for r := bot - n + 1; r <= bot; r++ {
+		ts.Lines[r] = make([]TermCell, ts.Cols)
+		ts.eraseCells(r, 0, ts.Cols)
+	}
+}
+
+// scrollDown scrolls the lines in the scrolling region down by n lines
+func (ts *TermScreen) scrollDown(n int) {
+	ts.scrollRegionDown(ts.ScrollTop, ts.ScrollBot, n)
+}
+
+// scrollRegionDown scrolls lines from top to bot (inclusive) down by n lines
+func (ts *TermScreen) scrollRegionDown(top, bot, n int) {
+	sz := bot - top + 1
+	n = termClamp(n, 0, sz)
+	if n == 0 {
+		return
+	}
+	copy(ts.Lines[top+n:bot+1], ts.Lines[top:bot+1-n])
+	for r := top; r < top+n; r++ {
+		ts.Lines[r] = make([]TermCell, ts.Cols)
+		ts.eraseCells(r, 0, ts.Cols)
+	}
+}
+
+// saveCursor saves the cursor position and formatting
+func (ts *TermScreen) saveCursor() {
+	ts.savedRow, ts.savedCol, ts.savedSt = ts.CurRow, ts.CurCol, ts.St
+}
+
+// restoreCursor restores the saved cursor position and formatting
+func (ts *TermScreen) restoreCursor() {
+	ts.CurRow = termClamp(ts.savedRow, 0, ts.Rows-1)
+	ts.CurCol = termClamp(ts.savedCol, 0, ts.Cols-1)
+	ts.St = ts.savedSt
+	ts.WrapPending = false
+}
+
+// moveTo moves the cursor to given row, col, clamped to the screen
+func (ts *TermScreen) moveTo(row, col int) {
+	ts.CurRow = termClamp(row, 0, ts.Rows-1)
+	ts.CurCol = termClamp(col, 0, ts.Cols-1)
+	ts.WrapPending = false
+}
+
+// termClamp clamps v to min..max
+func termClamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// csi processes a CSI sequence with given final byte, and the params in pbuf
+func (ts *TermScreen) csi(final byte) {
+	prm := ts.pbuf
+	var prefix byte
+	if len(prm) > 0 && (prm[0] == '?' || prm[0] == '>' || prm[0] == '=' || prm[0] == '<') {
+		prefix = prm[0]
+		prm = prm[1:]
+	}
+	if i := bytes.IndexFunc(prm, func(r rune) bool { return r < '0' || r > ';' }); i >= 0 {
+		if prm[i] >= 0x20 && prm[i] <= 0x2f { // intermediate bytes -- not supported
+			return
+		}
+		prm = prm[:i]
+	}
+	params := ansiParams(bytes.Replace(prm, []byte(":"), []byte(";"), -1))
+	arg := func(i, def int) int {
+		if i >= len(params) || params[i] == 0 {
+			return def
+		}
+		return params[i]
+	}
+	n := arg(0, 1)
+	switch final {
+	case '@': // insert chars
+		ln := ts.Lines[ts.CurRow]
+		n = termClamp(n, 0, ts.Cols-ts.CurCol)
+		copy(ln[ts.CurCol+n:], ln[ts.CurCol:])
+		ts.eraseCells(ts.CurRow, ts.CurCol, ts.CurCol+n)
+		ts.WrapPending = false
+	case 'A':
+		top := 0
+		if ts.CurRow >= ts.ScrollTop {
+			top = ts.ScrollTop
+		}
+		ts.moveTo(ints.MaxInt(ts.CurRow-n, top), ts.CurCol)
+	case 'B', 'e':
+		bot := ts.Rows - 1
+		if ts.CurRow <= ts.ScrollBot {
+			bot = ts.ScrollBot
+		}
+		ts.moveTo(ints.MinInt(ts.CurRow+n, bot), ts.CurCol)
+	case 'C', 'a':
+		ts.moveTo(ts.CurRow, ts.CurCol+n)
+	case 'D':
+		ts.moveTo(ts.CurRow, ts.CurCol-n)
+	case 'E':
+		ts.moveTo(ts.CurRow+n, 0)
+	case 'F':
+		ts.moveTo(ts.CurRow-n, 0)
+	case 'G', '`':
+		ts.moveTo(ts.CurRow, n-1)
+	case 'H', 'f':
+		row := arg(0, 1) - 1
+		if ts.OriginMode {
+			row = termClamp(row+ts.ScrollTop, ts.ScrollTop, ts.ScrollBot)
+		}
+		ts.moveTo(row, arg(1, 1)-1)
+	case 'd':
+		row := n - 1
+		if ts.OriginMode {
+			row = termClamp(row+ts.ScrollTop, ts.ScrollTop, ts.ScrollBot)
+		}
+		ts.moveTo(row, ts.CurCol)
+	case 'J':
+		switch arg(0, 0) {
+		case 0:
+			ts.eraseCells(ts.CurRow, ts.CurCol, ts.Cols)
+			ts.eraseLines(ts.CurRow+1, ts.Rows)
+		case 1:
+			ts.eraseLines(0, ts.CurRow)
+			ts.eraseCells(ts.CurRow, 0, ts.CurCol+1)
+		case 2:
+			ts.eraseLines(0, ts.Rows)
+		case 3:
+			ts.Scrollback = nil
+		}
+	case 'K':
+		switch arg(0, 0) {
+		case 0:
+			ts.eraseCells(ts.CurRow, ts.CurCol, ts.Cols)
+		case 1:
+			ts.eraseCells(ts.CurRow, 0, ts.CurCol+1)
+		case 2:
+			ts.eraseCells(ts.CurRow, 0, ts.Cols)
+		}
+	case 'L': // insert lines
+		if ts.CurRow >= ts.ScrollTop && ts.CurRow <= ts.ScrollBot {
+			ts.scrollRegionDown(ts.CurRow, ts.ScrollBot, n)
+			ts.CurCol = 0
+			ts.WrapPending = false
+		}
+	case 'M': // delete lines
+		if ts.CurRow >= ts.ScrollTop && ts.CurRow <= ts.ScrollBot {
+			ts.scrollRegionUp(ts.CurRow, ts.ScrollBot, n, false)
+			ts.CurCol = 0
+			ts.WrapPending = false
+		}
+	case 'P': // delete chars
+		ln := ts.Lines[ts.CurRow]
+		n = termClamp(n, 0, ts.Cols-ts.CurCol)
+		copy(ln[ts.CurCol:], ln[ts.CurCol+n:])
+		ts.eraseCells(ts.CurRow, ts.Cols-n, ts.Cols)
+		ts.WrapPending = false
+	case 'X': // erase chars
+		ts.eraseCells(ts.CurRow, ts.CurCol, ts.CurCol+n)
+		ts.WrapPending = false
+	case 'S':
+		if prefix == 0 {
+			ts.scrollUp(n)
+		}
+	case 'T':
+		if prefix == 0 {
+			ts.scrollDown(n)
+		}
+	case 'm':
+		if prefix == 0 {
+			ts.St.SetSGR(params)
+		}
+	case 'r':
+		if prefix == 0 {
+			top := arg(0, 1) - 1
+			bot := arg(1, ts.Rows) - 1
+			if top >= 0 && top < bot && bot < ts.Rows {
+				ts.ScrollTop, ts.ScrollBot = top, bot
+				if ts.OriginMode {
+					ts.moveTo(top, 0)
+				} else {
+					ts.moveTo(0, 0)
+				}
+			}
+		}
+	case 's':
+		if prefix == 0 {
+			ts.saveCursor()
+		}
+	case 'u':
+		if prefix == 0 {
+			ts.restoreCursor()
+		}
+	case 'h', 'l':
+		on := final == 'h'
+		for _, p := range params {
+			if prefix == '?' {
+				ts.setPrivateMode(p, on)
+			} else if p == 4 {
+				ts.InsertMode = on
+			}
+		}
+	case 'n':
+		if prefix != 0 {
+			return
+		}
+		switch arg(0, 0) {
+		case 5:
+			ts.Reply = append(ts.Reply, "\x1b[0n"...)
+		case 6:
+			row := ts.CurRow
+			if ts.OriginMode {
+				row -= ts.ScrollTop
+			}
+			ts.Reply = append(ts.Reply, fmt.Sprintf("\x1b[%d;%dR", row+1, ts.CurCol+1)...)
+		}
+	case 'c':
+		switch prefix {
+		case 0:
+			ts.Reply = append(ts.Reply, "\x1b[?1;2c"...)
+		case '>':
+			ts.Reply = append(ts.Reply, "\x1b[>0;0;0c"...)
+		}
+	}
+}
+
+// setPrivateMode sets DEC private mode (CSI ? p h / l)
+func (ts *TermScreen) setPrivateMode(p int, on bool) {
+	switch p {
+	case 1:
+		ts.AppCursor = on
+	case 6:
+		ts.OriginMode = on
+		ts.moveTo(ts.ScrollTop, 0)
+	case 7:
+		ts.AutoWrap = on
+	case 25:
+		ts.CursorVisible = on
+	case 47, 1047:
+		if !on && ts.AltScreen {
+			ts.eraseLines(0, ts.Rows)
+		}
+		ts.SetAltScreen(on)
+	case 1048:
+		if on {
+			ts.saveCursor()
+		} else {
+			ts.restoreCursor()
+		}
+	case 1049:
+		if on {
+			ts.saveCursor()
+			ts.SetAltScreen(true)
+			ts.eraseLines(0, ts.Rows)
+		} else {
+			ts.SetAltScreen(false)
+			ts.restoreCursor()
+		}
+	case 2004:
+		ts.BracketPaste = on
+	}
+}
+
+// osc processes an OSC sequence in pbuf -- only the title is supported
+func (ts *TermScreen) osc() {
+	prm := ts.pbuf
+	i := bytes.IndexByte(prm, ';')
+	if i < 0 {
+		return
+	}
+	switch string(prm[:i]) {
+	case "0", "2":
+		ts.Title = string(prm[i+1:])
+	}
+}
+
+// TermCellsMarkup returns the text and html markup for given line of
+// cells -- trailing blank cells are removed, except that the text is at
+// least minLen chars long
+func TermCellsMarkup(cells []TermCell, minLen int) (txt, mu []byte) {
+	n := len(cells)
+	for n > minLen && (cells[n-1].Ch == 0 || cells[n-1].Ch == ' ') && cells[n-1].St.Bg == "" {
+		n--
+	}
+	txt = make([]byte, 0, n)
+	mu = make([]byte, 0, n)
+	var rb [utf8.UTFMax]byte
+	var cst ANSIState
+	st := 0
+	for i := 0; i <= n; i++ {
+		if i < n && cells[i].St == cst {
+			continue
+		}
+		if i > st {
+			rs := len(txt)
+			for _, c := range cells[st:i] {
+				r := c.Ch
+				if r == 0 {
+					r = ' '
+				}
+				sz := utf8.EncodeRune(rb[:], r)
+				txt = append(txt, rb[:sz]...)
+			}
+			mu = append(mu, cst.StartTags()...)
+			mu = append(mu, HTMLEscapeBytes(txt[rs:])...)
+			mu = append(mu, cst.EndTags()...)
+		}
+		if i < n {
+			cst = cells[i].St
+		}
+		st = i
+	}
+	return
+}
+
+// TextMarkup returns the text and markup lines for the scrollback (only
+// for the main screen) followed by the screen lines, and the line and
+// char position of the cursor in those lines
+func (ts *TermScreen) TextMarkup() (lns, mus [][]byte, curLn, curCh int) {
+	ts.Mu.Lock()
+	defer ts.Mu.Unlock()
+	var sb [][]TermCell
+	if !ts.AltScreen {
+		sb = ts.Scrollback
+	}
+	nln := len(sb) + ts.Rows
+	lns = make([][]byte, nln)
+	mus = make([][]byte, nln)
+	for i, ln := range sb {
+		lns[i], mus[i] = TermCellsMarkup(ln, 0)
+	}
+	curLn = len(sb) + ts.CurRow
+	curCh = ts.CurCol
+	for r, ln := range ts.Lines {
+		ml := 0
+		if r == ts.CurRow {
+			ml = ts.CurCol
+		}
+		lns[len(sb)+r], mus[len(sb)+r] = TermCellsMarkup(ln, ml)
+	}
+	return
+}
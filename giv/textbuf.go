@@ -63,6 +63,7 @@ type TextBuf struct {
 	ki.Node
 	Txt              []byte              `json:"-" xml:"text" desc:"the current value of the entire text being edited -- using []byte slice for greater efficiency"`
 	Autosave         bool                `desc:"if true, auto-save file after changes (in a separate routine)"`
+	PersistUndo      bool                `desc:"if true, the undo history is saved next to the file whenever it is saved, and restored when the file is next opened, if it has not changed in the meantime -- see UndoFilename"`
 	Opts             textbuf.Opts        `desc:"options for how text editing / viewing works"`
 	Filename         gi.FileName         `json:"-" xml:"-" desc:"filename of file last loaded or saved"`
 	Info             FileInfo            `desc:"full info about file"`
@@ -88,9 +89,12 @@ type TextBuf struct {
 	Views            []*TextView         `json:"-" xml:"-" desc:"the TextViews that are currently viewing this buffer"`
 	Undos            textbuf.Undo        `json:"-" xml:"-" desc:"undo manager"`
 	PosHistory       []lex.Pos           `json:"-" xml:"-" desc:"history of cursor positions -- can move back through them"`
+	PosHistMu        sync.Mutex          `json:"-" xml:"-" view:"-" desc:"mutex for adding to the PosHistory, which is read by other goroutines, e.g., TextBufSession"`
 	Complete         *gi.Complete        `json:"-" xml:"-" desc:"functions and data for text completion"`
 	Spell            *gi.Spell           `json:"-" xml:"-" desc:"functions and data for spelling correction"`
 	CurView          *TextView           `json:"-" xml:"-" desc:"current textview -- e.g., the one that initiated Complete or Correct process -- update cursor position in this view -- is reset to nil after usage always"`
+	LSP              *TextBufLSP         `json:"-" xml:"-" desc:"language server protocol client connection for this buffer, if set -- use SetLSP and DeleteLSP"`
+	SemTags          []lex.Line          `json:"-" xml:"-" desc:"semantic tags for each line from the language server -- merged with HiTags for markup"`
 }
 
 var KiT_TextBuf = kit.Types.AddType(&TextBuf{}, TextBufProps)
@@ -100,6 +104,7 @@ func (tb *TextBuf) Disconnect() {
 	tb.TextBufSig.DisconnectAll()
 	tb.DeleteSpell()
 	tb.DeleteCompleter()
+	tb.DeleteLSP()
 }
 
 var TextBufProps = ki.Props{
@@ -184,6 +189,9 @@ func (tb *TextBuf) IsChanged() bool {
 // SetChanged marks buffer as changed
 func (tb *TextBuf) SetChanged() {
 	tb.SetFlag(int(TextBufChanged))
+	if tb.LSP != nil {
+		tb.LSP.Changed()
+	}
 }
 
 // ClearChanged marks buffer as un-changed
@@ -231,6 +239,61 @@ func (tb *TextBuf) SetTextLines(lns [][]byte, cpy bool) {
 	tb.ReMarkup()
 }
 
+// SetTextLinesMarkup sets the text to given lines of bytes, with the given
+// html markup for each line, for text that is marked up elsewhere (e.g.,
+// TerminalView) -- the buffer should not have syntax highlighting.
+// Does not signal the views, which must be refreshed by the caller,
+// and the bytes are used directly without copying.
+func (tb *TextBuf) SetTextLinesMarkup(lns, mus [][]byte) {
+	tb.SetLinesMarkupFrom(0, lns, mus)
+}
+
+// SetLinesMarkupFrom sets the lines from given starting line on to the
+// given lines of bytes and html markup, as in SetTextLinesMarkup, keeping
+// the lines before it, which must be the same as in lns and mus (which
+// are for the whole buffer).  This allows views to only update the lines
+// that have changed.
+func (tb *TextBuf) SetLinesMarkupFrom(st int, lns, mus [][]byte) {
+	tb.Defaults()
+	if len(lns) == 0 {
+		lns = [][]byte{[]byte("")}
+	}
+	nln := len(lns)
+	tb.LinesMu.Lock()
+	tb.MarkupMu.Lock()
+	st = ints.MinInt(st, ints.MinInt(nln, len(tb.Lines)))
+	if st < 0 {
+		st = 0
+	}
+	tb.Lines = append(tb.Lines[:st], make([][]rune, nln-st)...)
+	tb.LineBytes = lns
+	tb.Tags = append(tb.Tags[:st], make([]lex.Line, nln-st)...)
+	tb.HiTags = append(tb.HiTags[:st], make([]lex.Line, nln-st)...)
+	tb.SemTags = nil
+	tb.Markup = append(tb.Markup[:st], make([][]byte, nln-st)...)
+	tb.ByteOffs = append(tb.ByteOffs[:st], make([]int, nln-st)...)
+	bo := 0
+	if st > 0 {
+		bo = tb.ByteOffs[st-1] + len(lns[st-1]) + 1
+	}
+	for ln := st; ln < nln; ln++ {
+		txt := lns[ln]
+		tb.ByteOffs[ln] = bo
+		tb.Lines[ln] = bytes.Runes(txt)
+		if ln < len(mus) {
+			tb.Markup[ln] = mus[ln]
+		} else {
+			tb.Markup[ln] = HTMLEscapeRunes(tb.Lines[ln])
+		}
+		bo += len(txt) + 1 // lf
+	}
+	tb.NLines = nln
+	tb.TotalBytes = bo
+	tb.MarkupMu.Unlock()
+	tb.LinesMu.Unlock()
+	tb.LinesToBytes()
+}
+
 // EditDone finalizes any current editing, sends signal
 func (tb *TextBuf) EditDone() {
 	tb.AutoSaveDelete()
@@ -338,6 +401,7 @@ func (tb *TextBuf) New(nlines int) {
 	tb.LineBytes = make([][]byte, nlines)
 	tb.Tags = make([]lex.Line, nlines)
 	tb.HiTags = make([]lex.Line, nlines)
+	tb.SemTags = nil
 	tb.Markup = make([][]byte, nlines)
 
 	if cap(tb.ByteOffs) >= nlines {
@@ -420,6 +484,27 @@ func (tb *TextBuf) FileModCheck() bool {
 	return false
 }
 
+// ReloadIfModified checks if the underlying file has been modified on disk
+// since last Stat (open, save), e.g., when notified by a FileTree that is
+// watching for changes.  If so, and autoReload is true and there are no
+// unsaved edits, the buffer is reverted to the file on disk -- otherwise the
+// user is prompted as in FileModCheck.  returns true if file was modified
+func (tb *TextBuf) ReloadIfModified(autoReload bool) bool {
+	if tb.Filename == "" {
+		return false
+	}
+	info, err := os.Stat(string(tb.Filename))
+	if err != nil || info.ModTime() == time.Time(tb.Info.ModTime) {
+		return false
+	}
+	if !tb.IsChanged() && (autoReload || tb.ViewportFromView() == nil) {
+		tb.Revert()
+		return true
+	}
+	tb.ClearFlag(int(TextBufFileModOk)) // prompt again for each new change
+	return tb.FileModCheck()
+}
+
 // Open loads text from a file into the buffer
 func (tb *TextBuf) Open(filename gi.FileName) error {
 	tb.Defaults()
@@ -431,6 +516,9 @@ func (tb *TextBuf) Open(filename gi.FileName) error {
 		return err
 	}
 	tb.SetName(string(filename))
+	if tb.PersistUndo {
+		tb.UndoOpen()
+	}
 
 	tb.InitialMarkup()
 	tb.Refresh()
@@ -540,6 +628,12 @@ func (tb *TextBuf) SaveFile(filename gi.FileName) error {
 		tb.Filename = filename
 		tb.SetName(string(filename))
 		tb.Stat()
+		if tb.PersistUndo {
+			tb.UndoSave()
+		}
+		if tb.LSP != nil {
+			tb.LSP.Saved()
+		}
 	}
 	return err
 }
@@ -615,6 +709,7 @@ func (tb *TextBuf) Close(afterFun func(canceled bool)) bool {
 		return false // awaiting decisions..
 	}
 	tb.TextBufSig.Emit(tb.This(), int64(TextBufClosed), nil)
+	tb.DeleteLSP()
 	// for _, tve := range tb.Views {
 	// 	tve.SetBuf(nil) // automatically disconnects signals, views
 	// }
@@ -686,6 +781,40 @@ func (tb *TextBuf) AutoSaveCheck() bool {
 	return true
 }
 
+////////////////////////////////////////////////////////////////////////////////////////
+//		Persistent Undo
+
+// UndoFilename returns the filename where the undo history is saved when
+// PersistUndo is set -- a hidden file next to the file
+func (tb *TextBuf) UndoFilename() string {
+	path, fn := filepath.Split(string(tb.Filename))
+	return filepath.Join(path, "."+fn+".undo")
+}
+
+// UndoSave saves the undo history to UndoFilename, keyed by the hash of
+// the current text -- called on SaveFile when PersistUndo is set
+func (tb *TextBuf) UndoSave() error {
+	if tb.Filename == "" {
+		return fmt.Errorf("giv.TextBuf: filename is empty for UndoSave")
+	}
+	return tb.Undos.SaveJSON(tb.UndoFilename(), textbuf.UndoHash(tb.LinesToBytesCopy()))
+}
+
+// UndoOpen restores the undo history from UndoFilename, if it was saved for
+// the current text -- returns false if not -- called on Open when
+// PersistUndo is set
+func (tb *TextBuf) UndoOpen() bool {
+	if tb.Filename == "" {
+		return false
+	}
+	return tb.Undos.OpenJSON(tb.UndoFilename(), textbuf.UndoHash(tb.LinesToBytesCopy())) == nil
+}
+
+// UndoDelete deletes any saved undo history
+func (tb *TextBuf) UndoDelete() {
+	os.Remove(tb.UndoFilename())
+}
+
 /////////////////////////////////////////////////////////////////////////////
 //   Appending Lines
 
@@ -942,6 +1071,9 @@ func (tb *TextBuf) BytesToLines() {
 	}
 	tb.TotalBytes = bo
 	tb.LinesMu.Unlock()
+	if tb.LSP != nil {
+		tb.LSP.Changed()
+	}
 }
 
 // Strings returns the current text as []string array.
@@ -986,11 +1118,20 @@ func (tb *TextBuf) SearchRegexp(re *regexp.Regexp) (int, []textbuf.Match) {
 // BraceMatch finds the brace, bracket, or parens that is the partner
 // of the one passed to function.
 func (tb *TextBuf) BraceMatch(r rune, st lex.Pos) (en lex.Pos, found bool) {
+	return tb.BraceMatchLines(r, st, TextBufMaxScopeLines)
+}
+
+// BraceMatchLines finds the brace, bracket, or parens that is the partner
+// of the one passed to function, searching within maxLns lines.
+func (tb *TextBuf) BraceMatchLines(r rune, st lex.Pos, maxLns int) (en lex.Pos, found bool) {
 	tb.LinesMu.RLock()
 	defer tb.LinesMu.RUnlock()
 	tb.MarkupMu.RLock()
 	defer tb.MarkupMu.RUnlock()
-	return lex.BraceMatch(tb.Lines, tb.HiTags, r, st, TextBufMaxScopeLines)
+	if st.Ln >= len(tb.HiTags) || len(tb.HiTags) < len(tb.Lines) { // markup out of sync
+		return lex.Pos{Ln: -1}, false
+	}
+	return lex.BraceMatch(tb.Lines, tb.HiTags, r, st, maxLns)
 }
 
 /////////////////////////////////////////////////////////////////////////////
@@ -1433,6 +1574,8 @@ func (tb *TextBuf) ReplaceText(delSt, delEd, insPos lex.Pos, insTxt string, sign
 // SavePosHistory saves the cursor position in history stack of cursor positions --
 // tracks across views -- returns false if position was on same line as last one saved
 func (tb *TextBuf) SavePosHistory(pos lex.Pos) bool {
+	tb.PosHistMu.Lock()
+	defer tb.PosHistMu.Unlock()
 	if tb.PosHistory == nil {
 		tb.PosHistory = make([]lex.Pos, 0, 1000)
 	}
@@ -1502,6 +1645,15 @@ func (tb *TextBuf) LinesInserted(tbe *textbuf.Edit) {
 	copy(nht[stln:], tmpht)
 	tb.HiTags = nht
 
+	// SemTags
+	if len(tb.SemTags) >= stln {
+		tmpst := make([]lex.Line, nsz)
+		nst := append(tb.SemTags, tmpst...)
+		copy(nst[stln+nsz:], nst[stln:])
+		copy(nst[stln:], tmpst)
+		tb.SemTags = nst
+	}
+
 	// ByteOffs -- maintain mem updt
 	tmpof := make([]int, nsz)
 	nof := append(tb.ByteOffs, tmpof...)
@@ -1542,6 +1694,9 @@ func (tb *TextBuf) LinesDeleted(tbe *textbuf.Edit) {
 	tb.Markup = append(tb.Markup[:stln], tb.Markup[edln:]...)
 	tb.Tags = append(tb.Tags[:stln], tb.Tags[edln:]...)
 	tb.HiTags = append(tb.HiTags[:stln], tb.HiTags[edln:]...)
+	if len(tb.SemTags) >= edln {
+		tb.SemTags = append(tb.SemTags[:stln], tb.SemTags[edln:]...)
+	}
 	tb.ByteOffs = append(tb.ByteOffs[:stln], tb.ByteOffs[edln:]...)
 
 	if tb.Hi.UsingPi() {
@@ -1645,6 +1800,15 @@ func (tb *TextBuf) AdjustedTags(ln int) lex.Line {
 	return tb.AdjustedTagsImpl(tb.Tags[ln], ln)
 }
 
+// MergeSemTags returns given tags merged with the SemTags for given line,
+// adjusted for edits -- must be called under MarkupMu lock
+func (tb *TextBuf) MergeSemTags(ln int, tags lex.Line) lex.Line {
+	if ln >= len(tb.SemTags) || len(tb.SemTags[ln]) == 0 {
+		return tags
+	}
+	return lex.MergeLines(tb.AdjustedTagsImpl(tb.SemTags[ln], ln), tags)
+}
+
 // AdjustedTagsImpl updates tag positions for edits, for given list of tags
 func (tb *TextBuf) AdjustedTagsImpl(tags lex.Line, ln int) lex.Line {
 	sz := len(tags)
@@ -1755,7 +1919,7 @@ func (tb *TextBuf) MarkupAllLines(maxLines int) {
 	}
 	for ln := 0; ln < maxln; ln++ {
 		tb.Tags[ln] = tb.AdjustedTags(ln)
-		tb.Markup[ln] = tb.Hi.MarkupLine(tb.Lines[ln], tb.HiTags[ln], tb.Tags[ln])
+		tb.Markup[ln] = tb.Hi.MarkupLine(tb.Lines[ln], tb.HiTags[ln], tb.MergeSemTags(ln, tb.Tags[ln]))
 	}
 	tb.MarkupMu.Unlock()
 	tb.LinesMu.Unlock()
@@ -1773,7 +1937,7 @@ func (tb *TextBuf) MarkupFromTags() {
 
 	maxln := ints.MinInt(len(tb.HiTags), tb.NLines)
 	for ln := 0; ln < maxln; ln++ {
-		tb.Markup[ln] = tb.Hi.MarkupLine(tb.Lines[ln], tb.HiTags[ln], nil)
+		tb.Markup[ln] = tb.Hi.MarkupLine(tb.Lines[ln], tb.HiTags[ln], tb.MergeSemTags(ln, nil))
 	}
 	tb.MarkupMu.Unlock()
 	tb.ClearFlag(int(TextBufMarkingUp))
@@ -1797,7 +1961,7 @@ func (tb *TextBuf) MarkupLines(st, ed int) bool {
 		mt, err := tb.Hi.MarkupTagsLine(ln, ltxt)
 		if err == nil {
 			tb.HiTags[ln] = mt
-			tb.Markup[ln] = tb.Hi.MarkupLine(ltxt, mt, tb.AdjustedTags(ln))
+			tb.Markup[ln] = tb.Hi.MarkupLine(ltxt, mt, tb.MergeSemTags(ln, tb.AdjustedTags(ln)))
 		} else {
 			tb.Markup[ln] = HTMLEscapeRunes(ltxt)
 			allgood = false
@@ -91,7 +91,11 @@ func (te *Edit) CopyFrom(cp *Edit) {
 
 // AdjustPos adjusts the given text position as a function of the edit.
 // if the position was within a deleted region of text, del determines
-// what is returned
+// what is returned.  Positions on the last line of a deleted region after
+// its end are joined onto the start line, and positions on the first line
+// of an inserted region after its start move to the last line of the
+// insert, both with their char offset relative to the region, so
+// multi-line edits keep the position on the same text.
 func (te *Edit) AdjustPos(pos lex.Pos, del AdjustPosDel) lex.Pos {
 	if te == nil {
 		return pos
@@ -120,17 +124,13 @@ func (te *Edit) AdjustPos(pos lex.Pos, del AdjustPosDel) lex.Pos {
 			}
 		}
 		// this means pos.Ln == te.Reg.End.Ln, Ch >= end
-		if dl == 0 {
-			pos.Ch -= (te.Reg.End.Ch - te.Reg.Start.Ch)
-		} else {
-			pos.Ch -= te.Reg.End.Ch
-		}
+		pos.Ch = te.Reg.Start.Ch + pos.Ch - te.Reg.End.Ch
+		pos.Ln = te.Reg.Start.Ln
 	} else {
-		if dl == 0 {
-			pos.Ch += (te.Reg.End.Ch - te.Reg.Start.Ch)
-		} else {
-			pos.Ln += dl
+		if pos.Ln == te.Reg.Start.Ln { // rest of start line moves to end of insert
+			pos.Ch = te.Reg.End.Ch + pos.Ch - te.Reg.Start.Ch
 		}
+		pos.Ln += dl
 	}
 	return pos
 }
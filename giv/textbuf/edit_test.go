@@ -0,0 +1,53 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"testing"
+
+	"github.com/goki/pi/lex"
+)
+
+func TestAdjustPos(t *testing.T) {
+	pos := func(ln, ch int) lex.Pos {
+		return lex.Pos{Ln: ln, Ch: ch}
+	}
+	reg := func(sl, sc, el, ec int) Region {
+		return Region{Start: pos(sl, sc), End: pos(el, ec)}
+	}
+	tests := []struct {
+		reg Region
+		del bool
+		pos lex.Pos
+		adj AdjustPosDel
+		exp lex.Pos
+	}{
+		// insert within a line
+		{reg(2, 4, 2, 7), false, pos(2, 2), AdjustPosDelErr, pos(2, 2)},
+		{reg(2, 4, 2, 7), false, pos(2, 4), AdjustPosDelErr, pos(2, 4)},
+		{reg(2, 4, 2, 7), false, pos(2, 6), AdjustPosDelErr, pos(2, 9)},
+		{reg(2, 4, 2, 7), false, pos(5, 6), AdjustPosDelErr, pos(5, 6)},
+		// multi-line insert: rest of start line moves to the end of the insert
+		{reg(2, 4, 4, 3), false, pos(2, 6), AdjustPosDelErr, pos(4, 5)},
+		{reg(2, 4, 4, 3), false, pos(3, 1), AdjustPosDelErr, pos(5, 1)},
+		{reg(2, 4, 4, 3), false, pos(8, 1), AdjustPosDelErr, pos(10, 1)},
+		// delete within a line
+		{reg(2, 4, 2, 7), true, pos(2, 9), AdjustPosDelErr, pos(2, 6)},
+		{reg(2, 4, 2, 7), true, pos(2, 5), AdjustPosDelErr, lex.PosErr},
+		{reg(2, 4, 2, 7), true, pos(2, 5), AdjustPosDelStart, pos(2, 4)},
+		// multi-line delete: rest of end line is joined onto the start line
+		{reg(2, 4, 4, 3), true, pos(4, 3), AdjustPosDelErr, pos(2, 4)},
+		{reg(2, 4, 4, 3), true, pos(4, 8), AdjustPosDelErr, pos(2, 9)},
+		{reg(2, 4, 4, 3), true, pos(3, 8), AdjustPosDelStart, pos(2, 4)},
+		{reg(2, 4, 4, 3), true, pos(4, 1), AdjustPosDelErr, lex.PosErr},
+		{reg(2, 4, 4, 3), true, pos(7, 1), AdjustPosDelErr, pos(5, 1)},
+	}
+	for _, tst := range tests {
+		te := &Edit{Reg: tst.reg, Delete: tst.del}
+		if got := te.AdjustPos(tst.pos, tst.adj); got != tst.exp {
+			t.Errorf("AdjustPos(%v) for %v delete: %v = %v, expected %v", tst.pos, tst.reg, tst.del, got, tst.exp)
+		}
+	}
+}
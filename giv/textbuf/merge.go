@@ -0,0 +1,260 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Conflict markers, as written by git, svn, diff3 etc
+const (
+	ConflictStart = "<<<<<<<"
+	ConflictBase  = "|||||||"
+	ConflictSep   = "======="
+	ConflictEnd   = ">>>>>>>"
+)
+
+// MergeHunk is one region of a three-way merge of ours and theirs changes
+// relative to a common base.  The Tag is one of: 'e' (equal -- unchanged
+// in both), 'o' (changed in ours only), 't' (changed in theirs only), 's'
+// (same change in both), or 'c' (conflict -- different changes in both).
+type MergeHunk struct {
+	Tag    byte     `desc:"e = equal, o = ours changed, t = theirs changed, s = same change in both, c = conflict"`
+	Base   []string `desc:"lines of the base version -- nil if not known (e.g., from conflict markers without base)"`
+	Ours   []string `desc:"lines of our version"`
+	Theirs []string `desc:"lines of their version"`
+}
+
+// Merged returns the merged lines for the hunk -- for a conflict, the
+// lines with conflict markers around ours and theirs
+func (mh *MergeHunk) Merged() []string {
+	switch mh.Tag {
+	case 'e', 'o', 's':
+		return mh.Ours
+	case 't':
+		return mh.Theirs
+	}
+	return mh.ConflictLines("ours", "theirs")
+}
+
+// ConflictLines returns lines with conflict markers around ours and theirs,
+// with given labels for each
+func (mh *MergeHunk) ConflictLines(olbl, tlbl string) []string {
+	lns := make([]string, 0, len(mh.Ours)+len(mh.Theirs)+3)
+	lns = append(lns, ConflictStart+" "+olbl)
+	lns = append(lns, mh.Ours...)
+	lns = append(lns, ConflictSep)
+	lns = append(lns, mh.Theirs...)
+	lns = append(lns, ConflictEnd+" "+tlbl)
+	return lns
+}
+
+// Merge is a three-way merge, as a sequence of hunks covering the full text
+type Merge []MergeHunk
+
+// NConflicts returns the number of conflict hunks
+func (mg Merge) NConflicts() int {
+	n := 0
+	for _, mh := range mg {
+		if mh.Tag == 'c' {
+			n++
+		}
+	}
+	return n
+}
+
+// Lines returns the merged lines, with conflict markers for conflicts
+func (mg Merge) Lines() []string {
+	var lns []string
+	for i := range mg {
+		lns = append(lns, mg[i].Merged()...)
+	}
+	return lns
+}
+
+// String satisfies the Stringer interface
+func (mg Merge) String() string {
+	var b strings.Builder
+	for _, mh := range mg {
+		fmt.Fprintf(&b, "%c: base: %v ours: %v theirs: %v\n", mh.Tag, len(mh.Base), len(mh.Ours), len(mh.Theirs))
+	}
+	return b.String()
+}
+
+// mergeChg is a change from base in one side of a merge
+type mergeChg struct {
+	side int // 0 = ours, 1 = theirs
+	i1   int
+	i2   int
+	j1   int
+	j2   int
+}
+
+// MergeLines computes the three-way merge of ours and theirs, which are
+// both derived from base, using DiffLines of each relative to base.
+// Changes made only on one side are taken, as are identical changes on both
+// sides, while overlapping (or adjacent) different changes are conflicts.
+func MergeLines(base, ours, theirs []string) Merge {
+	sides := [2][]string{ours, theirs}
+	var chgs []mergeChg
+	for si, sd := range sides {
+		for _, df := range DiffLines(base, sd) {
+			if df.Tag == 'e' {
+				continue
+			}
+			chgs = append(chgs, mergeChg{side: si, i1: df.I1, i2: df.I2, j1: df.J1, j2: df.J2})
+		}
+	}
+	sort.SliceStable(chgs, func(i, j int) bool {
+		return chgs[i].i1 < chgs[j].i1
+	})
+	var mg Merge
+	var offs [2]int // offset of side line from base line, in unchanged regions
+	bln := 0        // current base line
+	nc := len(chgs)
+	for ci := 0; ci < nc; {
+		lo := chgs[ci].i1
+		hi := chgs[ci].i2
+		cj := ci + 1
+		for cj < nc && chgs[cj].i1 <= hi {
+			if chgs[cj].i2 > hi {
+				hi = chgs[cj].i2
+			}
+			cj++
+		}
+		if lo > bln {
+			eq := base[bln:lo]
+			mg = append(mg, MergeHunk{Tag: 'e', Base: eq, Ours: eq, Theirs: eq})
+		}
+		var rng [2][2]int
+		var has [2]bool
+		for si := 0; si < 2; si++ {
+			rng[si] = [2]int{lo + offs[si], hi + offs[si]}
+		}
+		for _, ch := range chgs[ci:cj] {
+			si := ch.side
+			if !has[si] {
+				rng[si][0] = ch.j1 - (ch.i1 - lo)
+				has[si] = true
+			}
+			rng[si][1] = ch.j2 + (hi - ch.i2)
+		}
+		mh := MergeHunk{Base: base[lo:hi]}
+		mh.Ours = sides[0][rng[0][0]:rng[0][1]]
+		mh.Theirs = sides[1][rng[1][0]:rng[1][1]]
+		switch {
+		case !has[1]:
+			mh.Tag = 'o'
+		case !has[0]:
+			mh.Tag = 't'
+		case equalLines(mh.Ours, mh.Theirs):
+			mh.Tag = 's'
+		default:
+			mh.Tag = 'c'
+		}
+		mg = append(mg, mh)
+		for si := 0; si < 2; si++ {
+			offs[si] = rng[si][1] - hi
+		}
+		bln = hi
+		ci = cj
+	}
+	if bln < len(base) {
+		eq := base[bln:]
+		mg = append(mg, MergeHunk{Tag: 'e', Base: eq, Ours: eq, Theirs: eq})
+	}
+	return mg
+}
+
+// equalLines returns true if the two line slices are identical
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// HasConflictMarkers returns true if given lines contain conflict markers
+func HasConflictMarkers(lns []string) bool {
+	for _, ln := range lns {
+		if strings.HasPrefix(ln, ConflictStart) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseConflicts parses lines containing conflict markers (<<<<<<<, an
+// optional ||||||| base section, =======, >>>>>>>) into a Merge, where
+// lines outside of the markers are equal hunks, and each marked region is a
+// conflict hunk.  The labels after the start and end markers are returned
+// for the first conflict (e.g., HEAD and a branch name).
+// Returns an error if the markers are not properly nested.
+func ParseConflicts(lns []string) (mg Merge, olbl, tlbl string, err error) {
+	const (
+		outside = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+	state := outside
+	st := 0
+	var mh MergeHunk
+	for i, ln := range lns {
+		switch {
+		case strings.HasPrefix(ln, ConflictStart):
+			if state != outside {
+				return nil, "", "", fmt.Errorf("textbuf.ParseConflicts: unexpected %v at line %d", ConflictStart, i+1)
+			}
+			if i > st {
+				eq := lns[st:i]
+				mg = append(mg, MergeHunk{Tag: 'e', Base: eq, Ours: eq, Theirs: eq})
+			}
+			if olbl == "" {
+				olbl = strings.TrimSpace(ln[len(ConflictStart):])
+			}
+			mh = MergeHunk{Tag: 'c'}
+			mh.Ours = []string{}
+			mh.Theirs = []string{}
+			state = inOurs
+		case strings.HasPrefix(ln, ConflictBase) && state == inOurs:
+			mh.Base = []string{}
+			state = inBase
+		case ln == ConflictSep && (state == inOurs || state == inBase):
+			state = inTheirs
+		case strings.HasPrefix(ln, ConflictEnd) && state == inTheirs:
+			if tlbl == "" {
+				tlbl = strings.TrimSpace(ln[len(ConflictEnd):])
+			}
+			mg = append(mg, mh)
+			state = outside
+			st = i + 1
+		default:
+			switch state {
+			case inOurs:
+				mh.Ours = append(mh.Ours, ln)
+			case inBase:
+				mh.Base = append(mh.Base, ln)
+			case inTheirs:
+				mh.Theirs = append(mh.Theirs, ln)
+			}
+		}
+	}
+	if state != outside {
+		return nil, "", "", fmt.Errorf("textbuf.ParseConflicts: unterminated conflict at end of text")
+	}
+	if st < len(lns) {
+		eq := lns[st:]
+		mg = append(mg, MergeHunk{Tag: 'e', Base: eq, Ours: eq, Theirs: eq})
+	}
+	return mg, olbl, tlbl, nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"strings"
+	"testing"
+)
+
+func mergeTestLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		base, ours, theirs string
+		tags               string
+		merged             string
+		nconf              int
+	}{
+		{"a,b,c", "a,b,c", "a,b,c", "e", "a,b,c", 0},
+		{"a,b,c", "a,B,c", "a,b,c", "eoe", "a,B,c", 0},
+		{"a,b,c", "a,b,c", "a,b,C", "et", "a,b,C", 0},
+		{"a,b,c", "A,b,c", "a,b,C", "oet", "A,b,C", 0},
+		{"a,b,c", "a,X,c", "a,X,c", "ese", "a,X,c", 0},
+		{"a,b,c", "a,X,c", "a,Y,c", "ece", "a,<<<<<<< ours,X,=======,Y,>>>>>>> theirs,c", 1},
+		{"a,b,c", "a,b,c,d", "a,b,c", "eo", "a,b,c,d", 0},
+		{"a,b,c", "a,c", "a,b,c,d", "eoet", "a,c,d", 0},
+		{"a,b,c,d", "a,X,c,d", "a,b,Y,d", "ece", "a,<<<<<<< ours,X,c,=======,b,Y,>>>>>>> theirs,d", 1}, // adjacent changes conflict
+		{"", "a", "b", "c", "<<<<<<< ours,a,=======,b,>>>>>>> theirs", 1},
+	}
+	for _, tst := range tests {
+		mg := MergeLines(mergeTestLines(tst.base), mergeTestLines(tst.ours), mergeTestLines(tst.theirs))
+		tags := ""
+		for _, mh := range mg {
+			tags += string(mh.Tag)
+		}
+		if tags != tst.tags {
+			t.Errorf("MergeLines(%q, %q, %q): tags %q != %q", tst.base, tst.ours, tst.theirs, tags, tst.tags)
+		}
+		if mrg := strings.Join(mg.Lines(), ","); mrg != tst.merged {
+			t.Errorf("MergeLines(%q, %q, %q): merged %q != %q", tst.base, tst.ours, tst.theirs, mrg, tst.merged)
+		}
+		if nc := mg.NConflicts(); nc != tst.nconf {
+			t.Errorf("MergeLines(%q, %q, %q): %v conflicts != %v", tst.base, tst.ours, tst.theirs, nc, tst.nconf)
+		}
+	}
+}
+
+func TestParseConflicts(t *testing.T) {
+	lns := mergeTestLines("a,<<<<<<< HEAD,X,||||||| base,b,=======,Y,>>>>>>> topic,c")
+	mg, olbl, tlbl, err := ParseConflicts(lns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if olbl != "HEAD" || tlbl != "topic" {
+		t.Errorf("ParseConflicts: labels %q, %q", olbl, tlbl)
+	}
+	if len(mg) != 3 || mg[1].Tag != 'c' || strings.Join(mg[1].Base, ",") != "b" || strings.Join(mg[1].Ours, ",") != "X" || strings.Join(mg[1].Theirs, ",") != "Y" {
+		t.Errorf("ParseConflicts: wrong hunks: %v", mg)
+	}
+	for _, bad := range []string{"a,<<<<<<<,b", "a,<<<<<<<,b,<<<<<<<,c,=======,>>>>>>>"} {
+		if _, _, _, err := ParseConflicts(mergeTestLines(bad)); err == nil {
+			t.Errorf("ParseConflicts(%q): expected error", bad)
+		}
+	}
+}
@@ -26,13 +26,40 @@ type Undo struct {
 	UndoStack []*Edit    `desc:"undo stack of *undo* edits -- added to whenever an Undo is done -- for emacs-style undo"`
 	Pos       int        `desc:"undo position in stack"`
 	Group     int        `desc:"group counter"`
+	Grouping  int        `desc:"if > 0, all edits are saved in the current group, regardless of timing -- see GroupStart / GroupEnd"`
 	Mu        sync.Mutex `json:"-" xml:"-" desc:"mutex protecting all updates"`
+	grpSaved  bool
 }
 
 // NewGroup increments the Group counter so subsequent undos will be grouped separately
+// -- does nothing within an explicit GroupStart / GroupEnd block
 func (un *Undo) NewGroup() {
 	un.Mu.Lock()
-	un.Group++
+	if un.Grouping == 0 {
+		un.Group++
+	}
+	un.Mu.Unlock()
+}
+
+// GroupStart starts an explicit undo group: all edits saved until the
+// matching GroupEnd are in one group, and thus undone and redone together,
+// e.g., for the same edit made at multiple cursors.  Calls can be nested.
+func (un *Undo) GroupStart() {
+	un.Mu.Lock()
+	if un.Grouping == 0 {
+		un.Group++
+		un.grpSaved = false
+	}
+	un.Grouping++
+	un.Mu.Unlock()
+}
+
+// GroupEnd ends an explicit undo group started by GroupStart
+func (un *Undo) GroupEnd() {
+	un.Mu.Lock()
+	if un.Grouping > 0 {
+		un.Grouping--
+	}
 	un.Mu.Unlock()
 }
 
@@ -40,6 +67,7 @@ func (un *Undo) NewGroup() {
 func (un *Undo) Reset() {
 	un.Pos = 0
 	un.Group = 0
+	un.Grouping = 0
 	un.Stack = nil
 	un.UndoStack = nil
 }
@@ -58,7 +86,7 @@ func (un *Undo) Save(tbe *Edit) {
 		}
 		un.Stack = un.Stack[:un.Pos]
 	}
-	if len(un.Stack) > 0 {
+	if len(un.Stack) > 0 && !(un.Grouping > 0 && un.grpSaved) {
 		since := tbe.Reg.SinceMSec(&un.Stack[len(un.Stack)-1].Reg)
 		if since > UndoGroupDelayMSec {
 			un.Group++
@@ -67,6 +95,9 @@ func (un *Undo) Save(tbe *Edit) {
 			}
 		}
 	}
+	if un.Grouping > 0 {
+		un.grpSaved = true
+	}
 	tbe.Group = un.Group
 	if UndoTrace {
 		fmt.Printf("Undo: save to pos: %v: group: %v\n->\t%v\n", un.Pos, un.Group, string(tbe.ToBytes()))
@@ -0,0 +1,89 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+)
+
+// UndoSaveMax is the maximum number of edits saved by Undo.SaveJSON --
+// older edits are dropped
+var UndoSaveMax = 10000
+
+// UndoFile is the saved form of an Undo stack, for the text with given Hash
+type UndoFile struct {
+	Hash  string  `desc:"content hash of the text that the undo stack applies to -- see UndoHash"`
+	Pos   int     `desc:"undo position in stack"`
+	Group int     `desc:"group counter"`
+	Stack []*Edit `desc:"undo stack of edits"`
+}
+
+// UndoHash returns the content hash of given text, used to key saved undo
+// stacks to the exact text they apply to
+func UndoHash(txt []byte) string {
+	sum := sha256.Sum256(txt)
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveJSON saves the undo stack to given file, keyed by the hash of the
+// current text that it applies to (see UndoHash) -- at most UndoSaveMax of
+// the most recent edits are saved.
+func (un *Undo) SaveJSON(filename string, hash string) error {
+	un.Mu.Lock()
+	uf := UndoFile{Hash: hash, Pos: un.Pos, Group: un.Group, Stack: un.Stack}
+	if n := len(uf.Stack) - UndoSaveMax; n > 0 {
+		uf.Stack = uf.Stack[n:]
+		uf.Pos -= n
+		if uf.Pos < 0 {
+			uf.Pos = 0
+		}
+	}
+	b, err := json.Marshal(&uf)
+	un.Mu.Unlock()
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(filename, b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenJSON opens an undo stack saved by SaveJSON, replacing the current one,
+// only if it was saved for the text with given hash -- otherwise the edits
+// would not apply, so an error is returned and the current stack is unchanged.
+func (un *Undo) OpenJSON(filename string, hash string) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	uf := UndoFile{}
+	err = json.Unmarshal(b, &uf)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if uf.Hash != hash {
+		return fmt.Errorf("textbuf.Undo OpenJSON: undo file: %v is for different text", filename)
+	}
+	if uf.Pos < 0 || uf.Pos > len(uf.Stack) {
+		uf.Pos = len(uf.Stack)
+	}
+	un.Mu.Lock()
+	un.Stack = uf.Stack
+	un.UndoStack = nil
+	un.Pos = uf.Pos
+	un.Group = uf.Group
+	un.Grouping = 0
+	un.Mu.Unlock()
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func undoTestEdits(un *Undo, n int) {
+	for i := 0; i < n; i++ {
+		un.Save(&Edit{Reg: NewRegion(i, 0, i, 3), Text: [][]rune{[]rune("abc")}})
+		un.NewGroup()
+	}
+}
+
+func TestUndoJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undofile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "test.undo")
+	hash := UndoHash([]byte("some text\n"))
+
+	un := &Undo{}
+	undoTestEdits(un, 3)
+	un.UndoPop()
+	if err := un.SaveJSON(fn, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	ou := &Undo{}
+	if err := ou.OpenJSON(fn, hash); err != nil {
+		t.Fatal(err)
+	}
+	if ou.Pos != un.Pos || ou.Group != un.Group || len(ou.Stack) != len(un.Stack) {
+		t.Fatalf("OpenJSON: got pos %v group %v len %v, expected %v %v %v", ou.Pos, ou.Group, len(ou.Stack), un.Pos, un.Group, len(un.Stack))
+	}
+	for i, tbe := range ou.Stack {
+		if tbe.Reg.Start != un.Stack[i].Reg.Start || tbe.Reg.End != un.Stack[i].Reg.End || tbe.Group != un.Stack[i].Group || string(tbe.ToBytes()) != string(un.Stack[i].ToBytes()) {
+			t.Errorf("OpenJSON: edit %v = %+v, expected %+v", i, tbe, un.Stack[i])
+		}
+	}
+
+	// undo stack for other text is rejected, leaving the current one
+	ou = &Undo{}
+	undoTestEdits(ou, 1)
+	if err := ou.OpenJSON(fn, UndoHash([]byte("other text\n"))); err == nil {
+		t.Errorf("OpenJSON: expected error for hash mismatch")
+	}
+	if len(ou.Stack) != 1 || ou.Pos != 1 {
+		t.Errorf("OpenJSON: hash mismatch changed stack: len %v pos %v", len(ou.Stack), ou.Pos)
+	}
+
+	// only the most recent UndoSaveMax edits are saved
+	svmax := UndoSaveMax
+	UndoSaveMax = 2
+	defer func() { UndoSaveMax = svmax }()
+	if err := un.SaveJSON(fn, hash); err != nil {
+		t.Fatal(err)
+	}
+	ou = &Undo{}
+	if err := ou.OpenJSON(fn, hash); err != nil {
+		t.Fatal(err)
+	}
+	if len(ou.Stack) != 2 || ou.Pos != 1 || ou.Stack[0].Reg.Start.Ln != 1 {
+		t.Errorf("SaveJSON UndoSaveMax: got len %v pos %v first line %v, expected 2 1 1", len(ou.Stack), ou.Pos, ou.Stack[0].Reg.Start.Ln)
+	}
+}
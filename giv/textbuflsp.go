@@ -0,0 +1,453 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/goki/gi/giv/lsp"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ints"
+	"github.com/goki/pi/complete"
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/token"
+)
+
+// LSPChangeMSec is the delay in milliseconds after the last change to a
+// TextBuf before its new text is sent to the language server
+var LSPChangeMSec = 300
+
+// LSPLookupLines is the number of lines shown for a lookup result when the
+// server only gives the location of the name
+var LSPLookupLines = 20
+
+// LSPDiagColors are the line colors used for diagnostics of each severity
+var LSPDiagColors = map[lsp.DiagnosticSeverity]string{
+	lsp.SeverityError:       "#FF8080",
+	lsp.SeverityWarning:     "#FFD060",
+	lsp.SeverityInformation: "#80C0FF",
+	lsp.SeverityHint:        "#80C0FF",
+}
+
+// LSPDiagIcons are the line icons used for diagnostics of each severity
+var LSPDiagIcons = map[lsp.DiagnosticSeverity]string{
+	lsp.SeverityError:       "close",
+	lsp.SeverityWarning:     "info",
+	lsp.SeverityInformation: "info",
+	lsp.SeverityHint:        "info",
+}
+
+// LSPSemTokens maps the standard semantic token types of the language server
+// protocol onto the tokens used for syntax highlighting
+var LSPSemTokens = map[string]token.Tokens{
+	"namespace":     token.NameNamespace,
+	"type":          token.NameType,
+	"class":         token.NameClass,
+	"enum":          token.NameEnum,
+	"interface":     token.NameInterface,
+	"struct":        token.NameStruct,
+	"typeParameter": token.NameTypeParam,
+	"parameter":     token.NameVarParam,
+	"variable":      token.NameVar,
+	"property":      token.NameProperty,
+	"enumMember":    token.NameEnumMember,
+	"event":         token.NameEvent,
+	"function":      token.NameFunction,
+	"method":        token.NameMethod,
+	"macro":         token.NameFunctionMagic,
+	"keyword":       token.Keyword,
+	"modifier":      token.Keyword,
+	"comment":       token.Comment,
+	"string":        token.LitStr,
+	"number":        token.LitNum,
+	"regexp":        token.LitStrRegex,
+	"operator":      token.Operator,
+	"decorator":     token.NameDecorator,
+	"label":         token.NameLabel,
+}
+
+// LSPCompletionIcons maps completion item kinds onto icon names
+var LSPCompletionIcons = map[lsp.CompletionItemKind]string{
+	lsp.CompletionMethod:      "method",
+	lsp.CompletionFunction:    "function",
+	lsp.CompletionConstructor: "function",
+	lsp.CompletionField:       "field",
+	lsp.CompletionProperty:    "field",
+	lsp.CompletionVariable:    "var",
+	lsp.CompletionClass:       "structure",
+	lsp.CompletionStruct:      "structure",
+	lsp.CompletionInterface:   "type",
+	lsp.CompletionEnum:        "type",
+	lsp.CompletionConstant:    "const",
+	lsp.CompletionEnumMember:  "const",
+	lsp.CompletionModule:      "package",
+}
+
+// TextBufLSP connects a TextBuf to a language server: the text is kept in
+// sync with the server, diagnostics are shown as line colors and icons,
+// semantic tokens are merged into the syntax highlighting, and completion,
+// hover info and lookup (go to definition) are provided by the server.
+// Use TextBuf.SetLSP to create.
+type TextBufLSP struct {
+	Client   *lsp.Client      `desc:"language server client -- can be shared by many buffers"`
+	Buf      *TextBuf         `desc:"the buffer"`
+	URI      string           `desc:"document URI of the buffer"`
+	LangID   string           `desc:"language identifier, e.g., go, python"`
+	Version  int              `desc:"version of the text last sent to the server"`
+	Diags    []lsp.Diagnostic `desc:"current diagnostics from the server"`
+	diagLns  []int            // lines with line colors / icons set for diagnostics
+	sentTime time.Time        // time the text was last sent to the server
+	sentTxt  []byte           // the text last sent to the server
+	chgTimer *time.Timer      // delay timer for sending changes
+	closed   bool             // DeleteLSP has been called
+	mu       sync.Mutex
+}
+
+// SetLSP connects the buffer to given language server client, with given
+// language identifier (e.g., go, python) -- opens the document on the
+// server, and sets the completer to use the server
+func (tb *TextBuf) SetLSP(cl *lsp.Client, langID string) *TextBufLSP {
+	tb.DeleteLSP()
+	tl := &TextBufLSP{Client: cl, Buf: tb, LangID: langID, Version: 1}
+	if tb.Filename != "" {
+		tl.URI = lsp.FileURI(string(tb.Filename))
+	} else {
+		tl.URI = "untitled:" + tb.Name()
+	}
+	tl.sentTime = time.Now()
+	tl.sentTxt = tb.LinesToBytesCopy()
+	err := cl.DidOpen(tl.URI, langID, tl.Version, string(tl.sentTxt), tl.SetDiags)
+	if err != nil {
+		log.Println(err)
+	}
+	tb.LSP = tl
+	if cl.HasCompletion() || cl.HasDefinition() {
+		tb.SetCompleter(tl, CompleteLSP, CompleteEditLSP, LookupLSP)
+	}
+	go tl.UpdateSemTags()
+	return tl
+}
+
+// DeleteLSP disconnects the buffer from the language server, if connected
+func (tb *TextBuf) DeleteLSP() {
+	tl := tb.LSP
+	if tl == nil {
+		return
+	}
+	tb.LSP = nil
+	tl.mu.Lock()
+	tl.closed = true
+	tl.Diags = nil
+	if tl.chgTimer != nil {
+		tl.chgTimer.Stop()
+		tl.chgTimer = nil
+	}
+	tl.mu.Unlock()
+	tl.Client.DidClose(tl.URI)
+	tl.ShowDiags()
+	tb.MarkupMu.Lock()
+	tb.SemTags = nil
+	tb.MarkupMu.Unlock()
+	if tb.Complete != nil && tb.Complete.Context == tl {
+		tb.DeleteCompleter()
+	}
+}
+
+// Changed is called when the buffer has changed -- sends the new text to
+// the server after LSPChangeMSec delay, and then updates semantic tags
+func (tl *TextBufLSP) Changed() {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	if tl.chgTimer != nil {
+		tl.chgTimer.Stop()
+	}
+	tl.chgTimer = time.AfterFunc(time.Duration(LSPChangeMSec)*time.Millisecond, func() {
+		tl.mu.Lock()
+		tl.chgTimer = nil
+		tl.mu.Unlock()
+		tl.SendText()
+		tl.UpdateSemTags()
+	})
+}
+
+// SendText sends the current full text of the buffer to the server
+func (tl *TextBufLSP) SendText() {
+	txt := tl.Buf.LinesToBytesCopy()
+	tl.mu.Lock()
+	tl.Version++
+	vers := tl.Version
+	tl.sentTime = time.Now()
+	tl.sentTxt = txt
+	tl.mu.Unlock()
+	tl.Client.DidChange(tl.URI, vers, string(txt))
+}
+
+// Saved is called when the buffer has been saved -- sends any pending
+// changes and tells the server
+func (tl *TextBufLSP) Saved() {
+	tl.mu.Lock()
+	pend := tl.chgTimer != nil && tl.chgTimer.Stop()
+	tl.chgTimer = nil
+	tl.mu.Unlock()
+	if pend {
+		tl.SendText()
+	}
+	tl.Client.DidSave(tl.URI)
+	if pend {
+		go tl.UpdateSemTags()
+	}
+}
+
+// Pos returns the server position for given buffer position
+func (tl *TextBufLSP) Pos(pos lex.Pos) lsp.Position {
+	tb := tl.Buf
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	lp := lsp.Position{Line: pos.Ln, Character: pos.Ch}
+	if pos.Ln >= 0 && pos.Ln < len(tb.Lines) {
+		lp.Character = lsp.UTF16Col(tb.Lines[pos.Ln], pos.Ch)
+	}
+	return lp
+}
+
+// SetDiags sets the current diagnostics -- called by the client when the
+// server publishes diagnostics, in the goroutine reading from the server,
+// so they are shown by ShowDiags on the main thread
+func (tl *TextBufLSP) SetDiags(diags []lsp.Diagnostic) {
+	tl.mu.Lock()
+	if tl.closed {
+		tl.mu.Unlock()
+		return
+	}
+	tl.Diags = diags
+	tl.mu.Unlock()
+	oswin.TheApp.GoRunOnMain(tl.ShowDiags)
+}
+
+// ShowDiags shows the current diagnostics as line colors and icons, in
+// place of those shown before -- must be called on the main thread
+func (tl *TextBufLSP) ShowDiags() {
+	tb := tl.Buf
+	tl.mu.Lock()
+	olns := tl.diagLns
+	diags := tl.Diags
+	// most severe (lowest value) diagnostic on each line determines its color
+	lnsev := make(map[int]lsp.DiagnosticSeverity)
+	for _, dg := range diags {
+		sev := dg.Severity
+		if sev == 0 {
+			sev = lsp.SeverityError
+		}
+		ln := dg.Range.Start.Line
+		if cur, has := lnsev[ln]; !has || sev < cur {
+			lnsev[ln] = sev
+		}
+	}
+	tl.diagLns = make([]int, 0, len(lnsev))
+	for ln := range lnsev {
+		tl.diagLns = append(tl.diagLns, ln)
+	}
+	tl.mu.Unlock()
+	for _, ln := range olns {
+		tb.DeleteLineColor(ln)
+		tb.DeleteLineIcon(ln)
+	}
+	for ln, sev := range lnsev {
+		tb.SetLineColor(ln, LSPDiagColors[sev])
+		tb.SetLineIcon(ln, LSPDiagIcons[sev])
+	}
+	tb.RefreshViews()
+}
+
+// LineDiags returns the diagnostics for given line
+func (tl *TextBufLSP) LineDiags(ln int) []lsp.Diagnostic {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	var dgs []lsp.Diagnostic
+	for _, dg := range tl.Diags {
+		if dg.Range.Start.Line <= ln && ln <= ints.MaxInt(dg.Range.Start.Line, dg.Range.End.Line) {
+			dgs = append(dgs, dg)
+		}
+	}
+	return dgs
+}
+
+// HoverText returns the text to show as a tooltip for given position:
+// any diagnostics for the line, followed by the hover info from the server
+func (tl *TextBufLSP) HoverText(pos lex.Pos) string {
+	var strs []string
+	for _, dg := range tl.LineDiags(pos.Ln) {
+		strs = append(strs, dg.String())
+	}
+	if tl.Client.HasHover() {
+		hv, err := tl.Client.Hover(tl.URI, tl.Pos(pos))
+		if err == nil && hv != "" {
+			strs = append(strs, hv)
+		}
+	}
+	txt := strings.TrimSpace(strings.Join(strs, "\n\n"))
+	if len(txt) > 2000 {
+		txt = txt[:2000] + "..."
+	}
+	return txt
+}
+
+// UpdateSemTags gets semantic tokens from the server, if supported, and
+// sets them as the SemTags of the buffer, updating the markup.  The token
+// columns are for the text last sent to the server, so the tags are
+// computed from that text, and adjusted for any edits made since then.
+func (tl *TextBufLSP) UpdateSemTags() {
+	if !tl.Client.HasSemanticTokens() {
+		return
+	}
+	tl.mu.Lock()
+	sent := tl.sentTime
+	vers := tl.Version
+	txt := tl.sentTxt
+	tl.mu.Unlock()
+	toks, err := tl.Client.SemanticTokens(tl.URI)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	tl.mu.Lock()
+	stale := tl.closed || tl.Version != vers // another update follows sending newer text
+	tl.mu.Unlock()
+	if stale {
+		return
+	}
+	slns := bytes.Split(txt, []byte("\n"))
+	tb := tl.Buf
+	tb.LinesMu.Lock()
+	tb.MarkupMu.Lock()
+	stags := make([]lex.Line, tb.NLines)
+	for i := range toks {
+		tk := &toks[i]
+		if tk.Line >= tb.NLines || tk.Line >= len(slns) {
+			continue
+		}
+		tok, has := LSPSemTokens[tk.Type]
+		if !has {
+			continue
+		}
+		switch {
+		case tk.HasMod("defaultLibrary"):
+			tok = token.NameBuiltin
+		case tk.Type == "variable" && tk.HasMod("readonly"):
+			tok = token.NameConstant
+		}
+		line := bytes.Runes(slns[tk.Line])
+		st := lsp.RuneCol(line, tk.Start)
+		ed := lsp.RuneCol(line, tk.Start+tk.Length)
+		// tags are as of when the text was sent, so later edits adjust them
+		lx := stags[tk.Line].AddLex(token.KeyToken{Tok: tok}, st, ed)
+		lx.Time.SetTime(sent)
+	}
+	tb.SemTags = stags
+	if tb.Hi.HasHi() {
+		maxln := ints.MinInt(len(tb.Markup), tb.NLines)
+		for ln := 0; ln < maxln; ln++ {
+			tb.Markup[ln] = tb.Hi.MarkupLine(tb.Lines[ln], tb.HiTags[ln], tb.MergeSemTags(ln, tb.AdjustedTags(ln)))
+		}
+	}
+	tb.MarkupMu.Unlock()
+	tb.LinesMu.Unlock()
+	tb.TextBufSig.Emit(tb.This(), int64(TextBufMarkUpdt), tb.Txt)
+}
+
+// lspSeed returns the identifier at the end of given text
+func lspSeed(text string) string {
+	rs := []rune(text)
+	st := len(rs)
+	for st > 0 && isLSPIdentRune(rs[st-1]) {
+		st--
+	}
+	return string(rs[st:])
+}
+
+// isLSPIdentRune returns true if rune can be part of an identifier
+func isLSPIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// CompleteLSP gets completions from the language server -- the data must
+// be the *TextBufLSP of the buffer
+func CompleteLSP(data interface{}, text string, posLn, posCh int) (md complete.Matches) {
+	tl, ok := data.(*TextBufLSP)
+	if !ok || tl == nil || !tl.Client.HasCompletion() {
+		return md
+	}
+	tl.mu.Lock()
+	pend := tl.chgTimer != nil && tl.chgTimer.Stop()
+	tl.chgTimer = nil
+	tl.mu.Unlock()
+	if pend { // server must have current text
+		tl.SendText()
+		go tl.UpdateSemTags()
+	}
+	items, err := tl.Client.Completion(tl.URI, tl.Pos(lex.Pos{Ln: posLn, Ch: posCh}))
+	if err != nil {
+		return md
+	}
+	md.Seed = lspSeed(text)
+	for _, it := range items {
+		c := complete.Completion{Text: it.Label, Label: it.Label, Desc: it.Detail}
+		switch {
+		case it.TextEdit != nil:
+			c.Text = it.TextEdit.NewText
+		case it.InsertText != "":
+			c.Text = it.InsertText
+		}
+		c.Icon = LSPCompletionIcons[it.Kind]
+		md.Matches = append(md.Matches, c)
+	}
+	if md.Seed != "" {
+		md.Matches = complete.MatchSeedCompletion(md.Matches, md.Seed)
+	}
+	return md
+}
+
+// CompleteEditLSP uses the selected completion to edit the text, replacing
+// the rest of the identifier after the cursor
+func CompleteEditLSP(data interface{}, text string, cursorPos int, comp complete.Completion, seed string) (ed complete.Edit) {
+	ed.NewText = comp.Text
+	rs := []rune(text)
+	for i := cursorPos; i < len(rs) && isLSPIdentRune(rs[i]); i++ {
+		ed.ForwardDelete++
+	}
+	return ed
+}
+
+// LookupLSP looks up the definition of the symbol at given position using
+// the language server, and shows it in a dialog -- the data must be the
+// *TextBufLSP of the buffer
+func LookupLSP(data interface{}, text string, posLn, posCh int) (ld complete.Lookup) {
+	tl, ok := data.(*TextBufLSP)
+	if !ok || tl == nil || !tl.Client.HasDefinition() {
+		return ld
+	}
+	locs, err := tl.Client.Definition(tl.URI, tl.Pos(lex.Pos{Ln: posLn, Ch: posCh}))
+	if err != nil || len(locs) == 0 {
+		return ld
+	}
+	loc := locs[0]
+	fname := lsp.URIPath(loc.URI)
+	edln := loc.Range.End.Line
+	if edln <= loc.Range.Start.Line { // typically just the name -- show some context
+		edln = loc.Range.Start.Line + LSPLookupLines
+	}
+	ld.SetFile(fname, loc.Range.Start.Line, edln)
+	txt := textbuf.FileRegionBytes(ld.Filename, ld.StLine, ld.EdLine, true, 10) // comments, 10 lines back max
+	prmpt := fmt.Sprintf("%v [%d:%d]", ld.Filename, ld.StLine, ld.EdLine)
+	TextViewDialog(nil, txt, DlgOpts{Title: "Lookup: " + lspSeed(text), Prompt: prmpt, Filename: ld.Filename, LineNos: true, Data: prmpt})
+	return ld
+}
@@ -0,0 +1,346 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/lex"
+)
+
+// TextBufSessionSaveMSec is the number of milliseconds between checks for
+// changes to the buffers in a TextBufSession, which are then saved
+var TextBufSessionSaveMSec = 1000
+
+// TextBufSessionBuf is the saved state of one buffer in a TextBufSession
+type TextBufSessionBuf struct {
+	Filename   gi.FileName `desc:"file that the buffer has open"`
+	PosHistory []lex.Pos   `desc:"history of cursor positions -- the last one is the current cursor position"`
+	Changed    bool        `desc:"buffer has unsaved changes, which are saved in the session directory"`
+	Recover    string      `desc:"base name of the files in the session directory holding the unsaved text (.txt) and its undo history (.undo), if Changed"`
+}
+
+// TextBufSession keeps track of a set of open buffers, saving their cursor
+// positions and any unsaved changes (with their undo history) in a session
+// directory as they change, so they can be restored by Restore after a
+// crash.  Close removes the saved session, so an existing session on startup
+// means that the previous one did not exit cleanly -- see HasSaved.
+// Buffers without a Filename are not saved.
+type TextBufSession struct {
+	ki.Node
+	Dir      string                                       `desc:"directory where the session is saved, e.g., within oswin.TheApp.AppPrefsDir()"`
+	Bufs     []*TextBuf                                   `json:"-" xml:"-" desc:"the buffers in the session"`
+	OpenFunc func(filename gi.FileName) (*TextBuf, error) `json:"-" xml:"-" view:"-" desc:"function used by Restore to open each buffer, e.g., via FileNode.OpenBuf in a FileTree -- if nil, a new TextBuf is opened"`
+	mu       sync.Mutex
+	saveMu   sync.Mutex
+	loopWg   sync.WaitGroup
+	dirty    bool
+	closed   bool
+	nposHist map[*TextBuf]int
+	done     chan struct{}
+}
+
+var KiT_TextBufSession = kit.Types.AddType(&TextBufSession{}, nil)
+
+// NewTextBufSession returns a new session saved in given directory, which
+// is created if needed -- call Restore to restore any previous session that
+// did not exit cleanly before adding any buffers, and Close when done
+func NewTextBufSession(dir string) (*TextBufSession, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	ts := &TextBufSession{Dir: dir}
+	ts.InitName(ts, "textbuf-session")
+	ts.nposHist = make(map[*TextBuf]int)
+	ts.done = make(chan struct{})
+	ts.loopWg.Add(1)
+	go ts.saveLoop(ts.done)
+	return ts, nil
+}
+
+// SessionFilename returns the name of the file listing the buffers
+func (ts *TextBufSession) SessionFilename() string {
+	return filepath.Join(ts.Dir, "session.json")
+}
+
+// HasSaved returns true if there is a saved session in the directory, i.e.,
+// a previous session did not exit cleanly
+func (ts *TextBufSession) HasSaved() bool {
+	_, err := os.Stat(ts.SessionFilename())
+	return err == nil
+}
+
+// Add adds given buffer to the session, if not already in it -- it is
+// removed when it is closed
+func (ts *TextBufSession) Add(tb *TextBuf) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, b := range ts.Bufs {
+		if b == tb {
+			return
+		}
+	}
+	ts.Bufs = append(ts.Bufs, tb)
+	ts.dirty = true
+	tb.TextBufSig.Connect(ts.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tsv := recv.Embed(KiT_TextBufSession).(*TextBufSession)
+		switch TextBufSignals(sig) {
+		case TextBufClosed:
+			tsv.Remove(send.Embed(KiT_TextBuf).(*TextBuf))
+		case TextBufNew, TextBufInsert, TextBufDelete, TextBufDone:
+			tsv.mu.Lock()
+			tsv.dirty = true
+			tsv.mu.Unlock()
+		}
+	})
+}
+
+// Remove removes given buffer from the session
+func (ts *TextBufSession) Remove(tb *TextBuf) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for i, b := range ts.Bufs {
+		if b == tb {
+			ts.Bufs = append(ts.Bufs[:i], ts.Bufs[i+1:]...)
+			tb.TextBufSig.Disconnect(ts.This())
+			delete(ts.nposHist, tb)
+			ts.dirty = true
+			return
+		}
+	}
+}
+
+// TextBufSessionRecoverPrefix is the prefix of the base names of the files
+// in the session directory holding unsaved changes -- only files with this
+// prefix are ever removed from the directory
+var TextBufSessionRecoverPrefix = "recover-"
+
+// RecoverName returns the base name of the files in the session directory
+// holding the unsaved changes for given file
+func (ts *TextBufSession) RecoverName(filename gi.FileName) string {
+	return TextBufSessionRecoverPrefix + textbuf.UndoHash([]byte(filename))[:16]
+}
+
+// Save saves the session: the cursor positions of each buffer, and the
+// text and undo history of those with unsaved changes.  The session file is
+// replaced atomically, so a crash while saving leaves the prior session.
+// Called automatically after any changes -- does nothing after Close.
+func (ts *TextBufSession) Save() error {
+	ts.saveMu.Lock()
+	defer ts.saveMu.Unlock()
+	ts.mu.Lock()
+	if ts.closed {
+		ts.mu.Unlock()
+		return nil
+	}
+	bufs := append([]*TextBuf{}, ts.Bufs...)
+	ts.dirty = false
+	hists := make([][]lex.Pos, len(bufs))
+	for i, tb := range bufs {
+		tb.PosHistMu.Lock()
+		hists[i] = append(hists[i], tb.PosHistory...)
+		tb.PosHistMu.Unlock()
+		ts.nposHist[tb] = len(hists[i])
+	}
+	ts.mu.Unlock()
+	sbs := make([]TextBufSessionBuf, 0, len(bufs))
+	recs := make(map[string]bool)
+	for i, tb := range bufs {
+		if tb.Filename == "" {
+			continue
+		}
+		sb := TextBufSessionBuf{Filename: tb.Filename, Changed: tb.IsChanged(), PosHistory: hists[i]}
+		if sb.Changed {
+			sb.Recover = ts.RecoverName(tb.Filename)
+			recs[sb.Recover] = true
+			txt := tb.LinesToBytesCopy()
+			rfn := filepath.Join(ts.Dir, sb.Recover)
+			err := ioutil.WriteFile(rfn+".txt", txt, 0644)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+			err = tb.Undos.SaveJSON(rfn+".undo", textbuf.UndoHash(txt))
+			if err != nil { // already logged
+				return err
+			}
+		}
+		sbs = append(sbs, sb)
+	}
+	b, err := json.MarshalIndent(sbs, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	sfn := ts.SessionFilename()
+	err = ioutil.WriteFile(sfn+".tmp", b, 0644)
+	if err == nil {
+		err = os.Rename(sfn+".tmp", sfn)
+	}
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	ts.removeRecover(recs)
+	return nil
+}
+
+// removeRecover removes the files for unsaved changes (with the
+// TextBufSessionRecoverPrefix) other than those in keep, e.g., for buffers
+// that have since been saved or closed
+func (ts *TextBufSession) removeRecover(keep map[string]bool) {
+	for _, ext := range []string{".txt", ".undo"} {
+		fns, _ := filepath.Glob(filepath.Join(ts.Dir, TextBufSessionRecoverPrefix+"*"+ext))
+		for _, fn := range fns {
+			nm := filepath.Base(fn)
+			if !keep[nm[:len(nm)-len(ext)]] {
+				os.Remove(fn)
+			}
+		}
+	}
+}
+
+// Restore restores the buffers of a saved session, opening each file with
+// OpenFunc, restoring its cursor positions, and restoring any unsaved
+// changes, which can be undone back to the file on disk, along with the
+// prior undo history.  The buffers are added to the session and returned --
+// cursor positions are applied when they are set in a TextView.
+func (ts *TextBufSession) Restore() ([]*TextBuf, error) {
+	b, err := ioutil.ReadFile(ts.SessionFilename())
+	if err != nil {
+		return nil, err
+	}
+	var sbs []TextBufSessionBuf
+	err = json.Unmarshal(b, &sbs)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	var bufs []*TextBuf
+	for _, sb := range sbs {
+		var tb *TextBuf
+		if ts.OpenFunc != nil {
+			tb, err = ts.OpenFunc(sb.Filename)
+		} else {
+			tb = &TextBuf{}
+			tb.InitName(tb, filepath.Base(string(sb.Filename)))
+			err = tb.Open(sb.Filename)
+		}
+		if err != nil || tb == nil {
+			continue
+		}
+		if sb.Changed {
+			ts.recover(tb, sb.Recover)
+		}
+		if len(sb.PosHistory) > 0 {
+			tb.PosHistMu.Lock()
+			tb.PosHistory = sb.PosHistory
+			tb.PosHistMu.Unlock()
+		}
+		ts.Add(tb)
+		bufs = append(bufs, tb)
+	}
+	return bufs, nil
+}
+
+// recover restores the unsaved changes to given buffer from the files with
+// given base name
+func (ts *TextBufSession) recover(tb *TextBuf, rnm string) {
+	rfn := filepath.Join(ts.Dir, rnm)
+	txt, err := ioutil.ReadFile(rfn + ".txt")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	ob := &TextBuf{}
+	ob.InitName(ob, "recover-tmp")
+	ob.Txt = txt
+	ob.BytesToLines()
+	diffs := tb.DiffBufs(ob)
+	if len(diffs) == 0 {
+		return
+	}
+	tb.PatchFromBuf(ob, diffs, true)
+	tb.SetChanged()
+	err = tb.Undos.OpenJSON(rfn+".undo", textbuf.UndoHash(tb.LinesToBytesCopy()))
+	if err != nil {
+		log.Println(err) // the text is restored, just without its undo history
+	}
+}
+
+// saveLoop saves the session whenever the buffers have changed, until closed
+func (ts *TextBufSession) saveLoop(done chan struct{}) {
+	defer ts.loopWg.Done()
+	tick := time.NewTicker(time.Duration(TextBufSessionSaveMSec) * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-tick.C:
+			if ts.NeedsSave() {
+				ts.Save()
+			}
+		}
+	}
+}
+
+// NeedsSave returns true if the buffers have been edited, added or removed,
+// or any cursor positions have been saved, since the last Save
+func (ts *TextBufSession) NeedsSave() bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.dirty {
+		return true
+	}
+	for _, tb := range ts.Bufs {
+		tb.PosHistMu.Lock()
+		nh := len(tb.PosHistory)
+		tb.PosHistMu.Unlock()
+		if ts.nposHist[tb] != nh {
+			return true
+		}
+	}
+	return false
+}
+
+// Close ends the session on a clean exit: it stops saving, disconnects from
+// the buffers, and removes the saved session, so it will not be restored.
+// It waits for any Save in progress, so that it cannot rewrite the session
+// after it has been removed.
+func (ts *TextBufSession) Close() {
+	ts.mu.Lock()
+	if ts.closed {
+		ts.mu.Unlock()
+		return
+	}
+	ts.closed = true
+	if ts.done != nil {
+		close(ts.done)
+		ts.done = nil
+	}
+	for _, tb := range ts.Bufs {
+		tb.TextBufSig.Disconnect(ts.This())
+	}
+	ts.Bufs = nil
+	ts.mu.Unlock()
+	ts.loopWg.Wait()
+	ts.saveMu.Lock()
+	defer ts.saveMu.Unlock()
+	os.Remove(ts.SessionFilename())
+	ts.removeRecover(nil)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/pi/lex"
+)
+
+// sessionTestOpen opens a buffer for given file, without the file type
+// and spelling setup of TextBuf.Open, which needs the app -- edits do not
+// check for the file changing on disk, which prompts the user
+func sessionTestOpen(filename gi.FileName) (*TextBuf, error) {
+	txt, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return nil, err
+	}
+	tb := &TextBuf{}
+	tb.InitName(tb, filepath.Base(string(filename)))
+	tb.Filename = filename
+	tb.SetFlag(int(TextBufFileModOk))
+	tb.SetText(txt)
+	return tb, nil
+}
+
+func TestTextBufSessionRestore(t *testing.T) {
+	textTestInit()
+	dir, err := ioutil.TempDir("", "textbufsession")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := gi.FileName(filepath.Join(dir, "test.txt"))
+	orig := "one\ntwo\n"
+	if err := ioutil.WriteFile(string(fn), []byte(orig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sdir := filepath.Join(dir, "session")
+
+	ts, err := NewTextBufSession(sdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tb, err := sessionTestOpen(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.Add(tb)
+	tb.InsertText(lex.Pos{Ln: 1, Ch: 0}, []byte("new "), EditSignal)
+	edtxt := string(tb.LinesToBytesCopy())
+	if err := ts.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if !ts.HasSaved() {
+		t.Fatalf("HasSaved false after Save")
+	}
+
+	// a new session in the same directory restores the unsaved edit,
+	// which can be undone back to the file
+	rs, err := NewTextBufSession(sdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.OpenFunc = sessionTestOpen
+	bufs, err := rs.Restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bufs) != 1 {
+		t.Fatalf("Restore: got %v bufs, expected 1", len(bufs))
+	}
+	rb := bufs[0]
+	if txt := string(rb.LinesToBytesCopy()); txt != edtxt {
+		t.Errorf("Restore: got text %q, expected %q", txt, edtxt)
+	}
+	if !rb.IsChanged() {
+		t.Errorf("Restore: buffer not marked as changed")
+	}
+	rb.Undo()
+	if txt := string(rb.LinesToBytesCopy()); txt != orig {
+		t.Errorf("Restore: after Undo got text %q, expected file text %q", txt, orig)
+	}
+
+	ts.Close()
+	rs.Close()
+	if rs.HasSaved() {
+		t.Errorf("HasSaved true after Close")
+	}
+	if fns, _ := filepath.Glob(filepath.Join(sdir, TextBufSessionRecoverPrefix+"*")); len(fns) > 0 {
+		t.Errorf("Close left recover files: %v", fns)
+	}
+	if err := ts.Save(); err != nil || ts.HasSaved() {
+		t.Errorf("Save after Close rewrote the session")
+	}
+}
@@ -65,6 +65,9 @@ type TextView struct {
 	Highlights             []textbuf.Region          `json:"-" xml:"-" desc:"highlighted regions, e.g., for search results"`
 	Scopelights            []textbuf.Region          `json:"-" xml:"-" desc:"highlighted regions, specific to scope markers"`
 	SelectMode             bool                      `json:"-" xml:"-" desc:"if true, select text as cursor moves"`
+	Folds                  []TextFold                `json:"-" xml:"-" desc:"folded regions of lines, which are hidden from the layout -- the text in the Buf is unaffected"`
+	Cursors                []TextCursor              `json:"-" xml:"-" desc:"additional cursors beyond the main CursorPos, for multi-cursor editing -- typing, deleting and pasting are applied at each cursor"`
+	Minimap                *TextViewMinimap          `json:"-" xml:"-" desc:"overview ruler for this view, if any -- see AddNewTextViewMinimap"`
 	ForceComplete          bool                      `json:"-" xml:"-" desc:"if true, complete regardless of any disqualifying reasons"`
 	ISearch                ISearch                   `json:"-" xml:"-" desc:"interactive search data"`
 	QReplace               QReplace                  `json:"-" xml:"-" desc:"query replace data"`
@@ -80,6 +83,13 @@ type TextView struct {
 	lastRecenter           int
 	lastAutoInsert         rune
 	lastFilename           gi.FileName
+	hidden                 []bool
+	foldEnds               []int
+	foldsMu                sync.Mutex
+	multiRec               bool
+	multiEdits             []*textbuf.Edit
+	colSel                 bool
+	colSelStart            lex.Pos
 }
 
 var KiT_TextView = kit.Types.AddType(&TextView{}, TextViewProps)
@@ -304,6 +314,10 @@ func (tv *TextView) Clear() {
 // ResetState resets all the random state variables, when opening a new buffer etc
 func (tv *TextView) ResetState() {
 	tv.SelectReset()
+	tv.Cursors = nil
+	tv.Folds = nil
+	tv.hidden = nil
+	tv.foldsChanged()
 	tv.Highlights = nil
 	tv.ISearch.On = false
 	tv.QReplace.On = false
@@ -364,6 +378,7 @@ func (tv *TextView) LinesInserted(tbe *textbuf.Edit) {
 	tv.Offs = nof
 
 	tv.NLines += nsz
+	tv.FoldsEdited(tbe)
 
 	tv.LayoutLines(tbe.Reg.Start.Ln, tbe.Reg.End.Ln, false)
 	tv.RenderAllLines()
@@ -379,6 +394,7 @@ func (tv *TextView) LinesDeleted(tbe *textbuf.Edit) {
 	tv.Offs = append(tv.Offs[:stln], tv.Offs[edln:]...)
 
 	tv.NLines -= dsz
+	tv.FoldsEdited(tbe)
 
 	tv.LayoutLines(tbe.Reg.Start.Ln, tbe.Reg.Start.Ln, true)
 	tv.RenderAllLines()
@@ -387,6 +403,13 @@ func (tv *TextView) LinesDeleted(tbe *textbuf.Edit) {
 // TextViewBufSigRecv receives a signal from the buffer and updates view accordingly
 func TextViewBufSigRecv(rvwki ki.Ki, sbufki ki.Ki, sig int64, data interface{}) {
 	tv := rvwki.Embed(KiT_TextView).(*TextView)
+	if tv.multiRec && (sig == int64(TextBufInsert) || sig == int64(TextBufDelete)) {
+		tv.multiEdits = append(tv.multiEdits, data.(*textbuf.Edit))
+	}
+	switch TextBufSignals(sig) {
+	case TextBufNew, TextBufInsert, TextBufDelete, TextBufMarkUpdt:
+		tv.foldsChanged()
+	}
 	if !tv.This().(gi.Node2D).IsVisible() {
 		return
 	}
@@ -523,6 +546,7 @@ func (tv *TextView) LayoutAllLines(inLayout bool) bool {
 	// fmt.Printf("layout all: %v\n", tv.Nm)
 
 	tv.NLines = tv.Buf.NumLines()
+	tv.updateHidden()
 	nln := tv.NLines
 	if cap(tv.Renders) >= nln {
 		tv.Renders = tv.Renders[:nln]
@@ -555,6 +579,9 @@ func (tv *TextView) LayoutAllLines(inLayout bool) bool {
 		}
 		tv.Offs[ln] = off
 		lsz := mat32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+		if tv.LineHidden(ln) {
+			lsz = 0
+		}
 		off += lsz
 		mxwd = mat32.Max(mxwd, tv.Renders[ln].Size.X)
 	}
@@ -663,6 +690,9 @@ func (tv *TextView) LayoutLines(st, ed int, isDel bool) bool {
 		for ln := ofst; ln < tv.NLines; ln++ {
 			tv.Offs[ln] = off
 			lsz := mat32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+			if tv.LineHidden(ln) {
+				lsz = 0
+			}
 			off += lsz
 		}
 		extraHalf := tv.LineHeight * 0.5 * float32(tv.VisSize.Y)
@@ -722,6 +752,7 @@ func (tv *TextView) SetCursor(pos lex.Pos) {
 	cpln := tv.CursorPos.Ln
 	tv.ClearScopelights()
 	tv.CursorPos = tv.Buf.ValidPos(pos)
+	tv.UnfoldLine(tv.CursorPos.Ln)
 	if cpln != tv.CursorPos.Ln && tv.HasLineNos() { // update cursor position highlight
 		rs := tv.Render()
 		rs.PushBounds(tv.VpBBox)
@@ -959,6 +990,9 @@ func (tv *TextView) CursorDown(steps int) {
 		}
 		if !gotwrap {
 			pos.Ln++
+			for pos.Ln < tv.NLines-1 && tv.LineHidden(pos.Ln) {
+				pos.Ln++
+			}
 			if pos.Ln >= tv.NLines {
 				pos.Ln = tv.NLines - 1
 				break
@@ -1094,6 +1128,9 @@ func (tv *TextView) CursorUp(steps int) {
 		}
 		if !gotwrap {
 			pos.Ln--
+			for pos.Ln > 0 && tv.LineHidden(pos.Ln) {
+				pos.Ln--
+			}
 			if pos.Ln < 0 {
 				pos.Ln = 0
 				break
@@ -3239,6 +3276,9 @@ func (tv *TextView) RenderDepthBg(stln, edln int) {
 	nclrs := len(TextViewDepthColors)
 	lstdp := 0
 	for ln := stln; ln <= edln; ln++ {
+		if tv.LineHidden(ln) {
+			continue
+		}
 		lst := tv.CharStartPos(lex.Pos{Ln: ln}).Y // note: charstart pos includes descent
 		led := lst + math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
 		if int(math32.Ceil(led)) < tv.VpBBox.Min.Y {
@@ -3478,6 +3518,7 @@ func (tv *TextView) RenderAllLines() {
 	tv.PopBounds()
 	tv.Viewport.This().(gi.Viewport).VpUploadRegion(tv.VpBBox, tv.WinBBox)
 	tv.RenderScrolls()
+	tv.Minimap.RenderMinimap()
 	tv.TopUpdateEnd(wupdt)
 }
 
@@ -3519,6 +3560,9 @@ func (tv *TextView) RenderAllLinesInBounds() {
 	if tv.HasLineNos() {
 		tv.RenderLineNosBoxAll()
 		for ln := stln; ln <= edln; ln++ {
+			if tv.LineHidden(ln) {
+				continue
+			}
 			tv.RenderLineNo(ln, false, false) // don't re-render std fill boxes, no separate vp upload
 		}
 	}
@@ -3527,6 +3571,7 @@ func (tv *TextView) RenderAllLinesInBounds() {
 	tv.RenderHighlights(stln, edln)
 	tv.RenderScopelights(stln, edln)
 	tv.RenderSelect()
+	tv.RenderCursors()
 	if tv.HasLineNos() {
 		tbb := tv.VpBBox
 		tbb.Min.X += int(tv.LineNoOff)
@@ -3535,6 +3580,9 @@ func (tv *TextView) RenderAllLinesInBounds() {
 		rs.Lock()
 	}
 	for ln := stln; ln <= edln; ln++ {
+		if tv.LineHidden(ln) {
+			continue
+		}
 		lst := pos.Y + tv.Offs[ln]
 		lp := pos
 		lp.Y = lst
@@ -3630,6 +3678,12 @@ func (tv *TextView) RenderLineNo(ln int, defFill bool, vpUpload bool) {
 	lfmt := fmt.Sprintf("%d", tv.LineNoDigs)
 	lfmt = "%" + lfmt + "d"
 	lnstr := fmt.Sprintf(lfmt, ln+1)
+	switch {
+	case tv.IsFolded(ln):
+		lnstr += " " + TextViewFoldedMarker
+	case tv.Foldable(ln):
+		lnstr += " " + TextViewFoldMarker
+	}
 	tv.LineNoRender.SetString(lnstr, &fst, &sty.UnContext, &sty.Text, true, 0, 0)
 	pos := mat32.Vec2{}
 	lst := tv.CharStartPos(lex.Pos{Ln: ln}).Y // note: charstart pos includes descent
@@ -3734,10 +3788,14 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 		tv.RenderHighlights(visSt, visEd)
 		tv.RenderScopelights(visSt, visEd)
 		tv.RenderSelect()
+		tv.RenderCursors()
 		tv.RenderLineNosBox(visSt, visEd)
 
 		if tv.HasLineNos() {
 			for ln := visSt; ln <= visEd; ln++ {
+				if tv.LineHidden(ln) {
+					continue
+				}
 				tv.RenderLineNo(ln, true, false)
 			}
 			tbb := tv.VpBBox
@@ -3747,6 +3805,9 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 			rs.Lock()
 		}
 		for ln := visSt; ln <= visEd; ln++ {
+			if tv.LineHidden(ln) {
+				continue
+			}
 			lst := pos.Y + tv.Offs[ln]
 			lp := pos
 			lp.Y = lst
@@ -3766,6 +3827,7 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 	}
 	tv.PopBounds()
 	tv.RenderScrolls()
+	tv.Minimap.RenderMinimap()
 	tv.TopUpdateEnd(wupdt)
 	return true
 }
@@ -3836,6 +3898,9 @@ func (tv *TextView) PixelToCursor(pt image.Point) lex.Pos {
 	} else {
 		got := false
 		for ln := stln; ln < tv.NLines; ln++ {
+			if tv.LineHidden(ln) {
+				continue
+			}
 			ls := tv.CharStartPos(lex.Pos{Ln: ln}).Y - yoff
 			es := ls
 			es += math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
@@ -4047,6 +4112,10 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		return
 	}
 
+	if tv.HasCursors() && tv.CursorsKeyInput(kt, kf) {
+		return
+	}
+
 	// cancelAll cancels search, completer, and..
 	cancelAll := func() {
 		tv.CancelComplete()
@@ -4190,6 +4259,26 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		cancelAll()
 		kt.SetProcessed()
 		tv.Lookup()
+	case gi.KeyFunFoldToggle:
+		cancelAll()
+		kt.SetProcessed()
+		tv.FoldCursorToggle()
+	case gi.KeyFunFoldAll:
+		cancelAll()
+		kt.SetProcessed()
+		tv.FoldAll()
+	case gi.KeyFunUnfoldAll:
+		cancelAll()
+		kt.SetProcessed()
+		tv.UnfoldAll()
+	case gi.KeyFunAddCursorNext:
+		cancelAll()
+		kt.SetProcessed()
+		tv.CursorAddNext()
+	case gi.KeyFunAddCursorLines:
+		cancelAll()
+		kt.SetProcessed()
+		tv.CursorsOnLines()
 	}
 	if tv.IsInactive() {
 		switch {
@@ -4544,6 +4633,12 @@ func (tv *TextView) MouseEvent(me *mouse.Event) {
 	case mouse.Left:
 		if me.Action == mouse.Press {
 			me.SetProcessed()
+			tv.ClearCursors()
+			if tv.HasLineNos() && pt.X < int(tv.LineNoOff) && tv.FoldToggle(newPos.Ln) { // gutter
+				return
+			}
+			tv.colSel = me.HasAnyModifier(key.Alt)
+			tv.colSelStart = newPos
 			if _, got := tv.OpenLinkAt(newPos); got {
 			} else {
 				tv.SetCursorFromMouse(pt, newPos, me.SelectMode())
@@ -4627,15 +4722,86 @@ func (tv *TextView) MouseDragEvent() {
 		me := d.(*mouse.DragEvent)
 		me.SetProcessed()
 		txf := recv.Embed(KiT_TextView).(*TextView)
+		pt := txf.PointToRelPos(me.Pos())
+		newPos := txf.PixelToCursor(pt)
+		if txf.colSel && me.HasAnyModifier(key.Alt) { // column selection
+			txf.SelectColumn(txf.colSelStart, newPos)
+			return
+		}
 		if !txf.SelectMode {
 			txf.SelectModeToggle()
 		}
-		pt := txf.PointToRelPos(me.Pos())
-		newPos := txf.PixelToCursor(pt)
 		txf.SetCursorFromMouse(pt, newPos, mouse.SelectOne)
 	})
 }
 
+// HoverTooltipEvent connects to HoverEvent and pops up a tooltip -- if the
+// buffer is connected to a language server, the tooltip shows the
+// diagnostics and hover info for the position under the mouse
+func (tv *TextView) HoverTooltipEvent() {
+	tv.ConnectEvent(oswin.MouseHoverEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.HoverEvent)
+		tvv := recv.Embed(KiT_TextView).(*TextView)
+		tvv.HoverTooltip(me)
+	})
+}
+
+// HoverTooltip pops up a tooltip for given hover event
+func (tv *TextView) HoverTooltip(me *mouse.HoverEvent) {
+	if tv.Buf == nil || tv.Buf.LSP == nil {
+		if tv.Tooltip != "" {
+			me.SetProcessed()
+			pos := tv.WinBBox.Max
+			pos.X -= 20
+			gi.PopupTooltip(tv.Tooltip, pos.X, pos.Y, tv.ViewportSafe(), tv.Nm)
+		}
+		return
+	}
+	me.SetProcessed()
+	pt := tv.PointToRelPos(me.Pos())
+	mpos := tv.PixelToCursor(pt)
+	if mpos.Ln >= tv.NLines {
+		return
+	}
+	tl := tv.Buf.LSP
+	win := tv.ParentWindow()
+	if win == nil {
+		return
+	}
+	ht := &textViewHoverTip{tv: tv, tip: tv.Tooltip, pos: me.Pos().Add(image.Point{10, 10})}
+	go func() { // server request can take a while
+		if tt := tl.HoverText(mpos); tt != "" {
+			ht.tip = tt
+		}
+		if ht.tip != "" {
+			win.SendCustomEvent(ht) // shown by HoverTipEvent in the window event loop
+		}
+	}()
+}
+
+// textViewHoverTip is a tooltip for a TextView obtained in the background,
+// sent to the window as a custom event to be shown by HoverTipEvent
+type textViewHoverTip struct {
+	tv  *TextView
+	tip string
+	pos image.Point
+}
+
+// HoverTipEvent connects to custom events for showing tooltips obtained in
+// the background by HoverTooltip
+func (tv *TextView) HoverTipEvent() {
+	tv.ConnectEvent(oswin.CustomEventType, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tvv := recv.Embed(KiT_TextView).(*TextView)
+		ce := d.(*oswin.CustomEvent)
+		ht, ok := ce.Data.(*textViewHoverTip)
+		if !ok || ht.tv != tvv {
+			return
+		}
+		ce.SetProcessed()
+		gi.PopupTooltip(ht.tip, ht.pos.X, ht.pos.Y, tvv.ViewportSafe(), tvv.Nm)
+	})
+}
+
 func (tv *TextView) MouseFocusEvent() {
 	tv.ConnectEvent(oswin.MouseFocusEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		txf := recv.Embed(KiT_TextView).(*TextView)
@@ -4656,6 +4822,7 @@ func (tv *TextView) MouseFocusEvent() {
 // TextViewEvents sets connections between mouse and key events and actions
 func (tv *TextView) TextViewEvents() {
 	tv.HoverTooltipEvent()
+	tv.HoverTipEvent()
 	tv.MouseMoveEvent()
 	tv.MouseDragEvent()
 	tv.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
@@ -4814,6 +4981,7 @@ func (tv *TextView) Render2D() {
 		}
 		tv.Render2DChildren()
 		tv.PopBounds()
+		tv.Minimap.RenderMinimap()
 	} else {
 		// fmt.Printf("tv render: %v  not vis stop cursor\n", tv.Nm)
 		tv.StopCursor()
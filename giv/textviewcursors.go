@@ -0,0 +1,502 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"sort"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki/indent"
+	"github.com/goki/ki/ints"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//    Multiple Cursors
+
+// TextCursor is an additional cursor in a TextView, beyond the main
+// CursorPos, with its own selection.  All edits typed into the view are
+// applied at each cursor, as one undo group.
+type TextCursor struct {
+	Pos      lex.Pos        `desc:"cursor position"`
+	SelStart lex.Pos        `desc:"starting point for selection -- will either be the start or end of selected region"`
+	Sel      textbuf.Region `desc:"selection region -- RegionNil if none"`
+}
+
+// HasCursors returns true if there are any additional cursors beyond the main one
+func (tv *TextView) HasCursors() bool {
+	return len(tv.Cursors) > 0
+}
+
+// ClearCursors removes all the additional cursors, leaving only the main one
+func (tv *TextView) ClearCursors() {
+	if len(tv.Cursors) == 0 {
+		return
+	}
+	tv.Cursors = nil
+	tv.colSel = false
+	tv.RenderAllLines()
+}
+
+// MainCursor returns the main cursor and selection as a TextCursor
+func (tv *TextView) MainCursor() TextCursor {
+	return TextCursor{Pos: tv.CursorPos, SelStart: tv.SelectStart, Sel: tv.SelectReg}
+}
+
+// AddCursor adds an additional cursor at given position, with given selection
+// (use textbuf.RegionNil for none) -- does nothing if there is already a
+// cursor at that position
+func (tv *TextView) AddCursor(pos lex.Pos, sel textbuf.Region) {
+	if tv.Buf == nil {
+		return
+	}
+	pos = tv.Buf.ValidPos(pos)
+	if pos == tv.CursorPos {
+		return
+	}
+	for _, c := range tv.Cursors {
+		if c.Pos == pos {
+			return
+		}
+	}
+	tv.Cursors = append(tv.Cursors, TextCursor{Pos: pos, SelStart: sel.Start, Sel: sel})
+	tv.RenderLines(pos.Ln, pos.Ln)
+}
+
+// setMainCursor makes the given cursor the main one, pushing the current
+// main cursor onto the additional cursors
+func (tv *TextView) setMainCursor(c TextCursor) {
+	tv.Cursors = append(tv.Cursors, tv.MainCursor())
+	tv.SelectStart = c.SelStart
+	tv.SelectReg = c.Sel
+	tv.PrevSelectReg = textbuf.RegionNil
+	tv.SetCursorShow(c.Pos)
+	tv.SetCursorCol(tv.CursorPos)
+}
+
+// CursorAddNext adds a cursor at the next occurrence of the selected text,
+// searching forward from the main cursor and wrapping around at the end,
+// and makes it the main cursor, selecting the occurrence.  If there is no
+// selection, the word at the cursor is selected first.  Only single-line
+// selections are supported.  Returns false if nothing was added.
+func (tv *TextView) CursorAddNext() bool {
+	if tv.Buf == nil {
+		return false
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	if !tv.HasSelection() {
+		if !tv.SelectWord() || !tv.HasSelection() {
+			return false
+		}
+		tv.SetCursorShow(tv.SelectReg.End)
+		tv.RenderSelectLines()
+		return true
+	}
+	if tv.SelectReg.Start.Ln != tv.SelectReg.End.Ln {
+		return false
+	}
+	find := tv.Selection().ToBytes()
+	_, matches := tv.Buf.Search(find, false, false)
+	if len(matches) == 0 {
+		return false
+	}
+	taken := func(reg textbuf.Region) bool {
+		if reg.Start == tv.SelectReg.Start {
+			return true
+		}
+		for _, c := range tv.Cursors {
+			if c.Sel.Start == reg.Start {
+				return true
+			}
+		}
+		return false
+	}
+	st := 0
+	for i, m := range matches {
+		if tv.SelectReg.End.IsLess(m.Reg.Start) || tv.SelectReg.End == m.Reg.Start {
+			st = i
+			break
+		}
+	}
+	nm := len(matches)
+	for i := 0; i < nm; i++ {
+		m := matches[(st+i)%nm]
+		if taken(m.Reg) {
+			continue
+		}
+		tv.setMainCursor(TextCursor{Pos: m.Reg.End, SelStart: m.Reg.Start, Sel: m.Reg})
+		tv.RenderAllLines()
+		return true
+	}
+	return false
+}
+
+// CursorsOnLines replaces a selection spanning multiple lines with a
+// cursor at the end of each line in the selection (the last line's cursor
+// is at the end of the selection, and is the main one).  Returns false if
+// the selection does not span multiple lines.
+func (tv *TextView) CursorsOnLines() bool {
+	if tv.Buf == nil || !tv.HasSelection() {
+		return false
+	}
+	reg := tv.SelectReg
+	if reg.Start.Ln == reg.End.Ln {
+		return false
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	tv.SelectReset()
+	for ln := reg.Start.Ln; ln < reg.End.Ln; ln++ {
+		tv.AddCursor(lex.Pos{Ln: ln, Ch: tv.Buf.LineLen(ln)}, textbuf.RegionNil)
+	}
+	tv.SetCursorShow(reg.End)
+	tv.SetCursorCol(tv.CursorPos)
+	tv.RenderAllLines()
+	return true
+}
+
+// SelectColumn makes a rectangular column selection between the given
+// start and end positions, with one cursor per line, each selecting the
+// characters between the start and end columns (clipped to the line
+// length).  The cursor on the line of the end position is the main one.
+func (tv *TextView) SelectColumn(st, ed lex.Pos) {
+	if tv.Buf == nil {
+		return
+	}
+	nln := tv.Buf.NumLines()
+	if nln == 0 {
+		return
+	}
+	// note: columns are not clipped to the end lines, only to each line
+	st.Ln, ed.Ln = ints.MinInt(st.Ln, nln-1), ints.MinInt(ed.Ln, nln-1)
+	st.Ch, ed.Ch = ints.MaxInt(st.Ch, 0), ints.MaxInt(ed.Ch, 0)
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	stln, edln := ints.MinInt(st.Ln, ed.Ln), ints.MaxInt(st.Ln, ed.Ln)
+	stch, edch := ints.MinInt(st.Ch, ed.Ch), ints.MaxInt(st.Ch, ed.Ch)
+	tv.Cursors = nil
+	for ln := stln; ln <= edln; ln++ {
+		sz := tv.Buf.LineLen(ln)
+		c := TextCursor{Pos: lex.Pos{Ln: ln, Ch: ints.MinInt(ed.Ch, sz)}, Sel: textbuf.RegionNil}
+		if stch < sz && stch != edch {
+			c.Sel = textbuf.NewRegion(ln, stch, ln, ints.MinInt(edch, sz))
+			c.SelStart = lex.Pos{Ln: ln, Ch: ints.MinInt(st.Ch, sz)}
+		}
+		if ln == ed.Ln {
+			tv.SelectStart = c.SelStart
+			tv.SelectReg = c.Sel
+			tv.PrevSelectReg = textbuf.RegionNil
+			tv.SetCursor(c.Pos)
+			continue
+		}
+		tv.Cursors = append(tv.Cursors, c)
+	}
+	tv.RenderAllLines()
+	tv.RenderCursor(true)
+}
+
+// cursorsSorted returns all cursors including the main one, sorted in
+// ascending position order with duplicates removed, and the index of the
+// main one.
+func (tv *TextView) cursorsSorted() ([]TextCursor, int) {
+	curs := make([]TextCursor, 0, len(tv.Cursors)+1)
+	mc := tv.MainCursor()
+	curs = append(curs, mc)
+	for _, c := range tv.Cursors {
+		c.Pos = tv.Buf.ValidPos(c.Pos)
+		curs = append(curs, c)
+	}
+	sort.SliceStable(curs, func(i, j int) bool {
+		return curs[i].Pos.IsLess(curs[j].Pos)
+	})
+	mi := 0
+	uc := curs[:0]
+	for i, c := range curs {
+		if i > 0 && c.Pos == uc[len(uc)-1].Pos {
+			if c.Pos == mc.Pos {
+				uc[len(uc)-1] = c
+			}
+			continue
+		}
+		if c.Pos == mc.Pos {
+			mi = len(uc)
+		}
+		uc = append(uc, c)
+	}
+	return uc, mi
+}
+
+// ForEachCursor calls given function with each cursor in turn set as the
+// main CursorPos and selection, in reverse position order, so that the
+// function can use all the standard single-cursor editing methods.  The
+// idx arg is the index of the cursor in ascending position order.  All
+// edits are saved as one undo group, and the positions of the cursors
+// are updated for the edits made at each other cursor.
+func (tv *TextView) ForEachCursor(fun func(idx int)) {
+	if tv.Buf == nil {
+		return
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	curs, mi := tv.cursorsSorted()
+	adj := func(pos lex.Pos, tbe *textbuf.Edit) lex.Pos {
+		return tbe.AdjustPos(pos, textbuf.AdjustPosDelStart)
+	}
+	tv.Buf.Undos.GroupStart()
+	tv.multiRec = true
+	for i := len(curs) - 1; i >= 0; i-- {
+		c := &curs[i]
+		tv.multiEdits = tv.multiEdits[:0]
+		tv.CursorPos = tv.Buf.ValidPos(c.Pos)
+		tv.SelectStart = c.SelStart
+		tv.SelectReg = c.Sel
+		tv.PrevSelectReg = textbuf.RegionNil
+		fun(i)
+		c.Pos = tv.CursorPos
+		c.SelStart = tv.SelectStart
+		c.Sel = tv.SelectReg
+		for _, tbe := range tv.multiEdits {
+			for j := i + 1; j < len(curs); j++ {
+				pc := &curs[j]
+				pc.Pos = adj(pc.Pos, tbe)
+				pc.SelStart = adj(pc.SelStart, tbe)
+				if pc.Sel != textbuf.RegionNil {
+					pc.Sel.Start = adj(pc.Sel.Start, tbe)
+					pc.Sel.End = adj(pc.Sel.End, tbe)
+				}
+			}
+		}
+	}
+	tv.multiRec = false
+	tv.multiEdits = nil
+	tv.Buf.Undos.GroupEnd()
+
+	mc := curs[mi]
+	tv.Cursors = tv.Cursors[:0]
+	for i, c := range curs {
+		if i == mi || c.Pos == mc.Pos {
+			continue
+		}
+		if len(tv.Cursors) > 0 && tv.Cursors[len(tv.Cursors)-1].Pos == c.Pos {
+			continue
+		}
+		tv.Cursors = append(tv.Cursors, c)
+	}
+	tv.SelectStart = mc.SelStart
+	tv.SelectReg = mc.Sel
+	tv.PrevSelectReg = textbuf.RegionNil
+	tv.SetCursorShow(mc.Pos)
+	tv.RenderAllLines()
+}
+
+// CursorsText returns the text selected at all cursors, in position order,
+// one per line -- returns nil if nothing is selected
+func (tv *TextView) CursorsText() []byte {
+	curs, _ := tv.cursorsSorted()
+	var b bytes.Buffer
+	got := false
+	for i, c := range curs {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if c.Sel.Start.IsLess(c.Sel.End) {
+			b.Write(tv.Buf.Region(c.Sel.Start, c.Sel.End).ToBytes())
+			got = true
+		}
+	}
+	if !got {
+		return nil
+	}
+	return b.Bytes()
+}
+
+// CursorsCopy copies the text selected at all cursors to the clipboard,
+// one line per cursor, optionally deleting it
+func (tv *TextView) CursorsCopy(cut bool) {
+	cb := tv.CursorsText()
+	if cb == nil {
+		return
+	}
+	TextViewClipHistAdd(cb)
+	oswin.TheApp.ClipBoard(tv.ParentWindow().OSWin).Write(mimedata.NewTextBytes(cb))
+	if cut {
+		tv.ForEachCursor(func(idx int) {
+			if tv.HasSelection() {
+				org := tv.SelectReg.Start
+				tv.DeleteSelection()
+				tv.SetCursor(org)
+			}
+		})
+	}
+}
+
+// CursorsPaste pastes the clipboard at each cursor -- if the clipboard has
+// one line per cursor (e.g., from CursorsCopy), each cursor gets its own
+// line, and otherwise each gets the full text.
+func (tv *TextView) CursorsPaste() {
+	data := oswin.TheApp.ClipBoard(tv.ParentWindow().OSWin).Read([]string{filecat.TextPlain})
+	if data == nil {
+		return
+	}
+	txt := data.TypeData(filecat.TextPlain)
+	lns := bytes.Split(bytes.TrimSuffix(txt, []byte("\n")), []byte("\n"))
+	if len(lns) != len(tv.Cursors)+1 {
+		lns = nil
+	}
+	tv.ForEachCursor(func(idx int) {
+		if lns != nil {
+			if len(lns[idx]) > 0 {
+				tv.InsertAtCursor(lns[idx])
+			} else if tv.HasSelection() {
+				tv.DeleteSelection()
+			}
+		} else {
+			tv.InsertAtCursor(txt)
+		}
+	})
+}
+
+// CursorsKeyInput handles keyboard input when there are additional cursors,
+// applying movement and editing to all the cursors.  Returns true if the
+// key was handled -- otherwise other keys remove the additional cursors
+// and are processed normally.
+func (tv *TextView) CursorsKeyInput(kt *key.ChordEvent, kf gi.KeyFuns) bool {
+	move := func(fun func()) {
+		kt.SetProcessed()
+		tv.ForEachCursor(func(idx int) {
+			tv.ShiftSelect(kt)
+			fun()
+			if kt.HasAnyModifier(key.Shift) {
+				tv.SelectRegUpdate(tv.CursorPos)
+			}
+		})
+	}
+	edit := func(fun func()) bool {
+		if tv.IsInactive() {
+			return false
+		}
+		kt.SetProcessed()
+		tv.lastAutoInsert = 0
+		tv.ForEachCursor(func(idx int) {
+			fun()
+		})
+		return true
+	}
+	switch kf {
+	case gi.KeyFunMoveRight:
+		move(func() { tv.CursorForward(1) })
+	case gi.KeyFunMoveLeft:
+		move(func() { tv.CursorBackward(1) })
+	case gi.KeyFunWordRight:
+		move(func() { tv.CursorForwardWord(1) })
+	case gi.KeyFunWordLeft:
+		move(func() { tv.CursorBackwardWord(1) })
+	case gi.KeyFunMoveUp:
+		move(func() { tv.CursorUp(1) })
+	case gi.KeyFunMoveDown:
+		move(func() { tv.CursorDown(1) })
+	case gi.KeyFunHome:
+		move(func() { tv.CursorStartLine() })
+	case gi.KeyFunEnd:
+		move(func() { tv.CursorEndLine() })
+	case gi.KeyFunCancelSelect, gi.KeyFunAbort:
+		kt.SetProcessed()
+		tv.ClearCursors()
+	case gi.KeyFunCopy:
+		kt.SetProcessed()
+		tv.CursorsCopy(false)
+	case gi.KeyFunAddCursorNext:
+		kt.SetProcessed()
+		tv.CursorAddNext()
+	case gi.KeyFunUndo, gi.KeyFunRedo:
+		tv.ClearCursors()
+		return false
+	case gi.KeyFunCut:
+		if tv.IsInactive() {
+			return false
+		}
+		kt.SetProcessed()
+		tv.CursorsCopy(true)
+	case gi.KeyFunPaste:
+		if tv.IsInactive() {
+			return false
+		}
+		kt.SetProcessed()
+		tv.CursorsPaste()
+	case gi.KeyFunBackspace:
+		return edit(func() { tv.CursorBackspace(1) })
+	case gi.KeyFunDelete:
+		return edit(func() { tv.CursorDelete(1) })
+	case gi.KeyFunBackspaceWord:
+		return edit(func() { tv.CursorBackspaceWord(1) })
+	case gi.KeyFunDeleteWord:
+		return edit(func() { tv.CursorDeleteWord(1) })
+	case gi.KeyFunEnter:
+		if kt.HasAnyModifier(key.Control, key.Meta) {
+			tv.ClearCursors()
+			return false
+		}
+		return edit(func() {
+			tv.InsertAtCursor([]byte("\n"))
+			if tv.Buf.Opts.AutoIndent {
+				tbe, _, cpos := tv.Buf.AutoIndent(tv.CursorPos.Ln)
+				if tbe != nil {
+					tv.SetCursor(lex.Pos{Ln: tbe.Reg.End.Ln, Ch: cpos})
+				}
+			}
+		})
+	case gi.KeyFunFocusNext: // tab
+		if kt.HasAnyModifier(key.Control, key.Meta) {
+			tv.ClearCursors()
+			return false
+		}
+		return edit(func() {
+			tv.InsertAtCursor(indent.Bytes(tv.Buf.Opts.IndentChar(), 1, tv.Sty.Text.TabSize))
+		})
+	case gi.KeyFunNil:
+		if !unicode.IsPrint(kt.Rune) || kt.HasAnyModifier(key.Control, key.Meta) {
+			return false
+		}
+		return edit(func() { tv.InsertAtCursor([]byte(string(kt.Rune))) })
+	default:
+		tv.ClearCursors()
+		return false
+	}
+	return true
+}
+
+// RenderCursors renders the additional cursors and their selections --
+// always called within context of outer RenderLines or RenderAllLines
+func (tv *TextView) RenderCursors() {
+	if len(tv.Cursors) == 0 {
+		return
+	}
+	rs := tv.Render()
+	pc := &rs.Paint
+	sty := &tv.StateStyles[TextViewActive]
+	cw := mat32.Max(tv.CursorWidth.Dots, 2)
+	for _, c := range tv.Cursors {
+		if c.Sel.Start.IsLess(c.Sel.End) {
+			tv.RenderRegionBox(c.Sel, TextViewSel)
+		}
+		if c.Pos.Ln >= tv.NLines {
+			continue
+		}
+		pos := tv.CharStartPos(c.Pos)
+		if int(mat32.Ceil(pos.Y+tv.FontHeight)) < tv.VpBBox.Min.Y || int(mat32.Floor(pos.Y)) > tv.VpBBox.Max.Y {
+			continue
+		}
+		pc.FillBoxColor(rs, pos, mat32.NewVec2(cw, tv.FontHeight), sty.Font.Color)
+	}
+}
@@ -0,0 +1,478 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"sort"
+	"unicode"
+
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/ki/ints"
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/syms"
+	"github.com/goki/pi/token"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//    Code Folding
+
+// TextViewMaxFoldLines is the maximum number of lines to search back from
+// the cursor for a fold region that contains it
+var TextViewMaxFoldLines = 10000
+
+// TextViewFoldMarker is shown after the line number of lines that can be
+// folded -- clicking on the line number toggles folding
+var TextViewFoldMarker = "-"
+
+// TextViewFoldedMarker is shown after the line number of folded lines
+var TextViewFoldedMarker = "+"
+
+// TextFold is a range of lines that is folded (collapsed) in a TextView:
+// the Start line remains visible, and lines after it through End are
+// hidden from the layout.  The text in the TextBuf is unaffected.
+type TextFold struct {
+	Start int `desc:"line that starts the fold, which remains visible"`
+	End   int `desc:"last line hidden by the fold (inclusive)"`
+}
+
+// LineHidden returns true if given line is hidden within a fold
+func (tv *TextView) LineHidden(ln int) bool {
+	if ln < 0 || ln >= len(tv.hidden) {
+		return false
+	}
+	return tv.hidden[ln]
+}
+
+// IsFolded returns true if given line starts a folded region
+func (tv *TextView) IsFolded(ln int) bool {
+	return tv.foldIdx(ln) >= 0
+}
+
+// foldIdx returns index of fold starting at given line, -1 if none
+func (tv *TextView) foldIdx(ln int) int {
+	for i, f := range tv.Folds {
+		if f.Start == ln {
+			return i
+		}
+	}
+	return -1
+}
+
+// foldRanges returns the last line of the fold region starting at each
+// line, or -1 if the line cannot be folded.  The ranges are computed for
+// the whole buffer from the lexer tags (matching paren, bracket and brace
+// groups) and the parsed symbols -- or from brace matching on the raw text
+// if the buffer has no tags -- and any remaining lines are folded by
+// indentation.  They are cached until the next buffer edit or markup update.
+func (tv *TextView) foldRanges() []int {
+	tv.foldsMu.Lock()
+	defer tv.foldsMu.Unlock()
+	if tv.foldEnds != nil {
+		return tv.foldEnds
+	}
+	tb := tv.Buf
+	ends := make([]int, tb.NumLines())
+	for i := range ends {
+		ends[i] = -1
+	}
+	tagged := tv.foldGroups(ends)
+	if tb.Hi.UsingPi() {
+		tv.foldSyms(ends)
+	}
+	if !tagged {
+		tv.foldBraces(ends)
+	}
+	tv.foldIndent(ends)
+	tv.foldEnds = ends
+	return ends
+}
+
+// foldsChanged clears the cached fold ranges -- called for each buffer
+// edit and markup update, which can come from another goroutine
+func (tv *TextView) foldsChanged() {
+	tv.foldsMu.Lock()
+	tv.foldEnds = nil
+	tv.foldsMu.Unlock()
+}
+
+// foldGroups sets the fold ranges for paren, bracket and brace groups in
+// the lexer tags that span multiple lines -- groups in comments and strings
+// are not tokens, so they are skipped.  The line with the closing token is
+// not included in the fold if that token starts the line, and the
+// innermost group that is open at the end of a line determines its range.
+// Returns false if the buffer has no lexer tags at all.
+func (tv *TextView) foldGroups(ends []int) bool {
+	type group struct {
+		ln  int
+		tok token.Tokens
+	}
+	tb := tv.Buf
+	tb.MarkupMu.RLock()
+	defer tb.MarkupMu.RUnlock()
+	var stack []group
+	tagged := false
+	nln := ints.MinInt(len(ends), len(tb.HiTags))
+	for ln := 0; ln < nln; ln++ {
+		if len(tb.HiTags[ln]) > 0 {
+			tagged = true
+		}
+		for i, lx := range tb.HiTags[ln] {
+			tok := lx.Tok.Tok
+			switch {
+			case tok.IsPunctGpLeft():
+				stack = append(stack, group{ln, tok})
+			case tok.IsPunctGpRight():
+				si := len(stack) - 1
+				for si >= 0 && stack[si].tok.PunctGpMatch() != tok { // skip unmatched
+					si--
+				}
+				if si < 0 {
+					continue
+				}
+				st := stack[si].ln
+				stack = stack[:si]
+				ed := ln
+				if i == 0 {
+					ed--
+				}
+				if ed > st && ends[st] < 0 {
+					ends[st] = ed
+				}
+			}
+		}
+	}
+	return tagged
+}
+
+// foldBraces sets the fold ranges for lines with a brace, bracket or paren
+// that is not closed on the same line, using TextBuf.BraceMatch -- this is
+// used for buffers without lexer tags, where the raw text is all we have.
+// As for foldGroups, the line with the closing brace is not included if
+// the brace starts that line.
+func (tv *TextView) foldBraces(ends []int) {
+	tb := tv.Buf
+	for ln := range ends {
+		if ends[ln] >= 0 {
+			continue
+		}
+		txt := tb.Line(ln)
+		ch := openBrace(txt)
+		if ch < 0 {
+			continue
+		}
+		en, found := tb.BraceMatchLines(txt[ch], lex.Pos{Ln: ln, Ch: ch}, TextViewMaxFoldLines)
+		if !found || en.Ln <= ln {
+			continue
+		}
+		ed := en.Ln
+		if lineIndentWidth(tb.Line(ed)[:en.Ch], 1) < 0 {
+			ed--
+		}
+		if ed > ln {
+			ends[ln] = ed
+		}
+	}
+}
+
+// openBrace returns the position of the last brace, bracket or paren in
+// given line that is not closed within the line, or -1 if none
+func openBrace(txt []rune) int {
+	var stack []int
+	for i, r := range txt {
+		switch r {
+		case '{', '(', '[':
+			stack = append(stack, i)
+		case '}', ')', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if len(stack) == 0 {
+		return -1
+	}
+	return stack[len(stack)-1]
+}
+
+// foldIndent sets the fold ranges for lines that are not otherwise
+// foldable based on indentation: a line folds all of the following lines
+// that are more indented than it is, with blank lines in between included.
+// This covers languages that use indentation for blocks (Python, YAML)
+// and plain text.
+func (tv *TextView) foldIndent(ends []int) {
+	type open struct {
+		ln  int
+		ind int
+	}
+	tb := tv.Buf
+	tabSz := ints.MaxInt(tb.Opts.TabSize, 1)
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	var stack []open
+	last := -1 // last non-blank line
+	closeTo := func(ind int) {
+		for si := len(stack) - 1; si >= 0 && stack[si].ind >= ind; si-- {
+			st := stack[si].ln
+			if last > st && ends[st] < 0 {
+				ends[st] = last
+			}
+			stack = stack[:si]
+		}
+	}
+	nln := ints.MinInt(len(ends), len(tb.Lines))
+	for ln := 0; ln < nln; ln++ {
+		ind := lineIndentWidth(tb.Lines[ln], tabSz)
+		if ind < 0 {
+			continue
+		}
+		closeTo(ind)
+		stack = append(stack, open{ln, ind})
+		last = ln
+	}
+	closeTo(0)
+}
+
+// lineIndentWidth returns the width of the leading whitespace in given
+// line, counting tabs as tabSz, or -1 if the line is blank
+func lineIndentWidth(txt []rune, tabSz int) int {
+	w := 0
+	for _, r := range txt {
+		switch {
+		case r == '\t':
+			w += tabSz
+		case unicode.IsSpace(r):
+			w++
+		default:
+			return w
+		}
+	}
+	return -1
+}
+
+// foldSyms sets the fold ranges for parsed symbols in this file that span
+// multiple lines, e.g., a function whose parameters start a group on its
+// first line -- the symbol region is used where it is longer than the group
+func (tv *TextView) foldSyms(ends []int) {
+	pfs := tv.Buf.PiState.Done()
+	pfs.SymsMu.RLock()
+	defer pfs.SymsMu.RUnlock()
+	var add func(sm syms.SymMap)
+	add = func(sm syms.SymMap) {
+		for _, sy := range sm {
+			if sy.Filename == pfs.Src.Filename {
+				st, ed := sy.Region.St.Ln, sy.Region.Ed.Ln
+				if st >= 0 && ed > st && ed < len(ends) && ends[st] < ed {
+					ends[st] = ed
+				}
+			}
+			add(sy.Children)
+		}
+	}
+	add(pfs.Syms)
+}
+
+// FoldRange returns the last line of the fold region starting at given
+// line, and true if the line can be folded.  Regions are defined by the
+// lexer tags and parsed symbols where available, and otherwise by brace
+// matching and indentation, see foldRanges.
+func (tv *TextView) FoldRange(ln int) (int, bool) {
+	if tv.Buf == nil || ln < 0 {
+		return ln, false
+	}
+	ends := tv.foldRanges()
+	if ln >= len(ends) || ends[ln] <= ln {
+		return ln, false
+	}
+	return ends[ln], true
+}
+
+// Foldable returns true if given line starts a region that can be folded
+func (tv *TextView) Foldable(ln int) bool {
+	_, ok := tv.FoldRange(ln)
+	return ok
+}
+
+// Fold folds the region starting at given line -- returns false if
+// it is not foldable or already folded
+func (tv *TextView) Fold(ln int) bool {
+	if tv.IsFolded(ln) || tv.LineHidden(ln) {
+		return false
+	}
+	ed, ok := tv.FoldRange(ln)
+	if !ok {
+		return false
+	}
+	tv.Folds = append(tv.Folds, TextFold{Start: ln, End: ed})
+	tv.FoldsUpdated()
+	return true
+}
+
+// Unfold unfolds the folded region starting at given line -- returns
+// false if not folded
+func (tv *TextView) Unfold(ln int) bool {
+	fi := tv.foldIdx(ln)
+	if fi < 0 {
+		return false
+	}
+	tv.Folds = append(tv.Folds[:fi], tv.Folds[fi+1:]...)
+	tv.FoldsUpdated()
+	return true
+}
+
+// FoldToggle folds or unfolds the region starting at given line --
+// returns false if nothing was done
+func (tv *TextView) FoldToggle(ln int) bool {
+	if tv.IsFolded(ln) {
+		return tv.Unfold(ln)
+	}
+	return tv.Fold(ln)
+}
+
+// FoldCursorToggle folds or unfolds the region at the cursor: if the
+// cursor line is folded it is unfolded, and otherwise the innermost
+// foldable region starting at or containing the cursor line is folded.
+func (tv *TextView) FoldCursorToggle() bool {
+	ln := tv.CursorPos.Ln
+	if tv.IsFolded(ln) {
+		return tv.Unfold(ln)
+	}
+	if tv.Fold(ln) {
+		return true
+	}
+	for l := ln - 1; l >= 0 && ln-l < TextViewMaxFoldLines; l-- {
+		if tv.LineHidden(l) {
+			continue
+		}
+		if ed, ok := tv.FoldRange(l); ok && ed >= ln { // innermost containing region
+			return tv.Fold(l)
+		}
+	}
+	return false
+}
+
+// FoldAll folds all the outermost foldable regions
+func (tv *TextView) FoldAll() {
+	if tv.Buf == nil {
+		return
+	}
+	tv.Folds = nil
+	nln := tv.Buf.NumLines()
+	for ln := 0; ln < nln; ln++ {
+		if ed, ok := tv.FoldRange(ln); ok {
+			tv.Folds = append(tv.Folds, TextFold{Start: ln, End: ed})
+			ln = ed
+		}
+	}
+	tv.FoldsUpdated()
+}
+
+// UnfoldAll unfolds all folded regions
+func (tv *TextView) UnfoldAll() {
+	if len(tv.Folds) == 0 {
+		return
+	}
+	tv.Folds = nil
+	tv.FoldsUpdated()
+}
+
+// UnfoldLine unfolds any folded regions that hide given line, so that it is
+// visible -- returns true if anything was unfolded
+func (tv *TextView) UnfoldLine(ln int) bool {
+	if !tv.LineHidden(ln) {
+		return false
+	}
+	nf := tv.Folds[:0]
+	for _, f := range tv.Folds {
+		if ln > f.Start && ln <= f.End {
+			continue
+		}
+		nf = append(nf, f)
+	}
+	tv.Folds = nf
+	tv.FoldsUpdated()
+	return true
+}
+
+// FoldsUpdated updates the layout after any changes to the Folds, and
+// moves the cursor out of any folded region
+func (tv *TextView) FoldsUpdated() {
+	tv.updateHidden()
+	if tv.Buf == nil || tv.Renders == nil {
+		return
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	tv.LayoutAllLines(false)
+	if tv.LineHidden(tv.CursorPos.Ln) {
+		ln := tv.CursorPos.Ln
+		for ln > 0 && tv.LineHidden(ln) {
+			ln--
+		}
+		tv.SelectReset()
+		tv.SetCursorShow(lex.Pos{Ln: ln, Ch: tv.Buf.LineLen(ln)})
+	}
+	tv.RenderAllLines()
+}
+
+// updateHidden sorts and validates the Folds and updates the hidden flags
+// for each line
+func (tv *TextView) updateHidden() {
+	nln := tv.NLines
+	if len(tv.Folds) == 0 {
+		tv.hidden = nil
+		return
+	}
+	sort.Slice(tv.Folds, func(i, j int) bool {
+		return tv.Folds[i].Start < tv.Folds[j].Start
+	})
+	if cap(tv.hidden) >= nln {
+		tv.hidden = tv.hidden[:nln]
+		for i := range tv.hidden {
+			tv.hidden[i] = false
+		}
+	} else {
+		tv.hidden = make([]bool, nln)
+	}
+	nf := tv.Folds[:0]
+	for _, f := range tv.Folds {
+		if f.Start < 0 || f.End <= f.Start || f.End >= nln || tv.hidden[f.Start] {
+			continue // invalid or nested within a prior fold
+		}
+		for ln := f.Start + 1; ln <= f.End; ln++ {
+			tv.hidden[ln] = true
+		}
+		nf = append(nf, f)
+	}
+	tv.Folds = nf
+}
+
+// FoldsEdited updates the Folds for lines inserted or deleted by given
+// edit -- folds that contain the edited lines are unfolded
+func (tv *TextView) FoldsEdited(tbe *textbuf.Edit) {
+	if len(tv.Folds) == 0 {
+		return
+	}
+	stln := tbe.Reg.Start.Ln
+	dl := tbe.Reg.End.Ln - stln
+	if tbe.Delete {
+		dl = -dl
+	}
+	nf := tv.Folds[:0]
+	for _, f := range tv.Folds {
+		switch {
+		case stln < f.Start:
+			if tbe.Delete && tbe.Reg.End.Ln >= f.Start { // deleted start
+				continue
+			}
+			f.Start += dl
+			f.End += dl
+		case stln <= f.End:
+			continue // edit within fold
+		}
+		nf = append(nf, f)
+	}
+	tv.Folds = nf
+	tv.updateHidden()
+}
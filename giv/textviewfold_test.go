@@ -0,0 +1,147 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"testing"
+
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/pi"
+)
+
+// textTestInit loads the languages and highlighting styles needed for
+// TextBuf markup, without the app prefs dir needed by histyle.Init
+func textTestInit() {
+	if histyle.AvailStyles == nil {
+		pi.LangSupport.OpenStd()
+		histyle.StdStyles.OpenDefaults()
+		histyle.MergeAvailStyles()
+	}
+}
+
+// foldTestView returns a TextView on a buffer with given text, marked up
+// for given language, without configuring the view
+func foldTestView(src string, sup filecat.Supported) *TextView {
+	textTestInit()
+	tb := &TextBuf{}
+	tb.InitName(tb, "tb")
+	tb.Opts.TabSize = 4
+	tb.Info.Sup = sup
+	tb.Txt = []byte(src)
+	tb.BytesToLines()
+	tb.MarkupAllLines(-1)
+	tv := &TextView{}
+	tv.InitName(tv, "tv")
+	tv.Buf = tb
+	tv.NLines = tb.NumLines()
+	return tv
+}
+
+var foldTestGo = `package main
+
+// brace in comment {
+func f(a int,
+	b int) {
+	s := "{"
+	if a > b {
+		s = "}"
+	}
+}
+
+var x = []int{1, 2}
+`
+
+var foldTestIndent = `def f(a):
+    if a:
+
+        return 1
+    return 2
+
+x = 1
+`
+
+var foldTestPlain = `section {
+  item one
+  item two
+}
+heading
+    detail
+    more
+end
+`
+
+func TestFoldRange(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		sup  filecat.Supported
+		ends []int
+	}{
+		{"go", foldTestGo, filecat.Go, []int{-1, -1, -1, 9, 8, -1, 7, -1, -1, -1, -1, -1}},
+		{"indent", foldTestIndent, filecat.Python, []int{4, 3, -1, -1, -1, -1, -1}},
+		{"plain", foldTestPlain, filecat.NoSupport, []int{2, -1, -1, -1, 6, -1, -1, -1}},
+	}
+	for _, tst := range tests {
+		tv := foldTestView(tst.src, tst.sup)
+		if nln := tv.Buf.NumLines(); nln != len(tst.ends) {
+			t.Fatalf("%v: got %v lines, expected %v", tst.name, nln, len(tst.ends))
+		}
+		for ln, xed := range tst.ends {
+			ed, ok := tv.FoldRange(ln)
+			if ok != (xed >= 0) || (ok && ed != xed) {
+				t.Errorf("%v: FoldRange(%v) = %v, %v, expected %v", tst.name, ln, ed, ok, xed)
+			}
+		}
+	}
+}
+
+func TestFoldsEdited(t *testing.T) {
+	edit := func(st, ed int, del bool) *textbuf.Edit {
+		return &textbuf.Edit{Reg: textbuf.NewRegion(st, 0, ed, 0), Delete: del}
+	}
+	tests := []struct {
+		name  string
+		tbe   *textbuf.Edit
+		nln   int
+		folds []TextFold
+	}{
+		{"insert before", edit(1, 3, false), 22, []TextFold{{7, 9}, {14, 17}}},
+		{"insert after", edit(18, 20, false), 22, []TextFold{{5, 7}, {12, 15}}},
+		{"insert within", edit(6, 7, false), 21, []TextFold{{13, 16}}},
+		{"delete before", edit(1, 3, true), 18, []TextFold{{3, 5}, {10, 13}}},
+		{"delete start", edit(3, 5, true), 18, []TextFold{{10, 13}}},
+		{"delete within", edit(13, 14, true), 19, []TextFold{{5, 7}}},
+	}
+	for _, tst := range tests {
+		tv := &TextView{}
+		tv.InitName(tv, "tv")
+		tv.NLines = tst.nln
+		tv.Folds = []TextFold{{5, 7}, {12, 15}}
+		tv.FoldsEdited(tst.tbe)
+		if len(tv.Folds) != len(tst.folds) {
+			t.Errorf("%v: got folds %v, expected %v", tst.name, tv.Folds, tst.folds)
+			continue
+		}
+		for i, f := range tst.folds {
+			if tv.Folds[i] != f {
+				t.Errorf("%v: got folds %v, expected %v", tst.name, tv.Folds, tst.folds)
+				break
+			}
+		}
+		for ln := 0; ln < tst.nln; ln++ {
+			hid := false
+			for _, f := range tst.folds {
+				if ln > f.Start && ln <= f.End {
+					hid = true
+				}
+			}
+			if tv.LineHidden(ln) != hid {
+				t.Errorf("%v: LineHidden(%v) = %v, expected %v", tst.name, ln, !hid, hid)
+			}
+		}
+	}
+}
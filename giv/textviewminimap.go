@@ -0,0 +1,357 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"image"
+	"sort"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/token"
+)
+
+// TextViewMinimap is an overview ruler for a TextView, typically placed
+// beside the layout that scrolls the view: it shows a scaled rendering of
+// the whole buffer, with the visible region outlined, and marks for search
+// matches (Highlights, ISearch and QReplace), spelling errors, and line
+// colors (e.g., diff hunks).  Clicking or dragging scrolls the view to the
+// corresponding line.  Create with AddNewTextViewMinimap.
+type TextViewMinimap struct {
+	gi.WidgetBase
+	View      *TextView   `json:"-" xml:"-" desc:"the view that this is the minimap for"`
+	MaxCols   int         `desc:"number of columns of text represented by the text width -- longer lines are cut off -- defaults to 100"`
+	LineSize  float32     `desc:"maximum height of each line, in pixels -- lines are scaled down from this to fit the whole buffer -- defaults to 2"`
+	MarkWidth units.Value `xml:"mark-width" desc:"width of the mark columns on each side, for spelling errors (left) and search matches (right) -- set from mark-width property"`
+}
+
+var KiT_TextViewMinimap = kit.Types.AddType(&TextViewMinimap{}, TextViewMinimapProps)
+
+// AddNewTextViewMinimap adds a new minimap for given view to given parent
+// node, with given name -- the parent should not be the layout that scrolls
+// the view
+func AddNewTextViewMinimap(parent ki.Ki, name string, tv *TextView) *TextViewMinimap {
+	mm := parent.AddNewChild(KiT_TextViewMinimap, name).(*TextViewMinimap)
+	mm.SetView(tv)
+	return mm
+}
+
+var TextViewMinimapProps = ki.Props{
+	"EnumType:Flag": gi.KiT_NodeFlags,
+	"width":         units.NewCh(10),
+	"min-width":     units.NewCh(10),
+	"height":        units.NewEm(10),
+	"max-height":    -1,
+	"mark-width":    units.NewPx(3),
+}
+
+// SetView sets the view that this is the minimap for
+func (mm *TextViewMinimap) SetView(tv *TextView) {
+	if mm.View != nil && mm.View.Minimap == mm {
+		mm.View.Minimap = nil
+	}
+	mm.View = tv
+	if tv != nil {
+		tv.Minimap = mm
+	}
+	mm.UpdateSig()
+}
+
+func (mm *TextViewMinimap) Disconnect() {
+	mm.WidgetBase.Disconnect()
+	if mm.View != nil && mm.View.Minimap == mm {
+		mm.View.Minimap = nil
+	}
+}
+
+// HasView returns true if the view has a buffer with text to show
+func (mm *TextViewMinimap) HasView() bool {
+	tv := mm.View
+	return tv != nil && tv.This() != nil && tv.Buf != nil && tv.NLines > 0 && len(tv.Offs) == tv.NLines
+}
+
+// ContentBox returns the position and size of the area within the margins
+// where the minimap is rendered
+func (mm *TextViewMinimap) ContentBox() (pos, sz mat32.Vec2) {
+	spc := mm.Sty.BoxSpace()
+	pos = mm.LayState.Alloc.Pos.AddScalar(spc)
+	sz = mm.LayState.Alloc.Size.SubScalar(2 * spc)
+	return
+}
+
+// LineScale returns the height of each line in the minimap
+func (mm *TextViewMinimap) LineScale() float32 {
+	_, sz := mm.ContentBox()
+	nln := mm.View.NLines
+	if nln == 0 {
+		return mm.LineSize
+	}
+	return math32.Min(mm.LineSize, sz.Y/float32(nln))
+}
+
+// VisLines returns the range of lines currently visible in the view
+func (mm *TextViewMinimap) VisLines() (st, ed int) {
+	tv := mm.View
+	pos := tv.RenderStartPos()
+	top := float32(tv.VpBBox.Min.Y) - pos.Y
+	bot := float32(tv.VpBBox.Max.Y) - pos.Y
+	st = sort.Search(tv.NLines, func(i int) bool { return tv.Offs[i]+tv.LineHeight > top }) // first not above
+	ed = sort.Search(tv.NLines, func(i int) bool { return tv.Offs[i] >= bot }) - 1          // last not below
+	if st >= tv.NLines {
+		st = tv.NLines - 1
+	}
+	if ed < st {
+		ed = st
+	}
+	return
+}
+
+// LineAtPoint returns the line in the view at given point in the minimap
+func (mm *TextViewMinimap) LineAtPoint(pt image.Point) int {
+	lsc := mm.LineScale()
+	if lsc <= 0 {
+		return 0
+	}
+	pos, _ := mm.ContentBox()
+	ln := int((float32(pt.Y) - pos.Y) / lsc)
+	if ln < 0 {
+		ln = 0
+	}
+	if ln >= mm.View.NLines {
+		ln = mm.View.NLines - 1
+	}
+	return ln
+}
+
+// ScrollToLine scrolls the view so given line is centered
+func (mm *TextViewMinimap) ScrollToLine(ln int) {
+	if !mm.HasView() || ln < 0 || ln >= mm.View.NLines {
+		return
+	}
+	tv := mm.View
+	pos := tv.RenderStartPos()
+	tv.ScrollToVertCenter(int(pos.Y + tv.Offs[ln] + 0.5*tv.LineHeight))
+}
+
+// MouseEvent handles clicking to scroll
+func (mm *TextViewMinimap) MouseEvent() {
+	mm.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		mmv := recv.Embed(KiT_TextViewMinimap).(*TextViewMinimap)
+		if me.Button == mouse.Left && (me.Action == mouse.Press || me.Action == mouse.DoubleClick) {
+			me.SetProcessed()
+			mmv.ScrollToLine(mmv.LineAtPoint(me.Where))
+		}
+	})
+}
+
+// MouseDragEvent handles dragging to scroll
+func (mm *TextViewMinimap) MouseDragEvent() {
+	mm.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		mmv := recv.Embed(KiT_TextViewMinimap).(*TextViewMinimap)
+		me.SetProcessed()
+		mmv.ScrollToLine(mmv.LineAtPoint(me.Where))
+	})
+}
+
+func (mm *TextViewMinimap) ConnectEvents2D() {
+	mm.MouseEvent()
+	mm.MouseDragEvent()
+}
+
+func (mm *TextViewMinimap) Style2D() {
+	mm.WidgetBase.Style2D()
+	mm.MarkWidth.SetFmInheritProp("mark-width", mm.This(), ki.NoInherit, ki.TypeProps)
+	mm.MarkWidth.ToDots(&mm.Sty.UnContext)
+	if mm.MaxCols <= 0 {
+		mm.MaxCols = 100
+	}
+	if mm.LineSize <= 0 {
+		mm.LineSize = 2
+	}
+}
+
+// RenderMinimap renders the minimap -- outside of the update process, e.g.,
+// when the view has been scrolled or edited
+func (mm *TextViewMinimap) RenderMinimap() {
+	if mm == nil || mm.This() == nil || !mm.This().(gi.Node2D).IsVisible() {
+		return
+	}
+	rs := mm.Render()
+	rs.PushBounds(mm.VpBBox)
+	wupdt := mm.TopUpdateStart()
+	mm.RenderInBounds()
+	rs.PopBounds()
+	mm.Viewport.This().(gi.Viewport).VpUploadRegion(mm.VpBBox, mm.WinBBox)
+	mm.TopUpdateEnd(wupdt)
+}
+
+// RenderInBounds renders the minimap after the bounds have been pushed
+func (mm *TextViewMinimap) RenderInBounds() {
+	rs := mm.Render()
+	rs.Lock()
+	defer rs.Unlock()
+	pc := &rs.Paint
+	pos, sz := mm.ContentBox()
+	if !mm.HasView() {
+		pc.FillBox(rs, pos, sz, &mm.Sty.Font.BgColor)
+		return
+	}
+	tv := mm.View
+	tsty := &tv.StateStyles[TextViewActive]
+	bg := tsty.Font.BgColor.Color
+	pc.FillBoxColor(rs, pos, sz, bg)
+	mw := math32.Ceil(mm.MarkWidth.Dots)
+	tx := pos.X + mw
+	tw := sz.X - 2*mw
+	cw := tw / float32(mm.MaxCols)
+	lsc := mm.LineScale()
+	lh := math32.Max(lsc, 1)
+	tb := tv.Buf
+	tabSz := tb.Opts.TabSize
+	if tabSz <= 0 {
+		tabSz = 4
+	}
+	fg := tsty.Font.Color
+	txclr := fg.Blend(50, bg)
+
+	tb.LinesMu.RLock()
+	nln := ints.MinInt(tv.NLines, tb.NLines)
+	for ln, clr := range tb.LineColors {
+		if ln < nln {
+			pc.FillBoxColor(rs, mat32.Vec2{pos.X, pos.Y + float32(ln)*lsc}, mat32.Vec2{sz.X, lh}, clr)
+		}
+	}
+	// lines that fall on the same pixel row are merged, to keep it fast for
+	// big buffers
+	row := -1
+	var ind, end int
+	flush := func() {
+		if row < 0 || end <= ind {
+			return
+		}
+		end = ints.MinInt(end, mm.MaxCols)
+		if end > ind {
+			pc.FillBoxColor(rs, mat32.Vec2{tx + float32(ind)*cw, pos.Y + float32(row)}, mat32.Vec2{float32(end-ind) * cw, lh}, txclr)
+		}
+	}
+	for ln := 0; ln < nln; ln++ {
+		li, le := minimapLineExtent(tb.Lines[ln], tabSz)
+		r := int(float32(ln) * lsc)
+		if r != row {
+			flush()
+			row, ind, end = r, li, le
+			continue
+		}
+		if le > li {
+			if end <= ind {
+				ind, end = li, le
+			} else {
+				ind = ints.MinInt(ind, li)
+				end = ints.MaxInt(end, le)
+			}
+		}
+	}
+	flush()
+
+	spclr := gi.Color{}
+	spclr.SetName("red")
+	for ln := 0; ln < nln && ln < len(tb.Tags); ln++ {
+		for _, t := range tb.Tags[ln] {
+			if t.Tok.Tok == token.TextSpellErr {
+				pc.FillBoxColor(rs, mat32.Vec2{pos.X, pos.Y + float32(ln)*lsc}, mat32.Vec2{mw, lh}, spclr)
+				break
+			}
+		}
+	}
+	tb.LinesMu.RUnlock()
+
+	hiclr := tv.StateStyles[TextViewHighlight].Font.BgColor.Color
+	if hiclr.IsNil() {
+		hiclr = gi.Prefs.Colors.Highlight
+	}
+	mark := func(reg textbuf.Region) {
+		if reg.IsNil() || reg.Start.Ln >= nln {
+			return
+		}
+		ed := ints.MinInt(reg.End.Ln, nln-1)
+		y := pos.Y + float32(reg.Start.Ln)*lsc
+		h := math32.Max(float32(ed-reg.Start.Ln+1)*lsc, lh)
+		pc.FillBoxColor(rs, mat32.Vec2{pos.X + sz.X - mw, y}, mat32.Vec2{mw, h}, hiclr)
+	}
+	for _, reg := range tv.Highlights {
+		mark(tb.AdjustReg(reg))
+	}
+	if tv.ISearch.On {
+		for _, m := range tv.ISearch.Matches {
+			mark(tb.AdjustReg(m.Reg))
+		}
+	}
+	if tv.QReplace.On {
+		for _, m := range tv.QReplace.Matches {
+			mark(tb.AdjustReg(m.Reg))
+		}
+	}
+
+	st, ed := mm.VisLines()
+	vpos := mat32.Vec2{pos.X, pos.Y + float32(st)*lsc}
+	vsz := mat32.Vec2{sz.X, math32.Max(float32(ed-st+1)*lsc, lh)}
+	pc.FillStyle.SetColor(fg)
+	pc.FillStyle.Opacity = 0.15
+	pc.StrokeStyle.SetColor(txclr)
+	pc.StrokeStyle.Width.Dots = 1
+	pc.DrawRectangle(rs, vpos.X+0.5, vpos.Y+0.5, vsz.X-1, vsz.Y-1)
+	pc.FillStrokeClear(rs)
+	pc.FillStyle.Opacity = 1
+}
+
+// minimapLineExtent returns the starting (after indentation) and ending
+// columns of the text in given line, with tabs expanded
+func minimapLineExtent(txt []rune, tabSz int) (st, ed int) {
+	col := 0
+	st = -1
+	for _, r := range txt {
+		if r == '\t' {
+			col = (col/tabSz + 1) * tabSz
+			continue
+		}
+		if r == ' ' {
+			col++
+			continue
+		}
+		if st < 0 {
+			st = col
+		}
+		col++
+		ed = col
+	}
+	if st < 0 {
+		return 0, 0
+	}
+	return st, ed
+}
+
+func (mm *TextViewMinimap) Render2D() {
+	if mm.FullReRenderIfNeeded() {
+		return
+	}
+	if mm.PushBounds() {
+		mm.This().(gi.Node2D).ConnectEvents2D()
+		mm.RenderInBounds()
+		mm.Render2DChildren()
+		mm.PopBounds()
+	} else {
+		mm.DisconnectAllEvents(gi.RegPri)
+	}
+}
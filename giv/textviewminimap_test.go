@@ -0,0 +1,111 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/mat32"
+)
+
+func TestMinimapLineExtent(t *testing.T) {
+	tests := []struct {
+		txt    string
+		st, ed int
+	}{
+		{"", 0, 0},
+		{"   ", 0, 0},
+		{"abc", 0, 3},
+		{"  abc  ", 2, 5},
+		{"\tabc", 4, 7},
+		{"  \tabc", 4, 7},
+		{"\t\ta b", 8, 11},
+		{"ab\tc", 0, 5},
+	}
+	for _, tst := range tests {
+		if st, ed := minimapLineExtent([]rune(tst.txt), 4); st != tst.st || ed != tst.ed {
+			t.Errorf("minimapLineExtent(%q) = %v, %v, expected %v, %v", tst.txt, st, ed, tst.st, tst.ed)
+		}
+	}
+}
+
+// minimapTestView returns a minimap for an unconfigured view of nln lines,
+// each lnHt high, with the viewport showing view positions top to bot
+func minimapTestView(nln int, lnHt float32, top, bot int) *TextViewMinimap {
+	par := &gi.Layout{}
+	par.InitName(par, "par")
+	tv := &TextView{}
+	tv.InitName(tv, "tv")
+	tv.Buf = &TextBuf{}
+	tv.NLines = nln
+	tv.LineHeight = lnHt
+	tv.Offs = make([]float32, nln)
+	for i := range tv.Offs {
+		tv.Offs[i] = float32(i) * lnHt
+	}
+	tv.VpBBox = image.Rect(0, top, 100, bot)
+	mm := AddNewTextViewMinimap(par, "mm", tv)
+	mm.LineSize = 2
+	mm.LayState.Alloc.Pos = mat32.Vec2{200, 10}
+	mm.LayState.Alloc.Size = mat32.Vec2{50, 100}
+	return mm
+}
+
+func TestTextViewMinimap(t *testing.T) {
+	mm := minimapTestView(20, 10, 0, 50)
+	tv := mm.View
+	if !mm.HasView() || tv.Minimap != mm {
+		t.Fatalf("AddNewTextViewMinimap: HasView %v, view minimap set %v", mm.HasView(), tv.Minimap == mm)
+	}
+	if lsc := mm.LineScale(); lsc != 2 {
+		t.Errorf("LineScale for few lines = %v, expected LineSize 2", lsc)
+	}
+	if st, ed := mm.VisLines(); st != 0 || ed != 4 {
+		t.Errorf("VisLines at top = %v, %v, expected 0, 4", st, ed)
+	}
+	tv.VpBBox = image.Rect(0, 105, 100, 155) // scrolled by half a line
+	if st, ed := mm.VisLines(); st != 10 || ed != 15 {
+		t.Errorf("VisLines scrolled = %v, %v, expected 10, 15", st, ed)
+	}
+	tests := []struct {
+		y  int
+		ln int
+	}{
+		{0, 0},
+		{10, 0},
+		{13, 1},
+		{40, 15},
+		{100, 19},
+	}
+	for _, tst := range tests {
+		if ln := mm.LineAtPoint(image.Point{220, tst.y}); ln != tst.ln {
+			t.Errorf("LineAtPoint y = %v: %v, expected %v", tst.y, ln, tst.ln)
+		}
+	}
+
+	// many lines are scaled down to fit
+	mm = minimapTestView(400, 10, 0, 50)
+	if lsc := mm.LineScale(); lsc != 0.25 {
+		t.Errorf("LineScale for many lines = %v, expected 0.25", lsc)
+	}
+	if ln := mm.LineAtPoint(image.Point{220, 60}); ln != 200 {
+		t.Errorf("LineAtPoint scaled: %v, expected 200", ln)
+	}
+
+	// a view has one minimap
+	tv = mm.View
+	par := mm.Parent()
+	mm2 := AddNewTextViewMinimap(par, "mm2", tv)
+	mm.SetView(nil)
+	if tv.Minimap != mm2 || mm.HasView() {
+		t.Errorf("SetView(nil) on old minimap cleared the new one")
+	}
+	mm2.Delete(true)
+	if tv.Minimap != nil {
+		t.Errorf("Delete: view still has the minimap")
+	}
+}
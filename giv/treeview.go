@@ -269,6 +269,50 @@ func (tv *TreeView) Label() string {
 	return tv.SrcNode.Name()
 }
 
+// HasBranch returns true if this node shows an open / close branch: it has
+// children, or its source node has children that can be loaded on demand
+// (see TreeLazyLoader).
+func (tv *TreeView) HasBranch() bool {
+	return tv.HasChildren() || HasLazyChildren(tv.SrcNode)
+}
+
+// TreeLazyLoader is an optional interface for source nodes that fetch their
+// children on demand, e.g., directories that are only read when opened.
+// Such nodes show a branch in TreeView and VirtTreeView even when they do
+// not have any children yet, and LoadChildren is called when they are opened.
+type TreeLazyLoader interface {
+	// HasLazyChildren returns true if the node may have children that have
+	// not yet been loaded.
+	HasLazyChildren() bool
+
+	// LoadChildren loads the children of the node -- they should be added
+	// within an UpdateStart / UpdateEnd block so that views are updated.
+	LoadChildren() error
+}
+
+// HasLazyChildren returns true if given node implements TreeLazyLoader and
+// has children that have not yet been loaded.
+func HasLazyChildren(k ki.Ki) bool {
+	if k == nil || k.This() == nil {
+		return false
+	}
+	ll, ok := k.This().(TreeLazyLoader)
+	return ok && ll.HasLazyChildren()
+}
+
+// LoadLazyChildren calls LoadChildren on given node if it has children that
+// have not yet been loaded (see TreeLazyLoader).
+func LoadLazyChildren(k ki.Ki) error {
+	if !HasLazyChildren(k) {
+		return nil
+	}
+	err := k.This().(TreeLazyLoader).LoadChildren()
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
 // UpdateInactive updates the Inactive state based on SrcNode -- returns true if
 // inactive.  The inactivity of individual nodes only affects display properties
 // typically, and not overall functional behavior, which is controlled by
@@ -850,6 +894,7 @@ func (tv *TreeView) Close() {
 func (tv *TreeView) Open() {
 	if tv.IsClosed() {
 		updt := tv.UpdateStart()
+		LoadLazyChildren(tv.SrcNode) // syncs our children from source signal
 		if tv.HasChildren() {
 			tv.SetFullReRender()
 		}
@@ -992,17 +1037,21 @@ func (tv *TreeView) SrcInsertAt(rel int, actNm string) {
 				par := tvv.SrcNode
 				dlg, _ := send.(*gi.Dialog)
 				n, typ := gi.NewKiDialogValues(dlg)
+				un := ValueUndoFor(tvv.This())
+				un.GroupStart()
 				updt := par.UpdateStart()
 				var ski ki.Ki
 				for i := 0; i < n; i++ {
 					nm := fmt.Sprintf("New%v%v", typ.Name(), myidx+rel+i)
 					nki := par.InsertNewChild(typ, myidx+i, nm)
+					ValueUndoTreeInsert(tvv.This(), nki)
 					if i == n-1 {
 						ski = nki
 					}
 				}
 				tvv.SetChanged()
 				par.UpdateEnd(updt)
+				un.GroupEnd()
 				if ski != nil {
 					if tvk := tvv.ChildByName("tv_"+ski.Name(), 0); tvk != nil {
 						stv, _ := tvk.Embed(KiT_TreeView).(*TreeView)
@@ -1030,17 +1079,21 @@ func (tv *TreeView) SrcAddChild() {
 				sk := tvv.SrcNode
 				dlg, _ := send.(*gi.Dialog)
 				n, typ := gi.NewKiDialogValues(dlg)
+				un := ValueUndoFor(tvv.This())
+				un.GroupStart()
 				updt := sk.UpdateStart()
 				var ski ki.Ki
 				for i := 0; i < n; i++ {
 					nm := fmt.Sprintf("New%v%v", typ.Name(), i)
 					nki := sk.AddNewChild(typ, nm)
+					ValueUndoTreeInsert(tvv.This(), nki)
 					if i == n-1 {
 						ski = nki
 					}
 				}
 				tvv.SetChanged()
 				sk.UpdateEnd(updt)
+				un.GroupEnd()
 				if ski != nil {
 					tvv.Open()
 					if tvk := tvv.ChildByName("tv_"+ski.Name(), 0); tvk != nil {
@@ -1066,7 +1119,7 @@ func (tv *TreeView) SrcDelete() {
 		log.Printf("TreeView %v nil SrcNode in: %v\n", ttl, tv.PathUnique())
 		return
 	}
-	sk.Delete(true)
+	ValueUndoTreeDelete(tv.This(), sk)
 	tv.SetChanged()
 }
 
@@ -1100,6 +1153,7 @@ func (tv *TreeView) SrcDuplicate() {
 	nwkid := sk.Clone()
 	nwkid.SetName(nm)
 	par.InsertChild(nwkid, myidx+1)
+	ValueUndoTreeInsert(tv.This(), nwkid)
 	tvpar.SetChanged()
 	if tvk := tvpar.ChildByName("tv_"+nm, 0); tvk != nil {
 		stv, _ := tvk.Embed(KiT_TreeView).(*TreeView)
@@ -1190,9 +1244,12 @@ func (tv *TreeView) Cut() {
 	tv.Copy(false)
 	sels := tv.SelectedSrcNodes()
 	tv.UnselectAll()
+	un := ValueUndoFor(tv.This())
+	un.GroupStart()
 	for _, sn := range sels {
-		sn.Delete(true)
+		ValueUndoTreeDelete(tv.RootView.This(), sn)
 	}
+	un.GroupEnd()
 	tv.SetChanged()
 }
 
@@ -1258,7 +1315,9 @@ func (tv *TreeView) PasteAssign(md mimedata.Mimes) {
 		log.Printf("TreeView PasteAssign nil SrcNode in: %v\n", tv.PathUnique())
 		return
 	}
+	old := sk.Clone()
 	sk.CopyFrom(sl[0])
+	ValueUndoTreeAssign(tv.This(), sk, old)
 	tv.SetChanged()
 }
 
@@ -1301,6 +1360,9 @@ func (tv *TreeView) PasteAt(md mimedata.Mimes, mod dnd.DropMods, rel int, actNm
 		return
 	}
 	myidx += rel
+	un := ValueUndoFor(tv.This())
+	un.GroupStart()
+	defer un.GroupEnd()
 	updt := par.UpdateStart()
 	sz := len(sl)
 	var ski ki.Ki
@@ -1311,6 +1373,7 @@ func (tv *TreeView) PasteAt(md mimedata.Mimes, mod dnd.DropMods, rel int, actNm
 			}
 		}
 		par.InsertChild(ns, myidx+i)
+		ValueUndoTreeInsert(tv.This(), ns)
 		if i == sz-1 {
 			ski = ns
 		}
@@ -1335,11 +1398,15 @@ func (tv *TreeView) PasteChildren(md mimedata.Mimes, mod dnd.DropMods) {
 		log.Printf("TreeView PasteChildren nil SrcNode in: %v\n", tv.PathUnique())
 		return
 	}
+	un := ValueUndoFor(tv.This())
+	un.GroupStart()
 	updt := sk.UpdateStart()
 	for _, ns := range sl {
 		sk.AddChild(ns)
+		ValueUndoTreeInsert(tv.This(), ns)
 	}
 	sk.UpdateEnd(updt)
+	un.GroupEnd()
 	tv.SetChanged()
 }
 
@@ -1422,7 +1489,7 @@ func (tv *TreeView) Dragged(de *dnd.Event) {
 			path := string(d.Data)
 			sn := sroot.FindPathUnique(path)
 			if sn != nil {
-				sn.Delete(true)
+				ValueUndoTreeDelete(tv.RootView.This(), sn)
 			}
 		}
 	}
@@ -1488,20 +1555,29 @@ func (tv *TreeView) DropAssign(md mimedata.Mimes) {
 
 // DropBefore inserts object(s) from mime data before this node
 func (tv *TreeView) DropBefore(md mimedata.Mimes, mod dnd.DropMods) {
+	un := ValueUndoFor(tv.This()) // move deletes source in same undo group
+	un.GroupStart()
 	tv.PasteBefore(md, mod)
 	tv.DragNDropFinalize(mod)
+	un.GroupEnd()
 }
 
 // DropAfter inserts object(s) from mime data after this node
 func (tv *TreeView) DropAfter(md mimedata.Mimes, mod dnd.DropMods) {
+	un := ValueUndoFor(tv.This()) // move deletes source in same undo group
+	un.GroupStart()
 	tv.PasteAfter(md, mod)
 	tv.DragNDropFinalize(mod)
+	un.GroupEnd()
 }
 
 // DropChildren inserts object(s) from mime data at end of children of this node
 func (tv *TreeView) DropChildren(md mimedata.Mimes, mod dnd.DropMods) {
+	un := ValueUndoFor(tv.This()) // move deletes source in same undo group
+	un.GroupStart()
 	tv.PasteChildren(md, mod)
 	tv.DragNDropFinalize(mod)
+	un.GroupEnd()
 }
 
 // DropCancel cancels the drop action e.g., preventing deleting of source
@@ -1662,7 +1738,7 @@ func (tv *TreeView) TreeViewEvents() {
 			tvv.Open()
 		}
 	})
-	if tv.HasChildren() {
+	if tv.HasBranch() {
 		if wb, ok := tv.BranchPart(); ok {
 			wb.ButtonSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				if sig == int64(gi.ButtonToggled) {
@@ -1741,7 +1817,8 @@ func (tv *TreeView) ConfigParts() {
 	tv.Parts.Lay = gi.LayoutHoriz
 	tv.Parts.Sty.Template = "giv.TreeView.Parts"
 	config := kit.TypeAndNameList{}
-	if tv.HasChildren() {
+	hasBranch := tv.HasBranch()
+	if hasBranch {
 		config.Add(gi.KiT_CheckBox, "branch")
 	}
 	if tv.Icon.IsValid() {
@@ -1750,7 +1827,7 @@ func (tv *TreeView) ConfigParts() {
 	config.Add(gi.KiT_Label, "label")
 	mods, updt := tv.Parts.ConfigChildren(config, ki.NonUniqueNames)
 	// if mods {
-	if tv.HasChildren() {
+	if hasBranch {
 		if wb, ok := tv.BranchPart(); ok {
 			wb.SetProp("#icon0", TVBranchProps)
 			wb.SetProp("#icon1", TVBranchProps)
@@ -1820,7 +1897,7 @@ func (tv *TreeView) ConfigPartsIfNeeded() {
 			lbl.SetText(ltxt)
 		}
 	}
-	if tv.HasChildren() {
+	if tv.HasBranch() {
 		if wb, ok := tv.BranchPart(); ok {
 			wb.SetChecked(!tv.IsClosed())
 		}
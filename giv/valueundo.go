@@ -0,0 +1,641 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// ValueUndoTrace -- set to true to get a report of value undo actions
+var ValueUndoTrace = false
+
+// ValueUndoMax is the maximum number of undo records saved in a ValueUndo
+// -- oldest records are discarded beyond this
+var ValueUndoMax = 500
+
+// ValueUndoRec is one record of an undoable edit, which restores the old or
+// new state of the edited value or tree.  Records with the same Group are
+// undone and redone together.
+type ValueUndoRec struct {
+	Desc  string `desc:"description of the edit, e.g., the path to the value that was edited"`
+	Group int    `desc:"group number -- all records in the same group are undone / redone together"`
+	Old   string `desc:"string representation of the old value, for reference"`
+	New   string `desc:"string representation of the new value, for reference"`
+	Undo  func() `json:"-" xml:"-" view:"-" desc:"function that restores the state prior to the edit"`
+	Redo  func() `json:"-" xml:"-" view:"-" desc:"function that restores the state after the edit"`
+	Drop  func() `json:"-" xml:"-" view:"-" desc:"optional function called when the record is discarded from the undo stack, e.g., to destroy a deleted node that can no longer be restored"`
+}
+
+// String returns a description of the record
+func (ur *ValueUndoRec) String() string {
+	if ur.Old == "" && ur.New == "" {
+		return ur.Desc
+	}
+	return fmt.Sprintf("%v: %v -> %v", ur.Desc, ur.Old, ur.New)
+}
+
+// ValueUndo is an undo manager for edits made through ValueView's
+// (StructView, MapView, SliceView, TableView) and TreeView structure
+// changes, recording the old and new reflected values and tree structure.
+// There is one for each window, installed as its gi.Undoer, so that
+// KeyFunUndo and KeyFunRedo work across all views in the window -- see
+// ValueUndoForWin.  Can also be used directly, e.g., per root object.
+type ValueUndo struct {
+	Off      bool            `desc:"if true, saving and using undos is turned off"`
+	Stack    []*ValueUndoRec `desc:"undo stack of edits"`
+	Pos      int             `desc:"undo position in stack -- records at and above this position have been undone and can be redone"`
+	Group    int             `desc:"group counter"`
+	Grouping int             `desc:"if > 0, all edits are saved in the current group -- see GroupStart / GroupEnd"`
+	Win      *gi.Window      `json:"-" xml:"-" desc:"window that we are the undoer for -- views in this window are updated after undo / redo"`
+	Mu       sync.Mutex      `json:"-" xml:"-" desc:"mutex protecting all updates"`
+}
+
+// ValueUndoForWin returns the ValueUndo for given window, creating and
+// installing it as the window's Undoer if not yet set.  Returns nil if window
+// is nil or has a different Undoer installed.
+func ValueUndoForWin(win *gi.Window) *ValueUndo {
+	if win == nil {
+		return nil
+	}
+	if win.Undoer == nil {
+		win.Undoer = &ValueUndo{Win: win}
+	}
+	un, _ := win.Undoer.(*ValueUndo)
+	return un
+}
+
+// ValueUndoFor returns the ValueUndo for the window of given node, or nil
+// if it is not (yet) in a window
+func ValueUndoFor(k ki.Ki) *ValueUndo {
+	if k == nil || k.This() == nil {
+		return nil
+	}
+	_, nb := gi.KiToNode2D(k)
+	if nb == nil {
+		return nil
+	}
+	return ValueUndoForWin(nb.ParentWindow())
+}
+
+// Reset clears all undo records
+func (un *ValueUndo) Reset() {
+	un.Mu.Lock()
+	valueUndoDrop(un.Stack)
+	un.Stack = nil
+	un.Pos = 0
+	un.Group = 0
+	un.Grouping = 0
+	un.Mu.Unlock()
+}
+
+// GroupStart starts an explicit undo group: all edits saved until the
+// matching GroupEnd are in one group, and thus undone and redone together.
+// Calls can be nested.
+func (un *ValueUndo) GroupStart() {
+	if un == nil {
+		return
+	}
+	un.Mu.Lock()
+	if un.Grouping == 0 {
+		un.Group++
+	}
+	un.Grouping++
+	un.Mu.Unlock()
+}
+
+// GroupEnd ends an explicit undo group started by GroupStart
+func (un *ValueUndo) GroupEnd() {
+	if un == nil {
+		return
+	}
+	un.Mu.Lock()
+	if un.Grouping > 0 {
+		un.Grouping--
+	}
+	un.Mu.Unlock()
+}
+
+// Save saves given record to the undo stack, discarding any records that
+// had been undone, and the oldest records beyond ValueUndoMax
+func (un *ValueUndo) Save(ur *ValueUndoRec) {
+	if un == nil {
+		return
+	}
+	un.Mu.Lock()
+	defer un.Mu.Unlock()
+	if un.Off {
+		return
+	}
+	if un.Grouping == 0 {
+		un.Group++
+	}
+	ur.Group = un.Group
+	if un.Pos < len(un.Stack) {
+		valueUndoDrop(un.Stack[un.Pos:])
+		un.Stack = un.Stack[:un.Pos]
+	}
+	un.Stack = append(un.Stack, ur)
+	if len(un.Stack) > ValueUndoMax {
+		valueUndoDrop(un.Stack[:len(un.Stack)-ValueUndoMax])
+		un.Stack = un.Stack[len(un.Stack)-ValueUndoMax:]
+	}
+	un.Pos = len(un.Stack)
+	if ValueUndoTrace {
+		fmt.Printf("ValueUndo: save: %v\n", ur)
+	}
+}
+
+// valueUndoDrop calls the Drop function of given records that are being
+// discarded
+func valueUndoDrop(recs []*ValueUndoRec) {
+	for _, ur := range recs {
+		if ur.Drop != nil {
+			ur.Drop()
+		}
+	}
+}
+
+// IsOff returns true if saving and using undos is turned off
+func (un *ValueUndo) IsOff() bool {
+	if un == nil {
+		return true
+	}
+	un.Mu.Lock()
+	defer un.Mu.Unlock()
+	return un.Off
+}
+
+// SetOff sets whether saving and using undos is turned off
+func (un *ValueUndo) SetOff(off bool) {
+	un.Mu.Lock()
+	un.Off = off
+	un.Mu.Unlock()
+}
+
+// CanUndo returns true if there is anything to undo
+func (un *ValueUndo) CanUndo() bool {
+	if un == nil {
+		return false
+	}
+	un.Mu.Lock()
+	defer un.Mu.Unlock()
+	return !un.Off && un.Pos > 0
+}
+
+// CanRedo returns true if there is anything to redo
+func (un *ValueUndo) CanRedo() bool {
+	if un == nil {
+		return false
+	}
+	un.Mu.Lock()
+	defer un.Mu.Unlock()
+	return !un.Off && un.Pos < len(un.Stack)
+}
+
+// Undo undoes the last group of edits, and updates the views in the window,
+// returning false if there was nothing to undo.  Satisfies gi.Undoer.
+func (un *ValueUndo) Undo() bool {
+	if !un.CanUndo() {
+		return false
+	}
+	un.Mu.Lock()
+	grp := un.Stack[un.Pos-1].Group
+	var recs []*ValueUndoRec
+	for un.Pos > 0 && un.Stack[un.Pos-1].Group == grp {
+		un.Pos--
+		recs = append(recs, un.Stack[un.Pos])
+	}
+	un.Off = true // don't record the undo itself
+	un.Mu.Unlock()
+	for _, ur := range recs {
+		if ValueUndoTrace {
+			fmt.Printf("ValueUndo: undo: %v\n", ur)
+		}
+		ur.Undo()
+	}
+	un.SetOff(false)
+	un.UpdateViews()
+	return true
+}
+
+// Redo redoes the last undone group of edits, and updates the views in the
+// window, returning false if there was nothing to redo.  Satisfies gi.Undoer.
+func (un *ValueUndo) Redo() bool {
+	if !un.CanRedo() {
+		return false
+	}
+	un.Mu.Lock()
+	grp := un.Stack[un.Pos].Group
+	var recs []*ValueUndoRec
+	for un.Pos < len(un.Stack) && un.Stack[un.Pos].Group == grp {
+		recs = append(recs, un.Stack[un.Pos])
+		un.Pos++
+	}
+	un.Off = true
+	un.Mu.Unlock()
+	for _, ur := range recs {
+		if ValueUndoTrace {
+			fmt.Printf("ValueUndo: redo: %v\n", ur)
+		}
+		ur.Redo()
+	}
+	un.SetOff(false)
+	un.UpdateViews()
+	return true
+}
+
+// UpdateViews updates all the struct, map and slice views in the window,
+// so they reflect the values after an undo or redo.  TreeView's update
+// automatically from their source trees.
+func (un *ValueUndo) UpdateViews() {
+	if un.Win == nil || un.Win.Viewport == nil {
+		return
+	}
+	vp := un.Win.Viewport
+	updt := vp.UpdateStart()
+	vp.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		switch vw := k.(type) {
+		case *StructView:
+			vw.UpdateFields()
+			vw.Validate()
+		case *StructViewInline:
+			vw.UpdateFields()
+		case *MapView:
+			vw.UpdateValues()
+			return ki.Break // rebuilt
+		case *MapViewInline:
+			vw.UpdateValues()
+			return ki.Break
+		case *SliceViewInline:
+			vw.UpdateValues()
+			return ki.Break
+		case SliceViewer:
+			vw.AsSliceViewBase().Update()
+			return ki.Break
+		}
+		return ki.Continue
+	})
+	vp.SetFullReRender()
+	vp.UpdateEnd(updt)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Saving edits
+
+// ValueUndoCopy returns a copy of given value that is not affected by
+// subsequent changes to it -- slices and maps are copied one level deep.
+func ValueUndoCopy(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		nv := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(nv, v)
+		return nv
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		nv := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			nv.SetMapIndex(k, v.MapIndex(k))
+		}
+		return nv
+	}
+	nv := reflect.New(v.Type()).Elem()
+	nv.Set(v)
+	return nv
+}
+
+// valueUndoSet sets the target value to given value
+func valueUndoSet(trg, val reflect.Value) {
+	if !val.IsValid() {
+		trg.Set(reflect.Zero(trg.Type()))
+		return
+	}
+	trg.Set(ValueUndoCopy(val))
+}
+
+// valueUndoStr returns the string representation of value, for records
+func valueUndoStr(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return kit.ToString(v.Interface())
+}
+
+// ValueViewUndoStart returns an undo record for an edit about to be made
+// by given ValueViewBase.SetValue, capturing the current value -- call
+// ValueViewUndoSave with it after the edit.  Returns nil if not recording.
+func ValueViewUndoStart(vv *ValueViewBase) *ValueUndoRec {
+	un := ValueUndoFor(vv.Widget)
+	if un.IsOff() {
+		return nil
+	}
+	desc := vv.ViewPath
+	if desc == "" {
+		desc = vv.Name()
+	}
+	ur := &ValueUndoRec{Desc: desc}
+	if vv.Owner != nil && vv.OwnKind == reflect.Map {
+		if vv.IsMapKey { // see ValueUndoMapKey
+			return nil
+		}
+		ov := kit.NonPtrValue(reflect.ValueOf(vv.Owner))
+		ck := vv.mapValueKey()
+		old := ValueUndoCopy(ov.MapIndex(ck))
+		ur.Old = valueUndoStr(old)
+		ur.Undo = func() { ov.SetMapIndex(ck, old) }
+		return ur
+	}
+	locate := vv.undoLocator()
+	trg := locate()
+	if !trg.IsValid() || !trg.CanSet() {
+		return nil
+	}
+	old := ValueUndoCopy(trg)
+	ur.Old = valueUndoStr(old)
+	tmp := vv.TmpSave
+	owner := vv.Owner
+	if kiv, ok := owner.(ki.Ki); ok && vv.OwnKind == reflect.Struct {
+		fnm := vv.Field.Name
+		ur.Undo = func() { kiv.SetField(fnm, old.Interface()) }
+		return ur
+	}
+	ur.Undo = func() {
+		if trg := locate(); trg.IsValid() {
+			valueUndoSet(trg, old)
+		}
+		if tmp != nil {
+			tmp.SaveTmp()
+		}
+		if updtr, ok := owner.(gi.Updater); ok {
+			updtr.Update()
+		}
+	}
+	return ur
+}
+
+// ValueViewUndoSave completes the undo record started by ValueViewUndoStart
+// after the edit was made, capturing the new value, and saves it to the
+// ValueUndo for the view's window.
+func ValueViewUndoSave(vv *ValueViewBase, ur *ValueUndoRec) {
+	if ur == nil {
+		return
+	}
+	un := ValueUndoFor(vv.Widget)
+	if un == nil {
+		return
+	}
+	if vv.Owner != nil && vv.OwnKind == reflect.Map {
+		ov := kit.NonPtrValue(reflect.ValueOf(vv.Owner))
+		ck := vv.mapValueKey()
+		nw := ValueUndoCopy(ov.MapIndex(ck))
+		ur.New = valueUndoStr(nw)
+		if ur.New == ur.Old {
+			return
+		}
+		ur.Redo = func() { ov.SetMapIndex(ck, nw) }
+		un.Save(ur)
+		return
+	}
+	locate := vv.undoLocator()
+	nw := ValueUndoCopy(locate())
+	ur.New = valueUndoStr(nw)
+	if ur.New == ur.Old { // no change
+		return
+	}
+	tmp := vv.TmpSave
+	owner := vv.Owner
+	if kiv, ok := owner.(ki.Ki); ok && vv.OwnKind == reflect.Struct {
+		fnm := vv.Field.Name
+		ur.Redo = func() { kiv.SetField(fnm, nw.Interface()) }
+	} else {
+		ur.Redo = func() {
+			if trg := locate(); trg.IsValid() {
+				valueUndoSet(trg, nw)
+			}
+			if tmp != nil {
+				tmp.SaveTmp()
+			}
+			if updtr, ok := owner.(gi.Updater); ok {
+				updtr.Update()
+			}
+		}
+	}
+	un.Save(ur)
+}
+
+// undoLocator returns a function that finds the value edited by this view.
+// Slice elements, and fields of structs stored by value in a slice (e.g., in
+// a TableView), are found again by their slice and index each time, as the
+// slice may be re-allocated in the meantime, e.g., by undoing an add or
+// delete of elements.
+func (vv *ValueViewBase) undoLocator() func() reflect.Value {
+	if vv.Owner != nil && vv.OwnKind == reflect.Slice {
+		owner, idx := vv.Owner, vv.Idx
+		return func() reflect.Value { return valueUndoElem(owner, idx) }
+	}
+	if vv.Owner != nil && vv.OwnKind == reflect.Struct && vv.Field != nil {
+		if sl, idx := vv.undoOwnerSlice(); sl != nil {
+			fidx := vv.Field.Index
+			return func() reflect.Value {
+				el := valueUndoElem(sl, idx)
+				if el.Kind() != reflect.Struct {
+					return reflect.Value{}
+				}
+				return kit.NonPtrValue(el.FieldByIndex(fidx))
+			}
+		}
+	}
+	trg := kit.NonPtrValue(kit.PtrValue(vv.Value))
+	return func() reflect.Value { return trg }
+}
+
+// undoOwnerSlice returns the slice that the owner of this struct field view
+// is stored in by value, and its index in the slice, if the view is within
+// a SliceViewer (e.g., TableView) -- nil otherwise
+func (vv *ValueViewBase) undoOwnerSlice() (interface{}, int) {
+	own := reflect.ValueOf(vv.Owner)
+	if vv.Widget == nil || own.Kind() != reflect.Ptr {
+		return nil, -1
+	}
+	svi, err := vv.Widget.ParentByTypeTry(KiT_SliceViewBase, ki.Embeds)
+	if err != nil {
+		return nil, -1
+	}
+	svb := svi.Embed(KiT_SliceViewBase).(*SliceViewBase)
+	sl := kit.NonPtrValue(reflect.ValueOf(svb.Slice))
+	if sl.Kind() != reflect.Slice || sl.Type().Elem().Kind() != reflect.Struct {
+		return nil, -1
+	}
+	for i := 0; i < sl.Len(); i++ {
+		if sl.Index(i).Addr().Pointer() == own.Pointer() {
+			return svb.Slice, i
+		}
+	}
+	return nil, -1
+}
+
+// valueUndoElem returns the (non-pointer) element at given index in the
+// slice pointed to by given pointer, or invalid if out of range
+func valueUndoElem(slice interface{}, idx int) reflect.Value {
+	sl := kit.NonPtrValue(reflect.ValueOf(slice))
+	if sl.Kind() != reflect.Slice || idx < 0 || idx >= sl.Len() {
+		return reflect.Value{}
+	}
+	return kit.NonPtrValue(sl.Index(idx))
+}
+
+// mapValueKey returns the current map key for a map value view
+func (vv *ValueViewBase) mapValueKey() reflect.Value {
+	if vv.KeyView != nil {
+		return kit.NonPtrValue(vv.KeyView.Val())
+	}
+	return kit.NonPtrValue(reflect.ValueOf(vv.Key))
+}
+
+// ValueUndoMapKey saves an undo record for a map key being renamed from
+// oldKey to newKey in given map, in the ValueUndo for the window of given
+// view node (e.g., the ValueView widget) -- does nothing if not in a window.
+func ValueUndoMapKey(vw ki.Ki, desc string, mp, oldKey, newKey reflect.Value) {
+	un := ValueUndoFor(vw)
+	if un.IsOff() {
+		return
+	}
+	ov := kit.NonPtrValue(mp)
+	rename := func(frm, to reflect.Value) {
+		cv := ov.MapIndex(frm)
+		ov.SetMapIndex(frm, reflect.Value{})
+		ov.SetMapIndex(to, cv)
+	}
+	un.Save(&ValueUndoRec{Desc: desc, Old: valueUndoStr(oldKey), New: valueUndoStr(newKey),
+		Undo: func() { rename(newKey, oldKey) },
+		Redo: func() { rename(oldKey, newKey) }})
+}
+
+// ValueUndoSnapshot returns a copy of the slice or map pointed to by given
+// pointer (or given map), for use in ValueUndoStru after a structural change,
+// e.g., adding or deleting elements
+func ValueUndoSnapshot(stru interface{}) reflect.Value {
+	return ValueUndoCopy(kit.NonPtrValue(reflect.ValueOf(stru)))
+}
+
+// ValueUndoStru saves an undo record for a structural change to the slice
+// or map pointed to by given pointer (or given map), where old is the
+// ValueUndoSnapshot taken before the change, in the ValueUndo for the
+// window of given view -- does nothing if not in a window.  The record is
+// described by the view path (or view name if empty) and action.  tmpSave is
+// called after the slice or map is restored, if non-nil.
+func ValueUndoStru(vw ki.Ki, path, action string, stru interface{}, old reflect.Value, tmpSave ValueView) {
+	un := ValueUndoFor(vw)
+	if un.IsOff() || !old.IsValid() {
+		return
+	}
+	if path == "" {
+		path = vw.Name()
+	}
+	desc := path + ": " + action
+	nw := ValueUndoSnapshot(stru)
+	restore := func(val reflect.Value) {
+		sv := kit.NonPtrValue(reflect.ValueOf(stru))
+		if sv.Kind() == reflect.Map {
+			for _, k := range sv.MapKeys() {
+				sv.SetMapIndex(k, reflect.Value{})
+			}
+			for _, k := range val.MapKeys() {
+				sv.SetMapIndex(k, val.MapIndex(k))
+			}
+		} else if sv.CanSet() {
+			sv.Set(ValueUndoCopy(val))
+		}
+		if tmpSave != nil {
+			tmpSave.SaveTmp()
+		}
+	}
+	un.Save(&ValueUndoRec{Desc: desc, Old: fmt.Sprintf("len: %d", old.Len()), New: fmt.Sprintf("len: %d", nw.Len()),
+		Undo: func() { restore(old) },
+		Redo: func() { restore(nw) }})
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Tree structure
+
+// ValueUndoTreeInsert saves an undo record for given node having been
+// inserted into the tree (at its current position in its parent), in the
+// ValueUndo for the window of given view -- does nothing if not in a window.
+func ValueUndoTreeInsert(vw ki.Ki, kid ki.Ki) {
+	un := ValueUndoFor(vw)
+	if un.IsOff() || kid.Parent() == nil {
+		return
+	}
+	par := kid.Parent()
+	idx, _ := kid.IndexInParent()
+	un.Save(&ValueUndoRec{Desc: "Insert: " + kid.PathUnique(),
+		Undo: func() { par.DeleteChild(kid, false) },
+		Redo: func() { valueUndoTreeAdd(par, kid, idx) },
+		Drop: func() { valueUndoTreeDestroy(kid) }})
+}
+
+// ValueUndoTreeDelete deletes given node from the tree, saving an undo
+// record in the ValueUndo for the window of given view that re-inserts it.
+// The node is destroyed immediately if undo is not active, and otherwise
+// when its undo record is discarded from the undo stack, as until then the
+// record retains it so that it can be restored.
+func ValueUndoTreeDelete(vw ki.Ki, kid ki.Ki) {
+	un := ValueUndoFor(vw)
+	par := kid.Parent()
+	if un.IsOff() || par == nil {
+		kid.Delete(true)
+		return
+	}
+	idx, _ := kid.IndexInParent()
+	un.Save(&ValueUndoRec{Desc: "Delete: " + kid.PathUnique(),
+		Undo: func() { valueUndoTreeAdd(par, kid, idx) },
+		Redo: func() { par.DeleteChild(kid, false) },
+		Drop: func() { valueUndoTreeDestroy(kid) }})
+	par.DeleteChild(kid, false)
+}
+
+// ValueUndoTreeAssign saves an undo record for given node having its
+// contents replaced (e.g., by CopyFrom), where old is a Clone of the node
+// taken prior to the change, in the ValueUndo for the window of given view.
+func ValueUndoTreeAssign(vw ki.Ki, node ki.Ki, old ki.Ki) {
+	un := ValueUndoFor(vw)
+	if un.IsOff() {
+		return
+	}
+	nw := node.Clone()
+	un.Save(&ValueUndoRec{Desc: "Assign: " + node.PathUnique(),
+		Undo: func() { node.CopyFrom(old) },
+		Redo: func() { node.CopyFrom(nw) }})
+}
+
+// valueUndoTreeAdd re-inserts previously deleted node into parent at index
+func valueUndoTreeAdd(par, kid ki.Ki, idx int) {
+	kid.ClearFlag(int(ki.NodeDeleted))
+	if idx > par.NumChildren() {
+		idx = par.NumChildren()
+	}
+	par.InsertChild(kid, idx)
+}
+
+// valueUndoTreeDestroy destroys given node if it is not currently in the
+// tree, when its undo record is discarded
+func valueUndoTreeDestroy(kid ki.Ki) {
+	if kid.This() != nil && kid.Parent() == nil {
+		kid.Destroy()
+	}
+}
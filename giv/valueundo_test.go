@@ -0,0 +1,147 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"reflect"
+	"testing"
+)
+
+// undoTestSet sets *v to nw, saving an undo record for it in un
+func undoTestSet(un *ValueUndo, v *int, nw int, dropped *[]int) {
+	old := *v
+	*v = nw
+	un.Save(&ValueUndoRec{Desc: "v", Undo: func() { *v = old }, Redo: func() { *v = nw },
+		Drop: func() { *dropped = append(*dropped, nw) }})
+}
+
+func TestValueUndo(t *testing.T) {
+	un := &ValueUndo{}
+	var dropped []int
+	v := 0
+	if un.Undo() || un.Redo() {
+		t.Errorf("Undo / Redo on empty stack returned true")
+	}
+	undoTestSet(un, &v, 1, &dropped)
+	undoTestSet(un, &v, 2, &dropped)
+	if !un.Undo() || v != 1 {
+		t.Errorf("Undo: v = %v, expected 1", v)
+	}
+	if !un.Undo() || v != 0 || un.CanUndo() {
+		t.Errorf("Undo: v = %v, expected 0 and nothing more to undo", v)
+	}
+	if !un.Redo() || v != 1 {
+		t.Errorf("Redo: v = %v, expected 1", v)
+	}
+	if !un.Redo() || v != 2 || un.CanRedo() {
+		t.Errorf("Redo: v = %v, expected 2 and nothing more to redo", v)
+	}
+
+	// the undo itself is not recorded
+	un.Undo()
+	if len(un.Stack) != 2 || un.Pos != 1 {
+		t.Errorf("Undo: stack len %v pos %v, expected 2 1", len(un.Stack), un.Pos)
+	}
+
+	// a new edit drops the undone edit, which can no longer be redone
+	undoTestSet(un, &v, 3, &dropped)
+	if un.CanRedo() || un.Redo() || v != 3 {
+		t.Errorf("Redo after new edit: v = %v, expected 3 and nothing to redo", v)
+	}
+	if !reflect.DeepEqual(dropped, []int{2}) {
+		t.Errorf("new edit: dropped %v, expected [2]", dropped)
+	}
+	un.Undo()
+	if v != 1 {
+		t.Errorf("Undo after new edit: v = %v, expected 1", v)
+	}
+
+	un.SetOff(true)
+	undoTestSet(un, &v, 4, &dropped)
+	if un.CanUndo() || un.CanRedo() || len(un.Stack) != 2 {
+		t.Errorf("Off: edit saved or undo active")
+	}
+	un.SetOff(false)
+
+	un.Reset()
+	if len(un.Stack) != 0 || un.CanUndo() || !reflect.DeepEqual(dropped, []int{2, 1, 3}) {
+		t.Errorf("Reset: stack len %v, dropped %v, expected 0, [2 1 3]", len(un.Stack), dropped)
+	}
+}
+
+func TestValueUndoGroup(t *testing.T) {
+	un := &ValueUndo{}
+	var dropped []int
+	v := 0
+	undoTestSet(un, &v, 1, &dropped)
+	un.GroupStart()
+	undoTestSet(un, &v, 2, &dropped)
+	un.GroupStart() // nested
+	undoTestSet(un, &v, 3, &dropped)
+	un.GroupEnd()
+	undoTestSet(un, &v, 4, &dropped)
+	un.GroupEnd()
+	undoTestSet(un, &v, 5, &dropped)
+
+	grps := make([]int, len(un.Stack))
+	for i, ur := range un.Stack {
+		grps[i] = ur.Group
+	}
+	if exp := []int{1, 2, 2, 2, 3}; !reflect.DeepEqual(grps, exp) {
+		t.Errorf("groups: got %v, expected %v", grps, exp)
+	}
+	un.Undo()
+	if v != 4 {
+		t.Errorf("Undo: v = %v, expected 4", v)
+	}
+	un.Undo() // whole group
+	if v != 1 || un.Pos != 1 {
+		t.Errorf("Undo group: v = %v pos %v, expected 1 1", v, un.Pos)
+	}
+	un.Redo()
+	if v != 4 || un.Pos != 4 {
+		t.Errorf("Redo group: v = %v pos %v, expected 4 4", v, un.Pos)
+	}
+}
+
+func TestValueUndoMax(t *testing.T) {
+	svmax := ValueUndoMax
+	ValueUndoMax = 3
+	defer func() { ValueUndoMax = svmax }()
+	un := &ValueUndo{}
+	var dropped []int
+	v := 0
+	for i := 1; i <= 5; i++ {
+		undoTestSet(un, &v, i, &dropped)
+	}
+	if len(un.Stack) != 3 || !reflect.DeepEqual(dropped, []int{1, 2}) {
+		t.Errorf("ValueUndoMax: stack len %v, dropped %v, expected 3, [1 2]", len(un.Stack), dropped)
+	}
+	for un.Undo() {
+	}
+	if v != 2 {
+		t.Errorf("ValueUndoMax: undo all: v = %v, expected 2", v)
+	}
+}
+
+func TestValueUndoCopy(t *testing.T) {
+	sl := []int{1, 2, 3}
+	cp := ValueUndoCopy(reflect.ValueOf(sl)).Interface().([]int)
+	sl[0] = 10
+	if cp[0] != 1 {
+		t.Errorf("ValueUndoCopy slice: copy changed with original: %v", cp)
+	}
+	mp := map[string]int{"a": 1}
+	mc := ValueUndoCopy(reflect.ValueOf(mp)).Interface().(map[string]int)
+	mp["a"] = 2
+	mp["b"] = 3
+	if !reflect.DeepEqual(mc, map[string]int{"a": 1}) {
+		t.Errorf("ValueUndoCopy map: copy changed with original: %v", mc)
+	}
+	var nsl []int
+	if cv := ValueUndoCopy(reflect.ValueOf(nsl)); !cv.IsNil() {
+		t.Errorf("ValueUndoCopy nil slice: got non-nil")
+	}
+}
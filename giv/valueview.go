@@ -615,6 +615,7 @@ func (vv *ValueViewBase) SetValue(val interface{}) bool {
 	if vv.This().(ValueView).IsInactive() {
 		return false
 	}
+	undo := ValueViewUndoStart(vv)
 	rval := false
 	if vv.Owner != nil {
 		switch vv.OwnKind {
@@ -649,6 +650,7 @@ func (vv *ValueViewBase) SetValue(val interface{}) bool {
 									vp.SetNeedsFullRender()
 								}
 							case 1:
+								ValueUndoMapKey(vv.Widget, vv.ViewPath, ov, kv, nv)
 								cv := ov.MapIndex(kv)               // get current value
 								ov.SetMapIndex(kv, reflect.Value{}) // delete old key
 								ov.SetMapIndex(nv, cv)              // set new key to current value
@@ -662,6 +664,9 @@ func (vv *ValueViewBase) SetValue(val interface{}) bool {
 						})
 					return false // abort this action right now
 				}
+				if val != kv.Interface() {
+					ValueUndoMapKey(vv.Widget, vv.ViewPath, ov, kv, nv)
+				}
 				ov.SetMapIndex(kv, reflect.Value{}) // delete old key
 				ov.SetMapIndex(nv, cv)              // set new key to current value
 				vv.Value = nv                       // update value to new key
@@ -688,6 +693,7 @@ func (vv *ValueViewBase) SetValue(val interface{}) bool {
 	}
 	if rval {
 		vv.This().(ValueView).SaveTmp()
+		ValueViewUndoSave(vv, undo)
 	}
 	// fmt.Printf("value view: %T sending for setting val %v\n", vv.This(), val)
 	vv.ViewSig.Emit(vv.This(), 0, nil)
@@ -997,6 +1003,10 @@ func (vi *ViewIFace) PrefsDbgView(prefs *gi.PrefsDebug) {
 	PrefsDbgView(prefs)
 }
 
+func (vi *ViewIFace) CommandPalette(win *gi.Window) {
+	CommandPaletteDialog(win)
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////
 //  VersCtrlValueView
 
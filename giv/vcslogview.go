@@ -67,6 +67,7 @@ func (lv *VCSLogView) Config(repo vci.Repo, lg vci.Log, file, since string) {
 	}
 	tv.SetStretchMax()
 	tv.SetInactive()
+	tv.SetProp("filter", true)
 	tv.SetSlice(&lv.Log)
 	lv.UpdateEnd(updt)
 }
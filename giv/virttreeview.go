@@ -0,0 +1,767 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"image/color"
+	"reflect"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  VirtTreeView
+
+// VirtTreeView is a virtualized, select-only view of a Ki tree, for trees
+// that are too large to view with a TreeView, which creates widgets for
+// every node.  The open portion of the tree is flattened into a list of
+// Rows, and only the rows that are visible are rendered, using the same
+// scroll-backed grid as SliceViewBase.  Nodes implementing TreeLazyLoader
+// load their children when first opened.  Emits TreeViewSig signals with
+// data = the source node, and WidgetSig WidgetSelected with the row index.
+// The tree cannot be edited through this view: there is no insert, delete,
+// duplicate, cut / paste, drag-and-drop or ValueUndo of tree changes, so it
+// is only used where asked for, e.g., GiEditor.VirtTree.  FileNode loads
+// directories lazily in a regular FileTreeView, which needs those edits,
+// and there is no virtualized FileTreeView.
+type VirtTreeView struct {
+	SliceViewBase
+	RootNode    ki.Ki              `copy:"-" json:"-" xml:"-" desc:"root of the source tree that we are viewing"`
+	Rows        []VirtTreeRow      `copy:"-" view:"-" json:"-" xml:"-" desc:"flattened list of visible (open) nodes in the tree -- this is the slice that is viewed"`
+	OpenNodes   map[ki.Ki]struct{} `copy:"-" view:"-" json:"-" xml:"-" desc:"nodes that are open -- their children are shown in Rows if they themselves are shown"`
+	OpenDepth   int                `xml:"open-depth" desc:"styled depth for nodes be initialized as open -- nodes beyond this depth will be initialized as closed, as are nodes with lazy children.  initial default is 4."`
+	Indent      units.Value        `xml:"indent" desc:"styled amount to indent children relative to their parent"`
+	TreeViewSig ki.Signal          `copy:"-" json:"-" xml:"-" desc:"signal for the tree -- data = affected source node -- see TreeViewSignals for the types"`
+	conns       map[ki.Ki]struct{} `copy:"-" view:"-" json:"-" xml:"-" desc:"source nodes that we receive node signals from"`
+	loading     bool               `copy:"-" view:"-" json:"-" xml:"-" desc:"true while loading lazy children -- source signals are ignored"`
+}
+
+var KiT_VirtTreeView = kit.Types.AddType(&VirtTreeView{}, VirtTreeViewProps)
+
+// AddNewVirtTreeView adds a new virttreeview to given parent node, with given name.
+func AddNewVirtTreeView(parent ki.Ki, name string) *VirtTreeView {
+	return parent.AddNewChild(KiT_VirtTreeView, name).(*VirtTreeView)
+}
+
+// check for interface impl
+var _ SliceViewer = (*VirtTreeView)(nil)
+
+// VirtTreeRow is one row of a VirtTreeView: a source node and its depth
+// within the tree.
+type VirtTreeRow struct {
+	Node  ki.Ki `desc:"source node shown in this row"`
+	Depth int   `desc:"depth of the node within the tree -- root is 0"`
+}
+
+var VirtTreeViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"max-width":        -1,
+	"max-height":       -1,
+	"indent":           units.NewCh(4),
+	"open-depth":       4,
+}
+
+// VirtTreeViewBranchProps are the properties of the branch open / close
+// checkbox in each row -- leaf rows have a blank space of the same width.
+var VirtTreeViewBranchProps = ki.Props{
+	"icon":             "wedge-down",
+	"icon-off":         "wedge-right",
+	"margin":           units.NewPx(0),
+	"padding":          units.NewPx(0),
+	"background-color": color.Transparent,
+	"width":            units.NewEm(.8),
+	"min-width":        units.NewEm(.8),
+	"max-width":        units.NewEm(.8),
+	"max-height":       units.NewEm(.8),
+	"#icon0":           TVBranchProps,
+	"#icon1":           TVBranchProps,
+}
+
+// VirtTreeViewLabelProps are the properties of the label in each row
+var VirtTreeViewLabelProps = ki.Props{
+	"margin":    units.NewPx(0),
+	"padding":   units.NewPx(0),
+	"min-width": units.NewCh(16),
+}
+
+func (vt *VirtTreeView) Disconnect() {
+	vt.SliceViewBase.Disconnect()
+	vt.TreeViewSig.DisconnectAll()
+	for k := range vt.conns {
+		k.NodeSignal().Disconnect(vt.This())
+	}
+	vt.conns = nil
+}
+
+// SetRootNode sets the root of the source tree that we are viewing, opening
+// nodes down to OpenDepth, and configures the view.
+func (vt *VirtTreeView) SetRootNode(root ki.Ki) {
+	updt := vt.UpdateStart()
+	vt.RootNode = root
+	if od, ok := vt.PropInherit("open-depth", ki.NoInherit, ki.TypeProps); ok {
+		if iv, ok := kit.ToInt(od); ok {
+			vt.OpenDepth = int(iv)
+		}
+	}
+	vt.OpenNodes = make(map[ki.Ki]struct{})
+	if root != nil {
+		vt.openToDepth(root, 0)
+	}
+	vt.SetInactive()
+	vt.StartIdx = 0
+	vt.SelectedIdx = -1
+	vt.ResetSelectedIdxs()
+	vt.SelectMode = false
+	vt.ShowIndex = false
+	vt.InactKeyNav = true
+	vt.Rebuild()
+	vt.Slice = &vt.Rows
+	vt.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(vt.Slice))
+	vt.SetFullReRender()
+	vt.Config()
+	vt.UpdateEnd(updt)
+}
+
+// openToDepth opens given node and its descendants down to OpenDepth,
+// skipping nodes whose children have not been loaded
+func (vt *VirtTreeView) openToDepth(k ki.Ki, depth int) {
+	if depth >= vt.OpenDepth || HasLazyChildren(k) || !vt.NodeHasKids(k) {
+		return
+	}
+	vt.OpenNodes[k] = struct{}{}
+	vt.funcKids(k, func(kid ki.Ki) {
+		vt.openToDepth(kid, depth+1)
+	})
+}
+
+// funcKids calls given function on the Ki fields and then the children of
+// given node, which is the order in which they are shown, as in TreeView.
+func (vt *VirtTreeView) funcKids(k ki.Ki, fun func(kid ki.Ki)) {
+	nf := k.NumKiFields()
+	for i := 0; i < nf; i++ {
+		fun(k.KiField(i))
+	}
+	for _, kid := range *k.Children() {
+		fun(kid)
+	}
+}
+
+// NodeHasKids returns true if given node has children or Ki fields that are
+// shown under it when open.
+func (vt *VirtTreeView) NodeHasKids(k ki.Ki) bool {
+	return k.HasChildren() || k.HasKiFields()
+}
+
+// NodeHasBranch returns true if given node can be opened: it has children,
+// or it has lazy children that have not been loaded (see TreeLazyLoader).
+func (vt *VirtTreeView) NodeHasBranch(k ki.Ki) bool {
+	return vt.NodeHasKids(k) || HasLazyChildren(k)
+}
+
+// IsNodeOpen returns true if given node is open
+func (vt *VirtTreeView) IsNodeOpen(k ki.Ki) bool {
+	_, open := vt.OpenNodes[k]
+	return open
+}
+
+// Rebuild rebuilds the flattened list of Rows from the source tree,
+// preserving the selection of nodes that are still shown.  Call Update
+// after this to update the display.
+func (vt *VirtTreeView) Rebuild() {
+	vt.ViewMuLock()
+	defer vt.ViewMuUnlock()
+
+	var cur ki.Ki
+	if vt.SelectedIdx >= 0 && vt.SelectedIdx < len(vt.Rows) {
+		cur = vt.Rows[vt.SelectedIdx].Node
+	}
+	sels := make(map[ki.Ki]struct{}, len(vt.SelectedIdxs))
+	for idx := range vt.SelectedIdxs {
+		if idx < len(vt.Rows) {
+			sels[vt.Rows[idx].Node] = struct{}{}
+		}
+	}
+	for k := range vt.OpenNodes {
+		if k.This() == nil || k.IsDeleted() || k.IsDestroyed() {
+			delete(vt.OpenNodes, k)
+		}
+	}
+	vt.Rows = vt.Rows[:0]
+	if vt.RootNode != nil && vt.RootNode.This() != nil {
+		vt.flatten(vt.RootNode, 0)
+	}
+	vt.SelectedIdx = -1
+	vt.ResetSelectedIdxs()
+	if cur != nil || len(sels) > 0 {
+		for i := range vt.Rows {
+			nd := vt.Rows[i].Node
+			if nd == cur {
+				vt.SelectedIdx = i
+			}
+			if _, sel := sels[nd]; sel {
+				vt.SelectedIdxs[i] = struct{}{}
+			}
+		}
+	}
+	vt.syncConns()
+}
+
+// flatten adds given node and its open descendants to Rows
+func (vt *VirtTreeView) flatten(k ki.Ki, depth int) {
+	vt.Rows = append(vt.Rows, VirtTreeRow{Node: k, Depth: depth})
+	if !vt.IsNodeOpen(k) {
+		return
+	}
+	vt.funcKids(k, func(kid ki.Ki) {
+		vt.flatten(kid, depth+1)
+	})
+}
+
+// syncConns makes sure we receive node signals from the root and all open
+// nodes shown in Rows, whose children determine the Rows, and disconnects
+// from all others -- visible rows are connected in UpdateSliceGrid.
+func (vt *VirtTreeView) syncConns() {
+	want := make(map[ki.Ki]struct{}, len(vt.OpenNodes)+1)
+	if vt.RootNode != nil && vt.RootNode.This() != nil {
+		want[vt.RootNode] = struct{}{}
+	}
+	for i := range vt.Rows {
+		nd := vt.Rows[i].Node
+		if vt.IsNodeOpen(nd) {
+			want[nd] = struct{}{}
+		}
+	}
+	for k := range vt.conns {
+		if _, ok := want[k]; !ok {
+			k.NodeSignal().Disconnect(vt.This())
+			delete(vt.conns, k)
+		}
+	}
+	for k := range want {
+		vt.connectNode(k)
+	}
+}
+
+// connectNode connects to node signals from given source node
+func (vt *VirtTreeView) connectNode(k ki.Ki) {
+	if vt.conns == nil {
+		vt.conns = make(map[ki.Ki]struct{})
+	}
+	if _, has := vt.conns[k]; has {
+		return
+	}
+	vt.conns[k] = struct{}{}
+	k.NodeSignal().Connect(vt.This(), VirtTreeViewSrcSignalFunc)
+}
+
+// VirtTreeViewSrcSignalFunc is the function for receiving node signals from
+// the source nodes -- structural changes rebuild the Rows
+func VirtTreeViewSrcSignalFunc(recv, send ki.Ki, sig int64, data interface{}) {
+	vt := recv.Embed(KiT_VirtTreeView).(*VirtTreeView)
+	if vt.loading {
+		return
+	}
+	if sig == int64(ki.NodeSignalDeleting) {
+		delete(vt.conns, send)
+		return // parent update does the rest
+	}
+	dflags, ok := data.(int64)
+	if !ok {
+		return
+	}
+	if bitflag.HasAnyMask(dflags, int64(ki.StruUpdateFlagsMask)) {
+		vt.Rebuild()
+	}
+	if vt.IsConfiged() {
+		vt.Update()
+	}
+}
+
+// OpenNode opens given node, loading its children if it has lazy children,
+// and updates the display.
+func (vt *VirtTreeView) OpenNode(k ki.Ki) {
+	if vt.IsNodeOpen(k) {
+		return
+	}
+	vt.loading = true
+	LoadLazyChildren(k)
+	vt.loading = false
+	vt.OpenNodes[k] = struct{}{}
+	vt.Rebuild()
+	vt.Update()
+	vt.TreeViewSig.Emit(vt.This(), int64(TreeViewOpened), k)
+}
+
+// CloseNode closes given node and updates the display.
+func (vt *VirtTreeView) CloseNode(k ki.Ki) {
+	if !vt.IsNodeOpen(k) {
+		return
+	}
+	delete(vt.OpenNodes, k)
+	vt.Rebuild()
+	vt.Update()
+	vt.TreeViewSig.Emit(vt.This(), int64(TreeViewClosed), k)
+}
+
+// ToggleNode toggles the open / closed status of given node
+func (vt *VirtTreeView) ToggleNode(k ki.Ki) {
+	if vt.IsNodeOpen(k) {
+		vt.CloseNode(k)
+	} else {
+		vt.OpenNode(k)
+	}
+}
+
+// OpenAll opens given node and all of its descendants that have children
+// -- lazy children are not loaded.
+func (vt *VirtTreeView) OpenAll(k ki.Ki) {
+	k.FuncDownMeFirst(0, nil, func(kn ki.Ki, level int, d interface{}) bool {
+		vt.openFields(kn)
+		return ki.Continue
+	})
+	vt.Rebuild()
+	vt.Update()
+	vt.TreeViewSig.Emit(vt.This(), int64(TreeViewOpened), k)
+}
+
+// openFields opens given node if it has kids, and its Ki fields, which are
+// not visited by FuncDownMeFirst
+func (vt *VirtTreeView) openFields(k ki.Ki) {
+	if !vt.NodeHasKids(k) {
+		return
+	}
+	vt.OpenNodes[k] = struct{}{}
+	nf := k.NumKiFields()
+	for i := 0; i < nf; i++ {
+		vt.openFields(k.KiField(i))
+	}
+}
+
+// CloseAll closes given node and all of its descendants
+func (vt *VirtTreeView) CloseAll(k ki.Ki) {
+	for kn := range vt.OpenNodes {
+		if kn == k || kn.HasParent(k) {
+			delete(vt.OpenNodes, kn)
+		}
+	}
+	vt.Rebuild()
+	vt.Update()
+	vt.TreeViewSig.Emit(vt.This(), int64(TreeViewClosed), k)
+}
+
+// NodeIdx returns the index of given node in Rows, or -1 if not shown
+func (vt *VirtTreeView) NodeIdx(k ki.Ki) int {
+	for i := range vt.Rows {
+		if vt.Rows[i].Node == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// SelectNode opens the parents of given node as needed, and selects and
+// scrolls to it -- returns false if it is not within our tree.
+func (vt *VirtTreeView) SelectNode(k ki.Ki) bool {
+	if vt.RootNode == nil || (k != vt.RootNode && !k.HasParent(vt.RootNode)) {
+		return false
+	}
+	k.FuncUpParent(0, nil, func(kn ki.Ki, level int, d interface{}) bool {
+		vt.OpenNodes[kn] = struct{}{}
+		return kn != vt.RootNode
+	})
+	vt.Rebuild()
+	vt.Update()
+	idx := vt.NodeIdx(k)
+	if idx < 0 {
+		return false
+	}
+	vt.ScrollToIdx(idx)
+	vt.SelectIdxAction(idx, mouse.SelectOne)
+	return true
+}
+
+// SelectedNodes returns the source nodes of the selected rows, in order
+func (vt *VirtTreeView) SelectedNodes() []ki.Ki {
+	idxs := vt.SelectedIdxsList(false)
+	sl := make([]ki.Ki, 0, len(idxs))
+	for _, idx := range idxs {
+		if idx < len(vt.Rows) {
+			sl = append(sl, vt.Rows[idx].Node)
+		}
+	}
+	return sl
+}
+
+// NodeLabel returns the label shown for given node: its Labeler label if
+// it has one, and otherwise its name
+func (vt *VirtTreeView) NodeLabel(k ki.Ki) string {
+	if lbl, has := gi.ToLabeler(k); has {
+		return lbl
+	}
+	return k.Name()
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  SliceViewer interface
+
+// Config configures the overall Frame, which has no toolbar
+func (vt *VirtTreeView) Config() {
+	vt.Lay = gi.LayoutVert
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Layout, "grid-lay")
+	mods, updt := vt.ConfigChildren(config, ki.UniqueNames)
+
+	gl := vt.GridLayout()
+	gl.Lay = gi.LayoutHoriz
+	gl.SetStretchMax() // for this to work, ALL layers above need it too
+	gconfig := kit.TypeAndNameList{}
+	gconfig.Add(gi.KiT_Frame, "grid")
+	gconfig.Add(gi.KiT_ScrollBar, "scrollbar")
+	gl.ConfigChildren(gconfig, ki.UniqueNames) // covered by above
+
+	vt.ConfigSliceGrid()
+	vt.WidgetSig.Connect(vt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.WidgetSelected) {
+			return
+		}
+		vtv := recv.Embed(KiT_VirtTreeView).(*VirtTreeView)
+		idx, _ := data.(int)
+		if idx >= 0 && idx < len(vtv.Rows) {
+			vtv.TreeViewSig.Emit(vtv.This(), int64(TreeViewSelected), vtv.Rows[idx].Node)
+		}
+	})
+	if mods {
+		vt.SetFullReRender()
+		vt.UpdateEnd(updt)
+	}
+}
+
+// RowWidgetNs returns number of widgets per row and offset for index label
+// -- each row is a single layout with the branch and label
+func (vt *VirtTreeView) RowWidgetNs() (nWidgPerRow, idxOff int) {
+	return 1, 0
+}
+
+// ConfigSliceGrid configures the SliceGrid for the current rows
+func (vt *VirtTreeView) ConfigSliceGrid() {
+	sg := vt.SliceGrid()
+	updt := sg.UpdateStart()
+	defer sg.UpdateEnd(updt)
+
+	sg.Lay = gi.LayoutGrid
+	sg.Stripes = gi.NoStripes
+	sg.SetProp("columns", 1)
+	// setting a pref here is key for giving it a scrollbar in larger context
+	sg.SetMinPrefHeight(units.NewEm(6))
+	sg.SetMinPrefWidth(units.NewCh(20))
+	sg.SetStretchMax()                        // for this to work, ALL layers above need it too
+	sg.SetProp("overflow", gi.OverflowScroll) // this still gives it true size during PrefSize
+
+	sz := vt.UpdtSliceSize()
+	if sz == 0 {
+		return
+	}
+	sg.DeleteChildren(ki.DestroyKids)
+	sg.Kids = make(ki.Slice, 1)
+	// one dummy row to get size of widgets
+	rw := vt.newRowWidget(sg, 0)
+	vt.setRowWidget(rw, 0, false)
+	vt.ConfigScroll()
+}
+
+// newRowWidget makes a new row layout at given display row in the grid
+func (vt *VirtTreeView) newRowWidget(sg *gi.Frame, row int) *gi.Layout {
+	rw := &gi.Layout{}
+	sg.SetChild(rw, row, fmt.Sprintf("row-%05d", row))
+	rw.Lay = gi.LayoutHoriz
+	rw.SetProp("vtv-row", row)
+	rw.SetProp("spacing", units.NewCh(.5))
+	rw.SetStretchMaxWidth()
+	return rw
+}
+
+// setRowWidget configures given row layout to show the row at given index
+func (vt *VirtTreeView) setRowWidget(rw *gi.Layout, idx int, sel bool) {
+	vr := vt.Rows[idx]
+	hasBr := vt.NodeHasBranch(vr.Node)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Space, "indent")
+	if hasBr {
+		config.Add(gi.KiT_CheckBox, "branch")
+	} else {
+		config.Add(gi.KiT_Space, "leaf")
+	}
+	config.Add(gi.KiT_Label, "label")
+	mods, updt := rw.ConfigChildren(config, ki.UniqueNames)
+	if mods {
+		if hasBr {
+			wb := rw.Child(1).(*gi.CheckBox)
+			wb.SetProps(VirtTreeViewBranchProps, ki.NoUpdate)
+			wb.SetProp("no-focus", true) // note: cannot be in compiled props
+			wb.ButtonSig.ConnectOnly(vt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig != int64(gi.ButtonToggled) {
+					return
+				}
+				vtv := recv.Embed(KiT_VirtTreeView).(*VirtTreeView)
+				if ridx, ok := vtv.rowIdx(send.Parent()); ok {
+					vtv.ToggleNode(vtv.Rows[ridx].Node)
+				}
+			})
+		} else {
+			rw.Child(1).(*gi.Space).SetFixedWidth(units.NewEm(.8))
+		}
+		lbl := rw.Child(2).(*gi.Label)
+		lbl.SetProps(VirtTreeViewLabelProps, ki.NoUpdate)
+		lbl.Redrawable = true
+		lbl.SetStretchMaxWidth()
+	}
+	vt.Indent.SetFmInheritProp("indent", vt.This(), ki.NoInherit, ki.TypeProps)
+	ind := rw.Child(0).(*gi.Space)
+	ind.SetFixedWidth(units.NewValue(float32(vr.Depth)*vt.Indent.Val, vt.Indent.Un))
+	if hasBr {
+		rw.Child(1).(*gi.CheckBox).SetChecked(vt.IsNodeOpen(vr.Node))
+	}
+	lbl := rw.Child(2).(*gi.Label)
+	lbl.CurBgColor = gi.Prefs.Colors.Background
+	lbl.SetText(vt.NodeLabel(vr.Node))
+	lbl.SetSelectedState(sel)
+	rw.UpdateEnd(updt)
+}
+
+// rowIdx returns the index in Rows of the row shown by given row layout
+func (vt *VirtTreeView) rowIdx(rw ki.Ki) (int, bool) {
+	if rw == nil {
+		return -1, false
+	}
+	rp, err := rw.PropTry("vtv-row")
+	if err != nil {
+		return -1, false
+	}
+	idx := rp.(int) + vt.StartIdx
+	if idx >= len(vt.Rows) {
+		return -1, false
+	}
+	return idx, true
+}
+
+// UpdateSliceGrid updates the rows shown in the grid -- robust to any time calling
+func (vt *VirtTreeView) UpdateSliceGrid() {
+	if kit.IfaceIsNil(vt.Slice) {
+		return
+	}
+	vt.ViewMuLock()
+	defer vt.ViewMuUnlock()
+
+	sz := vt.UpdtSliceSize()
+	if sz == 0 {
+		return
+	}
+	sg := vt.SliceGrid()
+	vt.DispRows = ints.MinInt(vt.SliceSize, vt.VisRows)
+
+	wupdt := vt.TopUpdateStart()
+	defer vt.TopUpdateEnd(wupdt)
+
+	updt := sg.UpdateStart()
+	defer sg.UpdateEnd(updt)
+
+	if vt.Values == nil || sg.NumChildren() != vt.DispRows {
+		vt.ViewMuUnlock()
+		vt.LayoutSliceGrid()
+		vt.ViewMuLock()
+	}
+
+	if sz > vt.DispRows {
+		sb := vt.ScrollBar()
+		vt.StartIdx = int(sb.Value)
+		lastSt := sz - vt.DispRows
+		vt.StartIdx = ints.MinInt(lastSt, vt.StartIdx)
+		vt.StartIdx = ints.MaxInt(0, vt.StartIdx)
+	} else {
+		vt.StartIdx = 0
+	}
+
+	for i := 0; i < vt.DispRows; i++ {
+		si := vt.StartIdx + i
+		var rw *gi.Layout
+		if sg.Kids[i] != nil {
+			rw = sg.Kids[i].(*gi.Layout)
+		} else {
+			rw = vt.newRowWidget(sg, i)
+		}
+		vt.setRowWidget(rw, si, vt.IdxIsSelected(si) || si == vt.SelectedIdx)
+		vt.connectNode(vt.Rows[si].Node)
+	}
+	vt.UpdateScroll()
+	vt.SetFullReRender() // indents and branches change with scrolling
+}
+
+// StyleRow is not used -- rows are styled by setRowWidget
+func (vt *VirtTreeView) StyleRow(svnp reflect.Value, widg gi.Node2D, idx, fidx int, vv ValueView) {
+}
+
+// RowFirstWidget returns the row layout for given display row -- false if
+// out of range
+func (vt *VirtTreeView) RowFirstWidget(row int) (*gi.WidgetBase, bool) {
+	if !vt.IsRowInBounds(row) {
+		return nil, false
+	}
+	sg := vt.SliceGrid()
+	if sg.Kids.IsValidIndex(row) != nil || sg.Kids[row] == nil {
+		return nil, false
+	}
+	return sg.Kids[row].(gi.Node2D).AsWidget(), true
+}
+
+// SelectRowWidgets sets the selection state of the label in given row
+func (vt *VirtTreeView) SelectRowWidgets(row int, sel bool) {
+	sg := vt.SliceGrid()
+	if row < 0 || sg.Kids.IsValidIndex(row) != nil || sg.Kids[row] == nil {
+		return
+	}
+	rw := sg.Kids[row].(*gi.Layout)
+	if rw.NumChildren() < 3 {
+		return
+	}
+	wupdt := vt.TopUpdateStart()
+	lbl := rw.Child(2).(*gi.Label)
+	lbl.SetSelectedState(sel)
+	lbl.UpdateSig()
+	vt.TopUpdateEnd(wupdt)
+}
+
+// ItemCtxtMenu pops up the context menu for the node at given index,
+// including open / close actions and the node's own inactive CtxtMenu
+func (vt *VirtTreeView) ItemCtxtMenu(idx int) {
+	if idx < 0 || idx >= len(vt.Rows) {
+		return
+	}
+	nd := vt.Rows[idx].Node
+	var men gi.Menu
+	CtxtMenuView(nd, true, vt.ViewportSafe(), &men)
+	if vt.NodeHasBranch(nd) {
+		if len(men) > 0 {
+			men.AddSeparator("sep-vtv")
+		}
+		if vt.IsNodeOpen(nd) {
+			men.AddAction(gi.ActOpts{Label: "Close", Data: nd},
+				vt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					vtv := recv.Embed(KiT_VirtTreeView).(*VirtTreeView)
+					vtv.CloseNode(data.(ki.Ki))
+				})
+		} else {
+			men.AddAction(gi.ActOpts{Label: "Open", Data: nd},
+				vt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					vtv := recv.Embed(KiT_VirtTreeView).(*VirtTreeView)
+					vtv.OpenNode(data.(ki.Ki))
+				})
+		}
+		men.AddAction(gi.ActOpts{Label: "Open All", Data: nd},
+			vt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				vtv := recv.Embed(KiT_VirtTreeView).(*VirtTreeView)
+				vtv.OpenAll(data.(ki.Ki))
+			})
+		men.AddAction(gi.ActOpts{Label: "Close All", Data: nd},
+			vt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				vtv := recv.Embed(KiT_VirtTreeView).(*VirtTreeView)
+				vtv.CloseAll(data.(ki.Ki))
+			})
+	}
+	if len(men) > 0 {
+		pos := vt.IdxPos(idx)
+		gi.PopupMenu(men, pos.X, pos.Y, vt.ViewportSafe(), vt.Nm+"-menu")
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//    Events
+
+// KeyInput handles the tree-specific keys: right / left open and close the
+// selected node, and enter toggles it -- others are handled by SliceViewBase.
+func (vt *VirtTreeView) KeyInput(kt *key.ChordEvent) {
+	idx := vt.SelectedIdx
+	if idx < 0 || idx >= len(vt.Rows) {
+		return
+	}
+	nd := vt.Rows[idx].Node
+	kf := gi.KeyFun(kt.Chord())
+	switch kf {
+	case gi.KeyFunMoveRight:
+		vt.OpenNode(nd)
+		kt.SetProcessed()
+	case gi.KeyFunMoveLeft:
+		if vt.IsNodeOpen(nd) {
+			vt.CloseNode(nd)
+		} else if par := nd.Parent(); par != nil && nd != vt.RootNode {
+			vt.SelectNode(par)
+		}
+		kt.SetProcessed()
+	case gi.KeyFunEnter, gi.KeyFunAccept:
+		vt.ToggleNode(nd)
+		kt.SetProcessed()
+	}
+}
+
+func (vt *VirtTreeView) ConnectEvents2D() {
+	vt.SliceViewBaseEvents()
+	vt.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		vtv := recv.Embed(KiT_VirtTreeView).(*VirtTreeView)
+		kt := d.(*key.ChordEvent)
+		vtv.KeyInput(kt)
+	})
+	sg := vt.SliceGrid()
+	for _, rwk := range sg.Kids {
+		if rwk == nil {
+			continue
+		}
+		rw := rwk.(*gi.Layout)
+		if rw.NumChildren() < 3 {
+			continue
+		}
+		lbl := rw.Child(2).(*gi.Label)
+		// HiPri is needed to override label's native processing
+		lbl.ConnectEvent(oswin.MouseEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			vtvi := recv.ParentByType(KiT_VirtTreeView, ki.Embeds)
+			if vtvi == nil || vtvi.This() == nil { // deleted
+				return
+			}
+			vtv := vtvi.Embed(KiT_VirtTreeView).(*VirtTreeView)
+			idx, ok := vtv.rowIdx(recv.Parent())
+			if !ok {
+				return
+			}
+			me := d.(*mouse.Event)
+			switch me.Button {
+			case mouse.Left:
+				switch me.Action {
+				case mouse.DoubleClick:
+					vtv.ToggleNode(vtv.Rows[idx].Node)
+					me.SetProcessed()
+				case mouse.Release:
+					vtv.SelectIdxAction(idx, me.SelectMode())
+					me.SetProcessed()
+				}
+			case mouse.Right:
+				if me.Action == mouse.Release {
+					me.SetProcessed()
+					if !vtv.IdxIsSelected(idx) {
+						vtv.SelectIdxAction(idx, mouse.SelectOne)
+					}
+					vtv.ItemCtxtMenu(idx)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"testing"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// lazyTestNode is a node that loads nkids children when opened
+type lazyTestNode struct {
+	ki.Node
+	nkids  int
+	loaded bool
+}
+
+var KiT_lazyTestNode = kit.Types.AddType(&lazyTestNode{}, nil)
+
+func (ln *lazyTestNode) HasLazyChildren() bool {
+	return !ln.loaded
+}
+
+func (ln *lazyTestNode) LoadChildren() error {
+	ln.loaded = true
+	updt := ln.UpdateStart()
+	for i := 0; i < ln.nkids; i++ {
+		ln.AddNewChild(ki.KiT_Node, "lazy"+string(rune('0'+i)))
+	}
+	ln.UpdateEnd(updt)
+	return nil
+}
+
+// virtTreeTestRows returns the names and depths of the rows
+func virtTreeTestRows(vt *VirtTreeView) ([]string, []int) {
+	nms := make([]string, len(vt.Rows))
+	dps := make([]int, len(vt.Rows))
+	for i, rw := range vt.Rows {
+		nms[i] = rw.Node.Name()
+		dps[i] = rw.Depth
+	}
+	return nms, dps
+}
+
+func virtTreeTestCheck(t *testing.T, vt *VirtTreeView, desc string, nms []string, dps []int) {
+	gnms, gdps := virtTreeTestRows(vt)
+	if len(gnms) != len(nms) {
+		t.Errorf("%v: rows %v, expected %v", desc, gnms, nms)
+		return
+	}
+	for i := range nms {
+		if gnms[i] != nms[i] || (dps != nil && gdps[i] != dps[i]) {
+			t.Errorf("%v: rows %v depths %v, expected %v %v", desc, gnms, gdps, nms, dps)
+			return
+		}
+	}
+}
+
+func TestVirtTreeView(t *testing.T) {
+	root := &ki.Node{}
+	root.InitName(root, "root")
+	a := root.AddNewChild(ki.KiT_Node, "a")
+	a1 := a.AddNewChild(ki.KiT_Node, "a1")
+	a1.AddNewChild(ki.KiT_Node, "a11")
+	a2 := a.AddNewChild(ki.KiT_Node, "a2")
+	b := &lazyTestNode{nkids: 2}
+	root.AddChild(b)
+	b.SetName("b")
+	c := root.AddNewChild(ki.KiT_Node, "c")
+
+	// set up without SetRootNode, which configures the widgets
+	vt := &VirtTreeView{}
+	vt.InitName(vt, "vt")
+	vt.RootNode = root
+	vt.OpenDepth = 2
+	vt.OpenNodes = make(map[ki.Ki]struct{})
+	vt.openToDepth(root, 0)
+	vt.SelectedIdx = -1
+	vt.ResetSelectedIdxs()
+	vt.Rebuild()
+	virtTreeTestCheck(t, vt, "open-depth 2", []string{"root", "a", "a1", "a2", "b", "c"}, []int{0, 1, 2, 2, 1, 1})
+	if vt.IsNodeOpen(a1) || vt.IsNodeOpen(b) || !vt.IsNodeOpen(a) {
+		t.Errorf("openToDepth: a1 open %v, lazy b open %v, a open %v", vt.IsNodeOpen(a1), vt.IsNodeOpen(b), vt.IsNodeOpen(a))
+	}
+	if !vt.NodeHasBranch(b) || vt.NodeHasKids(b) || vt.NodeHasBranch(c) {
+		t.Errorf("NodeHasBranch: lazy b %v, b has kids %v, c %v", vt.NodeHasBranch(b), vt.NodeHasKids(b), vt.NodeHasBranch(c))
+	}
+
+	// selection follows the nodes, and is dropped for hidden ones
+	vt.SelectedIdx = vt.NodeIdx(a2)
+	vt.SelectedIdxs[vt.NodeIdx(a2)] = struct{}{}
+	vt.SelectedIdxs[vt.NodeIdx(c)] = struct{}{}
+	delete(vt.OpenNodes, a)
+	vt.Rebuild()
+	virtTreeTestCheck(t, vt, "close a", []string{"root", "a", "b", "c"}, nil)
+	if sn := vt.SelectedNodes(); vt.SelectedIdx != -1 || len(sn) != 1 || sn[0] != c {
+		t.Errorf("close a: SelectedIdx %v, selected nodes %v, expected -1, [c]", vt.SelectedIdx, sn)
+	}
+
+	// opening a lazy node loads its children
+	vt.loading = true
+	LoadLazyChildren(b)
+	vt.loading = false
+	vt.OpenNodes[b] = struct{}{}
+	vt.Rebuild()
+	virtTreeTestCheck(t, vt, "open lazy b", []string{"root", "a", "b", "lazy0", "lazy1", "c"}, []int{0, 1, 1, 2, 2, 1})
+	if vt.NodeIdx(c) != 5 || vt.NodeIdx(a1) != -1 {
+		t.Errorf("NodeIdx: c %v, hidden a1 %v, expected 5, -1", vt.NodeIdx(c), vt.NodeIdx(a1))
+	}
+
+	// structural changes to shown open nodes rebuild the rows
+	root.AddNewChild(ki.KiT_Node, "d")
+	virtTreeTestCheck(t, vt, "add d", []string{"root", "a", "b", "lazy0", "lazy1", "c", "d"}, nil)
+	b.DeleteChildAtIndex(0, true)
+	virtTreeTestCheck(t, vt, "delete lazy0", []string{"root", "a", "b", "lazy1", "c", "d"}, nil)
+	a.AddNewChild(ki.KiT_Node, "a3") // a is closed: rows are unchanged
+	virtTreeTestCheck(t, vt, "add to closed a", []string{"root", "a", "b", "lazy1", "c", "d"}, nil)
+
+	// deleted open nodes are forgotten
+	root.DeleteChild(b, true)
+	virtTreeTestCheck(t, vt, "delete b", []string{"root", "a", "c", "d"}, nil)
+	if len(vt.OpenNodes) != 1 || !vt.IsNodeOpen(root) {
+		t.Errorf("delete b: %v open nodes, expected just root", len(vt.OpenNodes))
+	}
+
+	vt.Disconnect()
+	root.AddNewChild(ki.KiT_Node, "e")
+	if vt.NodeIdx(root.ChildByName("e", 0)) != -1 {
+		t.Errorf("Disconnect: rows still updated from source")
+	}
+}
@@ -149,6 +149,23 @@ func (dr *Drawing) TriangleStripsIndexed(start, count int) {
 	gl.DrawElements(gl.TRIANGLE_STRIP, int32(count), gl.UNSIGNED_INT, gl.PtrOffset(start*4))
 }
 
+// Points uses all existing settings to draw Points (non-indexed).
+// The point size is set by gl_PointSize in the vertex shader.
+func (dr *Drawing) Points(start, count int) {
+	gl.Enable(gl.PROGRAM_POINT_SIZE)
+	gl.DrawArrays(gl.POINTS, int32(start), int32(count))
+}
+
+// PointsIndexed uses all existing settings to draw Points Indexed.
+// You must have activated an IndexesBuffer that supplies
+// the indexes, and start + count determine range of such indexes
+// to use, and must be within bounds for that.
+// The point size is set by gl_PointSize in the vertex shader.
+func (dr *Drawing) PointsIndexed(start, count int) {
+	gl.Enable(gl.PROGRAM_POINT_SIZE)
+	gl.DrawElements(gl.POINTS, int32(count), gl.UNSIGNED_INT, gl.PtrOffset(start*4))
+}
+
 // Flush ensures that all rendering is pushed to current render target.
 // Especially useful for rendering to framebuffers (Window SwapBuffer
 // automatically does a flush)
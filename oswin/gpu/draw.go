@@ -67,6 +67,17 @@ type Drawing interface {
 	// to use, and must be within bounds for that.
 	TriangleStripsIndexed(start, count int)
 
+	// Points uses all existing settings to draw Points (non-indexed).
+	// The point size is set by gl_PointSize in the vertex shader.
+	Points(start, count int)
+
+	// PointsIndexed uses all existing settings to draw Points Indexed.
+	// You must have activated an IndexesBuffer that supplies
+	// the indexes, and start + count determine range of such indexes
+	// to use, and must be within bounds for that.
+	// The point size is set by gl_PointSize in the vertex shader.
+	PointsIndexed(start, count int)
+
 	// Flush ensures that all rendering is pushed to current render target.
 	// Especially useful for rendering to framebuffers (Window SwapBuffer
 	// automatically does a flush)